@@ -0,0 +1,1114 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/braunma/idrac-netbox-importer/internal/history"
+	"github.com/braunma/idrac-netbox-importer/internal/leader"
+	"github.com/braunma/idrac-netbox-importer/internal/output"
+	"github.com/braunma/idrac-netbox-importer/internal/schedule"
+	"github.com/braunma/idrac-netbox-importer/internal/service"
+	"github.com/braunma/idrac-netbox-importer/internal/signing"
+	"github.com/braunma/idrac-netbox-importer/internal/wizard"
+	"github.com/braunma/idrac-netbox-importer/pkg/config"
+	"github.com/braunma/idrac-netbox-importer/pkg/defaults"
+	"github.com/braunma/idrac-netbox-importer/pkg/errors"
+	"github.com/braunma/idrac-netbox-importer/pkg/logging"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+	"github.com/braunma/idrac-netbox-importer/pkg/redact"
+	"github.com/braunma/idrac-netbox-importer/pkg/runid"
+	"github.com/braunma/idrac-netbox-importer/pkg/scanner"
+)
+
+// command is a subcommand with its own flag set.
+type command struct {
+	summary string
+	run     func(args []string) error
+}
+
+// commands maps subcommand names to their implementations. Without a
+// subcommand the flag-based scan mode in main() is used.
+var commands = map[string]command{
+	"compare": {
+		summary: "Compare two JSON result files: servers added/removed, hardware changes per host, fleet totals",
+		run:     runCompare,
+	},
+	"device-types": {
+		summary: "Create NetBox device types for scanned models from the model catalog",
+		run:     runDeviceTypes,
+	},
+	"doctor": {
+		summary: "Check DNS, reachability, NetBox, git, trust store and environment before the first scan",
+		run:     runDoctor,
+	},
+	"find": {
+		summary: "Find drives, DIMMs and other components by serial or part number in stored results and history",
+		run:     runFind,
+	},
+	"init": {
+		summary: "Generate a starter config file interactively (or from flags) and test one iDRAC",
+		run:     runInit,
+	},
+	"install-service": {
+		summary: "Register daemon mode as a systemd unit (Linux) or service (Windows)",
+		run:     runInstallService,
+	},
+	"lock-server": {
+		summary: "Serve leases for daemon.leader_election.lock_url (in-memory HTTP lock service)",
+		run:     runLockServer,
+	},
+	"merge": {
+		summary: "Merge the JSON results of sharded scans into one result set or report",
+		run:     runMerge,
+	},
+	"prune-report": {
+		summary: "List NetBox devices not inventoried for N days as decommission candidates, optionally tagging them",
+		run:     runPruneReport,
+	},
+	"power-state": {
+		summary: "Query (never change) the power state of the configured hosts without a full scan",
+		run:     runPowerState,
+	},
+	"schedule": {
+		summary: "Show the per-host scan schedule of daemon mode (backoff, quarantine) and release hosts",
+		run:     runSchedule,
+	},
+	"serve": {
+		summary: "Serve the last results and fleet trends as a Grafana JSON or Infinity datasource",
+		run:     runServe,
+	},
+	"sync": {
+		summary: "Run the NetBox sync and GitLab export on saved JSON results without scanning",
+		run:     runSync,
+	},
+	"verify": {
+		summary: "Verify the signatures of signed inventory reports",
+		run:     runVerify,
+	},
+	"version": {
+		summary: "Show version, platform and the platform-specific features enabled in this build",
+		run:     runVersion,
+	},
+}
+
+// runCommand initializes logging, runs a subcommand and returns the exit code.
+func runCommand(cmd command, args []string) int {
+	if err := logging.Init(logging.Config{
+		Level:  "info",
+		Format: "console",
+		RunID:  runid.Current(),
+	}); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to initialize logging: %v\n", err)
+		return 1
+	}
+	defer logging.Sync()
+
+	if err := cmd.run(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		logging.Error("Execution failed", "error", err)
+		return errors.ExitCode(err)
+	}
+	return errors.ExitOK
+}
+
+// printCommands lists the available subcommands for the usage text.
+func printCommands(w io.Writer) {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintf(w, "Commands:\n")
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-18s %s\n", name, commands[name].summary)
+	}
+	fmt.Fprintf(w, "\n")
+}
+
+// runInstallService implements the install-service subcommand.
+// Arguments after "--" are passed on to the daemon (e.g. -- -sync).
+func runInstallService(args []string) error {
+	fs := flag.NewFlagSet("install-service", flag.ContinueOnError)
+	name := fs.String("name", defaults.AppName, "Service / task name")
+	configPath := fs.String("config", "config.yaml", "Path to configuration file")
+	stateDir := fs.String("state-dir", "", "State directory (default: managed by systemd, or the XDG state dir)")
+	envFile := fs.String("env-file", "", "KEY=VALUE file with secrets loaded by the service")
+	user := fs.String("user", "", "Run the service as this user (systemd only)")
+	start := fs.Bool("start", false, "Start the service after installing it")
+	dryRun := fs.Bool("dry-run", false, "Print the unit file / commands instead of installing")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage:\n  %s install-service [options] [-- daemon flags]\n\nOptions:\n", os.Args[0])
+		fs.PrintDefaults()
+		fmt.Fprintf(fs.Output(), "\nExample:\n  %s install-service -config /etc/idrac-inventory/config.yaml -env-file /etc/idrac-inventory/env -- -sync\n", os.Args[0])
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to determine executable path: %w", err)
+	}
+
+	// The service runs from a different working directory, so paths must be absolute.
+	opts := service.Options{
+		Name:       *name,
+		Executable: exe,
+		User:       *user,
+		ExtraArgs:  fs.Args(),
+		Start:      *start,
+	}
+	if opts.ConfigPath, err = filepath.Abs(*configPath); err != nil {
+		return err
+	}
+	if *stateDir != "" {
+		if opts.StateDir, err = filepath.Abs(*stateDir); err != nil {
+			return err
+		}
+	}
+	if *envFile != "" {
+		if opts.EnvFile, err = filepath.Abs(*envFile); err != nil {
+			return err
+		}
+	}
+
+	if err := service.Install(opts, *dryRun, os.Stdout); err != nil {
+		return err
+	}
+	if !*dryRun {
+		logging.Info("Service installed", "name", opts.Name, "config", opts.ConfigPath)
+	}
+	return nil
+}
+
+// runInit implements the init subcommand: it asks for the NetBox connection,
+// default iDRAC credentials and IP ranges (flag values are the defaults),
+// checks the connection to one sample host and writes a commented config.
+func runInit(args []string) error {
+	fs := flag.NewFlagSet("init", flag.ContinueOnError)
+	path := fs.String("output", "config.yaml", "Config file to write")
+	force := fs.Bool("force", false, "Overwrite an existing config file")
+	nonInteractive := fs.Bool("non-interactive", false, "Do not prompt; take all answers from the flags")
+	a := wizard.Answers{Concurrency: defaults.GetConcurrency()}
+	fs.StringVar(&a.NetBoxURL, "netbox-url", os.Getenv(defaults.EnvNetBoxURL), "NetBox URL (empty: no NetBox section)")
+	fs.StringVar(&a.NetBoxToken, "netbox-token", "", "NetBox API token, or ${VAR} to read it from the environment")
+	fs.StringVar(&a.Username, "user", "root", "Default iDRAC username")
+	fs.StringVar(&a.Password, "pass", "${"+defaults.EnvDefaultPassword+"}", "Default iDRAC password, or ${VAR} to read it from the environment")
+	ranges := fs.String("ranges", "", "Comma-separated iDRAC IP addresses or ranges, e.g. 10.0.0.1-10.0.0.25")
+	fs.BoolVar(&a.InsecureSkipVerify, "insecure", true, "Accept self-signed iDRAC certificates")
+	fs.IntVar(&a.Concurrency, "concurrency", a.Concurrency, "Servers to scan in parallel")
+	sample := fs.String("sample-host", "", "Host to test the connection with (default: first address of the ranges)")
+	skipCheck := fs.Bool("skip-check", false, "Write the config without testing the connection")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage:\n  %s init [options]\n\nOptions:\n", os.Args[0])
+		fs.PrintDefaults()
+		fmt.Fprintf(fs.Output(), "\nExample:\n  %s init -non-interactive -netbox-url https://netbox.example.com -netbox-token '${NETBOX_TOKEN}' -ranges 10.0.0.1-10.0.0.25\n", os.Args[0])
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	a.IPRanges = wizard.SplitList(*ranges)
+
+	var prompt *wizard.Prompter
+	if !*nonInteractive {
+		prompt = wizard.NewPrompter(os.Stdin, os.Stdout)
+	}
+	for {
+		if prompt != nil {
+			var err error
+			if a, err = prompt.Ask(a); err != nil {
+				return err
+			}
+		}
+		err := a.Validate()
+		if err == nil {
+			break
+		}
+		if prompt == nil {
+			return err
+		}
+		fmt.Printf("\n%v, please correct it.\n\n", err)
+	}
+
+	if !*skipCheck {
+		if err := checkSampleHost(a, *sample); err != nil {
+			if prompt == nil {
+				return fmt.Errorf("%w (use -skip-check to write the config anyway)", err)
+			}
+			fmt.Printf("\n%v\n", err)
+			write, askErr := prompt.Confirm("Write the config anyway", false)
+			if askErr != nil || !write {
+				return err
+			}
+		}
+	}
+
+	if err := wizard.Write(*path, a, *force); err != nil {
+		return err
+	}
+	fmt.Printf("\nWrote %s. Next steps:\n", *path)
+	fmt.Printf("  %s -config %s -validate\n", os.Args[0], *path)
+	fmt.Printf("  %s -config %s -output table\n", os.Args[0], *path)
+	return nil
+}
+
+// checkSampleHost validates the connection to one host with the generated
+// config: the sample, or the first address of the ranges.
+func checkSampleHost(a wizard.Answers, sample string) error {
+	// Only the iDRAC settings matter here; an unset NetBox token must not fail the check
+	a.NetBoxURL, a.NetBoxToken = "", ""
+	data, err := wizard.Render(a)
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Parse(data)
+	if err != nil {
+		return err
+	}
+	redact.AddSecrets(cfg.Secrets()...)
+	cfg.ReadOnly = true
+
+	server := cfg.Servers[0]
+	if sample != "" {
+		server.Host, server.Name = sample, ""
+	}
+	cfg.Servers = []config.ServerConfig{server}
+
+	fmt.Printf("\nTesting the connection to %s...\n", server.Host)
+	check := scanner.New(cfg).ValidateConnections(context.Background())[0]
+	if !check.OK() {
+		return fmt.Errorf("connection to %s failed: %w", server.Host, check.Error)
+	}
+	fmt.Printf("Connected: Redfish %s, firmware %s, %s\n",
+		dash(check.RedfishVersion), dash(check.FirmwareVersion), check.Latency.Round(time.Millisecond))
+	return nil
+}
+
+// runSync implements the sync subcommand: it loads results written by
+// -output json (or the daemon's last-scan.json) and runs only the downstream
+// steps, so they can be repeated without rescanning the iDRACs.
+func runSync(args []string) error {
+	fs := flag.NewFlagSet("sync", flag.ContinueOnError)
+	f := &flags{certDays: defaultCertDays}
+	fromFile := fs.String("from-file", "", "JSON results to sync (from -output json, gzipped or not, an -output-dir "+output.ChunkIndexFile+" or <state_dir>/"+lastScanFile+")")
+	noNetBox := fs.Bool("no-netbox", false, "Skip the NetBox sync (e.g. only run the GitLab export)")
+	fs.BoolVar(&f.force, "force", false, "Sync even if it would exceed netbox.max_changes or netbox.max_creates")
+	fs.BoolVar(&f.readOnly, "read-only", false, "Refuse all writes (NetBox, git push) at the client layer")
+	fs.StringVar(&f.configFile, "config", "config.yaml", "Path to configuration file")
+	fs.StringVar(&f.profile, "profile", os.Getenv(defaults.EnvProfile), "Named profile from the config file (env: "+defaults.EnvProfile+")")
+	fs.StringVar(&f.envFile, "env-file", "", "Load KEY=VALUE environment variables from this file before reading the config")
+	fs.StringVar(&f.outputFormat, "output", "", "Also print the loaded results: console, json, table, csv, junit, aggregate")
+	fs.StringVar(&f.report, "report", "", "Print an analysis report of the loaded results (see the main -report flag)")
+	fs.BoolVar(&f.noColor, "no-color", false, "Disable colored output")
+	fs.StringVar(&f.gitlabRepo, "gitlab-repo", "", "Path to local git repository; triggers aggregated export")
+	fs.StringVar(&f.gitlabBranch, "gitlab-branch", "main", "Git branch to commit the inventory to")
+	fs.StringVar(&f.gitlabDir, "gitlab-dir", "inventory", "Sub-directory inside the repo for inventory files")
+	fs.BoolVar(&f.gitlabPush, "gitlab-push", false, "Push to the remote after committing")
+	fs.BoolVar(&f.gitlabBeat, "gitlab-heartbeat", false, "Commit a timestamped heartbeat even if the inventory is unchanged")
+	fs.StringVar(&f.placementFile, "placement", "", "CSV/YAML file mapping service tags to site, rack, position and tenant (overrides placement.file)")
+	fs.StringVar(&f.annotationsFile, "annotations", "", "CSV/YAML file of notes per host or service tag (overrides annotations.file)")
+	fs.StringVar(&f.goldenFile, "golden", "", "YAML file of expected builds per model or NetBox device role (overrides golden.file)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage:\n  %s sync -from-file results.json [options]\n\nOptions:\n", os.Args[0])
+		fs.PrintDefaults()
+		fmt.Fprintf(fs.Output(), "\nExample:\n  %s -config config.yaml -output json > results.json\n  %s sync -from-file results.json -config config.yaml -output aggregate\n", os.Args[0], os.Args[0])
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *fromFile == "" {
+		fs.Usage()
+		return fmt.Errorf("-from-file is required")
+	}
+	f.syncNetBox = !*noNetBox
+
+	if f.envFile != "" {
+		if err := loadEnvFile(f.envFile); err != nil {
+			return err
+		}
+	}
+
+	cfg, err := config.LoadProfile(f.configFile, f.profile)
+	if err != nil {
+		return fmt.Errorf("failed to load config from %s: %w", f.configFile, err)
+	}
+	redact.AddSecrets(cfg.Secrets()...)
+	applyReadOnly(cfg, f)
+
+	results, stats, err := output.ReadJSONFile(*fromFile)
+	if err != nil {
+		return fmt.Errorf("%s: %w", *fromFile, err)
+	}
+
+	logging.Info("Loaded scan results",
+		"file", *fromFile,
+		"servers", len(results),
+		"successful", stats.SuccessfulCount,
+		"failed", stats.FailedCount,
+	)
+
+	if f.placementFile != "" {
+		cfg.Placement.File = f.placementFile
+	}
+	if f.annotationsFile != "" {
+		cfg.Annotations.File = f.annotationsFile
+	}
+	applyFiles(cfg, results)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	setupSignalHandler(cancel)
+
+	if f.goldenFile != "" {
+		cfg.Golden.File = f.goldenFile
+	}
+	checkGolden(ctx, cfg, results)
+	checkFirmware(cfg, results)
+
+	if f.report != "" {
+		if err := outputReport(f, cfg, results); err != nil {
+			return fmt.Errorf("failed to output report: %w", err)
+		}
+	} else if f.outputFormat != "" {
+		if err := outputResults(f, cfg, results, stats); err != nil {
+			return fmt.Errorf("failed to output results: %w", err)
+		}
+	}
+
+	return publishResults(ctx, cfg, f, results, stats, hookSummary(stats, *fromFile))
+}
+
+// runDeviceTypes implements the device-types subcommand: it creates a NetBox
+// device type (U height, airflow, weight, PSU power ports) for every model in
+// saved scan results, or fills the missing attributes of existing ones.
+func runDeviceTypes(args []string) error {
+	fs := flag.NewFlagSet("device-types", flag.ContinueOnError)
+	f := &flags{}
+	fromFile := fs.String("from-file", "", "JSON results to take the models from (as for the sync command)")
+	fs.BoolVar(&f.readOnly, "read-only", false, "Refuse all writes at the client layer")
+	fs.StringVar(&f.configFile, "config", "config.yaml", "Path to configuration file")
+	fs.StringVar(&f.profile, "profile", os.Getenv(defaults.EnvProfile), "Named profile from the config file (env: "+defaults.EnvProfile+")")
+	fs.StringVar(&f.envFile, "env-file", "", "Load KEY=VALUE environment variables from this file before reading the config")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage:\n  %s device-types -from-file results.json [options]\n\nOptions:\n", os.Args[0])
+		fs.PrintDefaults()
+		fmt.Fprintf(fs.Output(), "\nModels missing from the built-in catalog can be added with catalog.file.\n")
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *fromFile == "" {
+		fs.Usage()
+		return fmt.Errorf("-from-file is required")
+	}
+
+	if f.envFile != "" {
+		if err := loadEnvFile(f.envFile); err != nil {
+			return err
+		}
+	}
+
+	cfg, err := config.LoadProfile(f.configFile, f.profile)
+	if err != nil {
+		return fmt.Errorf("failed to load config from %s: %w", f.configFile, err)
+	}
+	if !cfg.NetBox.IsEnabled() {
+		return fmt.Errorf("NetBox is not configured (netbox.url and netbox.token)")
+	}
+	redact.AddSecrets(cfg.Secrets()...)
+	applyReadOnly(cfg, f)
+
+	results, _, err := output.ReadJSONFile(*fromFile)
+	if err != nil {
+		return fmt.Errorf("%s: %w", *fromFile, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	setupSignalHandler(cancel)
+
+	client, err := newNetBoxClient(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	seen := map[[2]string]bool{}
+	failed := 0
+	fmt.Println("\nNetBox Device Types:")
+	for _, r := range results {
+		key := [2]string{r.Manufacturer, r.Model}
+		if !r.IsValid() || r.Model == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		outcome, err := client.EnsureDeviceType(ctx, r.Manufacturer, r.Model)
+		if err != nil {
+			failed++
+			fmt.Printf("  ❌ %s: %v\n", r.Model, err)
+			continue
+		}
+		fmt.Printf("  ✅ %s: %s\n", r.Model, outcome)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d device types failed", failed, len(seen))
+	}
+	return nil
+}
+
+// runLockServer implements the lock-server command: a minimal HTTP lock
+// service for replicas that have no shared storage for a lock file.
+func runLockServer(args []string) error {
+	fs := flag.NewFlagSet("lock-server", flag.ContinueOnError)
+	listen := fs.String("listen", ":8080", "Address to listen on")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage:\n  %s lock-server [options]\n\nOptions:\n", os.Args[0])
+		fs.PrintDefaults()
+		fmt.Fprintf(fs.Output(), "\nEach URL path is a separate lock, e.g. in the replicas' config:\n  daemon:\n    leader_election:\n      lock_url: http://inventory-lock:8080/locks/idrac-inventory\n")
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	setupSignalHandler(cancel)
+
+	srv := &http.Server{Addr: *listen, Handler: leader.NewHandler(), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		srv.Close()
+	}()
+
+	logging.Info("Serving leases", "addr", *listen)
+	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// runMerge implements the merge subcommand: it combines the JSON results of
+// instances that each scanned one shard (-shard K/N) and prints them like a
+// single scan of all servers.
+func runMerge(args []string) error {
+	fs := flag.NewFlagSet("merge", flag.ContinueOnError)
+	f := &flags{certDays: defaultCertDays, chunkSize: defaults.DefaultOutputChunkSize}
+	fs.StringVar(&f.configFile, "config", "", "Configuration file for aggregation settings and the model catalog (optional)")
+	fs.StringVar(&f.outputFormat, "output", "json", "Output format: console, json, table, csv, junit, aggregate")
+	fs.StringVar(&f.report, "report", "", "Print an analysis report of the merged results (see the main -report flag)")
+	fs.BoolVar(&f.noColor, "no-color", false, "Disable colored output")
+	fs.BoolVar(&f.compress, "compress", false, "Gzip-compress -output json")
+	fs.StringVar(&f.outputDir, "output-dir", "", "Write -output json as chunk files plus "+output.ChunkIndexFile+" into this directory instead of stdout")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage:\n  %s merge [options] file...\n\nOptions:\n", os.Args[0])
+		fs.PrintDefaults()
+		fmt.Fprintf(fs.Output(), "\nExample:\n  %s -shard 1/2 -output json > shard-1.json\n  %s -shard 2/2 -output json > shard-2.json\n  %s merge shard-1.json shard-2.json > results.json\n", os.Args[0], os.Args[0], os.Args[0])
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return fmt.Errorf("no result files to merge")
+	}
+
+	cfg := &config.Config{}
+	if f.configFile != "" {
+		var err error
+		if cfg, err = config.Load(f.configFile); err != nil {
+			return fmt.Errorf("failed to load config from %s: %w", f.configFile, err)
+		}
+	}
+
+	var parts [][]models.ServerInfo
+	var partStats []models.CollectionStats
+	for _, path := range fs.Args() {
+		results, stats, err := output.ReadJSONFile(path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		parts = append(parts, results)
+		partStats = append(partStats, stats)
+	}
+
+	results, stats, err := models.MergeResults(parts, partStats)
+	if err != nil {
+		return err
+	}
+	if missing := missingShards(partStats); len(missing) > 0 {
+		logging.Warn("Merged results are incomplete, shards are missing", "missing", strings.Join(missing, ", "))
+	}
+	logging.Info("Merged scan results",
+		"files", fs.NArg(),
+		"servers", len(results),
+		"successful", stats.SuccessfulCount,
+		"failed", stats.FailedCount,
+	)
+
+	if f.report != "" {
+		return outputReport(f, cfg, results)
+	}
+	return outputResults(f, cfg, results, stats)
+}
+
+// runCompare implements the compare subcommand: it compares two saved result
+// sets of the fleet (e.g. last month's and today's) for audit sign-off.
+func runCompare(args []string) error {
+	fs := flag.NewFlagSet("compare", flag.ContinueOnError)
+	configFile := fs.String("config", "", "Configuration file for the units of sizes (optional)")
+	format := fs.String("output", "console", "Output format: console, table, markdown, csv, json")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage:\n  %s compare [options] old.json new.json\n\nOptions:\n", os.Args[0])
+		fs.PrintDefaults()
+		fmt.Fprintf(fs.Output(), "\nExample:\n  %s compare -output markdown inventory-2025-02.json inventory-2025-03.json > audit.md\n", os.Args[0])
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return fmt.Errorf("compare needs exactly two result files")
+	}
+
+	cfg := &config.Config{}
+	if *configFile != "" {
+		var err error
+		if cfg, err = config.Load(*configFile); err != nil {
+			return fmt.Errorf("failed to load config from %s: %w", *configFile, err)
+		}
+	}
+
+	var sets [2][]models.ServerInfo
+	for i, path := range fs.Args() {
+		results, _, err := output.ReadJSONFile(path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		sets[i] = results
+	}
+
+	c := models.CompareFleets(sets[0], sets[1])
+	logging.Info("Compared scan results",
+		"old", fs.Arg(0),
+		"new", fs.Arg(1),
+		"added", len(c.Added),
+		"removed", len(c.Removed),
+		"changed", len(c.Changed),
+		"unchanged", c.Unchanged,
+	)
+	return output.WriteComparison(os.Stdout, c, cfg.Units.Format(), *format)
+}
+
+// runFind implements the find subcommand: it searches saved results and the
+// history store for components by serial or part number, e.g. the serials
+// of a vendor recall, and reports the host and slot containing each.
+func runFind(args []string) error {
+	fs := flag.NewFlagSet("find", flag.ContinueOnError)
+	f := &flags{}
+	fs.StringVar(&f.configFile, "config", "config.yaml", "Path to configuration file (for paths.state_dir and history.path)")
+	fs.StringVar(&f.profile, "profile", os.Getenv(defaults.EnvProfile), "Named profile from the config file (env: "+defaults.EnvProfile+")")
+	fs.StringVar(&f.stateDir, "state-dir", "", "State directory with "+lastScanFile+" and the history (instead of -config)")
+	fromFiles := fs.String("from-file", "", "Comma-separated JSON result files to search instead of the state directory")
+	listFile := fs.String("file", "", "File with one serial or part number per line (# starts a comment)")
+	partial := fs.Bool("partial", false, "Match serial and part numbers containing a query instead of equal to it")
+	format := fs.String("output", "console", "Output format: console, table, csv, markdown, json")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage:\n  %s find [options] serial-or-part-number...\n\nOptions:\n", os.Args[0])
+		fs.PrintDefaults()
+		fmt.Fprintf(fs.Output(), "\nExample:\n  %s find -config config.yaml -file recall.txt -output csv\n", os.Args[0])
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	queries := fs.Args()
+	if *listFile != "" {
+		list, err := readList(*listFile)
+		if err != nil {
+			return err
+		}
+		queries = append(queries, list...)
+	}
+	if len(queries) == 0 {
+		fs.Usage()
+		return fmt.Errorf("no serial or part numbers to find")
+	}
+
+	var sources []string
+	var servers []models.ServerInfo
+	if *fromFiles != "" {
+		for _, path := range strings.Split(*fromFiles, ",") {
+			results, _, err := output.ReadJSONFile(strings.TrimSpace(path))
+			if err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+			servers = append(servers, results...)
+			sources = append(sources, path)
+		}
+	} else {
+		stateDir, historyPath := f.stateDir, config.HistoryConfig{}.GetPath(f.stateDir)
+		if stateDir == "" {
+			cfg, err := config.LoadProfile(f.configFile, f.profile)
+			if err != nil {
+				return fmt.Errorf("failed to load config from %s: %w", f.configFile, err)
+			}
+			stateDir = cfg.Paths.GetStateDir()
+			historyPath = cfg.History.GetPath(stateDir)
+		}
+
+		if _, err := os.Stat(historyPath); err == nil {
+			store, err := history.Load(historyPath)
+			if err != nil {
+				return err
+			}
+			servers = append(servers, store.Servers()...)
+			sources = append(sources, historyPath)
+		}
+		lastScan := filepath.Join(stateDir, lastScanFile)
+		if _, err := os.Stat(lastScan); err == nil {
+			results, _, err := output.ReadJSONFile(lastScan)
+			if err != nil {
+				return fmt.Errorf("%s: %w", lastScan, err)
+			}
+			servers = append(servers, results...)
+			sources = append(sources, lastScan)
+		}
+		if len(sources) == 0 {
+			return fmt.Errorf("no stored results in %s (enable history, run the daemon or use -from-file)", stateDir)
+		}
+	}
+
+	matches := models.FindComponents(servers, queries, *partial)
+	found := map[string]bool{}
+	for _, m := range matches {
+		found[m.Query] = true
+	}
+	var missing []string
+	for _, q := range queries {
+		if q = strings.ToUpper(strings.TrimSpace(q)); q != "" && !found[q] {
+			missing = append(missing, q)
+		}
+	}
+	logging.Info("Searched components",
+		"sources", sources,
+		"servers", len(servers),
+		"matches", len(matches),
+	)
+	if len(missing) > 0 {
+		logging.Info("Not found in any server", "queries", missing)
+	}
+
+	return output.WriteReport(os.Stdout, output.ComponentSearchReport(matches), *format)
+}
+
+// readList reads one item per line, skipping blank lines and # comments.
+func readList(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var items []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			items = append(items, line)
+		}
+	}
+	return items, nil
+}
+
+// missingShards returns the shards ("K/N") absent from the parts, judged by
+// the shard recorded in their stats. Parts without a shard are ignored.
+func missingShards(parts []models.CollectionStats) []string {
+	seen := map[string]bool{}
+	counts := map[int]bool{}
+	for _, p := range parts {
+		if p.Shard == "" {
+			continue
+		}
+		seen[p.Shard] = true
+		if shard, err := config.ParseShard(p.Shard, ""); err == nil {
+			counts[shard.Count] = true
+		}
+	}
+
+	var missing []string
+	for n := range counts {
+		for k := 1; k <= n; k++ {
+			if spec := fmt.Sprintf("%d/%d", k, n); !seen[spec] {
+				missing = append(missing, spec)
+			}
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// runSchedule implements the schedule command: it prints the per-host scan
+// schedule kept by daemon mode with daemon.backoff, and releases hosts from
+// backoff and quarantine so the daemon scans them in its next cycle.
+func runSchedule(args []string) error {
+	fs := flag.NewFlagSet("schedule", flag.ContinueOnError)
+	f := &flags{}
+	fs.StringVar(&f.configFile, "config", "config.yaml", "Path to configuration file (for paths.state_dir)")
+	fs.StringVar(&f.profile, "profile", os.Getenv(defaults.EnvProfile), "Named profile from the config file (env: "+defaults.EnvProfile+")")
+	fs.StringVar(&f.stateDir, "state-dir", "", "State directory of the daemon (instead of -config)")
+	asJSON := fs.Bool("json", false, "Print the schedule as JSON")
+	release := fs.String("release", "", "Comma-separated hosts to release from backoff and quarantine")
+	releaseAll := fs.Bool("release-all", false, "Release all hosts")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	stateDir := f.stateDir
+	if stateDir == "" {
+		cfg, err := config.LoadProfile(f.configFile, f.profile)
+		if err != nil {
+			return fmt.Errorf("failed to load config from %s: %w", f.configFile, err)
+		}
+		stateDir = cfg.Paths.GetStateDir()
+	}
+	path := filepath.Join(stateDir, defaults.DefaultScheduleFile)
+
+	sched, err := schedule.Load(path, schedule.Policy{})
+	if err != nil {
+		return err
+	}
+
+	if *release != "" || *releaseAll {
+		var hosts []string
+		if *releaseAll {
+			for _, h := range sched.Hosts() {
+				hosts = append(hosts, h.Host)
+			}
+		} else {
+			hosts = strings.Split(*release, ",")
+		}
+		for _, host := range hosts {
+			host = strings.TrimSpace(host)
+			if !sched.Release(host) {
+				logging.Warn("Host has no schedule", "host", host)
+				continue
+			}
+			logging.Info("Released host", "host", host)
+		}
+		return sched.Save()
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(sched.Hosts())
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "HOST\tSTATUS\tFAILURES\tLAST SCAN\tNEXT SCAN\tLAST ERROR")
+	for _, h := range sched.Hosts() {
+		status, next := "ok", h.NextScan.Local().Format(time.DateTime)
+		switch {
+		case h.Quarantined():
+			status, next = "quarantined", "-"
+		case h.Failures > 0:
+			status = "backoff"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\t%s\n", h.Host, status, h.Failures,
+			h.LastScan.Local().Format(time.DateTime), next, h.LastError)
+	}
+	return tw.Flush()
+}
+
+// runPowerState implements the power-state subcommand: it reads the power
+// state of each host from the service root and System resource only. The
+// scanner is always read-only, so the command cannot change a power state
+// even if a future code path tried to.
+func runPowerState(args []string) error {
+	fs := flag.NewFlagSet("power-state", flag.ContinueOnError)
+	f := &flags{}
+	fs.StringVar(&f.configFile, "config", "config.yaml", "Path to configuration file")
+	fs.StringVar(&f.profile, "profile", os.Getenv(defaults.EnvProfile), "Named profile from the config file (env: "+defaults.EnvProfile+")")
+	fs.StringVar(&f.envFile, "env-file", "", "Load KEY=VALUE environment variables from this file before reading the config")
+	fs.StringVar(&f.host, "host", "", "Single host to query (overrides config file)")
+	fs.StringVar(&f.username, "user", "", "Username for single host mode")
+	fs.StringVar(&f.password, "pass", "", "Password for single host mode")
+	only := fs.String("hosts", "", "Comma-separated hosts or names of the config file to query (default: all)")
+	expect := fs.String("expect", "", "Fail unless every host is in this power state (e.g. Off before maintenance)")
+	asJSON := fs.Bool("json", false, "Print the power states as JSON")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage:\n  %s power-state [options]\n\nOptions:\n", os.Args[0])
+		fs.PrintDefaults()
+		fmt.Fprintf(fs.Output(), "\nExample:\n  %s power-state -config config.yaml -hosts web01,web02 -expect Off\n", os.Args[0])
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if f.envFile != "" {
+		if err := loadEnvFile(f.envFile); err != nil {
+			return err
+		}
+	}
+	cfg, err := loadConfiguration(f)
+	if err != nil {
+		return err
+	}
+	redact.AddSecrets(cfg.Secrets()...)
+	cfg.ReadOnly = true
+
+	if *only != "" {
+		servers, err := selectServers(cfg.Servers, strings.Split(*only, ","))
+		if err != nil {
+			return err
+		}
+		cfg.Servers = servers
+	}
+
+	checks := scanner.New(cfg).QueryPowerStates(context.Background())
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(checks); err != nil {
+			return err
+		}
+	} else {
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "HOST\tNAME\tPOWER\tHEALTH\tMODEL\tSERVICE TAG\tLATENCY\tERROR")
+		for _, c := range checks {
+			errMsg := "-"
+			if !c.OK() {
+				errMsg = c.Error.Error()
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", c.Host, dash(c.Name), dash(c.PowerState),
+				dash(c.Health), dash(c.Model), dash(c.ServiceTag), c.Latency.Round(time.Millisecond), errMsg)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+
+	failed, unexpected := 0, 0
+	for _, c := range checks {
+		switch {
+		case !c.OK():
+			failed++
+		case *expect != "" && !strings.EqualFold(c.PowerState, *expect):
+			unexpected++
+		}
+	}
+	if failed > 0 || unexpected > 0 {
+		msg := fmt.Sprintf("%d of %d hosts could not be queried", failed, len(checks))
+		if *expect != "" {
+			msg += fmt.Sprintf(", %d not %s", unexpected, *expect)
+		}
+		return fmt.Errorf("%s", msg)
+	}
+	return nil
+}
+
+// selectServers returns the servers whose host or name is in names.
+func selectServers(servers []config.ServerConfig, names []string) ([]config.ServerConfig, error) {
+	wanted := map[string]bool{}
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" {
+			wanted[name] = true
+		}
+	}
+	var selected []config.ServerConfig
+	found := map[string]bool{}
+	for _, server := range servers {
+		if wanted[server.Host] || (server.Name != "" && wanted[server.Name]) {
+			selected = append(selected, server)
+			found[server.Host], found[server.Name] = true, true
+		}
+	}
+	var missing []string
+	for name := range wanted {
+		if !found[name] {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("not in the config file: %s", strings.Join(missing, ", "))
+	}
+	return selected, nil
+}
+
+// dash returns s, or "-" if it is empty.
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// defaultPruneDays is the age of the last inventory from which prune-report
+// lists a device.
+const defaultPruneDays = 30
+
+// runPruneReport implements the prune-report subcommand: it lists the NetBox
+// devices whose last inventory is older than -days, i.e. that no scan has
+// found recently, for decommission review.
+func runPruneReport(args []string) error {
+	fs := flag.NewFlagSet("prune-report", flag.ContinueOnError)
+	f := &flags{}
+	fs.BoolVar(&f.readOnly, "read-only", false, "Refuse all writes (-tag) at the client layer")
+	fs.StringVar(&f.configFile, "config", "config.yaml", "Path to configuration file")
+	fs.StringVar(&f.profile, "profile", os.Getenv(defaults.EnvProfile), "Named profile from the config file (env: "+defaults.EnvProfile+")")
+	fs.StringVar(&f.envFile, "env-file", "", "Load KEY=VALUE environment variables from this file before reading the config")
+	days := fs.Int("days", defaultPruneDays, "List devices whose last inventory is older than this many days")
+	filter := fs.String("filter", "", "NetBox device filters limiting the devices checked, e.g. manufacturer=dell&role=server")
+	unset := fs.Bool("unset", false, "Also list devices that were never inventoried (combine with -filter)")
+	tag := fs.String("tag", "", "Add this tag (created if needed) to the listed devices")
+	asJSON := fs.Bool("json", false, "Print the devices as JSON")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage:\n  %s prune-report [options]\n\nOptions:\n", os.Args[0])
+		fs.PrintDefaults()
+		fmt.Fprintf(fs.Output(), "\nExample:\n  %s prune-report -config config.yaml -days 60 -filter manufacturer=dell -unset -tag decommission-review\n", os.Args[0])
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *days <= 0 {
+		return fmt.Errorf("-days must be positive")
+	}
+	query, err := url.ParseQuery(*filter)
+	if err != nil {
+		return fmt.Errorf("invalid -filter: %w", err)
+	}
+
+	if f.envFile != "" {
+		if err := loadEnvFile(f.envFile); err != nil {
+			return err
+		}
+	}
+	cfg, err := config.LoadProfile(f.configFile, f.profile)
+	if err != nil {
+		return fmt.Errorf("failed to load config from %s: %w", f.configFile, err)
+	}
+	if !cfg.NetBox.IsEnabled() {
+		return fmt.Errorf("NetBox is not configured (netbox.url and netbox.token)")
+	}
+	redact.AddSecrets(cfg.Secrets()...)
+	applyReadOnly(cfg, f)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	setupSignalHandler(cancel)
+
+	client, err := newNetBoxClient(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	devices, err := client.StaleDevices(ctx, query, now.AddDate(0, 0, -*days), *unset)
+	if err != nil {
+		return fmt.Errorf("failed to list devices: %w", err)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(devices); err != nil {
+			return err
+		}
+	} else {
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "ID\tNAME\tSERIAL\tASSET TAG\tLAST INVENTORY\tAGE (DAYS)")
+		for _, d := range devices {
+			last, age := "never", "-"
+			if d.LastInventory != nil {
+				last = d.LastInventory.Format("2006-01-02")
+				age = fmt.Sprint(d.AgeDays(now))
+			}
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\n", d.ID, dash(d.Name), dash(d.Serial), dash(d.AssetTag), last, age)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+		fmt.Printf("\n%d device(s) not inventoried for %d days\n", len(devices), *days)
+	}
+
+	if *tag != "" && len(devices) > 0 {
+		tagged, err := client.TagDevices(ctx, devices, *tag)
+		if err != nil {
+			return err
+		}
+		logging.Info("Tagged stale devices", "tag", *tag, "tagged", tagged, "already_tagged", len(devices)-tagged)
+	}
+	return nil
+}
+
+// runVerify implements the verify subcommand: it checks each file against its
+// .sig file and fails if any of them was modified or is unsigned.
+func runVerify(args []string) error {
+	fs := flag.NewFlagSet("verify", flag.ContinueOnError)
+	keyPath := fs.String("key", "", "PEM ed25519 public key (default: signing.public_key or signing.private_key from -config)")
+	configPath := fs.String("config", "", "Configuration file to take the signing key from")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage:\n  %s verify [options] file...\n\nOptions:\n", os.Args[0])
+		fs.PrintDefaults()
+		fmt.Fprintf(fs.Output(), "\nExample:\n  %s verify -key signing-key.pub.pem inventory/hardware-inventory.json inventory/hardware-inventory.md\n", os.Args[0])
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return fmt.Errorf("no files to verify")
+	}
+
+	if *keyPath == "" && *configPath != "" {
+		cfg, err := config.Load(*configPath)
+		if err != nil {
+			return fmt.Errorf("failed to load config from %s: %w", *configPath, err)
+		}
+		*keyPath = cfg.Signing.PublicKey
+		if *keyPath == "" {
+			*keyPath = cfg.Signing.PrivateKey
+		}
+	}
+	if *keyPath == "" {
+		fs.Usage()
+		return fmt.Errorf("-key or a -config with a signing key is required")
+	}
+
+	pub, err := signing.LoadPublicKey(*keyPath)
+	if err != nil {
+		return fmt.Errorf("failed to load public key: %w", err)
+	}
+
+	failed := 0
+	for _, path := range fs.Args() {
+		sig, err := signing.VerifyFile(path, pub)
+		if err != nil {
+			failed++
+			fmt.Printf("FAIL  %s: %v\n", path, err)
+			continue
+		}
+		fmt.Printf("OK    %s (key %s, signed %s)\n", path, sig.KeyID, sig.SignedAt.Format(time.RFC3339))
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d files failed verification", failed, fs.NArg())
+	}
+	return nil
+}
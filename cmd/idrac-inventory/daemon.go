@@ -0,0 +1,355 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/braunma/idrac-netbox-importer/internal/grafana"
+	"github.com/braunma/idrac-netbox-importer/internal/health"
+	"github.com/braunma/idrac-netbox-importer/internal/hooks"
+	"github.com/braunma/idrac-netbox-importer/internal/inventory"
+	"github.com/braunma/idrac-netbox-importer/internal/leader"
+	"github.com/braunma/idrac-netbox-importer/internal/output"
+	"github.com/braunma/idrac-netbox-importer/internal/schedule"
+	"github.com/braunma/idrac-netbox-importer/internal/signing"
+	"github.com/braunma/idrac-netbox-importer/pkg/config"
+	"github.com/braunma/idrac-netbox-importer/pkg/defaults"
+	"github.com/braunma/idrac-netbox-importer/pkg/errors"
+	"github.com/braunma/idrac-netbox-importer/pkg/logging"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+	"github.com/braunma/idrac-netbox-importer/pkg/scanner"
+)
+
+// lastScanFile is the file in the state directory holding the latest results.
+const lastScanFile = "last-scan.json"
+
+// runDaemon scans at the configured interval until ctx is cancelled. Each cycle
+// writes the results to the state directory and runs the configured NetBox sync
+// and GitLab export. Failures of a cycle are logged; the daemon keeps running.
+// With leader election, replicas that do not hold the lease skip their cycles.
+// With daemon.events, hosts that push Redfish events are rescanned between
+// cycles.
+func runDaemon(ctx context.Context, cfg *config.Config, f *flags, s *scanner.Scanner) error {
+	interval := cfg.Daemon.GetInterval()
+	if f.interval > 0 {
+		interval = f.interval
+	}
+	stateDir := cfg.Paths.GetStateDir()
+	signer, err := newSigner(cfg)
+	if err != nil {
+		return err
+	}
+
+	// A cycle that runs for more than twice the interval counts as stalled.
+	status := health.New(2 * interval)
+	if cfg.Daemon.Backoff.Enabled {
+		status.Handle("/schedule", scheduleHandler(schedulePath(cfg)))
+	}
+	// The endpoints share one store, which follows the state file
+	store, resultsFile := inventory.New(), filepath.Join(stateDir, lastScanFile)
+	if cfg.Daemon.Grafana {
+		src := grafanaSource(store, resultsFile, cfg.History.GetTrendsPath(stateDir))
+		status.Handle("/grafana/", http.StripPrefix("/grafana", grafana.Handler(src)))
+	}
+	if cfg.Daemon.API {
+		status.Handle("/api/", http.StripPrefix("/api", inventory.Handler(store, resultsFile)))
+	}
+	if addr := cfg.Daemon.HealthListen; addr != "" {
+		go func() {
+			if err := status.ListenAndServe(ctx, addr); err != nil {
+				logging.Error("Health endpoints failed", "addr", addr, "error", err)
+			}
+		}()
+		logging.Info("Serving health endpoints", "addr", addr)
+	}
+
+	le := cfg.Daemon.LeaderElection
+	elector := leader.New(le, le.GetTTL(2*interval))
+	if elector != nil {
+		defer func() {
+			releaseCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			if err := elector.Release(releaseCtx); err != nil {
+				logging.Warn("Failed to release leader lease", "error", err)
+			}
+		}()
+		logging.Info("Leader election enabled",
+			"identity", le.GetIdentity(),
+			"ttl", le.GetTTL(2*interval),
+		)
+	}
+
+	receiver, err := startEvents(ctx, cfg, s)
+	if err != nil {
+		return err
+	}
+
+	logging.Info("Starting daemon mode",
+		"interval", interval,
+		"state_dir", stateDir,
+	)
+
+	for {
+		status.Beat()
+
+		isLeader := true
+		if elector != nil {
+			var err error
+			if isLeader, err = elector.Acquire(ctx); err != nil {
+				logging.Warn("Leader election failed, skipping scan cycle", "error", err)
+			} else if !isLeader {
+				logging.Info("Another replica holds the leader lease, skipping scan cycle")
+			}
+			status.SetLeader(isLeader)
+		}
+
+		var cycleErr error
+		if isLeader {
+			cycleErr = runDaemonCycle(ctx, cfg, f, s, stateDir, signer, interval)
+			if elector != nil && ctx.Err() == nil {
+				// Renew, so the lease outlasts the wait for the next cycle
+				if _, err := elector.Acquire(ctx); err != nil {
+					logging.Warn("Failed to renew leader lease", "error", err)
+				}
+			}
+		}
+		if ctx.Err() != nil {
+			logging.Info("Daemon stopped")
+			return nil
+		}
+		status.CycleDone(isLeader, cycleErr)
+
+		if !waitForCycle(ctx, cfg, f, s, stateDir, signer, receiver, interval, isLeader) {
+			logging.Info("Daemon stopped")
+			return nil
+		}
+	}
+}
+
+// runDaemonCycle runs one scan cycle of the daemon and returns its error.
+// With daemon.backoff, only the hosts that are due are scanned. The limits
+// of daemon.budget apply: no scans in blackout windows, at most max_hosts
+// hosts and no new hosts after max_duration. The results of a cycle that
+// scanned only part of the hosts are merged into the state file, and no
+// fleet trend is recorded for it.
+func runDaemonCycle(ctx context.Context, cfg *config.Config, f *flags, s *scanner.Scanner, stateDir string, signer *signing.Signer, interval time.Duration) error {
+	budget := cfg.Daemon.Budget
+	now := time.Now()
+	allServers := len(cfg.Servers)
+	if until, ok := budget.BlackoutUntil(now); ok {
+		logging.Info("Inside a blackout window, skipping scan cycle",
+			"until", until.Format(time.RFC3339),
+		)
+		return nil
+	}
+
+	var sched *schedule.Scheduler
+	if cfg.Daemon.Backoff.Enabled || budget.MaxHosts > 0 {
+		// Loaded every cycle, so hosts released with the schedule command
+		// are picked up
+		var err error
+		sched, err = schedule.Load(schedulePath(cfg), schedulePolicy(cfg, interval))
+		if err != nil {
+			logging.Warn("Failed to load scan schedule, scanning all hosts", "error", err)
+		} else {
+			due := cfg.Servers
+			if cfg.Daemon.Backoff.Enabled {
+				due = sched.Due(cfg.Servers, now)
+				if skipped := len(cfg.Servers) - len(due); skipped > 0 {
+					logging.Info("Skipping hosts in backoff or quarantine",
+						"due", len(due),
+						"skipped", skipped,
+					)
+				}
+				if len(due) == 0 {
+					return nil
+				}
+			}
+			if limited := sched.Oldest(due, budget.MaxHosts); len(limited) < len(due) {
+				logging.Info("Scan budget reached, deferring hosts to the next cycles",
+					"max_hosts", budget.MaxHosts,
+					"deferred", len(due)-len(limited),
+				)
+				due = limited
+			}
+			cycleCfg := *cfg
+			cycleCfg.Servers = due
+			cfg, s = &cycleCfg, s.ForServers(due)
+		}
+	}
+
+	if deadline := budget.Deadline(now); !deadline.IsZero() {
+		s = s.WithDeadline(deadline)
+	}
+	results, stats := scan(ctx, cfg, s)
+	if ctx.Err() != nil {
+		return nil
+	}
+	deferred := unscanned(cfg.Servers, results)
+	if len(deferred) > 0 {
+		logging.Warn("Scan deadline reached, deferring hosts to the next cycles",
+			"deferred", len(deferred),
+		)
+	}
+	partial := len(cfg.Servers) < allServers || len(deferred) > 0
+	if partial {
+		logging.Debug("Partial cycle, not recording fleet trends")
+	} else {
+		recordTrend(cfg, models.TrendSampleOf(time.Now().UTC(), results))
+	}
+
+	if sched != nil {
+		sched.Record(results, time.Now())
+		if err := sched.Save(); err != nil {
+			logging.Warn("Failed to save scan schedule", "error", err)
+		}
+	}
+
+	prev := previousResults(cfg)
+	summary := hookSummary(stats, "")
+	var err error
+	if partial {
+		err = mergeStateResults(stateDir, results, signer)
+	} else {
+		err = writeStateResults(stateDir, results, stats, signer)
+	}
+	if err != nil {
+		logging.Error("Failed to write state", "error", err)
+	} else {
+		summary.ResultsFile = filepath.Join(stateDir, lastScanFile)
+	}
+	runHooks(ctx, cfg, hooks.PostScan, summary)
+
+	err = publishResults(ctx, cfg, f, results, stats, summary)
+	notifySlack(ctx, cfg, prev, results, stats)
+	if err != nil {
+		logging.Error("Scan cycle failed", "error", err)
+	} else if stats.FailedCount > 0 {
+		err = errors.WithCode(errors.CodeScanFailed, fmt.Sprintf("%d of %d servers failed", stats.FailedCount, stats.TotalServers))
+	}
+	if err != nil {
+		summary.Error = err.Error()
+		summary.ErrorCode = string(errors.CodeOf(err))
+		runHooks(ctx, cfg, hooks.OnFailure, summary)
+	}
+
+	logging.Info("Scan cycle finished",
+		"successful", stats.SuccessfulCount,
+		"failed", stats.FailedCount,
+		"next_scan", time.Now().Add(interval).Format(time.RFC3339),
+	)
+	return err
+}
+
+// schedulePath returns the file of the per-host scan schedule.
+func schedulePath(cfg *config.Config) string {
+	return filepath.Join(cfg.Paths.GetStateDir(), defaults.DefaultScheduleFile)
+}
+
+// unscanned returns the hosts of the servers without a result.
+func unscanned(servers []config.ServerConfig, results []models.ServerInfo) []string {
+	scanned := make(map[string]bool, len(results))
+	for _, res := range results {
+		scanned[res.Host] = true
+	}
+	var hosts []string
+	for _, srv := range servers {
+		if !scanned[srv.Host] {
+			hosts = append(hosts, srv.Host)
+		}
+	}
+	return hosts
+}
+
+// schedulePolicy returns the backoff policy of daemon.backoff. Without
+// backoff the schedule only tracks when hosts were scanned (for
+// daemon.budget.max_hosts), so every host keeps the daemon interval.
+func schedulePolicy(cfg *config.Config, interval time.Duration) schedule.Policy {
+	if !cfg.Daemon.Backoff.Enabled {
+		return schedule.Policy{Interval: interval}
+	}
+	return schedule.Policy{
+		Interval:        interval,
+		MaxInterval:     cfg.Daemon.Backoff.GetMaxInterval(),
+		QuarantineAfter: cfg.Daemon.Backoff.GetQuarantineAfter(),
+	}
+}
+
+// scheduleHandler serves the per-host scan schedule as JSON.
+func scheduleHandler(path string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hosts, err := schedule.ReadFile(path)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if hosts == nil {
+			hosts = []schedule.HostState{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(hosts)
+	})
+}
+
+// writeStateResults atomically writes the scan results as JSON into the state
+// directory and signs them if a signer is given.
+func writeStateResults(stateDir string, results []models.ServerInfo, stats models.CollectionStats, signer *signing.Signer) error {
+	if err := os.MkdirAll(stateDir, 0o750); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := output.NewJSONFormatter(true).Format(&buf, results, stats); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := signer.WriteFile(filepath.Join(stateDir, lastScanFile), buf.Bytes(), 0o600); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	return nil
+}
+
+// loadEnvFile sets environment variables from a KEY=VALUE file. Blank lines and
+// lines starting with # are ignored, values may be quoted. Variables that are
+// already set in the environment are not overridden.
+func loadEnvFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open env file: %w", err)
+	}
+	defer file.Close()
+
+	sc := bufio.NewScanner(file)
+	lineNo := 0
+	for sc.Scan() {
+		lineNo++
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return fmt.Errorf("%s:%d: expected KEY=VALUE", path, lineNo)
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+
+		if _, exists := os.LookupEnv(key); !exists {
+			os.Setenv(key, value)
+		}
+	}
+
+	return sc.Err()
+}
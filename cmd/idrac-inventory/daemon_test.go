@@ -0,0 +1,27 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/braunma/idrac-netbox-importer/internal/signing"
+	"github.com/braunma/idrac-netbox-importer/internal/testutil"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+func TestWriteStateResults_Signed(t *testing.T) {
+	signer := testutil.NewSigner(t)
+	dir := t.TempDir()
+
+	for _, host := range []string{"10.0.0.1", "10.0.0.2"} {
+		results := []models.ServerInfo{{Host: host}}
+		require.NoError(t, writeStateResults(dir, results, models.CollectionStats{TotalServers: 1}, signer))
+
+		sig, err := signing.VerifyFile(filepath.Join(dir, lastScanFile), signer.PublicKey())
+		require.NoError(t, err)
+		assert.Equal(t, lastScanFile, sig.File)
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/braunma/idrac-netbox-importer/internal/doctor"
+	"github.com/braunma/idrac-netbox-importer/pkg/defaults"
+	"github.com/braunma/idrac-netbox-importer/pkg/netbox"
+	"github.com/braunma/idrac-netbox-importer/pkg/redact"
+)
+
+// runDoctor implements the doctor subcommand: it checks the runtime
+// prerequisites of a config and prints a remediation hint per problem.
+func runDoctor(args []string) error {
+	fs := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	f := &flags{}
+	fs.StringVar(&f.configFile, "config", "config.yaml", "Path to configuration file")
+	fs.StringVar(&f.profile, "profile", os.Getenv(defaults.EnvProfile), "Named profile from the config file (env: "+defaults.EnvProfile+")")
+	fs.StringVar(&f.envFile, "env-file", "", "Load KEY=VALUE environment variables from this file before reading the config")
+	samples := fs.Int("samples", doctor.DefaultSamples, "Number of configured targets whose DNS and reachability are checked")
+	asJSON := fs.Bool("json", false, "Print the check results as JSON")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage:\n  %s doctor [options]\n\nOptions:\n", os.Args[0])
+		fs.PrintDefaults()
+		fmt.Fprintf(fs.Output(), "\nExample:\n  %s doctor -config config.yaml -env-file /etc/idrac-inventory/env\n", os.Args[0])
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *samples < 1 {
+		return fmt.Errorf("-samples must be at least 1")
+	}
+
+	var results []doctor.Result
+	if f.envFile != "" {
+		if err := loadEnvFile(f.envFile); err != nil {
+			results = append(results, doctor.Result{Check: "env file", Status: doctor.Fail, Detail: err.Error(),
+				Hint: "fix the path or the KEY=VALUE lines of -env-file"})
+		}
+	}
+	cfg, err := loadConfiguration(f)
+	if err != nil {
+		results = append(results, doctor.Result{Check: "config", Status: doctor.Fail, Detail: err.Error(),
+			Hint: "fix the config file; the other checks need a valid config"})
+	} else {
+		redact.AddSecrets(cfg.Secrets()...)
+		d := doctor.New(cfg)
+		d.Samples = *samples
+		d.NetBox = func(ctx context.Context) error {
+			return netbox.NewClient(cfg.NetBox, netbox.WithHTTPHeaders(cfg.HTTP), netbox.WithReadOnly(true)).TestConnection(ctx)
+		}
+		results = append(results, d.Run(context.Background())...)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(results); err != nil {
+			return err
+		}
+	} else {
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "STATUS\tCHECK\tDETAIL")
+		for _, r := range results {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", strings.ToUpper(string(r.Status)), r.Check, r.Detail)
+			if r.Hint != "" {
+				fmt.Fprintf(tw, "\t\t  hint: %s\n", r.Hint)
+			}
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if failed := doctor.Failed(results); failed > 0 {
+		return fmt.Errorf("%d checks failed", failed)
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/braunma/idrac-netbox-importer/internal/events"
+	"github.com/braunma/idrac-netbox-importer/internal/output"
+	"github.com/braunma/idrac-netbox-importer/internal/signing"
+	"github.com/braunma/idrac-netbox-importer/pkg/config"
+	"github.com/braunma/idrac-netbox-importer/pkg/errors"
+	"github.com/braunma/idrac-netbox-importer/pkg/logging"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+	"github.com/braunma/idrac-netbox-importer/pkg/scanner"
+)
+
+// startEvents starts the Redfish event receiver of daemon mode and registers
+// the event subscriptions on the iDRACs. It returns nil if daemon.events is
+// not enabled. Failed subscriptions are logged, not fatal.
+func startEvents(ctx context.Context, cfg *config.Config, s *scanner.Scanner) (*events.Receiver, error) {
+	ev := cfg.Daemon.Events
+	if !ev.IsEnabled() {
+		return nil, nil
+	}
+
+	hosts := make([]string, 0, len(cfg.Servers))
+	for _, server := range cfg.Servers {
+		hosts = append(hosts, server.Host)
+	}
+	receiver := events.NewReceiver(hosts, events.Filter{
+		MessageIDs: ev.GetMessageIDs(),
+		Severities: ev.GetSeverities(),
+	}, ev.GetDebounce())
+	go func() {
+		if err := receiver.ListenAndServe(ctx, ev.Listen, ev.TLSCert, ev.TLSKey); err != nil {
+			logging.Error("Event receiver failed", "addr", ev.Listen, "error", err)
+		}
+	}()
+	logging.Info("Receiving Redfish events",
+		"addr", ev.Listen,
+		"tls", ev.TLSCert != "",
+		"debounce", ev.GetDebounce(),
+	)
+
+	if ev.Destination == "" {
+		return receiver, nil
+	}
+	servers := cfg.Servers
+	if len(ev.Hosts) > 0 {
+		var err error
+		if servers, err = selectServers(cfg.Servers, ev.Hosts); err != nil {
+			return nil, fmt.Errorf("daemon.events.hosts: %w", err)
+		}
+	}
+	created, failed := 0, 0
+	for _, sub := range s.ForServers(servers).Subscribe(ctx, ev.Destination) {
+		switch {
+		case sub.Error != nil:
+			failed++
+			logging.Warn("Failed to register event subscription", "host", sub.Host, "error", sub.Error)
+		case sub.Created:
+			created++
+		}
+	}
+	logging.Info("Registered event subscriptions",
+		"destination", ev.Destination,
+		"servers", len(servers),
+		"created", created,
+		"failed", failed,
+	)
+	return receiver, nil
+}
+
+// runEventRescan rescans the hosts that sent events, merges their results
+// into the state file and syncs them to NetBox with -sync. Fleet trends and
+// the GitLab export are left to the regular cycles, which see all hosts.
+func runEventRescan(ctx context.Context, cfg *config.Config, f *flags, s *scanner.Scanner, stateDir string, signer *signing.Signer, hosts []string) error {
+	servers, err := selectServers(cfg.Servers, hosts)
+	if err != nil {
+		return err
+	}
+	logging.Info("Rescanning hosts after Redfish events", "hosts", hosts)
+
+	rescanCfg := *cfg
+	rescanCfg.Servers = servers
+	results, stats := scan(ctx, &rescanCfg, s.ForServers(servers))
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	if err := mergeStateResults(stateDir, results, signer); err != nil {
+		logging.Error("Failed to update state", "error", err)
+	}
+
+	if f.syncNetBox && cfg.NetBox.IsEnabled() {
+		if err := runNetBoxSync(ctx, cfg, f.force, results, hookSummary(stats, "")); err != nil {
+			return err
+		}
+	}
+	if stats.FailedCount > 0 {
+		return errors.WithCode(errors.CodeScanFailed, fmt.Sprintf("%d of %d servers failed", stats.FailedCount, stats.TotalServers))
+	}
+	return nil
+}
+
+// mergeStateResults replaces the results of the rescanned hosts in the state
+// file, keeping the statistics of the last full cycle. Without a state file
+// the results are written on their own.
+func mergeStateResults(stateDir string, results []models.ServerInfo, signer *signing.Signer) error {
+	previous, stats, err := output.ReadJSONFile(filepath.Join(stateDir, lastScanFile))
+	switch {
+	case os.IsNotExist(err):
+		stats = models.CollectionStats{TotalServers: len(results)}
+	case err != nil:
+		return err
+	}
+
+	index := make(map[string]int, len(previous))
+	for i, srv := range previous {
+		index[srv.Key()] = i
+	}
+	for _, res := range results {
+		if i, ok := index[res.Key()]; ok {
+			previous[i] = res
+		} else {
+			previous = append(previous, res)
+		}
+	}
+	return writeStateResults(stateDir, previous, stats, signer)
+}
+
+// waitForCycle waits for the next scan cycle, rescanning the hosts of
+// Redfish events in the meantime if this replica scans. Inside a blackout
+// window the next cycle starts when the window ends, if that is before the
+// interval, and event rescans are held until then. It returns false if ctx
+// was cancelled.
+func waitForCycle(ctx context.Context, cfg *config.Config, f *flags, s *scanner.Scanner, stateDir string, signer *signing.Signer, receiver *events.Receiver, interval time.Duration, rescan bool) bool {
+	next := time.After(cfg.Daemon.Budget.CycleWait(time.Now(), interval))
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-next:
+			return true
+		case <-receiver.Ready():
+			hosts := receiver.Take()
+			if !rescan {
+				logging.Info("Not the leader, ignoring Redfish events", "hosts", hosts)
+				continue
+			}
+			if until, ok := cfg.Daemon.Budget.BlackoutUntil(time.Now()); ok {
+				logging.Info("Inside a blackout window, rescanning hosts of Redfish events when it ends",
+					"hosts", hosts,
+					"until", until.Format(time.RFC3339),
+				)
+				receiver.Requeue(hosts, time.Until(until))
+				continue
+			}
+			if err := runEventRescan(ctx, cfg, f, s, stateDir, signer, hosts); err != nil {
+				logging.Error("Event rescan failed", "error", err)
+			}
+		}
+	}
+}
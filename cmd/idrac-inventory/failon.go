@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/errors"
+	"github.com/braunma/idrac-netbox-importer/pkg/logging"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+// Values of -fail-on, from the most to the least lenient. Errors that
+// prevent the run (configuration, output) fail it whatever the value.
+const (
+	failOnNever  = "never"  // nothing else fails the run
+	failOnSync   = "sync"   // NetBox sync and export failures
+	failOnScan   = "scan"   // also servers that failed to scan (default)
+	failOnHealth = "health" // also servers violating the health policy
+)
+
+var failOnValues = []string{failOnNever, failOnSync, failOnScan, failOnHealth}
+
+// validateFailOn checks the value of -fail-on.
+func validateFailOn(v string) error {
+	for _, valid := range failOnValues {
+		if v == valid {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid -fail-on %q (must be one of %s)", v, strings.Join(failOnValues, ", "))
+}
+
+// exitCode logs the error a run ended with and returns the exit code of the
+// run. Failures that -fail-on does not count are logged as warnings and
+// exit with 0.
+func exitCode(failOn string, err error) int {
+	code := errors.ExitCode(err)
+	ignored := false
+	switch {
+	case code == errors.ExitSync:
+		ignored = failOn == failOnNever
+	case errors.CodeOf(err) == errors.CodeScanFailed:
+		ignored = failOn == failOnNever || failOn == failOnSync
+	}
+	if ignored {
+		logging.Warn("Run failed, not counted as a failure with -fail-on",
+			"fail_on", failOn,
+			"error", err,
+			"error_code", errors.CodeOf(err),
+		)
+		return errors.ExitOK
+	}
+	if err != nil {
+		logging.Error("Execution failed", "error", err, "error_code", errors.CodeOf(err), "exit_code", code)
+	}
+	return code
+}
+
+// exitConfigError logs an error in the configuration or flags and exits
+// with the configuration error exit code.
+func exitConfigError(msg string, err error) {
+	logging.Error(msg, "error", err, "error_code", errors.CodeConfig)
+	logging.Sync()
+	os.Exit(errors.ExitConfig)
+}
+
+// healthPolicy counts the servers that violate the health policy for
+// -fail-on health and logs their violations.
+type healthPolicy struct {
+	violating int
+	total     int
+}
+
+// check records the violations of a server.
+func (p *healthPolicy) check(srv models.ServerInfo) {
+	p.total++
+	violations := srv.PolicyViolations()
+	if len(violations) == 0 {
+		return
+	}
+	p.violating++
+	logging.Warn("Server violates the health policy",
+		"host", srv.Host,
+		"service_tag", srv.ServiceTag,
+		"violations", violations,
+	)
+}
+
+// err returns an E_HEALTH_POLICY error if any server violates the policy.
+func (p *healthPolicy) err() error {
+	if p.violating == 0 {
+		return nil
+	}
+	return errors.WithCode(errors.CodeHealthPolicy, fmt.Sprintf("%d of %d servers violate the health policy", p.violating, p.total))
+}
+
+// checkHealthPolicy returns an E_HEALTH_POLICY error if any of the results
+// violates the health policy.
+func checkHealthPolicy(results []models.ServerInfo) error {
+	var p healthPolicy
+	for _, srv := range results {
+		p.check(srv)
+	}
+	return p.err()
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/errors"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+func TestValidateFailOn(t *testing.T) {
+	for _, v := range []string{failOnNever, failOnSync, failOnScan, failOnHealth} {
+		assert.NoError(t, validateFailOn(v), v)
+	}
+	for _, v := range []string{"", "always", "Scan"} {
+		err := validateFailOn(v)
+		require.Error(t, err, v)
+		assert.Contains(t, err.Error(), "never, sync, scan, health")
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	scanErr := errors.WithCode(errors.CodeScanFailed, "2 of 5 servers failed")
+	syncErr := errors.AsSyncError(fmt.Errorf("failed to update device: connection refused"))
+
+	tests := []struct {
+		name string
+		err  error
+		// exit codes with -fail-on never, sync, scan and health
+		want [4]int
+	}{
+		{"success", nil, [4]int{0, 0, 0, 0}},
+		{"scan failure", scanErr, [4]int{0, 0, 1, 1}},
+		{"wrapped scan failure", fmt.Errorf("cycle: %w", scanErr), [4]int{0, 0, 1, 1}},
+		{"sync failure", syncErr, [4]int{0, 3, 3, 3}},
+		{"wrapped sync failure", fmt.Errorf("publish: %w", syncErr), [4]int{0, 3, 3, 3}},
+		{"sync failure with E_SYNC_FAILED", errors.WithCode(errors.CodeSyncFailed, "export failed"), [4]int{0, 3, 3, 3}},
+		// The code of the wrapped error wins for CodeOf, the sync failure for the exit code
+		{"sync lock held", errors.AsSyncError(fmt.Errorf("sync: %w", errors.ErrLocked)), [4]int{0, 3, 3, 3}},
+		{"sync wrapping a scan failure", errors.AsSyncError(scanErr), [4]int{0, 3, 3, 3}},
+		{"health policy", errors.WithCode(errors.CodeHealthPolicy, "1 of 5 servers violate the health policy"), [4]int{4, 4, 4, 4}},
+		{"configuration", errors.NewConfigError("servers", "no servers configured"), [4]int{2, 2, 2, 2}},
+		{"no servers", fmt.Errorf("shard: %w", errors.ErrNoServers), [4]int{2, 2, 2, 2}},
+		{"runtime error", fmt.Errorf("failed to output results: broken pipe"), [4]int{1, 1, 1, 1}},
+	}
+	for _, tt := range tests {
+		for i, failOn := range failOnValues {
+			assert.Equal(t, tt.want[i], exitCode(failOn, tt.err), "%s with -fail-on %s", tt.name, failOn)
+		}
+	}
+
+	// CodeOf precedence: a coded error keeps its code inside a SyncError
+	assert.Equal(t, errors.CodeLocked, errors.CodeOf(errors.AsSyncError(fmt.Errorf("sync: %w", errors.ErrLocked))))
+	assert.Equal(t, errors.CodeScanFailed, errors.CodeOf(errors.AsSyncError(scanErr)))
+	assert.Equal(t, errors.CodeSyncFailed, errors.CodeOf(syncErr))
+}
+
+func TestCheckHealthPolicy(t *testing.T) {
+	healthy := models.ServerInfo{Host: "10.0.0.1", CPUs: []models.CPUInfo{{Socket: "CPU.1", Health: models.HealthOK}}}
+	warning := models.ServerInfo{Host: "10.0.0.2", CPUs: []models.CPUInfo{{Socket: "CPU.1", Health: models.HealthWarning}}}
+	critical := models.ServerInfo{Host: "10.0.0.3", Drives: []models.DriveInfo{{Name: "Disk.Bay.0", Health: models.HealthCritical}}}
+	failed := models.ServerInfo{Host: "10.0.0.4", Error: errors.WithCode(errors.CodeTimeout, "timeout")}
+
+	assert.NoError(t, checkHealthPolicy(nil))
+	assert.NoError(t, checkHealthPolicy([]models.ServerInfo{healthy, warning, failed}),
+		"warnings and failed scans do not violate the policy")
+
+	err := checkHealthPolicy([]models.ServerInfo{healthy, critical, failed})
+	require.Error(t, err)
+	assert.Equal(t, errors.CodeHealthPolicy, errors.CodeOf(err))
+	assert.Equal(t, "1 of 3 servers violate the health policy", err.Error())
+	assert.Equal(t, errors.ExitHealth, exitCode(failOnHealth, err))
+
+	var p healthPolicy
+	for _, srv := range []models.ServerInfo{critical, critical, healthy} {
+		p.check(srv)
+	}
+	assert.Equal(t, healthPolicy{violating: 2, total: 3}, p)
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/braunma/idrac-netbox-importer/internal/grafana"
+	"github.com/braunma/idrac-netbox-importer/internal/history"
+	"github.com/braunma/idrac-netbox-importer/internal/inventory"
+	"github.com/braunma/idrac-netbox-importer/pkg/config"
+	"github.com/braunma/idrac-netbox-importer/pkg/defaults"
+	"github.com/braunma/idrac-netbox-importer/pkg/logging"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+// grafanaSource provides the data of the Grafana endpoints: the results of
+// the last scan from the store, which follows resultsFile, and the trend
+// samples. Missing files yield no data, so dashboards work before the first
+// scan.
+func grafanaSource(store *inventory.Store, resultsFile, trendsFile string) grafana.Source {
+	return grafana.Source{
+		Results: func() ([]models.ServerInfo, models.CollectionStats, error) {
+			if _, err := store.Refresh(resultsFile); err != nil {
+				return nil, models.CollectionStats{}, err
+			}
+			stats, _ := store.Stats()
+			return store.Servers(), stats, nil
+		},
+		Trends: func() ([]models.TrendSample, error) {
+			trends, err := history.LoadTrends(trendsFile)
+			if err != nil {
+				return nil, err
+			}
+			return trends.Samples(), nil
+		},
+	}
+}
+
+// runServe implements the serve command: it serves the Grafana endpoints
+// for the results and trends in the state directory, e.g. next to scans
+// run from cron.
+func runServe(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	f := &flags{}
+	fs.StringVar(&f.configFile, "config", "config.yaml", "Path to configuration file (for paths.state_dir and history.trends_path)")
+	fs.StringVar(&f.profile, "profile", os.Getenv(defaults.EnvProfile), "Named profile from the config file (env: "+defaults.EnvProfile+")")
+	fs.StringVar(&f.stateDir, "state-dir", "", "State directory with "+lastScanFile+" and the trends (instead of -config)")
+	resultsFile := fs.String("results", "", "JSON results to serve (default: "+lastScanFile+" in the state directory)")
+	listen := fs.String("listen", ":8082", "Address to listen on")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage:\n  %s serve [options]\n\nOptions:\n", os.Args[0])
+		fs.PrintDefaults()
+		fmt.Fprintf(fs.Output(), "\nAdd http://<host>:8082/ as a JSON or Infinity datasource in Grafana, e.g.:\n  %s serve -config config.yaml -listen :8082\n", os.Args[0])
+		fmt.Fprintf(fs.Output(), "\nThe latest result of every host is served as JSON under /api/servers and /api/stats.\n")
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	stateDir, trendsFile := f.stateDir, config.HistoryConfig{}.GetTrendsPath(f.stateDir)
+	if stateDir == "" {
+		cfg, err := config.LoadProfile(f.configFile, f.profile)
+		if err != nil {
+			return fmt.Errorf("failed to load config from %s: %w", f.configFile, err)
+		}
+		stateDir = cfg.Paths.GetStateDir()
+		trendsFile = cfg.History.GetTrendsPath(stateDir)
+	}
+	if *resultsFile == "" {
+		*resultsFile = filepath.Join(stateDir, lastScanFile)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	setupSignalHandler(cancel)
+
+	store := inventory.New()
+	mux := http.NewServeMux()
+	mux.Handle("/api/", http.StripPrefix("/api", inventory.Handler(store, *resultsFile)))
+	mux.Handle("/", grafana.Handler(grafanaSource(store, *resultsFile, trendsFile)))
+	srv := &http.Server{Addr: *listen, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		srv.Close()
+	}()
+
+	logging.Info("Serving Grafana and inventory API endpoints",
+		"addr", *listen,
+		"results", *resultsFile,
+		"trends", trendsFile,
+	)
+	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
@@ -3,19 +3,41 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
-
-	"idrac-inventory/internal/config"
-	"idrac-inventory/internal/gitlab"
-	"idrac-inventory/internal/models"
-	"idrac-inventory/internal/netbox"
-	"idrac-inventory/internal/output"
-	"idrac-inventory/internal/scanner"
-	"idrac-inventory/pkg/logging"
+	"time"
+
+	"github.com/braunma/idrac-netbox-importer/internal/annotations"
+	"github.com/braunma/idrac-netbox-importer/internal/firmware"
+	"github.com/braunma/idrac-netbox-importer/internal/gitlab"
+	"github.com/braunma/idrac-netbox-importer/internal/golden"
+	"github.com/braunma/idrac-netbox-importer/internal/history"
+	"github.com/braunma/idrac-netbox-importer/internal/hooks"
+	"github.com/braunma/idrac-netbox-importer/internal/leader"
+	"github.com/braunma/idrac-netbox-importer/internal/output"
+	"github.com/braunma/idrac-netbox-importer/internal/placement"
+	"github.com/braunma/idrac-netbox-importer/internal/regression"
+	"github.com/braunma/idrac-netbox-importer/internal/service"
+	"github.com/braunma/idrac-netbox-importer/internal/signing"
+	"github.com/braunma/idrac-netbox-importer/internal/terminal"
+	"github.com/braunma/idrac-netbox-importer/internal/warranty"
+	"github.com/braunma/idrac-netbox-importer/pkg/audit"
+	"github.com/braunma/idrac-netbox-importer/pkg/catalog"
+	"github.com/braunma/idrac-netbox-importer/pkg/config"
+	"github.com/braunma/idrac-netbox-importer/pkg/defaults"
+	"github.com/braunma/idrac-netbox-importer/pkg/errors"
+	"github.com/braunma/idrac-netbox-importer/pkg/logging"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+	"github.com/braunma/idrac-netbox-importer/pkg/netbox"
+	"github.com/braunma/idrac-netbox-importer/pkg/redact"
+	"github.com/braunma/idrac-netbox-importer/pkg/runid"
+	"github.com/braunma/idrac-netbox-importer/pkg/scanner"
+	"github.com/braunma/idrac-netbox-importer/pkg/units"
 )
 
 // Build information, set via ldflags.
@@ -25,10 +47,18 @@ var (
 	GitCommit = "unknown"
 )
 
+// defaultSlowestHosts is the slow-host report length for -report slowest without -slowest.
+const defaultSlowestHosts = 10
+
+// defaultCertDays is the expiry window of -report certs.
+const defaultCertDays = 30
+
 // CLI flags
 type flags struct {
 	// Config
 	configFile string
+	profile    string // named profile from the config file
+	targets    string // CSV file of servers, overrides targets
 
 	// Single server mode
 	host     string
@@ -39,10 +69,23 @@ type flags struct {
 	outputFormat string
 	verbose      bool
 	noColor      bool
+	wide         bool   // full, untruncated console and table output
+	report       string // analysis report printed instead of the server list
+	slowest      int    // number of hosts in the slow-host report
+	certDays     int    // expiry window of the certificate report
+	compress     bool   // gzip -output json
+	outputDir    string // write -output json as chunked files into this directory
+	chunkSize    int    // servers per chunk file
+	includeRaw   bool   // attach the raw Redfish responses to -output json
+	stream       bool   // process results as they complete instead of buffering
 
 	// Actions
 	syncNetBox          bool
+	force               bool // sync even above netbox.max_changes/max_creates
 	validateConnections bool
+	readOnly            bool   // refuse all writes (NetBox, git push, iDRAC)
+	strictSchema        bool   // validate iDRAC responses against the Redfish schemas
+	failOn              string // what fails the run: never, sync, scan, health
 
 	// GitLab export — write an aggregated report into a local git repo.
 	// The report is always aggregated when this flag is used.
@@ -50,6 +93,23 @@ type flags struct {
 	gitlabBranch string // branch to commit to (default: "main")
 	gitlabDir    string // sub-directory for inventory files (default: "inventory")
 	gitlabPush   bool   // push to remote after committing
+	gitlabBeat   bool   // commit a heartbeat even if the inventory is unchanged
+
+	// Daemon mode
+	daemon       bool
+	interval     time.Duration // overrides daemon.interval
+	stateDir     string        // overrides paths.state_dir
+	healthListen string        // overrides daemon.health_listen
+	envFile      string        // KEY=VALUE file loaded into the environment
+
+	// Enrichment
+	placementFile   string // overrides placement.file
+	annotationsFile string // overrides annotations.file
+	goldenFile      string // overrides golden.file
+
+	// Sharding
+	shard   string // overrides shard, e.g. "2/5"
+	shardBy string // overrides shard_by
 
 	// Misc
 	version  bool
@@ -57,6 +117,15 @@ type flags struct {
 }
 
 func main() {
+	defaults.Version = Version
+
+	// Subcommands have their own flag sets
+	if len(os.Args) > 1 {
+		if cmd, ok := commands[os.Args[1]]; ok {
+			os.Exit(runCommand(cmd, os.Args[2:]))
+		}
+	}
+
 	f := parseFlags()
 
 	if f.version {
@@ -65,19 +134,54 @@ func main() {
 	}
 
 	// Initialize logging
-	if err := logging.Init(logging.Config{
-		Level:  f.logLevel,
-		Format: "console",
-	}); err != nil {
+	if err := logging.Init(loggingConfig(f, nil)); err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to initialize logging: %v\n", err)
 		os.Exit(1)
 	}
 	defer logging.Sync()
 
+	if err := validateFailOn(f.failOn); err != nil {
+		exitConfigError("Invalid flag", err)
+	}
+	if f.envFile != "" {
+		if err := loadEnvFile(f.envFile); err != nil {
+			exitConfigError("Failed to load env file", err)
+		}
+	}
+
 	// Load configuration
 	cfg, err := loadConfiguration(f)
 	if err != nil {
-		logging.Fatal("Configuration error", "error", err)
+		exitConfigError("Configuration error", err)
+	}
+	redact.AddSecrets(cfg.Secrets()...)
+	if f.stateDir != "" {
+		cfg.Paths.StateDir = f.stateDir
+	}
+	if f.healthListen != "" {
+		cfg.Daemon.HealthListen = f.healthListen
+	}
+	if f.placementFile != "" {
+		cfg.Placement.File = f.placementFile
+	}
+	if f.annotationsFile != "" {
+		cfg.Annotations.File = f.annotationsFile
+	}
+	if f.goldenFile != "" {
+		cfg.Golden.File = f.goldenFile
+	}
+	cfg.HTTP.StrictSchema = cfg.HTTP.StrictSchema || f.strictSchema
+	cfg.Output.Raw.Enabled = cfg.Output.Raw.Enabled || f.includeRaw
+	applyReadOnly(cfg, f)
+	applyLoggingConfig(cfg, f)
+	if err := applyNetBoxTargets(context.Background(), cfg); err != nil {
+		logging.Fatal("Failed to load targets from NetBox", "error", err)
+	}
+	if err := applyShard(cfg, f); err != nil {
+		exitConfigError("Invalid shard", err)
+	}
+	if err := loadPlugins(cfg); err != nil {
+		logging.Fatal("Failed to load collector plugin", "error", err)
 	}
 
 	// Create context with signal handling
@@ -86,10 +190,12 @@ func main() {
 
 	setupSignalHandler(cancel)
 
-	// Run the appropriate action
-	if err := run(ctx, cfg, f); err != nil {
-		logging.Error("Execution failed", "error", err)
-		os.Exit(1)
+	// Run the appropriate action, as a Windows service if started by the
+	// service control manager
+	err = service.Run(ctx, func(ctx context.Context) error { return run(ctx, cfg, f) })
+	if code := exitCode(f.failOn, err); code != errors.ExitOK {
+		logging.Sync()
+		os.Exit(code)
 	}
 }
 
@@ -98,6 +204,8 @@ func parseFlags() *flags {
 
 	// Config
 	flag.StringVar(&f.configFile, "config", "config.yaml", "Path to configuration file")
+	flag.StringVar(&f.profile, "profile", os.Getenv(defaults.EnvProfile), "Named profile from the config file (env: "+defaults.EnvProfile+")")
+	flag.StringVar(&f.targets, "targets", "", "CSV file of servers (columns host, name, username, password, group), added to those of the config file (overrides targets)")
 
 	// Single server mode
 	flag.StringVar(&f.host, "host", "", "Single host to scan (overrides config file)")
@@ -105,19 +213,49 @@ func parseFlags() *flags {
 	flag.StringVar(&f.password, "pass", "", "Password for single host mode")
 
 	// Output options
-	flag.StringVar(&f.outputFormat, "output", "console", "Output format: console, json, table, csv")
+	flag.StringVar(&f.outputFormat, "output", "console", "Output format: console, json, table, csv, junit")
 	flag.BoolVar(&f.verbose, "verbose", false, "Show detailed output")
-	flag.BoolVar(&f.noColor, "no-color", false, "Disable colored output")
+	flag.BoolVar(&f.noColor, "no-color", false, "Disable colored output and emojis (NO_COLOR disables colors only)")
+	flag.BoolVar(&f.wide, "wide", false, "Do not truncate console and table output to the terminal width")
+	flag.StringVar(&f.report, "report", "", "Print an analysis report instead of the server list: spares, capacity, duplicates, slowest, certs, licenses, generations, compliance, firmware, nvme, accounts, capabilities (format via -output: console, csv, markdown, json)")
+	flag.IntVar(&f.certDays, "cert-days", defaultCertDays, "Expiry window in days for -report certs")
+	flag.IntVar(&f.slowest, "slowest", 0, "Print the N slowest hosts and their dominant collector phase (shorthand for -report slowest)")
+	flag.BoolVar(&f.compress, "compress", false, "Gzip-compress -output json (to stdout, or each chunk with -output-dir)")
+	flag.StringVar(&f.outputDir, "output-dir", "", "Write -output json as chunk files plus "+output.ChunkIndexFile+" into this directory instead of stdout")
+	flag.IntVar(&f.chunkSize, "chunk-size", defaults.DefaultOutputChunkSize, "Maximum servers per chunk file with -output-dir")
+	flag.BoolVar(&f.includeRaw, "include-raw", false, "Attach the Redfish responses of each host to -output json under raw, pruned and limited by output.raw (overrides output.raw.enabled)")
+	flag.BoolVar(&f.stream, "stream", false, "Output, sync and aggregate each host as its scan completes instead of buffering all results (console, json, csv)")
 
 	// Actions
 	flag.BoolVar(&f.syncNetBox, "sync", false, "Sync results to NetBox")
-	flag.BoolVar(&f.validateConnections, "validate", false, "Only validate connections, don't collect inventory")
+	flag.BoolVar(&f.force, "force", false, "Sync even if it would exceed netbox.max_changes or netbox.max_creates")
+	flag.BoolVar(&f.readOnly, "read-only", false, "Refuse all writes (NetBox, git push, iDRAC) at the client layer, regardless of config")
+	flag.BoolVar(&f.validateConnections, "validate", false, "Only validate connections (Redfish version, firmware, TLS cert expiry, latency); format via -output")
+	flag.BoolVar(&f.strictSchema, "strict-schema", false, "Validate iDRAC responses against the bundled Redfish schemas and log violations per host (overrides http.strict_schema)")
+	flag.StringVar(&f.failOn, "fail-on", failOnScan, "What fails the run (non-zero exit code): never, sync (NetBox sync or export failed), scan (also servers failed to scan), health (also health policy violations)")
 
 	// GitLab export
 	flag.StringVar(&f.gitlabRepo, "gitlab-repo", "", "Path to local git repository; triggers aggregated export")
 	flag.StringVar(&f.gitlabBranch, "gitlab-branch", "main", "Git branch to commit the inventory to")
 	flag.StringVar(&f.gitlabDir, "gitlab-dir", "inventory", "Sub-directory inside the repo for inventory files")
 	flag.BoolVar(&f.gitlabPush, "gitlab-push", false, "Push to the remote after committing")
+	flag.BoolVar(&f.gitlabBeat, "gitlab-heartbeat", false, "Commit a timestamped heartbeat even if the inventory is unchanged")
+
+	// Daemon mode
+	flag.BoolVar(&f.daemon, "daemon", false, "Run continuously, scanning at the configured interval (see install-service)")
+	flag.DurationVar(&f.interval, "interval", 0, "Scan interval in daemon mode (overrides daemon.interval, default 1h)")
+	flag.StringVar(&f.stateDir, "state-dir", "", "Directory for persistent state (default: XDG state dir)")
+	flag.StringVar(&f.healthListen, "health-listen", "", "Serve /healthz and /readyz on this address in daemon mode, e.g. :8081 (overrides daemon.health_listen)")
+	flag.StringVar(&f.envFile, "env-file", "", "Load KEY=VALUE environment variables from this file before reading the config")
+
+	// Enrichment
+	flag.StringVar(&f.placementFile, "placement", "", "CSV/YAML file mapping service tags to site, rack, position and tenant (overrides placement.file)")
+	flag.StringVar(&f.annotationsFile, "annotations", "", "CSV/YAML file of notes per host or service tag (overrides annotations.file)")
+	flag.StringVar(&f.goldenFile, "golden", "", "YAML file of expected builds per model or NetBox device role; deviations are reported (overrides golden.file)")
+
+	// Sharding
+	flag.StringVar(&f.shard, "shard", os.Getenv(defaults.EnvShard), "Scan only shard K of N (e.g. 2/5) so several instances split the servers; merge the JSON results with the merge command (env: "+defaults.EnvShard+")")
+	flag.StringVar(&f.shardBy, "shard-by", "", "Shard assignment: hash (of the host, stable when servers change) or index (position in the server list)")
 
 	// Misc
 	flag.BoolVar(&f.version, "version", false, "Show version information")
@@ -126,7 +264,9 @@ func parseFlags() *flags {
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "iDRAC Hardware Inventory Tool\n\n")
 		fmt.Fprintf(os.Stderr, "Usage:\n")
-		fmt.Fprintf(os.Stderr, "  %s [options]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s [options]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s <command> [options]\n\n", os.Args[0])
+		printCommands(os.Stderr)
 		fmt.Fprintf(os.Stderr, "Options:\n")
 		flag.PrintDefaults()
 		fmt.Fprintf(os.Stderr, "\nExamples:\n")
@@ -138,8 +278,29 @@ func parseFlags() *flags {
 		fmt.Fprintf(os.Stderr, "  %s -config config.yaml -sync\n\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  # Output as JSON\n")
 		fmt.Fprintf(os.Stderr, "  %s -config config.yaml -output json\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  # Compressed JSON, or chunks of 500 servers with an index file\n")
+		fmt.Fprintf(os.Stderr, "  %s -config config.yaml -output json -compress > results.json.gz\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -config config.yaml -output json -output-dir results/ -chunk-size 500\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  # Stream JSON and sync to NetBox as hosts complete (bounded memory for large fleets)\n")
+		fmt.Fprintf(os.Stderr, "  %s -config config.yaml -stream -output json -sync > results.json\n\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  # Aggregated console view (group identical hardware)\n")
 		fmt.Fprintf(os.Stderr, "  %s -config config.yaml -output aggregate\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  # Spare-part demand report as CSV\n")
+		fmt.Fprintf(os.Stderr, "  %s -config config.yaml -report spares -output csv\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  # Expansion headroom (free DIMM slots, drive bays, PCIe slots)\n")
+		fmt.Fprintf(os.Stderr, "  %s -config config.yaml -report capacity\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  # Ten slowest hosts and which collector phase dominated\n")
+		fmt.Fprintf(os.Stderr, "  %s -config config.yaml -slowest 10\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  # iDRAC certificates expiring within 60 days\n")
+		fmt.Fprintf(os.Stderr, "  %s -config config.yaml -report certs -cert-days 60\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  # NVMe drives by wear (life and endurance left, PCIe link)\n")
+		fmt.Fprintf(os.Stderr, "  %s -config config.yaml -report nvme\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  # iDRACs with risky accounts (needs accounts.audit)\n")
+		fmt.Fprintf(os.Stderr, "  %s -config config.yaml -report accounts\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  # Scan the lab environment defined under profiles.lab\n")
+		fmt.Fprintf(os.Stderr, "  %s -config config.yaml -profile lab\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  # Sync previously saved JSON results to NetBox without rescanning\n")
+		fmt.Fprintf(os.Stderr, "  %s sync -from-file results.json -config config.yaml\n\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  # Export aggregated report to a local GitLab repo\n")
 		fmt.Fprintf(os.Stderr, "  %s -config config.yaml -gitlab-repo /path/to/repo\n\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  # Export and push to remote\n")
@@ -148,6 +309,10 @@ func parseFlags() *flags {
 
 	flag.Parse()
 
+	if f.slowest > 0 && f.report == "" {
+		f.report = "slowest"
+	}
+
 	return f
 }
 
@@ -170,12 +335,13 @@ func loadConfiguration(f *flags) (*config.Config, error) {
 		"file", f.configFile,
 	)
 
-	cfg, err := config.Load(f.configFile)
+	cfg, err := config.LoadProfileTargets(f.configFile, f.profile, f.targets)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load config from %s: %w", f.configFile, err)
 	}
 
 	logging.Info("Configuration loaded",
+		"profile", cfg.Profile,
 		"servers", len(cfg.Servers),
 		"concurrency", cfg.Concurrency,
 		"netbox_enabled", cfg.NetBox.IsEnabled(),
@@ -184,6 +350,155 @@ func loadConfiguration(f *flags) (*config.Config, error) {
 	return cfg, nil
 }
 
+// loggingConfig builds the logging configuration from the flags and, once it
+// has been loaded, the logging section of the config file (nil before).
+func loggingConfig(f *flags, cfg *config.Config) logging.Config {
+	lc := logging.Config{
+		Level:  f.logLevel,
+		Format: "console",
+		RunID:  runid.Current(),
+	}
+	if cfg == nil {
+		return lc
+	}
+
+	l := cfg.Logging
+	if l.Stdout {
+		lc.OutputPaths = []string{"stdout"}
+	}
+	lc.File = logging.FileConfig{
+		Path:      l.File.Path,
+		ErrorPath: l.File.ErrorPath,
+		Rotation: logging.RotationConfig{
+			MaxSizeMB:  l.File.MaxSizeMB,
+			Every:      l.File.GetRotateEvery(),
+			MaxBackups: l.File.MaxBackups,
+			MaxAgeDays: l.File.MaxAgeDays,
+		},
+	}
+	lc.Syslog = logging.SyslogConfig{
+		Network:  l.Syslog.Network,
+		Address:  l.Syslog.Address,
+		Facility: l.Syslog.Facility,
+		Tag:      l.Syslog.Tag,
+	}
+	lc.Journald = logging.JournaldConfig{
+		Enabled:    l.Journald.Enabled,
+		Identifier: l.Journald.Identifier,
+	}
+	lc.DebugSampling = logging.SamplingConfig{
+		Initial:    l.DebugSampling.Initial,
+		Thereafter: l.DebugSampling.Thereafter,
+	}
+	lc.InfoSampling = logging.SamplingConfig{
+		Initial:    l.InfoSampling.Initial,
+		Thereafter: l.InfoSampling.Thereafter,
+	}
+	return lc
+}
+
+// applyLoggingConfig re-initialises logging with the log targets and
+// sampling from the config file, which are only known once it has been
+// loaded.
+func applyLoggingConfig(cfg *config.Config, f *flags) {
+	l := cfg.Logging
+	if !l.File.IsEnabled() && !l.Stdout && !l.Syslog.IsEnabled() && !l.Journald.Enabled &&
+		!l.DebugSampling.IsEnabled() && !l.InfoSampling.IsEnabled() {
+		return
+	}
+	if err := logging.Reinit(loggingConfig(f, cfg)); err != nil {
+		logging.Fatal("Failed to configure logging", "error", err)
+	}
+}
+
+// applyReadOnly enables read-only mode if requested by -read-only or read_only.
+func applyReadOnly(cfg *config.Config, f *flags) {
+	cfg.ReadOnly = cfg.ReadOnly || f.readOnly
+	if cfg.ReadOnly {
+		logging.Warn("Read-only mode: NetBox writes, git push and iDRAC writes are disabled")
+	}
+}
+
+// applyNetBoxTargets adds the NetBox devices selected by netbox.targets to
+// the servers. Hosts that are configured already keep their settings.
+func applyNetBoxTargets(ctx context.Context, cfg *config.Config) error {
+	t := cfg.NetBox.Targets
+	if !t.IsEnabled() {
+		return nil
+	}
+	query, err := t.Query()
+	if err != nil {
+		return err
+	}
+	client, err := newNetBoxClient(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	targets, skipped, err := client.Targets(ctx, query, t.GetAddress())
+	if err != nil {
+		return err
+	}
+
+	configured := make(map[string]bool, len(cfg.Servers))
+	for _, srv := range cfg.Servers {
+		configured[srv.Host] = true
+	}
+	added := 0
+	for _, target := range targets {
+		if configured[target.Address] {
+			continue
+		}
+		configured[target.Address] = true
+		cfg.Servers = append(cfg.Servers, t.Server(target.Address, target.Name))
+		added++
+	}
+	logging.Info("Loaded targets from NetBox",
+		"filter", t.Filter,
+		"devices", len(targets),
+		"added", added,
+		"without_address", skipped,
+	)
+
+	if len(cfg.Servers) == 0 {
+		return fmt.Errorf("no servers configured and no NetBox devices with %s match %q", t.GetAddress(), t.Filter)
+	}
+	return nil
+}
+
+// applyShard limits the servers to the shard selected by -shard or shard.
+// cfg.Shard is normalized to "K/N", or cleared if all servers are scanned.
+func applyShard(cfg *config.Config, f *flags) error {
+	if f.host != "" {
+		cfg.Shard = ""
+		return nil
+	}
+	if f.shard != "" {
+		cfg.Shard = f.shard
+	}
+	if f.shardBy != "" {
+		cfg.ShardBy = f.shardBy
+	}
+	shard, err := config.ParseShard(cfg.Shard, cfg.ShardBy)
+	if err != nil {
+		return err
+	}
+	if shard.IsAll() {
+		cfg.Shard = ""
+		return nil
+	}
+
+	total := len(cfg.Servers)
+	cfg.Servers = shard.Filter(cfg.Servers)
+	cfg.Shard = shard.String()
+	logging.Info("Scanning shard",
+		"shard", cfg.Shard,
+		"by", shard.By,
+		"servers", len(cfg.Servers),
+		"total", total,
+	)
+	return nil
+}
+
 func setupSignalHandler(cancel context.CancelFunc) {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
@@ -198,25 +513,452 @@ func setupSignalHandler(cancel context.CancelFunc) {
 }
 
 func run(ctx context.Context, cfg *config.Config, f *flags) error {
-	s := scanner.New(cfg)
+	s := scanner.New(cfg).WithAuditLog(audit.New(cfg.Audit.Path, cfg.Audit.Actor))
 
 	// Validate connections mode
 	if f.validateConnections {
-		return runValidateConnections(ctx, s)
+		return runValidateConnections(ctx, cfg, f, s)
+	}
+
+	// Without daemon mode, leader election decides whether this run scans
+	// at all (e.g. replicas of a Kubernetes CronJob)
+	if !f.daemon && cfg.Daemon.LeaderElection.IsEnabled() {
+		isLeader, err := acquireRunLease(ctx, cfg.Daemon.LeaderElection)
+		if err != nil {
+			return err
+		}
+		if !isLeader {
+			logging.Info("Another replica holds the leader lease, skipping scan")
+			return nil
+		}
 	}
 
-	// Scan all servers
+	// Streaming mode: consumers receive results as scans complete
+	if f.stream {
+		return runStream(ctx, cfg, f, s)
+	}
+
+	// Daemon mode: scan repeatedly until interrupted
+	if f.daemon {
+		return runDaemon(ctx, cfg, f, s)
+	}
+
+	results, stats := scan(ctx, cfg, s)
+	recordTrend(cfg, models.TrendSampleOf(time.Now().UTC(), results))
+	prev := previousResults(cfg)
+
+	// Hooks get the results as a file in the state directory, which the
+	// next Slack summary compares with
+	summary := hookSummary(stats, "")
+	if cfg.Hooks.IsEnabled() || cfg.Notify.Slack.Enabled {
+		signer, err := newSigner(cfg)
+		if err == nil {
+			err = writeStateResults(cfg.Paths.GetStateDir(), results, stats, signer)
+		}
+		if err != nil {
+			logging.Warn("Failed to write results for hooks", "error", err)
+		} else {
+			summary.ResultsFile = filepath.Join(cfg.Paths.GetStateDir(), lastScanFile)
+		}
+	}
+	runHooks(ctx, cfg, hooks.PostScan, summary)
+
+	err := outputAndPublish(ctx, cfg, f, results, stats, summary)
+	notifySlack(ctx, cfg, prev, results, stats)
+	if err != nil {
+		summary.Error = err.Error()
+		summary.ErrorCode = string(errors.CodeOf(err))
+		runHooks(ctx, cfg, hooks.OnFailure, summary)
+	}
+	return err
+}
+
+// acquireRunLease takes the leader lease for a single run. The lease is not
+// released afterwards: it covers the schedule period, so replicas started
+// for the same schedule skip their run.
+func acquireRunLease(ctx context.Context, le config.LeaderElectionConfig) (bool, error) {
+	if le.TTL == "" {
+		return false, fmt.Errorf("daemon.leader_election.ttl is required outside daemon mode (set it below the schedule period)")
+	}
+	isLeader, err := leader.New(le, le.GetTTL(0)).Acquire(ctx)
+	if err != nil {
+		return false, fmt.Errorf("leader election: %w", err)
+	}
+	return isLeader, nil
+}
+
+// outputAndPublish prints the results of a one-shot scan, runs the NetBox
+// sync and GitLab export, and fails if any server failed or, with -fail-on
+// health, violates the health policy.
+func outputAndPublish(ctx context.Context, cfg *config.Config, f *flags, results []models.ServerInfo, stats models.CollectionStats, summary hooks.Summary) error {
+	// Output results (or the requested analysis report)
+	if f.report != "" {
+		if err := outputReport(f, cfg, results); err != nil {
+			return fmt.Errorf("failed to output report: %w", err)
+		}
+	} else if err := outputResults(f, cfg, results, stats); err != nil {
+		return fmt.Errorf("failed to output results: %w", err)
+	}
+
+	if err := publishResults(ctx, cfg, f, results, stats, summary); err != nil {
+		return err
+	}
+
+	// Return error if any servers failed
+	if stats.FailedCount > 0 {
+		return errors.WithCode(errors.CodeScanFailed, fmt.Sprintf("%d of %d servers failed", stats.FailedCount, stats.TotalServers))
+	}
+
+	if f.failOn == failOnHealth {
+		return checkHealthPolicy(results)
+	}
+	return nil
+}
+
+// hookSummary builds the hook environment of a run from its statistics.
+func hookSummary(stats models.CollectionStats, resultsFile string) hooks.Summary {
+	return hooks.Summary{
+		RunID:       runid.Current(),
+		ResultsFile: resultsFile,
+		Total:       stats.TotalServers,
+		Successful:  stats.SuccessfulCount,
+		Failed:      stats.FailedCount,
+		Stale:       stats.StaleCount,
+
+		FailureCodes: stats.FailureCodesSummary(),
+	}
+}
+
+// runHooks runs the configured hooks of an event. Hook failures are logged
+// and do not fail the run.
+func runHooks(ctx context.Context, cfg *config.Config, event hooks.Event, summary hooks.Summary) {
+	runner := hooks.New(cfg.Hooks)
+	if !runner.Has(event) {
+		return
+	}
+	if err := runner.Run(ctx, event, summary); err != nil {
+		logging.Error("Hook failed", "event", event, "error", err)
+	}
+}
+
+// scan runs a full inventory scan and logs post-scan warnings.
+func scan(ctx context.Context, cfg *config.Config, s *scanner.Scanner) ([]models.ServerInfo, models.CollectionStats) {
 	logging.Info("Starting inventory scan",
 		"server_count", len(cfg.Servers),
 	)
 
 	results, stats := s.ScanAll(ctx)
+	stats.Shard = cfg.Shard
+	applyWarranty(ctx, cfg, results)
 
-	// Output results
-	if err := outputResults(f, results, stats); err != nil {
-		return fmt.Errorf("failed to output results: %w", err)
+	if cfg.History.Enabled {
+		var regs []regression.Regression
+		results, stats.StaleCount, regs = applyHistory(cfg.History.GetPath(cfg.Paths.GetStateDir()), results)
+		reportRegressions(ctx, cfg, stats, regs)
+	}
+	applyFiles(cfg, results)
+	checkGolden(ctx, cfg, results)
+	checkFirmware(cfg, results)
+
+	for _, dup := range models.FindDuplicates(results) {
+		logging.Warn("Duplicate hardware detected (only existing NetBox devices are updated)",
+			"kind", dup.Kind,
+			"value", dup.Value,
+			"hosts", dup.Hosts,
+		)
 	}
 
+	return results, stats
+}
+
+// applyWarranty sets the manufacture date of the Dell servers whose iDRAC
+// reported none to their ship date from the warranty API, if enabled. If
+// the API fails, the servers it did not answer for keep no date.
+func applyWarranty(ctx context.Context, cfg *config.Config, results []models.ServerInfo) {
+	if !cfg.Warranty.Enabled {
+		return
+	}
+	updated, err := warranty.New(cfg.Warranty).Apply(ctx, results)
+	if err != nil {
+		logging.Warn("Warranty lookup failed, reporting without ship dates", "error", err)
+	}
+	if updated > 0 {
+		logging.Info("Applied warranty ship dates", "servers", updated)
+	}
+}
+
+// applyHistory substitutes failed hosts with their last known good inventory
+// and records the successful ones, returning the health regressions of the
+// successful hosts against their previous inventory. Without a readable
+// history file the results are returned unchanged.
+func applyHistory(path string, results []models.ServerInfo) ([]models.ServerInfo, int, []regression.Regression) {
+	store, err := history.Load(path)
+	if err != nil {
+		logging.Warn("Failed to load history, reporting failed hosts without stale data", "error", err)
+		return results, 0, nil
+	}
+
+	var regs []regression.Regression
+	for _, res := range results {
+		if prev, ok := store.Last(res.Key()); ok && res.Error == nil {
+			regs = append(regs, regression.Detect(prev, res)...)
+		}
+	}
+
+	merged, stale := store.Apply(results)
+	for _, srv := range merged {
+		if srv.Stale {
+			logging.Warn("Host failed, reporting last known good inventory",
+				"host", srv.Host,
+				"stale_since", srv.StaleSince.Format(time.RFC3339),
+				"error", srv.StaleError,
+			)
+		}
+	}
+
+	if err := store.Save(); err != nil {
+		logging.Warn("Failed to save history", "error", err)
+	}
+	return merged, stale, regs
+}
+
+// recordTrend adds the fleet totals of a scan to the trend file if history
+// is enabled. Sharded runs only see part of the fleet and are not recorded.
+// An unreadable trend file is left alone rather than replaced.
+func recordTrend(cfg *config.Config, sample models.TrendSample) {
+	if !cfg.History.Enabled {
+		return
+	}
+	if cfg.Shard != "" {
+		logging.Debug("Sharded run, not recording fleet trends", "shard", cfg.Shard)
+		return
+	}
+	trends, err := history.LoadTrends(cfg.History.GetTrendsPath(cfg.Paths.GetStateDir()))
+	if err != nil {
+		logging.Warn("Failed to load trends", "error", err)
+		return
+	}
+	trends.Add(sample)
+	if err := trends.Save(); err != nil {
+		logging.Warn("Failed to save trends", "error", err)
+	}
+}
+
+// loadTrends returns the recorded fleet trends for the Markdown report, or
+// nil if history is disabled.
+func loadTrends(cfg *config.Config) []models.TrendSample {
+	if !cfg.History.Enabled {
+		return nil
+	}
+	trends, err := history.LoadTrends(cfg.History.GetTrendsPath(cfg.Paths.GetStateDir()))
+	if err != nil {
+		logging.Warn("Failed to load trends, reporting without them", "error", err)
+		return nil
+	}
+	return trends.Samples()
+}
+
+// regressionsFile is the file in the state directory listing the health
+// regressions passed to on_regression hooks.
+const regressionsFile = "regressions.json"
+
+// reportRegressions logs health regressions and runs the on_regression hooks
+// for those of at least hooks.regression_severity.
+func reportRegressions(ctx context.Context, cfg *config.Config, stats models.CollectionStats, regs []regression.Regression) {
+	for _, r := range regs {
+		log := logging.Warn
+		if r.Severity == regression.Critical {
+			log = logging.Error
+		}
+		log("Hardware health regression",
+			"host", r.Host,
+			"service_tag", r.ServiceTag,
+			"regression", r.String(),
+			"severity", r.Severity,
+		)
+	}
+
+	minSeverity, _ := regression.ParseSeverity(cfg.Hooks.RegressionSeverity)
+	regs = regression.Filter(regs, minSeverity)
+	if len(regs) == 0 || !hooks.New(cfg.Hooks).Has(hooks.OnRegression) {
+		return
+	}
+
+	summary := hookSummary(stats, "")
+	summary.Regressions = len(regs)
+	summary.RegressionSeverity = string(regression.Worst(regs))
+
+	path := filepath.Join(cfg.Paths.GetStateDir(), regressionsFile)
+	if err := writeRegressions(path, regs); err != nil {
+		logging.Warn("Failed to write regressions for hooks", "error", err)
+	} else {
+		summary.RegressionsFile = path
+	}
+	runHooks(ctx, cfg, hooks.OnRegression, summary)
+}
+
+func writeRegressions(path string, regs []regression.Regression) error {
+	data, err := json.MarshalIndent(regs, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o640)
+}
+
+// loadPlugins loads the collector plugins listed in the config. Collectors
+// compiled into the binary have registered themselves at this point.
+func loadPlugins(cfg *config.Config) error {
+	for _, path := range cfg.Collectors.Plugins {
+		if err := scanner.LoadPlugin(path); err != nil {
+			return err
+		}
+	}
+	if names := scanner.Collectors(); len(names) > 0 {
+		logging.Info("Custom collectors registered",
+			"collectors", names,
+			"disabled", cfg.Collectors.Disabled,
+		)
+	}
+	return nil
+}
+
+// loadOptional reads the optional file at path with load. It returns the
+// zero value if path is empty or the file cannot be read, in which case it
+// warns that the run continues without, e.g. "without placement data".
+func loadOptional[T any](path string, load func(string) (T, error), what, without string) T {
+	var zero T
+	if path == "" {
+		return zero
+	}
+	v, err := load(path)
+	if err != nil {
+		logging.Warn("Failed to load "+what+", continuing "+without, "file", path, "error", err)
+		return zero
+	}
+	return v
+}
+
+// loadPlacement returns the rack positions and sites of the placement file,
+// or nil if there is none.
+func loadPlacement(cfg *config.Config) *placement.Map {
+	return loadOptional(cfg.Placement.File, placement.Load, "placement file", "without placement data")
+}
+
+// loadAnnotations returns the operator notes of the annotations file, or
+// nil if there is none.
+func loadAnnotations(cfg *config.Config) *annotations.Map {
+	return loadOptional(cfg.Annotations.File, annotations.Load, "annotations file", "without notes")
+}
+
+// applyFiles applies the placement and annotations files to the results
+// and logs how many servers each matched.
+func applyFiles(cfg *config.Config, results []models.ServerInfo) {
+	if pm := loadPlacement(cfg); pm != nil {
+		logging.Info("Applied placement file",
+			"file", cfg.Placement.File,
+			"servers", pm.Apply(results),
+		)
+	}
+	if am := loadAnnotations(cfg); am != nil {
+		logging.Info("Applied annotations file",
+			"file", cfg.Annotations.File,
+			"servers", am.Apply(results),
+		)
+	}
+}
+
+// loadGolden reads the golden config and, if it matches by device role,
+// connects to NetBox for the role lookups. Without NetBox it checks by
+// model only; without a golden config it returns nil.
+func loadGolden(ctx context.Context, cfg *config.Config) (*golden.Spec, golden.RoleFunc) {
+	spec := loadOptional(cfg.Golden.File, golden.Load, "golden config", "without the compliance check")
+	if spec == nil {
+		return nil, nil
+	}
+	if !spec.UsesRoles() {
+		return spec, nil
+	}
+	if !cfg.NetBox.IsEnabled() {
+		logging.Warn("Golden config matches by device role but NetBox is not configured, checking by model only")
+		return spec, nil
+	}
+	client, err := newNetBoxClient(ctx, cfg)
+	if err != nil {
+		logging.Warn("Failed to connect to NetBox, checking by model only", "error", err)
+		return spec, nil
+	}
+	return spec, client.DeviceRole
+}
+
+// checkGolden records the golden config compliance of the results and logs
+// the servers deviating from their expected build.
+func checkGolden(ctx context.Context, cfg *config.Config, results []models.ServerInfo) {
+	spec, roles := loadGolden(ctx, cfg)
+	if spec == nil {
+		return
+	}
+	for i := range results {
+		logCompliance(results[i], spec.ApplyOne(ctx, &results[i], roles))
+	}
+	logging.Info("Checked golden config",
+		"file", cfg.Golden.File,
+		"non_compliant", len(models.FindNonCompliant(results)),
+	)
+}
+
+// logCompliance logs the golden config violations of a server.
+func logCompliance(srv models.ServerInfo, c *models.Compliance) {
+	if c == nil {
+		return
+	}
+	for _, v := range c.Violations {
+		logging.Warn("Server deviates from its golden config",
+			"host", srv.Host,
+			"service_tag", srv.ServiceTag,
+			"profile", c.Profile,
+			"violation", v.String(),
+		)
+	}
+}
+
+// checkFirmware records the firmware policy compliance of the results and
+// logs the servers running firmware below the minimum versions.
+func checkFirmware(cfg *config.Config, results []models.ServerInfo) {
+	policy := firmware.New(cfg.FirmwarePolicy)
+	if policy == nil {
+		return
+	}
+	for i := range results {
+		logFirmwareCompliance(results[i], policy.ApplyOne(&results[i]))
+	}
+	total := models.TotalFirmwareCompliance(models.SummarizeFirmwareCompliance(results))
+	logging.Info("Checked firmware policy",
+		"servers", total.Servers,
+		"non_compliant", total.Servers-total.Compliant,
+	)
+}
+
+// logFirmwareCompliance logs the firmware policy violations of a server.
+func logFirmwareCompliance(srv models.ServerInfo, c *models.FirmwareCompliance) {
+	if c == nil {
+		return
+	}
+	for _, v := range c.Violations {
+		logging.Warn("Server firmware is below the policy minimum",
+			"host", srv.Host,
+			"service_tag", srv.ServiceTag,
+			"policy", c.Policy,
+			"violation", v.String(),
+		)
+	}
+}
+
+// publishResults runs the NetBox sync and GitLab export, if requested. Its
+// errors are sync failures.
+func publishResults(ctx context.Context, cfg *config.Config, f *flags, results []models.ServerInfo, stats models.CollectionStats, summary hooks.Summary) error {
 	// Sync to NetBox if requested.
 	// Note: we do NOT return here so that a GitLab export (-gitlab-repo) can
 	// still run afterwards when both -sync and -gitlab-push are combined.
@@ -224,33 +966,43 @@ func run(ctx context.Context, cfg *config.Config, f *flags) error {
 		if !cfg.NetBox.IsEnabled() {
 			logging.Warn("NetBox sync requested but not configured")
 		} else {
-			if err := runNetBoxSync(ctx, cfg, results); err != nil {
-				return err
+			if err := runNetBoxSync(ctx, cfg, f.force, results, summary); err != nil {
+				return errors.AsSyncError(err)
 			}
 		}
 	}
 
-	// Export aggregated report to a local git repository (GitLab) if requested.
-	repoPath := f.gitlabRepo
-	if repoPath == "" {
-		repoPath = cfg.GitLab.RepoPath
+	// Archive the results to the configured output sinks.
+	if err := writeSinks(ctx, cfg, results, stats); err != nil {
+		return errors.AsSyncError(err)
 	}
-	if repoPath != "" {
-		if err := runGitLabExport(f, cfg, results, stats, repoPath); err != nil {
-			return err
-		}
+
+	// Upsert the results into the SQL warehouse.
+	if err := exportWarehouse(ctx, cfg, results, stats); err != nil {
+		return errors.AsSyncError(err)
 	}
 
-	// Return error if any servers failed
-	if stats.FailedCount > 0 {
-		return fmt.Errorf("%d of %d servers failed", stats.FailedCount, stats.TotalServers)
+	// Export aggregated report to a local git repository (GitLab) if requested.
+	if repoPath := gitlabRepoPath(f, cfg); repoPath != "" {
+		inv := models.GroupByConfigurationWithOptions(results, stats, fingerprintOptions(cfg))
+		if err := runGitLabExport(f, cfg, inv, repoPath); err != nil {
+			return errors.AsSyncError(err)
+		}
 	}
 
 	return nil
 }
 
-// runGitLabExport aggregates the scan results and commits them to a local git repository.
-func runGitLabExport(f *flags, cfg *config.Config, results []models.ServerInfo, stats models.CollectionStats, repoPath string) error {
+// gitlabRepoPath returns the repository of the GitLab export ("" if disabled).
+func gitlabRepoPath(f *flags, cfg *config.Config) string {
+	if f.gitlabRepo != "" {
+		return f.gitlabRepo
+	}
+	return cfg.GitLab.RepoPath
+}
+
+// runGitLabExport commits the aggregated inventory to a local git repository.
+func runGitLabExport(f *flags, cfg *config.Config, inv models.AggregatedInventory, repoPath string) error {
 	// Determine which flags were explicitly provided on the command line.
 	// flag.Visit only walks flags that were actually set, so we can tell apart
 	// "user passed -gitlab-branch main" from "flag kept its default value".
@@ -268,7 +1020,14 @@ func runGitLabExport(f *flags, cfg *config.Config, results []models.ServerInfo,
 	}
 	push := f.gitlabPush || cfg.GitLab.Push
 
-	inv := models.GroupByConfiguration(results, stats)
+	if err := annotateChassis(cfg, &inv); err != nil {
+		return err
+	}
+
+	signer, err := newSigner(cfg)
+	if err != nil {
+		return err
+	}
 
 	logging.Info("Exporting aggregated inventory to git repository",
 		"repo", repoPath,
@@ -285,11 +1044,21 @@ func runGitLabExport(f *flags, cfg *config.Config, results []models.ServerInfo,
 		AuthorName:   cfg.GitLab.AuthorName,
 		AuthorEmail:  cfg.GitLab.AuthorEmail,
 		Push:         push,
+		ReadOnly:     cfg.ReadOnly,
+		Signer:       signer,
+		Units:        cfg.Units.Format(),
+		Heartbeat:    f.gitlabBeat || cfg.GitLab.Heartbeat,
+		Trends:       loadTrends(cfg),
 	})
 
-	if err := exp.Export(inv); err != nil {
+	committed, err := exp.Export(inv)
+	if err != nil {
 		return fmt.Errorf("gitlab export failed: %w", err)
 	}
+	if !committed {
+		fmt.Printf("\nInventory unchanged since the last commit in %s: nothing committed\n", repoPath)
+		return nil
+	}
 
 	fmt.Printf("\nInventory exported to: %s/%s/\n", repoPath, dir)
 	if push {
@@ -301,16 +1070,39 @@ func runGitLabExport(f *flags, cfg *config.Config, results []models.ServerInfo,
 	return nil
 }
 
-func runValidateConnections(ctx context.Context, s *scanner.Scanner) error {
-	logging.Info("Validating connections to all servers")
+// newSigner loads the report signing key, or returns nil if signing is disabled.
+func newSigner(cfg *config.Config) (*signing.Signer, error) {
+	if !cfg.Signing.IsEnabled() {
+		return nil, nil
+	}
+	signer, err := signing.NewSigner(cfg.Signing.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load signing key: %w", err)
+	}
+	return signer, nil
+}
 
-	results := s.ValidateConnections(ctx)
+func runValidateConnections(ctx context.Context, cfg *config.Config, f *flags, s *scanner.Scanner) error {
+	logging.Info("Validating connections to all servers")
 
-	successCount := printValidationResults(results)
+	checks := s.ValidateConnections(ctx)
 
-	fmt.Printf("\nValidation complete: %d/%d successful\n", successCount, len(results))
+	formatter, ok := newFormatter(f, cfg.Units.Format()).(output.ValidationFormatter)
+	if !ok {
+		console := output.NewConsoleFormatter(f.verbose, f.noColor)
+		console.Style = consoleStyle(f)
+		formatter = console
+	}
+	if err := formatter.FormatValidation(os.Stdout, checks); err != nil {
+		return fmt.Errorf("failed to output validation results: %w", err)
+	}
 
-	failCount := len(results) - successCount
+	failCount := 0
+	for _, c := range checks {
+		if !c.OK() {
+			failCount++
+		}
+	}
 	if failCount > 0 {
 		return fmt.Errorf("%d connections failed", failCount)
 	}
@@ -318,74 +1110,318 @@ func runValidateConnections(ctx context.Context, s *scanner.Scanner) error {
 	return nil
 }
 
-func outputResults(f *flags, results []models.ServerInfo, stats models.CollectionStats) error {
+func outputResults(f *flags, cfg *config.Config, results []models.ServerInfo, stats models.CollectionStats) error {
 	// "aggregate" is a special format that groups servers by hardware config.
 	if f.outputFormat == "aggregate" {
-		inv := models.GroupByConfiguration(results, stats)
-		return output.NewAggregatedConsoleFormatter(f.noColor).FormatAggregated(os.Stdout, inv)
+		inv := models.GroupByConfigurationWithOptions(results, stats, fingerprintOptions(cfg))
+		if err := annotateChassis(cfg, &inv); err != nil {
+			return err
+		}
+		formatter := output.NewAggregatedConsoleFormatter(f.noColor)
+		formatter.Units = cfg.Units.Format()
+		formatter.Style = consoleStyle(f)
+		return formatter.FormatAggregated(os.Stdout, inv)
 	}
 
-	var formatter output.Formatter
+	if f.outputFormat == "json" {
+		return outputJSON(f, cfg, results, stats)
+	}
+
+	// JUnit reports the configured servers without a result as skipped.
+	if f.outputFormat == "junit" {
+		formatter := output.NewJUnitFormatter()
+		formatter.Unscanned = unscanned(cfg.Servers, results)
+		return formatter.Format(os.Stdout, results, stats)
+	}
+
+	return newFormatter(f, cfg.Units.Format()).Format(os.Stdout, results, stats)
+}
+
+// outputJSON writes JSON results to stdout, gzip-compressed with -compress,
+// or as chunk files with -output-dir, which are signed if signing is enabled.
+func outputJSON(f *flags, cfg *config.Config, results []models.ServerInfo, stats models.CollectionStats) error {
+	if f.outputDir != "" {
+		signer, err := newSigner(cfg)
+		if err != nil {
+			return err
+		}
+		index, err := output.WriteChunked(f.outputDir, results, stats, f.chunkSize, f.compress, signer)
+		if err != nil {
+			return err
+		}
+		logging.Info("Wrote chunked JSON results",
+			"dir", f.outputDir,
+			"chunks", len(index.Chunks),
+			"servers", index.TotalServers,
+		)
+		return nil
+	}
+
+	if !f.compress {
+		return output.NewJSONFormatter(true).Format(os.Stdout, results, stats)
+	}
+	zw := output.NewGzipWriter(os.Stdout)
+	if err := output.NewJSONFormatter(true).Format(zw, results, stats); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// newFormatter returns the formatter selected with -output. Console and
+// table output show sizes in the units of u and adapt to the width of the
+// terminal on stdout; console output is colored if stdout is a terminal.
+func newFormatter(f *flags, u units.Format) output.Formatter {
 	switch f.outputFormat {
 	case "json":
-		formatter = output.NewJSONFormatter(true)
+		return output.NewJSONFormatter(true)
 	case "table":
-		formatter = output.NewTableFormatter()
+		formatter := output.NewTableFormatter()
+		formatter.Units = u
+		formatter.Width, formatter.Wide = terminal.Width(os.Stdout), f.wide
+		return formatter
 	case "csv":
-		formatter = output.NewCSVFormatter()
-	case "console":
-		fallthrough
+		return output.NewCSVFormatter()
+	case "junit":
+		return output.NewJUnitFormatter()
 	default:
-		formatter = output.NewConsoleFormatter(f.verbose, f.noColor)
+		formatter := output.NewConsoleFormatter(f.verbose, f.noColor)
+		formatter.Units = u
+		formatter.Width, formatter.Wide = terminal.Width(os.Stdout), f.wide
+		formatter.Style = consoleStyle(f)
+		return formatter
 	}
+}
 
-	return formatter.Format(os.Stdout, results, stats)
+// consoleStyle returns the colors of console output: none with -no-color,
+// NO_COLOR set or stdout redirected to a file or pipe.
+func consoleStyle(f *flags) output.Style {
+	style := output.NewStyle(f.noColor)
+	style.Color = style.Color && terminal.IsTerminal(os.Stdout)
+	return style
 }
 
-func runNetBoxSync(ctx context.Context, cfg *config.Config, results []models.ServerInfo) error {
+// outputReport renders the analysis report selected with -report in the -output format.
+func outputReport(f *flags, cfg *config.Config, results []models.ServerInfo) error {
+	var report output.Report
+	switch f.report {
+	case "spares":
+		report = output.SparePartsReport(models.BuildSparePartsReport(results))
+	case "capacity":
+		report = output.CapacityReport(models.BuildCapacityHeadroom(results), cfg.Units.Format())
+	case "duplicates":
+		report = output.DuplicatesReport(models.FindDuplicates(results))
+	case "slowest":
+		n := f.slowest
+		if n <= 0 {
+			n = defaultSlowestHosts
+		}
+		report = output.SlowestHostsReport(models.SlowestHosts(results, n))
+	case "certs":
+		report = output.ExpiringCertificatesReport(models.FindExpiringCertificates(results, f.certDays, time.Now()))
+	case "licenses":
+		report = output.LicensesReport(models.SummarizeLicenses(results))
+	case "generations":
+		report = output.GenerationsReport(models.GroupByGeneration(results))
+	case "compliance":
+		report = output.ComplianceReport(models.FindNonCompliant(results))
+	case "firmware":
+		rates := models.SummarizeFirmwareCompliance(results)
+		if rates == nil {
+			logging.Warn("No firmware policy results, set firmware_policy in the config")
+		}
+		report = output.FirmwareComplianceReport(rates)
+	case "nvme":
+		report = output.NVMeWearReport(models.BuildNVMeWearReport(results))
+	case "accounts":
+		audited := 0
+		for _, r := range results {
+			if r.Accounts != nil {
+				audited++
+			}
+		}
+		if audited == 0 {
+			logging.Warn("No account audit in the results, enable accounts.audit in the config")
+		}
+		report = output.AccountAuditReport(models.BuildAccountAuditReport(results))
+	case "capabilities":
+		report = output.CapabilityReport(models.SummarizeCapabilities(results))
+	default:
+		return fmt.Errorf("unknown report %q (available: spares, capacity, duplicates, slowest, certs, licenses, generations, compliance, firmware, nvme, accounts, capabilities)", f.report)
+	}
+
+	return output.WriteReport(os.Stdout, report, f.outputFormat)
+}
+
+// fingerprintOptions maps the aggregation config onto the models fingerprint options.
+func fingerprintOptions(cfg *config.Config) models.FingerprintOptions {
+	fp := cfg.Aggregation.Fingerprint
+	return models.FingerprintOptions{
+		Ignore:           fp.Ignore,
+		RAMBucketsGiB:    fp.RAMBucketsGiB,
+		StorageBucketsTB: fp.StorageBucketsTB,
+	}
+}
+
+// annotateChassis adds U height, airflow, weight and PSU slots from the
+// model catalog to the model groups of an aggregated report.
+func annotateChassis(cfg *config.Config, inv *models.AggregatedInventory) error {
+	cat, err := catalog.Load(cfg.Catalog.File)
+	if err != nil {
+		return err
+	}
+	cat.Annotate(inv)
+	return nil
+}
+
+// runNetBoxSync syncs the results to NetBox. Unless force is set, a sync
+// that would exceed netbox.max_changes or netbox.max_creates is aborted
+// before anything is written.
+func runNetBoxSync(ctx context.Context, cfg *config.Config, force bool, results []models.ServerInfo, summary hooks.Summary) error {
 	logging.Info("Syncing results to NetBox",
 		"url", cfg.NetBox.URL,
 	)
 
-	client := netbox.NewClient(cfg.NetBox)
+	client, err := newNetBoxClient(ctx, cfg)
+	if err != nil {
+		return err
+	}
 
-	// Test connection first
-	if err := client.TestConnection(ctx); err != nil {
-		return fmt.Errorf("NetBox connection failed: %w", err)
+	release, err := acquireSyncLock(ctx, cfg, client)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	if cfg.NetBox.HasLimits() && !force {
+		plan := client.Plan(ctx, results)
+		if err := plan.Check(cfg.NetBox.MaxChanges, cfg.NetBox.MaxCreates); err != nil {
+			logging.Error("NetBox sync aborted, nothing was written",
+				"devices_modified", len(plan.Modified),
+				"objects_created", plan.Created,
+				"hosts", plan.Modified,
+			)
+			return fmt.Errorf("NetBox sync aborted: %w (re-run with -force to apply)", err)
+		}
 	}
 
-	syncResults := client.SyncAll(ctx, results)
+	return reportSyncResults(ctx, cfg, client.SyncAll(ctx, results), summary)
+}
 
-	// Print sync results and count failures
-	fmt.Println("\nNetBox Sync Results:")
-	failCount := printSyncResults(syncResults)
+// syncLockPoll is how often a run waiting for the NetBox sync lock retries.
+const syncLockPoll = 15 * time.Second
+
+// acquireSyncLock takes the NetBox sync lock if netbox.lock is enabled,
+// waiting up to netbox.lock.wait while another run holds it. The lease is
+// renewed every third of its TTL until the returned function releases the
+// lock. In read-only mode, where the sync cannot write anyway, no lock is
+// taken.
+func acquireSyncLock(ctx context.Context, cfg *config.Config, client *netbox.Client) (func(), error) {
+	lc := cfg.NetBox.Lock
+	if !lc.Enabled || cfg.ReadOnly {
+		return func() {}, nil
+	}
 
-	if failCount > 0 {
-		return fmt.Errorf("%d of %d servers failed to sync", failCount, len(syncResults))
+	lock := client.NewLock(lc.GetName(), lc.GetIdentity(), lc.GetTTL())
+	deadline := time.Now().Add(lc.GetWait())
+	for {
+		ok, err := lock.Acquire(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("NetBox sync lock: %w", err)
+		}
+		if ok {
+			break
+		}
+
+		holder, expires := lock.Holder()
+		if !time.Now().Before(deadline) {
+			return nil, fmt.Errorf("NetBox sync: %w (held by %s until %s)",
+				errors.ErrLocked, holder, expires.Format(time.RFC3339))
+		}
+		logging.Info("Waiting for NetBox sync lock",
+			"lock", lc.GetName(),
+			"holder", holder,
+			"expires", expires.Format(time.RFC3339),
+		)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(syncLockPoll):
+		}
 	}
 
-	return nil
+	logging.Debug("Acquired NetBox sync lock", "lock", lc.GetName(), "identity", lc.GetIdentity())
+
+	renewCtx, stopRenew := context.WithCancel(ctx)
+	renewed := make(chan struct{})
+	go func() {
+		defer close(renewed)
+		ticker := time.NewTicker(lc.GetTTL() / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-renewCtx.Done():
+				return
+			case <-ticker.C:
+				if err := lock.Renew(renewCtx); err != nil && renewCtx.Err() == nil {
+					logging.Warn("Failed to renew NetBox sync lock", "lock", lc.GetName(), "error", err)
+				}
+			}
+		}
+	}()
+
+	return func() {
+		stopRenew()
+		<-renewed
+
+		// Released even if the run was canceled, so the next run need not
+		// wait for the lease to expire
+		if err := lock.Release(context.WithoutCancel(ctx)); err != nil {
+			logging.Warn("Failed to release NetBox sync lock", "lock", lc.GetName(), "error", err)
+		}
+	}, nil
 }
 
-func printVersion() {
-	fmt.Printf("iDRAC Inventory Tool\n")
-	fmt.Printf("  Version:    %s\n", Version)
-	fmt.Printf("  Build Time: %s\n", BuildTime)
-	fmt.Printf("  Git Commit: %s\n", GitCommit)
+// newNetBoxClient creates the NetBox client and tests the connection.
+func newNetBoxClient(ctx context.Context, cfg *config.Config) (*netbox.Client, error) {
+	cat, err := catalog.Load(cfg.Catalog.File)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []netbox.ClientOption{
+		netbox.WithCatalog(cat),
+		netbox.WithHTTPHeaders(cfg.HTTP),
+		netbox.WithAuditLog(audit.New(cfg.Audit.Path, cfg.Audit.Actor)),
+		netbox.WithReadOnly(cfg.ReadOnly),
+		netbox.WithUnits(cfg.Units.Format()),
+	}
+	if cfg.NetBox.DeviceCache.Enabled {
+		path := filepath.Join(cfg.Paths.GetCacheDir(), defaults.DefaultNetBoxDeviceCacheFile)
+		opts = append(opts, netbox.WithDeviceCache(path, cfg.NetBox.DeviceCache))
+	}
+	client := netbox.NewClient(cfg.NetBox, opts...)
+
+	// Test connection first
+	if err := client.TestConnection(ctx); err != nil {
+		return nil, fmt.Errorf("NetBox connection failed: %w", err)
+	}
+	return client, nil
 }
 
-// printValidationResults prints validation results and returns the success count.
-func printValidationResults(results map[string]error) int {
-	successCount := 0
-	for host, err := range results {
-		if err != nil {
-			fmt.Printf("❌ %s: %v\n", host, err)
-		} else {
-			fmt.Printf("✅ %s: OK\n", host)
-			successCount++
-		}
+// reportSyncResults prints the NetBox sync results, runs the post_sync hooks
+// and fails if any host failed.
+func reportSyncResults(ctx context.Context, cfg *config.Config, syncResults []netbox.SyncResult, summary hooks.Summary) error {
+	fmt.Println("\nNetBox Sync Results:")
+	failCount := printSyncResults(syncResults)
+
+	summary.SyncFailed = failCount
+	runHooks(ctx, cfg, hooks.PostSync, summary)
+
+	if failCount > 0 {
+		return errors.WithCode(errors.CodeSyncFailed, fmt.Sprintf("%d of %d servers failed to sync", failCount, len(syncResults)))
 	}
-	return successCount
+
+	return nil
 }
 
 // printSyncResults prints NetBox sync results and returns the failure count.
@@ -395,7 +1431,7 @@ func printSyncResults(results []netbox.SyncResult) int {
 		if r.Success {
 			fmt.Printf("  ✅ %s: synced\n", r.Host)
 		} else {
-			fmt.Printf("  ❌ %s: %v\n", r.Host, r.Error)
+			fmt.Printf("  ❌ %s: %v [%s]\n", r.Host, r.Error, errors.CodeOf(r.Error))
 			failCount++
 		}
 	}
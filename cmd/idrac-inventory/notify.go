@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/braunma/idrac-netbox-importer/internal/output"
+	"github.com/braunma/idrac-netbox-importer/internal/slack"
+	"github.com/braunma/idrac-netbox-importer/pkg/config"
+	"github.com/braunma/idrac-netbox-importer/pkg/logging"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+	"github.com/braunma/idrac-netbox-importer/pkg/runid"
+)
+
+// previousResults returns the results of the previous run from the state
+// directory, which the Slack summary compares with. It returns nil if Slack
+// notifications are disabled or there is no readable previous run.
+func previousResults(cfg *config.Config) []models.ServerInfo {
+	if !cfg.Notify.Slack.Enabled {
+		return nil
+	}
+	path := filepath.Join(cfg.Paths.GetStateDir(), lastScanFile)
+	if _, err := os.Stat(path); err != nil {
+		return nil
+	}
+	results, _, err := output.ReadJSONFile(path)
+	if err != nil {
+		logging.Warn("Failed to read previous results, notifying without comparison", "file", path, "error", err)
+		return nil
+	}
+	if results == nil {
+		results = []models.ServerInfo{}
+	}
+	return results
+}
+
+// notifySlack posts the run summary to Slack (notify.slack), if enabled.
+// Failures are logged and do not fail the run.
+func notifySlack(ctx context.Context, cfg *config.Config, prev, results []models.ServerInfo, stats models.CollectionStats) {
+	if !cfg.Notify.Slack.Enabled {
+		return
+	}
+	if stats.RunID == "" {
+		stats.RunID = runid.Current()
+	}
+	posted, err := slack.New(cfg.Notify.Slack, cfg.ReadOnly).Notify(ctx, prev, results, stats)
+	if err != nil {
+		logging.Error("Failed to post Slack summary", "error", err)
+		return
+	}
+	if posted {
+		logging.Info("Posted Slack summary", "channel", cfg.Notify.Slack.Channel)
+	} else {
+		logging.Debug("No new failures or hardware changes, skipping Slack summary")
+	}
+}
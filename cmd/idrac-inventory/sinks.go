@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/braunma/idrac-netbox-importer/internal/output"
+	"github.com/braunma/idrac-netbox-importer/internal/sink"
+	"github.com/braunma/idrac-netbox-importer/pkg/config"
+	"github.com/braunma/idrac-netbox-importer/pkg/errors"
+	"github.com/braunma/idrac-netbox-importer/pkg/logging"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+// writeSinks writes the results to the output sinks of the configuration
+// (output.sinks), each in its own format and signed if signing is enabled. A
+// failing sink does not stop the others; all failures are returned together.
+func writeSinks(ctx context.Context, cfg *config.Config, results []models.ServerInfo, stats models.CollectionStats) error {
+	if len(cfg.Output.Sinks) == 0 {
+		return nil
+	}
+	signer, err := newSigner(cfg)
+	if err != nil {
+		return err
+	}
+
+	multiErr := &errors.MultiError{}
+	now := time.Now()
+	for _, sc := range cfg.Output.Sinks {
+		s, err := sink.New(sc, now, cfg.ReadOnly, signer)
+		if err != nil {
+			multiErr.Add(err)
+			continue
+		}
+		data, err := renderSink(cfg, sc, results, stats)
+		if err != nil {
+			multiErr.Add(fmt.Errorf("output sink %s: %w", s, err))
+			continue
+		}
+
+		writeCtx, cancel := context.WithTimeout(ctx, sc.Timeout())
+		err = s.Write(writeCtx, data)
+		cancel()
+		if err != nil {
+			logging.Error("Failed to write output",
+				"sink", s.String(),
+				"error", err,
+				"error_code", errors.CodeOf(err),
+			)
+			multiErr.Add(fmt.Errorf("output sink %s: %w", s, err))
+			continue
+		}
+		logging.Info("Wrote output",
+			"sink", s.String(),
+			"format", sc.GetFormat(),
+			"bytes", len(data),
+		)
+	}
+	return multiErr.ErrorOrNil()
+}
+
+// renderSink renders the results in the format of a sink, gzip-compressed
+// if configured.
+func renderSink(cfg *config.Config, sc config.SinkConfig, results []models.ServerInfo, stats models.CollectionStats) ([]byte, error) {
+	var buf bytes.Buffer
+	var w io.Writer = &buf
+	var zw *gzip.Writer
+	if sc.Compress {
+		zw = output.NewGzipWriter(&buf)
+		w = zw
+	}
+
+	var err error
+	switch sc.GetFormat() {
+	case "json":
+		err = output.NewJSONFormatter(true).Format(w, results, stats)
+	case "csv":
+		err = output.NewCSVFormatter().Format(w, results, stats)
+	case "table":
+		formatter := output.NewTableFormatter()
+		formatter.Units = cfg.Units.Format()
+		err = formatter.Format(w, results, stats)
+	case "console":
+		formatter := output.NewConsoleFormatter(false, true)
+		formatter.Units = cfg.Units.Format()
+		err = formatter.Format(w, results, stats)
+	case "junit":
+		formatter := output.NewJUnitFormatter()
+		formatter.Unscanned = unscanned(cfg.Servers, results)
+		err = formatter.Format(w, results, stats)
+	case "markdown":
+		inv := models.GroupByConfigurationWithOptions(results, stats, fingerprintOptions(cfg))
+		if err := annotateChassis(cfg, &inv); err != nil {
+			return nil, err
+		}
+		formatter := output.NewMarkdownFormatter()
+		formatter.Units = cfg.Units.Format()
+		err = formatter.FormatAggregated(w, inv)
+	default:
+		err = fmt.Errorf("unknown format %q", sc.Format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if zw != nil {
+		if err := zw.Close(); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/braunma/idrac-netbox-importer/internal/firmware"
+	"github.com/braunma/idrac-netbox-importer/internal/history"
+	"github.com/braunma/idrac-netbox-importer/internal/hooks"
+	"github.com/braunma/idrac-netbox-importer/internal/output"
+	"github.com/braunma/idrac-netbox-importer/internal/regression"
+	"github.com/braunma/idrac-netbox-importer/internal/warranty"
+	"github.com/braunma/idrac-netbox-importer/pkg/config"
+	"github.com/braunma/idrac-netbox-importer/pkg/errors"
+	"github.com/braunma/idrac-netbox-importer/pkg/logging"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+	"github.com/braunma/idrac-netbox-importer/pkg/netbox"
+	"github.com/braunma/idrac-netbox-importer/pkg/scanner"
+)
+
+// runStream is the -stream variant of a one-shot scan: results flow from the
+// scanner through the history store to the output formatter, the NetBox sync
+// and the GitLab aggregation as each host completes, instead of being
+// buffered for the whole fleet first.
+func runStream(ctx context.Context, cfg *config.Config, f *flags, s *scanner.Scanner) error {
+	formatter, ok := newFormatter(f, cfg.Units.Format()).(output.StreamFormatter)
+	switch {
+	case f.daemon:
+		return fmt.Errorf("-stream is not supported in daemon mode")
+	case f.report != "":
+		return fmt.Errorf("-stream cannot be combined with -report (reports need all results)")
+	case f.outputDir != "":
+		return fmt.Errorf("-stream cannot be combined with -output-dir")
+	case !ok || f.outputFormat == "aggregate":
+		return fmt.Errorf("-output %s cannot be streamed (use console, json or csv)", f.outputFormat)
+	case f.syncNetBox && cfg.NetBox.HasLimits() && !f.force:
+		return fmt.Errorf("-stream -sync cannot check netbox.max_changes/max_creates before writing (sync without -stream, or use -force)")
+	}
+
+	var store *history.Store
+	if cfg.History.Enabled {
+		var err error
+		if store, err = history.Load(cfg.History.GetPath(cfg.Paths.GetStateDir())); err != nil {
+			logging.Warn("Failed to load history, reporting failed hosts without stale data", "error", err)
+		}
+	}
+
+	pm := loadPlacement(cfg)
+	am := loadAnnotations(cfg)
+	spec, roles := loadGolden(ctx, cfg)
+	policy := firmware.New(cfg.FirmwarePolicy)
+	var wc *warranty.Client
+	if cfg.Warranty.Enabled {
+		wc = warranty.New(cfg.Warranty)
+	}
+
+	// NetBox sync consumes its own channel in parallel to the output
+	var syncCh chan models.ServerInfo
+	var syncDone chan []netbox.SyncResult
+	if f.syncNetBox {
+		if !cfg.NetBox.IsEnabled() {
+			logging.Warn("NetBox sync requested but not configured")
+		} else {
+			client, err := newNetBoxClient(ctx, cfg)
+			if err != nil {
+				return errors.AsSyncError(err)
+			}
+			release, err := acquireSyncLock(ctx, cfg, client)
+			if err != nil {
+				return errors.AsSyncError(err)
+			}
+			defer release()
+			syncCh = make(chan models.ServerInfo, cfg.Concurrency+1)
+			syncDone = make(chan []netbox.SyncResult, 1)
+			go func() { syncDone <- client.SyncStream(ctx, syncCh) }()
+		}
+	}
+
+	var agg *models.Aggregator
+	repoPath := gitlabRepoPath(f, cfg)
+	if repoPath != "" {
+		agg = models.NewAggregator(fingerprintOptions(cfg))
+	}
+
+	var w io.Writer = os.Stdout
+	var finishOutput func() error
+	if f.outputFormat == "json" && f.compress {
+		zw := output.NewGzipWriter(os.Stdout)
+		w, finishOutput = zw, zw.Close
+	}
+
+	logging.Info("Starting streaming inventory scan",
+		"server_count", len(cfg.Servers),
+	)
+
+	results := make(chan models.ServerInfo)
+	statsCh := make(chan models.CollectionStats, 1)
+	go func() { statsCh <- s.ScanStream(ctx, results) }()
+
+	var outputErr error
+	if outputErr = formatter.Begin(w); outputErr != nil {
+		outputErr = fmt.Errorf("failed to output results: %w", outputErr)
+	}
+
+	staleCount := 0
+	var health healthPolicy
+	var regs []regression.Regression
+	trend := models.TrendSample{}
+	for info := range results {
+		if wc != nil {
+			one := []models.ServerInfo{info}
+			if _, err := wc.Apply(ctx, one); err != nil {
+				logging.Warn("Warranty lookup failed, reporting without ship date", "host", info.Host, "error", err)
+			}
+			info = one[0]
+		}
+		if store != nil {
+			if prev, ok := store.Last(info.Key()); ok && info.Error == nil {
+				regs = append(regs, regression.Detect(prev, info)...)
+			}
+
+			var stale bool
+			if info, stale = store.ApplyOne(info); stale {
+				staleCount++
+				logging.Warn("Host failed, reporting last known good inventory",
+					"host", info.Host,
+					"stale_since", info.StaleSince.Format(time.RFC3339),
+					"error", info.StaleError,
+				)
+			}
+		}
+
+		if pm != nil {
+			pm.ApplyOne(&info)
+		}
+		if am != nil {
+			am.ApplyOne(&info)
+		}
+		if spec != nil {
+			logCompliance(info, spec.ApplyOne(ctx, &info, roles))
+		}
+		if policy != nil {
+			logFirmwareCompliance(info, policy.ApplyOne(&info))
+		}
+		if f.failOn == failOnHealth {
+			health.check(info)
+		}
+
+		// Keep draining the scan after an output error so sync and export finish
+		if outputErr == nil {
+			if err := formatter.WriteServer(w, info); err != nil {
+				outputErr = fmt.Errorf("failed to output results: %w", err)
+			}
+		}
+		if syncCh != nil {
+			syncCh <- info
+		}
+		if agg != nil {
+			agg.Add(info)
+		}
+		trend.Add(info)
+	}
+
+	stats := <-statsCh
+	stats.Shard = cfg.Shard
+	stats.StaleCount = staleCount
+
+	if store != nil {
+		if err := store.Save(); err != nil {
+			logging.Warn("Failed to save history", "error", err)
+		}
+		reportRegressions(ctx, cfg, stats, regs)
+		trend.At = time.Now().UTC()
+		recordTrend(cfg, trend)
+	}
+
+	if outputErr == nil {
+		if err := formatter.End(w, stats); err != nil {
+			outputErr = fmt.Errorf("failed to output results: %w", err)
+		}
+	}
+	if finishOutput != nil {
+		if err := finishOutput(); err != nil && outputErr == nil {
+			outputErr = fmt.Errorf("failed to output results: %w", err)
+		}
+	}
+
+	// Results were never buffered, so hooks get no results file
+	summary := hookSummary(stats, "")
+	runHooks(ctx, cfg, hooks.PostScan, summary)
+
+	var syncErr error
+	if syncCh != nil {
+		close(syncCh)
+		syncErr = errors.AsSyncError(reportSyncResults(ctx, cfg, <-syncDone, summary))
+	}
+
+	err := outputErr
+	if err == nil {
+		err = syncErr
+	}
+	if err == nil && agg != nil {
+		err = errors.AsSyncError(runGitLabExport(f, cfg, agg.Inventory(stats), repoPath))
+	}
+	if err == nil && stats.FailedCount > 0 {
+		err = errors.WithCode(errors.CodeScanFailed, fmt.Sprintf("%d of %d servers failed", stats.FailedCount, stats.TotalServers))
+	}
+	if err == nil && f.failOn == failOnHealth {
+		err = health.err()
+	}
+	if err != nil {
+		summary.Error = err.Error()
+		summary.ErrorCode = string(errors.CodeOf(err))
+		runHooks(ctx, cfg, hooks.OnFailure, summary)
+	}
+	return err
+}
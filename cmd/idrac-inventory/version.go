@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"text/tabwriter"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/features"
+)
+
+// buildInfo describes the running binary.
+type buildInfo struct {
+	Version   string             `json:"version"`
+	BuildTime string             `json:"build_time"`
+	GitCommit string             `json:"git_commit"`
+	GoVersion string             `json:"go_version"`
+	Platform  string             `json:"platform"`
+	CGO       string             `json:"cgo,omitempty"`
+	Tags      string             `json:"tags,omitempty"`
+	Features  []features.Feature `json:"features"`
+}
+
+func currentBuild() buildInfo {
+	b := buildInfo{
+		Version:   Version,
+		BuildTime: BuildTime,
+		GitCommit: GitCommit,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+		Features:  features.List(),
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range info.Settings {
+			switch s.Key {
+			case "CGO_ENABLED":
+				b.CGO = s.Value
+			case "-tags":
+				b.Tags = s.Value
+			}
+		}
+	}
+	return b
+}
+
+// writeVersion prints the build information and, with withFeatures, the
+// platform-specific features of the binary.
+func writeVersion(w io.Writer, b buildInfo, withFeatures bool) error {
+	fmt.Fprintf(w, "iDRAC Inventory Tool\n")
+	fmt.Fprintf(w, "  Version:    %s\n", b.Version)
+	fmt.Fprintf(w, "  Build Time: %s\n", b.BuildTime)
+	fmt.Fprintf(w, "  Git Commit: %s\n", b.GitCommit)
+	fmt.Fprintf(w, "  Go:         %s\n", b.GoVersion)
+	fmt.Fprintf(w, "  Platform:   %s\n", b.Platform)
+	if b.CGO != "" {
+		fmt.Fprintf(w, "  CGO:        %s\n", b.CGO)
+	}
+	if b.Tags != "" {
+		fmt.Fprintf(w, "  Build Tags: %s\n", b.Tags)
+	}
+	if !withFeatures {
+		return nil
+	}
+
+	fmt.Fprintf(w, "\nFeatures:\n")
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, f := range b.Features {
+		state := "enabled"
+		if !f.Enabled {
+			state = "disabled (" + f.Reason + ")"
+		}
+		fmt.Fprintf(tw, "  %s\t%s\t%s\n", f.Name, state, f.Description)
+	}
+	return tw.Flush()
+}
+
+func printVersion() {
+	_ = writeVersion(os.Stdout, currentBuild(), false)
+}
+
+// runVersion implements the version subcommand.
+func runVersion(args []string) error {
+	fs := flag.NewFlagSet("version", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "Print the build information as JSON")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage:\n  %s version [options]\n\nOptions:\n", os.Args[0])
+		fs.PrintDefaults()
+		fmt.Fprintf(fs.Output(), "\nExample:\n  %s version -json\n", os.Args[0])
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	b := currentBuild()
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(b)
+	}
+	return writeVersion(os.Stdout, b, true)
+}
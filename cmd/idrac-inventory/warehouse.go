@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+
+	_ "github.com/go-sql-driver/mysql" // warehouse driver "mysql"
+	_ "github.com/lib/pq"              // warehouse driver "postgres"
+
+	"github.com/braunma/idrac-netbox-importer/internal/warehouse"
+	"github.com/braunma/idrac-netbox-importer/pkg/config"
+	"github.com/braunma/idrac-netbox-importer/pkg/errors"
+	"github.com/braunma/idrac-netbox-importer/pkg/logging"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+	"github.com/braunma/idrac-netbox-importer/pkg/runid"
+)
+
+// exportWarehouse upserts the results into the SQL warehouse of the
+// configuration (warehouse), if enabled.
+func exportWarehouse(ctx context.Context, cfg *config.Config, results []models.ServerInfo, stats models.CollectionStats) error {
+	if !cfg.Warehouse.IsEnabled() {
+		return nil
+	}
+	scanID := stats.RunID
+	if scanID == "" {
+		scanID = runid.Current()
+	}
+
+	e, err := warehouse.Open(cfg.Warehouse, cfg.ReadOnly)
+	if err != nil {
+		return err
+	}
+	defer e.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.Warehouse.Timeout())
+	defer cancel()
+	n, err := e.Export(ctx, scanID, results, stats)
+	if err != nil {
+		logging.Error("Failed to export to warehouse",
+			"driver", cfg.Warehouse.Driver,
+			"error", err,
+			"error_code", errors.CodeOf(err),
+		)
+		return err
+	}
+	logging.Info("Exported results to warehouse",
+		"driver", cfg.Warehouse.Driver,
+		"scan_id", scanID,
+		"servers", n,
+	)
+	return nil
+}
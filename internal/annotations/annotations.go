@@ -0,0 +1,185 @@
+// Package annotations merges free-form operational notes ("pending RMA",
+// "decommission Q3") into scan results, keyed by host or service tag, so the
+// context travels with the inventory.
+//
+// The annotations file is CSV with a header row or YAML:
+//
+//	host,service_tag,note
+//	,ABC1234,pending RMA
+//	10.0.0.5,,decommission Q3
+//
+//	annotations:
+//	  - service_tag: ABC1234
+//	    note: pending RMA
+//	  - host: 10.0.0.5
+//	    note: decommission Q3
+package annotations
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/braunma/idrac-netbox-importer/internal/csvtable"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+// Entry is one note of an annotations file. It applies to the server with
+// the host or the service tag; exactly one of them is set.
+type Entry struct {
+	Host       string `yaml:"host"`
+	ServiceTag string `yaml:"service_tag"`
+	Note       string `yaml:"note"`
+}
+
+// Map holds the notes of each host and service tag, in file order.
+type Map struct {
+	byHost map[string][]string
+	byTag  map[string][]string
+}
+
+// Load reads an annotations file. Files ending in .yaml or .yml are parsed
+// as YAML, everything else as CSV.
+func Load(path string) (*Map, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read annotations file: %w", err)
+	}
+	defer file.Close()
+
+	var entries []Entry
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		entries, err = parseYAML(file)
+	default:
+		entries, err = parseCSV(file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	m, err := New(entries)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return m, nil
+}
+
+// New builds a Map from entries. A host or service tag may have several
+// notes.
+func New(entries []Entry) (*Map, error) {
+	m := &Map{byHost: map[string][]string{}, byTag: map[string][]string{}}
+	for i, e := range entries {
+		host, tag, note := normalizeHost(e.Host), normalizeTag(e.ServiceTag), strings.TrimSpace(e.Note)
+		switch {
+		case host == "" && tag == "":
+			return nil, fmt.Errorf("entry %d: host or service_tag is required", i+1)
+		case host != "" && tag != "":
+			return nil, fmt.Errorf("entry %d: set either host or service_tag, not both", i+1)
+		case note == "":
+			return nil, fmt.Errorf("entry %d: note is required", i+1)
+		case host != "":
+			m.byHost[host] = append(m.byHost[host], note)
+		default:
+			m.byTag[tag] = append(m.byTag[tag], note)
+		}
+	}
+	return m, nil
+}
+
+// Len returns the number of hosts and service tags with notes.
+func (m *Map) Len() int {
+	return len(m.byHost) + len(m.byTag)
+}
+
+// Lookup returns the notes of a server: those of its service tag
+// (case-insensitive) followed by those of its host, without duplicates.
+func (m *Map) Lookup(host, serviceTag string) []string {
+	var notes []string
+	seen := map[string]bool{}
+	add := func(list []string) {
+		for _, n := range list {
+			if !seen[n] {
+				seen[n] = true
+				notes = append(notes, n)
+			}
+		}
+	}
+	if tag := normalizeTag(serviceTag); tag != "" {
+		add(m.byTag[tag])
+	}
+	if h := normalizeHost(host); h != "" {
+		add(m.byHost[h])
+	}
+	return notes
+}
+
+// Apply sets the notes of every result whose host or service tag is listed
+// and returns the number of results that got notes.
+func (m *Map) Apply(results []models.ServerInfo) int {
+	applied := 0
+	for i := range results {
+		if m.ApplyOne(&results[i]) {
+			applied++
+		}
+	}
+	return applied
+}
+
+// ApplyOne replaces the notes of res with those listed for its host and
+// service tag and reports whether there were any. Results without notes
+// keep the notes the scan recorded.
+func (m *Map) ApplyOne(res *models.ServerInfo) bool {
+	notes := m.Lookup(res.Host, res.ServiceTag)
+	if len(notes) == 0 {
+		return false
+	}
+	res.Notes = notes
+	return true
+}
+
+func parseYAML(r io.Reader) ([]Entry, error) {
+	var f struct {
+		Annotations []Entry `yaml:"annotations"`
+	}
+	if err := yaml.NewDecoder(r).Decode(&f); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("invalid annotations YAML: %w", err)
+	}
+	return f.Annotations, nil
+}
+
+// parseCSV reads annotations from CSV with a note column and a host or
+// service_tag column (or both).
+func parseCSV(r io.Reader) ([]Entry, error) {
+	t, err := csvtable.Read(r, "annotations")
+	if err != nil {
+		return nil, err
+	}
+	if t.Len() == 0 {
+		return nil, nil
+	}
+	if err := t.Require("note"); err != nil {
+		return nil, err
+	}
+	if !t.Has("host") && !t.Has("service_tag") {
+		return nil, fmt.Errorf("CSV header has neither a host nor a service_tag column")
+	}
+
+	var entries []Entry
+	for n := 0; n < t.Len(); n++ {
+		entries = append(entries, Entry{Host: t.Get(n, "host"), ServiceTag: t.Get(n, "service_tag"), Note: t.Get(n, "note")})
+	}
+	return entries, nil
+}
+
+func normalizeTag(tag string) string {
+	return strings.ToUpper(strings.TrimSpace(tag))
+}
+
+func normalizeHost(host string) string {
+	return strings.ToLower(strings.TrimSpace(host))
+}
@@ -0,0 +1,85 @@
+package annotations
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoad_CSV(t *testing.T) {
+	path := writeFile(t, "annotations.csv", `# maintained by the DC team
+Host,Service_Tag,Note,Owner
+, abc1234, pending RMA, ops
+10.0.0.5,,"decommission Q3, see ticket 42",
+,ABC1234,pending RMA,
+`)
+	m, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, 2, m.Len())
+
+	assert.Equal(t, []string{"pending RMA"}, m.Lookup("10.0.0.9", "ABC1234"), "duplicate notes are dropped")
+	assert.Equal(t, []string{"decommission Q3, see ticket 42"}, m.Lookup("10.0.0.5", ""))
+	assert.Equal(t, []string{"pending RMA", "decommission Q3, see ticket 42"}, m.Lookup("10.0.0.5", "abc1234"))
+	assert.Empty(t, m.Lookup("10.0.0.6", "XYZ9876"))
+}
+
+func TestLoad_YAML(t *testing.T) {
+	path := writeFile(t, "annotations.yaml", `annotations:
+  - service_tag: ABC1234
+    note: pending RMA
+  - host: iDRAC-01.example.com
+    note: decommission Q3
+`)
+	m, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"decommission Q3"}, m.Lookup("idrac-01.example.com", ""))
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	for name, content := range map[string]string{
+		"no note column":   "host,service_tag\n10.0.0.5,\n",
+		"no target column": "name,note\nweb1,old\n",
+		"missing target":   "host,service_tag,note\n,,pending RMA\n",
+		"both targets":     "host,service_tag,note\n10.0.0.5,ABC1234,pending RMA\n",
+		"missing note":     "host,note\n10.0.0.5,\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := Load(writeFile(t, "annotations.csv", content))
+			assert.Error(t, err)
+		})
+	}
+
+	_, err := Load(filepath.Join(t.TempDir(), "missing.csv"))
+	assert.Error(t, err)
+}
+
+func TestMap_Apply(t *testing.T) {
+	m, err := New([]Entry{
+		{ServiceTag: "ABC1234", Note: "pending RMA"},
+		{Host: "10.0.0.2", Note: "unreachable since the move"},
+	})
+	require.NoError(t, err)
+
+	results := []models.ServerInfo{
+		{Host: "10.0.0.1", ServiceTag: "abc1234"},
+		{Host: "10.0.0.2", Error: errors.New("timeout")},
+		{Host: "10.0.0.3", ServiceTag: "DEF5678"},
+	}
+	assert.Equal(t, 2, m.Apply(results))
+	assert.Equal(t, []string{"pending RMA"}, results[0].Notes)
+	assert.Equal(t, []string{"unreachable since the move"}, results[1].Notes)
+	assert.Nil(t, results[2].Notes)
+}
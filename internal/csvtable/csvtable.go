@@ -0,0 +1,76 @@
+// Package csvtable reads the CSV files that complement the config (scan
+// targets, placement, annotations): a header row naming the columns,
+// matched case-insensitively, then one record per row. Unknown columns,
+// such as the rest of a DCIM export, are ignored, and lines starting with
+// # are comments.
+package csvtable
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Table is a CSV file with a header row.
+type Table struct {
+	cols    map[string]int
+	records [][]string // without the header
+}
+
+// Read reads a table; what names the file in errors, e.g. "placement".
+// Empty input yields an empty table without columns.
+func Read(r io.Reader, what string) (*Table, error) {
+	cr := csv.NewReader(r)
+	cr.Comment = '#'
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s CSV: %w", what, err)
+	}
+	t := &Table{cols: map[string]int{}}
+	if len(records) == 0 {
+		return t, nil
+	}
+	for i, name := range records[0] {
+		t.cols[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	t.records = records[1:]
+	return t, nil
+}
+
+// Has reports whether the header has the column.
+func (t *Table) Has(col string) bool {
+	_, ok := t.cols[col]
+	return ok
+}
+
+// Require returns an error naming the first of the columns missing from
+// the header. An empty table has all columns.
+func (t *Table) Require(cols ...string) error {
+	if len(t.cols) == 0 {
+		return nil
+	}
+	for _, col := range cols {
+		if !t.Has(col) {
+			return fmt.Errorf("CSV header has no %s column", col)
+		}
+	}
+	return nil
+}
+
+// Len returns the number of rows after the header.
+func (t *Table) Len() int {
+	return len(t.records)
+}
+
+// Get returns the trimmed value of a column in row n (from 0), or "" if
+// the column is missing or the row is shorter.
+func (t *Table) Get(n int, col string) string {
+	if i, ok := t.cols[col]; ok && i < len(t.records[n]) {
+		return strings.TrimSpace(t.records[n][i])
+	}
+	return ""
+}
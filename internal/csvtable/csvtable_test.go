@@ -0,0 +1,36 @@
+package csvtable
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRead(t *testing.T) {
+	tab, err := Read(strings.NewReader(`# DCIM export
+Service_Tag, Rack ,Extra
+ABC1234,  R01, x
+DEF5678
+`), "placement")
+	require.NoError(t, err)
+	require.Equal(t, 2, tab.Len())
+	assert.True(t, tab.Has("rack"))
+	assert.False(t, tab.Has("site"))
+	assert.Equal(t, "ABC1234", tab.Get(0, "service_tag"))
+	assert.Equal(t, "R01", tab.Get(0, "rack"))
+	assert.Equal(t, "", tab.Get(1, "rack"), "short row")
+	assert.Equal(t, "", tab.Get(0, "site"), "missing column")
+
+	assert.NoError(t, tab.Require("service_tag", "rack"))
+	assert.EqualError(t, tab.Require("service_tag", "site"), "CSV header has no site column")
+
+	tab, err = Read(strings.NewReader(""), "placement")
+	require.NoError(t, err)
+	assert.Equal(t, 0, tab.Len())
+	assert.NoError(t, tab.Require("service_tag"), "an empty file has no rows to check")
+
+	_, err = Read(strings.NewReader("a,\"b\n"), "targets")
+	assert.ErrorContains(t, err, "invalid targets CSV")
+}
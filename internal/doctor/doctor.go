@@ -0,0 +1,468 @@
+// Package doctor checks the runtime prerequisites of a config before the
+// first scan: DNS and reachability of the targets, the NetBox API, git for
+// the GitLab export, the certificate trust store, the state directory and
+// the environment. Each failed check carries a remediation hint.
+package doctor
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/config"
+	"github.com/braunma/idrac-netbox-importer/pkg/defaults"
+)
+
+// Status is the outcome of a check.
+type Status string
+
+const (
+	OK   Status = "ok"
+	Warn Status = "warn"
+	Fail Status = "fail"
+	Skip Status = "skip" // not applicable to the config
+)
+
+// Result is the outcome of one check.
+type Result struct {
+	Check  string `json:"check"`
+	Status Status `json:"status"`
+	Detail string `json:"detail"`
+	Hint   string `json:"hint,omitempty"`
+}
+
+// DefaultSamples is the number of targets whose DNS and reachability are
+// checked unless configured otherwise.
+const DefaultSamples = 5
+
+// dialTimeout limits each TCP connection attempt.
+const dialTimeout = 5 * time.Second
+
+// caBundles are the CA bundles Go looks for on Linux.
+var caBundles = []string{
+	"/etc/ssl/certs/ca-certificates.crt",
+	"/etc/pki/tls/certs/ca-bundle.crt",
+	"/etc/ssl/ca-bundle.pem",
+	"/etc/pki/tls/cacert.pem",
+	"/etc/pki/ca-trust/extracted/pem/tls-ca-bundle.pem",
+	"/etc/ssl/cert.pem",
+}
+
+// Doctor runs the checks of a config.
+type Doctor struct {
+	cfg *config.Config
+
+	// Samples is the number of targets checked (default: DefaultSamples).
+	Samples int
+
+	// NetBox tests the NetBox API (status endpoint and token), after DNS
+	// and TCP succeeded; nil skips the API test.
+	NetBox func(ctx context.Context) error
+
+	lookupHost func(ctx context.Context, host string) ([]string, error)
+	dial       func(ctx context.Context, network, addr string) (net.Conn, error)
+	lookPath   func(file string) (string, error)
+	getenv     func(key string) string
+	caBundles  []string
+}
+
+// New returns a doctor for cfg.
+func New(cfg *config.Config) *Doctor {
+	dialer := &net.Dialer{Timeout: dialTimeout}
+	return &Doctor{
+		cfg:        cfg,
+		Samples:    DefaultSamples,
+		lookupHost: net.DefaultResolver.LookupHost,
+		dial:       dialer.DialContext,
+		lookPath:   exec.LookPath,
+		getenv:     os.Getenv,
+		caBundles:  caBundles,
+	}
+}
+
+// Run runs all checks in order.
+func (d *Doctor) Run(ctx context.Context) []Result {
+	return []Result{
+		d.checkEnvironment(),
+		d.checkTrustStore(),
+		d.checkTargetDNS(ctx),
+		d.checkTargetReachability(ctx),
+		d.checkNetBox(ctx),
+		d.checkGit(),
+		d.checkStateDir(),
+	}
+}
+
+// Failed returns the number of failed checks.
+func Failed(results []Result) int {
+	n := 0
+	for _, r := range results {
+		if r.Status == Fail {
+			n++
+		}
+	}
+	return n
+}
+
+// envVars are the environment variables reported by the environment check:
+// credentials and proxy settings. Only their names are printed.
+var envVars = []string{
+	defaults.EnvDefaultUsername, defaults.EnvDefaultPassword,
+	defaults.EnvNetBoxURL, defaults.EnvNetBoxToken,
+	defaults.EnvNetBoxOAuth2ClientID, defaults.EnvNetBoxOAuth2ClientSecret,
+	defaults.EnvProfile,
+	"HTTPS_PROXY", "HTTP_PROXY", "NO_PROXY",
+}
+
+// checkEnvironment reports the environment variables that are set and the
+// servers that have no credentials.
+func (d *Doctor) checkEnvironment() Result {
+	r := Result{Check: "environment", Status: OK}
+
+	var set []string
+	for _, name := range envVars {
+		if d.getenv(name) != "" || d.getenv(strings.ToLower(name)) != "" {
+			set = append(set, name)
+		}
+	}
+	r.Detail = "no relevant variables set"
+	if len(set) > 0 {
+		r.Detail = "set: " + strings.Join(set, ", ")
+	}
+
+	missing := 0
+	for _, srv := range d.cfg.Servers {
+		if srv.ClientCert != "" {
+			continue
+		}
+		if srv.GetUsername(d.cfg.Defaults.Username) == "" || srv.GetPassword(d.cfg.Defaults.Password) == "" {
+			missing++
+		}
+	}
+	if missing > 0 {
+		r.Status = Fail
+		r.Detail = fmt.Sprintf("%d servers have no username or password; %s", missing, r.Detail)
+		r.Hint = fmt.Sprintf("set defaults.username/password, or export %s and %s (e.g. with -env-file)",
+			defaults.EnvDefaultUsername, defaults.EnvDefaultPassword)
+		return r
+	}
+
+	if d.cfg.NetBox.URL != "" && d.cfg.NetBox.Token == "" && d.cfg.NetBox.TokenCommand == "" && d.cfg.NetBox.Auth.GetMethod() == config.NetBoxAuthToken {
+		r.Status = Warn
+		r.Detail = "netbox.url is set but no API token; " + r.Detail
+		r.Hint = fmt.Sprintf("set netbox.token or netbox.token_command, or export %s", defaults.EnvNetBoxToken)
+	}
+	return r
+}
+
+// checkTrustStore checks that the system trust store can be loaded and that
+// a CA bundle exists, and parses the NetBox CA certificate.
+func (d *Doctor) checkTrustStore() Result {
+	r := Result{Check: "trust store", Status: OK}
+
+	for _, name := range []string{"SSL_CERT_FILE", "SSL_CERT_DIR"} {
+		path := d.getenv(name)
+		if path == "" {
+			continue
+		}
+		for _, p := range filepath.SplitList(path) {
+			if _, err := os.Stat(p); err != nil {
+				r.Status = Fail
+				r.Detail = fmt.Sprintf("%s: %v", name, err)
+				r.Hint = fmt.Sprintf("point %s at an existing CA bundle or unset it", name)
+				return r
+			}
+		}
+		r.Detail = name + " " + path
+	}
+
+	if _, err := x509.SystemCertPool(); err != nil {
+		r.Status = Fail
+		r.Detail = fmt.Sprintf("system trust store: %v", err)
+		r.Hint = "install the CA certificates package of the OS (e.g. ca-certificates)"
+		return r
+	}
+
+	if r.Detail == "" && runtime.GOOS == "linux" {
+		for _, bundle := range d.caBundles {
+			if _, err := os.Stat(bundle); err == nil {
+				r.Detail = "system CA bundle " + bundle
+				break
+			}
+		}
+		if r.Detail == "" {
+			r.Status = Warn
+			r.Detail = "no system CA bundle found"
+			r.Hint = "install ca-certificates (minimal container images have none) or set SSL_CERT_FILE; NetBox over https will fail"
+		}
+	}
+
+	if ca := d.cfg.NetBox.CACert; ca != "" && !x509.NewCertPool().AppendCertsFromPEM([]byte(ca)) {
+		r.Status = Fail
+		r.Detail = "netbox.ca_cert contains no PEM certificate"
+		r.Hint = "paste the PEM-encoded CA certificate (-----BEGIN CERTIFICATE-----) into netbox.ca_cert"
+	}
+	return r
+}
+
+// samples returns the targets (host, or host:port) of the first Samples
+// servers, without duplicates.
+func (d *Doctor) samples() []string {
+	n := d.Samples
+	if n <= 0 {
+		n = DefaultSamples
+	}
+	seen := make(map[string]bool)
+	var hosts []string
+	for _, srv := range d.cfg.Servers {
+		if len(hosts) == n {
+			break
+		}
+		if !seen[srv.Host] {
+			seen[srv.Host] = true
+			hosts = append(hosts, srv.Host)
+		}
+	}
+	return hosts
+}
+
+// checkTargetDNS resolves the sample targets that are host names.
+func (d *Doctor) checkTargetDNS(ctx context.Context) Result {
+	r := Result{Check: "target DNS"}
+	if len(d.cfg.Servers) == 0 {
+		return d.noTargets(r)
+	}
+
+	var names, failed []string
+	var lastErr error
+	for _, target := range d.samples() {
+		host := hostOnly(target)
+		if net.ParseIP(host) != nil {
+			continue
+		}
+		names = append(names, host)
+		if _, err := d.lookupHost(ctx, host); err != nil {
+			failed = append(failed, host)
+			lastErr = err
+		}
+	}
+
+	switch {
+	case len(names) == 0:
+		r.Status = Skip
+		r.Detail = "sample targets are IP addresses"
+	case len(failed) > 0:
+		r.Status = Fail
+		r.Detail = fmt.Sprintf("%d of %d names did not resolve: %s (%v)", len(failed), len(names), strings.Join(failed, ", "), lastErr)
+		r.Hint = "check the resolver of this host (/etc/resolv.conf, container DNS) or use the iDRAC IP addresses"
+	default:
+		r.Status = OK
+		r.Detail = fmt.Sprintf("%d names resolved", len(names))
+	}
+	return r
+}
+
+// checkTargetReachability connects to the HTTPS port of the sample targets.
+func (d *Doctor) checkTargetReachability(ctx context.Context) Result {
+	r := Result{Check: "target reachability"}
+	if len(d.cfg.Servers) == 0 {
+		return d.noTargets(r)
+	}
+
+	hosts := d.samples()
+	var failed []string
+	var lastErr error
+	for _, host := range hosts {
+		conn, err := d.dial(ctx, "tcp", withPort(host))
+		if err != nil {
+			failed = append(failed, host)
+			lastErr = err
+			continue
+		}
+		conn.Close()
+	}
+
+	switch {
+	case len(failed) == len(hosts):
+		r.Status = Fail
+		r.Detail = fmt.Sprintf("none of %d sample targets accepted a connection (%v)", len(hosts), lastErr)
+		r.Hint = "allow outbound HTTPS (TCP 443) from this host to the iDRAC network (firewall, VLAN routing)"
+	case len(failed) > 0:
+		r.Status = Warn
+		r.Detail = fmt.Sprintf("%d of %d sample targets unreachable: %s", len(failed), len(hosts), strings.Join(failed, ", "))
+		r.Hint = "the hosts may be powered off or behind a firewall; they will fail the scan"
+	default:
+		r.Status = OK
+		r.Detail = fmt.Sprintf("%d sample targets reachable", len(hosts))
+	}
+	return r
+}
+
+// noTargets is the result of the target checks without configured servers.
+func (d *Doctor) noTargets(r Result) Result {
+	if d.cfg.NetBox.Targets.IsEnabled() {
+		r.Status = Skip
+		r.Detail = "targets are read from NetBox at scan time"
+		return r
+	}
+	r.Status = Fail
+	r.Detail = "no servers configured"
+	r.Hint = "add servers, server_groups or a targets file to the config"
+	return r
+}
+
+// checkNetBox resolves and connects to NetBox and tests the API.
+func (d *Doctor) checkNetBox(ctx context.Context) Result {
+	r := Result{Check: "netbox"}
+	if d.cfg.NetBox.URL == "" {
+		r.Status = Skip
+		r.Detail = "netbox.url not set"
+		return r
+	}
+
+	u, err := url.Parse(d.cfg.NetBox.URL)
+	if err != nil || u.Hostname() == "" {
+		r.Status = Fail
+		r.Detail = fmt.Sprintf("invalid netbox.url %q", d.cfg.NetBox.URL)
+		r.Hint = "set netbox.url to the base URL, e.g. https://netbox.example.com"
+		return r
+	}
+	port := u.Port()
+	if port == "" {
+		port = "443"
+		if u.Scheme == "http" {
+			port = "80"
+		}
+	}
+
+	proxied := d.getenv("HTTPS_PROXY") != "" || d.getenv("https_proxy") != ""
+	if !proxied {
+		if net.ParseIP(u.Hostname()) == nil {
+			if _, err := d.lookupHost(ctx, u.Hostname()); err != nil {
+				r.Status = Fail
+				r.Detail = fmt.Sprintf("%s did not resolve: %v", u.Hostname(), err)
+				r.Hint = "check the resolver of this host, or set HTTPS_PROXY if NetBox is only reachable through a proxy"
+				return r
+			}
+		}
+		conn, err := d.dial(ctx, "tcp", net.JoinHostPort(u.Hostname(), port))
+		if err != nil {
+			r.Status = Fail
+			r.Detail = fmt.Sprintf("cannot connect to %s: %v", net.JoinHostPort(u.Hostname(), port), err)
+			r.Hint = "allow outbound connections to NetBox, or set HTTPS_PROXY"
+			return r
+		}
+		conn.Close()
+	}
+
+	if d.NetBox != nil {
+		if err := d.NetBox(ctx); err != nil {
+			r.Status = Fail
+			r.Detail = err.Error()
+			r.Hint = netBoxHint(err)
+			return r
+		}
+	}
+	r.Status = OK
+	r.Detail = "API reachable at " + u.Host
+	if proxied {
+		r.Detail += " (through HTTPS_PROXY)"
+	}
+	return r
+}
+
+// netBoxHint returns the remediation of a failed NetBox API test.
+func netBoxHint(err error) string {
+	var unknownAuthority x509.UnknownAuthorityError
+	var hostname x509.HostnameError
+	msg := err.Error()
+	switch {
+	case errors.As(err, &unknownAuthority) || strings.Contains(msg, "certificate signed by unknown authority"):
+		return "add the NetBox CA to netbox.ca_cert or to the system trust store (SSL_CERT_FILE)"
+	case errors.As(err, &hostname) || strings.Contains(msg, "certificate is valid for"):
+		return "netbox.url must use a host name the NetBox certificate is issued for"
+	case strings.Contains(msg, "401") || strings.Contains(msg, "403"):
+		return fmt.Sprintf("check the API token (netbox.token or %s) and its permissions", defaults.EnvNetBoxToken)
+	case strings.Contains(msg, "404"):
+		return "netbox.url must be the base URL of NetBox, without /api"
+	}
+	return "check netbox.url and that NetBox is running"
+}
+
+// checkGit checks git and the repository of the GitLab export.
+func (d *Doctor) checkGit() Result {
+	r := Result{Check: "git"}
+	gl := d.cfg.GitLab
+	if !gl.IsEnabled() {
+		r.Status = Skip
+		r.Detail = "gitlab.repo_path not set"
+		return r
+	}
+
+	path, err := d.lookPath("git")
+	if err != nil {
+		r.Status = Fail
+		r.Detail = "git not found in PATH"
+		r.Hint = "install git (e.g. apk add git or apt-get install git) for the GitLab export"
+		return r
+	}
+	if _, err := os.Stat(filepath.Join(gl.RepoPath, ".git")); err != nil {
+		r.Status = Fail
+		r.Detail = fmt.Sprintf("%s is not a git repository", gl.RepoPath)
+		r.Hint = "clone the inventory repository to gitlab.repo_path first"
+		return r
+	}
+	r.Status = OK
+	r.Detail = fmt.Sprintf("%s, repository %s", path, gl.RepoPath)
+	return r
+}
+
+// checkStateDir checks that the state directory (history, schedule,
+// results) is writable.
+func (d *Doctor) checkStateDir() Result {
+	r := Result{Check: "state directory"}
+	dir := d.cfg.Paths.GetStateDir()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		r.Status = Fail
+		r.Detail = err.Error()
+		r.Hint = "set paths.state_dir to a writable directory (in containers: a mounted volume)"
+		return r
+	}
+	f, err := os.CreateTemp(dir, ".doctor-*")
+	if err != nil {
+		r.Status = Fail
+		r.Detail = fmt.Sprintf("%s is not writable: %v", dir, err)
+		r.Hint = "fix the permissions of paths.state_dir or point it at a writable directory"
+		return r
+	}
+	f.Close()
+	os.Remove(f.Name())
+	r.Status = OK
+	r.Detail = dir + " is writable"
+	return r
+}
+
+// hostOnly strips the port of a target such as "10.0.0.1:8443".
+func hostOnly(target string) string {
+	if host, _, err := net.SplitHostPort(target); err == nil {
+		return host
+	}
+	return target
+}
+
+// withPort returns the address of a target, on port 443 unless it has one.
+func withPort(target string) string {
+	if _, _, err := net.SplitHostPort(target); err == nil {
+		return target
+	}
+	return net.JoinHostPort(target, "443")
+}
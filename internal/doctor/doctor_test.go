@@ -0,0 +1,171 @@
+package doctor
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/config"
+)
+
+// fakeDoctor returns a doctor whose DNS knows the names in hosts and whose
+// dialer accepts connections to the addresses in open.
+func fakeDoctor(t *testing.T, cfg *config.Config, hosts map[string]bool, open map[string]bool) *Doctor {
+	t.Helper()
+	d := New(cfg)
+	d.lookupHost = func(_ context.Context, host string) ([]string, error) {
+		if hosts[host] {
+			return []string{"10.9.9.9"}, nil
+		}
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+	d.dial = func(_ context.Context, _, addr string) (net.Conn, error) {
+		if open[addr] {
+			client, server := net.Pipe()
+			server.Close()
+			return client, nil
+		}
+		return nil, errors.New("connection refused")
+	}
+	d.lookPath = func(string) (string, error) { return "", errors.New("not found") }
+	d.getenv = func(string) string { return "" }
+	return d
+}
+
+func byCheck(results []Result) map[string]Result {
+	m := make(map[string]Result)
+	for _, r := range results {
+		m[r.Check] = r
+	}
+	return m
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.Config{
+		Servers: []config.ServerConfig{
+			{Host: "idrac-01.example.com"},
+			{Host: "idrac-02.example.com"},
+			{Host: "10.0.0.3:8443"},
+		},
+		Defaults: config.DefaultsConfig{Username: "root", Password: "secret"},
+		NetBox:   config.NetBoxConfig{URL: "https://netbox.example.com", Token: "token"},
+		GitLab:   config.GitLabConfig{RepoPath: dir},
+		Paths:    config.PathsConfig{StateDir: filepath.Join(dir, "state")},
+	}
+	d := fakeDoctor(t, cfg,
+		map[string]bool{"idrac-01.example.com": true, "netbox.example.com": true},
+		map[string]bool{"idrac-01.example.com:443": true, "10.0.0.3:8443": true, "netbox.example.com:443": true},
+	)
+	d.NetBox = func(context.Context) error { return nil }
+
+	results := byCheck(d.Run(context.Background()))
+
+	assert.Equal(t, OK, results["environment"].Status)
+
+	dns := results["target DNS"]
+	assert.Equal(t, Fail, dns.Status)
+	assert.Contains(t, dns.Detail, "1 of 2 names did not resolve: idrac-02.example.com")
+	assert.NotEmpty(t, dns.Hint)
+
+	reach := results["target reachability"]
+	assert.Equal(t, Warn, reach.Status, "some targets reachable")
+	assert.Contains(t, reach.Detail, "1 of 3")
+
+	assert.Equal(t, OK, results["netbox"].Status)
+
+	git := results["git"]
+	assert.Equal(t, Fail, git.Status)
+	assert.Equal(t, "git not found in PATH", git.Detail)
+
+	assert.Equal(t, OK, results["state directory"].Status)
+	assert.Equal(t, 2, Failed(d.Run(context.Background())))
+}
+
+func TestCheckEnvironment(t *testing.T) {
+	cfg := &config.Config{
+		Servers: []config.ServerConfig{{Host: "10.0.0.1"}, {Host: "10.0.0.2", Username: "root", Password: "x"}},
+		NetBox:  config.NetBoxConfig{URL: "https://netbox.example.com"},
+	}
+	d := fakeDoctor(t, cfg, nil, nil)
+	d.getenv = func(key string) string {
+		if key == "https_proxy" {
+			return "http://proxy:3128"
+		}
+		return ""
+	}
+
+	r := d.checkEnvironment()
+	assert.Equal(t, Fail, r.Status)
+	assert.Contains(t, r.Detail, "1 servers have no username or password")
+	assert.Contains(t, r.Detail, "set: HTTPS_PROXY")
+	assert.Contains(t, r.Hint, "IDRAC_DEFAULT_USER")
+
+	cfg.Defaults = config.DefaultsConfig{Username: "root", Password: "secret"}
+	r = d.checkEnvironment()
+	assert.Equal(t, Warn, r.Status, "NetBox without a token")
+	assert.Contains(t, r.Hint, "NETBOX_TOKEN")
+}
+
+func TestCheckNetBox(t *testing.T) {
+	cfg := &config.Config{NetBox: config.NetBoxConfig{URL: "https://netbox.example.com:8443"}}
+
+	d := fakeDoctor(t, cfg, nil, nil)
+	r := d.checkNetBox(context.Background())
+	assert.Equal(t, Fail, r.Status)
+	assert.Contains(t, r.Detail, "did not resolve")
+
+	d = fakeDoctor(t, cfg, map[string]bool{"netbox.example.com": true}, nil)
+	r = d.checkNetBox(context.Background())
+	assert.Equal(t, Fail, r.Status)
+	assert.Contains(t, r.Detail, "cannot connect to netbox.example.com:8443")
+
+	d = fakeDoctor(t, cfg, map[string]bool{"netbox.example.com": true}, map[string]bool{"netbox.example.com:8443": true})
+	d.NetBox = func(context.Context) error {
+		return errors.New(`Get "https://netbox.example.com:8443/api/status/": tls: failed to verify certificate: x509: certificate signed by unknown authority`)
+	}
+	r = d.checkNetBox(context.Background())
+	assert.Equal(t, Fail, r.Status)
+	assert.Contains(t, r.Hint, "netbox.ca_cert")
+
+	r = fakeDoctor(t, &config.Config{}, nil, nil).checkNetBox(context.Background())
+	assert.Equal(t, Skip, r.Status)
+}
+
+func TestCheckGit(t *testing.T) {
+	dir := t.TempDir()
+	d := fakeDoctor(t, &config.Config{GitLab: config.GitLabConfig{RepoPath: dir}}, nil, nil)
+	d.lookPath = func(string) (string, error) { return "/usr/bin/git", nil }
+
+	r := d.checkGit()
+	assert.Equal(t, Fail, r.Status)
+	assert.Contains(t, r.Detail, "is not a git repository")
+
+	require.NoError(t, os.Mkdir(filepath.Join(dir, ".git"), 0o755))
+	assert.Equal(t, OK, d.checkGit().Status)
+}
+
+func TestCheckTrustStore(t *testing.T) {
+	d := fakeDoctor(t, &config.Config{NetBox: config.NetBoxConfig{CACert: "not a certificate"}}, nil, nil)
+	d.caBundles = []string{filepath.Join(t.TempDir(), "missing.crt")}
+
+	r := d.checkTrustStore()
+	assert.Equal(t, Fail, r.Status)
+	assert.Contains(t, r.Detail, "netbox.ca_cert")
+
+	d.getenv = func(key string) string {
+		if key == "SSL_CERT_FILE" {
+			return "/nonexistent/ca.pem"
+		}
+		return ""
+	}
+	r = d.checkTrustStore()
+	assert.Equal(t, Fail, r.Status)
+	assert.Contains(t, r.Detail, "SSL_CERT_FILE")
+}
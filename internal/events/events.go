@@ -0,0 +1,247 @@
+// Package events receives the Redfish events that iDRACs push to daemon
+// mode and collects the hosts to rescan. Events that may change the
+// inventory (a replaced part, a firmware update) or have a triggering
+// severity mark their host; the hosts are handed out once the debounce
+// period after the first event has passed, so a burst of events of one
+// maintenance leads to a single rescan.
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/braunma/idrac-netbox-importer/internal/redfish"
+	"github.com/braunma/idrac-netbox-importer/pkg/logging"
+	"github.com/braunma/idrac-netbox-importer/pkg/scanner"
+)
+
+// maxPayload bounds the size of an event payload.
+const maxPayload = 1 << 20
+
+// Filter selects the events that trigger a rescan.
+type Filter struct {
+	// MessageIDs are prefixes of the last part of the MessageId, e.g. "PDR"
+	// for "iDRAC.2.8.PDR1016".
+	MessageIDs []string
+	// Severities trigger a rescan whatever the message.
+	Severities []string
+}
+
+// Matches reports whether an event triggers a rescan.
+func (f Filter) Matches(rec redfish.EventRecord) bool {
+	for _, severity := range f.Severities {
+		if strings.EqualFold(rec.Severity, severity) {
+			return true
+		}
+	}
+	id := rec.MessageID
+	if i := strings.LastIndex(id, "."); i >= 0 {
+		id = id[i+1:]
+	}
+	for _, prefix := range f.MessageIDs {
+		if prefix != "" && strings.HasPrefix(strings.ToUpper(id), strings.ToUpper(prefix)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Receiver is the HTTP handler of the event destination.
+type Receiver struct {
+	filter   Filter
+	debounce time.Duration
+	hosts    map[string]bool
+	logger   *zap.SugaredLogger
+
+	// lookupHost resolves the host names of the config, to check that an
+	// event comes from the host its Context names
+	lookupHost func(ctx context.Context, host string) ([]string, error)
+
+	mu      sync.Mutex
+	pending map[string]bool
+	timer   *time.Timer
+	ready   chan struct{}
+}
+
+// NewReceiver returns a receiver accepting events of the given hosts (as in
+// the config file).
+func NewReceiver(hosts []string, filter Filter, debounce time.Duration) *Receiver {
+	known := make(map[string]bool, len(hosts))
+	for _, h := range hosts {
+		known[h] = true
+	}
+	return &Receiver{
+		filter:   filter,
+		debounce: debounce,
+		hosts:    known,
+		logger:   logging.WithComponent("events"),
+		pending:  make(map[string]bool),
+		ready:    make(chan struct{}, 1),
+
+		lookupHost: net.DefaultResolver.LookupHost,
+	}
+}
+
+// ServeHTTP accepts an event payload. The sending host is taken from the
+// Context of subscriptions created by scanner.Subscribe, or else from the
+// remote address; events of hosts not in the config, and events whose
+// Context names a host other than the sender, are refused.
+func (r *Receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var event redfish.Event
+	if err := json.NewDecoder(io.LimitReader(req.Body, maxPayload)).Decode(&event); err != nil {
+		http.Error(w, "invalid event payload", http.StatusBadRequest)
+		return
+	}
+
+	remote, _, _ := net.SplitHostPort(req.RemoteAddr)
+	host := strings.TrimPrefix(event.Context, scanner.EventContextPrefix)
+	if host == event.Context {
+		host = remote
+	}
+	if !r.hosts[host] {
+		r.logger.Warnw("ignoring event of unknown host", "host", host, "remote_addr", req.RemoteAddr)
+		http.Error(w, "unknown host", http.StatusForbidden)
+		return
+	}
+	if !r.sentBy(req.Context(), host, remote) {
+		r.logger.Warnw("ignoring event not sent by the host of its context", "host", host, "remote_addr", req.RemoteAddr)
+		http.Error(w, "host does not match sender", http.StatusForbidden)
+		return
+	}
+
+	for _, rec := range event.Events {
+		log := r.logger.With(
+			"host", host,
+			"message_id", rec.MessageID,
+			"severity", rec.Severity,
+			"message", rec.Message,
+		)
+		if !r.filter.Matches(rec) {
+			log.Debugw("event received")
+			continue
+		}
+		log.Infow("event received, scheduling rescan", "debounce", r.debounce)
+		r.mark(host)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// sentBy reports whether the remote address is an address of host: the
+// host itself if it is an IP address, else one its name resolves to.
+func (r *Receiver) sentBy(ctx context.Context, host, remote string) bool {
+	remoteIP := net.ParseIP(remote)
+	if remoteIP == nil {
+		return false
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return ip.Equal(remoteIP)
+	}
+	addrs, err := r.lookupHost(ctx, host)
+	if err != nil {
+		r.logger.Warnw("failed to resolve host of event", "host", host, "error", err)
+		return false
+	}
+	for _, addr := range addrs {
+		if ip := net.ParseIP(addr); ip != nil && ip.Equal(remoteIP) {
+			return true
+		}
+	}
+	return false
+}
+
+// mark adds host to the pending hosts and starts the debounce timer if it
+// is the first one.
+func (r *Receiver) mark(host string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pending[host] = true
+	if r.timer == nil {
+		r.timer = time.AfterFunc(r.debounce, r.signal)
+	}
+}
+
+func (r *Receiver) signal() {
+	select {
+	case r.ready <- struct{}{}:
+	default:
+	}
+}
+
+// Ready returns a channel that receives a value when hosts are due for a
+// rescan. A nil receiver never becomes ready.
+func (r *Receiver) Ready() <-chan struct{} {
+	if r == nil {
+		return nil
+	}
+	return r.ready
+}
+
+// Requeue makes hosts taken with Take pending again, due after delay
+// unless other hosts are due earlier, e.g. when a blackout window holds
+// rescans.
+func (r *Receiver) Requeue(hosts []string, delay time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, h := range hosts {
+		r.pending[h] = true
+	}
+	if len(r.pending) > 0 && r.timer == nil {
+		r.timer = time.AfterFunc(delay, r.signal)
+	}
+}
+
+// Take returns the pending hosts, sorted, and clears them.
+func (r *Receiver) Take() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	hosts := make([]string, 0, len(r.pending))
+	for h := range r.pending {
+		hosts = append(hosts, h)
+	}
+	sort.Strings(hosts)
+	r.pending = make(map[string]bool)
+	if r.timer != nil {
+		r.timer.Stop()
+		r.timer = nil
+	}
+	return hosts
+}
+
+// ListenAndServe serves the receiver on addr until ctx is cancelled, with
+// TLS if certFile and keyFile are set.
+func (r *Receiver) ListenAndServe(ctx context.Context, addr, certFile, keyFile string) error {
+	srv := &http.Server{Addr: addr, Handler: r, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+
+	var err error
+	if certFile != "" {
+		err = srv.ListenAndServeTLS(certFile, keyFile)
+	} else {
+		err = srv.ListenAndServe()
+	}
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/braunma/idrac-netbox-importer/internal/redfish"
+)
+
+func TestFilter_Matches(t *testing.T) {
+	f := Filter{MessageIDs: []string{"PDR", "ResourceAdded"}, Severities: []string{"Critical"}}
+
+	assert.True(t, f.Matches(redfish.EventRecord{MessageID: "iDRAC.2.8.PDR1016", Severity: "Warning"}))
+	assert.True(t, f.Matches(redfish.EventRecord{MessageID: "ResourceEvent.1.0.ResourceAdded"}))
+	assert.True(t, f.Matches(redfish.EventRecord{MessageID: "iDRAC.2.8.TMP0120", Severity: "critical"}))
+	assert.False(t, f.Matches(redfish.EventRecord{MessageID: "iDRAC.2.8.TMP0120", Severity: "Warning"}))
+	assert.False(t, f.Matches(redfish.EventRecord{MessageID: "iDRAC.2.8.USR0030", Severity: "OK"}))
+}
+
+func post(r *Receiver, remoteAddr, body string) int {
+	req := httptest.NewRequest(http.MethodPost, "/events", strings.NewReader(body))
+	req.RemoteAddr = remoteAddr
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestReceiver(t *testing.T) {
+	r := NewReceiver([]string{"10.0.0.1", "10.0.0.2", "idrac3.example.com"},
+		Filter{MessageIDs: []string{"PDR"}, Severities: []string{"Critical"}}, 20*time.Millisecond)
+	r.lookupHost = func(_ context.Context, host string) ([]string, error) {
+		if host == "idrac3.example.com" {
+			return []string{"192.0.2.9"}, nil
+		}
+		return nil, fmt.Errorf("no such host %s", host)
+	}
+
+	// Host from the subscription context, else from the remote address
+	assert.Equal(t, http.StatusNoContent, post(r, "192.0.2.9:4431",
+		`{"Context": "idrac-inventory:idrac3.example.com", "Events": [{"MessageId": "iDRAC.2.8.PDR1016", "Severity": "Warning"}]}`))
+	assert.Equal(t, http.StatusNoContent, post(r, "10.0.0.1:4431",
+		`{"Context": "", "Events": [{"MessageId": "iDRAC.2.8.PSU0003", "Severity": "Critical"}]}`))
+	assert.Equal(t, http.StatusNoContent, post(r, "10.0.0.2:4431",
+		`{"Events": [{"MessageId": "iDRAC.2.8.USR0030", "Severity": "OK"}]}`), "accepted, but no rescan")
+
+	assert.Equal(t, http.StatusForbidden, post(r, "10.0.0.9:4431", `{"Events": [{"MessageId": "iDRAC.2.8.PDR1016"}]}`))
+
+	// A Context naming another configured host than the sender is spoofed
+	assert.Equal(t, http.StatusForbidden, post(r, "10.0.0.9:4431",
+		`{"Context": "idrac-inventory:10.0.0.2", "Events": [{"MessageId": "iDRAC.2.8.PDR1016"}]}`))
+	assert.Equal(t, http.StatusForbidden, post(r, "10.0.0.1:4431",
+		`{"Context": "idrac-inventory:idrac3.example.com", "Events": [{"MessageId": "iDRAC.2.8.PDR1016"}]}`))
+	assert.Equal(t, http.StatusBadRequest, post(r, "10.0.0.1:4431", `not json`))
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/events", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+
+	select {
+	case <-r.Ready():
+	case <-time.After(time.Second):
+		t.Fatal("receiver did not become ready")
+	}
+	assert.Equal(t, []string{"10.0.0.1", "idrac3.example.com"}, r.Take())
+	assert.Empty(t, r.Take())
+
+	var none *Receiver
+	assert.Nil(t, none.Ready())
+}
+
+func TestReceiver_Requeue(t *testing.T) {
+	r := NewReceiver([]string{"10.0.0.1", "10.0.0.2"}, Filter{Severities: []string{"Critical"}}, time.Hour)
+	assert.Equal(t, http.StatusNoContent, post(r, "10.0.0.1:4431",
+		`{"Events": [{"MessageId": "iDRAC.2.8.PSU0003", "Severity": "Critical"}]}`))
+	hosts := r.Take()
+	require.Equal(t, []string{"10.0.0.1"}, hosts)
+
+	// Held in a blackout window: pending again, due when it ends
+	r.Requeue(hosts, 20*time.Millisecond)
+	select {
+	case <-r.Ready():
+	case <-time.After(time.Second):
+		t.Fatal("receiver did not become ready")
+	}
+	assert.Equal(t, []string{"10.0.0.1"}, r.Take())
+}
@@ -0,0 +1,95 @@
+// Package firmware checks scan results against the minimum iDRAC and BIOS
+// versions of the firmware policy (firmware_policy in the config) and
+// records the result in each server's firmware_compliance.
+package firmware
+
+import (
+	"fmt"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/config"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+// DefaultPolicy is the name of the policy of servers no model rule matches.
+const DefaultPolicy = "default"
+
+// Policy holds the minimum firmware versions.
+type Policy struct {
+	cfg config.FirmwarePolicyConfig
+}
+
+// New returns the policy of cfg, or nil if no minimum version is set.
+func New(cfg config.FirmwarePolicyConfig) *Policy {
+	if !cfg.IsEnabled() {
+		return nil
+	}
+	return &Policy{cfg: cfg}
+}
+
+// Minimums returns the name of the rule that applies to model and its
+// minimum iDRAC and BIOS versions ("" if not checked).
+func (p *Policy) Minimums(model string) (rule, minIDRAC, minBIOS string) {
+	rule, minIDRAC, minBIOS = DefaultPolicy, p.cfg.MinIDRACVersion, p.cfg.MinBIOSVersion
+	for _, m := range p.cfg.Models {
+		if !models.MatchModel(m.Model, model) {
+			continue
+		}
+		rule = m.Model
+		if m.MinIDRACVersion != "" {
+			minIDRAC = m.MinIDRACVersion
+		}
+		if m.MinBIOSVersion != "" {
+			minBIOS = m.MinBIOSVersion
+		}
+		break
+	}
+	return rule, minIDRAC, minBIOS
+}
+
+// Check returns the firmware compliance of a server. A version the scan
+// could not read counts as a violation, since compliance cannot be shown.
+func (p *Policy) Check(srv models.ServerInfo) *models.FirmwareCompliance {
+	rule, minIDRAC, minBIOS := p.Minimums(srv.Model)
+	c := &models.FirmwareCompliance{Policy: rule}
+	check := func(component, minimum, actual string) {
+		if minimum == "" {
+			return
+		}
+		if actual == "" {
+			actual = "unknown"
+		} else if models.CompareVersions(actual, minimum) >= 0 {
+			return
+		}
+		c.Violations = append(c.Violations, models.Violation{
+			Component: component,
+			Check:     "min_version",
+			Expected:  fmt.Sprintf("≥ %s", minimum),
+			Actual:    actual,
+		})
+	}
+	check(models.FirmwareIDRAC, minIDRAC, srv.IDRACFirmwareVersion)
+	check(models.FirmwareBIOS, minBIOS, srv.BiosVersion)
+	return c
+}
+
+// ApplyOne records the firmware compliance of a successfully scanned
+// result and returns it, or nil if the scan failed.
+func (p *Policy) ApplyOne(res *models.ServerInfo) *models.FirmwareCompliance {
+	if res.Error != nil {
+		return nil
+	}
+	res.FirmwareCompliance = p.Check(*res)
+	return res.FirmwareCompliance
+}
+
+// Apply checks every successfully scanned result and returns the number of
+// non-compliant ones.
+func (p *Policy) Apply(results []models.ServerInfo) int {
+	n := 0
+	for i := range results {
+		if c := p.ApplyOne(&results[i]); c != nil && !c.Compliant() {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,69 @@
+package firmware
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/config"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+func testPolicy() *Policy {
+	return New(config.FirmwarePolicyConfig{
+		MinIDRACVersion: "6.10.30.00",
+		MinBIOSVersion:  "2.19.1",
+		Models: []config.FirmwareModelPolicy{
+			{Model: "R750", MinBIOSVersion: "1.9.2"},
+			{Model: "PowerEdge R660", MinIDRACVersion: "7.00.00.00", MinBIOSVersion: "1.5.6"},
+		},
+	})
+}
+
+func TestNew_Disabled(t *testing.T) {
+	assert.Nil(t, New(config.FirmwarePolicyConfig{}))
+}
+
+func TestMinimums(t *testing.T) {
+	p := testPolicy()
+
+	rule, idrac, bios := p.Minimums("PowerEdge R740")
+	assert.Equal(t, []string{DefaultPolicy, "6.10.30.00", "2.19.1"}, []string{rule, idrac, bios})
+
+	rule, idrac, bios = p.Minimums("PowerEdge R750")
+	assert.Equal(t, []string{"R750", "6.10.30.00", "1.9.2"}, []string{rule, idrac, bios}, "unset versions fall back to the default")
+
+	rule, idrac, bios = p.Minimums("PowerEdge R660")
+	assert.Equal(t, []string{"PowerEdge R660", "7.00.00.00", "1.5.6"}, []string{rule, idrac, bios})
+}
+
+func TestApply(t *testing.T) {
+	results := []models.ServerInfo{
+		{Host: "a", Model: "PowerEdge R740", IDRACFirmwareVersion: "6.10.30.00", BiosVersion: "2.19.1"},
+		{Host: "b", Model: "PowerEdge R740", IDRACFirmwareVersion: "5.10.50.00", BiosVersion: "2.9.4"},
+		{Host: "c", Model: "PowerEdge R750", IDRACFirmwareVersion: "7.00.00.171", BiosVersion: "1.10.2"},
+		{Host: "d", Model: "PowerEdge R660", BiosVersion: "1.5.6"},
+		{Host: "e", Model: "PowerEdge R660", Error: errors.New("timeout")},
+	}
+
+	assert.Equal(t, 2, testPolicy().Apply(results))
+
+	require.NotNil(t, results[0].FirmwareCompliance)
+	assert.True(t, results[0].FirmwareCompliance.Compliant())
+
+	assert.Equal(t, []models.Violation{
+		{Component: models.FirmwareIDRAC, Check: "min_version", Expected: "≥ 6.10.30.00", Actual: "5.10.50.00"},
+		{Component: models.FirmwareBIOS, Check: "min_version", Expected: "≥ 2.19.1", Actual: "2.9.4"},
+	}, results[1].FirmwareCompliance.Violations)
+
+	assert.True(t, results[2].FirmwareCompliance.Compliant(), "1.10.2 is newer than 1.9.2")
+	assert.Equal(t, "R750", results[2].FirmwareCompliance.Policy)
+
+	assert.Equal(t, []models.Violation{
+		{Component: models.FirmwareIDRAC, Check: "min_version", Expected: "≥ 7.00.00.00", Actual: "unknown"},
+	}, results[3].FirmwareCompliance.Violations)
+
+	assert.Nil(t, results[4].FirmwareCompliance)
+}
@@ -5,21 +5,32 @@
 //  1. Write hardware-inventory.md  (human-readable, renders in GitLab)
 //  2. Write hardware-inventory.json (machine-readable, full detail)
 //  3. git add <files>
-//  4. git commit -m "inventory: update hardware report <timestamp>"
+//  4. git commit -m "inventory: update hardware report <timestamp>", with the
+//     servers added, removed and changed since the last committed report
 //  5. (optional) git push origin <branch>
+//
+// If the inventory is unchanged since the last commit (ignoring timestamps,
+// durations and power readings), nothing is written, committed or pushed,
+// unless heartbeat commits are enabled.
 package gitlab
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"reflect"
 	"strings"
 
-	"idrac-inventory/internal/models"
-	"idrac-inventory/internal/output"
-	"idrac-inventory/pkg/logging"
+	"github.com/braunma/idrac-netbox-importer/internal/output"
+	"github.com/braunma/idrac-netbox-importer/internal/signing"
+	"github.com/braunma/idrac-netbox-importer/pkg/errors"
+	"github.com/braunma/idrac-netbox-importer/pkg/logging"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+	"github.com/braunma/idrac-netbox-importer/pkg/redact"
+	"github.com/braunma/idrac-netbox-importer/pkg/units"
 )
 
 // Config holds configuration for the GitLab exporter.
@@ -43,6 +54,45 @@ type Config struct {
 
 	// Push controls whether to push to the remote after committing.
 	Push bool
+
+	// ReadOnly refuses git push, even if Push is set. Local commits are still made.
+	ReadOnly bool
+
+	// Signer signs the report files; their .sig files are committed alongside.
+	// Nil disables signing.
+	Signer *signing.Signer
+
+	// Units are the memory and storage units of the Markdown report
+	// (default: binary, 2 decimals).
+	Units units.Format
+
+	// Heartbeat commits the report with a timestamped heartbeat message even
+	// if the inventory is unchanged since the last commit.
+	Heartbeat bool
+
+	// Trends are the daily fleet totals of the history store, rendered as
+	// a trend section of the Markdown report (nil: no trend section).
+	Trends []models.TrendSample
+}
+
+// volatileKeys are the JSON report keys that change on every scan without a
+// change of the inventory; they are ignored when comparing reports.
+var volatileKeys = map[string]bool{
+	"generated_at":            true,
+	"collected_at":            true,
+	"run_id":                  true,
+	"days_left":               true,
+	"scan_duration":           true,
+	"phase_durations":         true,
+	"duration":                true,
+	"dominant_phase_duration": true,
+	"total_duration":          true,
+	"average_duration":        true,
+	"fastest_duration":        true,
+	"slowest_duration":        true,
+	"power_consumed_watts":    true,
+	"power_peak_watts":        true,
+	"input_watts":             true,
 }
 
 // Exporter writes inventory reports into a local git repository and optionally
@@ -59,6 +109,9 @@ func New(cfg Config) *Exporter {
 	if cfg.InventoryDir == "" {
 		cfg.InventoryDir = "inventory"
 	}
+	if cfg.Units == (units.Format{}) {
+		cfg.Units = units.Default()
+	}
 	if cfg.AuthorName == "" {
 		cfg.AuthorName = "iDRAC Inventory Bot"
 	}
@@ -68,54 +121,99 @@ func New(cfg Config) *Exporter {
 	return &Exporter{cfg: cfg}
 }
 
-// Export writes the inventory files, commits them, and optionally pushes.
-func (e *Exporter) Export(inv models.AggregatedInventory) error {
+// Export writes the inventory files, commits them, and optionally pushes. It
+// returns false if the inventory is unchanged since the last commit and
+// nothing was committed.
+func (e *Exporter) Export(inv models.AggregatedInventory) (bool, error) {
 	if e.cfg.RepoPath == "" {
-		return fmt.Errorf("gitlab.repo_path is not configured")
+		return false, fmt.Errorf("gitlab.repo_path is not configured")
 	}
 
 	// Verify the target is an actual git repository.
 	if _, err := os.Stat(filepath.Join(e.cfg.RepoPath, ".git")); os.IsNotExist(err) {
-		return fmt.Errorf("not a git repository: %s (missing .git directory)", e.cfg.RepoPath)
+		return false, fmt.Errorf("not a git repository: %s (missing .git directory)", e.cfg.RepoPath)
+	}
+
+	mdFile := "hardware-inventory.md"
+	jsonFile := "hardware-inventory.json"
+	relMD := filepath.Join(e.cfg.InventoryDir, mdFile)
+	relJSON := filepath.Join(e.cfg.InventoryDir, jsonFile)
+
+	mdData, err := e.renderMarkdown(inv)
+	if err != nil {
+		return false, fmt.Errorf("failed to render Markdown report: %w", err)
+	}
+	jsonData, err := json.MarshalIndent(inv, "", "  ")
+	if err != nil {
+		return false, fmt.Errorf("failed to render JSON report: %w", err)
+	}
+
+	// Skip the commit if the inventory is unchanged since the last one.
+	committed := e.committedReport(relJSON)
+	unchanged := committed != nil && sameContent(committed, jsonData)
+	if unchanged && !e.cfg.Heartbeat {
+		logging.Info("Inventory unchanged since the last commit, skipping export",
+			"repo", e.cfg.RepoPath,
+			"file", relJSON,
+		)
+		return false, nil
 	}
 
 	// Ensure the inventory sub-directory exists.
 	inventoryDir := filepath.Join(e.cfg.RepoPath, e.cfg.InventoryDir)
 	if err := os.MkdirAll(inventoryDir, 0o755); err != nil {
-		return fmt.Errorf("failed to create inventory directory %s: %w", inventoryDir, err)
+		return false, fmt.Errorf("failed to create inventory directory %s: %w", inventoryDir, err)
 	}
 
 	// Write Markdown report.
-	mdFile := "hardware-inventory.md"
 	mdPath := filepath.Join(inventoryDir, mdFile)
-	if err := e.writeMarkdown(mdPath, inv); err != nil {
-		return fmt.Errorf("failed to write Markdown report: %w", err)
+	if err := os.WriteFile(mdPath, mdData, 0o644); err != nil {
+		return false, fmt.Errorf("failed to write Markdown report: %w", err)
 	}
 	logging.Info("Wrote Markdown report", "path", mdPath)
 
 	// Write JSON report.
-	jsonFile := "hardware-inventory.json"
 	jsonPath := filepath.Join(inventoryDir, jsonFile)
-	if err := e.writeJSON(jsonPath, inv); err != nil {
-		return fmt.Errorf("failed to write JSON report: %w", err)
+	if err := os.WriteFile(jsonPath, jsonData, 0o644); err != nil {
+		return false, fmt.Errorf("failed to write JSON report: %w", err)
 	}
 	logging.Info("Wrote JSON report", "path", jsonPath)
 
-	// Stage both files.
-	relMD := filepath.Join(e.cfg.InventoryDir, mdFile)
-	relJSON := filepath.Join(e.cfg.InventoryDir, jsonFile)
-	if err := e.gitRun("add", relMD, relJSON); err != nil {
-		return fmt.Errorf("git add failed: %w", err)
+	// Stage both files, plus their signatures if signing is enabled.
+	files := []string{relMD, relJSON}
+	if e.cfg.Signer != nil {
+		for _, path := range []string{mdPath, jsonPath} {
+			if _, err := e.cfg.Signer.SignFile(path); err != nil {
+				return false, fmt.Errorf("failed to sign %s: %w", path, err)
+			}
+		}
+		files = append(files, relMD+signing.Suffix, relJSON+signing.Suffix)
+		logging.Info("Signed inventory reports", "key_id", signing.KeyID(e.cfg.Signer.PublicKey()))
+	}
+	if err := e.gitRun(append([]string{"add"}, files...)...); err != nil {
+		return false, fmt.Errorf("git add failed: %w", err)
 	}
 
 	// Commit.
+	subject := "inventory: update hardware report"
+	if unchanged {
+		subject = "inventory: heartbeat, no hardware changes"
+	}
 	msg := fmt.Sprintf(
-		"inventory: update hardware report %s\n\nScanned: %d | Success: %d | Failed: %d | Models: %d | Config groups: %d",
-		inv.GeneratedAt.Format("2006-01-02 15:04:05 UTC"),
+		"%s %s\n\nScanned: %d | Success: %d | Failed: %d | Models: %d | Config groups: %d",
+		subject, inv.GeneratedAt.Format("2006-01-02 15:04:05 UTC"),
 		inv.TotalServers, inv.SuccessfulCount, inv.FailedCount, len(inv.ModelGroups), inv.TotalConfigGroups(),
 	)
+	if !unchanged {
+		if summary := changeSummary(committed, inv, e.cfg.Units); summary != "" {
+			msg += "\n\n" + summary
+		}
+	}
+	if inv.Stats.RunID != "" {
+		msg += "\n\nRun-ID: " + inv.Stats.RunID
+	}
 	if err := e.gitCommit(msg); err != nil {
-		return fmt.Errorf("git commit failed: %w", err)
+		return false, fmt.Errorf("git commit failed: %w", err)
 	}
 	logging.Info("Committed inventory",
 		"repo", e.cfg.RepoPath,
@@ -128,31 +226,64 @@ func (e *Exporter) Export(inv models.AggregatedInventory) error {
 	// Optionally push.
 	if e.cfg.Push {
 		if err := e.gitRun("push", "origin", e.cfg.Branch); err != nil {
-			return fmt.Errorf("git push failed: %w", err)
+			return true, fmt.Errorf("git push failed: %w", err)
 		}
 		logging.Info("Pushed inventory to remote", "branch", e.cfg.Branch)
 	}
 
-	return nil
+	return true, nil
 }
 
-// writeMarkdown renders the aggregated inventory as Markdown and writes it to path.
-func (e *Exporter) writeMarkdown(path string, inv models.AggregatedInventory) error {
-	f, err := os.Create(path)
-	if err != nil {
-		return err
+// renderMarkdown renders the aggregated inventory as Markdown.
+func (e *Exporter) renderMarkdown(inv models.AggregatedInventory) ([]byte, error) {
+	var buf bytes.Buffer
+	formatter := output.NewMarkdownFormatter()
+	formatter.Units = e.cfg.Units
+	formatter.Trends = e.cfg.Trends
+	if err := formatter.FormatAggregated(&buf, inv); err != nil {
+		return nil, err
 	}
-	defer f.Close()
-	return output.NewMarkdownFormatter().FormatAggregated(f, inv)
+	return buf.Bytes(), nil
 }
 
-// writeJSON serialises the aggregated inventory as indented JSON and writes it to path.
-func (e *Exporter) writeJSON(path string, inv models.AggregatedInventory) error {
-	data, err := json.MarshalIndent(inv, "", "  ")
+// committedReport returns the JSON report committed at HEAD, or nil if there
+// is none (e.g. before the first export).
+func (e *Exporter) committedReport(relPath string) []byte {
+	committed, err := e.gitOutput("show", "HEAD:"+filepath.ToSlash(relPath))
 	if err != nil {
-		return err
+		logging.Debug("no committed report to compare with", "file", relPath, "error", err)
+		return nil
+	}
+	return committed
+}
+
+// sameContent reports whether two JSON reports are equal apart from their
+// volatile keys.
+func sameContent(a, b []byte) bool {
+	var x, y interface{}
+	if json.Unmarshal(a, &x) != nil || json.Unmarshal(b, &y) != nil {
+		return false
 	}
-	return os.WriteFile(path, data, 0o644)
+	return reflect.DeepEqual(stripVolatile(x), stripVolatile(y))
+}
+
+// stripVolatile removes the volatile keys from a decoded JSON value.
+func stripVolatile(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, val := range t {
+			if volatileKeys[k] {
+				delete(t, k)
+			} else {
+				t[k] = stripVolatile(val)
+			}
+		}
+	case []interface{}:
+		for i := range t {
+			t[i] = stripVolatile(t[i])
+		}
+	}
+	return v
 }
 
 // gitCommit runs git commit, setting the configured author identity via -c flags.
@@ -167,19 +298,37 @@ func (e *Exporter) gitCommit(message string) error {
 	cmd := exec.Command("git", args...)
 	out, err := cmd.CombinedOutput()
 	if err != nil {
-		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+		return fmt.Errorf("%w: %s", err, redact.String(strings.TrimSpace(string(out))))
 	}
 	logging.Debug("git commit", "output", strings.TrimSpace(string(out)))
 	return nil
 }
 
+// gitOutput executes a read-only git sub-command inside RepoPath and returns
+// its standard output.
+func (e *Exporter) gitOutput(subArgs ...string) ([]byte, error) {
+	args := append([]string{"-C", e.cfg.RepoPath}, subArgs...)
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", err, redact.String(strings.TrimSpace(stderr.String())))
+	}
+	return out, nil
+}
+
 // gitRun executes a git sub-command inside RepoPath.
+// In read-only mode, push is refused.
 func (e *Exporter) gitRun(subArgs ...string) error {
+	if e.cfg.ReadOnly && len(subArgs) > 0 && subArgs[0] == "push" {
+		return fmt.Errorf("git push: %w", errors.ErrReadOnly)
+	}
 	args := append([]string{"-C", e.cfg.RepoPath}, subArgs...)
 	cmd := exec.Command("git", args...)
 	out, err := cmd.CombinedOutput()
 	if err != nil {
-		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+		return fmt.Errorf("%w: %s", err, redact.String(strings.TrimSpace(string(out))))
 	}
 	logging.Debug("git", "args", subArgs, "output", strings.TrimSpace(string(out)))
 	return nil
@@ -0,0 +1,131 @@
+package gitlab
+
+import (
+	"os/exec"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+	"github.com/braunma/idrac-netbox-importer/pkg/units"
+)
+
+func initRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	out, err := exec.Command("git", "init", "-q", dir).CombinedOutput()
+	require.NoError(t, err, string(out))
+	return dir
+}
+
+func commitCount(t *testing.T, repo string) int {
+	t.Helper()
+	out, err := exec.Command("git", "-C", repo, "rev-list", "--count", "HEAD").Output()
+	require.NoError(t, err)
+	n, err := strconv.Atoi(strings.TrimSpace(string(out)))
+	require.NoError(t, err)
+	return n
+}
+
+func inventory(at time.Time, ramGiB float64) models.AggregatedInventory {
+	srv := models.ServerInfo{
+		Host:               "10.0.0.1",
+		CollectedAt:        at,
+		RunID:              at.Format("150405"),
+		Model:              "PowerEdge R750",
+		ServiceTag:         "ABC1234",
+		TotalMemoryGiB:     ramGiB,
+		PowerConsumedWatts: at.Second() + 300,
+		ScanDuration:       time.Duration(at.Second()) * time.Millisecond,
+	}
+	inv := models.GroupByConfiguration([]models.ServerInfo{srv}, models.CollectionStats{
+		RunID:         srv.RunID,
+		TotalServers:  1,
+		TotalDuration: srv.ScanDuration,
+	})
+	inv.GeneratedAt = at
+	return inv
+}
+
+func TestExport_SkipsUnchanged(t *testing.T) {
+	repo := initRepo(t)
+	exp := New(Config{RepoPath: repo})
+	at := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	committed, err := exp.Export(inventory(at, 512))
+	require.NoError(t, err)
+	assert.True(t, committed)
+
+	committed, err = exp.Export(inventory(at.Add(time.Hour+7*time.Second), 512))
+	require.NoError(t, err)
+	assert.False(t, committed, "only timestamps, durations and power changed")
+	assert.Equal(t, 1, commitCount(t, repo))
+	status, err := exec.Command("git", "-C", repo, "status", "--porcelain").Output()
+	require.NoError(t, err)
+	assert.Empty(t, string(status), "nothing written")
+
+	committed, err = exp.Export(inventory(at.Add(2*time.Hour), 384))
+	require.NoError(t, err)
+	assert.True(t, committed)
+	assert.Equal(t, 2, commitCount(t, repo))
+}
+
+func TestExport_Heartbeat(t *testing.T) {
+	repo := initRepo(t)
+	exp := New(Config{RepoPath: repo, Heartbeat: true})
+	at := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	_, err := exp.Export(inventory(at, 512))
+	require.NoError(t, err)
+	committed, err := exp.Export(inventory(at.Add(time.Hour), 512))
+	require.NoError(t, err)
+	assert.True(t, committed)
+
+	subject, err := exec.Command("git", "-C", repo, "log", "-1", "--format=%s").Output()
+	require.NoError(t, err)
+	assert.Equal(t, "inventory: heartbeat, no hardware changes 2025-03-01 13:00:00 UTC", strings.TrimSpace(string(subject)))
+}
+
+func TestExport_ChangeSummary(t *testing.T) {
+	repo := initRepo(t)
+	exp := New(Config{RepoPath: repo})
+	at := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	_, err := exp.Export(inventory(at, 512))
+	require.NoError(t, err)
+	body, err := exec.Command("git", "-C", repo, "log", "-1", "--format=%b").Output()
+	require.NoError(t, err)
+	assert.NotContains(t, string(body), "Servers:", "nothing to compare the first report with")
+
+	inv := inventory(at.Add(time.Hour), 384)
+	added := models.ServerInfo{Host: "10.0.0.2", Model: "PowerEdge R650", ServiceTag: "XYZ9876", CollectedAt: at}
+	inv = models.GroupByConfiguration(append(inv.Servers(), added), inv.Stats)
+	inv.GeneratedAt = at.Add(time.Hour)
+	_, err = exp.Export(inv)
+	require.NoError(t, err)
+
+	body, err = exec.Command("git", "-C", repo, "log", "-1", "--format=%b").Output()
+	require.NoError(t, err)
+	assert.Contains(t, string(body), "Servers: +1 added, -0 removed, 1 changed, 0 unchanged\n"+
+		"Memory: 512 GiB -> 384 GiB\n\n"+
+		"+ 10.0.0.2 (XYZ9876, PowerEdge R650)\n"+
+		"~ 10.0.0.1 (ABC1234, PowerEdge R750): memory 512 GiB in 0 DIMMs -> 384 GiB in 0 DIMMs\n")
+	assert.Contains(t, string(body), "Run-ID:")
+}
+
+func TestFormatComparison_Truncates(t *testing.T) {
+	var c models.FleetComparison
+	for i := 0; i < maxSummaryLines+5; i++ {
+		c.Removed = append(c.Removed, models.ServerRef{Host: "10.0.1." + strconv.Itoa(i)})
+	}
+	summary := formatComparison(c, units.Default())
+	assert.Equal(t, maxSummaryLines+3, strings.Count(summary, "\n")+1)
+	assert.True(t, strings.HasSuffix(summary, "... and 5 more"))
+}
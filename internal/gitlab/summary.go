@@ -0,0 +1,82 @@
+package gitlab
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+	"github.com/braunma/idrac-netbox-importer/pkg/units"
+)
+
+// maxSummaryLines limits the server and hardware change lines of a commit
+// message; the committed reports hold the complete difference.
+const maxSummaryLines = 50
+
+// changeSummary returns the difference between the committed JSON report and
+// inv as the body of the commit message, or "" if the committed report is
+// missing or unreadable.
+func changeSummary(committed []byte, inv models.AggregatedInventory, u units.Format) string {
+	if committed == nil {
+		return ""
+	}
+	var old models.AggregatedInventory
+	if err := json.Unmarshal(committed, &old); err != nil {
+		return ""
+	}
+	return formatComparison(models.CompareFleets(old.Servers(), inv.Servers()), u)
+}
+
+// formatComparison renders a fleet comparison for a commit message: a count
+// line, the fleet totals that changed and one line per added or removed
+// server and per hardware change.
+func formatComparison(c models.FleetComparison, u units.Format) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Servers: +%d added, -%d removed, %d changed, %d unchanged",
+		len(c.Added), len(c.Removed), len(c.Changed), c.Unchanged)
+	if len(c.Unscanned) > 0 {
+		fmt.Fprintf(&b, ", %d not compared (failed scan)", len(c.Unscanned))
+	}
+	b.WriteString("\n")
+
+	count := func(v float64) string { return fmt.Sprintf("%d", int(v)) }
+	for _, t := range []struct {
+		metric   string
+		old, new float64
+		format   func(float64) string
+	}{
+		{"CPU cores", float64(c.Old.CPUCores), float64(c.New.CPUCores), count},
+		{"Memory", c.Old.MemoryGiB, c.New.MemoryGiB, u.Gigabytes},
+		{"Drives", float64(c.Old.Drives), float64(c.New.Drives), count},
+		{"Storage", c.Old.StorageTB, c.New.StorageTB, u.Terabytes},
+		{"GPUs", float64(c.Old.GPUs), float64(c.New.GPUs), count},
+	} {
+		if o, n := t.format(t.old), t.format(t.new); o != n {
+			fmt.Fprintf(&b, "%s: %s -> %s\n", t.metric, o, n)
+		}
+	}
+
+	var lines []string
+	for _, s := range c.Added {
+		lines = append(lines, "+ "+s.String())
+	}
+	for _, s := range c.Removed {
+		lines = append(lines, "- "+s.String())
+	}
+	for _, d := range c.Changed {
+		for _, ch := range d.Changes {
+			lines = append(lines, fmt.Sprintf("~ %s: %s", d.ServerRef, ch))
+		}
+	}
+	if len(lines) > 0 {
+		b.WriteString("\n")
+	}
+	for i, line := range lines {
+		if i == maxSummaryLines {
+			fmt.Fprintf(&b, "... and %d more\n", len(lines)-i)
+			break
+		}
+		b.WriteString(line + "\n")
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
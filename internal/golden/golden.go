@@ -0,0 +1,310 @@
+// Package golden checks scan results against the expected build ("golden
+// config") of their model or NetBox device role and records the deviations
+// in the results.
+//
+// The golden config file is YAML. A server is checked against the first
+// profile whose model and/or role matches; unset checks are skipped:
+//
+//	profiles:
+//	  - name: hypervisor
+//	    role: hypervisor          # NetBox device role slug
+//	    cpu:
+//	      count: 2
+//	      model: Xeon Gold 6338   # substring, ignoring case and (R)/(TM)
+//	      min_cores: 64           # total of all sockets
+//	    memory:
+//	      min_gib: 1024
+//	      dimms: 16
+//	      type: DDR4
+//	    drives:
+//	      count: 2
+//	      media_type: SSD
+//	      min_capacity_gb: 960    # marketing size of every drive
+//	    gpus:
+//	      count: 0
+//	  - name: storage-r740xd2
+//	    model: PowerEdge R740xd2
+//	    drives:
+//	      min_count: 24
+//	      media_type: HDD
+package golden
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+// Components of a violation.
+const (
+	ComponentCPU    = "cpu"
+	ComponentMemory = "memory"
+	ComponentDrives = "drives"
+	ComponentGPUs   = "gpus"
+)
+
+// Profile is the expected build of the servers of one model or role.
+type Profile struct {
+	Name  string `yaml:"name"`
+	Model string `yaml:"model"` // e.g. "PowerEdge R750" or "R750"
+	Role  string `yaml:"role"`  // NetBox device role slug
+
+	CPU    *CPUSpec    `yaml:"cpu"`
+	Memory *MemorySpec `yaml:"memory"`
+	Drives *DriveSpec  `yaml:"drives"`
+	GPUs   *GPUSpec    `yaml:"gpus"`
+}
+
+// CPUSpec is the expected CPU configuration.
+type CPUSpec struct {
+	Count    *int   `yaml:"count"`
+	Model    string `yaml:"model"`
+	MinCores int    `yaml:"min_cores"`
+}
+
+// MemorySpec is the expected memory configuration.
+type MemorySpec struct {
+	MinGiB float64 `yaml:"min_gib"`
+	DIMMs  *int    `yaml:"dimms"`
+	Type   string  `yaml:"type"`
+}
+
+// DriveSpec is the expected drive configuration.
+type DriveSpec struct {
+	Count         *int   `yaml:"count"`
+	MinCount      int    `yaml:"min_count"`
+	MediaType     string `yaml:"media_type"`
+	MinCapacityGB int    `yaml:"min_capacity_gb"`
+}
+
+// GPUSpec is the expected GPU configuration.
+type GPUSpec struct {
+	Count *int   `yaml:"count"`
+	Model string `yaml:"model"`
+}
+
+// Spec holds the golden config profiles.
+type Spec struct {
+	Profiles []Profile `yaml:"profiles"`
+}
+
+// RoleFunc returns the NetBox device role slug of a server ("" if it has
+// none or is not in NetBox).
+type RoleFunc func(ctx context.Context, srv models.ServerInfo) (string, error)
+
+// Load reads a golden config file.
+func Load(path string) (*Spec, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read golden config: %w", err)
+	}
+	defer file.Close()
+
+	var spec Spec
+	if err := yaml.NewDecoder(file).Decode(&spec); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("%s: invalid golden config YAML: %w", path, err)
+	}
+	if err := spec.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &spec, nil
+}
+
+func (s *Spec) validate() error {
+	seen := make(map[string]bool)
+	for i := range s.Profiles {
+		p := &s.Profiles[i]
+		if p.Model == "" && p.Role == "" {
+			return fmt.Errorf("profile %d: model or role is required", i+1)
+		}
+		if p.Name == "" {
+			p.Name = p.Role
+			if p.Name == "" {
+				p.Name = p.Model
+			}
+		}
+		if seen[p.Name] {
+			return fmt.Errorf("profile %d: duplicate name %q", i+1, p.Name)
+		}
+		seen[p.Name] = true
+		if p.CPU == nil && p.Memory == nil && p.Drives == nil && p.GPUs == nil {
+			return fmt.Errorf("profile %s: no checks", p.Name)
+		}
+	}
+	return nil
+}
+
+// UsesRoles reports whether any profile matches by NetBox device role.
+func (s *Spec) UsesRoles() bool {
+	for _, p := range s.Profiles {
+		if p.Role != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Match returns the first profile matching the model and role of a server,
+// or nil.
+func (s *Spec) Match(srv models.ServerInfo, role string) *Profile {
+	for i := range s.Profiles {
+		p := &s.Profiles[i]
+		if p.Role != "" && !strings.EqualFold(p.Role, role) {
+			continue
+		}
+		if p.Model != "" && !models.MatchModel(p.Model, srv.Model) {
+			continue
+		}
+		return p
+	}
+	return nil
+}
+
+// Apply checks every successfully scanned result and returns the number of
+// non-compliant ones. role may be nil if no profile matches by role.
+func (s *Spec) Apply(ctx context.Context, results []models.ServerInfo, role RoleFunc) int {
+	n := 0
+	for i := range results {
+		if c := s.ApplyOne(ctx, &results[i], role); c != nil && !c.Compliant() {
+			n++
+		}
+	}
+	return n
+}
+
+// ApplyOne checks res against the first profile matching its model and
+// NetBox role and records the compliance in it. It returns the compliance,
+// or nil if the scan failed or no profile matches. A failed role lookup is
+// treated as no role.
+func (s *Spec) ApplyOne(ctx context.Context, res *models.ServerInfo, role RoleFunc) *models.Compliance {
+	if res.Error != nil {
+		return nil
+	}
+	var r string
+	if role != nil && s.UsesRoles() {
+		r, _ = role(ctx, *res)
+	}
+	p := s.Match(*res, r)
+	if p == nil {
+		return nil
+	}
+	res.Compliance = &models.Compliance{
+		Profile:    p.Name,
+		Violations: p.Check(*res),
+	}
+	return res.Compliance
+}
+
+// Check returns the deviations of a server from the profile.
+func (p *Profile) Check(srv models.ServerInfo) []models.Violation {
+	var v violations
+	if c := p.CPU; c != nil {
+		if c.Count != nil && srv.CPUCount != *c.Count {
+			v.add(ComponentCPU, "count", *c.Count, srv.CPUCount)
+		}
+		if c.Model != "" {
+			for _, cpu := range srv.CPUs {
+				if !containsFold(cpu.Model, c.Model) {
+					v.add(ComponentCPU, "model", c.Model, cpu.Model)
+					break
+				}
+			}
+		}
+		if c.MinCores > 0 {
+			cores := 0
+			for _, cpu := range srv.CPUs {
+				cores += cpu.Cores
+			}
+			if cores < c.MinCores {
+				v.add(ComponentCPU, "min_cores", fmt.Sprintf("≥ %d", c.MinCores), cores)
+			}
+		}
+	}
+
+	if m := p.Memory; m != nil {
+		if m.MinGiB > 0 && srv.TotalMemoryGiB < m.MinGiB {
+			v.add(ComponentMemory, "min_gib", fmt.Sprintf("≥ %g", m.MinGiB), fmt.Sprintf("%g", srv.TotalMemoryGiB))
+		}
+		if m.DIMMs != nil && srv.MemorySlotsUsed != *m.DIMMs {
+			v.add(ComponentMemory, "dimms", *m.DIMMs, srv.MemorySlotsUsed)
+		}
+		if m.Type != "" {
+			for _, mem := range srv.Memory {
+				if mem.IsPopulated() && !strings.EqualFold(mem.Type, m.Type) {
+					v.add(ComponentMemory, "type", m.Type, mem.Type)
+					break
+				}
+			}
+		}
+	}
+
+	if d := p.Drives; d != nil {
+		if d.Count != nil && srv.DriveCount != *d.Count {
+			v.add(ComponentDrives, "count", *d.Count, srv.DriveCount)
+		}
+		if d.MinCount > 0 && srv.DriveCount < d.MinCount {
+			v.add(ComponentDrives, "min_count", fmt.Sprintf("≥ %d", d.MinCount), srv.DriveCount)
+		}
+		if d.MediaType != "" {
+			for _, drive := range srv.Drives {
+				if !strings.EqualFold(drive.MediaType, d.MediaType) {
+					v.add(ComponentDrives, "media_type", d.MediaType, fmt.Sprintf("%s (%s)", drive.MediaType, drive.Name))
+					break
+				}
+			}
+		}
+		if d.MinCapacityGB > 0 {
+			for _, drive := range srv.Drives {
+				if drive.MarketingGB() < d.MinCapacityGB {
+					v.add(ComponentDrives, "min_capacity_gb", fmt.Sprintf("≥ %d", d.MinCapacityGB), fmt.Sprintf("%d (%s)", drive.MarketingGB(), drive.Name))
+					break
+				}
+			}
+		}
+	}
+
+	if g := p.GPUs; g != nil {
+		if g.Count != nil && srv.GPUCount != *g.Count {
+			v.add(ComponentGPUs, "count", *g.Count, srv.GPUCount)
+		}
+		if g.Model != "" {
+			for _, gpu := range srv.GPUs {
+				if !containsFold(gpu.Model, g.Model) {
+					v.add(ComponentGPUs, "model", g.Model, gpu.Model)
+					break
+				}
+			}
+		}
+	}
+	return v
+}
+
+type violations []models.Violation
+
+func (v *violations) add(component, check string, expected, actual interface{}) {
+	*v = append(*v, models.Violation{
+		Component: component,
+		Check:     check,
+		Expected:  fmt.Sprint(expected),
+		Actual:    fmt.Sprint(actual),
+	})
+}
+
+// containsFold reports whether substr is in s, ignoring case, trademark
+// marks and repeated spaces, so "Xeon Gold 6338" is found in
+// "Intel(R) Xeon(R) Gold 6338 CPU @ 2.00GHz".
+func containsFold(s, substr string) bool {
+	return strings.Contains(normalize(s), normalize(substr))
+}
+
+var trademarks = strings.NewReplacer("(r)", " ", "(tm)", " ", "®", " ", "™", " ")
+
+func normalize(s string) string {
+	return strings.Join(strings.Fields(trademarks.Replace(strings.ToLower(s))), " ")
+}
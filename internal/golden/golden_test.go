@@ -0,0 +1,153 @@
+package golden
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+const goldenYAML = `profiles:
+  - name: hypervisor
+    role: hypervisor
+    cpu:
+      count: 2
+      model: Xeon Gold 6338
+      min_cores: 64
+    memory:
+      min_gib: 1024
+      dimms: 16
+      type: DDR4
+    gpus:
+      count: 0
+  - model: R750
+    drives:
+      count: 2
+      media_type: SSD
+      min_capacity_gb: 960
+`
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func server() models.ServerInfo {
+	return models.ServerInfo{
+		Host:     "10.0.0.1",
+		Model:    "PowerEdge R750",
+		CPUCount: 2,
+		CPUs: []models.CPUInfo{
+			{Model: "Intel(R) Xeon(R) Gold 6338 CPU @ 2.00GHz", Cores: 32},
+			{Model: "Intel(R) Xeon(R) Gold 6338 CPU @ 2.00GHz", Cores: 32},
+		},
+		TotalMemoryGiB:  1024,
+		MemorySlotsUsed: 16,
+		Memory:          []models.MemoryInfo{{Type: "DDR4", CapacityMiB: 65536, State: models.MemoryStateEnabled}},
+		DriveCount:      2,
+		Drives: []models.DriveInfo{
+			{Name: "Disk 0", MediaType: "SSD", CapacityGB: 894.25},
+			{Name: "Disk 1", MediaType: "SSD", CapacityGB: 894.25},
+		},
+	}
+}
+
+func TestLoad(t *testing.T) {
+	spec, err := Load(writeFile(t, "golden.yaml", goldenYAML))
+	require.NoError(t, err)
+	require.Len(t, spec.Profiles, 2)
+	assert.Equal(t, "R750", spec.Profiles[1].Name, "name defaults to the model")
+	assert.True(t, spec.UsesRoles())
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	for name, content := range map[string]string{
+		"no match":  "profiles:\n  - name: x\n    cpu: {count: 2}\n",
+		"no checks": "profiles:\n  - model: R750\n",
+		"duplicate": "profiles:\n  - model: R750\n    cpu: {count: 2}\n  - model: R750\n    gpus: {count: 0}\n",
+		"bad yaml":  "profiles: [",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := Load(writeFile(t, "golden.yaml", content))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	spec, err := Load(writeFile(t, "golden.yaml", goldenYAML))
+	require.NoError(t, err)
+
+	srv := server()
+	assert.Equal(t, "hypervisor", spec.Match(srv, "Hypervisor").Name, "role profiles come first")
+	assert.Equal(t, "R750", spec.Match(srv, "").Name)
+	srv.Model = "PowerEdge R650"
+	assert.Nil(t, spec.Match(srv, "storage"))
+}
+
+func TestCheck(t *testing.T) {
+	spec, err := Load(writeFile(t, "golden.yaml", goldenYAML))
+	require.NoError(t, err)
+
+	srv := server()
+	assert.Empty(t, spec.Profiles[0].Check(srv))
+	assert.Empty(t, spec.Profiles[1].Check(srv))
+
+	srv.CPUCount = 1
+	srv.CPUs = srv.CPUs[:1]
+	srv.TotalMemoryGiB = 768
+	srv.MemorySlotsUsed = 12
+	srv.GPUCount = 1
+	assert.Equal(t, []models.Violation{
+		{Component: ComponentCPU, Check: "count", Expected: "2", Actual: "1"},
+		{Component: ComponentCPU, Check: "min_cores", Expected: "≥ 64", Actual: "32"},
+		{Component: ComponentMemory, Check: "min_gib", Expected: "≥ 1024", Actual: "768"},
+		{Component: ComponentMemory, Check: "dimms", Expected: "16", Actual: "12"},
+		{Component: ComponentGPUs, Check: "count", Expected: "0", Actual: "1"},
+	}, spec.Profiles[0].Check(srv))
+
+	srv = server()
+	srv.Drives[1] = models.DriveInfo{Name: "Disk 1", MediaType: "HDD", CapacityGB: 447}
+	assert.Equal(t, []models.Violation{
+		{Component: ComponentDrives, Check: "media_type", Expected: "SSD", Actual: "HDD (Disk 1)"},
+		{Component: ComponentDrives, Check: "min_capacity_gb", Expected: "≥ 960", Actual: "480 (Disk 1)"},
+	}, spec.Profiles[1].Check(srv))
+}
+
+func TestApply(t *testing.T) {
+	spec, err := Load(writeFile(t, "golden.yaml", goldenYAML))
+	require.NoError(t, err)
+
+	compliant := server()
+	short := server()
+	short.Host = "10.0.0.2"
+	short.DriveCount = 1
+	short.Drives = short.Drives[:1]
+	results := []models.ServerInfo{
+		compliant,
+		short,
+		{Host: "10.0.0.3", Error: errors.New("timeout")},
+		{Host: "10.0.0.4", Model: "PowerEdge R650"},
+	}
+
+	roles := func(ctx context.Context, srv models.ServerInfo) (string, error) {
+		return "", errors.New("not in NetBox")
+	}
+	assert.Equal(t, 1, spec.Apply(context.Background(), results, roles))
+
+	require.NotNil(t, results[0].Compliance)
+	assert.True(t, results[0].Compliance.Compliant())
+	require.NotNil(t, results[1].Compliance)
+	assert.Equal(t, "R750", results[1].Compliance.Profile)
+	assert.Equal(t, "drives count: expected 2, found 1", results[1].Compliance.Violations[0].String())
+	assert.Nil(t, results[2].Compliance, "failed servers are not checked")
+	assert.Nil(t, results[3].Compliance, "no profile matches")
+}
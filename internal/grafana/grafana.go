@@ -0,0 +1,383 @@
+// Package grafana serves the last scan results and the fleet trends of the
+// history store to Grafana, so dashboards need no intermediate database. It
+// speaks the protocols of two datasource plugins:
+//
+// JSON datasource (simpod-json-datasource), with the URL of the handler:
+//
+//	GET  /         connection test
+//	POST /metrics  available metrics (POST /search for older plugin versions)
+//	POST /query    time series of the fleet trends, or the servers table
+//
+// Infinity datasource, as flat JSON arrays:
+//
+//	GET  /servers  one row per server of the last scan
+//	GET  /trends   one row per daily trend sample
+//	GET  /stats    the statistics of the last scan (a single row)
+package grafana
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/errors"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+// Time series metrics, from the daily trend samples.
+const (
+	MetricServers      = "servers"       // hosts with a known inventory
+	MetricFailed       = "failed"        // hosts whose scan failed
+	MetricMemoryGiB    = "memory_gib"    // total memory
+	MetricPowerWatts   = "power_watts"   // summed current power draw
+	MetricFailedDrives = "failed_drives" // drives in Critical health
+)
+
+// MetricInventory is the table of the servers of the last scan.
+const MetricInventory = "inventory"
+
+// Source provides the data served to Grafana. Both functions are called for
+// every request, so the responses follow the state directory without a
+// restart.
+type Source struct {
+	// Results returns the results of the last scan.
+	Results func() ([]models.ServerInfo, models.CollectionStats, error)
+
+	// Trends returns the daily trend samples, oldest first.
+	Trends func() ([]models.TrendSample, error)
+}
+
+// metrics lists the metrics of /metrics and /search.
+var metrics = []string{MetricServers, MetricFailed, MetricMemoryGiB, MetricPowerWatts, MetricFailedDrives, MetricInventory}
+
+// Handler returns the Grafana endpoints of src.
+func Handler(src Source) http.Handler {
+	h := &handler{src: src}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", h.test)
+	mux.HandleFunc("/metrics", h.metrics)
+	mux.HandleFunc("/search", h.search)
+	mux.HandleFunc("/query", h.query)
+	mux.HandleFunc("/servers", h.servers)
+	mux.HandleFunc("/trends", h.trends)
+	mux.HandleFunc("/stats", h.stats)
+	return mux
+}
+
+type handler struct {
+	src Source
+}
+
+// test answers the connection test of the JSON datasource.
+func (h *handler) test(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// metric is an entry of the /metrics response.
+type metric struct {
+	Label string `json:"label"`
+	Value string `json:"value"`
+}
+
+func (h *handler) metrics(w http.ResponseWriter, r *http.Request) {
+	list := make([]metric, len(metrics))
+	for i, m := range metrics {
+		list[i] = metric{Label: m, Value: m}
+	}
+	writeJSON(w, list)
+}
+
+func (h *handler) search(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, metrics)
+}
+
+// queryRequest is the body of a /query request. Only the fields used here
+// are decoded.
+type queryRequest struct {
+	Range struct {
+		From time.Time `json:"from"`
+		To   time.Time `json:"to"`
+	} `json:"range"`
+	Targets []struct {
+		Target string `json:"target"`
+		RefID  string `json:"refId"`
+		Hide   bool   `json:"hide"`
+	} `json:"targets"`
+}
+
+// series is a time series of the /query response, with datapoints as
+// [value, unix milliseconds].
+type series struct {
+	Target     string       `json:"target"`
+	Datapoints [][2]float64 `json:"datapoints"`
+}
+
+// table is a table of the /query response.
+type table struct {
+	Type    string          `json:"type"`
+	Columns []column        `json:"columns"`
+	Rows    [][]interface{} `json:"rows"`
+}
+
+type column struct {
+	Text string `json:"text"`
+	Type string `json:"type"` // string, number or time
+}
+
+func (h *handler) query(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req queryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("invalid query: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	var samples []models.TrendSample
+	var servers []models.ServerInfo
+	resp := []interface{}{}
+	for _, t := range req.Targets {
+		if t.Hide || t.Target == "" {
+			continue
+		}
+		if t.Target == MetricInventory {
+			if servers == nil {
+				var err error
+				if servers, _, err = h.src.Results(); err != nil {
+					http.Error(w, err.Error(), http.StatusInternalServerError)
+					return
+				}
+			}
+			resp = append(resp, inventoryTable(servers))
+			continue
+		}
+
+		if samples == nil {
+			var err error
+			if samples, err = h.src.Trends(); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+		s, err := trendSeries(t.Target, samples, req.Range.From, req.Range.To)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		resp = append(resp, s)
+	}
+	writeJSON(w, resp)
+}
+
+// trendSeries returns the time series of a metric from the samples within
+// [from, to]. A zero time leaves that end of the range open.
+func trendSeries(metric string, samples []models.TrendSample, from, to time.Time) (series, error) {
+	var value func(models.TrendSample) float64
+	switch metric {
+	case MetricServers:
+		value = func(s models.TrendSample) float64 { return float64(s.Servers) }
+	case MetricFailed:
+		value = func(s models.TrendSample) float64 { return float64(s.Failed) }
+	case MetricMemoryGiB:
+		value = func(s models.TrendSample) float64 { return s.MemoryGiB }
+	case MetricPowerWatts:
+		value = func(s models.TrendSample) float64 { return float64(s.PowerWatts) }
+	case MetricFailedDrives:
+		value = func(s models.TrendSample) float64 { return float64(len(s.FailedDrives)) }
+	default:
+		return series{}, fmt.Errorf("unknown metric %q", metric)
+	}
+
+	out := series{Target: metric, Datapoints: [][2]float64{}}
+	for _, s := range samples {
+		if (!from.IsZero() && s.At.Before(from)) || (!to.IsZero() && s.At.After(to)) {
+			continue
+		}
+		out.Datapoints = append(out.Datapoints, [2]float64{value(s), float64(s.At.UnixMilli())})
+	}
+	return out, nil
+}
+
+// serverRow is a server of the last scan, flattened for tables.
+type serverRow struct {
+	Host        string     `json:"host"`
+	Name        string     `json:"name"`
+	Model       string     `json:"model"`
+	ServiceTag  string     `json:"service_tag"`
+	Status      string     `json:"status"` // ok, partial, stale or failed
+	Health      string     `json:"health"` // OK, Warning or Critical ("" for failed hosts)
+	CPUs        int        `json:"cpus"`
+	MemoryGiB   float64    `json:"memory_gib"`
+	Drives      int        `json:"drives"`
+	StorageTB   float64    `json:"storage_tb"`
+	GPUs        int        `json:"gpus"`
+	PowerWatts  int        `json:"power_watts"`
+	PowerState  string     `json:"power_state"`
+	BiosVersion string     `json:"bios_version"`
+	ErrorCode   string     `json:"error_code,omitempty"`
+	CollectedAt *time.Time `json:"collected_at,omitempty"`
+
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// rowOf flattens a server.
+func rowOf(s models.ServerInfo) serverRow {
+	row := serverRow{
+		Host:        s.Host,
+		Name:        s.GetDisplayName(),
+		Model:       s.Model,
+		ServiceTag:  s.ServiceTag,
+		Status:      "ok",
+		CPUs:        s.CPUCount,
+		MemoryGiB:   s.TotalMemoryGiB,
+		Drives:      s.DriveCount,
+		StorageTB:   s.TotalStorageTB,
+		GPUs:        s.GPUCount,
+		PowerWatts:  s.PowerConsumedWatts,
+		PowerState:  s.PowerState,
+		BiosVersion: s.BiosVersion,
+		Labels:      s.Labels,
+	}
+	if !s.CollectedAt.IsZero() {
+		at := s.CollectedAt
+		row.CollectedAt = &at
+	}
+	switch {
+	case s.Error != nil:
+		row.Status = "failed"
+		row.ErrorCode = string(errors.CodeOf(s.Error))
+		return row
+	case s.Stale:
+		row.Status = "stale"
+	case s.IsPartial():
+		row.Status = "partial"
+	}
+	row.Health = models.WorstHealth(s.HealthIssues())
+	return row
+}
+
+// inventoryTable returns the servers as a /query table, with a column per
+// label key after the fixed columns.
+func inventoryTable(servers []models.ServerInfo) table {
+	labels := models.LabelKeys(servers)
+	t := table{
+		Type: "table",
+		Columns: []column{
+			{"Host", "string"}, {"Name", "string"}, {"Model", "string"}, {"Service Tag", "string"},
+			{"Status", "string"}, {"Health", "string"}, {"CPUs", "number"}, {"Memory (GiB)", "number"},
+			{"Drives", "number"}, {"Storage (TB)", "number"}, {"GPUs", "number"}, {"Power (W)", "number"},
+			{"Power State", "string"}, {"BIOS", "string"}, {"Error Code", "string"},
+		},
+		Rows: [][]interface{}{},
+	}
+	for _, k := range labels {
+		t.Columns = append(t.Columns, column{k, "string"})
+	}
+	for _, s := range sortedServers(servers) {
+		r := rowOf(s)
+		row := []interface{}{
+			r.Host, r.Name, r.Model, r.ServiceTag, r.Status, r.Health, r.CPUs, r.MemoryGiB,
+			r.Drives, r.StorageTB, r.GPUs, r.PowerWatts, r.PowerState, r.BiosVersion, r.ErrorCode,
+		}
+		for _, k := range labels {
+			row = append(row, r.Labels[k])
+		}
+		t.Rows = append(t.Rows, row)
+	}
+	return t
+}
+
+func (h *handler) servers(w http.ResponseWriter, r *http.Request) {
+	servers, _, err := h.src.Results()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	rows := make([]serverRow, 0, len(servers))
+	for _, s := range sortedServers(servers) {
+		rows = append(rows, rowOf(s))
+	}
+	writeJSON(w, rows)
+}
+
+// trendRow is a trend sample, flattened for tables.
+type trendRow struct {
+	Time         time.Time `json:"time"`
+	Servers      int       `json:"servers"`
+	Failed       int       `json:"failed"`
+	MemoryGiB    float64   `json:"memory_gib"`
+	PowerWatts   int       `json:"power_watts"`
+	FailedDrives int       `json:"failed_drives"`
+}
+
+func (h *handler) trends(w http.ResponseWriter, r *http.Request) {
+	samples, err := h.src.Trends()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	rows := make([]trendRow, 0, len(samples))
+	for _, s := range samples {
+		rows = append(rows, trendRow{
+			Time:         s.At,
+			Servers:      s.Servers,
+			Failed:       s.Failed,
+			MemoryGiB:    s.MemoryGiB,
+			PowerWatts:   s.PowerWatts,
+			FailedDrives: len(s.FailedDrives),
+		})
+	}
+	writeJSON(w, rows)
+}
+
+// statsRow is the statistics of the last scan, flattened for tables.
+type statsRow struct {
+	Total           int     `json:"total"`
+	Successful      int     `json:"successful"`
+	Failed          int     `json:"failed"`
+	Partial         int     `json:"partial"`
+	Stale           int     `json:"stale"`
+	Unchanged       int     `json:"unchanged"`
+	SuccessRate     float64 `json:"success_rate"`
+	DurationSeconds float64 `json:"duration_seconds"`
+	RunID           string  `json:"run_id,omitempty"`
+}
+
+func (h *handler) stats(w http.ResponseWriter, r *http.Request) {
+	_, stats, err := h.src.Results()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, []statsRow{{
+		Total:           stats.TotalServers,
+		Successful:      stats.SuccessfulCount,
+		Failed:          stats.FailedCount,
+		Partial:         stats.PartialCount,
+		Stale:           stats.StaleCount,
+		Unchanged:       stats.UnchangedCount,
+		SuccessRate:     stats.SuccessRate(),
+		DurationSeconds: stats.TotalDuration.Seconds(),
+		RunID:           stats.RunID,
+	}})
+}
+
+// sortedServers returns the servers sorted by host.
+func sortedServers(servers []models.ServerInfo) []models.ServerInfo {
+	sorted := append([]models.ServerInfo(nil), servers...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Key() < sorted[j].Key() })
+	return sorted
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,142 @@
+package grafana
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+func day(d int) time.Time {
+	return time.Date(2026, 10, d, 6, 0, 0, 0, time.UTC)
+}
+
+func testHandler() http.Handler {
+	return Handler(Source{
+		Results: func() ([]models.ServerInfo, models.CollectionStats, error) {
+			return []models.ServerInfo{
+				{Host: "10.0.0.2", Model: "PowerEdge R750", ServiceTag: "TAG2", CPUCount: 2, TotalMemoryGiB: 512, PowerConsumedWatts: 420,
+					Drives: []models.DriveInfo{{Name: "Disk 0", Health: models.HealthCritical}},
+					Labels: map[string]string{"datacenter": "fra1"}},
+				{Host: "10.0.0.1", Error: errors.New("context deadline exceeded")},
+			}, models.CollectionStats{
+				TotalServers: 2, SuccessfulCount: 1, FailedCount: 1, TotalDuration: 90 * time.Second,
+			}, nil
+		},
+		Trends: func() ([]models.TrendSample, error) {
+			return []models.TrendSample{
+				{At: day(1), Servers: 10, PowerWatts: 4000, Failed: 1},
+				{At: day(2), Servers: 11, PowerWatts: 4300, FailedDrives: []string{"TAG2/Disk 0"}},
+				{At: day(3), Servers: 11, PowerWatts: 4200},
+			}, nil
+		},
+	})
+}
+
+func do(t *testing.T, h http.Handler, method, path, body string, v interface{}) int {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+	if v != nil && rec.Code == http.StatusOK {
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), v), rec.Body.String())
+	}
+	return rec.Code
+}
+
+func TestHandler_JSONDatasource(t *testing.T) {
+	h := testHandler()
+
+	assert.Equal(t, http.StatusOK, do(t, h, http.MethodGet, "/", "", nil), "connection test")
+	assert.Equal(t, http.StatusNotFound, do(t, h, http.MethodGet, "/unknown", "", nil))
+
+	var metrics []metric
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPost, "/metrics", "{}", &metrics))
+	assert.Contains(t, metrics, metric{Label: MetricPowerWatts, Value: MetricPowerWatts})
+
+	var names []string
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPost, "/search", `{"target":""}`, &names))
+	assert.Contains(t, names, MetricInventory)
+
+	var resp []map[string]interface{}
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPost, "/query", `{
+		"range": {"from": "2026-10-02T00:00:00Z", "to": "2026-10-31T00:00:00Z"},
+		"targets": [
+			{"target": "power_watts", "refId": "A"},
+			{"target": "failed_drives", "refId": "B"},
+			{"target": "servers", "refId": "C", "hide": true},
+			{"target": "inventory", "refId": "D"}
+		]}`, &resp))
+	require.Len(t, resp, 3)
+
+	assert.Equal(t, "power_watts", resp[0]["target"])
+	assert.Equal(t, []interface{}{
+		[]interface{}{4300.0, float64(day(2).UnixMilli())},
+		[]interface{}{4200.0, float64(day(3).UnixMilli())},
+	}, resp[0]["datapoints"], "samples before the range are left out")
+	assert.Equal(t, []interface{}{1.0, float64(day(2).UnixMilli())}, resp[1]["datapoints"].([]interface{})[0])
+
+	assert.Equal(t, "table", resp[2]["type"])
+	rows := resp[2]["rows"].([]interface{})
+	require.Len(t, rows, 2)
+	assert.Equal(t, "10.0.0.1", rows[0].([]interface{})[0], "sorted by host")
+	assert.Equal(t, "failed", rows[0].([]interface{})[4])
+	assert.Equal(t, "E_TIMEOUT", rows[0].([]interface{})[14])
+	assert.Equal(t, models.HealthCritical, rows[1].([]interface{})[5])
+	columns := resp[2]["columns"].([]interface{})
+	assert.Equal(t, "datacenter", columns[len(columns)-1].(map[string]interface{})["text"], "a column per label")
+	assert.Equal(t, "", rows[0].([]interface{})[15])
+	assert.Equal(t, "fra1", rows[1].([]interface{})[15])
+
+	assert.Equal(t, http.StatusBadRequest, do(t, h, http.MethodPost, "/query", `{"targets":[{"target":"cpu_temp"}]}`, nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, do(t, h, http.MethodGet, "/query", "", nil))
+}
+
+func TestHandler_Infinity(t *testing.T) {
+	h := testHandler()
+
+	var servers []map[string]interface{}
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodGet, "/servers", "", &servers))
+	require.Len(t, servers, 2)
+	assert.Equal(t, "10.0.0.1", servers[0]["host"])
+	assert.Equal(t, "failed", servers[0]["status"])
+	assert.Equal(t, "PowerEdge R750", servers[1]["model"])
+	assert.Equal(t, 420.0, servers[1]["power_watts"])
+	assert.Equal(t, "ok", servers[1]["status"])
+	assert.Equal(t, map[string]interface{}{"datacenter": "fra1"}, servers[1]["labels"])
+
+	var trends []map[string]interface{}
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodGet, "/trends", "", &trends))
+	require.Len(t, trends, 3)
+	assert.Equal(t, "2026-10-01T06:00:00Z", trends[0]["time"])
+	assert.Equal(t, 1.0, trends[0]["failed"])
+	assert.Equal(t, 1.0, trends[1]["failed_drives"])
+
+	var stats []map[string]interface{}
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodGet, "/stats", "", &stats))
+	require.Len(t, stats, 1)
+	assert.Equal(t, 2.0, stats[0]["total"])
+	assert.Equal(t, 50.0, stats[0]["success_rate"])
+	assert.Equal(t, 90.0, stats[0]["duration_seconds"])
+}
+
+func TestHandler_SourceError(t *testing.T) {
+	h := Handler(Source{
+		Results: func() ([]models.ServerInfo, models.CollectionStats, error) {
+			return nil, models.CollectionStats{}, errors.New("unexpected end of JSON input")
+		},
+		Trends: func() ([]models.TrendSample, error) { return nil, nil },
+	})
+	assert.Equal(t, http.StatusInternalServerError, do(t, h, http.MethodGet, "/servers", "", nil))
+
+	var trends []map[string]interface{}
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodGet, "/trends", "", &trends))
+	assert.Empty(t, trends, "an empty array, not null")
+}
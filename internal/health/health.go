@@ -0,0 +1,152 @@
+// Package health serves the liveness and readiness endpoints of daemon mode,
+// for Kubernetes probes or load balancer checks:
+//
+//	/healthz  200 while the daemon loop makes progress, 503 if a scan cycle
+//	          has been running for longer than the stall timeout
+//	/readyz   200 once the first cycle has finished (scanned, or skipped by
+//	          a replica that is not the leader), 503 before
+//
+// Both return the daemon status as JSON.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Status is the daemon state reported by the endpoints. It is safe for
+// concurrent use.
+type Status struct {
+	mu         sync.Mutex
+	stallAfter time.Duration
+	now        func() time.Time
+
+	extra map[string]http.Handler
+
+	lastBeat  time.Time
+	ready     bool
+	leader    *bool
+	lastScan  time.Time
+	lastError string
+}
+
+// report is the JSON body of the endpoints.
+type report struct {
+	Status    string     `json:"status"`
+	Ready     bool       `json:"ready"`
+	Leader    *bool      `json:"leader,omitempty"`
+	LastBeat  time.Time  `json:"last_beat"`
+	LastScan  *time.Time `json:"last_scan,omitempty"`
+	LastError string     `json:"last_error,omitempty"`
+}
+
+// New returns a status whose liveness fails if the daemon loop does not
+// call Beat for longer than stallAfter.
+func New(stallAfter time.Duration) *Status {
+	return &Status{stallAfter: stallAfter, now: time.Now, lastBeat: time.Now()}
+}
+
+// Beat records that the daemon loop is making progress.
+func (s *Status) Beat() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastBeat = s.now()
+}
+
+// SetLeader records whether this replica holds the leader lease.
+func (s *Status) SetLeader(leader bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.leader = &leader
+}
+
+// CycleDone records the end of a cycle and marks the daemon ready. err is
+// the error of the cycle's scan, if one ran.
+func (s *Status) CycleDone(scanned bool, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastBeat = s.now()
+	s.ready = true
+	if scanned {
+		s.lastScan = s.lastBeat
+		s.lastError = ""
+		if err != nil {
+			s.lastError = err.Error()
+		}
+	}
+}
+
+// Handle adds an endpoint to Handler, e.g. for daemon state. It must be
+// called before the endpoints are served.
+func (s *Status) Handle(pattern string, h http.Handler) {
+	if s.extra == nil {
+		s.extra = map[string]http.Handler{}
+	}
+	s.extra[pattern] = h
+}
+
+// Handler returns the /healthz and /readyz endpoints and those added with
+// Handle.
+func (s *Status) Handler() http.Handler {
+	mux := http.NewServeMux()
+	for pattern, h := range s.extra {
+		mux.Handle(pattern, h)
+	}
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		rep := s.report()
+		s.write(w, rep, rep.Status == "ok")
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		rep := s.report()
+		s.write(w, rep, rep.Ready)
+	})
+	return mux
+}
+
+// ListenAndServe serves Handler on addr until ctx is cancelled.
+func (s *Status) ListenAndServe(ctx context.Context, addr string) error {
+	srv := &http.Server{Addr: addr, Handler: s.Handler(), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func (s *Status) report() report {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rep := report{
+		Status:    "ok",
+		Ready:     s.ready,
+		Leader:    s.leader,
+		LastBeat:  s.lastBeat,
+		LastError: s.lastError,
+	}
+	if s.stallAfter > 0 && s.now().Sub(s.lastBeat) > s.stallAfter {
+		rep.Status = "stalled"
+	}
+	if !s.lastScan.IsZero() {
+		last := s.lastScan
+		rep.LastScan = &last
+	}
+	return rep
+}
+
+func (s *Status) write(w http.ResponseWriter, rep report, ok bool) {
+	w.Header().Set("Content-Type", "application/json")
+	if !ok {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(rep)
+}
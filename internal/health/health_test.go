@@ -0,0 +1,66 @@
+package health
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func get(t *testing.T, h http.Handler, path string) (int, map[string]interface{}) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	return rec.Code, body
+}
+
+func TestStatus_Readiness(t *testing.T) {
+	s := New(time.Hour)
+	h := s.Handler()
+
+	code, body := get(t, h, "/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code, "not ready before the first cycle")
+	assert.Equal(t, false, body["ready"])
+
+	s.SetLeader(false)
+	s.CycleDone(false, nil)
+	code, body = get(t, h, "/readyz")
+	assert.Equal(t, http.StatusOK, code, "a standby replica is ready")
+	assert.Equal(t, false, body["leader"])
+	assert.NotContains(t, body, "last_scan")
+
+	s.SetLeader(true)
+	s.CycleDone(true, errors.New("2 of 10 servers failed"))
+	_, body = get(t, h, "/readyz")
+	assert.Equal(t, true, body["leader"])
+	assert.Contains(t, body, "last_scan")
+	assert.Equal(t, "2 of 10 servers failed", body["last_error"])
+}
+
+func TestStatus_Liveness(t *testing.T) {
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	s := New(2 * time.Hour)
+	s.now = func() time.Time { return now }
+	s.Beat()
+	h := s.Handler()
+
+	code, body := get(t, h, "/healthz")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "ok", body["status"])
+
+	now = now.Add(3 * time.Hour)
+	code, body = get(t, h, "/healthz")
+	assert.Equal(t, http.StatusServiceUnavailable, code, "no progress within the stall timeout")
+	assert.Equal(t, "stalled", body["status"])
+
+	s.Beat()
+	code, _ = get(t, h, "/healthz")
+	assert.Equal(t, http.StatusOK, code)
+}
@@ -0,0 +1,145 @@
+// Package history keeps the last known good inventory of every host, so that
+// hosts failing a scan can still be reported with their previous data.
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+// Store holds the last successful scan result per host, persisted as JSON.
+type Store struct {
+	path  string
+	hosts map[string]models.ServerInfo
+}
+
+// Load reads the store from path. A missing file yields an empty store.
+func Load(path string) (*Store, error) {
+	s := &Store{path: path, hosts: make(map[string]models.ServerInfo)}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	var servers []models.ServerInfo
+	if err := json.Unmarshal(data, &servers); err != nil {
+		return nil, fmt.Errorf("failed to parse history %s: %w", path, err)
+	}
+	for _, srv := range servers {
+		s.hosts[srv.Key()] = srv
+	}
+	return s, nil
+}
+
+// Last returns the last known good inventory of a result key (the host, see
+// models.ServerInfo.Key).
+func (s *Store) Last(key string) (models.ServerInfo, bool) {
+	srv, ok := s.hosts[key]
+	return srv, ok
+}
+
+// Servers returns the last known good inventory of every host, sorted by key.
+func (s *Store) Servers() []models.ServerInfo {
+	servers := make([]models.ServerInfo, 0, len(s.hosts))
+	for _, srv := range s.hosts {
+		servers = append(servers, srv)
+	}
+	sort.Slice(servers, func(i, j int) bool { return servers[i].Key() < servers[j].Key() })
+	return servers
+}
+
+// Len returns the number of hosts with a known good inventory.
+func (s *Store) Len() int {
+	return len(s.hosts)
+}
+
+// Apply records successful results as the new last known good inventory and
+// replaces failed results with the stored inventory of the same host, flagged
+// as stale. It returns the merged results and the number of stale entries.
+func (s *Store) Apply(results []models.ServerInfo) ([]models.ServerInfo, int) {
+	merged := make([]models.ServerInfo, len(results))
+	stale := 0
+
+	for i, res := range results {
+		var isStale bool
+		merged[i], isStale = s.ApplyOne(res)
+		if isStale {
+			stale++
+		}
+	}
+
+	return merged, stale
+}
+
+// ApplyOne is Apply for a single result, for use while results stream in.
+// It returns the (possibly stale) result and whether it was substituted.
+func (s *Store) ApplyOne(res models.ServerInfo) (models.ServerInfo, bool) {
+	if res.Error == nil {
+		// Stale entries are never written back; only fresh data is "good".
+		if !res.Stale {
+			s.hosts[res.Key()] = res
+		}
+		return res, false
+	}
+
+	last, ok := s.hosts[res.Key()]
+	if !ok {
+		return res, false
+	}
+
+	since := last.CollectedAt
+	last.Stale = true
+	last.StaleSince = &since
+	last.StaleError = res.Error.Error()
+	last.ScanDuration = res.ScanDuration
+	last.PhaseDurations = res.PhaseDurations
+	if res.Name != "" {
+		last.Name = res.Name
+	}
+	last.Labels = res.Labels
+	return last, true
+}
+
+// Save atomically writes the store back to its file.
+func (s *Store) Save() error {
+	data, err := json.MarshalIndent(s.Servers(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode history: %w", err)
+	}
+	if err := writeAtomic(s.path, data); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	return nil
+}
+
+// writeAtomic writes data to a temporary file next to path and renames it,
+// creating the directory if needed.
+func writeAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
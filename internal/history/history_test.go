@@ -0,0 +1,89 @@
+package history
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+func TestStore_ApplyAndReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.json")
+	collected := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	store, err := Load(path)
+	require.NoError(t, err)
+	assert.Zero(t, store.Len())
+
+	first, stale := store.Apply([]models.ServerInfo{
+		{Host: "10.0.0.1", Model: "PowerEdge R750", CollectedAt: collected},
+		{Host: "10.0.0.2", Error: errors.New("timeout")},
+	})
+	assert.Zero(t, stale)
+	assert.Error(t, first[1].Error)
+	require.NoError(t, store.Save())
+
+	reloaded, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, 1, reloaded.Len())
+
+	second, stale := reloaded.Apply([]models.ServerInfo{
+		{Host: "10.0.0.1", Error: errors.New("connection refused")},
+	})
+	require.Equal(t, 1, stale)
+	got := second[0]
+	assert.NoError(t, got.Error)
+	assert.True(t, got.Stale)
+	assert.Equal(t, "PowerEdge R750", got.Model)
+	assert.Equal(t, "connection refused", got.StaleError)
+	require.NotNil(t, got.StaleSince)
+	assert.True(t, got.StaleSince.Equal(collected))
+}
+
+func TestStore_StaleEntriesAreNotRecorded(t *testing.T) {
+	store, err := Load(filepath.Join(t.TempDir(), "history.json"))
+	require.NoError(t, err)
+
+	store.Apply([]models.ServerInfo{{Host: "10.0.0.1", Stale: true}})
+
+	assert.Zero(t, store.Len())
+}
+
+func TestStore_MultiSystemHost(t *testing.T) {
+	store, err := Load(filepath.Join(t.TempDir(), "history.json"))
+	require.NoError(t, err)
+
+	store.Apply([]models.ServerInfo{
+		{Host: "10.0.0.1", SystemID: "Node1", ServiceTag: "TAG1"},
+		{Host: "10.0.0.1", SystemID: "Node2", ServiceTag: "TAG2"},
+	})
+	assert.Equal(t, 2, store.Len(), "systems of one host are kept separately")
+
+	merged, stale := store.Apply([]models.ServerInfo{
+		{Host: "10.0.0.1", SystemID: "Node2", Error: errors.New("timeout")},
+	})
+	require.Equal(t, 1, stale)
+	assert.Equal(t, "TAG2", merged[0].ServiceTag)
+}
+
+func TestLoad_MigratesOldVersions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.json")
+	require.NoError(t, os.WriteFile(path, []byte(`[{"host": "10.0.0.1", "manufacturer": "Dell Inc.", "model": "Dell PowerEdge R750"}]`), 0o600))
+
+	store, err := Load(path)
+	require.NoError(t, err)
+	last, ok := store.Last("10.0.0.1")
+	require.True(t, ok)
+	assert.Equal(t, "PowerEdge R750", last.Model)
+
+	require.NoError(t, store.Save())
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"schema_version": 2`)
+}
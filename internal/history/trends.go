@@ -0,0 +1,91 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+// maxTrendSamples bounds the trend file to about three years of daily
+// samples; older samples are dropped.
+const maxTrendSamples = 3 * 366
+
+// Trends holds the daily fleet totals for the trend report, persisted as
+// JSON next to the last known good store.
+type Trends struct {
+	path    string
+	samples []models.TrendSample
+}
+
+// LoadTrends reads the trend samples from path. A missing file yields no
+// samples.
+func LoadTrends(path string) (*Trends, error) {
+	t := &Trends{path: path}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return t, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trends: %w", err)
+	}
+	if err := json.Unmarshal(data, &t.samples); err != nil {
+		return nil, fmt.Errorf("failed to parse trends %s: %w", path, err)
+	}
+	sort.SliceStable(t.samples, func(i, j int) bool { return t.samples[i].At.Before(t.samples[j].At) })
+	return t, nil
+}
+
+// Add records the totals of a scan. A sample of the same (UTC) day as the
+// last one replaces it, keeping the failed drives of both, so the file holds
+// one sample per day.
+func (t *Trends) Add(s models.TrendSample) {
+	if n := len(t.samples); n > 0 && sameDay(t.samples[n-1], s) {
+		s.FailedDrives = union(t.samples[n-1].FailedDrives, s.FailedDrives)
+		t.samples[n-1] = s
+		return
+	}
+	t.samples = append(t.samples, s)
+	if len(t.samples) > maxTrendSamples {
+		t.samples = t.samples[len(t.samples)-maxTrendSamples:]
+	}
+}
+
+// Samples returns the samples, oldest first.
+func (t *Trends) Samples() []models.TrendSample {
+	return t.samples
+}
+
+// Save atomically writes the samples back to their file.
+func (t *Trends) Save() error {
+	data, err := json.MarshalIndent(t.samples, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode trends: %w", err)
+	}
+	if err := writeAtomic(t.path, data); err != nil {
+		return fmt.Errorf("failed to write trends: %w", err)
+	}
+	return nil
+}
+
+func sameDay(a, b models.TrendSample) bool {
+	ya, ma, da := a.At.UTC().Date()
+	yb, mb, db := b.At.UTC().Date()
+	return ya == yb && ma == mb && da == db
+}
+
+func union(a, b []string) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	var out []string
+	for _, s := range append(append([]string{}, a...), b...) {
+		if !seen[s] {
+			seen[s] = true
+			out = append(out, s)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
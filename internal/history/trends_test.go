@@ -0,0 +1,46 @@
+package history
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+func TestTrends_DailySamples(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trends.json")
+	morning := time.Date(2025, 3, 1, 6, 0, 0, 0, time.UTC)
+
+	trends, err := LoadTrends(path)
+	require.NoError(t, err)
+	assert.Empty(t, trends.Samples())
+
+	trends.Add(models.TrendSample{At: morning, Servers: 10, FailedDrives: []string{"TAG1/Disk 0"}})
+	trends.Add(models.TrendSample{At: morning.Add(12 * time.Hour), Servers: 11, FailedDrives: []string{"TAG2/Disk 3"}})
+	trends.Add(models.TrendSample{At: morning.AddDate(0, 0, 1), Servers: 12})
+	require.NoError(t, trends.Save())
+
+	reloaded, err := LoadTrends(path)
+	require.NoError(t, err)
+	samples := reloaded.Samples()
+	require.Len(t, samples, 2, "one sample per day")
+	assert.Equal(t, 11, samples[0].Servers, "the last scan of the day wins")
+	assert.Equal(t, []string{"TAG1/Disk 0", "TAG2/Disk 3"}, samples[0].FailedDrives, "failures of the day are kept")
+	assert.Equal(t, 12, samples[1].Servers)
+}
+
+func TestTrends_Retention(t *testing.T) {
+	trends, err := LoadTrends(filepath.Join(t.TempDir(), "trends.json"))
+	require.NoError(t, err)
+
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < maxTrendSamples+5; i++ {
+		trends.Add(models.TrendSample{At: start.AddDate(0, 0, i)})
+	}
+	require.Len(t, trends.Samples(), maxTrendSamples)
+	assert.Equal(t, start.AddDate(0, 0, 5), trends.Samples()[0].At, "oldest samples are dropped")
+}
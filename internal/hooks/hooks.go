@@ -0,0 +1,159 @@
+// Package hooks runs user-provided commands at points of a scan run
+// (post_scan, post_sync, on_failure, on_regression), so site-specific glue such as ticket
+// creation or CMDB pushes can be added without modifying the tool.
+//
+// Each command is run through the shell (sh -c, or cmd /C on Windows) with
+// the run summary in environment variables:
+//
+//	IDRAC_HOOK_EVENT           post_scan, post_sync, on_failure or on_regression
+//	IDRAC_RUN_ID               ID of the run (see pkg/runid)
+//	IDRAC_RESULTS_FILE         JSON results of the scan (if written)
+//	IDRAC_TOTAL                number of scanned hosts
+//	IDRAC_SUCCESSFUL           number of successfully scanned hosts
+//	IDRAC_FAILED               number of failed hosts
+//	IDRAC_STALE                number of failed hosts reported from history
+//	IDRAC_SYNC_FAILED          number of hosts that failed to sync (post_sync)
+//	IDRAC_FAILURE_CODES        failed hosts by error code, e.g. E_AUTH=2,E_TIMEOUT=1
+//	IDRAC_ERROR                error that failed the run (on_failure)
+//	IDRAC_ERROR_CODE           error code of IDRAC_ERROR, e.g. E_NETBOX_AUTH
+//	IDRAC_REGRESSIONS          number of health regressions (on_regression)
+//	IDRAC_REGRESSION_SEVERITY  worst regression severity: warning or critical
+//	IDRAC_REGRESSIONS_FILE     JSON list of the regressions (on_regression)
+package hooks
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/config"
+	"github.com/braunma/idrac-netbox-importer/pkg/errors"
+	"github.com/braunma/idrac-netbox-importer/pkg/logging"
+)
+
+// Event identifies when a hook runs.
+type Event string
+
+// Hook events.
+const (
+	PostScan     Event = "post_scan"
+	PostSync     Event = "post_sync"
+	OnFailure    Event = "on_failure"
+	OnRegression Event = "on_regression"
+)
+
+// Summary describes the run passed to a hook.
+type Summary struct {
+	RunID       string
+	ResultsFile string
+	Total       int
+	Successful  int
+	Failed      int
+	Stale       int
+	SyncFailed  int
+	Error       string
+
+	// ErrorCode is the code of Error, FailureCodes the error codes of the
+	// failed hosts with their counts (see pkg/errors).
+	ErrorCode    string
+	FailureCodes string
+
+	// Health regressions since the last good scan (on_regression)
+	Regressions        int
+	RegressionSeverity string
+	RegressionsFile    string
+}
+
+// env returns the summary as environment variables for an event.
+func (s Summary) env(event Event) []string {
+	return []string{
+		"IDRAC_HOOK_EVENT=" + string(event),
+		"IDRAC_RUN_ID=" + s.RunID,
+		"IDRAC_RESULTS_FILE=" + s.ResultsFile,
+		"IDRAC_TOTAL=" + strconv.Itoa(s.Total),
+		"IDRAC_SUCCESSFUL=" + strconv.Itoa(s.Successful),
+		"IDRAC_FAILED=" + strconv.Itoa(s.Failed),
+		"IDRAC_STALE=" + strconv.Itoa(s.Stale),
+		"IDRAC_SYNC_FAILED=" + strconv.Itoa(s.SyncFailed),
+		"IDRAC_FAILURE_CODES=" + s.FailureCodes,
+		"IDRAC_ERROR=" + s.Error,
+		"IDRAC_ERROR_CODE=" + s.ErrorCode,
+		"IDRAC_REGRESSIONS=" + strconv.Itoa(s.Regressions),
+		"IDRAC_REGRESSION_SEVERITY=" + s.RegressionSeverity,
+		"IDRAC_REGRESSIONS_FILE=" + s.RegressionsFile,
+	}
+}
+
+// Runner runs the configured hooks.
+type Runner struct {
+	cfg    config.HooksConfig
+	logger *zap.SugaredLogger
+}
+
+// New creates a Runner for the configured hooks.
+func New(cfg config.HooksConfig) *Runner {
+	return &Runner{
+		cfg:    cfg,
+		logger: logging.WithComponent("hooks"),
+	}
+}
+
+// Has returns true if any command is configured for the event.
+func (r *Runner) Has(event Event) bool {
+	return len(r.commands(event)) > 0
+}
+
+func (r *Runner) commands(event Event) []string {
+	switch event {
+	case PostScan:
+		return r.cfg.PostScan
+	case PostSync:
+		return r.cfg.PostSync
+	case OnFailure:
+		return r.cfg.OnFailure
+	case OnRegression:
+		return r.cfg.OnRegression
+	}
+	return nil
+}
+
+// Run executes all commands of an event in order. A failing command does not
+// stop the others; all failures are returned together.
+func (r *Runner) Run(ctx context.Context, event Event, summary Summary) error {
+	multiErr := &errors.MultiError{}
+	for _, command := range r.commands(event) {
+		if err := r.runCommand(ctx, event, command, summary); err != nil {
+			multiErr.Add(fmt.Errorf("%s hook %q: %w", event, command, err))
+		}
+	}
+	return multiErr.ErrorOrNil()
+}
+
+func (r *Runner) runCommand(ctx context.Context, event Event, command string, summary Summary) error {
+	ctx, cancel := context.WithTimeout(ctx, r.cfg.Timeout())
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	cmd.Env = append(os.Environ(), summary.env(event)...)
+
+	r.logger.Infow("running hook", "event", event, "command", command)
+	out, err := cmd.CombinedOutput()
+	if output := strings.TrimSpace(string(out)); output != "" {
+		r.logger.Infow("hook output", "event", event, "command", command, "output", output)
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("timed out after %s", r.cfg.Timeout())
+	}
+	return err
+}
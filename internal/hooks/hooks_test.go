@@ -0,0 +1,95 @@
+package hooks
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/config"
+)
+
+func TestRunner_PassesSummary(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	out := filepath.Join(t.TempDir(), "env.txt")
+
+	r := New(config.HooksConfig{
+		OnFailure: []string{`echo "$IDRAC_HOOK_EVENT $IDRAC_RUN_ID $IDRAC_RESULTS_FILE $IDRAC_FAILED/$IDRAC_TOTAL $IDRAC_FAILURE_CODES $IDRAC_ERROR $IDRAC_ERROR_CODE" > ` + out},
+	})
+	require.True(t, r.Has(OnFailure))
+	assert.False(t, r.Has(PostScan))
+
+	err := r.Run(context.Background(), OnFailure, Summary{
+		RunID:       "run-1",
+		ResultsFile: "/var/lib/idrac-inventory/last-scan.json",
+		Total:       10,
+		Failed:      2,
+		Error:       "2 of 10 servers failed",
+
+		ErrorCode:    "E_SCAN_FAILED",
+		FailureCodes: "E_AUTH=1,E_TIMEOUT=1",
+	})
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.Equal(t, "on_failure run-1 /var/lib/idrac-inventory/last-scan.json 2/10 E_AUTH=1,E_TIMEOUT=1 2 of 10 servers failed E_SCAN_FAILED", strings.TrimSpace(string(data)))
+}
+
+func TestRunner_CollectsFailures(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	out := filepath.Join(t.TempDir(), "ran.txt")
+
+	r := New(config.HooksConfig{
+		PostScan: []string{"exit 3", "touch " + out},
+	})
+
+	err := r.Run(context.Background(), PostScan, Summary{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `post_scan hook "exit 3"`)
+	assert.FileExists(t, out, "later hooks still run")
+}
+
+func TestRunner_Timeout(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+
+	r := New(config.HooksConfig{PostSync: []string{"sleep 5"}, TimeoutSeconds: 1})
+
+	err := r.Run(context.Background(), PostSync, Summary{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "timed out")
+}
+
+func TestRunner_OnRegression(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	out := filepath.Join(t.TempDir(), "env.txt")
+
+	r := New(config.HooksConfig{
+		OnRegression: []string{`echo "$IDRAC_HOOK_EVENT $IDRAC_REGRESSIONS $IDRAC_REGRESSION_SEVERITY $IDRAC_REGRESSIONS_FILE" > ` + out},
+	})
+	require.True(t, r.Has(OnRegression))
+
+	err := r.Run(context.Background(), OnRegression, Summary{
+		Regressions:        2,
+		RegressionSeverity: "critical",
+		RegressionsFile:    "/var/lib/idrac-inventory/regressions.json",
+	})
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.Equal(t, "on_regression 2 critical /var/lib/idrac-inventory/regressions.json", strings.TrimSpace(string(data)))
+}
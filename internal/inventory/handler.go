@@ -0,0 +1,105 @@
+package inventory
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+// Handler returns the inventory endpoints of s:
+//
+//	GET /servers        all servers, or those matching one of the query
+//	                    parameters model, service_tag or health
+//	GET /servers/{key}  one server by host (host/system ID for hosts with
+//	                    several systems)
+//	GET /stats          the statistics of the scan
+//
+// Servers are encoded like the JSON output. If resultsFile is set, the store
+// follows it (see Refresh).
+func Handler(s *Store, resultsFile string) http.Handler {
+	h := &handler{store: s, resultsFile: resultsFile}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/servers", h.list)
+	mux.HandleFunc("/servers/", h.get)
+	mux.HandleFunc("/stats", h.stats)
+	return mux
+}
+
+type handler struct {
+	store       *Store
+	resultsFile string
+}
+
+// refresh reads the results file if it changed. A failure is reported and
+// the request fails, rather than serving results that may be outdated.
+func (h *handler) refresh(w http.ResponseWriter) bool {
+	if h.resultsFile == "" {
+		return true
+	}
+	if _, err := h.store.Refresh(h.resultsFile); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return false
+	}
+	return true
+}
+
+func (h *handler) list(w http.ResponseWriter, r *http.Request) {
+	if !h.refresh(w) {
+		return
+	}
+	q := r.URL.Query()
+	var servers []models.ServerInfo
+	switch {
+	case q.Has("service_tag"):
+		servers = []models.ServerInfo{}
+		if srv, ok := h.store.ByServiceTag(q.Get("service_tag")); ok {
+			servers = append(servers, srv)
+		}
+	case q.Has("model"):
+		servers = h.store.ByModel(q.Get("model"))
+	case q.Has("health"):
+		servers = h.store.ByHealth(q.Get("health"))
+	default:
+		servers = h.store.Servers()
+	}
+	writeJSON(w, servers)
+}
+
+func (h *handler) get(w http.ResponseWriter, r *http.Request) {
+	if !h.refresh(w) {
+		return
+	}
+	srv, ok := h.store.Get(strings.TrimPrefix(r.URL.Path, "/servers/"))
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, srv)
+}
+
+// statsResponse is the body of /stats.
+type statsResponse struct {
+	Servers   int                    `json:"servers"`
+	UpdatedAt *time.Time             `json:"updated_at,omitempty"`
+	Stats     models.CollectionStats `json:"stats"`
+}
+
+func (h *handler) stats(w http.ResponseWriter, r *http.Request) {
+	if !h.refresh(w) {
+		return
+	}
+	stats, updated := h.store.Stats()
+	resp := statsResponse{Servers: h.store.Len(), Stats: stats}
+	if !updated.IsZero() {
+		resp.UpdatedAt = &updated
+	}
+	writeJSON(w, resp)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
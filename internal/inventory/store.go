@@ -0,0 +1,245 @@
+// Package inventory holds the latest scan result of every host in memory
+// for the endpoints of daemon mode and the serve command, indexed by model,
+// service tag and health. It follows the results file in the state
+// directory: the file is read again only after it changed, not on every
+// request.
+package inventory
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/braunma/idrac-netbox-importer/internal/output"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+// HealthFailed is the health under which hosts whose scan failed are
+// indexed; the others are indexed by their worst component health (OK,
+// Warning or Critical).
+const HealthFailed = "Failed"
+
+// entry is a stored server and the fields it is indexed by.
+type entry struct {
+	srv        models.ServerInfo
+	model      string
+	serviceTag string
+	health     string
+}
+
+// fileStamp identifies a version of the results file.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// Store is the latest result per host (models.ServerInfo.Key) and the
+// statistics of the scan that produced them. It is safe for concurrent use.
+// The store keeps its own copy of the results, but reads share their
+// component lists with it, so callers must not modify the servers returned.
+type Store struct {
+	mu       sync.RWMutex
+	entries  map[string]entry
+	stats    models.CollectionStats
+	updated  time.Time
+	byModel  map[string][]string // upper-case model -> keys
+	byTag    map[string]string   // upper-case service tag -> key
+	byHealth map[string][]string // health -> keys
+
+	// loadMu serializes Refresh; loaded is the file version last read
+	loadMu sync.Mutex
+	loaded fileStamp
+}
+
+// New returns an empty store.
+func New() *Store {
+	return &Store{
+		entries:  make(map[string]entry),
+		byModel:  make(map[string][]string),
+		byTag:    make(map[string]string),
+		byHealth: make(map[string][]string),
+	}
+}
+
+// Replace replaces the stored results and statistics.
+func (s *Store) Replace(results []models.ServerInfo, stats models.CollectionStats) error {
+	entries := make(map[string]entry, len(results))
+	byModel := make(map[string][]string)
+	byTag := make(map[string]string)
+	byHealth := make(map[string][]string)
+
+	for _, res := range results {
+		srv, err := deepCopy(res)
+		if err != nil {
+			return fmt.Errorf("%s: %w", res.Key(), err)
+		}
+		e := entry{
+			srv:        srv,
+			model:      strings.ToUpper(srv.Model),
+			serviceTag: strings.ToUpper(srv.ServiceTag),
+			health:     healthOf(srv),
+		}
+		key := srv.Key()
+		entries[key] = e
+		if e.model != "" {
+			byModel[e.model] = append(byModel[e.model], key)
+		}
+		if e.serviceTag != "" {
+			byTag[e.serviceTag] = key
+		}
+		byHealth[e.health] = append(byHealth[e.health], key)
+	}
+	stats, err := deepCopy(stats)
+	if err != nil {
+		return fmt.Errorf("stats: %w", err)
+	}
+	for _, keys := range byModel {
+		sort.Strings(keys)
+	}
+	for _, keys := range byHealth {
+		sort.Strings(keys)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries, s.stats, s.updated = entries, stats, time.Now()
+	s.byModel, s.byTag, s.byHealth = byModel, byTag, byHealth
+	return nil
+}
+
+// deepCopy returns a copy of v sharing no slices or maps with it, made by a
+// JSON round trip as the results file would.
+func deepCopy[T any](v T) (T, error) {
+	var out T
+	data, err := json.Marshal(v)
+	if err != nil {
+		return out, err
+	}
+	err = json.Unmarshal(data, &out)
+	return out, err
+}
+
+// healthOf returns the health a server is indexed by.
+func healthOf(srv models.ServerInfo) string {
+	if srv.Error != nil {
+		return HealthFailed
+	}
+	return models.WorstHealth(srv.HealthIssues())
+}
+
+// Refresh reads the results file at path (written by JSONFormatter, e.g.
+// the daemon's last-scan.json) if it changed since it was last read. A
+// missing file leaves the store as it is. It reports whether the file was
+// read.
+func (s *Store) Refresh(path string) (bool, error) {
+	s.loadMu.Lock()
+	defer s.loadMu.Unlock()
+
+	fi, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	stamp := fileStamp{modTime: fi.ModTime(), size: fi.Size()}
+	if stamp == s.loaded {
+		return false, nil
+	}
+
+	results, stats, err := output.ReadJSONFile(path)
+	if err != nil {
+		return false, err
+	}
+	if err := s.Replace(results, stats); err != nil {
+		return false, err
+	}
+	s.loaded = stamp
+	return true, nil
+}
+
+// servers returns the servers with the given keys.
+func (s *Store) servers(keys []string) []models.ServerInfo {
+	out := make([]models.ServerInfo, 0, len(keys))
+	for _, k := range keys {
+		if e, ok := s.entries[k]; ok {
+			out = append(out, e.srv)
+		}
+	}
+	return out
+}
+
+// Servers returns all servers, sorted by key.
+func (s *Store) Servers() []models.ServerInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	keys := make([]string, 0, len(s.entries))
+	for k := range s.entries {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return s.servers(keys)
+}
+
+// Get returns the server with the given key.
+func (s *Store) Get(key string) (models.ServerInfo, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	e, ok := s.entries[key]
+	if !ok {
+		return models.ServerInfo{}, false
+	}
+	return e.srv, true
+}
+
+// ByServiceTag returns the server with the given service tag, compared
+// case-insensitively.
+func (s *Store) ByServiceTag(tag string) (models.ServerInfo, bool) {
+	s.mu.RLock()
+	key, ok := s.byTag[strings.ToUpper(strings.TrimSpace(tag))]
+	s.mu.RUnlock()
+	if !ok {
+		return models.ServerInfo{}, false
+	}
+	return s.Get(key)
+}
+
+// ByModel returns the servers of a model, compared case-insensitively,
+// sorted by key.
+func (s *Store) ByModel(model string) []models.ServerInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.servers(s.byModel[strings.ToUpper(strings.TrimSpace(model))])
+}
+
+// ByHealth returns the servers of a health (OK, Warning, Critical or
+// HealthFailed, compared case-insensitively), sorted by key.
+func (s *Store) ByHealth(health string) []models.ServerInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for h, keys := range s.byHealth {
+		if strings.EqualFold(h, strings.TrimSpace(health)) {
+			return s.servers(keys)
+		}
+	}
+	return []models.ServerInfo{}
+}
+
+// Stats returns the statistics of the scan and when the store was last
+// replaced (zero if never).
+func (s *Store) Stats() (models.CollectionStats, time.Time) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.stats, s.updated
+}
+
+// Len returns the number of stored servers.
+func (s *Store) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.entries)
+}
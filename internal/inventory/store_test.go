@@ -0,0 +1,169 @@
+package inventory
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/braunma/idrac-netbox-importer/internal/output"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+func testResults() []models.ServerInfo {
+	return []models.ServerInfo{
+		{Host: "10.0.0.2", Model: "PowerEdge R750", ServiceTag: "TAG2",
+			Drives: []models.DriveInfo{{Name: "Disk 0", Health: models.HealthCritical}}},
+		{Host: "10.0.0.1", Model: "PowerEdge R750", ServiceTag: "TAG1"},
+		{Host: "10.0.0.3", Model: "PowerEdge R640", ServiceTag: "TAG3"},
+		{Host: "10.0.0.4", Error: errors.New("context deadline exceeded")},
+	}
+}
+
+func hosts(servers []models.ServerInfo) []string {
+	out := make([]string, 0, len(servers))
+	for _, s := range servers {
+		out = append(out, s.Host)
+	}
+	return out
+}
+
+func TestStore_Indexes(t *testing.T) {
+	s := New()
+	require.NoError(t, s.Replace(testResults(), models.CollectionStats{TotalServers: 4, FailedCount: 1}))
+
+	assert.Equal(t, []string{"10.0.0.1", "10.0.0.2", "10.0.0.3", "10.0.0.4"}, hosts(s.Servers()))
+	assert.Equal(t, []string{"10.0.0.1", "10.0.0.2"}, hosts(s.ByModel("poweredge r750")))
+	assert.Equal(t, []string{"10.0.0.2"}, hosts(s.ByHealth("critical")))
+	assert.Equal(t, []string{"10.0.0.1", "10.0.0.3"}, hosts(s.ByHealth(models.HealthOK)))
+	assert.Equal(t, []string{"10.0.0.4"}, hosts(s.ByHealth(HealthFailed)))
+	assert.Empty(t, s.ByHealth("Warning"))
+
+	srv, ok := s.ByServiceTag("tag3")
+	require.True(t, ok)
+	assert.Equal(t, "10.0.0.3", srv.Host)
+	_, ok = s.ByServiceTag("TAG9")
+	assert.False(t, ok)
+
+	failed, ok := s.Get("10.0.0.4")
+	require.True(t, ok)
+	assert.Error(t, failed.Error)
+
+	stats, updated := s.Stats()
+	assert.Equal(t, 4, stats.TotalServers)
+	assert.False(t, updated.IsZero())
+}
+
+func TestStore_CopyOnReplace(t *testing.T) {
+	s := New()
+	results := testResults()
+	require.NoError(t, s.Replace(results, models.CollectionStats{}))
+	results[0].Drives[0].Name = "changed by the caller"
+
+	srv, _ := s.Get("10.0.0.2")
+	srv.Model = "changed by a reader"
+
+	again, _ := s.Get("10.0.0.2")
+	assert.Equal(t, "Disk 0", again.Drives[0].Name)
+	assert.Equal(t, "PowerEdge R750", again.Model)
+}
+
+func TestStore_ConcurrentUse(t *testing.T) {
+	s := New()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			s.Replace(testResults(), models.CollectionStats{})
+		}()
+		go func() {
+			defer wg.Done()
+			for _, srv := range s.ByModel("PowerEdge R750") {
+				srv.Drives = nil
+			}
+			s.Stats()
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 4, s.Len())
+}
+
+func writeResults(t *testing.T, path string, results []models.ServerInfo, modTime time.Time) {
+	t.Helper()
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	require.NoError(t, output.NewJSONFormatter(false).Format(f, results, models.CollectionStats{TotalServers: len(results)}))
+	require.NoError(t, f.Close())
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+}
+
+func TestStore_Refresh(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "last-scan.json")
+	s := New()
+
+	read, err := s.Refresh(path)
+	require.NoError(t, err)
+	assert.False(t, read, "a missing file leaves the store empty")
+
+	first := time.Now().Add(-time.Hour)
+	writeResults(t, path, testResults()[:1], first)
+	read, err = s.Refresh(path)
+	require.NoError(t, err)
+	assert.True(t, read)
+	assert.Equal(t, 1, s.Len())
+
+	read, err = s.Refresh(path)
+	require.NoError(t, err)
+	assert.False(t, read, "an unchanged file is not read again")
+
+	writeResults(t, path, testResults(), first.Add(time.Minute))
+	read, err = s.Refresh(path)
+	require.NoError(t, err)
+	assert.True(t, read)
+	assert.Equal(t, 4, s.Len())
+}
+
+func TestHandler(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "last-scan.json")
+	writeResults(t, path, testResults(), time.Now())
+	h := Handler(New(), path)
+
+	get := func(target string, v interface{}) int {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		if v != nil && rec.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), v), rec.Body.String())
+		}
+		return rec.Code
+	}
+
+	var servers []models.ServerInfo
+	require.Equal(t, http.StatusOK, get("/servers", &servers))
+	assert.Len(t, servers, 4)
+	require.Equal(t, http.StatusOK, get("/servers?model=PowerEdge+R640", &servers))
+	assert.Equal(t, []string{"10.0.0.3"}, hosts(servers))
+	require.Equal(t, http.StatusOK, get("/servers?health=Failed", &servers))
+	assert.Equal(t, []string{"10.0.0.4"}, hosts(servers))
+	require.Equal(t, http.StatusOK, get("/servers?service_tag=TAG9", &servers))
+	assert.Empty(t, servers)
+
+	var srv models.ServerInfo
+	require.Equal(t, http.StatusOK, get("/servers/10.0.0.2", &srv))
+	assert.Equal(t, "TAG2", srv.ServiceTag)
+	assert.Equal(t, http.StatusNotFound, get("/servers/10.0.0.9", nil))
+
+	var stats statsResponse
+	require.Equal(t, http.StatusOK, get("/stats", &stats))
+	assert.Equal(t, 4, stats.Servers)
+	assert.Equal(t, 4, stats.Stats.TotalServers)
+	assert.NotNil(t, stats.UpdatedAt)
+}
@@ -0,0 +1,149 @@
+package leader
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// httpTimeout limits each request to the lock service.
+const httpTimeout = 10 * time.Second
+
+// leaseRequest is the body of a PUT to the lock service.
+type leaseRequest struct {
+	Holder     string `json:"holder"`
+	TTLSeconds int    `json:"ttl_seconds"`
+}
+
+// HTTPLease keeps the lease in an HTTP lock service:
+//
+//	PUT    <url>            {"holder": "...", "ttl_seconds": 7200}
+//	                        200 granted or renewed, 409 held by another holder
+//	DELETE <url>?holder=... 204 released (or not held by holder)
+//
+// Both responses of a PUT carry the current Lease as JSON. Handler serves
+// this protocol.
+type HTTPLease struct {
+	url      string
+	identity string
+	ttl      time.Duration
+	client   *http.Client
+}
+
+// NewHTTPLease returns a lease kept by the lock service at url.
+func NewHTTPLease(url, identity string, ttl time.Duration) *HTTPLease {
+	return &HTTPLease{url: url, identity: identity, ttl: ttl, client: &http.Client{Timeout: httpTimeout}}
+}
+
+// Acquire implements Elector.
+func (h *HTTPLease) Acquire(ctx context.Context) (bool, error) {
+	body, err := json.Marshal(leaseRequest{Holder: h.identity, TTLSeconds: int(h.ttl.Seconds())})
+	if err != nil {
+		return false, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, h.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("lock service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusConflict:
+		return false, nil
+	default:
+		return false, fmt.Errorf("lock service: unexpected status %s", resp.Status)
+	}
+}
+
+// Release implements Elector.
+func (h *HTTPLease) Release(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete,
+		h.url+"?"+url.Values{"holder": {h.identity}}.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("lock service: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("lock service: unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// Handler is an in-memory lock service for HTTPLease. Each URL path is a
+// separate lock, so one service can serve several deployments. Leases are
+// lost when the service restarts, which at worst lets two replicas scan in
+// the same interval.
+type Handler struct {
+	mu     sync.Mutex
+	leases map[string]Lease
+	now    func() time.Time
+}
+
+// NewHandler returns an empty lock service.
+func NewHandler() *Handler {
+	return &Handler{leases: map[string]Lease{}, now: time.Now}
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := h.now()
+	cur := h.leases[r.URL.Path]
+
+	switch r.Method {
+	case http.MethodGet:
+		writeLease(w, http.StatusOK, cur)
+
+	case http.MethodPut:
+		var req leaseRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Holder == "" || req.TTLSeconds <= 0 {
+			http.Error(w, "expected {\"holder\": ..., \"ttl_seconds\": ...}", http.StatusBadRequest)
+			return
+		}
+		if cur.valid(now) && cur.Holder != req.Holder {
+			writeLease(w, http.StatusConflict, cur)
+			return
+		}
+		lease := Lease{Holder: req.Holder, Acquired: cur.Acquired, Expires: now.Add(time.Duration(req.TTLSeconds) * time.Second)}
+		if cur.Holder != req.Holder || !cur.valid(now) {
+			lease.Acquired = now
+		}
+		h.leases[r.URL.Path] = lease
+		writeLease(w, http.StatusOK, lease)
+
+	case http.MethodDelete:
+		if cur.Holder == r.URL.Query().Get("holder") {
+			delete(h.leases, r.URL.Path)
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		w.Header().Set("Allow", "GET, PUT, DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func writeLease(w http.ResponseWriter, status int, l Lease) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(l)
+}
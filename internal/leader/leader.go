@@ -0,0 +1,155 @@
+// Package leader implements lease-based leader election, so that only one of
+// several replicas (e.g. pods of a Kubernetes Deployment or runs of a
+// CronJob) scans per interval.
+//
+// A lease has a holder and an expiry. The holder renews it before each scan
+// cycle; other replicas skip their cycles until the lease expires. The lease
+// is kept in a file on shared storage (FileLease) or by an HTTP lock service
+// (HTTPLease, served by Handler).
+package leader
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/config"
+)
+
+// Elector grants a lease to one holder at a time.
+type Elector interface {
+	// Acquire takes or renews the lease. It returns false if another
+	// holder has a lease that has not expired.
+	Acquire(ctx context.Context) (bool, error)
+
+	// Release gives up the lease if it is held, so another replica can
+	// take over without waiting for it to expire.
+	Release(ctx context.Context) error
+}
+
+// Lease is the state of a lease.
+type Lease struct {
+	Holder   string    `json:"holder"`
+	Acquired time.Time `json:"acquired"`
+	Expires  time.Time `json:"expires"`
+}
+
+// valid reports whether the lease is held at now.
+func (l Lease) valid(now time.Time) bool {
+	return l.Holder != "" && now.Before(l.Expires)
+}
+
+// New returns the elector configured in cfg with the given lease TTL, or nil
+// if leader election is disabled.
+func New(cfg config.LeaderElectionConfig, ttl time.Duration) Elector {
+	switch {
+	case cfg.LockFile != "":
+		return NewFileLease(cfg.LockFile, cfg.GetIdentity(), ttl)
+	case cfg.LockURL != "":
+		return NewHTTPLease(cfg.LockURL, cfg.GetIdentity(), ttl)
+	}
+	return nil
+}
+
+// FileLease keeps the lease as a JSON file, e.g. on a volume shared by all
+// replicas. Updates are atomic renames; after writing, the file is read back
+// to detect a replica that took the lease at the same moment. This is
+// sufficient for replicas that check the lease minutes apart, but it is not
+// a strict mutual exclusion on every network file system.
+type FileLease struct {
+	path     string
+	identity string
+	ttl      time.Duration
+	now      func() time.Time
+}
+
+// NewFileLease returns a lease kept in the file at path.
+func NewFileLease(path, identity string, ttl time.Duration) *FileLease {
+	return &FileLease{path: path, identity: identity, ttl: ttl, now: time.Now}
+}
+
+// Acquire implements Elector.
+func (f *FileLease) Acquire(ctx context.Context) (bool, error) {
+	cur, err := f.read()
+	if err != nil {
+		return false, err
+	}
+	now := f.now()
+	if cur.valid(now) && cur.Holder != f.identity {
+		return false, nil
+	}
+
+	lease := Lease{Holder: f.identity, Acquired: cur.Acquired, Expires: now.Add(f.ttl)}
+	if cur.Holder != f.identity || !cur.valid(now) {
+		lease.Acquired = now
+	}
+	if err := f.write(lease); err != nil {
+		return false, err
+	}
+
+	cur, err = f.read()
+	if err != nil {
+		return false, err
+	}
+	return cur.Holder == f.identity, nil
+}
+
+// Release implements Elector.
+func (f *FileLease) Release(ctx context.Context) error {
+	cur, err := f.read()
+	if err != nil || cur.Holder != f.identity {
+		return err
+	}
+	if err := os.Remove(f.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to release lease: %w", err)
+	}
+	return nil
+}
+
+// read returns the current lease. A missing or unreadable file is an
+// expired lease, so a corrupted file cannot block all replicas.
+func (f *FileLease) read() (Lease, error) {
+	data, err := os.ReadFile(f.path)
+	if os.IsNotExist(err) {
+		return Lease{}, nil
+	}
+	if err != nil {
+		return Lease{}, fmt.Errorf("failed to read lease: %w", err)
+	}
+	var l Lease
+	if json.Unmarshal(data, &l) != nil {
+		return Lease{}, nil
+	}
+	return l, nil
+}
+
+func (f *FileLease) write(l Lease) error {
+	data, err := json.Marshal(l)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(f.path)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return fmt.Errorf("failed to create lease directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(f.path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write lease: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write lease: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write lease: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), f.path); err != nil {
+		return fmt.Errorf("failed to write lease: %w", err)
+	}
+	return nil
+}
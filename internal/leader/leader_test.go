@@ -0,0 +1,117 @@
+package leader
+
+import (
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/config"
+)
+
+func TestFileLease(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "leases", "inventory.lock")
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	a := NewFileLease(path, "pod-a", time.Hour)
+	b := NewFileLease(path, "pod-b", time.Hour)
+	a.now, b.now = clock, clock
+
+	ok, err := a.Acquire(ctx)
+	require.NoError(t, err)
+	assert.True(t, ok, "free lease is granted")
+
+	ok, err = b.Acquire(ctx)
+	require.NoError(t, err)
+	assert.False(t, ok, "lease held by pod-a")
+
+	now = now.Add(30 * time.Minute)
+	ok, err = a.Acquire(ctx)
+	require.NoError(t, err)
+	assert.True(t, ok, "holder renews")
+
+	now = now.Add(59 * time.Minute)
+	ok, err = b.Acquire(ctx)
+	require.NoError(t, err)
+	assert.False(t, ok, "renewed lease has not expired")
+
+	now = now.Add(2 * time.Minute)
+	ok, err = b.Acquire(ctx)
+	require.NoError(t, err)
+	assert.True(t, ok, "expired lease is taken over")
+
+	require.NoError(t, a.Release(ctx), "releasing a lease held by another is a no-op")
+	_, err = os.Stat(path)
+	require.NoError(t, err)
+
+	require.NoError(t, b.Release(ctx))
+	ok, err = a.Acquire(ctx)
+	require.NoError(t, err)
+	assert.True(t, ok, "released lease is free")
+}
+
+func TestFileLease_CorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "inventory.lock")
+	require.NoError(t, os.WriteFile(path, []byte("garbage"), 0o600))
+
+	ok, err := NewFileLease(path, "pod-a", time.Hour).Acquire(context.Background())
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestHTTPLease(t *testing.T) {
+	ctx := context.Background()
+	handler := NewHandler()
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	handler.now = func() time.Time { return now }
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	a := NewHTTPLease(server.URL+"/locks/inventory", "pod-a", time.Hour)
+	b := NewHTTPLease(server.URL+"/locks/inventory", "pod-b", time.Hour)
+	other := NewHTTPLease(server.URL+"/locks/other", "pod-b", time.Hour)
+
+	ok, err := a.Acquire(ctx)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = b.Acquire(ctx)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	ok, err = other.Acquire(ctx)
+	require.NoError(t, err)
+	assert.True(t, ok, "locks are separate per path")
+
+	now = now.Add(2 * time.Hour)
+	ok, err = b.Acquire(ctx)
+	require.NoError(t, err)
+	assert.True(t, ok, "expired lease is taken over")
+
+	require.NoError(t, b.Release(ctx))
+	ok, err = a.Acquire(ctx)
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestHTTPLease_ServiceDown(t *testing.T) {
+	server := httptest.NewServer(NewHandler())
+	server.Close()
+
+	ok, err := NewHTTPLease(server.URL, "pod-a", time.Hour).Acquire(context.Background())
+	assert.Error(t, err)
+	assert.False(t, ok)
+}
+
+func TestNew(t *testing.T) {
+	assert.Nil(t, New(config.LeaderElectionConfig{}, time.Hour))
+	assert.IsType(t, &FileLease{}, New(config.LeaderElectionConfig{LockFile: "/tmp/x.lock"}, time.Hour))
+	assert.IsType(t, &HTTPLease{}, New(config.LeaderElectionConfig{LockURL: "http://lock:8080/x"}, time.Hour))
+}
@@ -6,18 +6,23 @@ import (
 	"strings"
 	"time"
 
-	"idrac-inventory/internal/models"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+	"github.com/braunma/idrac-netbox-importer/pkg/units"
 )
 
 // AggregatedConsoleFormatter prints an aggregated hardware inventory to the terminal.
 // Servers are first grouped by model, then by hardware configuration within each model.
 type AggregatedConsoleFormatter struct {
 	NoColor bool
+	Units   units.Format
+
+	// Style highlights headings and failed scans (plain if zero).
+	Style Style
 }
 
 // NewAggregatedConsoleFormatter creates a new AggregatedConsoleFormatter.
 func NewAggregatedConsoleFormatter(noColor bool) *AggregatedConsoleFormatter {
-	return &AggregatedConsoleFormatter{NoColor: noColor}
+	return &AggregatedConsoleFormatter{NoColor: noColor, Units: units.Default(), Style: NewStyle(noColor)}
 }
 
 // FormatAggregated writes the aggregated inventory to w.
@@ -36,18 +41,44 @@ func (f *AggregatedConsoleFormatter) FormatAggregated(w io.Writer, inv models.Ag
 		inv.TotalServers, inv.SuccessfulCount, inv.FailedCount,
 		len(inv.ModelGroups), inv.TotalConfigGroups())
 	if inv.Stats.TotalDuration > 0 {
-		fmt.Fprintf(w, "  Scan time: %s total  |  avg %s/server\n",
-			inv.Stats.TotalDuration.Round(time.Millisecond),
-			inv.Stats.AverageDuration.Round(time.Millisecond))
+		perServer := inv.Stats.PercentilesSummary()
+		if perServer == "" {
+			perServer = "avg " + inv.Stats.AverageDuration.Round(time.Millisecond).String()
+		}
+		fmt.Fprintf(w, "  Scan time: %s total  |  per server: %s\n",
+			inv.Stats.TotalDuration.Round(time.Millisecond), perServer)
+	}
+	for _, r := range inv.Stats.TopFailureReasons(topFailureReasons) {
+		fmt.Fprintf(w, "  Failed (%s): %d  e.g. %s\n", r.Category, r.Count, r.ExampleHost)
+	}
+	if fl := inv.Stats.Fleet; fl != nil {
+		fmt.Fprintf(w, "  Fleet: %d cores  |  %s RAM  |  %s storage  |  %d GPUs\n",
+			fl.CPUCores, f.Units.Gigabytes(fl.MemoryGiB), f.Units.Terabytes(fl.StorageTB), fl.GPUs)
+		if storage := fleetStorage(*fl, f.Units); storage != "" {
+			fmt.Fprintf(w, "  Storage by media: %s\n", storage)
+		}
+		fmt.Fprintf(w, "  Generations: %s\n", fleetGenerations(*fl))
+	}
+	if rows := ageRows(inv.Ages); len(rows) > 0 {
+		fmt.Fprintf(w, "  Server age:\n")
+		for _, row := range rows {
+			fmt.Fprintf(w, "    %-12s %5d  %s\n", row.label, row.count, row.bar)
+		}
+	}
+	if inv.Firmware != nil {
+		total := models.TotalFirmwareCompliance(inv.Firmware)
+		fmt.Fprintf(w, "  Firmware policy: %d of %d compliant (%.1f%%)\n", total.Compliant, total.Servers, total.Rate())
 	}
 	fmt.Fprintf(w, "\n")
 
 	// Model groups
 	for i, mg := range inv.ModelGroups {
 		fmt.Fprintf(w, "%s\n", thin)
-		fmt.Fprintf(w, "  MODEL %d — %s%d× %s%s\n",
-			i+1,
-			f.bold(), mg.TotalCount, mg.DisplayModel(), f.reset())
+		fmt.Fprintf(w, "  MODEL %d — %s\n",
+			i+1, f.Style.Bold(fmt.Sprintf("%d× %s", mg.TotalCount, mg.DisplayModel())))
+		if mg.Chassis != nil {
+			fmt.Fprintf(w, "  %-15s %s\n", "Chassis:", mg.Chassis)
+		}
 		fmt.Fprintf(w, "%s\n", thin)
 
 		for j, cg := range mg.ConfigGroups {
@@ -55,8 +86,8 @@ func (f *AggregatedConsoleFormatter) FormatAggregated(w io.Writer, inv models.Ag
 
 			// If there is more than one config subgroup, label each one.
 			if len(mg.ConfigGroups) > 1 {
-				fmt.Fprintf(w, "\n  %sConfiguration %d/%d%s  (%d server",
-					f.bold(), j+1, len(mg.ConfigGroups), f.reset(), cg.Count)
+				fmt.Fprintf(w, "\n  %s  (%d server",
+					f.Style.Bold(fmt.Sprintf("Configuration %d/%d", j+1, len(mg.ConfigGroups))), cg.Count)
 				if cg.Count != 1 {
 					fmt.Fprintf(w, "s")
 				}
@@ -86,7 +117,7 @@ func (f *AggregatedConsoleFormatter) FormatAggregated(w io.Writer, inv models.Ag
 			}
 
 			// RAM line
-			ramSpec := fmt.Sprintf("%d GiB", fp.RAMTotalGiB)
+			ramSpec := ramDisplay(fp, f.Units)
 			if fp.RAMType != "" {
 				ramSpec += "  " + fp.RAMType
 				if fp.RAMSpeedMHz > 0 {
@@ -99,9 +130,9 @@ func (f *AggregatedConsoleFormatter) FormatAggregated(w io.Writer, inv models.Ag
 					moduleCount = cg.Servers[0].MemorySlotsUsed
 				}
 				if moduleCount > 0 {
-					ramSpec += fmt.Sprintf("  (%d× %d GiB modules)", moduleCount, fp.RAMModuleSizeGiB)
+					ramSpec += fmt.Sprintf("  (%d× %s modules)", moduleCount, f.Units.Gigabytes(float64(fp.RAMModuleSizeGiB)))
 				} else {
-					ramSpec += fmt.Sprintf("  (%d GiB/module)", fp.RAMModuleSizeGiB)
+					ramSpec += fmt.Sprintf("  (%s/module)", f.Units.Gigabytes(float64(fp.RAMModuleSizeGiB)))
 				}
 			}
 			fmt.Fprintf(w, "  %-15s %s\n", "RAM:", ramSpec)
@@ -119,17 +150,18 @@ func (f *AggregatedConsoleFormatter) FormatAggregated(w io.Writer, inv models.Ag
 					gpuSpec += " " + fp.GPUModel
 				}
 				if fp.GPUMemoryGiB > 0 {
-					gpuSpec += fmt.Sprintf(" (%d GB VRAM each)", fp.GPUMemoryGiB)
+					gpuSpec += fmt.Sprintf(" (%s VRAM each)", f.Units.Gigabytes(float64(fp.GPUMemoryGiB)))
 				}
 				fmt.Fprintf(w, "  %-15s %s\n", "GPUs:", gpuSpec)
 			}
 
-			// Storage
-			storageSpec := fp.StorageSummary
-			if cg.TotalStorageTB > 0 {
-				storageSpec += fmt.Sprintf("  (%.2f TB total)", cg.TotalStorageTB)
+			// Storage (omitted when excluded from the fingerprint)
+			if storageSpec := fp.StorageDisplay(); storageSpec != "" {
+				if cg.TotalStorageTB > 0 {
+					storageSpec += fmt.Sprintf("  (%s total)", f.Units.Terabytes(cg.TotalStorageTB))
+				}
+				fmt.Fprintf(w, "  %-15s %s\n", "Storage:", storageSpec)
 			}
-			fmt.Fprintf(w, "  %-15s %s\n", "Storage:", storageSpec)
 
 			// Server list
 			fmt.Fprintf(w, "\n  Servers (%d):\n", cg.Count)
@@ -164,7 +196,7 @@ func (f *AggregatedConsoleFormatter) FormatAggregated(w io.Writer, inv models.Ag
 			if srv.Error != nil {
 				errMsg = srv.Error.Error()
 			}
-			fmt.Fprintf(w, "  %-20s  %s\n", srv.Host, errMsg)
+			fmt.Fprintf(w, "  %s\n", f.Style.Critical(fmt.Sprintf("%-20s  %s", srv.Host, errMsg)))
 		}
 		fmt.Fprintf(w, "\n")
 	}
@@ -173,16 +205,50 @@ func (f *AggregatedConsoleFormatter) FormatAggregated(w io.Writer, inv models.Ag
 	return nil
 }
 
-func (f *AggregatedConsoleFormatter) bold() string {
-	if f.NoColor {
-		return ""
+// fleetStorage lists the storage of a fleet by media type, e.g.
+// "SSD 41.92 TiB (48 drives), HDD 261.93 TiB (24 drives)".
+func fleetStorage(fl models.FleetSummary, u units.Format) string {
+	parts := make([]string, 0, len(fl.Storage))
+	for _, m := range fl.Storage {
+		parts = append(parts, fmt.Sprintf("%s %s (%d drives)", m.MediaType, u.Terabytes(m.CapacityTB), m.Drives))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// fleetGenerations lists the servers of a fleet by generation, e.g.
+// "16G: 4, 15G: 10, other: 2".
+func fleetGenerations(fl models.FleetSummary) string {
+	parts := make([]string, 0, len(fl.Generations))
+	for _, g := range fl.Generations {
+		parts = append(parts, fmt.Sprintf("%s: %d", g.Generation, g.Servers))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ageRows returns the rows of an age distribution, with bars scaled to the
+// largest bucket like a duration histogram.
+func ageRows(buckets []models.AgeBucket) []histogramRow {
+	largest := 0
+	for _, b := range buckets {
+		largest = max(largest, b.Servers)
+	}
+	if largest == 0 {
+		return nil
+	}
+	rows := make([]histogramRow, len(buckets))
+	prev := 0
+	for i, b := range buckets {
+		n := (b.Servers*maxHistogramBar + largest - 1) / largest
+		rows[i] = histogramRow{label: b.Label(prev), count: b.Servers, bar: strings.Repeat("█", n)}
+		prev = b.MaxYears
 	}
-	return "\033[1m"
+	return rows
 }
 
-func (f *AggregatedConsoleFormatter) reset() string {
-	if f.NoColor {
-		return ""
+// ramDisplay is fp.RAMDisplay in the units of u.
+func ramDisplay(fp models.HardwareFingerprint, u units.Format) string {
+	if fp.RAMRange != "" {
+		return fp.RAMRange
 	}
-	return "\033[0m"
+	return u.Gigabytes(float64(fp.RAMTotalGiB))
 }
@@ -0,0 +1,186 @@
+package output
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/braunma/idrac-netbox-importer/internal/signing"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+// ChunkIndexFile is the name of the index written by WriteChunked.
+const ChunkIndexFile = "index.json"
+
+// ChunkIndex describes the files written by WriteChunked.
+type ChunkIndex struct {
+	GeneratedAt  time.Time              `json:"generated_at"`
+	TotalServers int                    `json:"total_servers"`
+	ChunkSize    int                    `json:"chunk_size"`
+	Compressed   bool                   `json:"compressed"`
+	Chunks       []ChunkInfo            `json:"chunks"`
+	Stats        models.CollectionStats `json:"stats"`
+}
+
+// ChunkInfo describes a single chunk file.
+type ChunkInfo struct {
+	File      string `json:"file"`
+	Servers   int    `json:"servers"`
+	FirstHost string `json:"first_host"`
+	LastHost  string `json:"last_host"`
+}
+
+// gzipMagic are the first bytes of every gzip stream.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// NewGzipWriter wraps w for -compress output. The caller must Close the
+// returned writer to flush the gzip trailer; it does not close w.
+func NewGzipWriter(w io.Writer) *gzip.Writer {
+	return gzip.NewWriter(w)
+}
+
+// maybeGunzip returns a reader that decompresses r if it starts with the gzip magic.
+func maybeGunzip(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(len(gzipMagic))
+	if err != nil || magic[0] != gzipMagic[0] || magic[1] != gzipMagic[1] {
+		// Too short or not gzip; let the JSON decoder report the error
+		return br, nil
+	}
+	zr, err := gzip.NewReader(br)
+	if err != nil {
+		return nil, fmt.Errorf("invalid gzip data: %w", err)
+	}
+	return zr, nil
+}
+
+// WriteChunked writes the results as JSON files of at most chunkSize servers
+// each into dir (servers-0001.json, ...) and an index.json listing them. Each
+// chunk is a complete JSONFormatter document with the stats of its servers,
+// so it can be read on its own. With compress, chunks are gzipped (.json.gz).
+// With a signer, every chunk and the index get a .sig file.
+func WriteChunked(dir string, results []models.ServerInfo, stats models.CollectionStats, chunkSize int, compress bool, signer *signing.Signer) (*ChunkIndex, error) {
+	if chunkSize <= 0 {
+		return nil, fmt.Errorf("chunk size must be positive")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	index := &ChunkIndex{
+		GeneratedAt:  time.Now(),
+		TotalServers: len(results),
+		ChunkSize:    chunkSize,
+		Compressed:   compress,
+		Stats:        stats,
+	}
+
+	for start := 0; start < len(results); start += chunkSize {
+		end := start + chunkSize
+		if end > len(results) {
+			end = len(results)
+		}
+		chunk := results[start:end]
+
+		name := fmt.Sprintf("servers-%04d.json", len(index.Chunks)+1)
+		if compress {
+			name += ".gz"
+		}
+		if err := writeChunk(filepath.Join(dir, name), chunk, compress, signer); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", name, err)
+		}
+
+		index.Chunks = append(index.Chunks, ChunkInfo{
+			File:      name,
+			Servers:   len(chunk),
+			FirstHost: chunk[0].Host,
+			LastHost:  chunk[len(chunk)-1].Host,
+		})
+	}
+
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := signer.WriteFile(filepath.Join(dir, ChunkIndexFile), append(data, '\n'), 0o644); err != nil {
+		return nil, fmt.Errorf("failed to write index: %w", err)
+	}
+	return index, nil
+}
+
+// writeChunk writes one chunk file, optionally gzip-compressed and signed.
+func writeChunk(path string, servers []models.ServerInfo, compress bool, signer *signing.Signer) error {
+	var buf bytes.Buffer
+	var w io.Writer = &buf
+	var zw *gzip.Writer
+	if compress {
+		zw = NewGzipWriter(&buf)
+		w = zw
+	}
+
+	if err := NewJSONFormatter(!compress).Format(w, servers, chunkStats(servers)); err != nil {
+		return err
+	}
+	if zw != nil {
+		if err := zw.Close(); err != nil {
+			return err
+		}
+	}
+	return signer.WriteFile(path, buf.Bytes(), 0o644)
+}
+
+// chunkStats returns the host counts of a single chunk. Timing and failure
+// breakdowns are only kept for the whole run, in the index.
+func chunkStats(servers []models.ServerInfo) models.CollectionStats {
+	stats := models.CollectionStats{TotalServers: len(servers)}
+	for _, s := range servers {
+		if s.IsValid() {
+			stats.SuccessfulCount++
+			if s.IsPartial() {
+				stats.PartialCount++
+			}
+		} else {
+			stats.FailedCount++
+		}
+	}
+	return stats
+}
+
+// ReadJSONFile reads results from a file written by -output json (optionally
+// gzip-compressed) or from the index.json of a chunked output directory, in
+// which case all chunks are loaded and the index stats are returned.
+func ReadJSONFile(path string) ([]models.ServerInfo, models.CollectionStats, error) {
+	if filepath.Base(path) != ChunkIndexFile {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, models.CollectionStats{}, err
+		}
+		defer file.Close()
+		return ReadJSON(file)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, models.CollectionStats{}, err
+	}
+	var index ChunkIndex
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, models.CollectionStats{}, fmt.Errorf("invalid chunk index: %w", err)
+	}
+
+	results := make([]models.ServerInfo, 0, index.TotalServers)
+	for _, c := range index.Chunks {
+		servers, _, err := ReadJSONFile(filepath.Join(filepath.Dir(path), c.File))
+		if err != nil {
+			return nil, models.CollectionStats{}, fmt.Errorf("%s: %w", c.File, err)
+		}
+		results = append(results, servers...)
+	}
+	return results, index.Stats, nil
+}
@@ -0,0 +1,37 @@
+package output
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/braunma/idrac-netbox-importer/internal/signing"
+	"github.com/braunma/idrac-netbox-importer/internal/testutil"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+func TestWriteChunked_Signed(t *testing.T) {
+	signer := testutil.NewSigner(t)
+	servers := []models.ServerInfo{{Host: "10.0.0.1"}, {Host: "10.0.0.2"}, {Host: "10.0.0.3"}}
+	dir := t.TempDir()
+
+	index, err := WriteChunked(dir, servers, models.CollectionStats{TotalServers: 3}, 2, true, signer)
+	require.NoError(t, err)
+	require.Len(t, index.Chunks, 2)
+
+	files := []string{ChunkIndexFile}
+	for _, c := range index.Chunks {
+		files = append(files, c.File)
+	}
+	for _, name := range files {
+		sig, err := signing.VerifyFile(filepath.Join(dir, name), signer.PublicKey())
+		require.NoError(t, err, name)
+		assert.Equal(t, name, sig.File)
+	}
+
+	got, _, err := ReadJSONFile(filepath.Join(dir, ChunkIndexFile))
+	require.NoError(t, err)
+	assert.Len(t, got, 3)
+}
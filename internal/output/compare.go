@@ -0,0 +1,110 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+	"github.com/braunma/idrac-netbox-importer/pkg/units"
+)
+
+// ComparisonReports builds the reports of a fleet comparison: the totals of
+// both result sets, the servers added and removed, and the hardware changes
+// per host. Sizes of the totals are shown in the units of u.
+func ComparisonReports(c models.FleetComparison, u units.Format) []Report {
+	totals := Report{
+		Title:   "Fleet Totals",
+		Headers: []string{"Metric", "Old", "New", "Change"},
+		Data:    c,
+	}
+	count := func(v float64) string { return fmt.Sprintf("%d", int(v)) }
+	for _, t := range []struct {
+		metric   string
+		old, new float64
+		format   func(float64) string
+	}{
+		{"Servers", float64(c.Old.Servers), float64(c.New.Servers), count},
+		{"CPU sockets", float64(c.Old.CPUSockets), float64(c.New.CPUSockets), count},
+		{"CPU cores", float64(c.Old.CPUCores), float64(c.New.CPUCores), count},
+		{"Memory", c.Old.MemoryGiB, c.New.MemoryGiB, u.Gigabytes},
+		{"Drives", float64(c.Old.Drives), float64(c.New.Drives), count},
+		{"Storage", c.Old.StorageTB, c.New.StorageTB, u.Terabytes},
+		{"GPUs", float64(c.Old.GPUs), float64(c.New.GPUs), count},
+	} {
+		totals.Rows = append(totals.Rows, []string{t.metric, t.format(t.old), t.format(t.new), signed(t.new-t.old, t.format)})
+	}
+	totals.Rows = append(totals.Rows,
+		[]string{"Servers changed", "", fmt.Sprintf("%d", len(c.Changed)), ""},
+		[]string{"Servers unchanged", "", fmt.Sprintf("%d", c.Unchanged), ""},
+		[]string{"Servers not compared (failed scan)", "", fmt.Sprintf("%d", len(c.Unscanned)), ""},
+	)
+
+	fleet := Report{
+		Title:   "Servers Added and Removed",
+		Headers: []string{"Change", "Host", "Service Tag", "Model"},
+	}
+	for _, group := range serverChanges(c) {
+		for _, s := range group.refs {
+			fleet.Rows = append(fleet.Rows, []string{group.change, s.Host, dashIfEmpty(s.ServiceTag), dashIfEmpty(s.Model)})
+		}
+	}
+
+	changes := Report{
+		Title:   "Hardware Changes",
+		Headers: []string{"Host", "Service Tag", "Field", "Old", "New"},
+	}
+	for _, d := range c.Changed {
+		for _, ch := range d.Changes {
+			changes.Rows = append(changes.Rows, []string{d.Host, dashIfEmpty(d.ServiceTag), ch.Field, dashIfEmpty(ch.Old), dashIfEmpty(ch.New)})
+		}
+	}
+
+	return []Report{totals, fleet, changes}
+}
+
+// serverChange is a group of servers added to, removed from or not compared
+// in a fleet comparison.
+type serverChange struct {
+	change string
+	refs   []models.ServerRef
+}
+
+func serverChanges(c models.FleetComparison) []serverChange {
+	return []serverChange{{"added", c.Added}, {"removed", c.Removed}, {"not compared", c.Unscanned}}
+}
+
+// WriteComparison renders a fleet comparison in the given format. JSON is
+// the comparison itself; CSV lists the added, removed and changed servers in
+// one table; the other formats write the reports of ComparisonReports.
+func WriteComparison(w io.Writer, c models.FleetComparison, u units.Format, format string) error {
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(c)
+	case "csv":
+		flat := Report{Headers: []string{"Change", "Host", "Service Tag", "Model", "Field", "Old", "New"}}
+		for _, group := range serverChanges(c) {
+			for _, s := range group.refs {
+				flat.Rows = append(flat.Rows, []string{group.change, s.Host, s.ServiceTag, s.Model, "", "", ""})
+			}
+		}
+		for _, d := range c.Changed {
+			for _, ch := range d.Changes {
+				flat.Rows = append(flat.Rows, []string{"changed", d.Host, d.ServiceTag, d.Model, ch.Field, ch.Old, ch.New})
+			}
+		}
+		return writeReportCSV(w, flat)
+	}
+
+	for _, r := range ComparisonReports(c, u) {
+		if len(r.Rows) == 0 {
+			continue
+		}
+		if err := WriteReport(w, r, format); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -2,6 +2,7 @@
 package output
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -9,7 +10,8 @@ import (
 	"text/tabwriter"
 	"time"
 
-	"idrac-inventory/internal/models"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+	"github.com/braunma/idrac-netbox-importer/pkg/units"
 )
 
 // Formatter defines the interface for output formatters.
@@ -17,25 +19,55 @@ type Formatter interface {
 	Format(w io.Writer, results []models.ServerInfo, stats models.CollectionStats) error
 }
 
+// StreamFormatter is implemented by formatters that can write results one at
+// a time as a streaming scan produces them, without buffering the fleet.
+// Begin is called once, WriteServer for every result and End with the final
+// statistics.
+type StreamFormatter interface {
+	Begin(w io.Writer) error
+	WriteServer(w io.Writer, info models.ServerInfo) error
+	End(w io.Writer, stats models.CollectionStats) error
+}
+
 // ConsoleFormatter outputs results in a human-readable console format.
 type ConsoleFormatter struct {
 	Verbose bool
 	NoColor bool
+	Units   units.Format
+
+	// Style highlights failures and health by severity (plain if zero).
+	Style Style
+
+	// Width is the terminal width the layout adapts to (0 if unknown).
+	// Lines that do not fit are truncated unless Wide is set.
+	Width int
+	Wide  bool
 }
 
 // JSONFormatter outputs results as JSON.
 type JSONFormatter struct {
 	Indent bool
+
+	written int // servers written since Begin
 }
 
 // TableFormatter outputs results in a tabular format.
-type TableFormatter struct{}
+type TableFormatter struct {
+	Units units.Format
+
+	// Width is the terminal width (0 if unknown). The model and status
+	// columns are truncated to fit unless Wide is set.
+	Width int
+	Wide  bool
+}
 
 // NewConsoleFormatter creates a new console formatter.
 func NewConsoleFormatter(verbose, noColor bool) *ConsoleFormatter {
 	return &ConsoleFormatter{
 		Verbose: verbose,
 		NoColor: noColor,
+		Units:   units.Default(),
+		Style:   NewStyle(noColor),
 	}
 }
 
@@ -46,7 +78,7 @@ func NewJSONFormatter(indent bool) *JSONFormatter {
 
 // NewTableFormatter creates a new table formatter.
 func NewTableFormatter() *TableFormatter {
-	return &TableFormatter{}
+	return &TableFormatter{Units: units.Default()}
 }
 
 // Format outputs results in console format.
@@ -59,16 +91,56 @@ func (f *ConsoleFormatter) Format(w io.Writer, results []models.ServerInfo, stat
 	return nil
 }
 
+// Begin implements StreamFormatter.
+func (f *ConsoleFormatter) Begin(w io.Writer) error {
+	return nil
+}
+
+// WriteServer implements StreamFormatter.
+func (f *ConsoleFormatter) WriteServer(w io.Writer, info models.ServerInfo) error {
+	f.formatServer(w, info)
+	return nil
+}
+
+// End implements StreamFormatter.
+func (f *ConsoleFormatter) End(w io.Writer, stats models.CollectionStats) error {
+	f.formatSummary(w, stats)
+	return nil
+}
+
 func (f *ConsoleFormatter) formatServer(w io.Writer, info models.ServerInfo) {
 	if info.Error != nil {
-		fmt.Fprintf(w, "\n%s %s - Error: %v\n", f.icon("❌"), info.Host, info.Error)
+		fmt.Fprintf(w, "\n%s\n", f.Style.Critical(f.fit(fmt.Sprintf("%s %s - Error: %v", f.icon("❌"), info.Host, info.Error))))
+		for _, n := range info.Notes {
+			fmt.Fprintf(w, "%s\n", f.fit("   └─ Note: "+n))
+		}
 		return
 	}
 
 	// Header
-	fmt.Fprintf(w, "\n%s\n", strings.Repeat("═", 72))
+	fmt.Fprintf(w, "\n%s\n", f.rule())
 	fmt.Fprintf(w, "%s  %s (%s)\n", f.icon("🖥️"), info.Host, info.Model)
-	fmt.Fprintf(w, "%s\n", strings.Repeat("═", 72))
+	fmt.Fprintf(w, "%s\n", f.rule())
+	if info.Stale {
+		fmt.Fprintf(w, "%s\n", f.Style.Warning(f.fit(fmt.Sprintf("%s STALE: last known good data from %s (current scan failed: %s)",
+			f.icon("⚠️"), info.StaleSince.Format(time.RFC3339), info.StaleError))))
+	}
+	if info.Unchanged && info.FullScanAt != nil {
+		fmt.Fprintf(w, "%s Unchanged: hardware from the full scan at %s (no Lifecycle Controller change)\n",
+			f.icon("ℹ️"), info.FullScanAt.Format(time.RFC3339))
+	}
+	if info.IsPartial() {
+		fmt.Fprintf(w, "%s\n", f.Style.Warning(f.icon("⚠️")+" PARTIAL: some components could not be collected"))
+		for _, e := range info.ComponentErrorList() {
+			fmt.Fprintf(w, "%s\n", f.fit(fmt.Sprintf("   └─ %s", e)))
+		}
+	}
+	if len(info.Notes) > 0 {
+		fmt.Fprintf(w, "\n%s Notes:\n", f.icon("📝"))
+		for _, n := range info.Notes {
+			fmt.Fprintf(w, "%s\n", f.fit("   └─ "+n))
+		}
+	}
 
 	// System Information
 	fmt.Fprintf(w, "\n%s System Information:\n", f.icon("📋"))
@@ -76,8 +148,43 @@ func (f *ConsoleFormatter) formatServer(w io.Writer, info models.ServerInfo) {
 	fmt.Fprintf(w, "   %-14s %s\n", "Service Tag:", f.valueOrNA(info.ServiceTag))
 	fmt.Fprintf(w, "   %-14s %s\n", "Serial:", f.valueOrNA(info.SerialNumber))
 	fmt.Fprintf(w, "   %-14s %s\n", "BIOS:", f.valueOrNA(info.BiosVersion))
+	fmt.Fprintf(w, "   %-14s %s\n", "iDRAC:", f.valueOrNA(info.IDRACFirmwareVersion))
 	fmt.Fprintf(w, "   %-14s %s\n", "Hostname:", f.valueOrNA(info.HostName))
 	fmt.Fprintf(w, "   %-14s %s\n", "Power State:", f.formatPowerState(info.PowerState))
+	fmt.Fprintf(w, "   %-14s %s\n", "iDRAC License:", f.valueOrNA(info.LicenseLevel))
+	if c := info.Clock; c != nil && (f.Verbose || c.Drifted()) {
+		line := c.String()
+		if c.Drifted() {
+			line = f.icon("⚠️") + " " + line
+		}
+		fmt.Fprintf(w, "   %-14s %s\n", "iDRAC Clock:", line)
+	}
+	if n := info.IDRACNetwork; f.Verbose && n != nil {
+		mode := "static"
+		if n.DHCP {
+			mode = "DHCP"
+		}
+		line := fmt.Sprintf("%s (%s)", f.valueOrNA(n.IPv4Address), mode)
+		if n.VLANEnabled {
+			line += fmt.Sprintf(", VLAN %d", n.VLANID)
+		}
+		if n.NICSelection != "" {
+			line += ", " + n.NICSelection
+		}
+		fmt.Fprintf(w, "   %-14s %s\n", "iDRAC Network:", line)
+		if name := n.DNSName(); name != "" {
+			fmt.Fprintf(w, "   %-14s %s\n", "iDRAC DNS:", name)
+		}
+	}
+	if a := info.Accounts; a != nil {
+		risks := a.Risks()
+		if f.Verbose {
+			fmt.Fprintf(w, "   %-14s %d enabled, %d administrators\n", "iDRAC Users:", len(a.Enabled()), len(a.Administrators()))
+		}
+		for _, risk := range risks {
+			fmt.Fprintf(w, "   %-14s %s %s\n", "iDRAC Users:", f.icon("⚠️"), risk)
+		}
+	}
 
 	// CPUs
 	fmt.Fprintf(w, "\n%s CPUs: %d installed\n", f.icon("🔲"), info.CPUCount)
@@ -96,14 +203,15 @@ func (f *ConsoleFormatter) formatServer(w io.Writer, info models.ServerInfo) {
 	}
 
 	// Memory
-	memoryLine := fmt.Sprintf("%.0f GiB total", info.TotalMemoryGiB)
+	u := f.Units
+	memoryLine := u.Gigabytes(info.TotalMemoryGiB) + " total"
 	for _, mem := range info.Memory {
 		if mem.IsPopulated() {
-			moduleGiB := int(mem.CapacityGB() + 0.5)
+			moduleSize := u.Gigabytes(mem.CapacityGB())
 			if mem.Type != "" {
-				memoryLine += fmt.Sprintf("  (%d× %d GiB %s)", info.MemorySlotsUsed, moduleGiB, mem.Type)
+				memoryLine += fmt.Sprintf("  (%d× %s %s)", info.MemorySlotsUsed, moduleSize, mem.Type)
 			} else {
-				memoryLine += fmt.Sprintf("  (%d× %d GiB)", info.MemorySlotsUsed, moduleGiB)
+				memoryLine += fmt.Sprintf("  (%d× %s)", info.MemorySlotsUsed, moduleSize)
 			}
 			break
 		}
@@ -115,19 +223,19 @@ func (f *ConsoleFormatter) formatServer(w io.Writer, info models.ServerInfo) {
 	if f.Verbose {
 		for _, mem := range info.Memory {
 			if mem.IsPopulated() {
-				fmt.Fprintf(w, "   └─ %s: %.0f GiB %s @ %d MHz\n",
-					mem.Slot, mem.CapacityGB(), mem.Type, mem.SpeedMHz)
+				fmt.Fprintf(w, "   └─ %s: %s %s @ %d MHz\n",
+					mem.Slot, u.Gigabytes(mem.CapacityGB()), mem.Type, mem.SpeedMHz)
 				fmt.Fprintf(w, "      %s %s (S/N: %s)\n",
 					mem.Manufacturer, mem.PartNumber, mem.SerialNumber)
 			} else {
-				fmt.Fprintf(w, "   └─ %s: [empty]\n", mem.Slot)
+				fmt.Fprintf(w, "   └─ %s\n", f.Style.Dim(mem.Slot+": [empty]"))
 			}
 		}
 	}
 
 	// Storage
-	fmt.Fprintf(w, "\n%s Storage: %d drive(s), %.2f TB total\n",
-		f.icon("💿"), info.DriveCount, info.TotalStorageTB)
+	fmt.Fprintf(w, "\n%s Storage: %d drive(s), %s total\n",
+		f.icon("💿"), info.DriveCount, u.Terabytes(info.TotalStorageTB))
 
 	if f.Verbose {
 		for _, drive := range info.Drives {
@@ -135,10 +243,22 @@ func (f *ConsoleFormatter) formatServer(w io.Writer, info models.ServerInfo) {
 			if drive.LifeLeftPct > 0 {
 				lifeInfo = fmt.Sprintf(" [%.0f%% life]", drive.LifeLeftPct)
 			}
-			fmt.Fprintf(w, "   └─ %s: %.0f GB %s (%s)\n",
-				drive.Name, drive.CapacityGB, drive.MediaType, drive.Protocol)
+			fmt.Fprintf(w, "   └─ %s: %s %s (%s)\n",
+				drive.Name, u.Gigabytes(drive.CapacityGB), drive.MediaType, drive.Protocol)
 			fmt.Fprintf(w, "      %s (S/N: %s) %s %s\n",
 				drive.Model, drive.SerialNumber, f.formatHealth(drive.Health), lifeInfo)
+			if n := drive.NVMe; n != nil {
+				fmt.Fprintf(w, "      %s\n", f.nvmeLine(*n))
+			}
+		}
+		for _, enc := range info.Enclosures {
+			fmt.Fprintf(w, "   └─ Enclosure: %s %s\n", enc, f.formatHealth(enc.Health))
+		}
+		for _, ctrl := range info.StorageControllers {
+			fmt.Fprintf(w, "   └─ Controller: %s %s\n", ctrl, f.formatHealth(ctrl.Health))
+			if b := ctrl.Battery; b != nil {
+				fmt.Fprintf(w, "      Battery: %s %s\n", f.valueOrNA(b.State), f.formatHealth(b.Health))
+			}
 		}
 	} else {
 		// Group by media type
@@ -154,10 +274,24 @@ func (f *ConsoleFormatter) formatServer(w io.Writer, info models.ServerInfo) {
 			}
 		}
 		if ssdCount > 0 {
-			fmt.Fprintf(w, "   └─ %d× SSD (%.0f GB total)\n", ssdCount, ssdCapacity)
+			fmt.Fprintf(w, "   └─ %d× SSD (%s total)\n", ssdCount, u.Gigabytes(ssdCapacity))
 		}
 		if hddCount > 0 {
-			fmt.Fprintf(w, "   └─ %d× HDD (%.0f GB total)\n", hddCount, hddCapacity)
+			fmt.Fprintf(w, "   └─ %d× HDD (%s total)\n", hddCount, u.Gigabytes(hddCapacity))
+		}
+		for _, ctrl := range info.StorageControllers {
+			if b := ctrl.Battery; b != nil && b.Health != "" && b.Health != models.HealthOK {
+				fmt.Fprintf(w, "   └─ %s %s battery %s: write-back caching may be disabled\n",
+					f.icon("⚠️"), ctrl.ID, f.valueOrNA(b.State))
+			}
+		}
+	}
+
+	// Drive bay and DIMM slot diagrams
+	if maps := info.SlotMaps(); f.Verbose && len(maps) > 0 {
+		fmt.Fprintf(w, "\n%s Slot Maps (%s):\n", f.icon("🗺️"), slotLegend)
+		for _, m := range maps {
+			writeSlotMap(w, m, "   ", f.Style)
 		}
 	}
 
@@ -173,14 +307,14 @@ func (f *ConsoleFormatter) formatServer(w io.Writer, info models.ServerInfo) {
 					if memType == "" {
 						memType = "VRAM"
 					}
-					fmt.Fprintf(w, "      %.0f GB %s\n", gpu.MemoryGB(), memType)
+					fmt.Fprintf(w, "      %s %s\n", u.Gigabytes(gpu.MemoryGB()), memType)
 				}
 				fmt.Fprintf(w, "      Health: %s\n", f.formatHealth(gpu.Health))
 			}
 		} else {
 			gpu := info.GPUs[0]
 			if gpu.MemoryMiB > 0 {
-				fmt.Fprintf(w, "   └─ %s (%.0f GB VRAM each)\n", gpu.Model, gpu.MemoryGB())
+				fmt.Fprintf(w, "   └─ %s (%s VRAM each)\n", gpu.Model, u.Gigabytes(gpu.MemoryGB()))
 			} else {
 				fmt.Fprintf(w, "   └─ %s\n", gpu.Model)
 			}
@@ -197,22 +331,168 @@ func (f *ConsoleFormatter) formatServer(w io.Writer, info models.ServerInfo) {
 			fmt.Fprintf(w, "   └─ Peak:    %d W\n", info.PowerPeakWatts)
 		}
 	}
+
+	// Golden config compliance
+	if c := info.Compliance; c != nil {
+		if c.Compliant() {
+			fmt.Fprintf(w, "\n%s Golden Config: %s (compliant)\n", f.icon("✅"), c.Profile)
+		} else {
+			fmt.Fprintf(w, "\n%s\n", f.Style.Warning(fmt.Sprintf("%s Golden Config: %s (%d violation(s))", f.icon("⚠️"), c.Profile, len(c.Violations))))
+			for _, v := range c.Violations {
+				fmt.Fprintf(w, "%s\n", f.fit(fmt.Sprintf("   └─ %s", v)))
+			}
+		}
+	}
+
+	// Firmware policy compliance
+	if c := info.FirmwareCompliance; c != nil {
+		if c.Compliant() {
+			fmt.Fprintf(w, "\n%s Firmware Policy: %s (compliant)\n", f.icon("✅"), c.Policy)
+		} else {
+			fmt.Fprintf(w, "\n%s\n", f.Style.Warning(fmt.Sprintf("%s Firmware Policy: %s (%d violation(s))", f.icon("⚠️"), c.Policy, len(c.Violations))))
+			for _, v := range c.Violations {
+				fmt.Fprintf(w, "%s\n", f.fit(fmt.Sprintf("   └─ %s", v)))
+			}
+		}
+	}
 }
 
 func (f *ConsoleFormatter) formatSummary(w io.Writer, stats models.CollectionStats) {
-	fmt.Fprintf(w, "\n%s\n", strings.Repeat("═", 72))
+	fmt.Fprintf(w, "\n%s\n", f.rule())
 	fmt.Fprintf(w, "%s Summary\n", f.icon("📊"))
-	fmt.Fprintf(w, "%s\n", strings.Repeat("═", 72))
+	fmt.Fprintf(w, "%s\n", f.rule())
 
 	fmt.Fprintf(w, "   Total Servers:   %d\n", stats.TotalServers)
 	fmt.Fprintf(w, "   %s Successful:    %d\n", f.icon("✅"), stats.SuccessfulCount)
-	fmt.Fprintf(w, "   %s Failed:        %d\n", f.icon("❌"), stats.FailedCount)
+	failed := fmt.Sprintf("%d", stats.FailedCount)
+	if stats.FailedCount > 0 {
+		failed = f.Style.Critical(failed)
+	}
+	fmt.Fprintf(w, "   %s Failed:        %s\n", f.icon("❌"), failed)
 	fmt.Fprintf(w, "   Success Rate:    %.1f%%\n", stats.SuccessRate())
+	if stats.RescannedCount > 0 {
+		fmt.Fprintf(w, "   Rescanned:       %d (%d recovered)\n", stats.RescannedCount, stats.RecoveredCount)
+	}
+	if stats.StaleCount > 0 {
+		fmt.Fprintf(w, "   Stale:           %d (last known good data shown)\n", stats.StaleCount)
+	}
+	if stats.UnchangedCount > 0 {
+		fmt.Fprintf(w, "   Unchanged:       %d (inventory of the last full scan reused)\n", stats.UnchangedCount)
+	}
+	if stats.PartialCount > 0 {
+		fmt.Fprintf(w, "   Partial:         %d (failed: %s)\n", stats.PartialCount, stats.FailedComponentsSummary())
+	}
 	fmt.Fprintf(w, "\n")
 	fmt.Fprintf(w, "   Total Duration:  %s\n", stats.TotalDuration.Round(time.Millisecond))
-	fmt.Fprintf(w, "   Avg per Server:  %s\n", stats.AverageDuration.Round(time.Millisecond))
-	fmt.Fprintf(w, "   Fastest:         %s\n", stats.FastestDuration.Round(time.Millisecond))
+	if p := stats.PercentilesSummary(); p != "" {
+		fmt.Fprintf(w, "   Per Server:      %s\n", p)
+	} else {
+		fmt.Fprintf(w, "   Avg per Server:  %s\n", stats.AverageDuration.Round(time.Millisecond))
+	}
 	fmt.Fprintf(w, "   Slowest:         %s\n", stats.SlowestDuration.Round(time.Millisecond))
+	if len(stats.DurationHistogram) > 0 {
+		fmt.Fprintf(w, "\n   Scan Durations:\n")
+		for _, row := range histogramRows(stats.DurationHistogram, f.histogramBar()) {
+			fmt.Fprintf(w, "   %-15s %5d  %s\n", row.label, row.count, row.bar)
+		}
+	}
+
+	if reasons := stats.TopFailureReasons(topFailureReasons); len(reasons) > 0 {
+		fmt.Fprintf(w, "\n   Top Failure Reasons:\n")
+		for _, r := range reasons {
+			fmt.Fprintf(w, "%s\n", f.fit(fmt.Sprintf("   %-18s %3d  (e.g. %s: %s)", r.Category, r.Count, r.ExampleHost, r.ExampleError)))
+		}
+	}
+
+	if fl := stats.Fleet; fl != nil {
+		fmt.Fprintf(w, "\n   Fleet Summary:\n")
+		fmt.Fprintf(w, "   Servers:         %d\n", fl.Servers)
+		fmt.Fprintf(w, "   CPUs:            %d sockets, %d cores, %d threads\n", fl.CPUSockets, fl.CPUCores, fl.CPUThreads)
+		fmt.Fprintf(w, "   Memory:          %s\n", f.Units.Gigabytes(fl.MemoryGiB))
+		fmt.Fprintf(w, "   Storage:         %s (%d drives)\n", f.Units.Terabytes(fl.StorageTB), fl.Drives)
+		for _, m := range fl.Storage {
+			fmt.Fprintf(w, "     %-15s %s (%d drives)\n", m.MediaType+":", f.Units.Terabytes(m.CapacityTB), m.Drives)
+		}
+		fmt.Fprintf(w, "   GPUs:            %d\n", fl.GPUs)
+		fmt.Fprintf(w, "   Generations:     %s\n", fleetGenerations(*fl))
+	}
+}
+
+// topFailureReasons is the number of failure categories shown in summaries.
+const topFailureReasons = 5
+
+// maxHistogramBar is the length of the longest bar of a duration histogram
+// in the default console width.
+const maxHistogramBar = 30
+
+// histogramLabelWidth is the width of a console histogram row before its bar.
+const histogramLabelWidth = 26
+
+type histogramRow struct {
+	label string
+	count int
+	bar   string
+}
+
+// histogramRows returns the rows of a duration histogram from the first to
+// the last non-empty bucket, with bars scaled to the largest bucket, which
+// gets a bar of barWidth.
+func histogramRows(buckets []models.HistogramBucket, barWidth int) []histogramRow {
+	first, last, largest := -1, -1, 0
+	for i, b := range buckets {
+		if b.Count > 0 {
+			if first < 0 {
+				first = i
+			}
+			last = i
+			largest = max(largest, b.Count)
+		}
+	}
+	if first < 0 {
+		return nil
+	}
+
+	rows := make([]histogramRow, 0, last-first+1)
+	for i := first; i <= last; i++ {
+		var prev time.Duration
+		if i > 0 {
+			prev = buckets[i-1].LE
+		}
+		b := buckets[i]
+		n := (b.Count*barWidth + largest - 1) / largest
+		rows = append(rows, histogramRow{label: b.Label(prev), count: b.Count, bar: strings.Repeat("█", n)})
+	}
+	return rows
+}
+
+// width returns the width of the layout: the terminal width, or the
+// default width if it is unknown.
+func (f *ConsoleFormatter) width() int {
+	if f.Width <= 0 {
+		return defaultConsoleWidth
+	}
+	return max(f.Width, minConsoleWidth)
+}
+
+// rule returns a separator line across the layout.
+func (f *ConsoleFormatter) rule() string {
+	return strings.Repeat("═", f.width())
+}
+
+// fit truncates a line of free text (errors, notes) to the terminal width,
+// so it does not wrap. Lines are kept whole with Wide or if the width is
+// unknown.
+func (f *ConsoleFormatter) fit(line string) string {
+	if f.Wide || f.Width <= 0 {
+		return line
+	}
+	return truncate(line, f.width())
+}
+
+// histogramBar returns the length of the longest histogram bar, scaled
+// with the layout width.
+func (f *ConsoleFormatter) histogramBar() int {
+	return max(min(maxHistogramBar*f.width()/defaultConsoleWidth, f.width()-histogramLabelWidth), 1)
 }
 
 func (f *ConsoleFormatter) icon(emoji string) string {
@@ -222,6 +502,25 @@ func (f *ConsoleFormatter) icon(emoji string) string {
 	return emoji
 }
 
+// nvmeLine describes the link and endurance of an NVMe drive, e.g.
+// "NVMe: link x4 @ 16 GT/s, 97% endurance left, 2 namespace(s)".
+func (f *ConsoleFormatter) nvmeLine(n models.NVMeInfo) string {
+	parts := []string{"link " + f.valueOrNA(n.Link())}
+	if n.Degraded() {
+		parts[0] += fmt.Sprintf(" %s degraded (capable x%d @ %g GT/s)", f.icon("⚠️"), n.CapableLinkWidth, n.CapableLinkSpeedGTs)
+	}
+	if n.EnduranceLeftPct != nil {
+		parts = append(parts, fmt.Sprintf("%d%% endurance left", *n.EnduranceLeftPct))
+	}
+	if n.Namespaces > 0 {
+		parts = append(parts, fmt.Sprintf("%d namespace(s)", n.Namespaces))
+	}
+	if n.FailurePredicted {
+		parts = append(parts, f.icon("❗")+" failure predicted")
+	}
+	return "NVMe: " + strings.Join(parts, ", ")
+}
+
 func (f *ConsoleFormatter) valueOrNA(s string) string {
 	if s == "" {
 		return "N/A"
@@ -264,39 +563,120 @@ func (f *ConsoleFormatter) formatPowerState(state string) string {
 }
 
 func (f *ConsoleFormatter) formatHealth(health string) string {
-	return f.formatWithIcon(health, healthIcons, "")
+	return f.Style.Health(health, f.formatWithIcon(health, healthIcons, ""))
 }
 
-// Format outputs results as JSON.
+// Format outputs results as JSON. Servers are encoded one at a time, so
+// memory use does not grow with the size of the document.
 func (f *JSONFormatter) Format(w io.Writer, results []models.ServerInfo, stats models.CollectionStats) error {
-	output := struct {
-		Servers []models.ServerInfo    `json:"servers"`
-		Stats   models.CollectionStats `json:"stats"`
-	}{
-		Servers: results,
-		Stats:   stats,
+	if err := f.Begin(w); err != nil {
+		return err
+	}
+	for _, info := range results {
+		if err := f.WriteServer(w, info); err != nil {
+			return err
+		}
 	}
+	return f.End(w, stats)
+}
 
-	encoder := json.NewEncoder(w)
+// jsonIndent returns the prefix, indent and newline used for indented output.
+func (f *JSONFormatter) jsonIndent() (string, string, string) {
 	if f.Indent {
-		encoder.SetIndent("", "  ")
+		return "    ", "  ", "\n"
 	}
+	return "", "", ""
+}
 
-	return encoder.Encode(output)
+// Begin implements StreamFormatter by opening the document and servers array.
+func (f *JSONFormatter) Begin(w io.Writer) error {
+	_, indent, nl := f.jsonIndent()
+	f.written = 0
+	_, err := fmt.Fprintf(w, "{%s%s\"schema_version\": %d,%s%s\"servers\": [", nl, indent, models.SchemaVersion, nl, indent)
+	return err
+}
+
+// WriteServer implements StreamFormatter by appending a server to the array.
+func (f *JSONFormatter) WriteServer(w io.Writer, info models.ServerInfo) error {
+	prefix, indent, nl := f.jsonIndent()
+	data, err := marshalJSON(info, prefix, indent)
+	if err != nil {
+		return fmt.Errorf("%s: %w", info.Host, err)
+	}
+	sep := ","
+	if f.written == 0 {
+		sep = ""
+	}
+	f.written++
+	_, err = fmt.Fprintf(w, "%s%s%s%s", sep, nl, prefix, data)
+	return err
+}
+
+// End implements StreamFormatter by closing the array and writing the stats.
+func (f *JSONFormatter) End(w io.Writer, stats models.CollectionStats) error {
+	_, indent, nl := f.jsonIndent()
+	closing := nl + indent
+	if f.written == 0 {
+		closing = ""
+	}
+
+	data, err := marshalJSON(stats, indent, indent)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s],%s%s\"stats\": %s%s}\n", closing, nl, indent, data, nl)
+	return err
+}
+
+// marshalJSON encodes v with json.Encoder, optionally indented, without the trailing newline.
+func marshalJSON(v interface{}, prefix, indent string) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	if indent != "" {
+		encoder.SetIndent(prefix, indent)
+	}
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
+
+// jsonResults is the document written by JSONFormatter.
+type jsonResults struct {
+	SchemaVersion int                    `json:"schema_version"`
+	Servers       []models.ServerInfo    `json:"servers"`
+	Stats         models.CollectionStats `json:"stats"`
+}
+
+// ReadJSON parses results previously written by JSONFormatter
+// (-output json or the daemon's last-scan.json). Gzip-compressed input
+// (-compress) is detected and decompressed transparently.
+func ReadJSON(r io.Reader) ([]models.ServerInfo, models.CollectionStats, error) {
+	r, err := maybeGunzip(r)
+	if err != nil {
+		return nil, models.CollectionStats{}, err
+	}
+	var doc jsonResults
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, models.CollectionStats{}, fmt.Errorf("invalid JSON results: %w", err)
+	}
+	return doc.Servers, doc.Stats, nil
 }
 
 // Format outputs results as a table.
 func (f *TableFormatter) Format(w io.Writer, results []models.ServerInfo, stats models.CollectionStats) error {
-	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
-
-	// Header
-	fmt.Fprintln(tw, "HOST\tMODEL\tSERVICE TAG\tCPUs\tRAM (GB)\tRAM SLOTS\tGPUs\tGPU MODEL\tDRIVES\tPOWER (W)\tSTATUS")
-	fmt.Fprintln(tw, "----\t-----\t-----------\t----\t--------\t---------\t----\t---------\t------\t---------\t------")
+	rows := [][]string{
+		{"HOST", "MODEL", "SERVICE TAG", "CPUs", fmt.Sprintf("RAM (%s)", f.Units.GBUnit()), "RAM SLOTS", "GPUs", "GPU MODEL", "DRIVES", "POWER (W)", "STATUS"},
+	}
 
 	for _, info := range results {
 		status := "OK"
 		if info.Error != nil {
 			status = "ERROR"
+		} else if info.Stale {
+			status = "STALE"
+		} else if info.IsPartial() {
+			status = "PARTIAL (" + strings.Join(info.FailedComponents(), ", ") + ")"
 		}
 
 		ramSlots := fmt.Sprintf("%d/%d (%d free)", info.MemorySlotsUsed, info.MemorySlotsTotal, info.MemorySlotsFree)
@@ -312,19 +692,37 @@ func (f *TableFormatter) Format(w io.Writer, results []models.ServerInfo, stats
 			gpuModel = info.GPUs[0].Model
 		}
 
-		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%.0f\t%s\t%d\t%s\t%d\t%s\t%s\n",
+		rows = append(rows, []string{
 			info.Host,
 			info.Model,
 			info.ServiceTag,
-			info.CPUCount,
-			info.TotalMemoryGiB,
+			fmt.Sprintf("%d", info.CPUCount),
+			fmt.Sprintf("%d", f.Units.WholeGB(info.TotalMemoryGiB)),
 			ramSlots,
-			info.GPUCount,
+			fmt.Sprintf("%d", info.GPUCount),
 			gpuModel,
-			info.DriveCount,
+			fmt.Sprintf("%d", info.DriveCount),
 			power,
 			status,
-		)
+		})
+	}
+
+	// Truncate the model, GPU model and status columns to the terminal
+	if !f.Wide {
+		fitColumns(rows, f.Width, tablePadding, 1, 7, 10)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, tablePadding, ' ', 0)
+	for i, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+		if i == 0 {
+			// Underline the header
+			underline := make([]string, len(row))
+			for j, h := range row {
+				underline[j] = strings.Repeat("-", len([]rune(h)))
+			}
+			fmt.Fprintln(tw, strings.Join(underline, "\t"))
+		}
 	}
 
 	tw.Flush()
@@ -333,10 +731,16 @@ func (f *TableFormatter) Format(w io.Writer, results []models.ServerInfo, stats
 	fmt.Fprintf(w, "\nTotal: %d servers (%d successful, %d failed) in %s\n",
 		stats.TotalServers, stats.SuccessfulCount, stats.FailedCount,
 		stats.TotalDuration.Round(time.Millisecond))
+	if stats.PartialCount > 0 {
+		fmt.Fprintf(w, "Partial: %d servers (failed: %s)\n", stats.PartialCount, stats.FailedComponentsSummary())
+	}
 
 	return nil
 }
 
+// tablePadding is the space between table columns.
+const tablePadding = 2
+
 // CSVFormatter outputs results as CSV.
 type CSVFormatter struct{}
 
@@ -347,52 +751,77 @@ func NewCSVFormatter() *CSVFormatter {
 
 // Format outputs results as CSV.
 func (f *CSVFormatter) Format(w io.Writer, results []models.ServerInfo, stats models.CollectionStats) error {
-	// Header
-	fmt.Fprintln(w, "host,model,manufacturer,service_tag,serial,bios_version,power_state,cpu_count,cpu_model,ram_total_gb,ram_slots_total,ram_slots_used,ram_slots_free,gpu_count,gpu_model,gpu_memory_gb,drive_count,storage_total_tb,power_consumed_watts,power_peak_watts,status,error")
-
+	if err := f.Begin(w); err != nil {
+		return err
+	}
 	for _, info := range results {
-		status := "OK"
-		errorMsg := ""
-		if info.Error != nil {
-			status = "ERROR"
-			errorMsg = info.Error.Error()
+		if err := f.WriteServer(w, info); err != nil {
+			return err
 		}
+	}
+	return f.End(w, stats)
+}
 
-		gpuModel := ""
-		gpuMemoryGB := 0
-		if len(info.GPUs) > 0 {
-			gpuModel = info.GPUs[0].Model
-			for _, g := range info.GPUs {
-				gpuMemoryGB += int(g.MemoryGB())
-			}
-		}
+// Begin implements StreamFormatter by writing the header row.
+func (f *CSVFormatter) Begin(w io.Writer) error {
+	_, err := fmt.Fprintln(w, "host,model,manufacturer,service_tag,serial,bios_version,power_state,cpu_count,cpu_model,ram_total_gb,ram_slots_total,ram_slots_used,ram_slots_free,gpu_count,gpu_model,gpu_memory_gb,drive_count,storage_total_tb,power_consumed_watts,power_peak_watts,idrac_license,status,error")
+	return err
+}
 
-		fmt.Fprintf(w, "%s,%s,%s,%s,%s,%s,%s,%d,%s,%.0f,%d,%d,%d,%d,%s,%d,%d,%.2f,%d,%d,%s,%s\n",
-			csvEscape(info.Host),
-			csvEscape(info.Model),
-			csvEscape(info.Manufacturer),
-			csvEscape(info.ServiceTag),
-			csvEscape(info.SerialNumber),
-			csvEscape(info.BiosVersion),
-			csvEscape(info.PowerState),
-			info.CPUCount,
-			csvEscape(info.CPUModel),
-			info.TotalMemoryGiB,
-			info.MemorySlotsTotal,
-			info.MemorySlotsUsed,
-			info.MemorySlotsFree,
-			info.GPUCount,
-			csvEscape(gpuModel),
-			gpuMemoryGB,
-			info.DriveCount,
-			info.TotalStorageTB,
-			info.PowerConsumedWatts,
-			info.PowerPeakWatts,
-			status,
-			csvEscape(errorMsg),
-		)
+// WriteServer implements StreamFormatter by writing one row.
+func (f *CSVFormatter) WriteServer(w io.Writer, info models.ServerInfo) error {
+	status := "OK"
+	errorMsg := ""
+	if info.Error != nil {
+		status = "ERROR"
+		errorMsg = info.Error.Error()
+	} else if info.Stale {
+		status = "STALE"
+		errorMsg = info.StaleError
+	} else if info.IsPartial() {
+		status = "PARTIAL"
+		errorMsg = strings.Join(info.ComponentErrorList(), "; ")
 	}
 
+	gpuModel := ""
+	gpuMemoryGB := 0
+	if len(info.GPUs) > 0 {
+		gpuModel = info.GPUs[0].Model
+		for _, g := range info.GPUs {
+			gpuMemoryGB += int(g.MemoryGB())
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "%s,%s,%s,%s,%s,%s,%s,%d,%s,%.0f,%d,%d,%d,%d,%s,%d,%d,%.2f,%d,%d,%s,%s,%s\n",
+		csvEscape(info.Host),
+		csvEscape(info.Model),
+		csvEscape(info.Manufacturer),
+		csvEscape(info.ServiceTag),
+		csvEscape(info.SerialNumber),
+		csvEscape(info.BiosVersion),
+		csvEscape(info.PowerState),
+		info.CPUCount,
+		csvEscape(info.CPUModel),
+		info.TotalMemoryGiB,
+		info.MemorySlotsTotal,
+		info.MemorySlotsUsed,
+		info.MemorySlotsFree,
+		info.GPUCount,
+		csvEscape(gpuModel),
+		gpuMemoryGB,
+		info.DriveCount,
+		info.TotalStorageTB,
+		info.PowerConsumedWatts,
+		info.PowerPeakWatts,
+		csvEscape(info.LicenseLevel),
+		status,
+		csvEscape(errorMsg),
+	)
+	return err
+}
+
+// End implements StreamFormatter; CSV output has no footer.
+func (f *CSVFormatter) End(w io.Writer, stats models.CollectionStats) error {
 	return nil
 }
 
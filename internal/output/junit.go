@@ -0,0 +1,208 @@
+package output
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/errors"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+// junitSuite is the name of the test suite of a scan.
+const junitSuite = "idrac-inventory"
+
+// JUnitFormatter outputs results as a JUnit XML report, so CI pipelines
+// (GitLab artifacts:reports:junit, Jenkins) show per-host pass/fail. Each
+// server is a test case: failed and stale scans are failures carrying the
+// error, hosts excluded from the NetBox sync (duplicate hardware) and hosts
+// without a result are skipped.
+type JUnitFormatter struct {
+	// Unscanned are configured hosts without a result (e.g. deferred by the
+	// scan budget), reported as skipped test cases.
+	Unscanned []string
+}
+
+// NewJUnitFormatter creates a new JUnit formatter.
+func NewJUnitFormatter() *JUnitFormatter {
+	return &JUnitFormatter{}
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Errors     int             `xml:"errors,attr"`
+	Skipped    int             `xml:"skipped,attr"`
+	Time       string          `xml:"time,attr"`
+	Timestamp  string          `xml:"timestamp,attr,omitempty"`
+	Properties []junitProperty `xml:"properties>property,omitempty"`
+	Cases      []junitTestCase `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+// Format outputs the results as a JUnit XML report.
+func (f *JUnitFormatter) Format(w io.Writer, results []models.ServerInfo, stats models.CollectionStats) error {
+	suite := junitTestSuite{Name: junitSuite, Time: junitSeconds(stats.TotalDuration)}
+	if stats.Shard != "" {
+		suite.Name += " shard " + stats.Shard
+	}
+	if stats.RunID != "" {
+		suite.Properties = append(suite.Properties, junitProperty{Name: "run_id", Value: stats.RunID})
+	}
+
+	duplicates := models.DuplicateHosts(models.FindDuplicates(results))
+	var first time.Time
+	for _, info := range results {
+		if !info.CollectedAt.IsZero() && (first.IsZero() || info.CollectedAt.Before(first)) {
+			first = info.CollectedAt
+		}
+		tc := junitServer(info)
+		if dup, ok := duplicates[info.Host]; ok && tc.Failure == nil {
+			tc.Skipped = &junitMessage{Message: "duplicate hardware, only existing NetBox devices are updated: " + dup.String()}
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+	unscanned := append([]string(nil), f.Unscanned...)
+	sort.Strings(unscanned)
+	for _, host := range unscanned {
+		suite.Cases = append(suite.Cases, junitTestCase{
+			Name:      host,
+			ClassName: junitSuite,
+			Time:      junitSeconds(0),
+			Skipped:   &junitMessage{Message: "not scanned"},
+		})
+	}
+	if !first.IsZero() {
+		suite.Timestamp = first.UTC().Format("2006-01-02T15:04:05")
+	}
+
+	return writeJUnit(w, suite)
+}
+
+// junitServer returns the test case of a server.
+func junitServer(info models.ServerInfo) junitTestCase {
+	tc := junitTestCase{
+		Name:      info.Key(),
+		ClassName: junitSuite,
+		Time:      junitSeconds(info.ScanDuration),
+	}
+	if info.Model != "" {
+		tc.ClassName += "." + info.Model
+	}
+
+	var out []string
+	switch {
+	case info.Error != nil:
+		code := errors.CodeOf(info.Error)
+		tc.Failure = &junitMessage{Message: info.Error.Error(), Type: string(code), Text: info.Error.Error()}
+	case info.Stale:
+		tc.Failure = &junitMessage{
+			Message: "stale: " + info.StaleError,
+			Type:    "stale",
+			Text:    fmt.Sprintf("current scan failed: %s\nlast known good data from %s", info.StaleError, info.StaleSince.Format(time.RFC3339)),
+		}
+	default:
+		if info.ServiceTag != "" {
+			out = append(out, fmt.Sprintf("%s (service tag %s)", info.Model, info.ServiceTag))
+		}
+		for _, e := range info.ComponentErrorList() {
+			out = append(out, "partial: "+e)
+		}
+		for _, v := range info.PolicyViolations() {
+			out = append(out, "policy: "+v)
+		}
+	}
+	out = append(out, info.Notes...)
+	tc.SystemOut = strings.Join(out, "\n")
+	return tc
+}
+
+// FormatValidation outputs connection validation results as a JUnit XML
+// report with a test case per host.
+func (f *JUnitFormatter) FormatValidation(w io.Writer, checks []models.ConnectionCheck) error {
+	suite := junitTestSuite{Name: junitSuite + " validation"}
+	var total time.Duration
+	for _, c := range checks {
+		total += c.Latency
+		tc := junitTestCase{Name: c.Host, ClassName: junitSuite + ".validation", Time: junitSeconds(c.Latency)}
+		if !c.OK() {
+			tc.Failure = &junitMessage{Message: c.Error.Error(), Type: string(errors.CodeOf(c.Error)), Text: c.Error.Error()}
+		} else {
+			tc.SystemOut = fmt.Sprintf("Redfish %s, firmware %s", c.RedfishVersion, c.FirmwareVersion)
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+	suite.Time = junitSeconds(total)
+	return writeJUnit(w, suite)
+}
+
+// writeJUnit counts the test cases of suite and writes it as a report.
+func writeJUnit(w io.Writer, suite junitTestSuite) error {
+	suite.Tests = len(suite.Cases)
+	for _, tc := range suite.Cases {
+		switch {
+		case tc.Failure != nil:
+			suite.Failures++
+		case tc.Skipped != nil:
+			suite.Skipped++
+		}
+	}
+	report := junitTestSuites{
+		Name:     junitSuite,
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Skipped:  suite.Skipped,
+		Time:     suite.Time,
+		Suites:   []junitTestSuite{suite},
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return fmt.Errorf("failed to encode JUnit report: %w", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// junitSeconds formats a duration as JUnit seconds.
+func junitSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
@@ -0,0 +1,62 @@
+package output
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/errors"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+// assertGolden compares got with the golden file testdata/name, or writes
+// it with -update.
+func assertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		require.NoError(t, os.WriteFile(path, got, 0o644))
+	}
+	want, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, string(want), string(got))
+}
+
+func TestJUnitFormatter_Format(t *testing.T) {
+	collected := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	results := []models.ServerInfo{
+		{
+			Host: "10.0.0.1", Model: "PowerEdge R750", ServiceTag: "ABC1234",
+			CollectedAt: collected, ScanDuration: 1500 * time.Millisecond,
+			Notes: []string{"rack A1"},
+		},
+		{
+			Host: "10.0.0.2", CollectedAt: collected.Add(time.Second), ScanDuration: 30 * time.Second,
+			Error: errors.WithCode(errors.CodeTimeout, `GET /redfish/v1 <x> & "quoted": timeout`),
+		},
+		{
+			// Same service tag as 10.0.0.4: excluded from the NetBox sync
+			Host: "10.0.0.3", Model: "PowerEdge R640", ServiceTag: "DUP0001",
+			CollectedAt: collected.Add(2 * time.Second), ScanDuration: 2 * time.Second,
+		},
+		{
+			Host: "10.0.0.4", Model: "PowerEdge R640", ServiceTag: "DUP0001",
+			CollectedAt: collected.Add(3 * time.Second), ScanDuration: 2 * time.Second,
+		},
+	}
+	stats := models.CollectionStats{RunID: "run-1", TotalDuration: 35 * time.Second}
+
+	f := NewJUnitFormatter()
+	f.Unscanned = []string{"10.0.0.6", "10.0.0.5"}
+	var buf bytes.Buffer
+	require.NoError(t, f.Format(&buf, results, stats))
+	assertGolden(t, "junit.golden", buf.Bytes())
+}
@@ -6,7 +6,8 @@ import (
 	"strings"
 	"time"
 
-	"idrac-inventory/internal/models"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+	"github.com/braunma/idrac-netbox-importer/pkg/units"
 )
 
 // MarkdownFormatter generates a GitLab-flavoured Markdown inventory report.
@@ -14,11 +15,17 @@ import (
 //   - Tables for hardware specs and server lists
 //   - <details> collapsible sections per group for large deployments
 //   - A summary table linking all groups at the top
-type MarkdownFormatter struct{}
+type MarkdownFormatter struct {
+	Units units.Format
+
+	// Trends are the daily fleet totals of the history store, shown as a
+	// trend section if there are at least two.
+	Trends []models.TrendSample
+}
 
 // NewMarkdownFormatter creates a new MarkdownFormatter.
 func NewMarkdownFormatter() *MarkdownFormatter {
-	return &MarkdownFormatter{}
+	return &MarkdownFormatter{Units: units.Default()}
 }
 
 // FormatAggregated writes the aggregated inventory as Markdown to w.
@@ -48,7 +55,7 @@ func (f *MarkdownFormatter) FormatAggregated(w io.Writer, inv models.AggregatedI
 			if fp.CPUModel != "" {
 				cpuCol += " " + shortenCPUModel(fp.CPUModel)
 			}
-			ramCol = fmt.Sprintf("%d GiB", fp.RAMTotalGiB)
+			ramCol = ramDisplay(fp, f.Units)
 			if fp.RAMType != "" {
 				ramTypeCol = fp.RAMType
 				if fp.RAMSpeedMHz > 0 {
@@ -58,13 +65,13 @@ func (f *MarkdownFormatter) FormatAggregated(w io.Writer, inv models.AggregatedI
 			if fp.RAMSlotsTotal > 0 && len(mg.ConfigGroups[0].Servers) > 0 {
 				s := mg.ConfigGroups[0].Servers[0]
 				if fp.RAMModuleSizeGiB > 0 {
-					ramSlotsCol = fmt.Sprintf("%d/%d × %d GiB (%d free)",
-						s.MemorySlotsUsed, fp.RAMSlotsTotal, fp.RAMModuleSizeGiB, s.MemorySlotsFree)
+					ramSlotsCol = fmt.Sprintf("%d/%d × %s (%d free)",
+						s.MemorySlotsUsed, fp.RAMSlotsTotal, f.Units.Gigabytes(float64(fp.RAMModuleSizeGiB)), s.MemorySlotsFree)
 				} else {
 					ramSlotsCol = fmt.Sprintf("%d/%d (%d free)", s.MemorySlotsUsed, fp.RAMSlotsTotal, s.MemorySlotsFree)
 				}
 			}
-			storageCol = fp.StorageSummary
+			storageCol = dashIfEmpty(fp.StorageDisplay())
 			if len(mg.ConfigGroups) > 1 {
 				storageCol += " *(varies)*"
 			}
@@ -82,15 +89,82 @@ func (f *MarkdownFormatter) FormatAggregated(w io.Writer, inv models.AggregatedI
 
 	fmt.Fprintf(w, "\n")
 
+	if fl := inv.Stats.Fleet; fl != nil {
+		fmt.Fprintf(w, "### Fleet Summary\n\n")
+		fmt.Fprintf(w, "| Metric | Total |\n")
+		fmt.Fprintf(w, "|--------|-------|\n")
+		fmt.Fprintf(w, "| Servers | %d |\n", fl.Servers)
+		fmt.Fprintf(w, "| CPUs | %d sockets, %s cores, %s threads |\n",
+			fl.CPUSockets, formatWithCommas(fl.CPUCores), formatWithCommas(fl.CPUThreads))
+		fmt.Fprintf(w, "| Memory | %s |\n", f.Units.Gigabytes(fl.MemoryGiB))
+		fmt.Fprintf(w, "| Storage | %s (%d drives) |\n", f.Units.Terabytes(fl.StorageTB), fl.Drives)
+		for _, m := range fl.Storage {
+			fmt.Fprintf(w, "| &nbsp;&nbsp;%s | %s (%d drives) |\n", m.MediaType, f.Units.Terabytes(m.CapacityTB), m.Drives)
+		}
+		fmt.Fprintf(w, "| GPUs | %d |\n", fl.GPUs)
+		fmt.Fprintf(w, "| Generations | %s |\n\n", fleetGenerations(*fl))
+	}
+
+	if rows := ageRows(inv.Ages); len(rows) > 0 {
+		fmt.Fprintf(w, "### Server Age\n\n")
+		fmt.Fprintf(w, "| Age | Servers | |\n")
+		fmt.Fprintf(w, "|-----|---------|-|\n")
+		for _, row := range rows {
+			fmt.Fprintf(w, "| %s | %d | %s |\n", row.label, row.count, row.bar)
+		}
+		fmt.Fprintf(w, "\n")
+	}
+
+	if inv.Firmware != nil {
+		fmt.Fprintf(w, "### Firmware Compliance\n\n")
+		fmt.Fprintf(w, "| Model | Servers | Compliant | Rate |\n")
+		fmt.Fprintf(w, "|-------|---------|-----------|------|\n")
+		for _, c := range inv.Firmware {
+			fmt.Fprintf(w, "| %s | %d | %d | %.1f%% |\n", c.Model, c.Servers, c.Compliant, c.Rate())
+		}
+		total := models.TotalFirmwareCompliance(inv.Firmware)
+		fmt.Fprintf(w, "| **Total** | %d | %d | %.1f%% |\n", total.Servers, total.Compliant, total.Rate())
+		fmt.Fprintf(w, "\n")
+	}
+
 	// Scan timing stats (if available)
 	if inv.Stats.TotalDuration > 0 {
 		fmt.Fprintf(w, "### Scan Timing\n\n")
 		fmt.Fprintf(w, "| Metric | Value |\n")
 		fmt.Fprintf(w, "|--------|-------|\n")
 		fmt.Fprintf(w, "| Total duration | `%s` |\n", inv.Stats.TotalDuration.Round(time.Millisecond))
-		fmt.Fprintf(w, "| Average per server | `%s` |\n", inv.Stats.AverageDuration.Round(time.Millisecond))
-		fmt.Fprintf(w, "| Fastest | `%s` |\n", inv.Stats.FastestDuration.Round(time.Millisecond))
+		if len(inv.Stats.DurationPercentiles) == 0 {
+			fmt.Fprintf(w, "| Average per server | `%s` |\n", inv.Stats.AverageDuration.Round(time.Millisecond))
+		}
+		for _, p := range inv.Stats.DurationPercentiles {
+			fmt.Fprintf(w, "| %s per server | `%s` |\n", p.Label(), p.Duration.Round(time.Millisecond))
+		}
 		fmt.Fprintf(w, "| Slowest | `%s` |\n\n", inv.Stats.SlowestDuration.Round(time.Millisecond))
+
+		if rows := histogramRows(inv.Stats.DurationHistogram, maxHistogramBar); len(rows) > 0 {
+			fmt.Fprintf(w, "| Scan duration | Servers | |\n")
+			fmt.Fprintf(w, "|---------------|---------|-|\n")
+			for _, row := range rows {
+				fmt.Fprintf(w, "| %s | %d | %s |\n", row.label, row.count, row.bar)
+			}
+			fmt.Fprintf(w, "\n")
+		}
+	}
+
+	if reasons := inv.Stats.TopFailureReasons(topFailureReasons); len(reasons) > 0 {
+		fmt.Fprintf(w, "### Top Failure Reasons\n\n")
+		fmt.Fprintf(w, "| Category | Hosts | Example |\n")
+		fmt.Fprintf(w, "|----------|-------|---------|\n")
+		for _, r := range reasons {
+			fmt.Fprintf(w, "| %s | %d | `%s`: %s |\n", r.Category, r.Count, r.ExampleHost, mdEscape(r.ExampleError))
+		}
+		fmt.Fprintf(w, "\n")
+	}
+
+	f.writeNotes(w, inv)
+
+	if len(f.Trends) >= 2 {
+		f.writeTrends(w, f.Trends)
 	}
 
 	fmt.Fprintf(w, "---\n\n")
@@ -101,6 +175,8 @@ func (f *MarkdownFormatter) FormatAggregated(w io.Writer, inv models.AggregatedI
 		f.writeModelGroup(w, i+1, mg)
 	}
 
+	f.writeSlotMaps(w, inv)
+
 	// Failed servers section
 	if len(inv.FailedServers) > 0 {
 		f.writeFailedServers(w, inv.FailedServers)
@@ -112,6 +188,9 @@ func (f *MarkdownFormatter) FormatAggregated(w io.Writer, inv models.AggregatedI
 func (f *MarkdownFormatter) writeModelGroup(w io.Writer, idx int, mg models.ModelGroup) {
 	fmt.Fprintf(w, "<a id=\"model-%d\"></a>\n\n", idx)
 	fmt.Fprintf(w, "### Model %d — %d× %s\n\n", idx, mg.TotalCount, mg.DisplayModel())
+	if mg.Chassis != nil {
+		fmt.Fprintf(w, "**Chassis:** %s\n\n", mg.Chassis)
+	}
 
 	if len(mg.ConfigGroups) == 1 {
 		// Single config: render inline without a nested header.
@@ -160,7 +239,7 @@ func (f *MarkdownFormatter) writeConfigGroup(w io.Writer, idx int, group models.
 	}
 
 	// RAM rows
-	fmt.Fprintf(w, "| **RAM** | %d GiB |\n", fp.RAMTotalGiB)
+	fmt.Fprintf(w, "| **RAM** | %s |\n", ramDisplay(fp, f.Units))
 	if fp.RAMType != "" {
 		ramTypeLine := fp.RAMType
 		if fp.RAMSpeedMHz > 0 {
@@ -171,8 +250,8 @@ func (f *MarkdownFormatter) writeConfigGroup(w io.Writer, idx int, group models.
 	if fp.RAMSlotsTotal > 0 && len(group.Servers) > 0 {
 		s := group.Servers[0]
 		if fp.RAMModuleSizeGiB > 0 {
-			fmt.Fprintf(w, "| **RAM Slots** | %d/%d × %d GiB (%d free) |\n",
-				s.MemorySlotsUsed, fp.RAMSlotsTotal, fp.RAMModuleSizeGiB, s.MemorySlotsFree)
+			fmt.Fprintf(w, "| **RAM Slots** | %d/%d × %s (%d free) |\n",
+				s.MemorySlotsUsed, fp.RAMSlotsTotal, f.Units.Gigabytes(float64(fp.RAMModuleSizeGiB)), s.MemorySlotsFree)
 		} else {
 			fmt.Fprintf(w, "| **RAM Slots** | %d/%d (%d free) |\n",
 				s.MemorySlotsUsed, fp.RAMSlotsTotal, s.MemorySlotsFree)
@@ -186,15 +265,17 @@ func (f *MarkdownFormatter) writeConfigGroup(w io.Writer, idx int, group models.
 			gpuLine += " " + fp.GPUModel
 		}
 		if fp.GPUMemoryGiB > 0 {
-			gpuLine += fmt.Sprintf(" · %d GB VRAM each", fp.GPUMemoryGiB)
+			gpuLine += fmt.Sprintf(" · %s VRAM each", f.Units.Gigabytes(float64(fp.GPUMemoryGiB)))
 		}
 		fmt.Fprintf(w, "| **GPUs/Accelerators** | %s |\n", gpuLine)
 	}
 
-	// Storage rows
-	fmt.Fprintf(w, "| **Storage** | %s |\n", mdEscape(fp.StorageSummary))
+	// Storage rows (omitted when excluded from the fingerprint)
+	if storage := fp.StorageDisplay(); storage != "" {
+		fmt.Fprintf(w, "| **Storage** | %s |\n", mdEscape(storage))
+	}
 	if group.TotalStorageTB > 0 {
-		fmt.Fprintf(w, "| **Total Storage** | %.2f TB |\n", group.TotalStorageTB)
+		fmt.Fprintf(w, "| **Total Storage** | %s |\n", f.Units.Terabytes(group.TotalStorageTB))
 	}
 
 	fmt.Fprintf(w, "\n")
@@ -230,6 +311,80 @@ func (f *MarkdownFormatter) writeConfigGroup(w io.Writer, idx int, group models.
 	fmt.Fprintf(w, "\n</details>\n\n")
 }
 
+// writeNotes lists the servers with notes from the annotations file, if any.
+func (f *MarkdownFormatter) writeNotes(w io.Writer, inv models.AggregatedInventory) {
+	var annotated []models.ServerInfo
+	for _, mg := range inv.ModelGroups {
+		for _, g := range mg.ConfigGroups {
+			for _, srv := range g.Servers {
+				if len(srv.Notes) > 0 {
+					annotated = append(annotated, srv)
+				}
+			}
+		}
+	}
+	for _, srv := range inv.FailedServers {
+		if len(srv.Notes) > 0 {
+			annotated = append(annotated, srv)
+		}
+	}
+	if len(annotated) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "### Notes\n\n")
+	fmt.Fprintf(w, "| IP Address | Service Tag | Model | Notes |\n")
+	fmt.Fprintf(w, "|-----------|-------------|-------|-------|\n")
+	for _, srv := range annotated {
+		model := srv.Model
+		if srv.Error != nil {
+			model = "❌ Failed"
+		}
+		fmt.Fprintf(w, "| `%s` | %s | %s | %s |\n",
+			srv.Host, dashIfEmpty(srv.ServiceTag), dashIfEmpty(model), mdEscape(strings.Join(srv.Notes, "; ")))
+	}
+	fmt.Fprintf(w, "\n")
+}
+
+// writeSlotMaps draws the drive bay and DIMM slot maps of each server in a
+// collapsible section, so technicians can see which bays and slots are free.
+func (f *MarkdownFormatter) writeSlotMaps(w io.Writer, inv models.AggregatedInventory) {
+	type serverMaps struct {
+		srv  models.ServerInfo
+		maps []models.SlotMap
+	}
+	var servers []serverMaps
+	for _, mg := range inv.ModelGroups {
+		for _, g := range mg.ConfigGroups {
+			for _, srv := range g.Servers {
+				if maps := srv.SlotMaps(); len(maps) > 0 {
+					servers = append(servers, serverMaps{srv, maps})
+				}
+			}
+		}
+	}
+	if len(servers) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "## Slot Maps\n\n")
+	fmt.Fprintf(w, "> %s\n\n", slotLegend)
+	for _, s := range servers {
+		var free []string
+		for _, m := range s.maps {
+			free = append(free, fmt.Sprintf("%d/%d %s free", m.Free(), m.Total(), m.Kind))
+		}
+		fmt.Fprintf(w, "<details>\n")
+		fmt.Fprintf(w, "<summary><code>%s</code> %s — %s</summary>\n\n",
+			s.srv.Host, dashIfEmpty(s.srv.ServiceTag), strings.Join(free, ", "))
+		fmt.Fprintf(w, "```\n")
+		for _, m := range s.maps {
+			writeSlotMap(w, m, "", Style{})
+		}
+		fmt.Fprintf(w, "```\n\n</details>\n\n")
+	}
+}
+
 func (f *MarkdownFormatter) writeFailedServers(w io.Writer, failed []models.ServerInfo) {
 	fmt.Fprintf(w, "## Failed Scans\n\n")
 	fmt.Fprintf(w, "| IP Address | Error |\n")
@@ -0,0 +1,475 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+	"github.com/braunma/idrac-netbox-importer/pkg/units"
+)
+
+// Report is a tabular report derived from scan results. The same report can be
+// rendered as a console table, CSV, Markdown or JSON.
+type Report struct {
+	Title   string
+	Headers []string
+	Rows    [][]string
+
+	// Data is the structured form of the report, used for JSON output.
+	Data interface{}
+}
+
+// WriteReport renders the report in the given format
+// (console, table, csv, markdown or json).
+func WriteReport(w io.Writer, r Report, format string) error {
+	switch format {
+	case "csv":
+		return writeReportCSV(w, r)
+	case "markdown", "md":
+		return writeReportMarkdown(w, r)
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(r.Data)
+	case "console", "table", "":
+		return writeReportTable(w, r)
+	default:
+		return fmt.Errorf("unsupported report format %q (use console, table, csv, markdown or json)", format)
+	}
+}
+
+func writeReportTable(w io.Writer, r Report) error {
+	if r.Title != "" {
+		fmt.Fprintf(w, "\n%s\n%s\n", r.Title, strings.Repeat("=", len([]rune(r.Title))))
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(r.Headers, "\t"))
+	underline := make([]string, len(r.Headers))
+	for i, h := range r.Headers {
+		underline[i] = strings.Repeat("-", len([]rune(h)))
+	}
+	fmt.Fprintln(tw, strings.Join(underline, "\t"))
+	for _, row := range r.Rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+func writeReportCSV(w io.Writer, r Report) error {
+	escaped := make([]string, len(r.Headers))
+	for i, h := range r.Headers {
+		escaped[i] = csvEscape(strings.ToLower(strings.ReplaceAll(h, " ", "_")))
+	}
+	fmt.Fprintln(w, strings.Join(escaped, ","))
+
+	for _, row := range r.Rows {
+		cells := make([]string, len(row))
+		for i, c := range row {
+			cells[i] = csvEscape(c)
+		}
+		fmt.Fprintln(w, strings.Join(cells, ","))
+	}
+	return nil
+}
+
+func writeReportMarkdown(w io.Writer, r Report) error {
+	if r.Title != "" {
+		fmt.Fprintf(w, "## %s\n\n", r.Title)
+	}
+
+	fmt.Fprintf(w, "| %s |\n", strings.Join(r.Headers, " | "))
+	sep := make([]string, len(r.Headers))
+	for i := range sep {
+		sep[i] = "---"
+	}
+	fmt.Fprintf(w, "|%s|\n", strings.Join(sep, "|"))
+	for _, row := range r.Rows {
+		cells := make([]string, len(row))
+		for i, c := range row {
+			cells[i] = mdEscape(c)
+		}
+		fmt.Fprintf(w, "| %s |\n", strings.Join(cells, " | "))
+	}
+	fmt.Fprintf(w, "\n")
+	return nil
+}
+
+// SparePartsReport builds the spare-part demand report
+// (part number → installed quantity → models using it).
+func SparePartsReport(parts []models.SparePart) Report {
+	r := Report{
+		Title:   "Spare Part Demand",
+		Headers: []string{"Category", "Part Number", "Manufacturer", "Description", "Quantity", "Servers", "Models"},
+		Data:    parts,
+	}
+	for _, p := range parts {
+		r.Rows = append(r.Rows, []string{
+			p.Category,
+			p.PartNumber,
+			dashIfEmpty(p.Manufacturer),
+			p.Description,
+			fmt.Sprintf("%d", p.Quantity),
+			fmt.Sprintf("%d", p.ServerCount),
+			strings.Join(p.Models, ", "),
+		})
+	}
+	return r
+}
+
+// CapacityReport builds the expansion headroom report with a fleet-wide total row,
+// with memory sizes in the units of u. Drive bays and PCIe slots show "-" where
+// the count was not detectable.
+func CapacityReport(c models.CapacityHeadroom, u units.Format) Report {
+	r := Report{
+		Title:   "Capacity Headroom",
+		Headers: []string{"Host", "Name", "Model", "Free DIMM Slots", "Largest DIMM", "Max Additional RAM", "Free Drive Bays", "Free PCIe Slots"},
+		Data:    c,
+	}
+	for _, s := range c.Servers {
+		r.Rows = append(r.Rows, []string{
+			s.Host,
+			dashIfEmpty(s.Name),
+			s.Model,
+			fmt.Sprintf("%d", s.FreeDIMMSlots),
+			u.Gigabytes(s.LargestDIMMGiB),
+			u.Gigabytes(s.MaxAdditionalRAMGiB),
+			optionalCount(s.FreeDriveBays),
+			optionalCount(s.FreePCIeSlots),
+		})
+	}
+	r.Rows = append(r.Rows, []string{
+		"TOTAL",
+		fmt.Sprintf("%d servers", len(c.Servers)),
+		"",
+		fmt.Sprintf("%d", c.TotalFreeDIMMSlots),
+		"",
+		u.Gigabytes(c.TotalMaxAdditionalRAMGiB),
+		fmt.Sprintf("%d (%d known)", c.TotalFreeDriveBays, c.DriveBaysKnown),
+		fmt.Sprintf("%d (%d known)", c.TotalFreePCIeSlots, c.PCIeSlotsKnown),
+	})
+	return r
+}
+
+func optionalCount(v *int) string {
+	if v == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%d", *v)
+}
+
+// DuplicatesReport lists service tags and serial numbers reported by more than one host.
+func DuplicatesReport(dups []models.Duplicate) Report {
+	r := Report{
+		Title:   "Duplicate Serial Numbers",
+		Headers: []string{"Kind", "Value", "Host Count", "Hosts"},
+		Data:    dups,
+	}
+	for _, d := range dups {
+		r.Rows = append(r.Rows, []string{
+			d.Kind,
+			d.Value,
+			fmt.Sprintf("%d", len(d.Hosts)),
+			strings.Join(d.Hosts, ", "),
+		})
+	}
+	return r
+}
+
+// SlowestHostsReport lists the slowest hosts and the collector phase that dominated each scan.
+func SlowestHostsReport(hosts []models.SlowHost) Report {
+	r := Report{
+		Title:   "Slowest Hosts",
+		Headers: []string{"Host", "Name", "Model", "Duration", "Dominant Phase", "Phase Duration", "Error"},
+		Data:    hosts,
+	}
+	for _, h := range hosts {
+		r.Rows = append(r.Rows, []string{
+			h.Host,
+			dashIfEmpty(h.Name),
+			dashIfEmpty(h.Model),
+			h.Duration.Round(time.Millisecond).String(),
+			dashIfEmpty(h.DominantPhase),
+			h.DominantPhaseDuration.Round(time.Millisecond).String(),
+			dashIfEmpty(h.Error),
+		})
+	}
+	return r
+}
+
+// ExpiringCertificatesReport lists iDRAC certificates that expire soon or have expired.
+func ExpiringCertificatesReport(certs []models.ExpiringCertificate) Report {
+	r := Report{
+		Title:   "Expiring iDRAC Certificates",
+		Headers: []string{"Host", "Name", "Expires", "Days Left", "Subject", "Issuer", "Self-Signed"},
+		Data:    certs,
+	}
+	for _, c := range certs {
+		selfSigned := "no"
+		if c.SelfSigned {
+			selfSigned = "yes"
+		}
+		r.Rows = append(r.Rows, []string{
+			c.Host,
+			dashIfEmpty(c.Name),
+			c.NotAfter.Format("2006-01-02"),
+			fmt.Sprintf("%d", c.DaysLeft),
+			c.Subject,
+			c.Issuer,
+			selfSigned,
+		})
+	}
+	return r
+}
+
+// LicensesReport lists the iDRAC license levels across the fleet.
+func LicensesReport(summary []models.LicenseSummary) Report {
+	r := Report{
+		Title:   "iDRAC Licenses",
+		Headers: []string{"License", "Servers", "Feature-Limited", "Hosts"},
+		Data:    summary,
+	}
+	for _, s := range summary {
+		limited := "no"
+		if s.Limited {
+			limited = "yes"
+		}
+		r.Rows = append(r.Rows, []string{
+			s.Level,
+			fmt.Sprintf("%d", s.Count),
+			limited,
+			strings.Join(s.Hosts, ", "),
+		})
+	}
+	return r
+}
+
+// GenerationsReport lists the servers by Dell generation, oldest first, for
+// refresh planning.
+func GenerationsReport(groups []models.GenerationGroup) Report {
+	r := Report{
+		Title:   "Server Generations",
+		Headers: []string{"Generation", "Servers", "Models", "Hosts"},
+		Data:    groups,
+	}
+	for _, g := range groups {
+		names := make([]string, 0, len(g.Models))
+		for name := range g.Models {
+			names = append(names, name)
+		}
+		sort.Slice(names, func(i, j int) bool {
+			if g.Models[names[i]] != g.Models[names[j]] {
+				return g.Models[names[i]] > g.Models[names[j]]
+			}
+			return names[i] < names[j]
+		})
+		counts := make([]string, len(names))
+		for i, name := range names {
+			counts[i] = fmt.Sprintf("%d× %s", g.Models[name], name)
+		}
+		r.Rows = append(r.Rows, []string{
+			g.Generation,
+			fmt.Sprintf("%d", g.Count),
+			strings.Join(counts, ", "),
+			strings.Join(g.Hosts, ", "),
+		})
+	}
+	return r
+}
+
+// ComplianceReport lists the deviations of servers from their golden config,
+// one row per violation.
+func ComplianceReport(findings []models.ComplianceFinding) Report {
+	r := Report{
+		Title:   "Golden Config Violations",
+		Headers: []string{"Host", "Service Tag", "Model", "Profile", "Component", "Expected", "Actual"},
+		Data:    findings,
+	}
+	for _, f := range findings {
+		for _, v := range f.Violations {
+			r.Rows = append(r.Rows, []string{
+				f.Host,
+				dashIfEmpty(f.ServiceTag),
+				dashIfEmpty(f.Model),
+				f.Profile,
+				v.Component + " " + v.Check,
+				v.Expected,
+				v.Actual,
+			})
+		}
+	}
+	return r
+}
+
+// FirmwareComplianceReport lists the firmware policy compliance rate per
+// model, with a total row and the hosts below the minimum versions.
+func FirmwareComplianceReport(rates []models.FirmwareComplianceRate) Report {
+	r := Report{
+		Title:   "Firmware Compliance",
+		Headers: []string{"Model", "Servers", "Compliant", "Rate", "Non-Compliant Hosts"},
+		Data:    rates,
+	}
+	row := func(c models.FirmwareComplianceRate) []string {
+		return []string{
+			dashIfEmpty(c.Model),
+			fmt.Sprintf("%d", c.Servers),
+			fmt.Sprintf("%d", c.Compliant),
+			fmt.Sprintf("%.1f%%", c.Rate()),
+			dashIfEmpty(strings.Join(c.NonCompliant, ", ")),
+		}
+	}
+	for _, c := range rates {
+		r.Rows = append(r.Rows, row(c))
+	}
+	if len(rates) > 1 {
+		r.Rows = append(r.Rows, row(models.TotalFirmwareCompliance(rates)))
+	}
+	return r
+}
+
+// CapabilityReport is the capability matrix: one row per model and iDRAC
+// firmware version, one column per collector phase. A cell is "yes" if all
+// servers support the phase, "no" if none does, otherwise the number of
+// servers supporting it (including degraded ones) out of those checked.
+func CapabilityReport(groups []models.CapabilityGroup) Report {
+	phases := models.CapabilityPhases(groups)
+	r := Report{
+		Title:   "Capability Matrix",
+		Headers: append([]string{"Model", "iDRAC", "Servers"}, phases...),
+		Data:    groups,
+	}
+	for _, g := range groups {
+		row := []string{dashIfEmpty(g.Model), dashIfEmpty(g.IDRACFirmwareVersion), fmt.Sprintf("%d", g.Servers)}
+		for _, phase := range phases {
+			row = append(row, capabilityCell(g.Phases[phase]))
+		}
+		r.Rows = append(r.Rows, row)
+	}
+	return r
+}
+
+func capabilityCell(c models.CapabilityCount) string {
+	switch {
+	case c.Checked() == 0:
+		return "-"
+	case c.Supported == c.Checked():
+		return "yes"
+	case c.Unsupported == c.Checked():
+		return "no"
+	}
+	cell := fmt.Sprintf("%d/%d", c.Supported+c.Degraded, c.Checked())
+	if c.Degraded > 0 {
+		cell += fmt.Sprintf(" (%d degraded)", c.Degraded)
+	}
+	return cell
+}
+
+// ComponentSearchReport lists the components found by serial or part
+// number, with the server and slot containing them.
+func ComponentSearchReport(matches []models.ComponentMatch) Report {
+	r := Report{
+		Title:   "Component Search",
+		Headers: []string{"Query", "Host", "Name", "Service Tag", "Component", "Slot", "Model", "Serial", "Part Number", "Scanned At"},
+		Data:    matches,
+	}
+	for _, m := range matches {
+		scannedAt := "-"
+		if !m.CollectedAt.IsZero() {
+			scannedAt = m.CollectedAt.Format("2006-01-02 15:04")
+		}
+		r.Rows = append(r.Rows, []string{
+			m.Query,
+			m.Host,
+			dashIfEmpty(m.Name),
+			dashIfEmpty(m.ServiceTag),
+			m.Component,
+			dashIfEmpty(m.Slot),
+			dashIfEmpty(m.Model),
+			dashIfEmpty(m.SerialNumber),
+			dashIfEmpty(m.PartNumber),
+			scannedAt,
+		})
+	}
+	return r
+}
+
+// NVMeWearReport lists the NVMe drives, most worn first, with their life
+// and endurance left and PCIe link. Degraded links are marked.
+func NVMeWearReport(drives []models.NVMeWear) Report {
+	r := Report{
+		Title:   "NVMe Wear",
+		Headers: []string{"Host", "Service Tag", "Drive", "Model", "Serial", "Health", "Life Left", "Endurance Left", "Failure Predicted", "Link", "Namespaces"},
+		Data:    drives,
+	}
+	percent := func(v float64) string {
+		if v <= 0 {
+			return "-"
+		}
+		return fmt.Sprintf("%.0f%%", v)
+	}
+	for _, d := range drives {
+		endurance := "-"
+		if d.EnduranceLeftPct != nil {
+			endurance = fmt.Sprintf("%d%%", *d.EnduranceLeftPct)
+		}
+		predicted := "no"
+		if d.FailurePredicted {
+			predicted = "yes"
+		}
+		link := dashIfEmpty(d.Link)
+		if d.LinkDegraded {
+			link += " (degraded)"
+		}
+		namespaces := "-"
+		if d.Namespaces > 0 {
+			namespaces = fmt.Sprintf("%d", d.Namespaces)
+		}
+		r.Rows = append(r.Rows, []string{
+			d.Host,
+			dashIfEmpty(d.ServiceTag),
+			d.Drive,
+			dashIfEmpty(d.Model),
+			dashIfEmpty(d.SerialNumber),
+			dashIfEmpty(d.Health),
+			percent(d.LifeLeftPct),
+			endurance,
+			predicted,
+			link,
+			namespaces,
+		})
+	}
+	return r
+}
+
+// AccountAuditReport lists the iDRACs with risky account configurations,
+// most risks first.
+func AccountAuditReport(hosts []models.AccountRisk) Report {
+	r := Report{
+		Title:   "iDRAC Account Audit",
+		Headers: []string{"Host", "Service Tag", "Enabled Users", "Administrators", "Default Password", "Risks"},
+		Data:    hosts,
+	}
+	for _, h := range hosts {
+		defaultPassword := "not probed"
+		if h.DefaultCredentials != nil {
+			defaultPassword = "rejected"
+			if *h.DefaultCredentials {
+				defaultPassword = "accepted"
+			}
+		}
+		r.Rows = append(r.Rows, []string{
+			h.Host,
+			dashIfEmpty(h.ServiceTag),
+			dashIfEmpty(strings.Join(h.EnabledUsers, ", ")),
+			dashIfEmpty(strings.Join(h.Administrators, ", ")),
+			defaultPassword,
+			strings.Join(h.Risks, "; "),
+		})
+	}
+	return r
+}
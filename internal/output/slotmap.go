@@ -0,0 +1,69 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+// Marks of the slots in slot diagrams.
+const (
+	slotUsed   = "■"
+	slotFree   = "□"
+	slotFailed = "✗"
+)
+
+// slotsPerLine is the number of slots drawn per line; longer rows wrap.
+const slotsPerLine = 12
+
+// slotLegend explains the marks of slot diagrams.
+var slotLegend = fmt.Sprintf("%s used  %s free  %s failed or disabled", slotUsed, slotFree, slotFailed)
+
+// writeSlotMap draws a slot map as a text grid, each line prefixed with
+// indent, with free slots dimmed and failed slots in red by style, e.g.
+//
+//	Drive bays: BP15G+ 8x2.5 (Enclosure.Internal.0-1), 6 of 8 free
+//	  [0 ■] [1 ■] [2 □] [3 □] [4 □] [5 □] [6 □] [7 □]
+func writeSlotMap(w io.Writer, m models.SlotMap, indent string, style Style) {
+	fmt.Fprintf(w, "%s%s, %d of %d free\n", indent, m.Title, m.Free(), m.Total())
+
+	labelWidth, rowWidth := 0, 0
+	for _, r := range m.Rows {
+		rowWidth = max(rowWidth, len(r.Label))
+		for _, s := range r.Slots {
+			labelWidth = max(labelWidth, len(s.Label))
+		}
+	}
+
+	for _, r := range m.Rows {
+		for start := 0; start < len(r.Slots); start += slotsPerLine {
+			label := ""
+			if start == 0 {
+				label = r.Label
+			}
+			cells := make([]string, 0, slotsPerLine)
+			for _, s := range r.Slots[start:min(start+slotsPerLine, len(r.Slots))] {
+				var cell string
+				switch {
+				case s.Failed:
+					cell = style.Critical(fmt.Sprintf("[%*s %s]", labelWidth, s.Label, slotFailed))
+				case s.Used:
+					cell = fmt.Sprintf("[%*s %s]", labelWidth, s.Label, slotUsed)
+				default:
+					cell = style.Dim(fmt.Sprintf("[%*s %s]", labelWidth, s.Label, slotFree))
+				}
+				cells = append(cells, cell)
+			}
+			if rowWidth > 0 {
+				fmt.Fprintf(w, "%s  %-*s  %s\n", indent, rowWidth, label, strings.Join(cells, " "))
+			} else {
+				fmt.Fprintf(w, "%s  %s\n", indent, strings.Join(cells, " "))
+			}
+		}
+	}
+	if m.Unplaced > 0 {
+		fmt.Fprintf(w, "%s  (%d drive(s) in unknown bays)\n", indent, m.Unplaced)
+	}
+}
@@ -0,0 +1,60 @@
+package output
+
+import (
+	"os"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+// ANSI escape sequences of the console styles.
+const (
+	ansiReset  = "\033[0m"
+	ansiBold   = "\033[1m"
+	ansiDim    = "\033[2m"
+	ansiRed    = "\033[31m"
+	ansiYellow = "\033[33m"
+)
+
+// Style highlights console text by severity with ANSI colors: red for
+// critical, yellow for warnings, dim for empty slots. The zero value
+// renders plain text.
+type Style struct {
+	Color bool
+}
+
+// NewStyle returns the console style: colored unless noColor (-no-color) or
+// the NO_COLOR environment variable (https://no-color.org) is set.
+func NewStyle(noColor bool) Style {
+	return Style{Color: !noColor && os.Getenv("NO_COLOR") == ""}
+}
+
+func (s Style) wrap(code, text string) string {
+	if !s.Color || text == "" {
+		return text
+	}
+	return code + text + ansiReset
+}
+
+// Bold renders headings.
+func (s Style) Bold(text string) string { return s.wrap(ansiBold, text) }
+
+// Dim renders empty slots and other secondary text.
+func (s Style) Dim(text string) string { return s.wrap(ansiDim, text) }
+
+// Critical renders failures and Critical health in red.
+func (s Style) Critical(text string) string { return s.wrap(ansiRed, text) }
+
+// Warning renders warnings and Warning health in yellow.
+func (s Style) Warning(text string) string { return s.wrap(ansiYellow, text) }
+
+// Health renders text in the color of a health status; other statuses
+// (OK, unknown) are left plain.
+func (s Style) Health(health, text string) string {
+	switch health {
+	case models.HealthCritical:
+		return s.Critical(text)
+	case models.HealthWarning:
+		return s.Warning(text)
+	}
+	return text
+}
@@ -0,0 +1,105 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+// sparkWidth is the maximum number of characters of a sparkline; longer
+// series are averaged into this many buckets.
+const sparkWidth = 30
+
+// maxTrendBar is the maximum length of a bar in the drive failure table.
+const maxTrendBar = 40
+
+var sparkChars = []rune("▁▂▃▄▅▆▇█")
+
+// writeTrends writes the fleet trends: a sparkline with the first and last
+// value per metric, and the drive failures per month.
+func (f *MarkdownFormatter) writeTrends(w io.Writer, samples []models.TrendSample) {
+	first, last := samples[0], samples[len(samples)-1]
+	const day = "2006-01-02"
+
+	fmt.Fprintf(w, "### Trends\n\n")
+	fmt.Fprintf(w, "| Metric | Trend | %s | %s | Change |\n", first.At.Format(day), last.At.Format(day))
+	fmt.Fprintf(w, "|--------|-------|------------|------------|--------|\n")
+
+	series := func(value func(models.TrendSample) float64) []float64 {
+		values := make([]float64, len(samples))
+		for i, s := range samples {
+			values[i] = value(s)
+		}
+		return values
+	}
+	servers := series(func(s models.TrendSample) float64 { return float64(s.Servers) })
+	memory := series(func(s models.TrendSample) float64 { return s.MemoryGiB })
+	power := series(func(s models.TrendSample) float64 { return float64(s.PowerWatts) })
+
+	fmt.Fprintf(w, "| Servers | `%s` | %d | %d | %s |\n",
+		sparkline(servers), first.Servers, last.Servers, signed(float64(last.Servers-first.Servers), func(v float64) string {
+			return fmt.Sprintf("%d", int(v))
+		}))
+	fmt.Fprintf(w, "| Memory | `%s` | %s | %s | %s |\n",
+		sparkline(memory), f.Units.Gigabytes(first.MemoryGiB), f.Units.Gigabytes(last.MemoryGiB),
+		signed(last.MemoryGiB-first.MemoryGiB, f.Units.Gigabytes))
+	fmt.Fprintf(w, "| Power draw | `%s` | %s W | %s W | %s |\n",
+		sparkline(power), formatWithCommas(first.PowerWatts), formatWithCommas(last.PowerWatts),
+		signed(float64(last.PowerWatts-first.PowerWatts), func(v float64) string {
+			return formatWithCommas(int(v)) + " W"
+		}))
+	fmt.Fprintf(w, "\n")
+
+	fmt.Fprintf(w, "#### Drive Failures per Month\n\n")
+	fmt.Fprintf(w, "| Month | Failures | |\n")
+	fmt.Fprintf(w, "|-------|----------|-|\n")
+	for _, m := range models.MonthlyDriveFailures(samples) {
+		fmt.Fprintf(w, "| %s | %d | %s |\n", m.Month, m.Count, strings.Repeat("█", min(m.Count, maxTrendBar)))
+	}
+	fmt.Fprintf(w, "\n")
+}
+
+// sparkline renders values as a line of block characters scaled between
+// their minimum and maximum.
+func sparkline(values []float64) string {
+	if len(values) > sparkWidth {
+		buckets := make([]float64, sparkWidth)
+		for i := range buckets {
+			from, to := i*len(values)/sparkWidth, (i+1)*len(values)/sparkWidth
+			sum := 0.0
+			for _, v := range values[from:to] {
+				sum += v
+			}
+			buckets[i] = sum / float64(to-from)
+		}
+		values = buckets
+	}
+
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+	var b strings.Builder
+	for _, v := range values {
+		i := len(sparkChars) / 2
+		if hi > lo {
+			i = int((v - lo) / (hi - lo) * float64(len(sparkChars)-1))
+		}
+		b.WriteRune(sparkChars[i])
+	}
+	return b.String()
+}
+
+// signed formats a change with its sign, or "±0".
+func signed(delta float64, format func(float64) string) string {
+	switch {
+	case delta > 0:
+		return "+" + format(delta)
+	case delta < 0:
+		return "−" + format(-delta)
+	}
+	return "±0"
+}
@@ -0,0 +1,167 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+// ValidationFormatter renders the results of a connection validation run.
+// All standard formatters implement it.
+type ValidationFormatter interface {
+	FormatValidation(w io.Writer, checks []models.ConnectionCheck) error
+}
+
+// certWarnDays highlights certificates expiring within this many days.
+const certWarnDays = 30
+
+// FormatValidation outputs validation results in a human-readable console format.
+func (f *ConsoleFormatter) FormatValidation(w io.Writer, checks []models.ConnectionCheck) error {
+	now := time.Now()
+	okCount := 0
+
+	for _, c := range checks {
+		if !c.OK() {
+			fmt.Fprintf(w, "%s %s: %v\n", f.statusIcon(false), c.Host, c.Error)
+			continue
+		}
+		okCount++
+
+		fmt.Fprintf(w, "%s %s: OK (Redfish %s, firmware %s, %s)\n",
+			f.statusIcon(true), c.Host,
+			f.valueOrNA(c.RedfishVersion), f.valueOrNA(c.FirmwareVersion),
+			c.Latency.Round(time.Millisecond))
+
+		if days, ok := c.CertDaysLeft(now); ok {
+			warn := ""
+			if days < certWarnDays {
+				warn = " " + f.icon("⚠️") + " expiring soon"
+			}
+			fmt.Fprintf(w, "   └─ TLS cert: %s, expires %s (%d days)%s\n",
+				f.valueOrNA(c.Certificate.Subject), c.Certificate.NotAfter.Format("2006-01-02"), days, warn)
+		}
+		if clock := c.Clock; clock != nil && clock.Drifted() {
+			fmt.Fprintf(w, "   └─ %s %s (more than %s): TLS validity and SEL timestamps are unreliable\n",
+				f.icon("⚠️"), clock, time.Duration(clock.MaxDriftSeconds*float64(time.Second)))
+		}
+	}
+
+	fmt.Fprintf(w, "\nValidation complete: %d/%d successful\n", okCount, len(checks))
+	return nil
+}
+
+func (f *ConsoleFormatter) statusIcon(ok bool) string {
+	if f.NoColor {
+		if ok {
+			return "[OK]"
+		}
+		return "[FAIL]"
+	}
+	if ok {
+		return "✅"
+	}
+	return "❌"
+}
+
+// FormatValidation outputs validation results as JSON.
+func (f *JSONFormatter) FormatValidation(w io.Writer, checks []models.ConnectionCheck) error {
+	output := struct {
+		Checks []models.ConnectionCheck `json:"checks"`
+	}{
+		Checks: checks,
+	}
+
+	encoder := json.NewEncoder(w)
+	if f.Indent {
+		encoder.SetIndent("", "  ")
+	}
+
+	return encoder.Encode(output)
+}
+
+// FormatValidation outputs validation results as a table.
+func (f *TableFormatter) FormatValidation(w io.Writer, checks []models.ConnectionCheck) error {
+	now := time.Now()
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "HOST\tSTATUS\tREDFISH\tFIRMWARE\tLATENCY\tCERT EXPIRES\tDAYS LEFT\tCLOCK DRIFT\tERROR")
+	fmt.Fprintln(tw, "----\t------\t-------\t--------\t-------\t------------\t---------\t-----------\t-----")
+
+	for _, c := range checks {
+		status, errMsg := "OK", "-"
+		if !c.OK() {
+			status, errMsg = "ERROR", c.Error.Error()
+		}
+		expires, daysLeft := "-", "-"
+		if days, ok := c.CertDaysLeft(now); ok {
+			expires = c.Certificate.NotAfter.Format("2006-01-02")
+			daysLeft = fmt.Sprintf("%d", days)
+		}
+		drift := "-"
+		if c.Clock != nil {
+			drift = c.Clock.Drift().String()
+			if c.Clock.Drifted() {
+				drift += " (drifted)"
+			}
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			c.Host,
+			status,
+			dashIfEmpty(c.RedfishVersion),
+			dashIfEmpty(c.FirmwareVersion),
+			c.Latency.Round(time.Millisecond),
+			expires,
+			daysLeft,
+			drift,
+			errMsg,
+		)
+	}
+
+	return tw.Flush()
+}
+
+// FormatValidation outputs validation results as CSV.
+func (f *CSVFormatter) FormatValidation(w io.Writer, checks []models.ConnectionCheck) error {
+	now := time.Now()
+	fmt.Fprintln(w, "host,name,status,redfish_version,firmware_version,latency_ms,cert_subject,cert_not_after,cert_days_left,clock_drift_seconds,clock_drifted,error")
+
+	for _, c := range checks {
+		status, errMsg := "OK", ""
+		if !c.OK() {
+			status, errMsg = "ERROR", c.Error.Error()
+		}
+		subject, notAfter, daysLeft := "", "", ""
+		if days, ok := c.CertDaysLeft(now); ok {
+			subject = c.Certificate.Subject
+			notAfter = c.Certificate.NotAfter.Format(time.RFC3339)
+			daysLeft = fmt.Sprintf("%d", days)
+		}
+		drift, drifted := "", ""
+		if c.Clock != nil {
+			drift = fmt.Sprintf("%.0f", c.Clock.DriftSeconds)
+			drifted = fmt.Sprintf("%t", c.Clock.Drifted())
+		}
+
+		fmt.Fprintf(w, "%s,%s,%s,%s,%s,%d,%s,%s,%s,%s,%s,%s\n",
+			csvEscape(c.Host),
+			csvEscape(c.Name),
+			status,
+			csvEscape(c.RedfishVersion),
+			csvEscape(c.FirmwareVersion),
+			c.Latency.Milliseconds(),
+			csvEscape(subject),
+			notAfter,
+			daysLeft,
+			drift,
+			drifted,
+			csvEscape(errMsg),
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package output
+
+import "unicode/utf8"
+
+// defaultConsoleWidth is the width of the console layout when the terminal
+// width is unknown, e.g. when the output is redirected.
+const defaultConsoleWidth = 72
+
+// minConsoleWidth is the narrowest console layout.
+const minConsoleWidth = 40
+
+// minColumnWidth is the narrowest a table column is truncated to.
+const minColumnWidth = 8
+
+// truncate shortens s to at most n characters, marking the cut with "…".
+func truncate(s string, n int) string {
+	if n <= 0 || utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	r := []rune(s)
+	return string(r[:n-1]) + "…"
+}
+
+// fitColumns truncates the cells of the shrinkable columns so that the rows,
+// with padding spaces between columns, fit into width. The widest of the
+// shrinkable columns is shortened first, none below minColumnWidth or its
+// header (the first row); if that is not enough, the rows stay wider than
+// width.
+func fitColumns(rows [][]string, width, padding int, shrinkable ...int) {
+	if width <= 0 || len(rows) == 0 {
+		return
+	}
+	widths := make([]int, len(rows[0]))
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], utf8.RuneCountInString(cell))
+		}
+	}
+	total := padding * (len(widths) - 1)
+	for _, w := range widths {
+		total += w
+	}
+
+	for total > width {
+		widest := -1
+		for _, i := range shrinkable {
+			narrowest := max(minColumnWidth, utf8.RuneCountInString(rows[0][i]))
+			if widths[i] > narrowest && (widest < 0 || widths[i] > widths[widest]) {
+				widest = i
+			}
+		}
+		if widest < 0 {
+			break
+		}
+		widths[widest]--
+		total--
+	}
+
+	for _, row := range rows {
+		for _, i := range shrinkable {
+			row[i] = truncate(row[i], widths[i])
+		}
+	}
+}
@@ -0,0 +1,193 @@
+// Package placement merges physical placement data (site, rack, U position,
+// tenant) recorded during racking into scan results, keyed by service tag.
+//
+// The placement file is CSV with a header row or YAML:
+//
+//	service_tag,site,rack,position,face,tenant
+//	ABC1234,fra1,R12,20,front,acme
+//
+//	placements:
+//	  - service_tag: ABC1234
+//	    site: fra1
+//	    rack: R12
+//	    position: 20
+package placement
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/braunma/idrac-netbox-importer/internal/csvtable"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+// Entry is one row of a placement file.
+type Entry struct {
+	ServiceTag       string `yaml:"service_tag"`
+	models.Placement `yaml:",inline"`
+}
+
+// Map holds the placement of each service tag.
+type Map struct {
+	byTag map[string]models.Placement
+}
+
+// Load reads a placement file. Files ending in .yaml or .yml are parsed as
+// YAML, everything else as CSV.
+func Load(path string) (*Map, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read placement file: %w", err)
+	}
+	defer file.Close()
+
+	var entries []Entry
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		entries, err = parseYAML(file)
+	default:
+		entries, err = parseCSV(file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	m, err := New(entries)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return m, nil
+}
+
+// New builds a Map from entries. Service tags must be unique.
+func New(entries []Entry) (*Map, error) {
+	m := &Map{byTag: make(map[string]models.Placement, len(entries))}
+	for i, e := range entries {
+		tag := normalizeTag(e.ServiceTag)
+		if err := e.validate(); err != nil {
+			return nil, fmt.Errorf("entry %d: %w", i+1, err)
+		}
+		if _, dup := m.byTag[tag]; dup {
+			return nil, fmt.Errorf("entry %d: duplicate service tag %s", i+1, tag)
+		}
+		p := e.Placement
+		if p.Position > 0 && p.Face == "" {
+			p.Face = "front"
+		}
+		m.byTag[tag] = p
+	}
+	return m, nil
+}
+
+func (e Entry) validate() error {
+	if normalizeTag(e.ServiceTag) == "" {
+		return fmt.Errorf("service_tag is required")
+	}
+	if e.IsEmpty() {
+		return fmt.Errorf("%s: no placement attributes", e.ServiceTag)
+	}
+	if e.Rack != "" && e.Site == "" {
+		return fmt.Errorf("%s: rack requires a site", e.ServiceTag)
+	}
+	if e.Position != 0 {
+		if e.Rack == "" {
+			return fmt.Errorf("%s: position requires a rack", e.ServiceTag)
+		}
+		if e.Position < 1 || e.Position*2 != float64(int(e.Position*2)) {
+			return fmt.Errorf("%s: position must be a U number ≥ 1 (multiple of 0.5)", e.ServiceTag)
+		}
+	}
+	switch e.Face {
+	case "", "front", "rear":
+	default:
+		return fmt.Errorf("%s: face must be front or rear", e.ServiceTag)
+	}
+	return nil
+}
+
+// Len returns the number of service tags with a placement.
+func (m *Map) Len() int {
+	return len(m.byTag)
+}
+
+// Lookup returns the placement of a service tag (case-insensitive).
+func (m *Map) Lookup(serviceTag string) (models.Placement, bool) {
+	p, ok := m.byTag[normalizeTag(serviceTag)]
+	return p, ok
+}
+
+// Apply sets the placement of every result whose service tag is listed and
+// returns the number of results that got one.
+func (m *Map) Apply(results []models.ServerInfo) int {
+	applied := 0
+	for i := range results {
+		if m.ApplyOne(&results[i]) {
+			applied++
+		}
+	}
+	return applied
+}
+
+// ApplyOne sets the placement of res if its service tag is listed and
+// reports whether it did; the stream output calls it per scanned host.
+func (m *Map) ApplyOne(res *models.ServerInfo) bool {
+	if res.ServiceTag == "" {
+		return false
+	}
+	p, ok := m.Lookup(res.ServiceTag)
+	if !ok {
+		return false
+	}
+	res.Placement = &p
+	return true
+}
+
+func parseYAML(r io.Reader) ([]Entry, error) {
+	var f struct {
+		Placements []Entry `yaml:"placements"`
+	}
+	if err := yaml.NewDecoder(r).Decode(&f); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("invalid placement YAML: %w", err)
+	}
+	return f.Placements, nil
+}
+
+// parseCSV reads placements from CSV with a service_tag column and the
+// optional site, rack, position, face and tenant columns.
+func parseCSV(r io.Reader) ([]Entry, error) {
+	t, err := csvtable.Read(r, "placement")
+	if err != nil {
+		return nil, err
+	}
+	if err := t.Require("service_tag"); err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	for n := 0; n < t.Len(); n++ {
+		get := func(col string) string { return t.Get(n, col) }
+		e := Entry{ServiceTag: get("service_tag"), Placement: models.Placement{
+			Site:   get("site"),
+			Rack:   get("rack"),
+			Face:   strings.ToLower(get("face")),
+			Tenant: get("tenant"),
+		}}
+		if pos := get("position"); pos != "" {
+			if e.Position, err = strconv.ParseFloat(pos, 64); err != nil {
+				return nil, fmt.Errorf("row %d: invalid position %q", n+1, pos)
+			}
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+func normalizeTag(tag string) string {
+	return strings.ToUpper(strings.TrimSpace(tag))
+}
@@ -0,0 +1,93 @@
+package placement
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoad_CSV(t *testing.T) {
+	path := writeFile(t, "placement.csv", `# racked 2024-05
+Service_Tag,Site,Rack,Position,Face,Tenant,Notes
+abc1234, fra1, R12, 20, , acme, cable 3
+DEF5678,fra1,,,,,
+`)
+	m, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, 2, m.Len())
+
+	p, ok := m.Lookup("ABC1234")
+	require.True(t, ok)
+	assert.Equal(t, models.Placement{Site: "fra1", Rack: "R12", Position: 20, Face: "front", Tenant: "acme"}, p)
+
+	p, ok = m.Lookup("def5678")
+	require.True(t, ok)
+	assert.Equal(t, models.Placement{Site: "fra1"}, p)
+}
+
+func TestLoad_YAML(t *testing.T) {
+	path := writeFile(t, "placement.yaml", `placements:
+  - service_tag: ABC1234
+    site: fra1
+    rack: R12
+    position: 20.5
+    face: rear
+`)
+	m, err := Load(path)
+	require.NoError(t, err)
+
+	p, ok := m.Lookup("ABC1234")
+	require.True(t, ok)
+	assert.Equal(t, 20.5, p.Position)
+	assert.Equal(t, "rear", p.Face)
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	for name, content := range map[string]string{
+		"no service_tag column": "site,rack\nfra1,R1\n",
+		"bad position":          "service_tag,site,rack,position\nABC,fra1,R1,top\n",
+		"missing tag":           "service_tag,site\n,fra1\n",
+		"no attributes":         "service_tag,site\nABC,\n",
+		"rack without site":     "service_tag,rack\nABC,R1\n",
+		"position without rack": "service_tag,site,position\nABC,fra1,4\n",
+		"position zero-ish":     "service_tag,site,rack,position\nABC,fra1,R1,0.3\n",
+		"bad face":              "service_tag,site,rack,position,face\nABC,fra1,R1,4,top\n",
+		"duplicate":             "service_tag,site\nABC,fra1\nabc,fra2\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := Load(writeFile(t, "placement.csv", content))
+			assert.Error(t, err)
+		})
+	}
+
+	_, err := Load(filepath.Join(t.TempDir(), "missing.csv"))
+	assert.Error(t, err)
+}
+
+func TestMap_Apply(t *testing.T) {
+	m, err := New([]Entry{{ServiceTag: "ABC1234", Placement: models.Placement{Site: "fra1"}}})
+	require.NoError(t, err)
+
+	results := []models.ServerInfo{
+		{Host: "10.0.0.1", ServiceTag: "abc1234"},
+		{Host: "10.0.0.2", ServiceTag: "ZZZ9999"},
+		{Host: "10.0.0.3"},
+	}
+	assert.Equal(t, 1, m.Apply(results))
+	require.NotNil(t, results[0].Placement)
+	assert.Equal(t, "fra1", results[0].Placement.Site)
+	assert.Nil(t, results[1].Placement)
+	assert.Nil(t, results[2].Placement)
+}
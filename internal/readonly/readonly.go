@@ -0,0 +1,39 @@
+// Package readonly enforces read-only mode at the HTTP client layer: any
+// request that could change state on a remote system is refused before it
+// leaves the process, regardless of which code path issued it.
+package readonly
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/errors"
+)
+
+// Transport wraps rt so that only GET, HEAD and OPTIONS requests are sent.
+// Other methods fail with errors.ErrReadOnly. A nil rt uses http.DefaultTransport.
+func Transport(rt http.RoundTripper) http.RoundTripper {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	if _, ok := rt.(*transport); ok {
+		return rt
+	}
+	return &transport{next: rt}
+}
+
+type transport struct {
+	next http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return t.next.RoundTrip(req)
+	}
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	return nil, fmt.Errorf("%s %s: %w", req.Method, req.URL.Redacted(), errors.ErrReadOnly)
+}
@@ -0,0 +1,37 @@
+package readonly
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/errors"
+)
+
+func TestTransport(t *testing.T) {
+	var writes int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writes++
+		}
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: Transport(nil)}
+
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	req, err := http.NewRequest(http.MethodPatch, server.URL, strings.NewReader(`{}`))
+	require.NoError(t, err)
+	_, err = client.Do(req)
+
+	require.Error(t, err)
+	assert.ErrorIs(t, err, errors.ErrReadOnly)
+	assert.Zero(t, writes)
+}
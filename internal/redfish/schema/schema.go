@@ -0,0 +1,272 @@
+// Package schema validates Redfish responses against a bundled subset of the
+// DMTF Redfish schemas: the properties of the resources the scanner reads,
+// with their JSON types, nullability and enumerations. The strict schema mode
+// (http.strict_schema) uses it to diagnose firmware that returns values the
+// scanner would otherwise mis-parse silently (a string where an integer is
+// expected reads as an error or as 0).
+//
+// The subset is a small JSON schema dialect: type (a name or a list of names,
+// "null" included), properties, required, items, enum and $ref, which names
+// another top-level entry. A node with both $ref and properties is checked
+// against both, so resources extend the common Resource properties. Unknown
+// properties are allowed, since every vendor adds OEM properties.
+package schema
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/braunma/idrac-netbox-importer/internal/redfish"
+)
+
+//go:embed redfish.json
+var bundled []byte
+
+// Node is a schema or the schema of a property.
+type Node struct {
+	Ref        string           `json:"$ref,omitempty"`
+	Type       Types            `json:"type,omitempty"`
+	Properties map[string]*Node `json:"properties,omitempty"`
+	Required   []string         `json:"required,omitempty"`
+	Items      *Node            `json:"items,omitempty"`
+	Enum       []string         `json:"enum,omitempty"`
+}
+
+// Types are the JSON types a value may have: "object", "array", "string",
+// "integer", "number", "boolean" or "null". Empty allows any type.
+type Types []string
+
+// UnmarshalJSON accepts a single type name or a list of names.
+func (t *Types) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*t = Types{name}
+		return nil
+	}
+	var names []string
+	if err := json.Unmarshal(data, &names); err != nil {
+		return fmt.Errorf("type must be a string or a list of strings")
+	}
+	*t = names
+	return nil
+}
+
+func (t Types) allows(kind string) bool {
+	if len(t) == 0 {
+		return true
+	}
+	for _, name := range t {
+		if name == kind || (name == "number" && kind == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// Violation is a property of a response that does not match its schema.
+type Violation struct {
+	// Path is the property, e.g. "MemorySummary.TotalSystemMemoryGiB" or
+	// "Members[3].@odata.id"; empty for the response itself.
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+func (v Violation) String() string {
+	if v.Path == "" {
+		return v.Message
+	}
+	return v.Path + ": " + v.Message
+}
+
+// Validator checks responses against a set of schemas.
+type Validator struct {
+	schemas map[string]*Node
+}
+
+var (
+	defaultOnce      sync.Once
+	defaultValidator *Validator
+)
+
+// Default returns the validator of the bundled schemas.
+func Default() *Validator {
+	defaultOnce.Do(func() {
+		v, err := Load(bundled)
+		if err != nil {
+			panic(fmt.Sprintf("bundled Redfish schemas: %v", err))
+		}
+		defaultValidator = v
+	})
+	return defaultValidator
+}
+
+// Load parses schemas keyed by Redfish resource type (the schema name of
+// @odata.type, e.g. "ComputerSystem"). Every $ref must name one of them.
+func Load(data []byte) (*Validator, error) {
+	var schemas map[string]*Node
+	if err := json.Unmarshal(data, &schemas); err != nil {
+		return nil, fmt.Errorf("failed to parse schemas: %w", err)
+	}
+	v := &Validator{schemas: schemas}
+	for name, node := range schemas {
+		if err := v.checkRefs(name, node); err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
+
+func (v *Validator) checkRefs(path string, n *Node) error {
+	if n == nil {
+		return nil
+	}
+	if n.Ref != "" && v.schemas[n.Ref] == nil {
+		return fmt.Errorf("%s: unknown $ref %q", path, n.Ref)
+	}
+	for name, p := range n.Properties {
+		if err := v.checkRefs(path+"."+name, p); err != nil {
+			return err
+		}
+	}
+	return v.checkRefs(path+"[]", n.Items)
+}
+
+// Resources returns the names of the resource types with a schema, sorted.
+func (v *Validator) Resources() []string {
+	names := make([]string, 0, len(v.schemas))
+	for name := range v.schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Validate checks a response body against the schema of its @odata.type
+// and returns the resource type and the violations found. Collections
+// without a schema of their own are checked against "Collection". Bodies
+// without @odata.type or of a type without a schema are not checked and
+// return no violations; a body that is not a JSON object is a violation.
+func (v *Validator) Validate(body []byte) (resource string, violations []Violation) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return "", []Violation{{Message: fmt.Sprintf("invalid JSON: %v", err)}}
+	}
+	obj, ok := doc.(map[string]interface{})
+	if !ok {
+		return "", []Violation{{Message: fmt.Sprintf("expected object, got %s", describe(doc))}}
+	}
+
+	odataType, _ := obj["@odata.type"].(string)
+	resource = redfish.ResourceType(odataType)
+	node := v.schemas[resource]
+	if node == nil && strings.HasSuffix(resource, "Collection") {
+		node = v.schemas["Collection"]
+	}
+	if node == nil {
+		return resource, nil
+	}
+	v.check("", doc, node, &violations)
+	return resource, violations
+}
+
+func (v *Validator) check(path string, value interface{}, n *Node, out *[]Violation) {
+	if n.Ref != "" {
+		v.check(path, value, v.schemas[n.Ref], out)
+	}
+
+	kind := kindOf(value)
+	if !n.Type.allows(kind) {
+		*out = append(*out, Violation{Path: path, Message: fmt.Sprintf("expected %s, got %s", strings.Join(n.Type, " or "), describe(value))})
+		return
+	}
+
+	switch val := value.(type) {
+	case string:
+		if len(n.Enum) > 0 && !contains(n.Enum, val) {
+			*out = append(*out, Violation{Path: path, Message: fmt.Sprintf("%q is not one of %s", val, strings.Join(n.Enum, ", "))})
+		}
+	case map[string]interface{}:
+		for _, name := range n.Required {
+			if _, ok := val[name]; !ok {
+				*out = append(*out, Violation{Path: join(path, name), Message: "missing required property"})
+			}
+		}
+		// Sorted for a stable order of violations
+		names := make([]string, 0, len(n.Properties))
+		for name := range n.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if pv, ok := val[name]; ok {
+				v.check(join(path, name), pv, n.Properties[name], out)
+			}
+		}
+	case []interface{}:
+		if n.Items != nil {
+			for i, item := range val {
+				v.check(fmt.Sprintf("%s[%d]", path, i), item, n.Items, out)
+			}
+		}
+	}
+}
+
+// kindOf returns the JSON type of a value decoded with UseNumber.
+func kindOf(value interface{}) string {
+	switch val := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		if strings.ContainsAny(val.String(), ".eE") {
+			return "number"
+		}
+		return "integer"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+// describe returns the type of a value and, for scalars, the value itself.
+func describe(value interface{}) string {
+	switch val := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		if len(val) > 40 {
+			val = val[:40] + "..."
+		}
+		return fmt.Sprintf("string %q", val)
+	case json.Number, bool:
+		return fmt.Sprintf("%s %v", kindOf(value), val)
+	}
+	return kindOf(value)
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
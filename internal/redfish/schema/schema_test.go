@@ -0,0 +1,94 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefault(t *testing.T) {
+	v := Default()
+	for _, name := range []string{"ServiceRoot", "ComputerSystem", "Processor", "Memory", "Storage", "Drive", "Manager", "Collection"} {
+		assert.Contains(t, v.Resources(), name)
+	}
+}
+
+func TestValidate_Conforming(t *testing.T) {
+	resource, violations := Default().Validate([]byte(`{
+		"@odata.id": "/redfish/v1/Systems/System.Embedded.1/Memory/DIMM.Socket.A1",
+		"@odata.type": "#Memory.v1_11_0.Memory",
+		"Id": "DIMM.Socket.A1",
+		"Name": "DIMM A1",
+		"CapacityMiB": 32768,
+		"OperatingSpeedMhz": null,
+		"AllowedSpeedsMHz": [3200],
+		"MemoryLocation": {"Socket": 1, "Slot": 1},
+		"Status": {"Health": "OK", "State": "Enabled"},
+		"Oem": {"Dell": {"DellMemory": {"Rank": "Double"}}}
+	}`))
+	assert.Equal(t, "Memory", resource)
+	assert.Empty(t, violations)
+}
+
+func TestValidate_Violations(t *testing.T) {
+	resource, violations := Default().Validate([]byte(`{
+		"@odata.id": "/redfish/v1/Systems/System.Embedded.1",
+		"@odata.type": "#ComputerSystem.v1_20_0.ComputerSystem",
+		"Name": "System",
+		"PowerState": "Standby",
+		"MemorySummary": {"TotalSystemMemoryGiB": "512"},
+		"ProcessorSummary": {"Count": 2.0},
+		"Links": {"Chassis": [{"@odata.id": "/redfish/v1/Chassis/System.Embedded.1"}, {}]},
+		"Status": {"Health": "Ok"}
+	}`))
+	assert.Equal(t, "ComputerSystem", resource)
+	assert.Equal(t, []Violation{
+		{Path: "Id", Message: "missing required property"},
+		{Path: "Links.Chassis[1].@odata.id", Message: "missing required property"},
+		{Path: "MemorySummary.TotalSystemMemoryGiB", Message: `expected number or null, got string "512"`},
+		{Path: "PowerState", Message: `"Standby" is not one of On, Off, PoweringOn, PoweringOff, Paused`},
+		{Path: "ProcessorSummary.Count", Message: "expected integer or null, got number 2.0"},
+		{Path: "Status.Health", Message: `"Ok" is not one of OK, Warning, Critical`},
+	}, violations)
+}
+
+func TestValidate_Collection(t *testing.T) {
+	resource, violations := Default().Validate([]byte(`{
+		"@odata.id": "/redfish/v1/Systems/System.Embedded.1/Processors",
+		"@odata.type": "#ProcessorCollection.ProcessorCollection",
+		"Name": "Processors",
+		"Members@odata.count": "2",
+		"Members": [{"@odata.id": "/cpu/1"}, "/cpu/2"]
+	}`))
+	assert.Equal(t, "ProcessorCollection", resource)
+	assert.Equal(t, []Violation{
+		{Path: "Members[1]", Message: `expected object, got string "/cpu/2"`},
+		{Path: "Members@odata.count", Message: `expected integer, got string "2"`},
+	}, violations)
+}
+
+func TestValidate_Unchecked(t *testing.T) {
+	resource, violations := Default().Validate([]byte(`{"Attributes": {"NIC.1.Selection": "Dedicated"}}`))
+	assert.Empty(t, resource)
+	assert.Empty(t, violations)
+
+	resource, violations = Default().Validate([]byte(`{"@odata.type": "#DellLicense.v1_0_0.DellLicense", "Id": 7}`))
+	assert.Equal(t, "DellLicense", resource)
+	assert.Empty(t, violations)
+
+	_, violations = Default().Validate([]byte(`<html>Service Unavailable</html>`))
+	require.Len(t, violations, 1)
+	assert.Contains(t, violations[0].String(), "invalid JSON")
+
+	_, violations = Default().Validate([]byte(`[1, 2]`))
+	assert.Equal(t, []Violation{{Message: "expected object, got array"}}, violations)
+}
+
+func TestLoad_UnknownRef(t *testing.T) {
+	_, err := Load([]byte(`{"Thing": {"properties": {"Status": {"$ref": "Missing"}}}}`))
+	assert.EqualError(t, err, `Thing.Status: unknown $ref "Missing"`)
+
+	_, err = Load([]byte(`{"Thing": {"type": 1}}`))
+	assert.Error(t, err)
+}
@@ -1,6 +1,8 @@
 // Package redfish provides a client for interacting with Dell iDRAC Redfish API.
 package redfish
 
+import "strings"
+
 // ============================================================================
 // Redfish API Response Structures
 // ============================================================================
@@ -21,6 +23,22 @@ type Link struct {
 	OdataID string `json:"@odata.id"`
 }
 
+// ResourceType returns the schema name of an @odata.type value, e.g.
+// "Processor" for "#Processor.v1_10_0.Processor", or "" if it is empty.
+func ResourceType(odataType string) string {
+	name := strings.TrimPrefix(odataType, "#")
+	if i := strings.IndexByte(name, '.'); i >= 0 {
+		name = name[:i]
+	}
+	return name
+}
+
+// IsResourceType reports whether odataType names the given schema. Resources
+// without @odata.type (older firmware) are assumed to match.
+func IsResourceType(odataType, schema string) bool {
+	return odataType == "" || ResourceType(odataType) == schema
+}
+
 // Status represents the status of a Redfish component.
 type Status struct {
 	State        string `json:"State"`
@@ -62,13 +80,20 @@ type System struct {
 	Oem SystemOEM `json:"Oem"`
 
 	// Links to other resources
-	Processors Link `json:"Processors"`
-	Memory     Link `json:"Memory"`
-	Storage    Link `json:"Storage"`
+	Processors Link        `json:"Processors"`
+	Memory     Link        `json:"Memory"`
+	Storage    Link        `json:"Storage"`
+	Links      SystemLinks `json:"Links"`
 
 	Status Status `json:"Status"`
 }
 
+// SystemLinks links a ComputerSystem to its chassis and managers.
+type SystemLinks struct {
+	Chassis   []Link `json:"Chassis"`
+	ManagedBy []Link `json:"ManagedBy"`
+}
+
 // MemorySummary provides a summary of memory in the system.
 type MemorySummary struct {
 	TotalSystemMemoryGiB float64 `json:"TotalSystemMemoryGiB"`
@@ -91,6 +116,15 @@ type DellSystemAttributes struct {
 	MaxDIMMSlots   int `json:"MaxDIMMSlots,omitempty"`
 	PopulatedSlots int `json:"PopulatedSlots,omitempty"`
 	MemoryMaxGB    int `json:"SysMemMaxCapacityGB,omitempty"`
+
+	// Lifecycle Controller timestamps: the last collection of the system
+	// inventory (at boot or after a part change) and the last firmware or
+	// configuration update
+	LastSystemInventoryTime string `json:"LastSystemInventoryTime,omitempty"`
+	LastUpdateTime          string `json:"LastUpdateTime,omitempty"`
+
+	// Manufacture date of the system, reported by newer firmware
+	ManufactureDate string `json:"ManufactureDate,omitempty"`
 }
 
 // ProcessorSummary provides a summary of processors in the system.
@@ -135,9 +169,18 @@ func (p *Processor) IsInstalled() bool {
 	return p.Status.State == StateEnabled
 }
 
-// IsGPU returns true if this processor is a GPU or accelerator.
+// IsGPU returns true if this processor is a GPU or accelerator. It relies on
+// the ProcessorType enum and, if that is missing, on the Dell FQDD in Id
+// ("Video.Slot.38-1"), never on Name or Description, which are localized
+// (e.g. "Beschleuniger" on German iDRACs).
 func (p *Processor) IsGPU() bool {
-	return p.ProcessorType == "Accelerator" || p.ProcessorType == "GPU"
+	switch {
+	case strings.EqualFold(p.ProcessorType, "Accelerator"), strings.EqualFold(p.ProcessorType, "GPU"):
+		return true
+	case p.ProcessorType != "":
+		return false
+	}
+	return strings.HasPrefix(p.ID, "Video.")
 }
 
 // ProcessorMemory represents memory (VRAM) attached to a processor or GPU accelerator.
@@ -216,19 +259,169 @@ type Storage struct {
 	Drives      []Link `json:"Drives"`
 	DrivesCount int    `json:"Drives@odata.count"`
 
+	Links StorageLinks `json:"Links"`
+	Oem   StorageOEM   `json:"Oem"`
+
 	Status Status `json:"Status"`
 }
 
+// StorageOEM represents vendor-specific OEM extensions of a storage resource.
+type StorageOEM struct {
+	Dell *DellStorageOEM `json:"Dell,omitempty"`
+}
+
+// DellStorageOEM contains Dell-specific controller data. DellControllerBattery
+// is only present for PERC controllers with a cache battery or cachevault.
+type DellStorageOEM struct {
+	DellController        *DellController        `json:"DellController,omitempty"`
+	DellControllerBattery *DellControllerBattery `json:"DellControllerBattery,omitempty"`
+}
+
+// DellController contains Dell attributes of a storage controller.
+type DellController struct {
+	CacheSizeInMB int `json:"CacheSizeInMB"`
+}
+
+// DellControllerBattery is the cache battery of a PERC controller.
+type DellControllerBattery struct {
+	ID            string `json:"Id"`
+	Name          string `json:"Name"`
+	PrimaryStatus string `json:"PrimaryStatus"` // OK, Degraded, Error, Unknown
+	RAIDState     string `json:"RAIDState"`     // Ready, Charging, Learning, Degraded, Failed, Missing, Unknown
+}
+
+// StorageLinks contains references from a Storage resource to related resources.
+type StorageLinks struct {
+	Enclosures []Link `json:"Enclosures"`
+}
+
+// Enclosure represents a Chassis resource of type Enclosure (backplane or JBOD)
+// linked from a storage controller.
+type Enclosure struct {
+	OdataID      string         `json:"@odata.id"`
+	ID           string         `json:"Id"`
+	Name         string         `json:"Name"`
+	ChassisType  string         `json:"ChassisType"`
+	Model        string         `json:"Model"`
+	Manufacturer string         `json:"Manufacturer"`
+	PartNumber   string         `json:"PartNumber"`
+	SerialNumber string         `json:"SerialNumber"`
+	Links        EnclosureLinks `json:"Links"`
+	Oem          EnclosureOEM   `json:"Oem"`
+	Status       Status         `json:"Status"`
+}
+
+// EnclosureLinks links an enclosure to the drives in its slots.
+type EnclosureLinks struct {
+	Drives []Link `json:"Drives"`
+}
+
+// EnclosureOEM represents vendor-specific OEM extensions of an enclosure.
+type EnclosureOEM struct {
+	Dell *DellEnclosureOEM `json:"Dell,omitempty"`
+}
+
+// DellEnclosureOEM contains Dell-specific enclosure data.
+type DellEnclosureOEM struct {
+	DellEnclosure *DellEnclosure `json:"DellEnclosure,omitempty"`
+}
+
+// DellEnclosure contains Dell enclosure/backplane attributes.
+type DellEnclosure struct {
+	SlotCount  int    `json:"SlotCount"`
+	Version    string `json:"Version"`    // backplane or EMM firmware
+	Connector  int    `json:"Connector"`  // controller connector the enclosure is cabled to
+	WiredOrder int    `json:"WiredOrder"` // position in a daisy chain of JBODs
+	ServiceTag string `json:"ServiceTag"`
+}
+
+// PCIeSlots represents the Redfish PCIeSlots resource of a chassis.
+type PCIeSlots struct {
+	OdataID string     `json:"@odata.id"`
+	Slots   []PCIeSlot `json:"Slots"`
+}
+
+// PCIeSlot describes a single PCIe slot and the device installed in it, if any.
+type PCIeSlot struct {
+	PCIeType string           `json:"PCIeType"`
+	SlotType string           `json:"SlotType"`
+	Lanes    int              `json:"Lanes"`
+	Links    PCIeSlotLinks    `json:"Links"`
+	Location PhysicalLocation `json:"Location"`
+	Status   Status           `json:"Status"`
+}
+
+// PCIeSlotLinks references the PCIe devices installed in a slot.
+type PCIeSlotLinks struct {
+	PCIeDevice []Link `json:"PCIeDevice"`
+}
+
+// IsOccupied returns true if a device is installed in the slot.
+func (s PCIeSlot) IsOccupied() bool {
+	return len(s.Links.PCIeDevice) > 0
+}
+
+// PCIeDevice represents a Redfish PCIeDevice resource (an installed card).
+type PCIeDevice struct {
+	OdataID      string `json:"@odata.id"`
+	ID           string `json:"Id"`
+	Name         string `json:"Name"`
+	Description  string `json:"Description"`
+	Manufacturer string `json:"Manufacturer"`
+	Model        string `json:"Model"`
+	PartNumber   string `json:"PartNumber"`
+	SerialNumber string `json:"SerialNumber"`
+	DeviceType   string `json:"DeviceType"`
+	Status       Status `json:"Status"`
+
+	// PCIeFunctions links the function collection (PCIeDevice v1.4+);
+	// older firmware lists the functions in Links instead.
+	PCIeFunctions Link            `json:"PCIeFunctions"`
+	Links         PCIeDeviceLinks `json:"Links"`
+}
+
+// PCIeDeviceLinks references the functions of a PCIe device.
+type PCIeDeviceLinks struct {
+	PCIeFunctions []Link `json:"PCIeFunctions"`
+}
+
+// FunctionLinks returns the links to the device's PCIe functions, if listed
+// inline, and the link to the function collection otherwise.
+func (d PCIeDevice) FunctionLinks() (members []Link, collection string) {
+	if len(d.Links.PCIeFunctions) > 0 {
+		return d.Links.PCIeFunctions, ""
+	}
+	return nil, d.PCIeFunctions.OdataID
+}
+
+// PCIeFunction represents a Redfish PCIeFunction resource.
+type PCIeFunction struct {
+	OdataID string `json:"@odata.id"`
+	ID      string `json:"Id"`
+
+	// DeviceClass is the PCI class as a locale-independent enum
+	// (DisplayController, ProcessingAccelerators, NetworkController, ...).
+	DeviceClass string `json:"DeviceClass"`
+	ClassCode   string `json:"ClassCode"` // e.g. "0x030200"
+}
+
 // StorageController represents information about a storage controller.
 type StorageController struct {
-	MemberID                 string   `json:"MemberId"`
-	Name                     string   `json:"Name"`
-	Manufacturer             string   `json:"Manufacturer"`
-	Model                    string   `json:"Model"`
-	FirmwareVersion          string   `json:"FirmwareVersion"`
-	SpeedGbps                float64  `json:"SpeedGbps"`
-	SupportedDeviceProtocols []string `json:"SupportedDeviceProtocols"`
-	Status                   Status   `json:"Status"`
+	MemberID                 string        `json:"MemberId"`
+	Name                     string        `json:"Name"`
+	Manufacturer             string        `json:"Manufacturer"`
+	Model                    string        `json:"Model"`
+	FirmwareVersion          string        `json:"FirmwareVersion"`
+	SpeedGbps                float64       `json:"SpeedGbps"`
+	SupportedDeviceProtocols []string      `json:"SupportedDeviceProtocols"`
+	CacheSummary             *CacheSummary `json:"CacheSummary,omitempty"`
+	Status                   Status        `json:"Status"`
+}
+
+// CacheSummary describes the cache memory of a storage controller.
+type CacheSummary struct {
+	TotalCacheSizeMiB int    `json:"TotalCacheSizeMiB"`
+	Status            Status `json:"Status"`
 }
 
 // Drive represents a Redfish Drive resource.
@@ -269,7 +462,44 @@ type Drive struct {
 	// Location
 	PhysicalLocation PhysicalLocation `json:"PhysicalLocation"`
 
-	Status Status `json:"Status"`
+	Links  DriveLinks `json:"Links"`
+	Oem    DriveOEM   `json:"Oem"`
+	Status Status     `json:"Status"`
+}
+
+// DriveLinks links a drive to its volumes (NVMe namespaces, where exposed).
+type DriveLinks struct {
+	Volumes []Link `json:"Volumes"`
+}
+
+// DriveOEM represents vendor-specific OEM extensions of a drive.
+type DriveOEM struct {
+	Dell *DellDriveOEM `json:"Dell,omitempty"`
+}
+
+// DellDriveOEM contains Dell-specific drive data. NVMe drives report
+// DellPCIeSSD, SAS/SATA drives DellPhysicalDisk.
+type DellDriveOEM struct {
+	DellPCIeSSD      *DellPCIeSSD      `json:"DellPCIeSSD,omitempty"`
+	DellPhysicalDisk *DellPhysicalDisk `json:"DellPhysicalDisk,omitempty"`
+}
+
+// DellPCIeSSD contains Dell attributes of a PCIe (NVMe) SSD. Link speeds
+// are transfer rates like "16 GT/s", widths lane counts like "x4".
+type DellPCIeSSD struct {
+	PCIeNegotiatedLinkSpeed string `json:"PCIeNegotiatedLinkSpeed"`
+	PCIeCapableLinkSpeed    string `json:"PCIeCapableLinkSpeed"`
+	PCIeNegotiatedLinkWidth string `json:"PCIeNegotiatedLinkWidth"`
+	PCIeCapableLinkWidth    string `json:"PCIeCapableLinkWidth"`
+
+	// RemainingRatedWriteEndurancePercent is 255 if the drive does not
+	// report it.
+	RemainingRatedWriteEndurancePercent int `json:"RemainingRatedWriteEndurancePercent"`
+}
+
+// DellPhysicalDisk contains Dell attributes of a drive behind a controller.
+type DellPhysicalDisk struct {
+	RemainingRatedWriteEndurancePercent int `json:"RemainingRatedWriteEndurancePercent"`
 }
 
 // PhysicalLocation describes the physical location of a component.
@@ -341,25 +571,186 @@ type ServiceRoot struct {
 	Vendor         string `json:"Vendor"`
 }
 
+// Manager represents a Redfish Manager resource (the iDRAC itself).
+type Manager struct {
+	OdataID         string `json:"@odata.id"`
+	OdataType       string `json:"@odata.type"`
+	ID              string `json:"Id"`
+	Name            string `json:"Name"`
+	Model           string `json:"Model"`
+	ManagerType     string `json:"ManagerType"`
+	FirmwareVersion string `json:"FirmwareVersion"`
+	Status          Status `json:"Status"`
+
+	// DateTime is the current time of the manager, e.g.
+	// "2025-01-10T12:34:56-06:00".
+	DateTime string `json:"DateTime"`
+
+	EthernetInterfaces Link `json:"EthernetInterfaces"`
+}
+
+// EthernetInterface represents a network interface of a manager (the
+// iDRAC's own NIC) or a system.
+type EthernetInterface struct {
+	OdataID          string        `json:"@odata.id"`
+	ID               string        `json:"Id"`
+	Name             string        `json:"Name"`
+	HostName         string        `json:"HostName"`
+	FQDN             string        `json:"FQDN"`
+	MACAddress       string        `json:"MACAddress"`
+	SpeedMbps        int           `json:"SpeedMbps"`
+	InterfaceEnabled *bool         `json:"InterfaceEnabled"`
+	DHCPv4           DHCPv4        `json:"DHCPv4"`
+	IPv4Addresses    []IPv4Address `json:"IPv4Addresses"`
+	VLAN             VLAN          `json:"VLAN"`
+	NameServers      []string      `json:"NameServers"`
+	Status           Status        `json:"Status"`
+}
+
+// DHCPv4 is the DHCPv4 configuration of an interface.
+type DHCPv4 struct {
+	DHCPEnabled bool `json:"DHCPEnabled"`
+}
+
+// IPv4Address is an IPv4 address of an interface.
+type IPv4Address struct {
+	Address       string `json:"Address"`
+	SubnetMask    string `json:"SubnetMask"`
+	Gateway       string `json:"Gateway"`
+	AddressOrigin string `json:"AddressOrigin"` // Static, DHCP, ...
+}
+
+// VLAN is the VLAN configuration of an interface.
+type VLAN struct {
+	VLANEnable bool `json:"VLANEnable"`
+	VLANID     int  `json:"VLANId"`
+}
+
+// ManagerAttributes represents the Dell iDRAC attribute registry values of a
+// manager (e.g. "NIC.1.Selection").
+type ManagerAttributes struct {
+	Attributes map[string]interface{} `json:"Attributes"`
+}
+
+// ManagerAccount represents a local user account of the AccountService.
+type ManagerAccount struct {
+	OdataID  string `json:"@odata.id"`
+	ID       string `json:"Id"`
+	UserName string `json:"UserName"`
+	RoleID   string `json:"RoleId"`
+	Enabled  bool   `json:"Enabled"`
+	Locked   bool   `json:"Locked"`
+}
+
+// DellLicense represents an installed iDRAC license (Dell OEM DellLicenses member).
+type DellLicense struct {
+	OdataID              string   `json:"@odata.id"`
+	ID                   string   `json:"Id"`
+	EntitlementID        string   `json:"EntitlementID"`
+	LicenseDescription   []string `json:"LicenseDescription"`
+	LicenseType          string   `json:"LicenseType"`
+	LicensePrimaryStatus string   `json:"LicensePrimaryStatus"`
+	LicenseInstallDate   string   `json:"LicenseInstallDate"`
+	LicenseSoldDate      string   `json:"LicenseSoldDate"`
+}
+
 // Power represents a Redfish Power resource containing power consumption data.
 type Power struct {
-	OdataID      string         `json:"@odata.id"`
-	OdataType    string         `json:"@odata.type"`
-	ID           string         `json:"Id"`
-	Name         string         `json:"Name"`
-	PowerControl []PowerControl `json:"PowerControl"`
+	OdataID       string         `json:"@odata.id"`
+	OdataType     string         `json:"@odata.type"`
+	ID            string         `json:"Id"`
+	Name          string         `json:"Name"`
+	PowerControl  []PowerControl `json:"PowerControl"`
+	PowerSupplies []PowerSupply  `json:"PowerSupplies"`
+}
+
+// Chassis is the subset of a Redfish Chassis resource that links to its
+// power resources.
+type Chassis struct {
+	OdataID            string `json:"@odata.id"`
+	ID                 string `json:"Id"`
+	Power              Link   `json:"Power"`
+	PowerSubsystem     Link   `json:"PowerSubsystem"`
+	EnvironmentMetrics Link   `json:"EnvironmentMetrics"`
+}
+
+// PowerSubsystem replaces the deprecated Power resource in newer firmware
+// (Redfish 2020.4+). Power readings moved to EnvironmentMetrics.
+type PowerSubsystem struct {
+	OdataID       string  `json:"@odata.id"`
+	CapacityWatts float64 `json:"CapacityWatts"`
+	PowerSupplies Link    `json:"PowerSupplies"`
+	Status        Status  `json:"Status"`
+}
+
+// SubsystemPowerSupply is a member of the PowerSubsystem power supply
+// collection. Its input power is in the linked PowerSupplyMetrics.
+type SubsystemPowerSupply struct {
+	OdataID            string  `json:"@odata.id"`
+	ID                 string  `json:"Id"`
+	Name               string  `json:"Name"`
+	Model              string  `json:"Model"`
+	Manufacturer       string  `json:"Manufacturer"`
+	SerialNumber       string  `json:"SerialNumber"`
+	PartNumber         string  `json:"PartNumber"`
+	SparePartNumber    string  `json:"SparePartNumber"`
+	FirmwareVersion    string  `json:"FirmwareVersion"`
+	PowerCapacityWatts float64 `json:"PowerCapacityWatts"`
+	PowerSupplyType    string  `json:"PowerSupplyType"`
+	Metrics            Link    `json:"Metrics"`
+	Status             Status  `json:"Status"`
+}
+
+// PowerSupplyMetrics holds the readings of a power supply.
+type PowerSupplyMetrics struct {
+	InputPowerWatts SensorExcerpt `json:"InputPowerWatts"`
+}
+
+// EnvironmentMetrics holds the power and energy readings of a chassis.
+type EnvironmentMetrics struct {
+	PowerWatts SensorExcerpt `json:"PowerWatts"`
+	EnergykWh  SensorExcerpt `json:"EnergykWh"`
+}
+
+// SensorExcerpt is a sensor reading embedded in another resource.
+// DataSourceURI links to the full Sensor.
+type SensorExcerpt struct {
+	DataSourceURI string   `json:"DataSourceUri"`
+	Reading       *float64 `json:"Reading"`
+}
+
+// Sensor is the subset of a Redfish Sensor resource used for peak readings.
+type Sensor struct {
+	Reading     *float64 `json:"Reading"`
+	PeakReading *float64 `json:"PeakReading"`
+}
+
+// PowerSupply represents a power supply unit listed in the Power resource.
+type PowerSupply struct {
+	MemberID           string  `json:"MemberId"`
+	Name               string  `json:"Name"`
+	Model              string  `json:"Model"`
+	Manufacturer       string  `json:"Manufacturer"`
+	SerialNumber       string  `json:"SerialNumber"`
+	PartNumber         string  `json:"PartNumber"`
+	SparePartNumber    string  `json:"SparePartNumber"`
+	FirmwareVersion    string  `json:"FirmwareVersion"`
+	PowerCapacityWatts float64 `json:"PowerCapacityWatts"`
+	PowerInputWatts    float64 `json:"PowerInputWatts"`
+	PowerSupplyType    string  `json:"PowerSupplyType"`
+	Status             Status  `json:"Status"`
 }
 
 // PowerControl represents power control and consumption information.
 type PowerControl struct {
-	MemberID             string       `json:"MemberId"`
-	Name                 string       `json:"Name"`
-	PowerConsumedWatts   int          `json:"PowerConsumedWatts,omitempty"`
-	PowerMetrics         PowerMetrics `json:"PowerMetrics,omitempty"`
-	PowerCapacityWatts   int          `json:"PowerCapacityWatts,omitempty"`
-	PowerAllocatedWatts  int          `json:"PowerAllocatedWatts,omitempty"`
-	PowerAvailableWatts  int          `json:"PowerAvailableWatts,omitempty"`
-	PowerRequestedWatts  int          `json:"PowerRequestedWatts,omitempty"`
+	MemberID            string       `json:"MemberId"`
+	Name                string       `json:"Name"`
+	PowerConsumedWatts  int          `json:"PowerConsumedWatts,omitempty"`
+	PowerMetrics        PowerMetrics `json:"PowerMetrics,omitempty"`
+	PowerCapacityWatts  int          `json:"PowerCapacityWatts,omitempty"`
+	PowerAllocatedWatts int          `json:"PowerAllocatedWatts,omitempty"`
+	PowerAvailableWatts int          `json:"PowerAvailableWatts,omitempty"`
+	PowerRequestedWatts int          `json:"PowerRequestedWatts,omitempty"`
 }
 
 // PowerMetrics contains historical power consumption statistics.
@@ -369,3 +760,32 @@ type PowerMetrics struct {
 	AverageConsumedWatts int `json:"AverageConsumedWatts,omitempty"`
 	IntervalInMin        int `json:"IntervalInMin,omitempty"`
 }
+
+// EventDestination represents a Redfish event subscription (a member of
+// /redfish/v1/EventService/Subscriptions).
+type EventDestination struct {
+	OdataID     string   `json:"@odata.id,omitempty"`
+	ID          string   `json:"Id,omitempty"`
+	Destination string   `json:"Destination"`
+	EventTypes  []string `json:"EventTypes,omitempty"`
+	Context     string   `json:"Context,omitempty"`
+	Protocol    string   `json:"Protocol,omitempty"`
+}
+
+// Event is the payload an iDRAC pushes to a subscription's destination.
+type Event struct {
+	Context string        `json:"Context"`
+	Events  []EventRecord `json:"Events"`
+}
+
+// EventRecord is one event of an Event payload, e.g. MessageId
+// "iDRAC.2.8.PDR1016" (drive removed) with Severity "Warning".
+type EventRecord struct {
+	EventType         string `json:"EventType"`
+	EventID           string `json:"EventId"`
+	EventTimestamp    string `json:"EventTimestamp"`
+	Severity          string `json:"Severity"`
+	Message           string `json:"Message"`
+	MessageID         string `json:"MessageId"`
+	OriginOfCondition Link   `json:"OriginOfCondition"`
+}
@@ -0,0 +1,48 @@
+package redfish
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProcessor_IsGPU(t *testing.T) {
+	tests := []struct {
+		name string
+		p    Processor
+		want bool
+	}{
+		{"accelerator", Processor{ProcessorType: "Accelerator", Name: "Beschleuniger 1"}, true},
+		{"gpu", Processor{ProcessorType: "GPU"}, true},
+		{"cpu with localized name", Processor{ProcessorType: "CPU", Name: "Prozessor 1"}, false},
+		{"untyped dell gpu", Processor{ID: "Video.Slot.38-1", Name: "Beschleuniger"}, true},
+		{"untyped cpu", Processor{ID: "CPU.Socket.1", Name: "Accelerator"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.p.IsGPU())
+		})
+	}
+}
+
+func TestResourceType(t *testing.T) {
+	assert.Equal(t, "Processor", ResourceType("#Processor.v1_10_0.Processor"))
+	assert.Equal(t, "PCIeFunction", ResourceType("#PCIeFunction.v1_2_3.PCIeFunction"))
+	assert.Empty(t, ResourceType(""))
+
+	assert.True(t, IsResourceType("#Memory.v1_11_0.Memory", "Memory"))
+	assert.True(t, IsResourceType("", "Memory"))
+	assert.False(t, IsResourceType("#Processor.v1_10_0.Processor", "Memory"))
+}
+
+func TestPCIeDevice_FunctionLinks(t *testing.T) {
+	inline := PCIeDevice{Links: PCIeDeviceLinks{PCIeFunctions: []Link{{OdataID: "/f/0"}}}}
+	members, collection := inline.FunctionLinks()
+	assert.Len(t, members, 1)
+	assert.Empty(t, collection)
+
+	linked := PCIeDevice{PCIeFunctions: Link{OdataID: "/redfish/v1/Chassis/System.Embedded.1/PCIeDevices/59-0/PCIeFunctions"}}
+	members, collection = linked.FunctionLinks()
+	assert.Empty(t, members)
+	assert.Equal(t, "/redfish/v1/Chassis/System.Embedded.1/PCIeDevices/59-0/PCIeFunctions", collection)
+}
@@ -0,0 +1,184 @@
+// Package regression detects hardware health regressions of a host between
+// two scans, such as a drive going from OK to Warning, a DIMM disappearing or
+// a power supply failing.
+package regression
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+// Severity of a regression.
+type Severity string
+
+// Severities, in increasing order.
+const (
+	Warning  Severity = "warning"
+	Critical Severity = "critical"
+)
+
+// Kinds of change.
+const (
+	ChangeHealth      = "health"
+	ChangeDisappeared = "disappeared"
+)
+
+// Regression is one component that got worse since the previous scan.
+type Regression struct {
+	Host       string   `json:"host"`
+	ServiceTag string   `json:"service_tag,omitempty"`
+	Component  string   `json:"component"` // cpu, memory, drive, controller, battery, gpu, psu
+	Name       string   `json:"name"`
+	Change     string   `json:"change"` // health or disappeared
+	Old        string   `json:"old,omitempty"`
+	New        string   `json:"new,omitempty"`
+	Severity   Severity `json:"severity"`
+
+	// Labels are the labels of the server (see config.ServerConfig.Labels).
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// String returns a one-line description, e.g. "drive Disk.Bay.3 health OK → Warning".
+func (r Regression) String() string {
+	if r.Change == ChangeDisappeared {
+		return fmt.Sprintf("%s %s disappeared", r.Component, r.Name)
+	}
+	return fmt.Sprintf("%s %s health %s → %s", r.Component, r.Name, r.Old, r.New)
+}
+
+// ParseSeverity parses a severity name ("" is Warning).
+func ParseSeverity(s string) (Severity, error) {
+	switch Severity(s) {
+	case "", Warning:
+		return Warning, nil
+	case Critical:
+		return Critical, nil
+	}
+	return "", fmt.Errorf("unknown severity %q (use warning or critical)", s)
+}
+
+func (s Severity) rank() int {
+	if s == Critical {
+		return 2
+	}
+	if s == Warning {
+		return 1
+	}
+	return 0
+}
+
+// component is a part of the server identified by kind and name.
+type component struct {
+	kind, name string
+}
+
+// components returns the health of every installed component. A component
+// without a health reading is reported as "".
+func components(s models.ServerInfo) map[component]string {
+	m := map[component]string{}
+	for _, cpu := range s.CPUs {
+		m[component{"cpu", cpu.Socket}] = cpu.Health
+	}
+	for _, mem := range s.Memory {
+		if mem.IsPopulated() {
+			m[component{"memory", mem.Slot}] = mem.Health
+		}
+	}
+	for _, d := range s.Drives {
+		m[component{"drive", d.Name}] = d.Health
+	}
+	for _, c := range s.StorageControllers {
+		m[component{"controller", c.ID}] = c.Health
+		if c.Battery != nil {
+			m[component{"battery", c.ID}] = c.Battery.Health
+		}
+	}
+	for _, gpu := range s.GPUs {
+		m[component{"gpu", gpu.Slot}] = gpu.Health
+	}
+	for _, psu := range s.PowerSupplies {
+		if psu.IsInstalled() {
+			m[component{"psu", psu.Name}] = psu.Health
+		}
+	}
+	return m
+}
+
+// healthRank orders health values; unknown counts as OK.
+func healthRank(h string) int {
+	switch h {
+	case models.HealthWarning:
+		return 1
+	case models.HealthCritical:
+		return 2
+	}
+	return 0
+}
+
+// Detect compares the current scan of a host with its previous good scan.
+// A component whose health got worse is a Warning regression if it is now
+// Warning and Critical if it is now Critical; a component that disappeared is
+// Critical. Results are sorted by component and name.
+func Detect(prev, cur models.ServerInfo) []Regression {
+	before := components(prev)
+	after := components(cur)
+
+	var regs []Regression
+	for c, oldHealth := range before {
+		r := Regression{Host: cur.Host, ServiceTag: cur.ServiceTag, Component: c.kind, Name: c.name, Labels: cur.Labels}
+
+		newHealth, ok := after[c]
+		switch {
+		case !ok:
+			r.Change = ChangeDisappeared
+			r.Old = oldHealth
+			r.Severity = Critical
+		case healthRank(newHealth) > healthRank(oldHealth):
+			r.Change = ChangeHealth
+			r.Old = oldHealth
+			if r.Old == "" {
+				r.Old = models.HealthOK
+			}
+			r.New = newHealth
+			r.Severity = Warning
+			if newHealth == models.HealthCritical {
+				r.Severity = Critical
+			}
+		default:
+			continue
+		}
+		regs = append(regs, r)
+	}
+
+	sort.Slice(regs, func(i, j int) bool {
+		if regs[i].Component != regs[j].Component {
+			return regs[i].Component < regs[j].Component
+		}
+		return regs[i].Name < regs[j].Name
+	})
+	return regs
+}
+
+// Filter returns the regressions of at least the given severity.
+func Filter(regs []Regression, min Severity) []Regression {
+	var out []Regression
+	for _, r := range regs {
+		if r.Severity.rank() >= min.rank() {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// Worst returns the highest severity of regs ("" if regs is empty).
+func Worst(regs []Regression) Severity {
+	var worst Severity
+	for _, r := range regs {
+		if r.Severity.rank() > worst.rank() {
+			worst = r.Severity
+		}
+	}
+	return worst
+}
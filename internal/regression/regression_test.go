@@ -0,0 +1,89 @@
+package regression
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+func TestDetect(t *testing.T) {
+	prev := models.ServerInfo{
+		Host: "10.0.0.1",
+		Memory: []models.MemoryInfo{
+			{Slot: "DIMM.Socket.A1", State: models.MemoryStateEnabled, Health: models.HealthOK},
+			{Slot: "DIMM.Socket.A2", State: models.MemoryStateEnabled, Health: models.HealthOK},
+		},
+		Drives: []models.DriveInfo{
+			{Name: "Disk.Bay.3", Health: models.HealthOK},
+			{Name: "Disk.Bay.4", Health: models.HealthWarning},
+		},
+		PowerSupplies: []models.PowerSupplyInfo{{Name: "PSU 1", State: "Enabled", Health: models.HealthOK}},
+	}
+	cur := models.ServerInfo{
+		Host:       "10.0.0.1",
+		ServiceTag: "ABC1234",
+		Labels:     map[string]string{"environment": "prod"},
+		Memory: []models.MemoryInfo{
+			{Slot: "DIMM.Socket.A1", State: models.MemoryStateEnabled, Health: models.HealthOK},
+			{Slot: "DIMM.Socket.A2", State: models.MemoryStateAbsent},
+		},
+		Drives: []models.DriveInfo{
+			{Name: "Disk.Bay.3", Health: models.HealthWarning},
+			{Name: "Disk.Bay.4", Health: models.HealthOK}, // recovered
+		},
+		PowerSupplies: []models.PowerSupplyInfo{{Name: "PSU 1", State: "Enabled", Health: models.HealthCritical}},
+	}
+
+	regs := Detect(prev, cur)
+	require.Len(t, regs, 3)
+
+	assert.Equal(t, "drive Disk.Bay.3 health OK → Warning", regs[0].String())
+	assert.Equal(t, Warning, regs[0].Severity)
+	assert.Equal(t, "ABC1234", regs[0].ServiceTag)
+	assert.Equal(t, "prod", regs[0].Labels["environment"])
+
+	assert.Equal(t, "memory DIMM.Socket.A2 disappeared", regs[1].String())
+	assert.Equal(t, Critical, regs[1].Severity)
+
+	assert.Equal(t, "psu PSU 1 health OK → Critical", regs[2].String())
+	assert.Equal(t, Critical, regs[2].Severity)
+
+	assert.Equal(t, Critical, Worst(regs))
+	assert.Len(t, Filter(regs, Critical), 2)
+	assert.Len(t, Filter(regs, Warning), 3)
+	assert.Empty(t, Detect(cur, cur))
+}
+
+func TestDetect_ControllerBattery(t *testing.T) {
+	ctrl := func(battery string) models.ServerInfo {
+		return models.ServerInfo{Host: "10.0.0.1", StorageControllers: []models.StorageControllerInfo{{
+			ID:      "RAID.Integrated.1-1",
+			Health:  models.HealthOK,
+			Battery: &models.BatteryInfo{Health: battery},
+		}}}
+	}
+
+	regs := Detect(ctrl(models.HealthOK), ctrl(models.HealthWarning))
+	require.Len(t, regs, 1)
+	assert.Equal(t, "battery RAID.Integrated.1-1 health OK → Warning", regs[0].String())
+	assert.Equal(t, Warning, regs[0].Severity)
+
+	assert.Empty(t, Detect(models.ServerInfo{Host: "10.0.0.1"}, ctrl(models.HealthWarning)),
+		"results of older versions have no controllers to compare with")
+}
+
+func TestParseSeverity(t *testing.T) {
+	s, err := ParseSeverity("")
+	require.NoError(t, err)
+	assert.Equal(t, Warning, s)
+
+	s, err = ParseSeverity("critical")
+	require.NoError(t, err)
+	assert.Equal(t, Critical, s)
+
+	_, err = ParseSeverity("info")
+	assert.Error(t, err)
+}
@@ -0,0 +1,239 @@
+// Package schedule decides which hosts a daemon cycle scans. Healthy hosts
+// are scanned every cycle; a host that fails repeatedly is re-scanned at
+// exponentially longer intervals (interval, 2×, 4×, … up to a maximum) and
+// quarantined after too many consecutive failures, so a few dead iDRACs do
+// not slow down every cycle with timeouts. Quarantined hosts are not scanned
+// until they are released (see the schedule command).
+//
+// The state is kept per host in a JSON file in the state directory.
+package schedule
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/config"
+	"github.com/braunma/idrac-netbox-importer/pkg/errors"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+// Policy sets the re-scan intervals of failing hosts.
+type Policy struct {
+	// Interval is the normal cadence (the daemon interval).
+	Interval time.Duration
+	// MaxInterval caps the backoff.
+	MaxInterval time.Duration
+	// QuarantineAfter is the number of consecutive failures after which a
+	// host is quarantined (0: never).
+	QuarantineAfter int
+}
+
+// delay returns the wait before the next scan of a host with the given
+// number of consecutive failures.
+func (p Policy) delay(failures int) time.Duration {
+	d := p.Interval
+	for i := 1; i < failures && d < p.MaxInterval; i++ {
+		d *= 2
+	}
+	if p.MaxInterval > 0 && d > p.MaxInterval {
+		d = p.MaxInterval
+	}
+	return d
+}
+
+// HostState is the schedule of one host.
+type HostState struct {
+	Host             string     `json:"host"`
+	Failures         int        `json:"consecutive_failures"`
+	LastScan         time.Time  `json:"last_scan"`
+	LastSuccess      *time.Time `json:"last_success,omitempty"`
+	LastError        string     `json:"last_error,omitempty"`
+	NextScan         time.Time  `json:"next_scan"`
+	QuarantinedSince *time.Time `json:"quarantined_since,omitempty"`
+
+	// Seq orders the hosts by their last scan: each recorded result gets
+	// the next number, so hosts of one cycle are not tied (see Oldest).
+	Seq int64 `json:"seq,omitempty"`
+}
+
+// Quarantined reports whether the host is excluded from scans.
+func (h HostState) Quarantined() bool {
+	return h.QuarantinedSince != nil
+}
+
+// Scheduler holds the schedule of all hosts that were scanned.
+type Scheduler struct {
+	path   string
+	policy Policy
+	hosts  map[string]*HostState
+	seq    int64 // highest Seq of the hosts
+}
+
+// Load reads the schedule from path. A missing file yields an empty
+// schedule, in which every host is due.
+func Load(path string, policy Policy) (*Scheduler, error) {
+	hosts, err := ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s := &Scheduler{path: path, policy: policy, hosts: make(map[string]*HostState, len(hosts))}
+	for i := range hosts {
+		s.hosts[hosts[i].Host] = &hosts[i]
+		s.seq = max(s.seq, hosts[i].Seq)
+	}
+	return s, nil
+}
+
+// ReadFile returns the host states saved at path, sorted by host.
+func ReadFile(path string) ([]HostState, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schedule: %w", err)
+	}
+	var hosts []HostState
+	if err := json.Unmarshal(data, &hosts); err != nil {
+		return nil, fmt.Errorf("failed to parse schedule %s: %w", path, err)
+	}
+	return hosts, nil
+}
+
+// Due returns the servers to scan at now: hosts without a schedule and
+// hosts whose next scan is due, except quarantined ones.
+func (s *Scheduler) Due(servers []config.ServerConfig, now time.Time) []config.ServerConfig {
+	var due []config.ServerConfig
+	for _, srv := range servers {
+		h, ok := s.hosts[srv.Host]
+		if !ok || (!h.Quarantined() && !now.Before(h.NextScan)) {
+			due = append(due, srv)
+		}
+	}
+	return due
+}
+
+// Oldest returns at most n of the servers, those scanned least recently
+// first (hosts never scanned in configured order before all others). Hosts
+// scanned in the same cycle are ordered by when their results were
+// recorded, not by their position in the config, so a limit on the hosts
+// per cycle rotates through the whole fleet. Picked hosts that got no
+// result (e.g. after the scan deadline) stay first for the next cycle.
+func (s *Scheduler) Oldest(servers []config.ServerConfig, n int) []config.ServerConfig {
+	if n <= 0 || len(servers) <= n {
+		return servers
+	}
+	seq := func(srv config.ServerConfig) int64 {
+		if h, ok := s.hosts[srv.Host]; ok {
+			return h.Seq
+		}
+		return 0
+	}
+	sorted := append([]config.ServerConfig(nil), servers...)
+	sort.SliceStable(sorted, func(i, j int) bool { return seq(sorted[i]) < seq(sorted[j]) })
+	return sorted[:n]
+}
+
+// Record updates the schedule with the results of a scan finished at now.
+// Stale results (failed hosts reported from history) count as failures,
+// except for a busy BMC.
+func (s *Scheduler) Record(results []models.ServerInfo, now time.Time) {
+	for _, res := range results {
+		h, ok := s.hosts[res.Host]
+		if !ok {
+			h = &HostState{Host: res.Host}
+			s.hosts[res.Host] = h
+		}
+		h.LastScan = now
+		s.seq++
+		h.Seq = s.seq
+
+		if res.Error == nil && !res.Stale {
+			success := now
+			h.Failures = 0
+			h.LastSuccess = &success
+			h.LastError = ""
+			h.NextScan = now.Add(s.policy.Interval)
+			continue
+		}
+
+		h.LastError = res.StaleError
+		if res.Error != nil {
+			h.LastError = res.Error.Error()
+		}
+
+		// A BMC that is resetting or in maintenance is not failing; keep
+		// its cadence and failure count
+		if bmcBusy(res) {
+			h.NextScan = now.Add(s.policy.Interval)
+			continue
+		}
+
+		h.Failures++
+		h.NextScan = now.Add(s.policy.delay(h.Failures))
+		if s.policy.QuarantineAfter > 0 && h.Failures >= s.policy.QuarantineAfter && !h.Quarantined() {
+			since := now
+			h.QuarantinedSince = &since
+		}
+	}
+}
+
+// bmcBusy reports whether a failed result was caused by a busy BMC. Stale
+// results only carry the error message.
+func bmcBusy(res models.ServerInfo) bool {
+	err := res.Error
+	if err == nil {
+		err = fmt.Errorf("%s", res.StaleError)
+	}
+	return errors.Categorize(err) == errors.CategoryBMCBusy
+}
+
+// Release clears the failures and quarantine of a host, so it is scanned
+// in the next cycle. It returns false if the host has no schedule.
+func (s *Scheduler) Release(host string) bool {
+	if _, ok := s.hosts[host]; !ok {
+		return false
+	}
+	delete(s.hosts, host)
+	return true
+}
+
+// Hosts returns the state of all hosts, sorted by host.
+func (s *Scheduler) Hosts() []HostState {
+	hosts := make([]HostState, 0, len(s.hosts))
+	for _, h := range s.hosts {
+		hosts = append(hosts, *h)
+	}
+	sort.Slice(hosts, func(i, j int) bool { return hosts[i].Host < hosts[j].Host })
+	return hosts
+}
+
+// Save writes the schedule atomically.
+func (s *Scheduler) Save() error {
+	data, err := json.MarshalIndent(s.Hosts(), "", "  ")
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(s.path)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return fmt.Errorf("failed to create schedule directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(s.path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write schedule: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write schedule: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write schedule: %w", err)
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
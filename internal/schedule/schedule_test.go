@@ -0,0 +1,178 @@
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/config"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+func hosts(servers []config.ServerConfig) []string {
+	var out []string
+	for _, s := range servers {
+		out = append(out, s.Host)
+	}
+	return out
+}
+
+func TestPolicy_Delay(t *testing.T) {
+	p := Policy{Interval: time.Hour, MaxInterval: 6 * time.Hour}
+	assert.Equal(t, time.Hour, p.delay(1))
+	assert.Equal(t, 2*time.Hour, p.delay(2))
+	assert.Equal(t, 4*time.Hour, p.delay(3))
+	assert.Equal(t, 6*time.Hour, p.delay(4))
+	assert.Equal(t, 6*time.Hour, p.delay(100))
+}
+
+func TestScheduler_Backoff(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scan-schedule.json")
+	s, err := Load(path, Policy{Interval: time.Hour, MaxInterval: 8 * time.Hour, QuarantineAfter: 3})
+	require.NoError(t, err)
+
+	servers := []config.ServerConfig{{Host: "10.0.0.1"}, {Host: "10.0.0.2"}, {Host: "10.0.0.3"}}
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	assert.Len(t, s.Due(servers, now), 3, "unknown hosts are due")
+
+	scan := func(now time.Time) {
+		s.Record([]models.ServerInfo{
+			{Host: "10.0.0.1"},
+			{Host: "10.0.0.2", Error: errors.New("timeout")},
+			{Host: "10.0.0.3", Stale: true, StaleError: "connection refused"},
+		}, now)
+	}
+
+	scan(now)
+	now = now.Add(time.Hour)
+	assert.Equal(t, []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}, hosts(s.Due(servers, now)), "first failure keeps the cadence")
+
+	scan(now)
+	now = now.Add(time.Hour)
+	assert.Equal(t, []string{"10.0.0.1"}, hosts(s.Due(servers, now)), "second failure doubles the interval")
+	now = now.Add(time.Hour)
+	assert.Equal(t, []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}, hosts(s.Due(servers, now)))
+
+	scan(now)
+	state := s.Hosts()
+	require.Len(t, state, 3)
+	assert.False(t, state[0].Quarantined())
+	assert.True(t, state[1].Quarantined(), "quarantined after 3 failures")
+	assert.Equal(t, "timeout", state[1].LastError)
+	assert.Equal(t, "connection refused", state[2].LastError)
+	assert.Equal(t, 3, state[2].Failures)
+
+	now = now.Add(24 * time.Hour)
+	assert.Equal(t, []string{"10.0.0.1"}, hosts(s.Due(servers, now)), "quarantined hosts are not scanned")
+
+	require.NoError(t, s.Save())
+	reloaded, err := Load(path, Policy{Interval: time.Hour})
+	require.NoError(t, err)
+	assert.Equal(t, s.Hosts(), reloaded.Hosts())
+
+	assert.True(t, reloaded.Release("10.0.0.2"))
+	assert.False(t, reloaded.Release("10.0.0.9"))
+	assert.Equal(t, []string{"10.0.0.1", "10.0.0.2"}, hosts(reloaded.Due(servers, now)))
+}
+
+func TestScheduler_Recovery(t *testing.T) {
+	s, err := Load(filepath.Join(t.TempDir(), "missing.json"), Policy{Interval: time.Hour, MaxInterval: 24 * time.Hour})
+	require.NoError(t, err)
+
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		s.Record([]models.ServerInfo{{Host: "10.0.0.1", Error: errors.New("timeout")}}, now)
+	}
+	s.Record([]models.ServerInfo{{Host: "10.0.0.1"}}, now)
+
+	h := s.Hosts()[0]
+	assert.Zero(t, h.Failures)
+	assert.Empty(t, h.LastError)
+	assert.Equal(t, now.Add(time.Hour), h.NextScan)
+	require.NotNil(t, h.LastSuccess)
+}
+
+func TestScheduler_BusyBMC(t *testing.T) {
+	s, err := Load(filepath.Join(t.TempDir(), "missing.json"), Policy{Interval: time.Hour, QuarantineAfter: 2})
+	require.NoError(t, err)
+
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		s.Record([]models.ServerInfo{
+			{Host: "10.0.0.1", Stale: true, StaleError: "BMC busy, retry after 2m0s: redfish error on https://10.0.0.1/redfish/v1: (HTTP 503)"},
+		}, now)
+	}
+
+	h := s.Hosts()[0]
+	assert.Zero(t, h.Failures, "a busy BMC is not a failure")
+	assert.False(t, h.Quarantined())
+	assert.Equal(t, now.Add(time.Hour), h.NextScan)
+	assert.Contains(t, h.LastError, "BMC busy")
+}
+
+func TestScheduler_Oldest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scan-schedule.json")
+	s, err := Load(path, Policy{Interval: time.Hour})
+	require.NoError(t, err)
+
+	var servers []config.ServerConfig
+	for i := 1; i <= 10; i++ {
+		servers = append(servers, config.ServerConfig{Host: fmt.Sprintf("10.0.0.%d", i)})
+	}
+	now := time.Date(2025, 1, 10, 2, 0, 0, 0, time.UTC)
+	assert.Equal(t, servers, s.Oldest(servers, 0), "no limit")
+
+	for _, n := range []int{3, 4, 7, 9} {
+		window := (len(servers) + n - 1) / n
+		var batches [][]string
+		scans := make(map[string]int)
+		for cycle := 0; cycle < 4*window; cycle++ {
+			// Reloaded every cycle, as by the daemon
+			s, err = Load(path, Policy{Interval: time.Hour})
+			require.NoError(t, err)
+			batch := s.Oldest(servers, n)
+			require.Len(t, batch, n)
+			var hosts []string
+			var results []models.ServerInfo
+			for _, srv := range batch {
+				hosts = append(hosts, srv.Host)
+				results = append(results, models.ServerInfo{Host: srv.Host})
+				scans[srv.Host]++
+			}
+
+			// No host is scanned more than once more than any other
+			least, most := scans[servers[0].Host], 0
+			for _, srv := range servers {
+				least, most = min(least, scans[srv.Host]), max(most, scans[srv.Host])
+			}
+			assert.LessOrEqual(t, most-least, 1, "n=%d, cycle %d: %v", n, cycle, scans)
+			batches = append(batches, hosts)
+			s.Record(results, now)
+			require.NoError(t, s.Save())
+			now = now.Add(time.Hour)
+		}
+
+		// Every host is scanned within any ceil(len/n) consecutive cycles
+		for first := 0; first+window <= len(batches); first++ {
+			seen := make(map[string]bool)
+			for _, hosts := range batches[first : first+window] {
+				for _, h := range hosts {
+					seen[h] = true
+				}
+			}
+			assert.Len(t, seen, len(servers), "n=%d, cycles %d-%d: %v", n, first, first+window-1, batches)
+		}
+	}
+
+	// A picked host without a result (scan deadline) stays first
+	s, err = Load(path, Policy{Interval: time.Hour})
+	require.NoError(t, err)
+	batch := s.Oldest(servers, 3)
+	s.Record([]models.ServerInfo{{Host: batch[1].Host}, {Host: batch[2].Host}}, now)
+	assert.Equal(t, batch[0], s.Oldest(servers, 3)[0])
+}
@@ -0,0 +1,11 @@
+//go:build !windows
+
+package service
+
+import "context"
+
+// Run calls fn with ctx. Only on Windows it also serves the service control
+// manager.
+func Run(ctx context.Context, fn func(context.Context) error) error {
+	return fn(ctx)
+}
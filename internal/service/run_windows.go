@@ -0,0 +1,63 @@
+//go:build windows
+
+package service
+
+import (
+	"context"
+
+	"golang.org/x/sys/windows/svc"
+)
+
+// Run calls fn with ctx. When the process was started by the Windows service
+// control manager, it reports the service as running while fn runs and
+// cancels the context of fn on a stop or shutdown request.
+func Run(ctx context.Context, fn func(context.Context) error) error {
+	isService, err := svc.IsWindowsService()
+	if err != nil || !isService {
+		return fn(ctx)
+	}
+
+	h := &handler{fn: fn, ctx: ctx}
+	// The name is ignored for services running in their own process
+	if err := svc.Run("", h); err != nil {
+		return err
+	}
+	return h.err
+}
+
+// handler implements svc.Handler around the daemon function.
+type handler struct {
+	fn  func(context.Context) error
+	ctx context.Context
+	err error
+}
+
+// Execute implements svc.Handler.
+func (h *handler) Execute(_ []string, requests <-chan svc.ChangeRequest, status chan<- svc.Status) (bool, uint32) {
+	ctx, cancel := context.WithCancel(h.ctx)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() { done <- h.fn(ctx) }()
+	status <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+
+	for {
+		select {
+		case h.err = <-done:
+			status <- svc.Status{State: svc.StopPending}
+			if h.err != nil {
+				// A non-zero exit code lets the failure actions restart the service
+				return false, 1
+			}
+			return false, 0
+		case req := <-requests:
+			switch req.Cmd {
+			case svc.Interrogate:
+				status <- req.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				status <- svc.Status{State: svc.StopPending}
+				cancel()
+			}
+		}
+	}
+}
@@ -0,0 +1,238 @@
+// Package service registers the inventory daemon as a managed OS service:
+// a systemd unit on Linux, a service of the service control manager on
+// Windows. On Windows, Run also serves the control manager while the daemon
+// runs.
+package service
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/defaults"
+	"github.com/braunma/idrac-netbox-importer/pkg/features"
+	"github.com/braunma/idrac-netbox-importer/pkg/logging"
+)
+
+// Options describes the service to install.
+type Options struct {
+	// Name of the systemd unit / Windows service (default: "idrac-inventory").
+	Name string
+
+	// Executable is the absolute path of the binary to run.
+	Executable string
+
+	// ConfigPath is the absolute path of the configuration file.
+	ConfigPath string
+
+	// StateDir overrides the state directory. If empty, systemd manages
+	// /var/lib/<name> via StateDirectory=.
+	StateDir string
+
+	// EnvFile is an optional KEY=VALUE file with secrets (e.g. IDRAC_DEFAULT_PASS).
+	EnvFile string
+
+	// User runs the service as this account (systemd only; default: root).
+	User string
+
+	// ExtraArgs are appended to the daemon command line (e.g. -sync).
+	ExtraArgs []string
+
+	// Start also starts the service after registering it.
+	Start bool
+}
+
+// SystemdUnitDir is where unit files are installed.
+var SystemdUnitDir = "/etc/systemd/system"
+
+func (o Options) name() string {
+	if o.Name == "" {
+		return defaults.AppName
+	}
+	return o.Name
+}
+
+// daemonArgs returns the command line arguments of the daemon process.
+func (o Options) daemonArgs() []string {
+	args := []string{"-daemon", "-config", o.ConfigPath}
+	if o.StateDir != "" {
+		args = append(args, "-state-dir", o.StateDir)
+	}
+	return append(args, o.ExtraArgs...)
+}
+
+// SystemdUnit renders the systemd unit file for the daemon.
+func SystemdUnit(o Options) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "[Unit]\n")
+	fmt.Fprintf(&b, "Description=iDRAC hardware inventory daemon\n")
+	fmt.Fprintf(&b, "Wants=network-online.target\n")
+	fmt.Fprintf(&b, "After=network-online.target\n\n")
+
+	fmt.Fprintf(&b, "[Service]\n")
+	fmt.Fprintf(&b, "Type=simple\n")
+	fmt.Fprintf(&b, "ExecStart=%s\n", systemdCommandLine(append([]string{o.Executable}, o.daemonArgs()...)))
+	if o.EnvFile != "" {
+		// "-" prefix: do not fail if the file is missing
+		fmt.Fprintf(&b, "EnvironmentFile=-%s\n", o.EnvFile)
+	}
+	if o.StateDir == "" {
+		fmt.Fprintf(&b, "StateDirectory=%s\n", o.name())
+	}
+	fmt.Fprintf(&b, "CacheDirectory=%s\n", o.name())
+	if o.User != "" {
+		fmt.Fprintf(&b, "User=%s\n", o.User)
+	}
+	fmt.Fprintf(&b, "Restart=on-failure\n")
+	fmt.Fprintf(&b, "RestartSec=30s\n\n")
+
+	fmt.Fprintf(&b, "[Install]\n")
+	fmt.Fprintf(&b, "WantedBy=multi-user.target\n")
+
+	return b.String()
+}
+
+// WindowsServiceArgs returns the sc.exe commands that register the daemon
+// as a service starting automatically at boot under LocalSystem and being
+// restarted 30s after a failure, like the systemd unit.
+func WindowsServiceArgs(o Options) [][]string {
+	args := o.daemonArgs()
+	if o.EnvFile != "" {
+		args = append([]string{"-env-file", o.EnvFile}, args...)
+	}
+	return [][]string{
+		{
+			"create", o.name(),
+			"binPath=", windowsCommandLine(append([]string{o.Executable}, args...)),
+			"start=", "auto",
+			"DisplayName=", "iDRAC hardware inventory daemon",
+		},
+		{"failure", o.name(), "reset=", "86400", "actions=", "restart/30000"},
+	}
+}
+
+// Install registers the service for the current OS. With dryRun the unit file or
+// commands are written to w instead of being applied.
+func Install(o Options, dryRun bool, w io.Writer) error {
+	if err := features.Check(features.ServiceInstall); err != nil {
+		return err
+	}
+	switch runtime.GOOS {
+	case "linux":
+		return installSystemd(o, dryRun, w)
+	case "windows":
+		return installWindowsService(o, dryRun, w)
+	default:
+		return fmt.Errorf("install-service is not supported on %s", runtime.GOOS)
+	}
+}
+
+func installSystemd(o Options, dryRun bool, w io.Writer) error {
+	unitPath := filepath.Join(SystemdUnitDir, o.name()+".service")
+	unit := SystemdUnit(o)
+
+	enable := []string{"enable", o.name()}
+	if o.Start {
+		enable = []string{"enable", "--now", o.name()}
+	}
+
+	if dryRun {
+		fmt.Fprintf(w, "# %s\n%s\n", unitPath, unit)
+		fmt.Fprintf(w, "systemctl daemon-reload\nsystemctl %s\n", strings.Join(enable, " "))
+		return nil
+	}
+
+	if err := os.WriteFile(unitPath, []byte(unit), 0o644); err != nil {
+		return fmt.Errorf("failed to write unit file: %w", err)
+	}
+	logging.Info("Wrote systemd unit", "path", unitPath)
+
+	if err := runCommand("systemctl", "daemon-reload"); err != nil {
+		return err
+	}
+	return runCommand("systemctl", enable...)
+}
+
+func installWindowsService(o Options, dryRun bool, w io.Writer) error {
+	commands := WindowsServiceArgs(o)
+	if o.Start {
+		commands = append(commands, []string{"start", o.name()})
+	}
+
+	if dryRun {
+		for _, args := range commands {
+			fmt.Fprintf(w, "sc.exe %s\n", windowsCommandLine(args))
+		}
+		return nil
+	}
+
+	for _, args := range commands {
+		if err := runCommand("sc.exe", args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runCommand(name string, args ...string) error {
+	out, err := exec.Command(name, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s %s failed: %w\n%s", name, strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// systemdCommandLine quotes arguments for ExecStart=. "%" and "$" are
+// doubled so systemd does not expand them as specifiers or variables.
+func systemdCommandLine(args []string) string {
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		if a == "" || strings.ContainsAny(a, " \t\"'\\") {
+			a = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(a) + `"`
+		}
+		quoted[i] = strings.NewReplacer("%", "%%", "$", "$$").Replace(a)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// windowsCommandLine joins arguments using Windows command line quoting.
+func windowsCommandLine(args []string) string {
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		quoted[i] = windowsQuote(a)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// windowsQuote quotes an argument the way CommandLineToArgvW parses it:
+// backslashes are literal unless they precede a quote, so those before an
+// embedded quote and before the closing quote are doubled.
+func windowsQuote(a string) string {
+	if a != "" && !strings.ContainsAny(a, " \t\"") {
+		return a
+	}
+
+	var b strings.Builder
+	b.WriteByte('"')
+	slashes := 0
+	for i := 0; i < len(a); i++ {
+		switch a[i] {
+		case '\\':
+			slashes++
+		case '"':
+			b.WriteString(strings.Repeat(`\`, slashes+1))
+			slashes = 0
+		default:
+			slashes = 0
+		}
+		b.WriteByte(a[i])
+	}
+	b.WriteString(strings.Repeat(`\`, slashes))
+	b.WriteByte('"')
+	return b.String()
+}
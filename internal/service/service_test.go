@@ -0,0 +1,129 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSystemdUnit(t *testing.T) {
+	unit := SystemdUnit(Options{
+		Executable: "/usr/local/bin/idrac-inventory",
+		ConfigPath: "/etc/idrac-inventory/config.yaml",
+		EnvFile:    "/etc/idrac-inventory/env",
+		User:       "inventory",
+		ExtraArgs:  []string{"-sync"},
+	})
+
+	assert.Contains(t, unit, "ExecStart=/usr/local/bin/idrac-inventory -daemon -config /etc/idrac-inventory/config.yaml -sync\n")
+	assert.Contains(t, unit, "EnvironmentFile=-/etc/idrac-inventory/env\n")
+	assert.Contains(t, unit, "StateDirectory=idrac-inventory\n")
+	assert.Contains(t, unit, "CacheDirectory=idrac-inventory\n")
+	assert.Contains(t, unit, "User=inventory\n")
+	assert.Contains(t, unit, "WantedBy=multi-user.target\n")
+}
+
+func TestSystemdUnit_ExplicitStateDir(t *testing.T) {
+	unit := SystemdUnit(Options{
+		Name:       "inventory",
+		Executable: "/opt/idrac inventory/bin",
+		ConfigPath: "/etc/inv.yaml",
+		StateDir:   "/srv/inventory",
+	})
+
+	assert.Contains(t, unit, `ExecStart="/opt/idrac inventory/bin" -daemon -config /etc/inv.yaml -state-dir /srv/inventory`)
+	assert.NotContains(t, unit, "StateDirectory=")
+	assert.Contains(t, unit, "CacheDirectory=inventory\n")
+}
+
+func TestSystemdCommandLine(t *testing.T) {
+	tests := map[string]struct {
+		args []string
+		want string
+	}{
+		"plain":     {[]string{"/bin/inv", "-sync"}, `/bin/inv -sync`},
+		"spaces":    {[]string{"/opt/my inv", "-config", "a b.yaml"}, `"/opt/my inv" -config "a b.yaml"`},
+		"specifier": {[]string{"/bin/inv", "-config", "/etc/%i.yaml"}, `/bin/inv -config /etc/%%i.yaml`},
+		"variable":  {[]string{"/bin/inv", "-config", "$HOME/c.yaml"}, `/bin/inv -config $$HOME/c.yaml`},
+		"quoted":    {[]string{"/bin/inv", `50% "$x"`}, `/bin/inv "50%% \"$$x\""`},
+		"empty":     {[]string{"/bin/inv", ""}, `/bin/inv ""`},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.want, systemdCommandLine(tt.args))
+		})
+	}
+}
+
+func TestWindowsQuote(t *testing.T) {
+	tests := map[string]string{
+		`C:\inv.exe`:           `C:\inv.exe`,
+		`C:\Program Files\inv`: `"C:\Program Files\inv"`,
+		`C:\my dir\`:           `"C:\my dir\\"`,
+		`C:\my dir\\`:          `"C:\my dir\\\\"`,
+		`say "hi"`:             `"say \"hi\""`,
+		`a\"b`:                 `"a\\\"b"`,
+		``:                     `""`,
+	}
+	for arg, want := range tests {
+		assert.Equal(t, want, windowsQuote(arg), arg)
+	}
+}
+
+func TestWindowsServiceArgs(t *testing.T) {
+	commands := WindowsServiceArgs(Options{
+		Executable: `C:\Program Files\idrac-inventory\idrac-inventory.exe`,
+		ConfigPath: `C:\ProgramData\idrac-inventory\config.yaml`,
+		EnvFile:    `C:\ProgramData\idrac-inventory\env`,
+		StateDir:   `C:\ProgramData\idrac inventory\`,
+	})
+
+	require.Len(t, commands, 2)
+	create := commands[0]
+	assert.Equal(t, []string{"create", "idrac-inventory", "binPath="}, create[:3])
+	assert.Equal(t,
+		`"C:\Program Files\idrac-inventory\idrac-inventory.exe" -env-file C:\ProgramData\idrac-inventory\env -daemon -config C:\ProgramData\idrac-inventory\config.yaml -state-dir "C:\ProgramData\idrac inventory\\"`,
+		create[3])
+	assert.Equal(t, []string{"start=", "auto"}, create[4:6])
+	assert.Equal(t, []string{"failure", "idrac-inventory", "reset=", "86400", "actions=", "restart/30000"}, commands[1])
+}
+
+func TestRun(t *testing.T) {
+	type key struct{}
+
+	// Outside the service control manager fn is called directly
+	ctx := context.WithValue(context.Background(), key{}, "daemon")
+	err := Run(ctx, func(got context.Context) error {
+		assert.Equal(t, "daemon", got.Value(key{}))
+		return assert.AnError
+	})
+	assert.ErrorIs(t, err, assert.AnError)
+}
+
+func TestInstall_DryRun(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("systemd dry run only on linux")
+	}
+
+	var buf bytes.Buffer
+	err := Install(Options{Executable: "/bin/idrac-inventory", ConfigPath: "/etc/c.yaml", Start: true}, true, &buf)
+
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "# /etc/systemd/system/idrac-inventory.service")
+	assert.Contains(t, buf.String(), "systemctl enable --now idrac-inventory")
+}
+
+func TestInstallWindowsService_DryRun(t *testing.T) {
+	var buf bytes.Buffer
+	err := installWindowsService(Options{Executable: `C:\inv.exe`, ConfigPath: `C:\c.yaml`, Start: true}, true, &buf)
+
+	require.NoError(t, err)
+	assert.Equal(t, `sc.exe create idrac-inventory binPath= "C:\inv.exe -daemon -config C:\c.yaml" start= auto DisplayName= "iDRAC hardware inventory daemon"
+sc.exe failure idrac-inventory reset= 86400 actions= restart/30000
+sc.exe start idrac-inventory
+`, buf.String())
+}
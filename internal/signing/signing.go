@@ -0,0 +1,232 @@
+// Package signing signs generated inventory files with an ed25519 key and
+// verifies them, so consumers can prove a report has not been modified since
+// it was produced. Signatures are written to a sidecar file next to the
+// report (<file>.sig).
+package signing
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Suffix is appended to a file name to get its signature file.
+const Suffix = ".sig"
+
+// Algorithm is the only supported signature algorithm.
+const Algorithm = "ed25519"
+
+// ErrInvalidSignature is returned when a file does not match its signature.
+var ErrInvalidSignature = errors.New("signature verification failed")
+
+// Signature is the content of a .sig file.
+type Signature struct {
+	Algorithm string    `json:"algorithm"`
+	KeyID     string    `json:"key_id"`
+	File      string    `json:"file"`
+	SHA256    string    `json:"sha256"`
+	Signature string    `json:"signature"` // base64 ed25519 signature of the file content
+	SignedAt  time.Time `json:"signed_at"`
+}
+
+// Signer signs files with an ed25519 private key. A nil *Signer does not
+// sign, so callers do not need to check whether signing is enabled.
+type Signer struct {
+	key ed25519.PrivateKey
+}
+
+// NewSigner loads a PEM-encoded PKCS#8 ed25519 private key, e.g. created with
+// "openssl genpkey -algorithm ed25519 -out signing-key.pem".
+func NewSigner(keyPath string) (*Signer, error) {
+	block, err := readPEM(keyPath)
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", keyPath, err)
+	}
+	edKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%s: not an ed25519 private key", keyPath)
+	}
+	return &Signer{key: edKey}, nil
+}
+
+// PublicKey returns the public half of the signing key.
+func (s *Signer) PublicKey() ed25519.PublicKey {
+	return s.key.Public().(ed25519.PublicKey)
+}
+
+// SignFile signs the file at path and writes the signature to path+Suffix.
+// It returns the path of the signature file ("" for a nil Signer).
+func (s *Signer) SignFile(path string) (string, error) {
+	if s == nil {
+		return "", nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	out, err := s.Sign(filepath.Base(path), data)
+	if err != nil {
+		return "", err
+	}
+	sigPath := path + Suffix
+	if err := os.WriteFile(sigPath, out, 0o644); err != nil {
+		return "", err
+	}
+	return sigPath, nil
+}
+
+// Sign returns the content of the .sig file for data stored under name, for
+// reports that are not written to a local file, e.g. uploads to S3. A nil
+// Signer returns nil.
+func (s *Signer) Sign(name string, data []byte) ([]byte, error) {
+	if s == nil {
+		return nil, nil
+	}
+
+	sum := sha256.Sum256(data)
+	sig := Signature{
+		Algorithm: Algorithm,
+		KeyID:     KeyID(s.PublicKey()),
+		File:      name,
+		SHA256:    hex.EncodeToString(sum[:]),
+		Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(s.key, data)),
+		SignedAt:  time.Now().UTC(),
+	}
+
+	out, err := json.MarshalIndent(sig, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
+
+// WriteFile writes data to path atomically (through a temporary file in the
+// same directory) and signs it. The signature is written before the file is
+// renamed into place, so a reader never sees a new report next to the
+// signature of the previous one. A nil Signer only writes the file.
+func (s *Signer) WriteFile(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if s != nil {
+		sig, err := s.Sign(filepath.Base(path), data)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(path+Suffix, sig, 0o644); err != nil {
+			return fmt.Errorf("failed to write signature: %w", err)
+		}
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// LoadPublicKey loads a PEM-encoded ed25519 public key, e.g. created with
+// "openssl pkey -in signing-key.pem -pubout -out signing-key.pub.pem".
+// A private key file is accepted as well.
+func LoadPublicKey(path string) (ed25519.PublicKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if block.Type == "PRIVATE KEY" {
+		s, err := NewSigner(path)
+		if err != nil {
+			return nil, err
+		}
+		return s.PublicKey(), nil
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	edKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("%s: not an ed25519 public key", path)
+	}
+	return edKey, nil
+}
+
+// VerifyFile checks the file at path against its signature file (path+Suffix).
+// It returns the parsed signature, and ErrInvalidSignature if the content was
+// modified or was signed with a different key.
+func VerifyFile(path string, pub ed25519.PublicKey) (*Signature, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := os.ReadFile(path + Suffix)
+	if err != nil {
+		return nil, fmt.Errorf("no signature found: %w", err)
+	}
+
+	var sig Signature
+	if err := json.Unmarshal(raw, &sig); err != nil {
+		return nil, fmt.Errorf("%s%s: %w", path, Suffix, err)
+	}
+	if sig.Algorithm != Algorithm {
+		return &sig, fmt.Errorf("unsupported signature algorithm %q", sig.Algorithm)
+	}
+	if id := KeyID(pub); sig.KeyID != id {
+		return &sig, fmt.Errorf("%w: signed with key %s, expected %s", ErrInvalidSignature, sig.KeyID, id)
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(sig.Signature)
+	if err != nil {
+		return &sig, fmt.Errorf("%s%s: invalid signature encoding: %w", path, Suffix, err)
+	}
+	if !ed25519.Verify(pub, data, signature) {
+		return &sig, ErrInvalidSignature
+	}
+	return &sig, nil
+}
+
+// KeyID returns a short fingerprint of a public key
+// (the first 8 bytes of its SHA-256, hex encoded).
+func KeyID(pub ed25519.PublicKey) string {
+	sum := sha256.Sum256(pub)
+	return hex.EncodeToString(sum[:8])
+}
+
+func readPEM(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no PEM data found", path)
+	}
+	return block, nil
+}
@@ -0,0 +1,134 @@
+package signing
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeKeys generates an ed25519 key pair and writes it as PEM files.
+func writeKeys(t *testing.T, dir string) (privPath, pubPath string) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	privDER, err := x509.MarshalPKCS8PrivateKey(priv)
+	require.NoError(t, err)
+	pubDER, err := x509.MarshalPKIXPublicKey(pub)
+	require.NoError(t, err)
+
+	privPath = filepath.Join(dir, "key.pem")
+	pubPath = filepath.Join(dir, "key.pub.pem")
+	require.NoError(t, os.WriteFile(privPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privDER}), 0o600))
+	require.NoError(t, os.WriteFile(pubPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}), 0o644))
+	return privPath, pubPath
+}
+
+func TestSignAndVerify(t *testing.T) {
+	dir := t.TempDir()
+	privPath, pubPath := writeKeys(t, dir)
+	report := filepath.Join(dir, "hardware-inventory.json")
+	require.NoError(t, os.WriteFile(report, []byte(`{"total_servers": 3}`), 0o644))
+
+	signer, err := NewSigner(privPath)
+	require.NoError(t, err)
+	sigPath, err := signer.SignFile(report)
+	require.NoError(t, err)
+	assert.Equal(t, report+Suffix, sigPath)
+
+	pub, err := LoadPublicKey(pubPath)
+	require.NoError(t, err)
+	sig, err := VerifyFile(report, pub)
+	require.NoError(t, err)
+	assert.Equal(t, "hardware-inventory.json", sig.File)
+	assert.Equal(t, KeyID(pub), sig.KeyID)
+
+	// Any modification invalidates the signature
+	require.NoError(t, os.WriteFile(report, []byte(`{"total_servers": 4}`), 0o644))
+	_, err = VerifyFile(report, pub)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+}
+
+func TestVerifyFile_WrongKey(t *testing.T) {
+	dir := t.TempDir()
+	privPath, _ := writeKeys(t, dir)
+	report := filepath.Join(dir, "report.md")
+	require.NoError(t, os.WriteFile(report, []byte("# Inventory\n"), 0o644))
+
+	signer, err := NewSigner(privPath)
+	require.NoError(t, err)
+	_, err = signer.SignFile(report)
+	require.NoError(t, err)
+
+	other := t.TempDir()
+	_, otherPub := writeKeys(t, other)
+	pub, err := LoadPublicKey(otherPub)
+	require.NoError(t, err)
+
+	_, err = VerifyFile(report, pub)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+}
+
+func TestLoadPublicKey_FromPrivateKey(t *testing.T) {
+	privPath, pubPath := writeKeys(t, t.TempDir())
+
+	fromPriv, err := LoadPublicKey(privPath)
+	require.NoError(t, err)
+	fromPub, err := LoadPublicKey(pubPath)
+	require.NoError(t, err)
+	assert.Equal(t, fromPub, fromPriv)
+}
+
+func TestNilSigner(t *testing.T) {
+	var s *Signer
+	path, err := s.SignFile("does-not-matter")
+	assert.NoError(t, err)
+	assert.Empty(t, path)
+}
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	privPath, _ := writeKeys(t, dir)
+	signer, err := NewSigner(privPath)
+	require.NoError(t, err)
+	report := filepath.Join(dir, "servers-0001.json")
+
+	for _, content := range []string{`{"total_servers": 3}`, `{"total_servers": 4}`} {
+		require.NoError(t, signer.WriteFile(report, []byte(content), 0o640))
+
+		data, err := os.ReadFile(report)
+		require.NoError(t, err)
+		assert.Equal(t, content, string(data))
+		sig, err := VerifyFile(report, signer.PublicKey())
+		require.NoError(t, err)
+		assert.Equal(t, "servers-0001.json", sig.File)
+	}
+
+	info, err := os.Stat(report)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o640), info.Mode().Perm())
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 4, "no temporary files are left behind")
+}
+
+func TestWriteFile_NilSigner(t *testing.T) {
+	var s *Signer
+	report := filepath.Join(t.TempDir(), "last-scan.json")
+	require.NoError(t, s.WriteFile(report, []byte("{}"), 0o600))
+
+	assert.FileExists(t, report)
+	assert.NoFileExists(t, report+Suffix)
+
+	sig, err := s.Sign("last-scan.json", []byte("{}"))
+	assert.NoError(t, err)
+	assert.Nil(t, sig)
+}
@@ -0,0 +1,213 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/braunma/idrac-netbox-importer/internal/signing"
+	"github.com/braunma/idrac-netbox-importer/pkg/config"
+)
+
+// S3 stores the output as an object in an S3 bucket (AWS or a compatible
+// store such as MinIO), signed with AWS Signature Version 4.
+type S3 struct {
+	Endpoint     string
+	Region       string
+	Bucket       string
+	Key          string
+	AccessKey    string
+	SecretKey    string
+	SessionToken string
+	PathStyle    bool
+	ContentType  string
+	Client       *http.Client
+
+	// Now is the signing time.
+	Now time.Time
+
+	// Signer signs the output, which is stored first as Key+".sig".
+	Signer *signing.Signer
+}
+
+// NewS3 returns a sink that stores the output under key in the bucket of cfg.
+func NewS3(cfg config.S3Config, key, contentType string, now time.Time, client *http.Client) *S3 {
+	accessKey, secretKey, sessionToken := cfg.Credentials()
+	return &S3{
+		Endpoint:     cfg.GetEndpoint(),
+		Region:       cfg.GetRegion(),
+		Bucket:       cfg.Bucket,
+		Key:          key,
+		AccessKey:    accessKey,
+		SecretKey:    secretKey,
+		SessionToken: sessionToken,
+		PathStyle:    cfg.PathStyle,
+		ContentType:  contentType,
+		Client:       client,
+		Now:          now,
+	}
+}
+
+// Write implements Sink.
+func (s *S3) Write(ctx context.Context, data []byte) error {
+	if s.Signer != nil {
+		sig, err := s.Signer.Sign(path.Base(s.Key), data)
+		if err != nil {
+			return err
+		}
+		if err := s.put(ctx, s.Key+signing.Suffix, "application/json", sig); err != nil {
+			return fmt.Errorf("signature: %w", err)
+		}
+	}
+	return s.put(ctx, s.Key, s.ContentType, data)
+}
+
+// put stores data as the object key.
+func (s *S3) put(ctx context.Context, key, contentType string, data []byte) error {
+	u, err := s.objectURL(key)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, u.String(), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	if s.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.SessionToken)
+	}
+	payloadHash := sha256Hex(data)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	signV4(req, payloadHash, s.AccessKey, s.SecretKey, s.Region, "s3", s.Now)
+	return do(s.Client, req)
+}
+
+func (s *S3) String() string {
+	return "s3://" + s.Bucket + "/" + s.Key
+}
+
+// objectURL returns the URL of the object key, with the bucket in the path
+// (path style) or in the host name (virtual-hosted style).
+func (s *S3) objectURL(key string) (*url.URL, error) {
+	u, err := url.Parse(s.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid S3 endpoint %q: %w", s.Endpoint, err)
+	}
+	objectPath := "/" + key
+	if s.PathStyle {
+		objectPath = "/" + s.Bucket + objectPath
+	} else {
+		u.Host = s.Bucket + "." + u.Host
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + objectPath
+	u.RawPath = uriEncode(u.Path, false)
+	return u, nil
+}
+
+// signV4 adds the X-Amz-Date and Authorization headers of AWS Signature
+// Version 4 to req, signing the host and all headers already set.
+func signV4(req *http.Request, payloadHash, accessKey, secretKey, region, service string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+	signature := hex.EncodeToString(hmacSHA256(signingKey(secretKey, date, region, service), stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKey, scope, signedHeaders, signature))
+}
+
+// signingKey derives the key of a day, region and service from the secret key.
+func signingKey(secretKey, date, region, service string) []byte {
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	return hmacSHA256(key, "aws4_request")
+}
+
+// canonicalQuery returns the query parameters sorted and URI-encoded.
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, k := range keys {
+		values := query[k]
+		sort.Strings(values)
+		for _, v := range values {
+			parts = append(parts, uriEncode(k, true)+"="+uriEncode(v, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// uriEncode percent-encodes all characters but the unreserved ones
+// (A-Z a-z 0-9 - _ . ~), and the slash unless encodeSlash is set.
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,132 @@
+package sink
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/braunma/idrac-netbox-importer/internal/signing"
+	"github.com/braunma/idrac-netbox-importer/internal/testutil"
+)
+
+const exampleSecretKey = "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"
+
+func TestSigningKey(t *testing.T) {
+	// Example from the AWS Signature Version 4 documentation
+	key := signingKey(exampleSecretKey, "20120215", "us-east-1", "iam")
+	assert.Equal(t, "f4780e2d9f65fa895f9c67b32ce1baf0b0d8a43505a000a1a9e090d414db404d", hex.EncodeToString(key))
+}
+
+func TestSignV4(t *testing.T) {
+	// "get-vanilla" of the AWS Signature Version 4 test suite
+	req, err := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	require.NoError(t, err)
+	signV4(req, sha256Hex(nil), "AKIDEXAMPLE", exampleSecretKey, "us-east-1", "service",
+		time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+
+	assert.Equal(t, "20150830T123600Z", req.Header.Get("X-Amz-Date"))
+	assert.Equal(t, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, "+
+		"SignedHeaders=host;x-amz-date, "+
+		"Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		req.Header.Get("Authorization"))
+}
+
+func TestS3_Write(t *testing.T) {
+	var (
+		gotPath, gotAuth, gotHash, gotType string
+		gotBody                            []byte
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		gotPath = r.URL.EscapedPath()
+		gotAuth = r.Header.Get("Authorization")
+		gotHash = r.Header.Get("X-Amz-Content-Sha256")
+		gotType = r.Header.Get("Content-Type")
+		gotBody, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	s := &S3{
+		Endpoint:    server.URL,
+		Region:      "eu-central-1",
+		Bucket:      "inventory",
+		Key:         "dc1/inventory-20261016T120000Z.json",
+		AccessKey:   "AKIDEXAMPLE",
+		SecretKey:   exampleSecretKey,
+		PathStyle:   true,
+		ContentType: "application/json",
+		Client:      server.Client(),
+		Now:         time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC),
+	}
+	require.NoError(t, s.Write(context.Background(), []byte(`{"servers":[]}`)))
+
+	assert.Equal(t, "/inventory/dc1/inventory-20261016T120000Z.json", gotPath)
+	assert.Equal(t, `{"servers":[]}`, string(gotBody))
+	assert.Equal(t, sha256Hex(gotBody), gotHash)
+	assert.Equal(t, "application/json", gotType)
+	assert.True(t, strings.HasPrefix(gotAuth, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20261016/eu-central-1/s3/aws4_request, "+
+		"SignedHeaders=content-type;host;x-amz-content-sha256;x-amz-date, Signature="), gotAuth)
+	assert.Equal(t, "s3://inventory/dc1/inventory-20261016T120000Z.json", s.String())
+}
+
+func TestS3_Write_Signed(t *testing.T) {
+	var paths []string
+	var sig signing.Signature
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if strings.HasSuffix(r.URL.Path, signing.Suffix) {
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&sig))
+		}
+	}))
+	defer server.Close()
+
+	s := &S3{
+		Endpoint:  server.URL,
+		Bucket:    "inventory",
+		Key:       "dc1/inventory.csv",
+		PathStyle: true,
+		Client:    server.Client(),
+		Signer:    testutil.NewSigner(t),
+	}
+	require.NoError(t, s.Write(context.Background(), []byte("host\n")))
+
+	assert.Equal(t, []string{"/inventory/dc1/inventory.csv.sig", "/inventory/dc1/inventory.csv"}, paths)
+	assert.Equal(t, "inventory.csv", sig.File)
+	assert.Equal(t, sha256Hex([]byte("host\n")), sig.SHA256)
+}
+
+func TestS3_ObjectURL(t *testing.T) {
+	s := &S3{Endpoint: "https://s3.eu-central-1.amazonaws.com", Bucket: "inventory", Key: "a b+c.json"}
+	u, err := s.objectURL(s.Key)
+	require.NoError(t, err)
+	assert.Equal(t, "https://inventory.s3.eu-central-1.amazonaws.com/a%20b%2Bc.json", u.String())
+
+	s.PathStyle = true
+	u, err = s.objectURL(s.Key)
+	require.NoError(t, err)
+	assert.Equal(t, "https://s3.eu-central-1.amazonaws.com/inventory/a%20b%2Bc.json", u.String())
+}
+
+func TestS3_WriteError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = io.WriteString(w, "<Error><Code>SignatureDoesNotMatch</Code></Error>")
+	}))
+	defer server.Close()
+
+	s := &S3{Endpoint: server.URL, Bucket: "inventory", Key: "k.json", PathStyle: true, Client: server.Client()}
+	err := s.Write(context.Background(), []byte("{}"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "HTTP 403")
+	assert.Contains(t, err.Error(), "SignatureDoesNotMatch")
+}
@@ -0,0 +1,215 @@
+// Package sink writes rendered output to its destination: stdout, a local
+// file, an S3-compatible object store or an HTTP endpoint. Sinks only move
+// bytes; the format is chosen by the caller (see config.SinkConfig), so any
+// formatter can be combined with any destination.
+package sink
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/braunma/idrac-netbox-importer/internal/readonly"
+	"github.com/braunma/idrac-netbox-importer/internal/signing"
+	"github.com/braunma/idrac-netbox-importer/pkg/config"
+)
+
+// TimestampFormat is the format {timestamp} expands to.
+const TimestampFormat = "20060102T150405Z"
+
+// Sink is a destination of rendered output.
+type Sink interface {
+	// Write stores the output of one run.
+	Write(ctx context.Context, data []byte) error
+
+	// String describes the destination for logs, e.g. "s3://bucket/key".
+	String() string
+}
+
+// New returns the sink of cfg. now is the time of the run, which {timestamp}
+// and S3 keys expand to; with readOnly, uploads to S3 and HTTP sinks are
+// refused. With a signer, file, S3 and HTTP sinks store a .sig next to the
+// output; stdout is not signed.
+func New(cfg config.SinkConfig, now time.Time, readOnly bool, signer *signing.Signer) (Sink, error) {
+	ts := now.UTC().Format(TimestampFormat)
+	contentType := ContentType(cfg.GetFormat(), cfg.Compress)
+
+	var transport http.RoundTripper = http.DefaultTransport
+	if readOnly {
+		transport = readonly.Transport(transport)
+	}
+	client := &http.Client{Timeout: cfg.Timeout(), Transport: transport}
+
+	switch strings.ToLower(cfg.Type) {
+	case config.SinkStdout:
+		return &Writer{W: os.Stdout, Name: "stdout"}, nil
+	case config.SinkFile:
+		return &File{Path: expand(cfg.Path, ts), Signer: signer}, nil
+	case config.SinkHTTP:
+		return &HTTP{
+			URL:         expand(cfg.URL, ts),
+			Headers:     cfg.Headers,
+			ContentType: contentType,
+			Client:      client,
+			Signer:      signer,
+		}, nil
+	case config.SinkS3:
+		s3 := NewS3(cfg.S3, cfg.S3.Prefix+"inventory-"+ts+Extension(cfg.GetFormat(), cfg.Compress), contentType, now, client)
+		s3.Signer = signer
+		return s3, nil
+	}
+	return nil, fmt.Errorf("unknown sink type %q", cfg.Type)
+}
+
+// expand replaces {timestamp} in s.
+func expand(s, timestamp string) string {
+	return strings.ReplaceAll(s, "{timestamp}", timestamp)
+}
+
+// Extension returns the file extension of an output format, with .gz
+// appended for compressed output.
+func Extension(format string, compress bool) string {
+	ext := ".txt"
+	switch format {
+	case "json":
+		ext = ".json"
+	case "csv":
+		ext = ".csv"
+	case "markdown":
+		ext = ".md"
+	case "junit":
+		ext = ".xml"
+	}
+	if compress {
+		ext += ".gz"
+	}
+	return ext
+}
+
+// ContentType returns the MIME type of an output format.
+func ContentType(format string, compress bool) string {
+	if compress {
+		return "application/gzip"
+	}
+	switch format {
+	case "json":
+		return "application/json"
+	case "csv":
+		return "text/csv; charset=utf-8"
+	case "markdown":
+		return "text/markdown; charset=utf-8"
+	case "junit":
+		return "application/xml"
+	}
+	return "text/plain; charset=utf-8"
+}
+
+// Writer writes the output to a stream such as stdout.
+type Writer struct {
+	W    io.Writer
+	Name string
+}
+
+// Write implements Sink.
+func (s *Writer) Write(_ context.Context, data []byte) error {
+	_, err := s.W.Write(data)
+	return err
+}
+
+func (s *Writer) String() string {
+	return s.Name
+}
+
+// File writes the output to a local file, replacing it atomically and
+// creating its directory if needed.
+type File struct {
+	Path string
+
+	// Signer signs the file into Path+".sig" before it is renamed into place.
+	Signer *signing.Signer
+}
+
+// Write implements Sink.
+func (s *File) Write(_ context.Context, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0o750); err != nil {
+		return err
+	}
+	return s.Signer.WriteFile(s.Path, data, 0o600)
+}
+
+func (s *File) String() string {
+	return s.Path
+}
+
+// HTTP sends the output to a URL with PUT, e.g. a WebDAV share or an
+// artifact repository.
+type HTTP struct {
+	URL         string
+	Headers     map[string]string
+	ContentType string
+	Client      *http.Client
+
+	// Signer signs the output, which is uploaded first to the URL with
+	// ".sig" appended to its path.
+	Signer *signing.Signer
+}
+
+// Write implements Sink.
+func (s *HTTP) Write(ctx context.Context, data []byte) error {
+	if s.Signer != nil {
+		u, err := url.Parse(s.URL)
+		if err != nil {
+			return err
+		}
+		sig, err := s.Signer.Sign(path.Base(u.Path), data)
+		if err != nil {
+			return err
+		}
+		u.Path += signing.Suffix
+		u.RawPath = ""
+		if err := s.put(ctx, u.String(), "application/json", sig); err != nil {
+			return fmt.Errorf("signature: %w", err)
+		}
+	}
+	return s.put(ctx, s.URL, s.ContentType, data)
+}
+
+// put uploads data to rawURL with the configured headers.
+func (s *HTTP) put(ctx context.Context, rawURL, contentType string, data []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, rawURL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	for name, value := range s.Headers {
+		req.Header.Set(name, value)
+	}
+	return do(s.Client, req)
+}
+
+func (s *HTTP) String() string {
+	return s.URL
+}
+
+// do sends an upload and fails on responses other than 2xx.
+func do(client *http.Client, req *http.Request) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("PUT %s: HTTP %d: %s", req.URL.Redacted(), resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
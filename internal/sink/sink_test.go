@@ -0,0 +1,145 @@
+package sink
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/braunma/idrac-netbox-importer/internal/signing"
+	"github.com/braunma/idrac-netbox-importer/internal/testutil"
+	"github.com/braunma/idrac-netbox-importer/pkg/config"
+	"github.com/braunma/idrac-netbox-importer/pkg/errors"
+)
+
+var runTime = time.Date(2026, 10, 16, 12, 30, 0, 0, time.UTC)
+
+func TestNew(t *testing.T) {
+	s, err := New(config.SinkConfig{Type: "file", Path: "/archive/inventory-{timestamp}.csv", Format: "csv"}, runTime, false, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "/archive/inventory-20261016T123000Z.csv", s.String())
+
+	s, err = New(config.SinkConfig{Type: "s3", Compress: true, S3: config.S3Config{
+		Bucket: "inventory", Prefix: "dc1/", AccessKey: "AKIDEXAMPLE", SecretKey: "secret",
+	}}, runTime, false, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "s3://inventory/dc1/inventory-20261016T123000Z.json.gz", s.String())
+	assert.Equal(t, "application/gzip", s.(*S3).ContentType)
+
+	s, err = New(config.SinkConfig{Type: "stdout"}, runTime, false, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "stdout", s.String())
+
+	_, err = New(config.SinkConfig{Type: "ftp"}, runTime, false, nil)
+	assert.Error(t, err)
+}
+
+func TestFile_Write(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "archive", "inventory.json")
+	s := &File{Path: path}
+
+	require.NoError(t, s.Write(context.Background(), []byte("first")))
+	require.NoError(t, s.Write(context.Background(), []byte("second")))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "second", string(data))
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "no temporary files left behind")
+}
+
+func TestFile_Write_Signed(t *testing.T) {
+	signer := testutil.NewSigner(t)
+	path := filepath.Join(t.TempDir(), "inventory.json")
+	s, err := New(config.SinkConfig{Type: "file", Path: path}, runTime, false, signer)
+	require.NoError(t, err)
+
+	for _, content := range []string{"first", "second"} {
+		require.NoError(t, s.Write(context.Background(), []byte(content)))
+		_, err := signing.VerifyFile(path, signer.PublicKey())
+		require.NoError(t, err)
+	}
+}
+
+func TestHTTP_Write(t *testing.T) {
+	var gotMethod, gotAuth, gotType, gotBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMethod = r.Method
+		gotAuth = r.Header.Get("Authorization")
+		gotType = r.Header.Get("Content-Type")
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	s, err := New(config.SinkConfig{
+		Type:    "http",
+		URL:     server.URL + "/reports/{timestamp}.md",
+		Format:  "markdown",
+		Headers: map[string]string{"Authorization": "Bearer token"},
+	}, runTime, false, nil)
+	require.NoError(t, err)
+	assert.Equal(t, server.URL+"/reports/20261016T123000Z.md", s.String())
+
+	require.NoError(t, s.Write(context.Background(), []byte("# Inventory")))
+	assert.Equal(t, http.MethodPut, gotMethod)
+	assert.Equal(t, "Bearer token", gotAuth)
+	assert.Equal(t, "text/markdown; charset=utf-8", gotType)
+	assert.Equal(t, "# Inventory", gotBody)
+}
+
+func TestHTTP_Write_Signed(t *testing.T) {
+	signer := testutil.NewSigner(t)
+	dir := t.TempDir()
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		body, _ := io.ReadAll(r.Body)
+		_ = os.WriteFile(filepath.Join(dir, filepath.Base(r.URL.Path)), body, 0o644)
+	}))
+	defer server.Close()
+
+	s, err := New(config.SinkConfig{Type: "http", URL: server.URL + "/reports/inventory.json?overwrite=true"}, runTime, false, signer)
+	require.NoError(t, err)
+	require.NoError(t, s.Write(context.Background(), []byte(`{"servers":[]}`)))
+
+	// The signature is uploaded first, so the report never lacks one
+	assert.Equal(t, []string{"/reports/inventory.json.sig", "/reports/inventory.json"}, paths)
+	sig, err := signing.VerifyFile(filepath.Join(dir, "inventory.json"), signer.PublicKey())
+	require.NoError(t, err)
+	assert.Equal(t, "inventory.json", sig.File)
+}
+
+func TestHTTP_ReadOnly(t *testing.T) {
+	var uploads int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		uploads++
+	}))
+	defer server.Close()
+
+	s, err := New(config.SinkConfig{Type: "http", URL: server.URL}, runTime, true, nil)
+	require.NoError(t, err)
+
+	err = s.Write(context.Background(), []byte("{}"))
+	require.Error(t, err)
+	assert.ErrorIs(t, err, errors.ErrReadOnly)
+	assert.Zero(t, uploads)
+}
+
+func TestExtension(t *testing.T) {
+	assert.Equal(t, ".json", Extension("json", false))
+	assert.Equal(t, ".csv.gz", Extension("csv", true))
+	assert.Equal(t, ".md", Extension("markdown", false))
+	assert.Equal(t, ".txt", Extension("table", false))
+	assert.Equal(t, ".xml", Extension("junit", false))
+}
@@ -0,0 +1,286 @@
+// Package slack posts a compact summary of each run to a Slack incoming
+// webhook: the totals, the hosts that failed since the last run and the
+// largest hardware changes, with a link to the full report. Unlike an HTTP
+// output sink, which uploads the rendered results, it sends a Block Kit
+// message meant to be read in a channel.
+package slack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/braunma/idrac-netbox-importer/internal/readonly"
+	"github.com/braunma/idrac-netbox-importer/pkg/config"
+	"github.com/braunma/idrac-netbox-importer/pkg/errors"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+// maxReasonLength limits the error message shown per failed host.
+const maxReasonLength = 100
+
+// Summary is what a run summary reports.
+type Summary struct {
+	Stats models.CollectionStats
+
+	// Compared is false if there was no previous run to compare with; all
+	// failures are then new and no drift is known.
+	Compared bool
+
+	// NewFailures are the failed (or stale) hosts that did not fail in the
+	// previous run, sorted by host.
+	NewFailures []models.ServerInfo
+
+	// Drift are the servers whose hardware changed since the previous run,
+	// those with the most changes first.
+	Drift []models.HostDiff
+}
+
+// Summarize compares the results of a run with those of the previous run
+// (nil if unknown).
+func Summarize(prev, results []models.ServerInfo, stats models.CollectionStats) Summary {
+	s := Summary{Stats: stats, Compared: prev != nil}
+
+	prevFailed := make(map[string]bool)
+	for _, p := range prev {
+		if failed(p) {
+			prevFailed[p.Key()] = true
+		}
+	}
+	for _, r := range results {
+		if failed(r) && !prevFailed[r.Key()] {
+			s.NewFailures = append(s.NewFailures, r)
+		}
+	}
+	sort.SliceStable(s.NewFailures, func(i, j int) bool { return s.NewFailures[i].Key() < s.NewFailures[j].Key() })
+
+	if s.Compared {
+		s.Drift = models.CompareFleets(prev, results).Changed
+		sort.SliceStable(s.Drift, func(i, j int) bool {
+			if len(s.Drift[i].Changes) != len(s.Drift[j].Changes) {
+				return len(s.Drift[i].Changes) > len(s.Drift[j].Changes)
+			}
+			return s.Drift[i].Host < s.Drift[j].Host
+		})
+	}
+	return s
+}
+
+// failed reports whether the scan of a host failed, including hosts
+// reported with their last known good inventory.
+func failed(s models.ServerInfo) bool {
+	return s.Error != nil || s.Stale
+}
+
+// Changed reports whether hosts newly failed or hardware changed.
+func (s Summary) Changed() bool {
+	return len(s.NewFailures) > 0 || len(s.Drift) > 0
+}
+
+// changeCount returns the number of hardware changes.
+func (s Summary) changeCount() int {
+	n := 0
+	for _, d := range s.Drift {
+		n += len(d.Changes)
+	}
+	return n
+}
+
+// Message is the webhook payload.
+type Message struct {
+	Channel string  `json:"channel,omitempty"`
+	Text    string  `json:"text"` // shown in notifications and by clients without blocks
+	Blocks  []Block `json:"blocks"`
+}
+
+// Block is a Block Kit layout block: a section with Text, or a context
+// with Elements.
+type Block struct {
+	Type     string `json:"type"`
+	Text     *Text  `json:"text,omitempty"`
+	Elements []Text `json:"elements,omitempty"`
+}
+
+// Text is a Block Kit text object.
+type Text struct {
+	Type string `json:"type"` // mrkdwn or plain_text
+	Text string `json:"text"`
+}
+
+func section(text string) Block {
+	return Block{Type: "section", Text: &Text{Type: "mrkdwn", Text: text}}
+}
+
+// Notifier posts run summaries to the webhook of its configuration.
+type Notifier struct {
+	cfg        config.SlackConfig
+	httpClient *http.Client
+}
+
+// New returns a notifier for cfg. With readOnly, the webhook request is
+// refused.
+func New(cfg config.SlackConfig, readOnly bool) *Notifier {
+	var transport http.RoundTripper = http.DefaultTransport
+	if readOnly {
+		transport = readonly.Transport(transport)
+	}
+	return &Notifier{cfg: cfg, httpClient: &http.Client{Timeout: cfg.Timeout(), Transport: transport}}
+}
+
+// Message returns the payload of a run summary.
+func (n *Notifier) Message(s Summary) Message {
+	st := s.Stats
+	maxItems := n.cfg.GetMaxItems()
+
+	title := "*iDRAC inventory*"
+	if st.RunID != "" {
+		title += fmt.Sprintf(" · run `%s`", escape(st.RunID))
+	}
+	if st.Shard != "" {
+		title += fmt.Sprintf(" · shard %s", escape(st.Shard))
+	}
+
+	failures := fmt.Sprintf("*%d* failed", st.FailedCount)
+	if s.Compared {
+		failures += fmt.Sprintf(" (%d new)", len(s.NewFailures))
+	}
+	totals := fmt.Sprintf("*%d* servers: *%d* OK, %s", st.TotalServers, st.SuccessfulCount, failures)
+	if st.StaleCount > 0 {
+		totals += fmt.Sprintf(", %d stale", st.StaleCount)
+	}
+	totals += fmt.Sprintf(" · %.1f%% success in %s", st.SuccessRate(), st.TotalDuration.Round(time.Second))
+	if s.Compared {
+		totals += fmt.Sprintf("\n*%d* hardware changes on %d servers", s.changeCount(), len(s.Drift))
+	} else {
+		totals += "\n_No previous run to compare with_"
+	}
+
+	msg := Message{
+		Channel: n.cfg.Channel,
+		Text: fmt.Sprintf("iDRAC inventory: %d/%d servers OK, %d failed",
+			st.SuccessfulCount, st.TotalServers, st.FailedCount),
+		Blocks: []Block{section(title + "\n" + totals)},
+	}
+	if s.Compared {
+		msg.Text += fmt.Sprintf(" (%d new), %d hardware changes", len(s.NewFailures), s.changeCount())
+	}
+
+	if len(s.NewFailures) > 0 {
+		heading := "*New failures*"
+		if !s.Compared {
+			heading = "*Failures*"
+		}
+		var lines []string
+		for _, f := range s.NewFailures {
+			lines = append(lines, failureLine(f))
+		}
+		msg.Blocks = append(msg.Blocks, section(heading+"\n"+bullets(lines, maxItems)))
+	}
+
+	if len(s.Drift) > 0 {
+		var lines []string
+		for _, d := range s.Drift {
+			for _, ch := range d.Changes {
+				lines = append(lines, fmt.Sprintf("%s: %s", escape(d.ServerRef.String()), escape(ch.String())))
+			}
+		}
+		msg.Blocks = append(msg.Blocks, section("*Top drift*\n"+bullets(lines, maxItems)))
+	}
+
+	if u := n.reportURL(st.RunID); u != "" {
+		msg.Blocks = append(msg.Blocks, Block{Type: "context", Elements: []Text{{Type: "mrkdwn", Text: fmt.Sprintf("<%s|Full report>", u)}}})
+	}
+	return msg
+}
+
+// failureLine describes a failed host, e.g. "10.0.0.5 (ABC1234) `E_TIMEOUT`:
+// context deadline exceeded".
+func failureLine(s models.ServerInfo) string {
+	ref := models.ServerRef{Host: s.Key(), ServiceTag: s.ServiceTag, Model: s.Model}
+	line := escape(ref.String())
+	reason := s.StaleError
+	if s.Error != nil {
+		line += fmt.Sprintf(" `%s`", errors.CodeOf(s.Error))
+		reason = s.Error.Error()
+	} else {
+		line += " (stale)"
+	}
+	if r := []rune(reason); len(r) > maxReasonLength {
+		reason = string(r[:maxReasonLength-1]) + "…"
+	}
+	if reason != "" {
+		line += ": " + escape(reason)
+	}
+	return line
+}
+
+// bullets renders at most limit lines as a list, noting how many were left out.
+func bullets(lines []string, limit int) string {
+	var b strings.Builder
+	for i, line := range lines {
+		if i == limit {
+			fmt.Fprintf(&b, "… and %d more\n", len(lines)-i)
+			break
+		}
+		b.WriteString("• " + line + "\n")
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// escape escapes the characters Slack reserves for links and mentions.
+func escape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+// reportURL returns the report link with {run_id} expanded, or "".
+func (n *Notifier) reportURL(runID string) string {
+	return strings.ReplaceAll(n.cfg.ReportURL, "{run_id}", runID)
+}
+
+// Notify posts the summary of a run compared with the previous run (nil if
+// unknown). With only_changes, nothing is posted unless hosts newly failed
+// or hardware changed. It reports whether a message was posted.
+func (n *Notifier) Notify(ctx context.Context, prev, results []models.ServerInfo, stats models.CollectionStats) (bool, error) {
+	s := Summarize(prev, results, stats)
+	if n.cfg.OnlyChanges && !s.Changed() {
+		return false, nil
+	}
+	return true, n.Post(ctx, n.Message(s))
+}
+
+// Post sends a message to the webhook.
+func (n *Notifier) Post(ctx context.Context, msg Message) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode Slack message: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, n.cfg.Timeout())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.cfg.GetWebhookURL(), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("invalid Slack webhook URL")
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		// The webhook URL is a secret; leave it out of the error
+		if urlErr, ok := err.(*url.Error); ok {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("failed to post to Slack: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		text, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("failed to post to Slack: HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(text)))
+	}
+	return nil
+}
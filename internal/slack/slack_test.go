@@ -0,0 +1,149 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/config"
+	"github.com/braunma/idrac-netbox-importer/pkg/errors"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+func server(host, tag string, memGiB float64) models.ServerInfo {
+	return models.ServerInfo{Host: host, ServiceTag: tag, Model: "PowerEdge R750", TotalMemoryGiB: memGiB}
+}
+
+func failedServer(host string) models.ServerInfo {
+	return models.ServerInfo{Host: host, Error: errors.WithCode(errors.CodeTimeout, "context deadline exceeded")}
+}
+
+func TestSummarize(t *testing.T) {
+	prev := []models.ServerInfo{
+		server("10.0.0.1", "TAG1", 256),
+		server("10.0.0.2", "TAG2", 256),
+		failedServer("10.0.0.3"),
+	}
+	results := []models.ServerInfo{
+		server("10.0.0.1", "TAG1", 512),
+		failedServer("10.0.0.2"),
+		failedServer("10.0.0.3"),
+		failedServer("10.0.0.4"),
+	}
+
+	s := Summarize(prev, results, models.CollectionStats{})
+	require.True(t, s.Compared)
+	require.Len(t, s.NewFailures, 2, "10.0.0.3 failed before")
+	assert.Equal(t, "10.0.0.2", s.NewFailures[0].Host)
+	assert.Equal(t, "10.0.0.4", s.NewFailures[1].Host)
+	require.Len(t, s.Drift, 1)
+	assert.Equal(t, "TAG1", s.Drift[0].ServiceTag)
+	assert.True(t, s.Changed())
+
+	s = Summarize(nil, results, models.CollectionStats{})
+	assert.False(t, s.Compared)
+	assert.Len(t, s.NewFailures, 3, "without a previous run all failures are new")
+	assert.Empty(t, s.Drift)
+
+	assert.False(t, Summarize(results, results, models.CollectionStats{}).Changed())
+}
+
+func TestNotifier_Message(t *testing.T) {
+	n := New(config.SlackConfig{
+		Channel:   "#inventory",
+		ReportURL: "https://ci.example.com/runs/{run_id}/report.html",
+		MaxItems:  2,
+	}, false)
+	stale := server("10.0.0.6", "TAG6", 256)
+	stale.Stale = true
+	stale.StaleError = "dial tcp 10.0.0.6:443: connect: connection refused"
+
+	msg := n.Message(Summary{
+		Stats: models.CollectionStats{
+			RunID: "r-42", TotalServers: 10, SuccessfulCount: 7, FailedCount: 3, StaleCount: 1,
+			TotalDuration: 93 * time.Second,
+		},
+		Compared:    true,
+		NewFailures: []models.ServerInfo{failedServer("10.0.0.2"), stale},
+		Drift: []models.HostDiff{{
+			ServerRef: models.ServerRef{Host: "10.0.0.1", ServiceTag: "TAG1"},
+			Changes: []models.HostChange{
+				{Field: "memory", Old: "256 GiB", New: "512 GiB"},
+				{Field: "drive added", New: "Disk.Bay.2 <SSD>"},
+				{Field: "bios", Old: "1.0", New: "1.1"},
+			},
+		}},
+	})
+
+	assert.Equal(t, "#inventory", msg.Channel)
+	assert.Equal(t, "iDRAC inventory: 7/10 servers OK, 3 failed (2 new), 3 hardware changes", msg.Text)
+	require.Len(t, msg.Blocks, 4)
+	assert.Equal(t, "*iDRAC inventory* · run `r-42`\n"+
+		"*10* servers: *7* OK, *3* failed (2 new), 1 stale · 70.0% success in 1m33s\n"+
+		"*3* hardware changes on 1 servers", msg.Blocks[0].Text.Text)
+	assert.Equal(t, "*New failures*\n"+
+		"• 10.0.0.2 `E_TIMEOUT`: context deadline exceeded\n"+
+		"• 10.0.0.6 (TAG6, PowerEdge R750) (stale): dial tcp 10.0.0.6:443: connect: connection refused",
+		msg.Blocks[1].Text.Text)
+	assert.Equal(t, "*Top drift*\n"+
+		"• 10.0.0.1 (TAG1): memory 256 GiB -&gt; 512 GiB\n"+
+		"• 10.0.0.1 (TAG1): drive added Disk.Bay.2 &lt;SSD&gt;\n"+
+		"… and 1 more", msg.Blocks[2].Text.Text)
+	assert.Equal(t, "context", msg.Blocks[3].Type)
+	assert.Equal(t, "<https://ci.example.com/runs/r-42/report.html|Full report>", msg.Blocks[3].Elements[0].Text)
+
+	msg = n.Message(Summary{Stats: models.CollectionStats{TotalServers: 1, SuccessfulCount: 1}})
+	require.Len(t, msg.Blocks, 2)
+	assert.Contains(t, msg.Blocks[0].Text.Text, "No previous run")
+}
+
+func TestNotifier_Notify(t *testing.T) {
+	var posted []Message
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		var msg Message
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&msg))
+		posted = append(posted, msg)
+		fmt.Fprint(w, "ok")
+	}))
+	defer srv.Close()
+
+	results := []models.ServerInfo{server("10.0.0.1", "TAG1", 256)}
+	n := New(config.SlackConfig{WebhookURL: srv.URL, OnlyChanges: true}, false)
+
+	sent, err := n.Notify(context.Background(), results, results, models.CollectionStats{TotalServers: 1, SuccessfulCount: 1})
+	require.NoError(t, err)
+	assert.False(t, sent, "nothing changed")
+	assert.Empty(t, posted)
+
+	sent, err = n.Notify(context.Background(), results, append(results, failedServer("10.0.0.2")), models.CollectionStats{TotalServers: 2, SuccessfulCount: 1, FailedCount: 1})
+	require.NoError(t, err)
+	assert.True(t, sent)
+	require.Len(t, posted, 1)
+	assert.Contains(t, posted[0].Blocks[1].Text.Text, "10.0.0.2")
+}
+
+func TestNotifier_PostErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, "no_service")
+	}))
+	defer srv.Close()
+
+	secret := srv.URL + "/services/T000/B000/secret"
+	err := New(config.SlackConfig{WebhookURL: secret}, false).Post(context.Background(), Message{Text: "x"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "HTTP 404: no_service")
+	assert.NotContains(t, err.Error(), "secret", "the webhook URL is left out")
+
+	err = New(config.SlackConfig{WebhookURL: secret}, true).Post(context.Background(), Message{Text: "x"})
+	assert.ErrorIs(t, err, errors.ErrReadOnly)
+}
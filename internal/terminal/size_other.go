@@ -0,0 +1,10 @@
+//go:build !(linux || darwin || freebsd)
+
+package terminal
+
+import "os"
+
+// size is unknown on this platform; only COLUMNS sets the width.
+func size(*os.File) int {
+	return 0
+}
@@ -0,0 +1,19 @@
+//go:build linux || darwin || freebsd
+
+package terminal
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// size asks the terminal for its window size. It fails for files and pipes.
+func size(f *os.File) int {
+	var ws struct{ Row, Col, XPixel, YPixel uint16 }
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), uintptr(syscall.TIOCGWINSZ), uintptr(unsafe.Pointer(&ws)))
+	if errno != 0 {
+		return 0
+	}
+	return int(ws.Col)
+}
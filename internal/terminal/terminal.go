@@ -0,0 +1,34 @@
+// Package terminal detects the width of the terminal the output is written
+// to, so the console and table output can adapt their layout to it, and
+// whether it is a terminal at all, so colors are only written to one.
+package terminal
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Width returns the number of columns of the terminal f is attached to. The
+// COLUMNS environment variable takes precedence over the size reported by
+// the terminal. It returns 0 if f is not a terminal (e.g. output redirected
+// to a file or pipe) or its size is unknown on this platform.
+func Width(f *os.File) int {
+	if n, err := strconv.Atoi(strings.TrimSpace(os.Getenv("COLUMNS"))); err == nil && n > 0 {
+		return n
+	}
+	if f == nil {
+		return 0
+	}
+	return size(f)
+}
+
+// IsTerminal reports whether f is a terminal (a character device), so
+// color escape sequences are not written to files and pipes.
+func IsTerminal(f *os.File) bool {
+	if f == nil {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,35 @@
+package terminal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWidth(t *testing.T) {
+	file, err := os.Create(filepath.Join(t.TempDir(), "out.txt"))
+	require.NoError(t, err)
+	defer file.Close()
+
+	t.Setenv("COLUMNS", "")
+	assert.Equal(t, 0, Width(file), "a file is not a terminal")
+	assert.Equal(t, 0, Width(nil))
+
+	t.Setenv("COLUMNS", "132")
+	assert.Equal(t, 132, Width(file), "COLUMNS takes precedence")
+
+	t.Setenv("COLUMNS", "wide")
+	assert.Equal(t, 0, Width(file))
+}
+
+func TestIsTerminal(t *testing.T) {
+	file, err := os.Create(filepath.Join(t.TempDir(), "out.txt"))
+	require.NoError(t, err)
+	defer file.Close()
+
+	assert.False(t, IsTerminal(file))
+	assert.False(t, IsTerminal(nil))
+}
@@ -0,0 +1,411 @@
+// Package warehouse exports scan results into a SQL database (Postgres or
+// MySQL) for analytics over the fleet. Each run is a row in the scans table;
+// the servers table has a row per server and scan, and the cpus, dimms and
+// drives tables a row per component, all keyed by service tag and scan ID.
+//
+// The package does not register database drivers; the binary imports them.
+package warehouse
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/config"
+	"github.com/braunma/idrac-netbox-importer/pkg/errors"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+// Column kinds, mapped to a SQL type per dialect.
+const (
+	kindKey   = "key"   // short string that is part of the primary key
+	kindText  = "text"  // string
+	kindInt   = "int"   // integer
+	kindFloat = "float" // floating-point number
+	kindTime  = "time"  // timestamp
+)
+
+type column struct {
+	name string
+	kind string
+}
+
+// table is the definition of an exported table. Key lists the columns of
+// the primary key.
+type table struct {
+	name    string
+	columns []column
+	key     []string
+}
+
+var (
+	scansTable = table{
+		name: "scans",
+		columns: []column{
+			{"scan_id", kindKey},
+			{"shard", kindText},
+			{"exported_at", kindTime},
+			{"total_servers", kindInt},
+			{"successful_count", kindInt},
+			{"failed_count", kindInt},
+			{"partial_count", kindInt},
+			{"stale_count", kindInt},
+			{"duration_seconds", kindFloat},
+		},
+		key: []string{"scan_id"},
+	}
+
+	serversTable = table{
+		name: "servers",
+		columns: []column{
+			{"service_tag", kindKey},
+			{"scan_id", kindKey},
+			{"host", kindText},
+			{"name", kindText},
+			{"system_id", kindText},
+			{"model", kindText},
+			{"manufacturer", kindText},
+			{"serial_number", kindText},
+			{"bios_version", kindText},
+			{"hostname", kindText},
+			{"power_state", kindText},
+			{"license_level", kindText},
+			{"cpu_count", kindInt},
+			{"cpu_model", kindText},
+			{"total_memory_gib", kindFloat},
+			{"memory_slots_total", kindInt},
+			{"memory_slots_used", kindInt},
+			{"drive_count", kindInt},
+			{"total_storage_tb", kindFloat},
+			{"gpu_count", kindInt},
+			{"power_consumed_watts", kindInt},
+			{"failed_components", kindText},
+			{"collected_at", kindTime},
+		},
+		key: []string{"service_tag", "scan_id"},
+	}
+
+	cpusTable = table{
+		name: "cpus",
+		columns: []column{
+			{"service_tag", kindKey},
+			{"scan_id", kindKey},
+			{"socket", kindKey},
+			{"model", kindText},
+			{"manufacturer", kindText},
+			{"cores", kindInt},
+			{"threads", kindInt},
+			{"max_speed_mhz", kindInt},
+			{"health", kindText},
+		},
+		key: []string{"service_tag", "scan_id", "socket"},
+	}
+
+	dimmsTable = table{
+		name: "dimms",
+		columns: []column{
+			{"service_tag", kindKey},
+			{"scan_id", kindKey},
+			{"slot", kindKey},
+			{"capacity_mib", kindInt},
+			{"type", kindText},
+			{"base_module_type", kindText},
+			{"speed_mhz", kindInt},
+			{"manufacturer", kindText},
+			{"part_number", kindText},
+			{"serial_number", kindText},
+			{"state", kindText},
+			{"health", kindText},
+		},
+		key: []string{"service_tag", "scan_id", "slot"},
+	}
+
+	drivesTable = table{
+		name: "drives",
+		columns: []column{
+			{"service_tag", kindKey},
+			{"scan_id", kindKey},
+			{"name", kindKey},
+			{"model", kindText},
+			{"manufacturer", kindText},
+			{"serial_number", kindText},
+			{"capacity_bytes", kindInt},
+			{"media_type", kindText},
+			{"protocol", kindText},
+			{"life_left_pct", kindFloat},
+			{"enclosure", kindText},
+			{"bay", kindInt},
+			{"health", kindText},
+		},
+		key: []string{"service_tag", "scan_id", "name"},
+	}
+
+	tables = []table{scansTable, serversTable, cpusTable, dimmsTable, drivesTable}
+)
+
+// dialect is the SQL flavor of a driver.
+type dialect string
+
+const (
+	postgres dialect = config.WarehousePostgres
+	mysql    dialect = config.WarehouseMySQL
+)
+
+func (d dialect) quote(name string) string {
+	if d == mysql {
+		return "`" + name + "`"
+	}
+	return `"` + name + `"`
+}
+
+// placeholder returns the bind parameter n (1-based).
+func (d dialect) placeholder(n int) string {
+	if d == mysql {
+		return "?"
+	}
+	return fmt.Sprintf("$%d", n)
+}
+
+func (d dialect) columnType(kind string) string {
+	switch kind {
+	case kindKey:
+		return "VARCHAR(255)"
+	case kindText:
+		return "TEXT"
+	case kindInt:
+		return "BIGINT"
+	case kindFloat:
+		return "DOUBLE PRECISION"
+	case kindTime:
+		if d == mysql {
+			return "DATETIME(6)"
+		}
+		return "TIMESTAMPTZ"
+	}
+	panic("warehouse: unknown column kind " + kind)
+}
+
+// Exporter writes results into the tables of a database.
+type Exporter struct {
+	DB      *sql.DB
+	dialect dialect
+
+	// Schema and Prefix qualify the table names (see config.WarehouseConfig).
+	Schema string
+	Prefix string
+
+	// ReadOnly refuses the export.
+	ReadOnly bool
+
+	created bool
+}
+
+// New returns an exporter into db, which was opened with driver (postgres
+// or mysql).
+func New(db *sql.DB, driver string) (*Exporter, error) {
+	switch d := dialect(driver); d {
+	case postgres, mysql:
+		return &Exporter{DB: db, dialect: d}, nil
+	}
+	return nil, fmt.Errorf("unsupported warehouse driver %q", driver)
+}
+
+// Open connects to the database of cfg.
+func Open(cfg config.WarehouseConfig, readOnly bool) (*Exporter, error) {
+	db, err := sql.Open(cfg.Driver, cfg.GetDSN())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open warehouse: %w", err)
+	}
+	e, err := New(db, cfg.Driver)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	e.Schema = cfg.Schema
+	e.Prefix = cfg.TablePrefix
+	e.ReadOnly = readOnly
+	return e, nil
+}
+
+// Close closes the database.
+func (e *Exporter) Close() error {
+	return e.DB.Close()
+}
+
+// tableName returns the qualified and quoted name of t.
+func (e *Exporter) tableName(t table) string {
+	name := e.dialect.quote(e.Prefix + t.name)
+	if e.Schema != "" {
+		name = e.dialect.quote(e.Schema) + "." + name
+	}
+	return name
+}
+
+// createTable returns the CREATE TABLE statement of t.
+func (e *Exporter) createTable(t table) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "CREATE TABLE IF NOT EXISTS %s (", e.tableName(t))
+	for _, c := range t.columns {
+		fmt.Fprintf(&b, "%s %s, ", e.dialect.quote(c.name), e.dialect.columnType(c.kind))
+	}
+	fmt.Fprintf(&b, "PRIMARY KEY (%s))", e.quoteAll(t.key))
+	return b.String()
+}
+
+// upsert returns the statement that inserts a row of t, or updates the
+// non-key columns of the row with the same key.
+func (e *Exporter) upsert(t table) string {
+	names := make([]string, len(t.columns))
+	values := make([]string, len(t.columns))
+	var updates []string
+	for i, c := range t.columns {
+		names[i] = c.name
+		values[i] = e.dialect.placeholder(i + 1)
+		if contains(t.key, c.name) {
+			continue
+		}
+		col := e.dialect.quote(c.name)
+		if e.dialect == mysql {
+			updates = append(updates, col+" = VALUES("+col+")")
+		} else {
+			updates = append(updates, col+" = EXCLUDED."+col)
+		}
+	}
+
+	stmt := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
+		e.tableName(t), e.quoteAll(names), strings.Join(values, ", "))
+	if e.dialect == mysql {
+		return stmt + " ON DUPLICATE KEY UPDATE " + strings.Join(updates, ", ")
+	}
+	return stmt + " ON CONFLICT (" + e.quoteAll(t.key) + ") DO UPDATE SET " + strings.Join(updates, ", ")
+}
+
+func (e *Exporter) quoteAll(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = e.dialect.quote(name)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// CreateTables creates the schema (Postgres only) and the tables that do
+// not exist yet.
+func (e *Exporter) CreateTables(ctx context.Context) error {
+	if e.Schema != "" && e.dialect == postgres {
+		if _, err := e.DB.ExecContext(ctx, "CREATE SCHEMA IF NOT EXISTS "+e.dialect.quote(e.Schema)); err != nil {
+			return fmt.Errorf("failed to create schema %s: %w", e.Schema, err)
+		}
+	}
+	for _, t := range tables {
+		if _, err := e.DB.ExecContext(ctx, e.createTable(t)); err != nil {
+			return fmt.Errorf("failed to create table %s: %w", e.tableName(t), err)
+		}
+	}
+	e.created = true
+	return nil
+}
+
+// Export upserts the results of the scan scanID in a single transaction
+// and returns the number of servers written. Failed and stale results, and
+// results without a service tag, are not exported; they are only counted
+// in the scans table.
+func (e *Exporter) Export(ctx context.Context, scanID string, results []models.ServerInfo, stats models.CollectionStats) (int, error) {
+	if e.ReadOnly {
+		return 0, fmt.Errorf("warehouse export: %w", errors.ErrReadOnly)
+	}
+	if scanID == "" {
+		return 0, fmt.Errorf("warehouse export: missing scan ID")
+	}
+	if !e.created {
+		if err := e.CreateTables(ctx); err != nil {
+			return 0, err
+		}
+	}
+
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback() // no-op after Commit
+
+	stmts := make(map[string]*sql.Stmt, len(tables))
+	for _, t := range tables {
+		stmt, err := tx.PrepareContext(ctx, e.upsert(t))
+		if err != nil {
+			return 0, fmt.Errorf("failed to prepare upsert into %s: %w", e.tableName(t), err)
+		}
+		defer stmt.Close()
+		stmts[t.name] = stmt
+	}
+	exec := func(t table, args ...interface{}) error {
+		if _, err := stmts[t.name].ExecContext(ctx, args...); err != nil {
+			return fmt.Errorf("failed to upsert into %s: %w", e.tableName(t), err)
+		}
+		return nil
+	}
+
+	if err := exec(scansTable, scanID, stats.Shard, time.Now().UTC(),
+		stats.TotalServers, stats.SuccessfulCount, stats.FailedCount,
+		stats.PartialCount, stats.StaleCount, stats.TotalDuration.Seconds()); err != nil {
+		return 0, err
+	}
+
+	exported := 0
+	for i := range results {
+		r := &results[i]
+		if !r.IsValid() || r.Stale || r.ServiceTag == "" {
+			continue
+		}
+		tag := r.ServiceTag
+
+		if err := exec(serversTable, tag, scanID, r.Host, r.Name, r.SystemID,
+			r.Model, r.Manufacturer, r.SerialNumber, r.BiosVersion, r.HostName,
+			r.PowerState, r.LicenseLevel, r.CPUCount, r.CPUModel,
+			r.TotalMemoryGiB, r.MemorySlotsTotal, r.MemorySlotsUsed,
+			r.DriveCount, r.TotalStorageTB, r.GPUCount, r.PowerConsumedWatts,
+			strings.Join(r.FailedComponents(), ","), r.CollectedAt.UTC()); err != nil {
+			return 0, err
+		}
+		for _, c := range r.CPUs {
+			if err := exec(cpusTable, tag, scanID, c.Socket, c.Model, c.Manufacturer,
+				c.Cores, c.Threads, c.MaxSpeedMHz, c.Health); err != nil {
+				return 0, err
+			}
+		}
+		for _, m := range r.Memory {
+			if err := exec(dimmsTable, tag, scanID, m.Slot, m.CapacityMiB, m.Type,
+				m.BaseModuleType, m.SpeedMHz, m.Manufacturer, m.PartNumber,
+				m.SerialNumber, m.State, m.Health); err != nil {
+				return 0, err
+			}
+		}
+		for _, d := range r.Drives {
+			var bay interface{}
+			if d.Bay != nil {
+				bay = *d.Bay
+			}
+			if err := exec(drivesTable, tag, scanID, d.Name, d.Model, d.Manufacturer,
+				d.SerialNumber, d.Bytes(), d.MediaType, d.Protocol, d.LifeLeftPct,
+				d.Enclosure, bay, d.Health); err != nil {
+				return 0, err
+			}
+		}
+		exported++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit warehouse export: %w", err)
+	}
+	return exported, nil
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,230 @@
+package warehouse
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/errors"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+// recorder is a database/sql driver that records the executed statements.
+type recorder struct {
+	mu    sync.Mutex
+	execs []execution
+	fail  string // statements containing this fail
+}
+
+type execution struct {
+	query string
+	args  []driver.Value
+}
+
+func (r *recorder) Open(string) (driver.Conn, error) { return &conn{r}, nil }
+
+// rows returns the arguments of the statements that start with prefix.
+func (r *recorder) rows(prefix string) [][]driver.Value {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var rows [][]driver.Value
+	for _, e := range r.execs {
+		if strings.HasPrefix(e.query, prefix) {
+			rows = append(rows, e.args)
+		}
+	}
+	return rows
+}
+
+type conn struct{ r *recorder }
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) { return &stmt{c.r, query}, nil }
+func (c *conn) Close() error                              { return nil }
+func (c *conn) Begin() (driver.Tx, error)                 { return tx{}, nil }
+
+type tx struct{}
+
+func (tx) Commit() error   { return nil }
+func (tx) Rollback() error { return nil }
+
+type stmt struct {
+	r     *recorder
+	query string
+}
+
+func (s *stmt) Close() error  { return nil }
+func (s *stmt) NumInput() int { return -1 }
+
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	if s.r.fail != "" && strings.Contains(s.query, s.r.fail) {
+		return nil, assert.AnError
+	}
+	s.r.mu.Lock()
+	defer s.r.mu.Unlock()
+	s.r.execs = append(s.r.execs, execution{s.query, args})
+	return driver.RowsAffected(1), nil
+}
+
+func (s *stmt) Query([]driver.Value) (driver.Rows, error) { return nil, driver.ErrSkip }
+
+var driverSeq atomic.Int64
+
+// newRecorder returns an exporter for driver whose database records the
+// executed statements.
+func newRecorder(t *testing.T, driverName string) (*Exporter, *recorder) {
+	t.Helper()
+	rec := &recorder{}
+	name := fmt.Sprintf("warehousetest-%d", driverSeq.Add(1))
+	sql.Register(name, rec)
+	db, err := sql.Open(name, "")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	e, err := New(db, driverName)
+	require.NoError(t, err)
+	return e, rec
+}
+
+func TestNew_UnsupportedDriver(t *testing.T) {
+	_, err := New(nil, "sqlite")
+	assert.Error(t, err)
+}
+
+func TestCreateTable(t *testing.T) {
+	e := &Exporter{dialect: postgres, Schema: "inventory", Prefix: "idrac_"}
+	assert.Equal(t,
+		`CREATE TABLE IF NOT EXISTS "inventory"."idrac_cpus" (`+
+			`"service_tag" VARCHAR(255), "scan_id" VARCHAR(255), "socket" VARCHAR(255), `+
+			`"model" TEXT, "manufacturer" TEXT, "cores" BIGINT, "threads" BIGINT, `+
+			`"max_speed_mhz" BIGINT, "health" TEXT, `+
+			`PRIMARY KEY ("service_tag", "scan_id", "socket"))`,
+		e.createTable(cpusTable))
+
+	e = &Exporter{dialect: mysql}
+	stmt := e.createTable(scansTable)
+	assert.True(t, strings.HasPrefix(stmt, "CREATE TABLE IF NOT EXISTS `scans` (`scan_id` VARCHAR(255), "), stmt)
+	assert.Contains(t, stmt, "`exported_at` DATETIME(6)")
+}
+
+func TestUpsert(t *testing.T) {
+	e := &Exporter{dialect: postgres}
+	assert.Equal(t,
+		`INSERT INTO "scans" ("scan_id", "shard", "exported_at", "total_servers", "successful_count", `+
+			`"failed_count", "partial_count", "stale_count", "duration_seconds") `+
+			`VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) `+
+			`ON CONFLICT ("scan_id") DO UPDATE SET "shard" = EXCLUDED."shard", `+
+			`"exported_at" = EXCLUDED."exported_at", "total_servers" = EXCLUDED."total_servers", `+
+			`"successful_count" = EXCLUDED."successful_count", "failed_count" = EXCLUDED."failed_count", `+
+			`"partial_count" = EXCLUDED."partial_count", "stale_count" = EXCLUDED."stale_count", `+
+			`"duration_seconds" = EXCLUDED."duration_seconds"`,
+		e.upsert(scansTable))
+
+	e = &Exporter{dialect: mysql, Prefix: "idrac_"}
+	stmt := e.upsert(cpusTable)
+	assert.True(t, strings.HasPrefix(stmt, "INSERT INTO `idrac_cpus` (`service_tag`, `scan_id`, `socket`, "), stmt)
+	assert.Contains(t, stmt, "VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?) ON DUPLICATE KEY UPDATE `model` = VALUES(`model`), ")
+	assert.NotContains(t, stmt, "`socket` = VALUES")
+}
+
+func testResults() []models.ServerInfo {
+	bay := 3
+	return []models.ServerInfo{
+		{
+			Host:        "10.0.0.1",
+			ServiceTag:  "ABC1234",
+			Model:       "PowerEdge R650",
+			CollectedAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+			CPUs: []models.CPUInfo{
+				{Socket: "CPU.Socket.1", Model: "Xeon Gold 6338", Cores: 32},
+				{Socket: "CPU.Socket.2", Model: "Xeon Gold 6338", Cores: 32},
+			},
+			Memory: []models.MemoryInfo{{Slot: "DIMM.Socket.A1", CapacityMiB: 32768, State: "Enabled"}},
+			Drives: []models.DriveInfo{
+				{Name: "Disk 0", CapacityBytes: 960197124096, Bay: &bay},
+				{Name: "BOSS Disk", CapacityBytes: 480103981056},
+			},
+		},
+		{Host: "10.0.0.2", Error: assert.AnError},
+		{Host: "10.0.0.3", ServiceTag: "STALE01", Stale: true},
+		{Host: "10.0.0.4"},
+	}
+}
+
+func TestExport(t *testing.T) {
+	e, rec := newRecorder(t, "postgres")
+	e.Schema = "inventory"
+
+	stats := models.CollectionStats{TotalServers: 4, SuccessfulCount: 2, FailedCount: 2, TotalDuration: 90 * time.Second}
+	n, err := e.Export(context.Background(), "run-1", testResults(), stats)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n, "failed, stale and untagged results are skipped")
+
+	assert.Len(t, rec.rows(`CREATE SCHEMA IF NOT EXISTS "inventory"`), 1)
+	assert.Len(t, rec.rows("CREATE TABLE"), len(tables))
+
+	scans := rec.rows(`INSERT INTO "inventory"."scans"`)
+	require.Len(t, scans, 1)
+	assert.Equal(t, "run-1", scans[0][0])
+	assert.Equal(t, int64(4), scans[0][3])
+	assert.Equal(t, 90.0, scans[0][8])
+
+	servers := rec.rows(`INSERT INTO "inventory"."servers"`)
+	require.Len(t, servers, 1)
+	assert.Equal(t, "ABC1234", servers[0][0])
+	assert.Equal(t, "run-1", servers[0][1])
+	assert.Equal(t, "PowerEdge R650", servers[0][5])
+
+	cpus := rec.rows(`INSERT INTO "inventory"."cpus"`)
+	require.Len(t, cpus, 2)
+	assert.Equal(t, "CPU.Socket.2", cpus[1][2])
+
+	assert.Len(t, rec.rows(`INSERT INTO "inventory"."dimms"`), 1)
+
+	drives := rec.rows(`INSERT INTO "inventory"."drives"`)
+	require.Len(t, drives, 2)
+	assert.Equal(t, int64(960197124096), drives[0][6])
+	assert.Equal(t, int64(3), drives[0][11])
+	assert.Nil(t, drives[1][11], "drives outside a bay have no bay")
+
+	// Tables are only created once per exporter.
+	_, err = e.Export(context.Background(), "run-2", testResults(), stats)
+	require.NoError(t, err)
+	assert.Len(t, rec.rows("CREATE TABLE"), len(tables))
+}
+
+func TestExport_MySQL(t *testing.T) {
+	e, rec := newRecorder(t, "mysql")
+	e.Schema = "inventory"
+
+	_, err := e.Export(context.Background(), "run-1", testResults(), models.CollectionStats{})
+	require.NoError(t, err)
+	assert.Empty(t, rec.rows("CREATE SCHEMA"), "MySQL databases are not created")
+	assert.Len(t, rec.rows("INSERT INTO `inventory`.`servers`"), 1)
+}
+
+func TestExport_Errors(t *testing.T) {
+	e, rec := newRecorder(t, "postgres")
+
+	_, err := e.Export(context.Background(), "", nil, models.CollectionStats{})
+	assert.Error(t, err)
+
+	e.ReadOnly = true
+	_, err = e.Export(context.Background(), "run-1", testResults(), models.CollectionStats{})
+	assert.ErrorIs(t, err, errors.ErrReadOnly)
+	assert.Empty(t, rec.rows(""))
+
+	e.ReadOnly = false
+	rec.fail = `INSERT INTO "drives"`
+	_, err = e.Export(context.Background(), "run-1", testResults(), models.CollectionStats{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "drives")
+}
@@ -0,0 +1,168 @@
+// Package warranty looks up the ship dates of Dell servers by service tag
+// with the Dell warranty API (TechDirect asset entitlements), as the
+// manufacture date of servers whose iDRAC does not report one.
+package warranty
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/config"
+	"github.com/braunma/idrac-netbox-importer/pkg/defaults"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+// maxResponseSize limits the response bodies that are read.
+const maxResponseSize = 8 << 20
+
+// Client queries the asset entitlements endpoint. A Client fetches one
+// access token with the client credentials grant and uses it for all
+// lookups of a run.
+type Client struct {
+	cfg        config.WarrantyConfig
+	httpClient *http.Client
+	token      string
+}
+
+// New returns a client for the API configured in cfg.
+func New(cfg config.WarrantyConfig) *Client {
+	return &Client{cfg: cfg, httpClient: &http.Client{Timeout: cfg.Timeout()}}
+}
+
+// asset is an entry of the asset entitlements response.
+type asset struct {
+	ServiceTag string `json:"serviceTag"`
+	ShipDate   string `json:"shipDate"`
+	Invalid    bool   `json:"invalid"`
+}
+
+// ShipDates returns the ship dates of the service tags, in batches of
+// defaults.DefaultWarrantyBatchSize. Tags that are unknown to Dell or have
+// no ship date are missing from the result.
+func (c *Client) ShipDates(ctx context.Context, tags []string) (map[string]time.Time, error) {
+	dates := make(map[string]time.Time, len(tags))
+	for start := 0; start < len(tags); start += defaults.DefaultWarrantyBatchSize {
+		batch := tags[start:min(start+defaults.DefaultWarrantyBatchSize, len(tags))]
+
+		var assets []asset
+		if err := c.get(ctx, c.cfg.GetURL()+"?"+url.Values{"servicetags": {strings.Join(batch, ",")}}.Encode(), &assets); err != nil {
+			return dates, err
+		}
+		for _, a := range assets {
+			if a.Invalid || a.ShipDate == "" {
+				continue
+			}
+			if shipped, err := time.Parse(time.RFC3339, a.ShipDate); err == nil {
+				dates[strings.ToUpper(a.ServiceTag)] = shipped.UTC()
+			}
+		}
+	}
+	return dates, nil
+}
+
+// Apply sets the manufacture date of the successfully scanned Dell servers
+// whose iDRAC reported none to their ship date, and returns the number of
+// servers updated. Servers updated before an error keep their date.
+func (c *Client) Apply(ctx context.Context, results []models.ServerInfo) (int, error) {
+	var tags []string
+	seen := make(map[string]bool)
+	for _, srv := range results {
+		tag := strings.ToUpper(srv.ServiceTag)
+		if needsDate(srv) && !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	if len(tags) == 0 {
+		return 0, nil
+	}
+
+	dates, err := c.ShipDates(ctx, tags)
+	updated := 0
+	for i := range results {
+		if shipped, ok := dates[strings.ToUpper(results[i].ServiceTag)]; ok && needsDate(results[i]) {
+			results[i].ManufactureDate = &shipped
+			results[i].ManufactureDateSource = models.ManufactureDateWarranty
+			updated++
+		}
+	}
+	return updated, err
+}
+
+// needsDate reports whether the ship date of srv is to be looked up.
+func needsDate(srv models.ServerInfo) bool {
+	return srv.Error == nil && srv.ManufactureDate == nil && srv.ServiceTag != "" && srv.PlatformInfo() != nil
+}
+
+// get sends an authorized GET and decodes the JSON response into target.
+func (c *Client) get(ctx context.Context, u string, target interface{}) error {
+	if c.token == "" {
+		token, err := c.fetchToken(ctx)
+		if err != nil {
+			return err
+		}
+		c.token = token
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("warranty API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return fmt.Errorf("warranty API: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("warranty API: unexpected status %s", resp.Status)
+	}
+	if err := json.Unmarshal(body, target); err != nil {
+		return fmt.Errorf("warranty API: invalid response: %w", err)
+	}
+	return nil
+}
+
+// fetchToken requests an access token with the client credentials grant.
+func (c *Client) fetchToken(ctx context.Context) (string, error) {
+	form := url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {c.cfg.GetClientID()},
+		"client_secret": {c.cfg.GetClientSecret()},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.cfg.GetTokenURL(), strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("warranty API token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("warranty API token: unexpected status %s", resp.Status)
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(&token); err != nil || token.AccessToken == "" {
+		return "", fmt.Errorf("warranty API token: no access token in the response")
+	}
+	return token.AccessToken, nil
+}
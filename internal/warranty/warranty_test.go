@@ -0,0 +1,97 @@
+package warranty
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/config"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+func newTestAPI(t *testing.T, queries *[]string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			require.NoError(t, r.ParseForm())
+			if r.PostForm.Get("client_secret") != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "tok", "expires_in": 3600})
+		case "/asset-entitlements":
+			if r.Header.Get("Authorization") != "Bearer tok" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			*queries = append(*queries, r.URL.Query().Get("servicetags"))
+			var assets []map[string]interface{}
+			for _, tag := range strings.Split(r.URL.Query().Get("servicetags"), ",") {
+				switch tag {
+				case "ABC1234":
+					assets = append(assets, map[string]interface{}{"serviceTag": tag, "shipDate": "2019-05-14T05:00:00Z"})
+				case "UNKNOWN":
+					assets = append(assets, map[string]interface{}{"serviceTag": tag, "invalid": true})
+				}
+			}
+			json.NewEncoder(w).Encode(assets)
+		}
+	}))
+}
+
+func TestApply(t *testing.T) {
+	var queries []string
+	server := newTestAPI(t, &queries)
+	defer server.Close()
+
+	client := New(config.WarrantyConfig{
+		ClientID:     "id",
+		ClientSecret: "secret",
+		URL:          server.URL + "/asset-entitlements",
+		TokenURL:     server.URL + "/token",
+	})
+
+	built := time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC)
+	results := []models.ServerInfo{
+		{Host: "a", Manufacturer: "Dell Inc.", Model: "PowerEdge R740", ServiceTag: "abc1234"},
+		{Host: "b", Manufacturer: "Dell Inc.", Model: "PowerEdge R740", ServiceTag: "UNKNOWN"},
+		{Host: "c", Manufacturer: "Dell Inc.", Model: "PowerEdge R750", ServiceTag: "DEF5678", ManufactureDate: &built},
+		{Host: "d", Manufacturer: "HPE", Model: "ProLiant DL380 Gen10", ServiceTag: "CZ12345"},
+		{Host: "e", Error: errors.New("timeout"), ServiceTag: "GHI9012"},
+	}
+	updated, err := client.Apply(context.Background(), results)
+	require.NoError(t, err)
+	assert.Equal(t, 1, updated)
+	assert.Equal(t, []string{"ABC1234,UNKNOWN"}, queries, "only Dell servers without a date are looked up")
+
+	require.NotNil(t, results[0].ManufactureDate)
+	assert.Equal(t, "2019-05-14", results[0].ManufactureDate.Format("2006-01-02"))
+	assert.Equal(t, models.ManufactureDateWarranty, results[0].ManufactureDateSource)
+	assert.Nil(t, results[1].ManufactureDate)
+	assert.Equal(t, built, *results[2].ManufactureDate, "the iDRAC date is kept")
+}
+
+func TestShipDates_Errors(t *testing.T) {
+	var queries []string
+	server := newTestAPI(t, &queries)
+	defer server.Close()
+
+	client := New(config.WarrantyConfig{
+		ClientID:     "id",
+		ClientSecret: "wrong",
+		URL:          server.URL + "/asset-entitlements",
+		TokenURL:     server.URL + "/token",
+	})
+	_, err := client.ShipDates(context.Background(), []string{"ABC1234"})
+	assert.ErrorContains(t, err, "warranty API token: unexpected status 401")
+	assert.Empty(t, queries)
+}
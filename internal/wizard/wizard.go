@@ -0,0 +1,280 @@
+// Package wizard generates a starter configuration file from a few answers
+// (NetBox connection, default iDRAC credentials, IP ranges), asked
+// interactively or given as flags to the init command.
+package wizard
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/config"
+	"github.com/braunma/idrac-netbox-importer/pkg/defaults"
+)
+
+// Answers are the settings written to the generated config.
+type Answers struct {
+	NetBoxURL   string
+	NetBoxToken string
+
+	Username string
+	Password string
+
+	// IPRanges are single addresses or ranges ("10.0.0.1-10.0.0.25").
+	IPRanges []string
+
+	InsecureSkipVerify bool
+	Concurrency        int
+}
+
+// envRef matches a value that is only an environment variable reference,
+// like ${IDRAC_DEFAULT_PASS}.
+var envRef = regexp.MustCompile(`^\$\{[A-Za-z_][A-Za-z0-9_]*\}$`)
+
+// Validate checks the answers. Secrets may contain "$" only as a complete
+// ${VAR} reference, since the config file is expanded from the environment.
+func (a Answers) Validate() error {
+	if len(a.IPRanges) == 0 {
+		return fmt.Errorf("at least one IP range is required")
+	}
+	if _, err := config.ExpandIPRanges(a.IPRanges); err != nil {
+		return err
+	}
+	if a.NetBoxURL != "" && !envRef.MatchString(a.NetBoxURL) &&
+		!strings.HasPrefix(a.NetBoxURL, "http://") && !strings.HasPrefix(a.NetBoxURL, "https://") {
+		return fmt.Errorf("NetBox URL must start with http:// or https://")
+	}
+	for name, v := range map[string]string{
+		"NetBox token":   a.NetBoxToken,
+		"iDRAC username": a.Username,
+		"iDRAC password": a.Password,
+	} {
+		if strings.Contains(v, "$") && !envRef.MatchString(v) {
+			return fmt.Errorf("%s contains \"$\": set it in an environment variable and enter ${VAR} instead", name)
+		}
+	}
+	if a.Concurrency < 1 || a.Concurrency > defaults.DefaultMaxConcurrency {
+		return fmt.Errorf("concurrency must be between 1 and %d", defaults.DefaultMaxConcurrency)
+	}
+	return nil
+}
+
+// Prompter asks questions on a terminal, offering the current value as the
+// default.
+type Prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// NewPrompter returns a Prompter reading answers from in.
+func NewPrompter(in io.Reader, out io.Writer) *Prompter {
+	return &Prompter{in: bufio.NewReader(in), out: out}
+}
+
+// Ask asks for each answer, starting from a (e.g. the flag values). An empty
+// line keeps the default.
+func (p *Prompter) Ask(a Answers) (Answers, error) {
+	fmt.Fprintf(p.out, "Answers are shown as typed; enter ${VAR} to read a secret from the environment.\n\n")
+
+	var err error
+	ask := func(question string, value *string) {
+		if err == nil {
+			*value, err = p.String(question, *value)
+		}
+	}
+	ask("NetBox URL (empty to skip NetBox)", &a.NetBoxURL)
+	if a.NetBoxURL != "" {
+		ask("NetBox API token", &a.NetBoxToken)
+	}
+	ask("Default iDRAC username", &a.Username)
+	ask("Default iDRAC password", &a.Password)
+
+	ranges := strings.Join(a.IPRanges, ", ")
+	ask("iDRAC IP ranges (comma-separated, e.g. 10.0.0.1-10.0.0.25)", &ranges)
+	a.IPRanges = SplitList(ranges)
+
+	if err == nil {
+		a.InsecureSkipVerify, err = p.Confirm("Accept self-signed iDRAC certificates", a.InsecureSkipVerify)
+	}
+	if err == nil {
+		concurrency := strconv.Itoa(a.Concurrency)
+		ask("Servers to scan in parallel", &concurrency)
+		if n, convErr := strconv.Atoi(strings.TrimSpace(concurrency)); convErr == nil {
+			a.Concurrency = n
+		}
+	}
+	return a, err
+}
+
+// String asks a question and returns the answer, or def for an empty line.
+func (p *Prompter) String(question, def string) (string, error) {
+	if def != "" {
+		fmt.Fprintf(p.out, "%s [%s]: ", question, def)
+	} else {
+		fmt.Fprintf(p.out, "%s: ", question)
+	}
+	line, err := p.in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", fmt.Errorf("no answer to %q: %w", question, err)
+	}
+	if line = strings.TrimSpace(line); line != "" {
+		return line, nil
+	}
+	return def, nil
+}
+
+// Confirm asks a yes/no question and returns the answer, or def for an
+// empty line.
+func (p *Prompter) Confirm(question string, def bool) (bool, error) {
+	hint := "y/N"
+	if def {
+		hint = "Y/n"
+	}
+	for {
+		answer, err := p.String(fmt.Sprintf("%s? (%s)", question, hint), "")
+		if err != nil {
+			return false, err
+		}
+		switch strings.ToLower(answer) {
+		case "":
+			return def, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+	}
+}
+
+// SplitList splits a comma-separated list, dropping empty items.
+func SplitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Render returns the config file for the answers, with comments.
+func Render(a Answers) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := configTemplate.Execute(&buf, a); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Write renders the answers to path, readable by the owner only as it may
+// contain secrets. An existing file is only replaced if overwrite is set.
+func Write(path string, a Answers, overwrite bool) error {
+	data, err := Render(a)
+	if err != nil {
+		return err
+	}
+	flags := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if overwrite {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+	f, err := os.OpenFile(path, flags, 0o600)
+	if err != nil {
+		if os.IsExist(err) {
+			return fmt.Errorf("%s already exists (use -force to overwrite)", path)
+		}
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// quote returns s as a double-quoted YAML scalar (JSON strings are valid
+// YAML).
+func quote(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+var configTemplate = template.Must(template.New("config").Funcs(template.FuncMap{"quote": quote}).Parse(
+	`# =============================================================================
+# iDRAC Hardware Inventory Tool - Configuration
+# =============================================================================
+#
+# Generated by "idrac-inventory init". See the example config.yaml shipped
+# with the tool for all settings (history, GitLab export, hooks, ...).
+# Values can be overridden via environment variables (see the Environment
+# Variables section of the README).
+# =============================================================================
+
+# -----------------------------------------------------------------------------
+# NetBox Integration
+# -----------------------------------------------------------------------------
+{{if .NetBoxURL -}}
+netbox:
+  # NetBox API URL - Override: NETBOX_URL
+  url: {{quote .NetBoxURL}}
+
+  # API Token - Override: NETBOX_TOKEN
+  # Generate at: NetBox > Admin > API Tokens
+  token: {{quote .NetBoxToken}}
+
+  # Skip TLS certificate verification - Override: NETBOX_INSECURE_SKIP_VERIFY
+  insecure_skip_verify: false
+
+  # API timeout in seconds - Override: NETBOX_TIMEOUT
+  timeout_seconds: 30
+{{else -}}
+# Not configured. Set NETBOX_URL and NETBOX_TOKEN, or uncomment:
+#
+# netbox:
+#   url: "https://netbox.example.com"
+#   token: "${NETBOX_TOKEN}"
+{{end}}
+# -----------------------------------------------------------------------------
+# Default iDRAC Settings
+# -----------------------------------------------------------------------------
+defaults:
+  # Default iDRAC username - Override: IDRAC_DEFAULT_USER
+  username: {{quote .Username}}
+
+  # Default iDRAC password - Override: IDRAC_DEFAULT_PASS
+  password: {{quote .Password}}
+
+  # Connection timeout in seconds - Override: IDRAC_DEFAULT_TIMEOUT
+  timeout_seconds: 60
+
+  # Skip TLS verification (iDRAC uses self-signed certs) - Override: IDRAC_INSECURE_SKIP_VERIFY
+  insecure_skip_verify: {{.InsecureSkipVerify}}
+
+# Maximum number of servers to scan in parallel - Override: IDRAC_CONCURRENCY
+concurrency: {{.Concurrency}}
+
+# -----------------------------------------------------------------------------
+# Logging
+# -----------------------------------------------------------------------------
+logging:
+  level: "info"
+  format: "console"
+
+# -----------------------------------------------------------------------------
+# Servers
+# -----------------------------------------------------------------------------
+# Single addresses or ranges ("10.10.10.1-10.10.10.25"). Add more groups with
+# their own username/password for other network segments.
+server_groups:
+  - name: "default"
+    ip_ranges:
+{{- range .IPRanges}}
+      - {{quote .}}
+{{- end}}
+`))
@@ -0,0 +1,125 @@
+package wizard
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/config"
+)
+
+func validAnswers() Answers {
+	return Answers{
+		NetBoxURL:          "https://netbox.example.com",
+		NetBoxToken:        "0123456789abcdef",
+		Username:           "root",
+		Password:           `pa"ss: #word`,
+		IPRanges:           []string{"10.0.0.1-10.0.0.3", "10.0.1.10"},
+		InsecureSkipVerify: true,
+		Concurrency:        5,
+	}
+}
+
+func TestRender_ParsesBack(t *testing.T) {
+	a := validAnswers()
+	data, err := Render(a)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "# Default iDRAC password - Override: IDRAC_DEFAULT_PASS")
+
+	cfg, err := config.Parse(data)
+	require.NoError(t, err)
+	assert.Equal(t, "https://netbox.example.com", cfg.NetBox.URL)
+	assert.Equal(t, a.Password, cfg.Defaults.Password, "secrets are quoted")
+	assert.True(t, cfg.Defaults.GetInsecureSkipVerify())
+	assert.Equal(t, 5, cfg.Concurrency)
+	require.Len(t, cfg.Servers, 4)
+	assert.Equal(t, "10.0.0.1", cfg.Servers[0].Host)
+	assert.Equal(t, "10.0.1.10", cfg.Servers[3].Host)
+}
+
+func TestRender_WithoutNetBox(t *testing.T) {
+	a := validAnswers()
+	a.NetBoxURL, a.NetBoxToken = "", ""
+	data, err := Render(a)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "# netbox:\n")
+
+	cfg, err := config.Parse(data)
+	require.NoError(t, err)
+	assert.False(t, cfg.NetBox.IsEnabled())
+}
+
+func TestAnswers_Validate(t *testing.T) {
+	require.NoError(t, validAnswers().Validate())
+
+	a := validAnswers()
+	a.Password = "${IDRAC_DEFAULT_PASS}"
+	assert.NoError(t, a.Validate(), "environment reference")
+
+	for name, modify := range map[string]func(*Answers){
+		"no ranges":    func(a *Answers) { a.IPRanges = nil },
+		"bad range":    func(a *Answers) { a.IPRanges = []string{"10.0.0.9-10.0.0.1"} },
+		"bad URL":      func(a *Answers) { a.NetBoxURL = "netbox.example.com" },
+		"dollar":       func(a *Answers) { a.Password = "pa$word" },
+		"concurrency":  func(a *Answers) { a.Concurrency = 0 },
+		"partial ref":  func(a *Answers) { a.NetBoxToken = "${TOKEN}x" },
+		"too parallel": func(a *Answers) { a.Concurrency = 1000 },
+	} {
+		t.Run(name, func(t *testing.T) {
+			a := validAnswers()
+			modify(&a)
+			assert.Error(t, a.Validate())
+		})
+	}
+}
+
+func TestPrompter_Ask(t *testing.T) {
+	in := strings.NewReader(strings.Join([]string{
+		"https://netbox.example.com",
+		"${NETBOX_TOKEN}",
+		"", // keep username
+		"secret",
+		"10.0.0.1-10.0.0.3, 10.0.1.10,",
+		"maybe", // asked again
+		"n",
+		"8",
+	}, "\n") + "\n")
+	var out bytes.Buffer
+
+	a, err := NewPrompter(in, &out).Ask(Answers{Username: "root", InsecureSkipVerify: true, Concurrency: 5})
+	require.NoError(t, err)
+	assert.Equal(t, Answers{
+		NetBoxURL:   "https://netbox.example.com",
+		NetBoxToken: "${NETBOX_TOKEN}",
+		Username:    "root",
+		Password:    "secret",
+		IPRanges:    []string{"10.0.0.1-10.0.0.3", "10.0.1.10"},
+		Concurrency: 8,
+	}, a)
+	assert.Contains(t, out.String(), "Default iDRAC username [root]: ")
+	assert.Equal(t, 2, strings.Count(out.String(), "Accept self-signed iDRAC certificates? (Y/n)"))
+}
+
+func TestPrompter_Ask_EndOfInput(t *testing.T) {
+	_, err := NewPrompter(strings.NewReader("https://netbox.example.com\n"), &bytes.Buffer{}).Ask(Answers{})
+	assert.Error(t, err)
+}
+
+func TestWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, Write(path, validAnswers(), false))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	err = Write(path, validAnswers(), false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "already exists")
+	assert.NoError(t, Write(path, validAnswers(), true))
+}
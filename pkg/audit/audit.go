@@ -0,0 +1,120 @@
+// Package audit appends a record of every mutating operation (NetBox writes,
+// iDRAC writes) to a JSON Lines file for change-management purposes.
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"sync"
+	"time"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/runid"
+)
+
+// Results recorded in Entry.Result.
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+)
+
+// Entry is a single audit record (one line in the audit log).
+type Entry struct {
+	Timestamp     time.Time `json:"timestamp"`
+	Actor         string    `json:"actor"`
+	RunID         string    `json:"run_id"`
+	System        string    `json:"system"` // netbox, idrac
+	Action        string    `json:"action"` // HTTP method
+	Target        string    `json:"target"` // URL or resource
+	PayloadSHA256 string    `json:"payload_sha256,omitempty"`
+	Result        string    `json:"result"`
+	StatusCode    int       `json:"status_code,omitempty"`
+	Error         string    `json:"error,omitempty"`
+}
+
+// Logger appends entries to an audit file. A nil *Logger discards all
+// entries, so callers do not need to check whether auditing is enabled.
+type Logger struct {
+	path  string
+	actor string
+	mu    sync.Mutex
+}
+
+// New returns a Logger writing to path, or nil if path is empty.
+// If actor is empty, "<user>@<hostname>" of the running process is used.
+func New(path, actor string) *Logger {
+	if path == "" {
+		return nil
+	}
+	if actor == "" {
+		actor = DefaultActor()
+	}
+	return &Logger{path: path, actor: actor}
+}
+
+// DefaultActor returns "<user>@<hostname>" of the running process.
+func DefaultActor() string {
+	name := "unknown"
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		name = u.Username
+	}
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		return name
+	}
+	return name + "@" + host
+}
+
+// Digest returns the hex SHA-256 of a request payload ("" for an empty payload).
+func Digest(payload []byte) string {
+	if len(payload) == 0 {
+		return ""
+	}
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
+
+// Record appends an entry. Timestamp and Actor are filled in if unset, and
+// Result is derived from Error if unset.
+func (l *Logger) Record(e Entry) error {
+	if l == nil {
+		return nil
+	}
+	if e.Timestamp.IsZero() {
+		e.Timestamp = time.Now().UTC()
+	}
+	if e.Actor == "" {
+		e.Actor = l.actor
+	}
+	if e.RunID == "" {
+		e.RunID = runid.Current()
+	}
+	if e.Result == "" {
+		e.Result = ResultSuccess
+		if e.Error != "" {
+			e.Result = ResultFailure
+		}
+	}
+
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	file, err := os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	if _, err := file.Write(line); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return file.Close()
+}
@@ -0,0 +1,59 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/runid"
+)
+
+func TestLogger_Record(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	l := New(path, "ops@scanner01")
+
+	require.NoError(t, l.Record(Entry{
+		System:        "netbox",
+		Action:        "PATCH",
+		Target:        "https://netbox.example.com/api/dcim/devices/1/",
+		PayloadSHA256: Digest([]byte(`{"custom_fields":{}}`)),
+		StatusCode:    200,
+	}))
+	require.NoError(t, l.Record(Entry{
+		System: "netbox",
+		Action: "PATCH",
+		Target: "https://netbox.example.com/api/dcim/devices/2/",
+		Error:  "API error 400",
+	}))
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var entries []Entry
+	sc := bufio.NewScanner(file)
+	for sc.Scan() {
+		var e Entry
+		require.NoError(t, json.Unmarshal(sc.Bytes(), &e))
+		entries = append(entries, e)
+	}
+
+	require.Len(t, entries, 2)
+	assert.Equal(t, "ops@scanner01", entries[0].Actor)
+	assert.Equal(t, runid.Current(), entries[0].RunID)
+	assert.Equal(t, ResultSuccess, entries[0].Result)
+	assert.Len(t, entries[0].PayloadSHA256, 64)
+	assert.False(t, entries[0].Timestamp.IsZero())
+	assert.Equal(t, ResultFailure, entries[1].Result)
+}
+
+func TestLogger_NilDiscards(t *testing.T) {
+	var l *Logger
+	assert.Nil(t, New("", ""))
+	assert.NoError(t, l.Record(Entry{Action: "POST"}))
+}
@@ -0,0 +1,138 @@
+// Package catalog provides the physical properties (U height, airflow, weight,
+// power supply slots) of Dell PowerEdge models. A curated catalog is embedded
+// in the binary; users can add missing models or correct entries with an
+// override file (catalog.file).
+package catalog
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+//go:embed catalog.yaml
+var builtin []byte
+
+// Airflow values accepted by NetBox device types.
+var airflows = map[string]bool{
+	"front-to-rear": true, "rear-to-front": true,
+	"left-to-right": true, "right-to-left": true,
+	"side-to-rear": true, "rear-to-side": true,
+	"bottom-to-top": true, "top-to-bottom": true,
+	"passive": true, "mixed": true,
+}
+
+// Entry is one model in a catalog file.
+type Entry struct {
+	Model              string `yaml:"model"`
+	models.ChassisSpec `yaml:",inline"`
+}
+
+// file is the layout of the embedded catalog and of override files.
+type file struct {
+	Models []Entry `yaml:"models"`
+}
+
+// Catalog maps server models to their chassis properties.
+type Catalog struct {
+	specs map[string]models.ChassisSpec
+}
+
+// Default returns the embedded catalog.
+func Default() *Catalog {
+	c := &Catalog{specs: make(map[string]models.ChassisSpec)}
+	if err := c.merge(builtin); err != nil {
+		panic(fmt.Sprintf("embedded catalog: %v", err))
+	}
+	return c
+}
+
+// Load returns the embedded catalog merged with the override file at path.
+// Entries in the override file replace embedded entries of the same model.
+// An empty path returns the embedded catalog.
+func Load(path string) (*Catalog, error) {
+	c := Default()
+	if path == "" {
+		return c, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read model catalog: %w", err)
+	}
+	if err := c.merge(data); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return c, nil
+}
+
+func (c *Catalog) merge(data []byte) error {
+	var f file
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return fmt.Errorf("invalid model catalog: %w", err)
+	}
+	for i, e := range f.Models {
+		if err := e.validate(); err != nil {
+			return fmt.Errorf("models[%d]: %w", i, err)
+		}
+		c.specs[normalize(e.Model)] = e.ChassisSpec
+	}
+	return nil
+}
+
+func (e Entry) validate() error {
+	if normalize(e.Model) == "" {
+		return fmt.Errorf("model is required")
+	}
+	if e.UHeight <= 0 || e.UHeight*2 != float64(int(e.UHeight*2)) {
+		return fmt.Errorf("%s: u_height must be a positive multiple of 0.5", e.Model)
+	}
+	if e.Airflow != "" && !airflows[e.Airflow] {
+		return fmt.Errorf("%s: unknown airflow %q", e.Model, e.Airflow)
+	}
+	if e.WeightKg < 0 || e.PSUSlots < 0 {
+		return fmt.Errorf("%s: weight_kg and psu_slots must not be negative", e.Model)
+	}
+	return nil
+}
+
+// Lookup returns the chassis properties of model. "PowerEdge R750",
+// "Dell PowerEdge R750" and "r750" all match the R750 entry.
+func (c *Catalog) Lookup(model string) (models.ChassisSpec, bool) {
+	spec, ok := c.specs[normalize(model)]
+	return spec, ok
+}
+
+// Models returns the catalog's model keys in sorted order.
+func (c *Catalog) Models() []string {
+	keys := make([]string, 0, len(c.specs))
+	for k := range c.specs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Annotate sets the Chassis of every model group found in the catalog.
+func (c *Catalog) Annotate(inv *models.AggregatedInventory) {
+	for i := range inv.ModelGroups {
+		if spec, ok := c.Lookup(inv.ModelGroups[i].Model); ok {
+			inv.ModelGroups[i].Chassis = &spec
+		}
+	}
+}
+
+// normalize reduces a model name to its catalog key, e.g. "PowerEdge R750xa" → "R750XA".
+func normalize(model string) string {
+	fields := strings.Fields(strings.ToUpper(model))
+	for len(fields) > 1 && (fields[0] == "DELL" || fields[0] == "POWEREDGE") {
+		fields = fields[1:]
+	}
+	return strings.Join(fields, " ")
+}
@@ -0,0 +1,93 @@
+package catalog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+func TestDefault_Lookup(t *testing.T) {
+	c := Default()
+
+	for _, name := range []string{"PowerEdge R750", "Dell PowerEdge R750", "r750", " R750 "} {
+		spec, ok := c.Lookup(name)
+		require.True(t, ok, name)
+		assert.Equal(t, 2.0, spec.UHeight)
+		assert.Equal(t, "front-to-rear", spec.Airflow)
+		assert.Equal(t, 2, spec.PSUSlots)
+	}
+
+	spec, ok := c.Lookup("PowerEdge R750xa")
+	require.True(t, ok)
+	assert.Equal(t, 2.0, spec.UHeight)
+
+	_, ok = c.Lookup("PowerEdge R9999")
+	assert.False(t, ok)
+	_, ok = c.Lookup("")
+	assert.False(t, ok)
+}
+
+func TestLoad_Override(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "catalog.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`models:
+  - model: PowerEdge R750
+    u_height: 2
+    airflow: front-to-rear
+    weight_kg: 30
+    psu_slots: 2
+  - model: XR11
+    u_height: 1
+    airflow: rear-to-front
+`), 0o600))
+
+	c, err := Load(path)
+	require.NoError(t, err)
+
+	spec, ok := c.Lookup("R750")
+	require.True(t, ok)
+	assert.Equal(t, 30.0, spec.WeightKg, "override replaces the embedded entry")
+
+	spec, ok = c.Lookup("PowerEdge XR11")
+	require.True(t, ok, "override adds missing models")
+	assert.Equal(t, "rear-to-front", spec.Airflow)
+
+	_, ok = c.Lookup("R640")
+	assert.True(t, ok, "embedded entries are kept")
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	for name, content := range map[string]string{
+		"missing model":   "models:\n  - u_height: 1\n",
+		"zero height":     "models:\n  - model: X1\n",
+		"fractional":      "models:\n  - model: X1\n    u_height: 1.3\n",
+		"unknown airflow": "models:\n  - model: X1\n    u_height: 1\n    airflow: sideways\n",
+		"not yaml":        "models: [",
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "catalog.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+			_, err := Load(path)
+			assert.Error(t, err)
+		})
+	}
+
+	_, err := Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+}
+
+func TestAnnotate(t *testing.T) {
+	inv := models.AggregatedInventory{ModelGroups: []models.ModelGroup{
+		{Model: "PowerEdge R640"},
+		{Model: "Custom Box"},
+	}}
+	Default().Annotate(&inv)
+
+	require.NotNil(t, inv.ModelGroups[0].Chassis)
+	assert.Equal(t, "1U · front-to-rear · 21.9 kg · 2 PSU slots", inv.ModelGroups[0].Chassis.String())
+	assert.Nil(t, inv.ModelGroups[1].Chassis)
+}
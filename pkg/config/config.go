@@ -0,0 +1,2648 @@
+// Package config handles loading, validation, and management of application configuration.
+// It supports YAML configuration files with environment variable overrides.
+// All default values are sourced from the defaults package to ensure consistency.
+package config
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/defaults"
+	"github.com/braunma/idrac-netbox-importer/pkg/errors"
+	"github.com/braunma/idrac-netbox-importer/pkg/features"
+	"github.com/braunma/idrac-netbox-importer/pkg/logging"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+	"github.com/braunma/idrac-netbox-importer/pkg/units"
+)
+
+// Config is the root configuration structure.
+type Config struct {
+	NetBox       NetBoxConfig      `yaml:"netbox"`
+	GitLab       GitLabConfig      `yaml:"gitlab"`
+	Servers      []ServerConfig    `yaml:"servers"`
+	ServerGroups []ServerGroup     `yaml:"server_groups,omitempty"`
+	Defaults     DefaultsConfig    `yaml:"defaults"`
+	Concurrency  int               `yaml:"concurrency"`
+	ReadOnly     bool              `yaml:"read_only"` // refuse all writes (NetBox, git push, iDRAC)
+	Logging      LoggingConfig     `yaml:"logging"`
+	Retry        RetryConfig       `yaml:"retry"`
+	Rescan       RescanConfig      `yaml:"rescan"`
+	HTTP         HTTPConfig        `yaml:"http"`
+	Aggregation  AggregationConfig `yaml:"aggregation"`
+	Daemon       DaemonConfig      `yaml:"daemon"`
+	Paths        PathsConfig       `yaml:"paths"`
+	History      HistoryConfig     `yaml:"history"`
+	Audit        AuditConfig       `yaml:"audit"`
+	Signing      SigningConfig     `yaml:"signing"`
+	Hooks        HooksConfig       `yaml:"hooks"`
+	Catalog      CatalogConfig     `yaml:"catalog"`
+	Placement    PlacementConfig   `yaml:"placement"`
+	Annotations  AnnotationsConfig `yaml:"annotations"`
+	Golden       GoldenConfig      `yaml:"golden"`
+	Collectors   CollectorsConfig  `yaml:"collectors"`
+	Units        UnitsConfig       `yaml:"units"`
+	Clock        ClockConfig       `yaml:"clock"`
+	Accounts     AccountsConfig    `yaml:"accounts"`
+	Output       OutputConfig      `yaml:"output"`
+	Warehouse    WarehouseConfig   `yaml:"warehouse"`
+	Stats        StatsConfig       `yaml:"stats"`
+	Warranty     WarrantyConfig    `yaml:"warranty"`
+	Notify       NotifyConfig      `yaml:"notify"`
+
+	// FirmwarePolicy sets the minimum iDRAC and BIOS versions the servers
+	// are checked against after each scan.
+	FirmwarePolicy FirmwarePolicyConfig `yaml:"firmware_policy"`
+
+	// Targets is a CSV file of further servers (columns host, name,
+	// username, password, group), e.g. a DCIM export. Its servers are added
+	// after those of server_groups (see ParseTargets). A relative path is
+	// relative to the directory of the config file.
+	Targets string `yaml:"targets"`
+
+	// Shard ("2/5") limits this instance to a deterministic subset of the
+	// servers, so several instances can split a large fleet (see ParseShard).
+	Shard   string `yaml:"shard"`
+	ShardBy string `yaml:"shard_by"` // hash (default) or index
+
+	// Profiles are named overlays of the settings above (e.g. prod, lab),
+	// selected with -profile. A profile may contain any top-level key except
+	// profiles; if it lists servers, server_groups or targets, it replaces the
+	// top-level inventory entirely.
+	Profiles map[string]yaml.Node `yaml:"profiles,omitempty"`
+
+	// Profile is the name of the applied profile ("" if none).
+	Profile string `yaml:"-"`
+}
+
+// DaemonConfig controls daemon mode (-daemon), which scans at a fixed interval.
+type DaemonConfig struct {
+	// Interval between scan cycles as a Go duration (default: "1h").
+	Interval string `yaml:"interval"`
+
+	// HealthListen is the address of the /healthz (liveness) and /readyz
+	// (readiness) endpoints, e.g. ":8081". Empty disables them.
+	HealthListen string `yaml:"health_listen"`
+
+	// Grafana serves the last results and the fleet trends for the Grafana
+	// JSON and Infinity datasources under /grafana/ on HealthListen.
+	Grafana bool `yaml:"grafana"`
+
+	// API serves the latest result of every host as JSON under /api/ on
+	// HealthListen (see internal/inventory).
+	API bool `yaml:"api"`
+
+	// LeaderElection lets only one of several replicas scan per interval.
+	LeaderElection LeaderElectionConfig `yaml:"leader_election"`
+
+	// Backoff re-scans failing hosts at growing intervals.
+	Backoff BackoffConfig `yaml:"backoff"`
+
+	// Budget limits the duration, the hosts and the hours of scan cycles.
+	Budget BudgetConfig `yaml:"budget"`
+
+	// Events rescans hosts when their iDRAC pushes a Redfish event.
+	Events EventsConfig `yaml:"events"`
+}
+
+// EventsConfig controls the Redfish event listener of daemon mode. The
+// daemon receives the events pushed by the iDRACs and rescans a host (and
+// syncs it to NetBox with -sync) when a hardware or configuration event
+// arrives, instead of waiting for the next cycle.
+type EventsConfig struct {
+	// Listen is the address of the event receiver, e.g. ":8443". Empty
+	// disables it.
+	Listen string `yaml:"listen"`
+
+	// TLSCert and TLSKey are the PEM files of the receiver's certificate.
+	// iDRACs only push events to HTTPS destinations.
+	TLSCert string `yaml:"tls_cert"`
+	TLSKey  string `yaml:"tls_key"`
+
+	// Destination is the URL of the receiver as reached by the iDRACs, e.g.
+	// "https://inventory.example.com:8443/events". If set, the daemon
+	// registers a subscription for it on the iDRACs at startup.
+	Destination string `yaml:"destination"`
+
+	// Hosts limits the subscriptions to these hosts or server names
+	// (default: all servers).
+	Hosts []string `yaml:"hosts"`
+
+	// MessageIDs are the message ID prefixes that trigger a rescan, matched
+	// against the last part of the MessageId, e.g. "PDR" for
+	// "iDRAC.2.8.PDR1016" (default: part replacement, drive, memory, PSU,
+	// CPU, hardware configuration and firmware update messages, and the
+	// Redfish resource events).
+	MessageIDs []string `yaml:"message_ids"`
+
+	// Severities trigger a rescan whatever the message (default: Critical).
+	Severities []string `yaml:"severities"`
+
+	// Debounce is how long events are collected after the first one before
+	// the hosts are rescanned (Go duration, default: "30s").
+	Debounce string `yaml:"debounce"`
+}
+
+// defaultEventMessageIDs are the message ID prefixes of events that may
+// change the inventory.
+var defaultEventMessageIDs = []string{
+	"PR", "PDR", "MEM", "PSU", "CPU", "HWC", "RED",
+	"ResourceAdded", "ResourceRemoved", "ResourceChanged",
+}
+
+// IsEnabled returns true if the event receiver should run.
+func (e EventsConfig) IsEnabled() bool {
+	return e.Listen != ""
+}
+
+// GetMessageIDs returns the message ID prefixes that trigger a rescan.
+func (e EventsConfig) GetMessageIDs() []string {
+	if len(e.MessageIDs) > 0 {
+		return e.MessageIDs
+	}
+	return defaultEventMessageIDs
+}
+
+// GetSeverities returns the severities that trigger a rescan.
+func (e EventsConfig) GetSeverities() []string {
+	if len(e.Severities) > 0 {
+		return e.Severities
+	}
+	return []string{"Critical"}
+}
+
+// GetDebounce returns how long events are collected before rescanning.
+func (e EventsConfig) GetDebounce() time.Duration {
+	if d, err := time.ParseDuration(e.Debounce); err == nil && d > 0 {
+		return d
+	}
+	return defaults.DefaultEventDebounce
+}
+
+// BackoffConfig controls the per-host schedule of daemon mode: a host that
+// fails repeatedly is re-scanned after 1, 2, 4, … intervals up to
+// MaxInterval, and quarantined (no longer scanned until released with the
+// schedule command) after QuarantineAfter consecutive failures.
+type BackoffConfig struct {
+	Enabled         bool   `yaml:"enabled"`
+	MaxInterval     string `yaml:"max_interval"`     // Go duration (default: "24h")
+	QuarantineAfter int    `yaml:"quarantine_after"` // default: 10; negative disables quarantine
+}
+
+// GetMaxInterval returns the longest re-scan interval of a failing host.
+func (b BackoffConfig) GetMaxInterval() time.Duration {
+	if i, err := time.ParseDuration(b.MaxInterval); err == nil && i > 0 {
+		return i
+	}
+	return defaults.DefaultBackoffMaxInterval
+}
+
+// GetQuarantineAfter returns the number of consecutive failures that
+// quarantine a host, or 0 if quarantine is disabled.
+func (b BackoffConfig) GetQuarantineAfter() int {
+	switch {
+	case b.QuarantineAfter < 0:
+		return 0
+	case b.QuarantineAfter == 0:
+		return defaults.DefaultQuarantineAfter
+	}
+	return b.QuarantineAfter
+}
+
+// BudgetConfig keeps the daemon's iDRAC traffic within limits: a cycle
+// stops starting new hosts after MaxDuration, scans at most MaxHosts hosts
+// (those scanned least recently first, the rest in the next cycles) and no
+// scans run during the Blackout windows, e.g. business hours.
+type BudgetConfig struct {
+	MaxDuration string   `yaml:"max_duration"` // Go duration; empty: no limit
+	MaxHosts    int      `yaml:"max_hosts"`    // 0: no limit
+	Blackout    []string `yaml:"blackout"`     // daily windows such as "08:00-18:00"
+
+	// Timezone of the blackout windows, e.g. "Europe/Berlin" (default: the
+	// local time zone).
+	Timezone string `yaml:"timezone"`
+}
+
+// GetMaxDuration returns the longest time a cycle starts new hosts, or 0
+// if unlimited.
+func (b BudgetConfig) GetMaxDuration() time.Duration {
+	if d, err := time.ParseDuration(b.MaxDuration); err == nil && d > 0 {
+		return d
+	}
+	return 0
+}
+
+// GetBlackout returns the blackout windows. Invalid windows are left out
+// (see Validate).
+func (b BudgetConfig) GetBlackout() []ClockWindow {
+	var windows []ClockWindow
+	for _, s := range b.Blackout {
+		if w, err := ParseClockWindow(s); err == nil {
+			windows = append(windows, w)
+		}
+	}
+	return windows
+}
+
+// Location returns the time zone of the blackout windows.
+func (b BudgetConfig) Location() *time.Location {
+	if b.Timezone != "" {
+		if loc, err := time.LoadLocation(b.Timezone); err == nil {
+			return loc
+		}
+	}
+	return time.Local
+}
+
+// BlackoutUntil reports whether now is inside a blackout window and, if so,
+// when scans may resume. Adjacent or overlapping windows are joined.
+func (b BudgetConfig) BlackoutUntil(now time.Time) (time.Time, bool) {
+	windows := b.GetBlackout()
+	t := now.In(b.Location())
+	in := false
+	// Each pass moves past one window, so len(windows) passes join them all
+	for range windows {
+		moved := false
+		for _, w := range windows {
+			if w.Contains(t) {
+				t, in, moved = w.EndAfter(t), true, true
+			}
+		}
+		if !moved {
+			break
+		}
+	}
+	return t, in
+}
+
+// NextBlackout returns the start of the first blackout window after now,
+// or the zero time if there are none.
+func (b BudgetConfig) NextBlackout(now time.Time) time.Time {
+	var next time.Time
+	t := now.In(b.Location())
+	for _, w := range b.GetBlackout() {
+		if start := w.NextStart(t); next.IsZero() || start.Before(next) {
+			next = start
+		}
+	}
+	return next
+}
+
+// Deadline returns when a cycle starting at now must stop starting new
+// hosts: after MaxDuration or when a blackout window begins, whichever
+// comes first. It returns the zero time if there is no limit.
+func (b BudgetConfig) Deadline(now time.Time) time.Time {
+	deadline := b.NextBlackout(now)
+	if d := b.GetMaxDuration(); d > 0 && (deadline.IsZero() || now.Add(d).Before(deadline)) {
+		deadline = now.Add(d)
+	}
+	return deadline
+}
+
+// CycleWait returns the wait after a cycle at now: the interval, or less if
+// now is inside a blackout window that ends earlier. Otherwise a cycle
+// skipped for a blackout could land in the window again every interval
+// (e.g. every 24h) and never scan.
+func (b BudgetConfig) CycleWait(now time.Time, interval time.Duration) time.Duration {
+	if until, ok := b.BlackoutUntil(now); ok && until.Sub(now) < interval {
+		return until.Sub(now)
+	}
+	return interval
+}
+
+// validate checks the budget settings.
+func (b BudgetConfig) validate(multiErr *errors.MultiError) {
+	if b.MaxDuration != "" {
+		if d, err := time.ParseDuration(b.MaxDuration); err != nil || d <= 0 {
+			multiErr.Add(errors.NewConfigError("daemon.budget.max_duration",
+				fmt.Sprintf("invalid duration %q (use a positive Go duration such as 45m)", b.MaxDuration)))
+		}
+	}
+	if b.MaxHosts < 0 {
+		multiErr.Add(errors.NewConfigError("daemon.budget.max_hosts",
+			"must not be negative"))
+	}
+	for i, s := range b.Blackout {
+		if _, err := ParseClockWindow(s); err != nil {
+			multiErr.Add(errors.NewConfigError(fmt.Sprintf("daemon.budget.blackout[%d]", i), err.Error()))
+		}
+	}
+	if b.Timezone != "" {
+		if _, err := time.LoadLocation(b.Timezone); err != nil {
+			multiErr.Add(errors.NewConfigError("daemon.budget.timezone",
+				fmt.Sprintf("unknown time zone %q", b.Timezone)))
+		}
+	}
+}
+
+// LeaderElectionConfig configures the lease that replicas compete for. The
+// lease is a file on shared storage (LockFile) or held by an HTTP lock
+// service (LockURL, see the lock-server command).
+type LeaderElectionConfig struct {
+	LockFile string `yaml:"lock_file"`
+	LockURL  string `yaml:"lock_url"`
+
+	// Identity of this replica (default: the hostname, i.e. the pod name).
+	Identity string `yaml:"identity"`
+
+	// TTL of the lease as a Go duration. The leader renews it every cycle;
+	// if it stops, another replica takes over once the lease expired
+	// (default: twice the daemon interval; required outside daemon mode).
+	TTL string `yaml:"ttl"`
+}
+
+// IsEnabled returns true if a lock file or lock service is configured.
+func (l LeaderElectionConfig) IsEnabled() bool {
+	return l.LockFile != "" || l.LockURL != ""
+}
+
+// GetIdentity returns the identity of this replica.
+func (l LeaderElectionConfig) GetIdentity() string {
+	if l.Identity != "" {
+		return l.Identity
+	}
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		return fmt.Sprintf("pid-%d", os.Getpid())
+	}
+	return host
+}
+
+// GetTTL returns the lease TTL, or fallback if none is configured.
+func (l LeaderElectionConfig) GetTTL(fallback time.Duration) time.Duration {
+	if ttl, err := time.ParseDuration(l.TTL); err == nil && ttl > 0 {
+		return ttl
+	}
+	return fallback
+}
+
+// GetInterval returns the scan interval.
+func (d DaemonConfig) GetInterval() time.Duration {
+	if d.Interval == "" {
+		return defaults.DefaultDaemonInterval
+	}
+	if i, err := time.ParseDuration(d.Interval); err == nil && i > 0 {
+		return i
+	}
+	return defaults.DefaultDaemonInterval
+}
+
+// PathsConfig overrides the state and cache directories.
+// By default they follow the XDG base directory spec (see defaults.StateDir).
+type PathsConfig struct {
+	StateDir string `yaml:"state_dir"`
+	CacheDir string `yaml:"cache_dir"`
+}
+
+// GetStateDir returns the directory for persistent state.
+func (p PathsConfig) GetStateDir() string {
+	return getStringOrDefault(p.StateDir, defaults.StateDir())
+}
+
+// GetCacheDir returns the directory for cached data.
+func (p PathsConfig) GetCacheDir() string {
+	return getStringOrDefault(p.CacheDir, defaults.CacheDir())
+}
+
+// HistoryConfig controls the last-known-good store. When enabled, a host that
+// fails a scan but succeeded before is reported with its previous inventory,
+// flagged as stale, instead of as a bare error. The daily fleet totals are
+// recorded as well, for the trend section of the Markdown report.
+type HistoryConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Path of the history file (default: <state_dir>/last-known-good.json).
+	Path string `yaml:"path"`
+	// TrendsPath is the file of the daily fleet totals
+	// (default: <state_dir>/trends.json).
+	TrendsPath string `yaml:"trends_path"`
+
+	// SkipUnchanged skips the deep collection of hosts whose Lifecycle
+	// Controller reports no inventory or configuration change since their
+	// last known good scan, and reuses that inventory instead.
+	SkipUnchanged bool `yaml:"skip_unchanged"`
+	// FullScanAfter forces a full scan of an unchanged host once this long
+	// has passed since its last full scan (Go duration, default: "168h").
+	FullScanAfter string `yaml:"full_scan_after"`
+}
+
+// GetPath returns the history file path within the given state directory.
+func (h HistoryConfig) GetPath(stateDir string) string {
+	return getStringOrDefault(h.Path, filepath.Join(stateDir, defaults.DefaultHistoryFile))
+}
+
+// GetFullScanAfter returns how long the inventory of an unchanged host may
+// be reused.
+func (h HistoryConfig) GetFullScanAfter() time.Duration {
+	if d, err := time.ParseDuration(h.FullScanAfter); err == nil && d > 0 {
+		return d
+	}
+	return defaults.DefaultFullScanAfter
+}
+
+// GetTrendsPath returns the trends file path within the given state directory.
+func (h HistoryConfig) GetTrendsPath(stateDir string) string {
+	return getStringOrDefault(h.TrendsPath, filepath.Join(stateDir, defaults.DefaultTrendsFile))
+}
+
+// AuditConfig controls the audit log of mutating operations (NetBox and
+// iDRAC writes).
+type AuditConfig struct {
+	// Path of the JSON Lines audit log; auditing is disabled if empty.
+	Path string `yaml:"path"`
+	// Actor recorded with each entry (default: "<user>@<hostname>").
+	Actor string `yaml:"actor"`
+}
+
+// SigningConfig controls ed25519 signing of generated reports. Each signed
+// file gets a <file>.sig sidecar that the verify subcommand checks.
+type SigningConfig struct {
+	// PrivateKey is the path of a PEM PKCS#8 ed25519 key; signing is disabled if empty.
+	PrivateKey string `yaml:"private_key"`
+	// PublicKey is the path of the PEM public key used by verify (default: derived from PrivateKey).
+	PublicKey string `yaml:"public_key"`
+}
+
+// IsEnabled returns true if reports should be signed.
+func (s SigningConfig) IsEnabled() bool {
+	return s.PrivateKey != ""
+}
+
+// HooksConfig lists user commands run at points of a scan run. Each command
+// is run through the shell with the results file and a run summary in
+// IDRAC_* environment variables (see the hooks package).
+type HooksConfig struct {
+	PostScan  []string `yaml:"post_scan"`  // after every scan, with the results written
+	PostSync  []string `yaml:"post_sync"`  // after the NetBox sync
+	OnFailure []string `yaml:"on_failure"` // when hosts failed or the run errored
+
+	// OnRegression runs when a host's hardware health got worse since its
+	// last good scan (requires history). RegressionSeverity is the minimum
+	// severity that triggers it: warning (default) or critical.
+	OnRegression       []string `yaml:"on_regression"`
+	RegressionSeverity string   `yaml:"regression_severity"`
+
+	// TimeoutSeconds limits each command (default: 300).
+	TimeoutSeconds int `yaml:"timeout_seconds"`
+}
+
+// IsEnabled returns true if any hook is configured.
+func (h HooksConfig) IsEnabled() bool {
+	return len(h.PostScan)+len(h.PostSync)+len(h.OnFailure)+len(h.OnRegression) > 0
+}
+
+// Timeout returns the per-command timeout.
+func (h HooksConfig) Timeout() time.Duration {
+	return secondsToDuration(h.TimeoutSeconds, defaults.DefaultHookTimeout)
+}
+
+// CatalogConfig configures the model catalog, which provides U height,
+// airflow, weight and power supply slots per server model.
+type CatalogConfig struct {
+	// File is a YAML file with models to add to (or correct in) the embedded
+	// catalog of Dell PowerEdge models.
+	File string `yaml:"file"`
+
+	// Aliases map model names to the name to use instead, e.g. when
+	// firmware versions report the same machine differently. Model names
+	// are normalized first (trimmed, vendor prefix dropped, PowerEdge
+	// models spelled the Dell way) and matched case-insensitively.
+	Aliases map[string]string `yaml:"aliases"`
+}
+
+// PlacementConfig configures the placement file, which maps service tags to
+// site, rack, U position and tenant. Listed placements are merged into the
+// results and written to the NetBox device on sync.
+type PlacementConfig struct {
+	// File is a CSV (with header row) or YAML (.yaml/.yml) placement file.
+	File string `yaml:"file"`
+}
+
+// AnnotationsConfig configures the annotations file, which maps hosts and
+// service tags to free-form notes. Listed notes are merged into the results
+// and, with netbox.sync_comments, written to the NetBox device comments.
+type AnnotationsConfig struct {
+	// File is a CSV (with header row) or YAML (.yaml/.yml) annotations file.
+	File string `yaml:"file"`
+}
+
+// GoldenConfig configures the golden config check, which compares each
+// server with the expected build of its model or NetBox device role.
+type GoldenConfig struct {
+	// File is the YAML file of expected builds (see internal/golden).
+	File string `yaml:"file"`
+}
+
+// FirmwarePolicyConfig sets the minimum firmware versions of the servers.
+// Servers running older iDRAC or BIOS firmware are marked non-compliant
+// in their results (firmware_compliance) and in -report firmware.
+type FirmwarePolicyConfig struct {
+	// Minimum versions of all models, e.g. "7.00.00.00" and "2.19.1"
+	MinIDRACVersion string `yaml:"min_idrac_version"`
+	MinBIOSVersion  string `yaml:"min_bios_version"`
+
+	// Models override the minimums per model. The first rule whose model
+	// matches the full model name or its last word (R750 matches
+	// "PowerEdge R750") applies; unset versions fall back to the minimums
+	// above.
+	Models []FirmwareModelPolicy `yaml:"models"`
+}
+
+// FirmwareModelPolicy holds the minimum firmware versions of one model.
+type FirmwareModelPolicy struct {
+	Model           string `yaml:"model"`
+	MinIDRACVersion string `yaml:"min_idrac_version"`
+	MinBIOSVersion  string `yaml:"min_bios_version"`
+}
+
+// IsEnabled returns true if any minimum version is set.
+func (f FirmwarePolicyConfig) IsEnabled() bool {
+	return f.MinIDRACVersion != "" || f.MinBIOSVersion != "" || len(f.Models) > 0
+}
+
+// validate checks the model rules and that every version contains a number.
+func (f FirmwarePolicyConfig) validate(multiErr *errors.MultiError) {
+	checkVersion := func(field, version string) {
+		if version != "" && !strings.ContainsAny(version, "0123456789") {
+			multiErr.Add(errors.NewConfigError(field, fmt.Sprintf("invalid version %q", version)))
+		}
+	}
+	checkVersion("firmware_policy.min_idrac_version", f.MinIDRACVersion)
+	checkVersion("firmware_policy.min_bios_version", f.MinBIOSVersion)
+	for i, m := range f.Models {
+		field := fmt.Sprintf("firmware_policy.models[%d]", i)
+		if m.Model == "" {
+			multiErr.Add(errors.NewConfigError(field+".model", "model is required"))
+		}
+		if m.MinIDRACVersion == "" && m.MinBIOSVersion == "" {
+			multiErr.Add(errors.NewConfigError(field, "min_idrac_version or min_bios_version is required"))
+		}
+		checkVersion(field+".min_idrac_version", m.MinIDRACVersion)
+		checkVersion(field+".min_bios_version", m.MinBIOSVersion)
+	}
+}
+
+// ClockConfig controls the check of the iDRAC clocks against the local
+// clock. A drifted BMC clock breaks TLS certificate validity checks and
+// makes SEL and Lifecycle Controller timestamps misleading.
+type ClockConfig struct {
+	// MaxDrift is the largest accepted difference as a Go duration
+	// (default: "2m").
+	MaxDrift string `yaml:"max_drift"`
+}
+
+// GetMaxDrift returns the largest accepted clock drift.
+func (c ClockConfig) GetMaxDrift() time.Duration {
+	if d, err := time.ParseDuration(c.MaxDrift); err == nil && d > 0 {
+		return d
+	}
+	return defaults.DefaultMaxClockDrift
+}
+
+// AccountsConfig controls the audit of the local iDRAC user accounts, an
+// opt-in collector since reading the AccountService needs a user with the
+// Configure Users privilege.
+type AccountsConfig struct {
+	// Audit collects the accounts of each iDRAC.
+	Audit bool `yaml:"audit"`
+
+	// ProbeDefaultCredentials additionally tries a single login as root
+	// with the factory default password. Each failed probe counts towards
+	// the iDRAC's IP blocking threshold.
+	ProbeDefaultCredentials bool `yaml:"probe_default_credentials"`
+
+	// MaxAdministrators flags iDRACs with more enabled Administrator
+	// accounts (0 for no limit).
+	MaxAdministrators int `yaml:"max_administrators"`
+}
+
+// OutputConfig lists destinations the results are written to in addition
+// to the -output on stdout, e.g. to archive every report in object storage.
+type OutputConfig struct {
+	Sinks []SinkConfig `yaml:"sinks"`
+
+	// Raw attaches the Redfish responses to the JSON output.
+	Raw RawOutputConfig `yaml:"raw"`
+}
+
+// RawOutputConfig attaches the Redfish responses read while scanning a host
+// to its JSON output under raw (-include-raw), for consumers that need
+// properties the inventory does not model. Responses are grouped by
+// collector phase and keyed by resource path.
+type RawOutputConfig struct {
+	Enabled bool `yaml:"enabled"`
+
+	// Phases limits the responses to these collector phases, e.g. storage
+	// (all phases if empty).
+	Phases []string `yaml:"phases"`
+
+	// Prune lists properties removed from the responses at any depth, e.g.
+	// "@odata.etag", "Links" or "Oem".
+	Prune []string `yaml:"prune"`
+
+	// MaxResourceKB leaves out responses larger than this after pruning
+	// (default 256). MaxHostKB stops adding responses to a host once its
+	// total reaches this (default 4096). Left out responses are listed in
+	// raw.omitted.
+	MaxResourceKB int `yaml:"max_resource_kb"`
+	MaxHostKB     int `yaml:"max_host_kb"`
+}
+
+// GetMaxResourceBytes returns the size limit of one response.
+func (r RawOutputConfig) GetMaxResourceBytes() int {
+	if r.MaxResourceKB > 0 {
+		return r.MaxResourceKB * 1024
+	}
+	return defaults.DefaultRawMaxResourceKB * 1024
+}
+
+// GetMaxHostBytes returns the size limit of all responses of a host.
+func (r RawOutputConfig) GetMaxHostBytes() int {
+	if r.MaxHostKB > 0 {
+		return r.MaxHostKB * 1024
+	}
+	return defaults.DefaultRawMaxHostKB * 1024
+}
+
+// IncludesPhase reports whether the responses of a collector phase are
+// attached.
+func (r RawOutputConfig) IncludesPhase(phase string) bool {
+	if len(r.Phases) == 0 {
+		return true
+	}
+	for _, p := range r.Phases {
+		if strings.EqualFold(p, phase) {
+			return true
+		}
+	}
+	return false
+}
+
+// Output sink types.
+const (
+	SinkStdout = "stdout"
+	SinkFile   = "file"
+	SinkS3     = "s3"
+	SinkHTTP   = "http"
+)
+
+// SinkConfig is one output destination and the format written to it. In
+// path, url and the S3 key, {timestamp} expands to the UTC time of the run
+// (20060102T150405Z).
+type SinkConfig struct {
+	// Type is stdout, file, s3 or http.
+	Type string `yaml:"type"`
+
+	// Format is json (default), csv, table, console, markdown (the
+	// aggregated report) or junit (a JUnit XML report for CI pipelines).
+	Format string `yaml:"format"`
+
+	// Compress gzips the output.
+	Compress bool `yaml:"compress"`
+
+	// Path of the file (file).
+	Path string `yaml:"path"`
+
+	// URL the output is sent to with PUT, and extra request headers such as
+	// Authorization (http).
+	URL     string            `yaml:"url"`
+	Headers map[string]string `yaml:"headers"`
+
+	// S3 is the bucket the output is stored in (s3).
+	S3 S3Config `yaml:"s3"`
+
+	// TimeoutSeconds limits each upload (default: 60).
+	TimeoutSeconds int `yaml:"timeout_seconds"`
+}
+
+// GetFormat returns the output format of the sink.
+func (s SinkConfig) GetFormat() string {
+	return getStringOrDefault(strings.ToLower(s.Format), "json")
+}
+
+// Timeout returns the timeout of each upload.
+func (s SinkConfig) Timeout() time.Duration {
+	return secondsToDuration(s.TimeoutSeconds, defaults.DefaultSinkTimeout)
+}
+
+// S3Config is an S3 bucket on AWS or an S3-compatible store such as MinIO.
+// Objects are stored as <prefix>inventory-{timestamp}.<ext>, so each run
+// is archived under its own key.
+type S3Config struct {
+	// Endpoint is the URL of the store (default:
+	// https://s3.<region>.amazonaws.com), e.g. https://minio.example.com:9000.
+	Endpoint string `yaml:"endpoint"`
+
+	// Region used for request signing (default: us-east-1).
+	Region string `yaml:"region"`
+
+	Bucket string `yaml:"bucket"`
+
+	// Prefix of the object keys, e.g. "inventory/".
+	Prefix string `yaml:"prefix"`
+
+	// AccessKey, SecretKey and SessionToken default to AWS_ACCESS_KEY_ID,
+	// AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN.
+	AccessKey    string `yaml:"access_key"`
+	SecretKey    string `yaml:"secret_key"`
+	SessionToken string `yaml:"session_token"`
+
+	// PathStyle addresses the bucket in the URL path instead of the host
+	// name, as MinIO requires by default.
+	PathStyle bool `yaml:"path_style"`
+}
+
+// GetRegion returns the signing region.
+func (s S3Config) GetRegion() string {
+	return getStringOrDefault(s.Region, defaults.DefaultS3Region)
+}
+
+// GetEndpoint returns the URL of the store.
+func (s S3Config) GetEndpoint() string {
+	return getStringOrDefault(strings.TrimSuffix(s.Endpoint, "/"), "https://s3."+s.GetRegion()+".amazonaws.com")
+}
+
+// Credentials returns the access key, secret key and session token.
+func (s S3Config) Credentials() (accessKey, secretKey, sessionToken string) {
+	return getStringOrDefault(s.AccessKey, os.Getenv(defaults.EnvAWSAccessKeyID)),
+		getStringOrDefault(s.SecretKey, os.Getenv(defaults.EnvAWSSecretAccessKey)),
+		getStringOrDefault(s.SessionToken, os.Getenv(defaults.EnvAWSSessionToken))
+}
+
+// WarehouseConfig exports the results of each run into a SQL database
+// (Postgres or MySQL) for analytics over the fleet. Missing tables are
+// created on the first export; rows are upserted by service tag and scan ID,
+// so exporting a run again replaces its rows.
+type WarehouseConfig struct {
+	// Driver is postgres or mysql; empty disables the export.
+	Driver string `yaml:"driver"`
+
+	// DSN is the connection string of the driver (default:
+	// IDRAC_WAREHOUSE_DSN), e.g. postgres://user:pass@db/inventory or
+	// user:pass@tcp(db:3306)/inventory.
+	DSN string `yaml:"dsn"`
+
+	// Schema qualifies the table names: a Postgres schema (created if
+	// missing) or a MySQL database. Default: the one of the connection.
+	Schema string `yaml:"schema"`
+
+	// TablePrefix is prepended to the table names (scans, servers, cpus,
+	// dimms, drives), e.g. "idrac_".
+	TablePrefix string `yaml:"table_prefix"`
+
+	// TimeoutSeconds limits each export (default: 120).
+	TimeoutSeconds int `yaml:"timeout_seconds"`
+}
+
+// Warehouse drivers.
+const (
+	WarehousePostgres = "postgres"
+	WarehouseMySQL    = "mysql"
+)
+
+// IsEnabled returns true if a driver is configured.
+func (w WarehouseConfig) IsEnabled() bool {
+	return w.Driver != ""
+}
+
+// GetDSN returns the connection string.
+func (w WarehouseConfig) GetDSN() string {
+	return getStringOrDefault(w.DSN, os.Getenv(defaults.EnvWarehouseDSN))
+}
+
+// Password returns the password of the connection string, for redaction:
+// the user info of a URL (postgres://user:pass@db/inventory) or of the
+// MySQL form (user:pass@tcp(db:3306)/inventory). The password=... of the
+// key/value form is redacted by pattern.
+func (w WarehouseConfig) Password() string {
+	dsn := w.GetDSN()
+	if u, err := url.Parse(dsn); err == nil && u.Scheme != "" && u.User != nil {
+		pass, _ := u.User.Password()
+		return pass
+	}
+	at := strings.LastIndex(dsn, "@")
+	if at < 0 {
+		return ""
+	}
+	_, pass, _ := strings.Cut(dsn[:at], ":")
+	return pass
+}
+
+// Timeout returns the timeout of each export.
+func (w WarehouseConfig) Timeout() time.Duration {
+	return secondsToDuration(w.TimeoutSeconds, defaults.DefaultWarehouseTimeout)
+}
+
+// UnitsConfig selects how memory and storage sizes are shown in console,
+// table and Markdown output and written to NetBox. JSON and CSV output keep
+// the binary values of the scan.
+type UnitsConfig struct {
+	// System is binary (GiB, TiB; default) or decimal (GB, TB).
+	System string `yaml:"system"`
+
+	// Precision is the number of decimals of terabyte values (default: 2).
+	Precision *int `yaml:"precision,omitempty"`
+}
+
+// StatsConfig configures the run statistics.
+type StatsConfig struct {
+	// Percentiles of the per-host scan duration shown in the summary
+	// (default: 50, 90, 99).
+	Percentiles []float64 `yaml:"percentiles"`
+}
+
+// GetPercentiles returns the scan duration percentiles.
+func (s StatsConfig) GetPercentiles() []float64 {
+	if len(s.Percentiles) == 0 {
+		return models.DefaultPercentiles
+	}
+	return s.Percentiles
+}
+
+// WarrantyConfig configures the lookup of ship dates with the Dell warranty
+// API (TechDirect asset entitlements). The ship date is the manufacture
+// date of servers whose iDRAC does not report one, for their age.
+type WarrantyConfig struct {
+	Enabled bool `yaml:"enabled"`
+
+	// Client credentials of the API key (default: IDRAC_WARRANTY_CLIENT_ID
+	// and IDRAC_WARRANTY_CLIENT_SECRET).
+	ClientID     string `yaml:"client_id"`
+	ClientSecret string `yaml:"client_secret"`
+
+	// URL of the asset entitlements endpoint and the token endpoint
+	// (default: the Dell API gateway).
+	URL      string `yaml:"url"`
+	TokenURL string `yaml:"token_url"`
+
+	// TimeoutSeconds limits each request (default: 30).
+	TimeoutSeconds int `yaml:"timeout_seconds"`
+}
+
+// GetClientID returns the client ID of the API key.
+func (w WarrantyConfig) GetClientID() string {
+	return getStringOrDefault(w.ClientID, os.Getenv(defaults.EnvWarrantyClientID))
+}
+
+// GetClientSecret returns the client secret of the API key.
+func (w WarrantyConfig) GetClientSecret() string {
+	return getStringOrDefault(w.ClientSecret, os.Getenv(defaults.EnvWarrantyClientSecret))
+}
+
+// GetURL returns the URL of the asset entitlements endpoint.
+func (w WarrantyConfig) GetURL() string {
+	return getStringOrDefault(w.URL, defaults.DefaultWarrantyURL)
+}
+
+// GetTokenURL returns the URL of the token endpoint.
+func (w WarrantyConfig) GetTokenURL() string {
+	return getStringOrDefault(w.TokenURL, defaults.DefaultWarrantyTokenURL)
+}
+
+// Timeout returns the timeout of each request.
+func (w WarrantyConfig) Timeout() time.Duration {
+	return secondsToDuration(w.TimeoutSeconds, defaults.DefaultWarrantyTimeout)
+}
+
+// NotifyConfig configures the notifications sent after each run.
+type NotifyConfig struct {
+	Slack SlackConfig `yaml:"slack"`
+}
+
+// SlackConfig configures the run summary posted to a Slack incoming
+// webhook: totals, hosts that failed since the last run and the largest
+// hardware changes, with a link to the full report.
+type SlackConfig struct {
+	Enabled bool `yaml:"enabled"`
+
+	// WebhookURL is the incoming webhook (default: IDRAC_SLACK_WEBHOOK_URL).
+	WebhookURL string `yaml:"webhook_url"`
+
+	// Channel overrides the channel of the webhook, for webhooks that allow it.
+	Channel string `yaml:"channel"`
+
+	// ReportURL links the full report of the run, e.g. a CI artifact or an
+	// S3 sink object; {run_id} is replaced with the run ID.
+	ReportURL string `yaml:"report_url"`
+
+	// MaxItems limits the failure and drift lines of the summary (default: 5).
+	MaxItems int `yaml:"max_items"`
+
+	// OnlyChanges posts only if hosts failed that did not fail in the last
+	// run, or hardware changed.
+	OnlyChanges bool `yaml:"only_changes"`
+
+	// TimeoutSeconds limits the webhook request (default: 15).
+	TimeoutSeconds int `yaml:"timeout_seconds"`
+}
+
+// GetWebhookURL returns the URL of the incoming webhook.
+func (s SlackConfig) GetWebhookURL() string {
+	return getStringOrDefault(s.WebhookURL, os.Getenv(defaults.EnvSlackWebhookURL))
+}
+
+// GetMaxItems returns the number of failure and drift lines.
+func (s SlackConfig) GetMaxItems() int {
+	if s.MaxItems > 0 {
+		return s.MaxItems
+	}
+	return defaults.DefaultSlackMaxItems
+}
+
+// Timeout returns the timeout of the webhook request.
+func (s SlackConfig) Timeout() time.Duration {
+	return secondsToDuration(s.TimeoutSeconds, defaults.DefaultSlackTimeout)
+}
+
+// Format returns the configured unit format. An invalid system falls back
+// to binary; Validate reports it.
+func (u UnitsConfig) Format() units.Format {
+	f := units.Default()
+	if system, err := units.ParseSystem(u.System); err == nil {
+		f.System = system
+	}
+	if u.Precision != nil {
+		f.Precision = *u.Precision
+	}
+	return f
+}
+
+// CollectorsConfig configures custom collectors (see scanner.Collector).
+type CollectorsConfig struct {
+	// Plugins are Go plugins (.so, built with -buildmode=plugin) that are
+	// loaded at startup and register a collector each.
+	Plugins []string `yaml:"plugins"`
+
+	// Disabled lists registered collectors that are not run.
+	Disabled []string `yaml:"disabled"`
+}
+
+// AggregationConfig controls how servers are grouped in aggregated reports.
+type AggregationConfig struct {
+	Fingerprint FingerprintConfig `yaml:"fingerprint"`
+}
+
+// FingerprintConfig customises the hardware fingerprint used to split servers
+// of the same model into configuration groups.
+type FingerprintConfig struct {
+	// Ignore lists attributes that should not create a new config group.
+	// Valid values: storage, gpu, ram_speed, ram_module_size, ram_slots, cpu_speed.
+	Ignore []string `yaml:"ignore,omitempty"`
+
+	// RAMBucketsGiB groups total RAM into ranges with these ascending boundaries.
+	RAMBucketsGiB []int `yaml:"ram_buckets_gib,omitempty"`
+
+	// StorageBucketsTB groups total storage into ranges with these ascending boundaries.
+	StorageBucketsTB []float64 `yaml:"storage_buckets_tb,omitempty"`
+}
+
+// GitLabConfig holds configuration for exporting inventory reports to a local
+// git repository that is connected to a GitLab instance.
+type GitLabConfig struct {
+	// RepoPath is the absolute path to the local git repository.
+	RepoPath string `yaml:"repo_path"`
+
+	// Branch is the git branch to commit to (default: "main").
+	Branch string `yaml:"branch"`
+
+	// InventoryDir is the sub-directory within the repo where files are written
+	// (default: "inventory").
+	InventoryDir string `yaml:"inventory_dir"`
+
+	// AuthorName is used for the git commit author (default: "iDRAC Inventory Bot").
+	AuthorName string `yaml:"author_name"`
+
+	// AuthorEmail is used for the git commit author (default: "idrac-inventory@localhost").
+	AuthorEmail string `yaml:"author_email"`
+
+	// Push controls whether to push to the remote after committing.
+	Push bool `yaml:"push"`
+
+	// Heartbeat commits a timestamped report even if the inventory is
+	// unchanged since the last commit (default: skip the commit and push).
+	Heartbeat bool `yaml:"heartbeat"`
+}
+
+// IsEnabled returns true if GitLab export is configured.
+func (g GitLabConfig) IsEnabled() bool {
+	return g.RepoPath != ""
+}
+
+// ServerGroup holds configuration for a group of servers with IP ranges.
+// This allows specifying different credentials for different IP ranges.
+type ServerGroup struct {
+	Name               string   `yaml:"name,omitempty"`
+	IPRanges           []string `yaml:"ip_ranges"`
+	Username           string   `yaml:"username,omitempty"`
+	Password           string   `yaml:"password,omitempty"`
+	InsecureSkipVerify *bool    `yaml:"insecure_skip_verify,omitempty"`
+	TimeoutSeconds     *int     `yaml:"timeout_seconds,omitempty"`
+	ClientCert         string   `yaml:"client_cert,omitempty"`
+	ClientKey          string   `yaml:"client_key,omitempty"`
+
+	// Labels are passed on to the servers of the group (see ServerConfig.Labels).
+	Labels map[string]string `yaml:"labels,omitempty"`
+}
+
+// NetBoxConfig holds NetBox API configuration.
+type NetBoxConfig struct {
+	URL                string `yaml:"url"`
+	Token              string `yaml:"token"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify"`
+	TimeoutSeconds     int    `yaml:"timeout_seconds"`
+	CACert             string `yaml:"ca_cert"`
+
+	// TokenCommand is a shell command printing the API token, used instead
+	// of Token, e.g. a secrets platform CLI issuing short-lived tokens. It
+	// runs again when NetBox rejects the token (401 or 403).
+	TokenCommand string `yaml:"token_command"`
+
+	// SyncModules creates module bays and modules for OCP mezzanine and
+	// riser cards instead of only flattening them into custom fields.
+	SyncModules bool `yaml:"sync_modules"`
+
+	// SyncDeviceTypes fills missing airflow and weight of the
+	// devices' types from the model catalog.
+	SyncDeviceTypes bool `yaml:"sync_device_types"`
+
+	// Journal adds a journal entry to each synced device summarizing the
+	// scan, changed hardware fields and components that are not healthy.
+	Journal bool `yaml:"journal"`
+
+	// SyncComments writes the notes of the annotations file to a managed
+	// section of the device comments, keeping the rest of the comments.
+	SyncComments bool `yaml:"sync_comments"`
+
+	// SyncManufactureDate writes the manufacture date of the servers to the
+	// hw_manufacture_date custom field (a date field), for procurement planning.
+	SyncManufactureDate bool `yaml:"sync_manufacture_date"`
+
+	// SyncFirmwareCompliance writes the result of the firmware policy check
+	// to the hw_firmware_compliant custom field (a boolean field).
+	SyncFirmwareCompliance bool `yaml:"sync_firmware_compliance"`
+
+	// SyncIDRACLicense writes the iDRAC license level to the
+	// hw_idrac_license custom field (a text field).
+	SyncIDRACLicense bool `yaml:"sync_idrac_license"`
+
+	// SyncLastScanID writes the run ID to the hw_last_scan_id custom field
+	// (a text field), to find the logs and outputs of the last sync.
+	SyncLastScanID bool `yaml:"sync_last_scan_id"`
+
+	// SyncIDRACDNSName writes the DNS name of the iDRAC to the
+	// hw_idrac_dns_name custom field (a text field).
+	SyncIDRACDNSName bool `yaml:"sync_idrac_dns_name"`
+
+	// SyncLabelTags tags the devices with the labels of their servers, as
+	// "key:value" tags. The other tags of a device are kept.
+	SyncLabelTags bool `yaml:"sync_label_tags"`
+
+	// PowerDraw writes measured power draw to power ports or power feeds.
+	PowerDraw PowerDrawConfig `yaml:"power_draw"`
+
+	// MaxChanges aborts a sync that would update more than this many
+	// devices (hardware fields, placement, comments, tags, device type or
+	// power draw), unless -force is given (0: no limit).
+	MaxChanges int `yaml:"max_changes"`
+
+	// MaxCreates aborts a sync that would create more than this many
+	// objects (module bays, modules, ...), unless -force is given (0: no limit).
+	MaxCreates int `yaml:"max_creates"`
+
+	// Auth selects how the client authenticates, for NetBox instances
+	// behind an SSO proxy (default: the static token).
+	Auth NetBoxAuthConfig `yaml:"auth"`
+
+	// Targets selects NetBox devices as scan targets, in addition to the
+	// servers of the config file.
+	Targets NetBoxTargetsConfig `yaml:"targets"`
+
+	// Lock takes an advisory lock in NetBox for the duration of a sync, so
+	// that concurrent runs do not interleave their writes.
+	Lock NetBoxLockConfig `yaml:"lock"`
+
+	// DeviceCache caches the device IDs found by asset tag and serial
+	// between runs, so syncs skip the device searches.
+	DeviceCache NetBoxDeviceCacheConfig `yaml:"device_cache"`
+
+	// Match selects how servers are matched to NetBox devices.
+	Match NetBoxMatchConfig `yaml:"match"`
+
+	// GraphQL looks devices up with the GraphQL API instead of REST
+	// searches. Writes always use REST.
+	GraphQL NetBoxGraphQLConfig `yaml:"graphql"`
+}
+
+// NetBoxLockConfig configures the advisory sync lock. The lock is a tag
+// whose description holds the holder and expiry of the lease; creating it
+// is atomic because tag slugs are unique. A lease that expired (e.g. of a
+// run that was killed) is taken over.
+type NetBoxLockConfig struct {
+	Enabled bool `yaml:"enabled"`
+
+	// Name of the lock tag (default: idrac-inventory-sync-lock). Runs with
+	// the same name exclude each other.
+	Name string `yaml:"name"`
+
+	// TTL of the lease as a Go duration (default: 1h). The holder renews the
+	// lease every third of the TTL while it syncs, so the TTL only bounds
+	// how long the lock of a killed run blocks others.
+	TTL string `yaml:"ttl"`
+
+	// Wait is how long a run waits for a held lock before it fails, as a
+	// Go duration (default: fail immediately).
+	Wait string `yaml:"wait"`
+
+	// Identity of this run (default: hostname and process ID).
+	Identity string `yaml:"identity"`
+}
+
+// GetName returns the name of the lock tag.
+func (l NetBoxLockConfig) GetName() string {
+	return getStringOrDefault(l.Name, defaults.DefaultNetBoxLockName)
+}
+
+// GetTTL returns the lease TTL.
+func (l NetBoxLockConfig) GetTTL() time.Duration {
+	if ttl, err := time.ParseDuration(l.TTL); err == nil && ttl > 0 {
+		return ttl
+	}
+	return defaults.DefaultNetBoxLockTTL
+}
+
+// GetWait returns how long to wait for a held lock.
+func (l NetBoxLockConfig) GetWait() time.Duration {
+	if wait, err := time.ParseDuration(l.Wait); err == nil && wait > 0 {
+		return wait
+	}
+	return 0
+}
+
+// GetIdentity returns the identity of this run.
+func (l NetBoxLockConfig) GetIdentity() string {
+	if l.Identity != "" {
+		return l.Identity
+	}
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s/%d", host, os.Getpid())
+}
+
+// NetBoxDeviceCacheConfig configures the device ID cache. Entries are kept
+// in <cache_dir>/netbox-devices.json; a cached device is fetched by ID, and
+// one that was deleted (404) or lost its asset tag or serial is searched again.
+type NetBoxDeviceCacheConfig struct {
+	Enabled bool `yaml:"enabled"`
+
+	// TTL of a cached ID as a Go duration (default: 24h).
+	TTL string `yaml:"ttl"`
+
+	// Prewarm lists the devices once per run, fetching the pages in
+	// parallel, instead of fetching each device.
+	Prewarm bool `yaml:"prewarm"`
+
+	// PrewarmFilter limits the prewarm listing with NetBox device filters
+	// as a query string, e.g. "site=dc1&manufacturer=dell".
+	PrewarmFilter string `yaml:"prewarm_filter"`
+}
+
+// GetTTL returns the TTL of a cached device ID.
+func (d NetBoxDeviceCacheConfig) GetTTL() time.Duration {
+	if ttl, err := time.ParseDuration(d.TTL); err == nil && ttl > 0 {
+		return ttl
+	}
+	return defaults.DefaultNetBoxDeviceCacheTTL
+}
+
+// PrewarmQuery returns the filters of the prewarm listing.
+func (d NetBoxDeviceCacheConfig) PrewarmQuery() (url.Values, error) {
+	return url.ParseQuery(d.PrewarmFilter)
+}
+
+// NetBox device match strategies.
+const (
+	NetBoxMatchSearch = "search" // search each device by asset tag and serial
+	NetBoxMatchBulk   = "bulk"   // list the devices once and match locally
+)
+
+// NetBoxMatchConfig configures how servers are matched to NetBox devices.
+// The bulk strategy turns a search per server into a few list requests,
+// which pays off for large fleets; servers whose device is not in the
+// listing are reported as not found.
+type NetBoxMatchConfig struct {
+	// Strategy is search (default) or bulk.
+	Strategy string `yaml:"strategy"`
+
+	// Filter limits the bulk listing with NetBox device filters as a query
+	// string (default: manufacturer=dell).
+	Filter string `yaml:"filter"`
+}
+
+// GetStrategy returns the match strategy.
+func (m NetBoxMatchConfig) GetStrategy() string {
+	return getStringOrDefault(strings.ToLower(m.Strategy), NetBoxMatchSearch)
+}
+
+// Query returns the filters of the bulk listing.
+func (m NetBoxMatchConfig) Query() (url.Values, error) {
+	return url.ParseQuery(getStringOrDefault(m.Filter, defaults.DefaultNetBoxMatchFilter))
+}
+
+// NetBoxGraphQLConfig configures device lookups with the NetBox GraphQL API
+// (NetBox 4.3 or later). A lookup queries only the device fields the sync
+// needs, and the lookups of a sync are batched into a few queries.
+type NetBoxGraphQLConfig struct {
+	Enabled bool `yaml:"enabled"`
+
+	// BatchSize is the number of asset tag and serial lookups per query
+	// (default: 60). Lower it if NetBox rejects the long query URLs.
+	BatchSize int `yaml:"batch_size"`
+}
+
+// GetBatchSize returns the number of lookups per query.
+func (g NetBoxGraphQLConfig) GetBatchSize() int {
+	if g.BatchSize > 0 {
+		return g.BatchSize
+	}
+	return defaults.DefaultNetBoxGraphQLBatchSize
+}
+
+// NetBox target address sources; any other value names a custom field.
+const (
+	TargetAddressOOBIP      = "oob_ip"
+	TargetAddressPrimaryIP  = "primary_ip"
+	TargetAddressPrimaryIP4 = "primary_ip4"
+)
+
+// NetBoxTargetsConfig fetches the servers to scan from NetBox: the devices
+// matching Filter with an iDRAC address. They are fetched when a run starts
+// and added to the configured servers, except hosts that are configured
+// already. Disabled if Filter is empty.
+type NetBoxTargetsConfig struct {
+	// Filter is a NetBox device query, e.g. "role=server&tag=idrac&site=dc1".
+	Filter string `yaml:"filter"`
+
+	// Address is where the iDRAC address is read from: oob_ip (default),
+	// primary_ip4, primary_ip or the name of a custom field.
+	Address string `yaml:"address"`
+
+	// Username and Password of the iDRACs (default: the defaults section).
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+}
+
+// IsEnabled returns true if a device filter is configured.
+func (t NetBoxTargetsConfig) IsEnabled() bool {
+	return t.Filter != ""
+}
+
+// Query returns the device filter as query parameters.
+func (t NetBoxTargetsConfig) Query() (url.Values, error) {
+	return url.ParseQuery(t.Filter)
+}
+
+// GetAddress returns the source of the iDRAC address.
+func (t NetBoxTargetsConfig) GetAddress() string {
+	return getStringOrDefault(t.Address, TargetAddressOOBIP)
+}
+
+// Server returns the server of a NetBox device whose iDRAC is at host.
+func (t NetBoxTargetsConfig) Server(host, name string) ServerConfig {
+	return ServerConfig{Host: host, Name: name, Username: t.Username, Password: t.Password}
+}
+
+// NetBox authentication methods.
+const (
+	NetBoxAuthToken  = "token"  // static API token in the Authorization header
+	NetBoxAuthOAuth2 = "oauth2" // access token of an OAuth2 client credentials grant
+	NetBoxAuthMTLS   = "mtls"   // TLS client certificate only
+)
+
+// NetBoxAuthConfig configures the authentication against NetBox.
+type NetBoxAuthConfig struct {
+	// Method is token (default), oauth2 or mtls.
+	Method string `yaml:"method"`
+
+	// OAuth2 configures the client credentials grant of the oauth2 method.
+	OAuth2 OAuth2Config `yaml:"oauth2"`
+
+	// ClientCert and ClientKey are the PEM files of a TLS client
+	// certificate. Required for mtls, presented with any method if set.
+	ClientCert string `yaml:"client_cert"`
+	ClientKey  string `yaml:"client_key"`
+}
+
+// OAuth2 client authentication styles at the token endpoint.
+const (
+	OAuth2AuthStyleHeader = "header" // HTTP Basic (client_secret_basic)
+	OAuth2AuthStyleParams = "params" // form parameters (client_secret_post)
+)
+
+// OAuth2Config configures an OAuth2 client credentials grant (service
+// principal). The access token is cached and fetched again shortly before
+// it expires or when NetBox (or the proxy) rejects it.
+type OAuth2Config struct {
+	TokenURL     string   `yaml:"token_url"`
+	ClientID     string   `yaml:"client_id"`
+	ClientSecret string   `yaml:"client_secret"`
+	Scopes       []string `yaml:"scopes"`
+	Audience     string   `yaml:"audience"` // audience parameter, for IdPs that require it
+
+	// AuthStyle is header (default) or params.
+	AuthStyle string `yaml:"auth_style"`
+
+	// Header carries the access token as "Bearer <token>" (default:
+	// Authorization). With another header, the static token, if set, is
+	// still sent in the Authorization header for NetBox itself.
+	Header string `yaml:"header"`
+}
+
+// GetAuthStyle returns the client authentication style.
+func (o OAuth2Config) GetAuthStyle() string {
+	return getStringOrDefault(strings.ToLower(o.AuthStyle), OAuth2AuthStyleHeader)
+}
+
+// GetHeader returns the header carrying the access token.
+func (o OAuth2Config) GetHeader() string {
+	return getStringOrDefault(o.Header, "Authorization")
+}
+
+// GetMethod returns the authentication method.
+func (a NetBoxAuthConfig) GetMethod() string {
+	return getStringOrDefault(strings.ToLower(a.Method), NetBoxAuthToken)
+}
+
+// Power draw modes.
+const (
+	PowerDrawAllocated = "allocated_draw" // power-port allocated_draw of the device
+	PowerDrawFeedField = "feed_field"     // custom field on the connected power feeds
+)
+
+// PowerDrawConfig controls writing measured power draw into NetBox for PDU
+// capacity planning. Disabled if Mode is empty.
+type PowerDrawConfig struct {
+	// Mode is allocated_draw or feed_field.
+	Mode string `yaml:"mode"`
+
+	// FeedField is the power feed custom field in feed_field mode
+	// (default: "measured_draw_watts").
+	FeedField string `yaml:"feed_field"`
+
+	// UsePeak writes the peak instead of the current draw, if reported.
+	UsePeak bool `yaml:"use_peak"`
+}
+
+// GetFeedField returns the power feed custom field name.
+func (p PowerDrawConfig) GetFeedField() string {
+	return getStringOrDefault(p.FeedField, defaults.DefaultPowerFeedField)
+}
+
+// HasLimits returns true if max_changes or max_creates is set.
+func (n NetBoxConfig) HasLimits() bool {
+	return n.MaxChanges > 0 || n.MaxCreates > 0
+}
+
+// IsEnabled returns true if NetBox integration is configured.
+func (n NetBoxConfig) IsEnabled() bool {
+	if n.Auth.GetMethod() != NetBoxAuthToken {
+		return n.URL != ""
+	}
+	return n.URL != "" && (n.Token != "" || n.TokenCommand != "")
+}
+
+// Timeout returns the configured timeout as a Duration.
+func (n NetBoxConfig) Timeout() time.Duration {
+	return secondsToDuration(n.TimeoutSeconds, defaults.GetNetBoxTimeout())
+}
+
+// ServerConfig holds configuration for a single iDRAC server.
+type ServerConfig struct {
+	Host               string `yaml:"host"`
+	Username           string `yaml:"username,omitempty"`
+	Password           string `yaml:"password,omitempty"`
+	Name               string `yaml:"name,omitempty"`
+	InsecureSkipVerify *bool  `yaml:"insecure_skip_verify,omitempty"`
+	TimeoutSeconds     *int   `yaml:"timeout_seconds,omitempty"`
+
+	// ClientCert and ClientKey are the PEM files of a TLS client
+	// certificate for iDRACs with certificate-based login. If set, no
+	// username and password are sent.
+	ClientCert string `yaml:"client_cert,omitempty"`
+	ClientKey  string `yaml:"client_key,omitempty"`
+
+	// Labels partition the servers for downstream systems, e.g.
+	// datacenter: fra1 or environment: prod. They are copied to the results
+	// and, if enabled, to NetBox tags. Keys are Prometheus label names.
+	Labels map[string]string `yaml:"labels,omitempty"`
+}
+
+// UsesClientCert returns true if the server authenticates with a client
+// certificate instead of username and password.
+func (s ServerConfig) UsesClientCert() bool {
+	return s.ClientCert != ""
+}
+
+// GetUsername returns the username, falling back to the provided default.
+func (s ServerConfig) GetUsername(defaultUser string) string {
+	return getStringOrDefault(s.Username, defaultUser)
+}
+
+// GetPassword returns the password, falling back to the provided default.
+func (s ServerConfig) GetPassword(defaultPass string) string {
+	return getStringOrDefault(s.Password, defaultPass)
+}
+
+// GetDisplayName returns a human-readable name for this server.
+func (s ServerConfig) GetDisplayName() string {
+	return getStringOrDefault(s.Name, s.Host)
+}
+
+// GetInsecureSkipVerify returns the TLS verification setting for this server.
+func (s ServerConfig) GetInsecureSkipVerify(defaultValue bool) bool {
+	return getBoolPtrOrDefault(s.InsecureSkipVerify, defaultValue)
+}
+
+// GetTimeout returns the timeout for this server.
+func (s ServerConfig) GetTimeout(defaultTimeout time.Duration) time.Duration {
+	return secondsPtrToDuration(s.TimeoutSeconds, defaultTimeout)
+}
+
+// DefaultsConfig holds default values for server connections.
+type DefaultsConfig struct {
+	Username           string `yaml:"username"`
+	Password           string `yaml:"password"`
+	TimeoutSeconds     int    `yaml:"timeout_seconds"`
+	InsecureSkipVerify *bool  `yaml:"insecure_skip_verify,omitempty"`
+}
+
+// Timeout returns the configured timeout as a Duration.
+func (d DefaultsConfig) Timeout() time.Duration {
+	return secondsToDuration(d.TimeoutSeconds, defaults.GetTimeout())
+}
+
+// GetInsecureSkipVerify returns the TLS verification setting.
+func (d DefaultsConfig) GetInsecureSkipVerify() bool {
+	return getBoolPtrOrDefault(d.InsecureSkipVerify, defaults.DefaultInsecureSkipVerify)
+}
+
+// LoggingConfig holds logging configuration.
+type LoggingConfig struct {
+	Level  string `yaml:"level"`  // debug, info, warn, error
+	Format string `yaml:"format"` // json, console
+
+	// DebugSampling limits repeated debug messages (e.g. one line per
+	// Redfish request) so debug logs of large scans stay readable.
+	DebugSampling LogSamplingConfig `yaml:"debug_sampling"`
+
+	// InfoSampling limits repeated info messages (e.g. the component
+	// summaries of each host) on scans of thousands of hosts.
+	InfoSampling LogSamplingConfig `yaml:"info_sampling"`
+
+	// File writes logs to rotating files instead of stderr, e.g. when
+	// running as a service.
+	File LogFileConfig `yaml:"file"`
+
+	// Stdout writes logs to stdout as well (instead of stderr if no other
+	// target is configured).
+	Stdout bool `yaml:"stdout"`
+
+	// Syslog sends logs to a syslog server or the local syslog socket.
+	Syslog LogSyslogConfig `yaml:"syslog"`
+
+	// Journald sends logs to the systemd journal with structured fields.
+	Journald LogJournaldConfig `yaml:"journald"`
+}
+
+// LogSyslogConfig configures the RFC 5424 syslog target.
+type LogSyslogConfig struct {
+	Network  string `yaml:"network"`  // udp, tcp or unix (empty disables syslog)
+	Address  string `yaml:"address"`  // host:port, or socket path for unix (default: /dev/log)
+	Facility string `yaml:"facility"` // e.g. daemon, local0 (default: daemon)
+	Tag      string `yaml:"tag"`      // APP-NAME (default: idrac-inventory)
+}
+
+// IsEnabled returns true if a syslog target is configured.
+func (l LogSyslogConfig) IsEnabled() bool {
+	return l.Network != ""
+}
+
+// LogJournaldConfig configures the systemd journal target.
+type LogJournaldConfig struct {
+	Enabled    bool   `yaml:"enabled"`
+	Identifier string `yaml:"identifier"` // SYSLOG_IDENTIFIER (default: idrac-inventory)
+}
+
+// LogFileConfig configures log files and their rotation.
+type LogFileConfig struct {
+	Path      string `yaml:"path"`       // all entries at the configured level
+	ErrorPath string `yaml:"error_path"` // errors only, in addition to path
+
+	MaxSizeMB   int    `yaml:"max_size_mb"`  // rotate before the file exceeds this size
+	RotateEvery string `yaml:"rotate_every"` // rotate after this Go duration (e.g. "24h")
+	MaxBackups  int    `yaml:"max_backups"`  // rotated files to keep (0 = all)
+	MaxAgeDays  int    `yaml:"max_age_days"` // delete rotated files older than this (0 = never)
+}
+
+// IsEnabled returns true if a log file or error log file is configured.
+func (l LogFileConfig) IsEnabled() bool {
+	return l.Path != "" || l.ErrorPath != ""
+}
+
+// GetRotateEvery returns the time-based rotation interval (0 if disabled).
+func (l LogFileConfig) GetRotateEvery() time.Duration {
+	d, err := time.ParseDuration(l.RotateEvery)
+	if err != nil {
+		return 0
+	}
+	return d
+}
+
+// LogSamplingConfig configures log sampling. Per message and second,
+// the first Initial entries are logged, then every Thereafter-th one.
+type LogSamplingConfig struct {
+	Initial    int `yaml:"initial"`
+	Thereafter int `yaml:"thereafter"`
+}
+
+// IsEnabled returns true if sampling is configured.
+func (l LogSamplingConfig) IsEnabled() bool {
+	return l.Initial > 0 || l.Thereafter > 0
+}
+
+// RetryConfig holds retry configuration. It applies to requests that an
+// iDRAC answers with 503 Service Unavailable while it resets or is in
+// maintenance: the request is repeated up to MaxAttempts times, waiting for
+// the Retry-After header (or exponentially from BaseDelay), as long as the
+// wait does not exceed MaxDelay.
+type RetryConfig struct {
+	MaxAttempts int    `yaml:"max_attempts"`
+	BaseDelay   string `yaml:"base_delay"`
+	MaxDelay    string `yaml:"max_delay"`
+
+	// DeferBusy holds back hosts that are still busy after the retries and
+	// scans them again at the end of the run, even if rescan is disabled.
+	DeferBusy bool `yaml:"defer_busy"`
+}
+
+// GetMaxAttempts returns the max retry attempts.
+func (r RetryConfig) GetMaxAttempts() int {
+	return getIntOrDefault(r.MaxAttempts, defaults.DefaultRetryMaxAttempts)
+}
+
+// GetBaseDelay returns the base retry delay.
+func (r RetryConfig) GetBaseDelay() time.Duration {
+	if r.BaseDelay == "" {
+		return defaults.DefaultRetryBaseDelay
+	}
+	if d, err := time.ParseDuration(r.BaseDelay); err == nil {
+		return d
+	}
+	return defaults.DefaultRetryBaseDelay
+}
+
+// GetMaxDelay returns the max retry delay.
+func (r RetryConfig) GetMaxDelay() time.Duration {
+	if r.MaxDelay == "" {
+		return defaults.DefaultRetryMaxDelay
+	}
+	if d, err := time.ParseDuration(r.MaxDelay); err == nil {
+		return d
+	}
+	return defaults.DefaultRetryMaxDelay
+}
+
+// RescanConfig controls the second pass that retries failed hosts once at the
+// end of a scan, with a longer timeout and lower concurrency.
+type RescanConfig struct {
+	Enabled bool `yaml:"enabled"`
+
+	// TimeoutSeconds is the per-host timeout of the second pass
+	// (default: twice defaults.timeout_seconds).
+	TimeoutSeconds int `yaml:"timeout_seconds"`
+
+	// Concurrency is the number of parallel workers of the second pass (default: 2).
+	Concurrency int `yaml:"concurrency"`
+}
+
+// Timeout returns the second-pass timeout, derived from the regular timeout if unset.
+func (r RescanConfig) Timeout(base time.Duration) time.Duration {
+	return secondsToDuration(r.TimeoutSeconds, base*time.Duration(defaults.DefaultRescanTimeoutMultiplier))
+}
+
+// GetConcurrency returns the second-pass concurrency.
+func (r RescanConfig) GetConcurrency() int {
+	return getIntOrDefault(r.Concurrency, defaults.DefaultRescanConcurrency)
+}
+
+// HTTPConfig holds HTTP client configuration.
+type HTTPConfig struct {
+	MaxIdleConns       int `yaml:"max_idle_conns"`
+	IdleConnTimeoutSec int `yaml:"idle_conn_timeout_seconds"`
+
+	// ETagCache enables conditional requests (If-None-Match) for Redfish
+	// member resources, cached in <cache_dir>/redfish-etags.json. The cache
+	// keeps the 50000 most recently used responses.
+	ETagCache bool `yaml:"etag_cache"`
+
+	// StrictSchema validates iDRAC responses against the bundled Redfish
+	// schemas and logs each violation per host (also -strict-schema).
+	StrictSchema bool `yaml:"strict_schema"`
+
+	// UserAgent sent to iDRAC and NetBox; {version} expands to the tool version
+	// (default: "github.com/braunma/idrac-netbox-importer/{version}").
+	UserAgent string `yaml:"user_agent"`
+
+	// AcceptLanguage is sent to iDRACs (e.g. "en-US") to get English names
+	// and messages from iDRACs set to another locale.
+	AcceptLanguage string `yaml:"accept_language"`
+
+	// Headers are extra headers added to every iDRAC and NetBox request
+	// (e.g. X-Scan-Ticket).
+	Headers map[string]string `yaml:"headers"`
+}
+
+// GetMaxIdleConns returns max idle connections.
+func (h HTTPConfig) GetMaxIdleConns() int {
+	return getIntOrDefault(h.MaxIdleConns, defaults.DefaultHTTPMaxIdleConns)
+}
+
+// GetIdleConnTimeout returns idle connection timeout.
+func (h HTTPConfig) GetIdleConnTimeout() time.Duration {
+	return secondsToDuration(h.IdleConnTimeoutSec, defaults.GetHTTPIdleConnTimeout())
+}
+
+// GetUserAgent returns the User-Agent with {version} expanded.
+func (h HTTPConfig) GetUserAgent() string {
+	return strings.ReplaceAll(getStringOrDefault(h.UserAgent, defaults.DefaultUserAgent), "{version}", defaults.Version)
+}
+
+// ApplyHeaders sets the User-Agent and the extra headers on an outgoing request.
+func (h HTTPConfig) ApplyHeaders(header http.Header) {
+	header.Set("User-Agent", h.GetUserAgent())
+	for name, value := range h.Headers {
+		header.Set(name, value)
+	}
+}
+
+// Load reads and parses a configuration file from the given path.
+func Load(path string) (*Config, error) {
+	return LoadProfile(path, "")
+}
+
+// LoadProfile reads a configuration file and applies the named profile.
+// An empty profile uses the top-level settings only.
+func LoadProfile(path, profile string) (*Config, error) {
+	return LoadProfileTargets(path, profile, "")
+}
+
+// LoadProfileTargets is LoadProfile with a targets CSV file that overrides
+// the targets setting (-targets). An empty targets keeps the setting.
+func LoadProfileTargets(path, profile, targets string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	return parse(data, profile, targets, filepath.Dir(path))
+}
+
+// Parse parses configuration from YAML bytes.
+// Environment variable references in the form ${VAR} or $VAR are expanded
+// before parsing, so values like `username: "${IDRAC_DEFAULT_USER}"` work
+// as expected.
+func Parse(data []byte) (*Config, error) {
+	return ParseProfile(data, "")
+}
+
+// ParseProfile parses configuration from YAML bytes and applies the named profile
+// on top of the top-level settings. An empty profile uses the top-level settings only.
+func ParseProfile(data []byte, profile string) (*Config, error) {
+	return parse(data, profile, "", "")
+}
+
+// parse parses configuration from YAML bytes, applies the named profile and
+// overrides the targets setting if targets is not empty. A relative targets
+// setting is resolved against dir, the directory of the config file ("" for
+// the working directory).
+func parse(data []byte, profile, targets, dir string) (*Config, error) {
+	var cfg Config
+
+	// Expand ${VAR} / $VAR placeholders in the raw YAML before unmarshaling.
+	expanded := os.ExpandEnv(string(data))
+	if err := yaml.Unmarshal([]byte(expanded), &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+
+	if profile != "" {
+		if err := cfg.applyProfile(profile); err != nil {
+			return nil, err
+		}
+	}
+
+	// Expand server groups into individual servers
+	if err := cfg.expandServerGroups(); err != nil {
+		return nil, fmt.Errorf("failed to expand server groups: %w", err)
+	}
+
+	// Add the servers of the targets file
+	if targets != "" {
+		cfg.Targets = targets
+	} else if cfg.Targets != "" && !filepath.IsAbs(cfg.Targets) {
+		cfg.Targets = filepath.Join(dir, cfg.Targets)
+	}
+	if err := cfg.loadTargets(); err != nil {
+		return nil, fmt.Errorf("failed to load targets: %w", err)
+	}
+
+	// Apply environment variable overrides
+	cfg.applyEnvOverrides()
+
+	// Apply defaults
+	cfg.applyDefaults()
+
+	// Validate
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("config validation failed: %w", err)
+	}
+
+	return &cfg, nil
+}
+
+// ProfileNames returns the names of all configured profiles, sorted.
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// applyProfile decodes the named profile on top of the current configuration.
+func (c *Config) applyProfile(name string) error {
+	node, ok := c.Profiles[name]
+	if !ok {
+		available := "none configured"
+		if len(c.Profiles) > 0 {
+			available = strings.Join(c.ProfileNames(), ", ")
+		}
+		return fmt.Errorf("unknown profile %q (available: %s)", name, available)
+	}
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("profile %q must be a mapping", name)
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		switch node.Content[i].Value {
+		case "profiles":
+			return fmt.Errorf("profile %q: nested profiles are not supported", name)
+		case "servers", "server_groups", "targets":
+			// The profile owns the inventory; don't mix in top-level servers
+			c.Servers = nil
+			c.ServerGroups = nil
+			c.Targets = ""
+		}
+	}
+
+	if err := node.Decode(c); err != nil {
+		return fmt.Errorf("failed to parse profile %q: %w", name, err)
+	}
+	c.Profile = name
+
+	return nil
+}
+
+// expandServerGroups expands IP ranges from server_groups into individual servers.
+func (c *Config) expandServerGroups() error {
+	if len(c.ServerGroups) == 0 {
+		return nil
+	}
+
+	var expandedServers []ServerConfig
+
+	for i, group := range c.ServerGroups {
+		if len(group.IPRanges) == 0 {
+			return fmt.Errorf("server_group[%d]: no ip_ranges specified", i)
+		}
+
+		// Expand all IP ranges in this group
+		ips, err := ExpandIPRanges(group.IPRanges)
+		if err != nil {
+			groupName := group.Name
+			if groupName == "" {
+				groupName = fmt.Sprintf("group %d", i)
+			}
+			return fmt.Errorf("server_group %s: %w", groupName, err)
+		}
+
+		// Create a ServerConfig for each IP
+		for _, ip := range ips {
+			srv := ServerConfig{
+				Host:               ip,
+				Username:           group.Username,
+				Password:           group.Password,
+				InsecureSkipVerify: group.InsecureSkipVerify,
+				TimeoutSeconds:     group.TimeoutSeconds,
+				ClientCert:         group.ClientCert,
+				ClientKey:          group.ClientKey,
+				Labels:             MergeLabels(group.Labels, nil),
+			}
+
+			// Use group name + IP as the server name if group has a name
+			if group.Name != "" {
+				srv.Name = fmt.Sprintf("%s - %s", group.Name, ip)
+			}
+
+			expandedServers = append(expandedServers, srv)
+		}
+	}
+
+	// Append expanded servers to existing servers
+	c.Servers = append(c.Servers, expandedServers...)
+
+	return nil
+}
+
+// labelName matches the label keys accepted by Prometheus.
+var labelName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// MergeLabels returns the labels of a group overridden by those of one of
+// its servers, or nil if there are none.
+func MergeLabels(group, server map[string]string) map[string]string {
+	if len(group) == 0 && len(server) == 0 {
+		return nil
+	}
+	merged := make(map[string]string, len(group)+len(server))
+	for k, v := range group {
+		merged[k] = v
+	}
+	for k, v := range server {
+		merged[k] = v
+	}
+	return merged
+}
+
+// validateLabels adds an error per label key that is not a Prometheus label
+// name or is reserved (leading "__"), in key order.
+func validateLabels(path string, labels map[string]string, multiErr *errors.MultiError) {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if !labelName.MatchString(k) || strings.HasPrefix(k, "__") {
+			multiErr.Add(errors.NewConfigError(path,
+				fmt.Sprintf("invalid label name %q (letters, digits and underscores, not starting with a digit or \"__\")", k)))
+		}
+	}
+}
+
+// applyEnvOverrides applies environment variable overrides to the config.
+func (c *Config) applyEnvOverrides() {
+	// NetBox overrides
+	if url := os.Getenv(defaults.EnvNetBoxURL); url != "" {
+		c.NetBox.URL = url
+	}
+	if token := os.Getenv(defaults.EnvNetBoxToken); token != "" {
+		c.NetBox.Token = token
+	}
+	if id := os.Getenv(defaults.EnvNetBoxOAuth2ClientID); id != "" {
+		c.NetBox.Auth.OAuth2.ClientID = id
+	}
+	if secret := os.Getenv(defaults.EnvNetBoxOAuth2ClientSecret); secret != "" {
+		c.NetBox.Auth.OAuth2.ClientSecret = secret
+	}
+	if caCert := os.Getenv(defaults.EnvNetBoxCACert); caCert != "" {
+		c.NetBox.CACert = caCert
+	}
+	if insecureSkipVerify := os.Getenv(defaults.EnvNetBoxInsecureSkipVerify); insecureSkipVerify != "" {
+		if val, err := strconv.ParseBool(insecureSkipVerify); err == nil {
+			c.NetBox.InsecureSkipVerify = val
+		}
+	}
+
+	// Default credentials overrides
+	if user := os.Getenv(defaults.EnvDefaultUsername); user != "" {
+		c.Defaults.Username = user
+	}
+	if pass := os.Getenv(defaults.EnvDefaultPassword); pass != "" {
+		c.Defaults.Password = pass
+	}
+
+	// Logging overrides
+	if level := os.Getenv(defaults.EnvLogLevel); level != "" {
+		c.Logging.Level = level
+	}
+	if format := os.Getenv(defaults.EnvLogFormat); format != "" {
+		c.Logging.Format = format
+	}
+}
+
+// applyDefaults sets default values for unset fields.
+func (c *Config) applyDefaults() {
+	// Concurrency
+	if c.Concurrency <= 0 {
+		c.Concurrency = defaults.GetConcurrency()
+	}
+	if c.Concurrency > defaults.DefaultMaxConcurrency {
+		c.Concurrency = defaults.DefaultMaxConcurrency
+	}
+
+	// Timeouts
+	if c.Defaults.TimeoutSeconds <= 0 {
+		c.Defaults.TimeoutSeconds = defaults.DefaultTimeoutSeconds
+	}
+
+	// Logging
+	if c.Logging.Level == "" {
+		c.Logging.Level = defaults.DefaultLogLevel
+	}
+	if c.Logging.Format == "" {
+		c.Logging.Format = defaults.DefaultLogFormat
+	}
+
+	// NetBox
+	if c.NetBox.TimeoutSeconds <= 0 {
+		c.NetBox.TimeoutSeconds = defaults.DefaultNetBoxTimeoutSeconds
+	}
+}
+
+// Secrets returns the configured credentials (iDRAC passwords, NetBox token,
+// OAuth2 client secret, Slack webhook URL, S3 sink keys, warehouse password
+// and warranty API secret), for registering with redact.AddSecrets.
+func (c *Config) Secrets() []string {
+	secrets := []string{c.Defaults.Password, c.NetBox.Token, c.NetBox.Auth.OAuth2.ClientSecret, c.NetBox.Targets.Password, c.Notify.Slack.GetWebhookURL()}
+	for _, srv := range c.Servers {
+		secrets = append(secrets, srv.Password)
+	}
+	for _, g := range c.ServerGroups {
+		secrets = append(secrets, g.Password)
+	}
+	for _, sink := range c.Output.Sinks {
+		_, secretKey, sessionToken := sink.S3.Credentials()
+		secrets = append(secrets, secretKey, sessionToken)
+	}
+	secrets = append(secrets, c.Warehouse.Password(), c.Warranty.GetClientSecret())
+	return secrets
+}
+
+// validate adds the errors of the NetBox authentication settings.
+func (a NetBoxAuthConfig) validate(multiErr *errors.MultiError) {
+	switch a.GetMethod() {
+	case NetBoxAuthToken:
+	case NetBoxAuthOAuth2:
+		o := a.OAuth2
+		if o.TokenURL == "" {
+			multiErr.Add(errors.NewConfigError("netbox.auth.oauth2.token_url", "token_url is required for oauth2"))
+		} else if u, err := url.Parse(o.TokenURL); err != nil || u.Scheme == "" || u.Host == "" {
+			multiErr.Add(errors.NewConfigError("netbox.auth.oauth2.token_url",
+				fmt.Sprintf("invalid url %q", o.TokenURL)))
+		}
+		if o.ClientID == "" {
+			multiErr.Add(errors.NewConfigError("netbox.auth.oauth2.client_id",
+				fmt.Sprintf("client_id is required for oauth2 (or set %s)", defaults.EnvNetBoxOAuth2ClientID)))
+		}
+		if o.ClientSecret == "" {
+			multiErr.Add(errors.NewConfigError("netbox.auth.oauth2.client_secret",
+				fmt.Sprintf("client_secret is required for oauth2 (or set %s)", defaults.EnvNetBoxOAuth2ClientSecret)))
+		}
+		switch o.GetAuthStyle() {
+		case OAuth2AuthStyleHeader, OAuth2AuthStyleParams:
+		default:
+			multiErr.Add(errors.NewConfigError("netbox.auth.oauth2.auth_style",
+				fmt.Sprintf("invalid auth_style %q (must be header or params)", o.AuthStyle)))
+		}
+	case NetBoxAuthMTLS:
+		if a.ClientCert == "" {
+			multiErr.Add(errors.NewConfigError("netbox.auth.client_cert", "client_cert is required for mtls"))
+		}
+	default:
+		multiErr.Add(errors.NewConfigError("netbox.auth.method",
+			fmt.Sprintf("invalid method %q (must be token, oauth2 or mtls)", a.Method)))
+	}
+	if (a.ClientCert == "") != (a.ClientKey == "") {
+		multiErr.Add(errors.NewConfigError("netbox.auth.client_key",
+			"client_cert and client_key must be set together"))
+	}
+}
+
+// validateNetBoxTargets checks netbox.targets.
+func (c *Config) validateNetBoxTargets(multiErr *errors.MultiError) {
+	t := c.NetBox.Targets
+	if !c.NetBox.IsEnabled() {
+		multiErr.Add(errors.NewConfigError("netbox.targets",
+			"requires netbox.url and netbox.token"))
+	}
+	if _, err := t.Query(); err != nil {
+		multiErr.Add(errors.NewConfigError("netbox.targets.filter",
+			fmt.Sprintf("invalid filter %q: %v", t.Filter, err)))
+	}
+
+	if t.Username == "" && c.Defaults.Username == "" || t.Password == "" && c.Defaults.Password == "" {
+		multiErr.Add(errors.NewConfigError("netbox.targets",
+			fmt.Sprintf("no credentials for NetBox devices (set username and password, or %s and %s)",
+				defaults.EnvDefaultUsername, defaults.EnvDefaultPassword)))
+	}
+}
+
+// validateNetBoxLock checks netbox.lock.
+func (c *Config) validateNetBoxLock(multiErr *errors.MultiError) {
+	l := c.NetBox.Lock
+	if !c.NetBox.IsEnabled() {
+		multiErr.Add(errors.NewConfigError("netbox.lock",
+			"requires netbox.url and netbox.token"))
+	}
+	if l.Name != "" && lockNameInvalid.MatchString(l.Name) {
+		multiErr.Add(errors.NewConfigError("netbox.lock.name",
+			fmt.Sprintf("invalid name %q (use lowercase letters, digits and dashes)", l.Name)))
+	}
+	if l.TTL != "" {
+		if ttl, err := time.ParseDuration(l.TTL); err != nil || ttl <= 0 {
+			multiErr.Add(errors.NewConfigError("netbox.lock.ttl",
+				fmt.Sprintf("invalid ttl %q (use a positive Go duration such as 1h)", l.TTL)))
+		}
+	}
+	if l.Wait != "" {
+		if wait, err := time.ParseDuration(l.Wait); err != nil || wait < 0 {
+			multiErr.Add(errors.NewConfigError("netbox.lock.wait",
+				fmt.Sprintf("invalid wait %q (use a Go duration such as 10m)", l.Wait)))
+		}
+	}
+}
+
+// validateNetBoxDeviceCache checks netbox.device_cache.
+func (c *Config) validateNetBoxDeviceCache(multiErr *errors.MultiError) {
+	d := c.NetBox.DeviceCache
+	if d.TTL != "" {
+		if ttl, err := time.ParseDuration(d.TTL); err != nil || ttl <= 0 {
+			multiErr.Add(errors.NewConfigError("netbox.device_cache.ttl",
+				fmt.Sprintf("invalid ttl %q (use a positive Go duration such as 24h)", d.TTL)))
+		}
+	}
+	if _, err := d.PrewarmQuery(); err != nil {
+		multiErr.Add(errors.NewConfigError("netbox.device_cache.prewarm_filter",
+			fmt.Sprintf("invalid query string %q: %v", d.PrewarmFilter, err)))
+	}
+}
+
+// lockNameInvalid matches characters that are not allowed in a lock name, which
+// is also the slug of the lock tag.
+var lockNameInvalid = regexp.MustCompile(`[^a-z0-9-]`)
+
+// sqlIdentifier matches the schema and table names the warehouse accepts.
+var sqlIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// validate checks an enabled warehouse export.
+func (w WarehouseConfig) validate(multiErr *errors.MultiError) {
+	switch w.Driver {
+	case WarehousePostgres, WarehouseMySQL:
+	default:
+		multiErr.Add(errors.NewConfigError("warehouse.driver",
+			fmt.Sprintf("invalid driver %q (must be postgres or mysql)", w.Driver)))
+	}
+	if w.GetDSN() == "" {
+		multiErr.Add(errors.NewConfigError("warehouse.dsn",
+			fmt.Sprintf("dsn is required (or set %s)", defaults.EnvWarehouseDSN)))
+	}
+	if w.Schema != "" && !sqlIdentifier.MatchString(w.Schema) {
+		multiErr.Add(errors.NewConfigError("warehouse.schema",
+			fmt.Sprintf("invalid schema %q (letters, digits and underscores only)", w.Schema)))
+	}
+	if w.TablePrefix != "" && !sqlIdentifier.MatchString(w.TablePrefix) {
+		multiErr.Add(errors.NewConfigError("warehouse.table_prefix",
+			fmt.Sprintf("invalid table_prefix %q (letters, digits and underscores only)", w.TablePrefix)))
+	}
+	if w.TimeoutSeconds < 0 {
+		multiErr.Add(errors.NewConfigError("warehouse.timeout_seconds", "must not be negative"))
+	}
+}
+
+// validate checks the warranty API settings.
+func (w WarrantyConfig) validate(multiErr *errors.MultiError) {
+	if w.GetClientID() == "" || w.GetClientSecret() == "" {
+		multiErr.Add(errors.NewConfigError("warranty",
+			fmt.Sprintf("client_id and client_secret are required (or set %s and %s)",
+				defaults.EnvWarrantyClientID, defaults.EnvWarrantyClientSecret)))
+	}
+	for _, u := range []struct{ field, value string }{{"warranty.url", w.URL}, {"warranty.token_url", w.TokenURL}} {
+		if u.value != "" && !strings.HasPrefix(u.value, "https://") && !strings.HasPrefix(u.value, "http://") {
+			multiErr.Add(errors.NewConfigError(u.field,
+				fmt.Sprintf("invalid URL %q (must start with https://)", u.value)))
+		}
+	}
+	if w.TimeoutSeconds < 0 {
+		multiErr.Add(errors.NewConfigError("warranty.timeout_seconds", "must not be negative"))
+	}
+}
+
+// validate checks the Slack notification settings.
+func (s SlackConfig) validate(multiErr *errors.MultiError) {
+	if u := s.GetWebhookURL(); u == "" {
+		multiErr.Add(errors.NewConfigError("notify.slack.webhook_url",
+			fmt.Sprintf("webhook_url is required (or set %s)", defaults.EnvSlackWebhookURL)))
+	} else if !strings.HasPrefix(u, "https://") && !strings.HasPrefix(u, "http://") {
+		multiErr.Add(errors.NewConfigError("notify.slack.webhook_url", "invalid URL (must start with https://)"))
+	}
+	if s.MaxItems < 0 || s.TimeoutSeconds < 0 {
+		multiErr.Add(errors.NewConfigError("notify.slack", "max_items and timeout_seconds must not be negative"))
+	}
+}
+
+// validate checks an output sink; field is its position, e.g.
+// "output.sinks[0]".
+func (s SinkConfig) validate(field string, multiErr *errors.MultiError) {
+	switch s.GetFormat() {
+	case "json", "csv", "table", "console", "markdown", "junit":
+	default:
+		multiErr.Add(errors.NewConfigError(field+".format",
+			fmt.Sprintf("invalid format %q (must be json, csv, table, console, markdown or junit)", s.Format)))
+	}
+
+	switch strings.ToLower(s.Type) {
+	case SinkStdout:
+	case SinkFile:
+		if s.Path == "" {
+			multiErr.Add(errors.NewConfigError(field+".path", "path is required for file"))
+		}
+	case SinkHTTP:
+		if u, err := url.Parse(s.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			multiErr.Add(errors.NewConfigError(field+".url",
+				fmt.Sprintf("invalid url %q (must start with https://)", s.URL)))
+		}
+		for name := range s.Headers {
+			if !validHeaderName(name) {
+				multiErr.Add(errors.NewConfigError(field+".headers",
+					fmt.Sprintf("invalid header name %q", name)))
+			}
+		}
+	case SinkS3:
+		if s.S3.Bucket == "" {
+			multiErr.Add(errors.NewConfigError(field+".s3.bucket", "bucket is required for s3"))
+		}
+		if s.S3.Endpoint != "" {
+			if u, err := url.Parse(s.S3.Endpoint); err != nil || u.Scheme == "" || u.Host == "" {
+				multiErr.Add(errors.NewConfigError(field+".s3.endpoint",
+					fmt.Sprintf("invalid url %q", s.S3.Endpoint)))
+			}
+		}
+		if accessKey, secretKey, _ := s.S3.Credentials(); accessKey == "" || secretKey == "" {
+			multiErr.Add(errors.NewConfigError(field+".s3",
+				fmt.Sprintf("access_key and secret_key are required (or set %s and %s)",
+					defaults.EnvAWSAccessKeyID, defaults.EnvAWSSecretAccessKey)))
+		}
+	default:
+		multiErr.Add(errors.NewConfigError(field+".type",
+			fmt.Sprintf("invalid type %q (must be stdout, file, s3 or http)", s.Type)))
+	}
+}
+
+// Validate checks the configuration for errors.
+func (c *Config) Validate() error {
+	multiErr := &errors.MultiError{}
+
+	// Validate servers (note: server_groups are already expanded into servers at this point)
+	if len(c.Servers) == 0 && !c.NetBox.Targets.IsEnabled() {
+		msg := "no servers configured (provide 'servers', 'server_groups' or 'targets')"
+		if c.Profile == "" && len(c.Profiles) > 0 {
+			msg = fmt.Sprintf("no top-level servers configured; select a profile with -profile (%s)",
+				strings.Join(c.ProfileNames(), ", "))
+		}
+		multiErr.Add(errors.NewConfigError("servers", msg))
+	}
+
+	for i, srv := range c.Servers {
+		if srv.Host == "" {
+			multiErr.Add(errors.NewConfigError(
+				fmt.Sprintf("server[%d].host", i),
+				"host is required"))
+		}
+		validateLabels(fmt.Sprintf("server[%d].labels", i), srv.Labels, multiErr)
+
+		if srv.UsesClientCert() {
+			if srv.ClientKey == "" {
+				multiErr.Add(errors.NewConfigError(
+					fmt.Sprintf("server[%d].client_key", i),
+					fmt.Sprintf("client_key is required with client_cert for %s", srv.Host)))
+			}
+			continue
+		}
+		if srv.ClientKey != "" {
+			multiErr.Add(errors.NewConfigError(
+				fmt.Sprintf("server[%d].client_cert", i),
+				fmt.Sprintf("client_cert is required with client_key for %s", srv.Host)))
+		}
+
+		// Check if we have credentials (either per-server or defaults)
+		username := srv.GetUsername(c.Defaults.Username)
+		password := srv.GetPassword(c.Defaults.Password)
+
+		if username == "" {
+			multiErr.Add(errors.NewConfigError(
+				fmt.Sprintf("server[%d].username", i),
+				fmt.Sprintf("no username configured for %s (set %s or per-server username)",
+					srv.Host, defaults.EnvDefaultUsername)))
+		}
+		if password == "" {
+			multiErr.Add(errors.NewConfigError(
+				fmt.Sprintf("server[%d].password", i),
+				fmt.Sprintf("no password configured for %s (set %s or per-server password)",
+					srv.Host, defaults.EnvDefaultPassword)))
+		}
+	}
+
+	// Validate NetBox config if provided
+	if c.NetBox.URL != "" || c.NetBox.Token != "" || c.NetBox.TokenCommand != "" {
+		if c.NetBox.URL == "" {
+			multiErr.Add(errors.NewConfigError(
+				"netbox.url",
+				fmt.Sprintf("url is required when token is set (or set %s)", defaults.EnvNetBoxURL)))
+		}
+		if c.NetBox.Token == "" && c.NetBox.TokenCommand == "" && c.NetBox.Auth.GetMethod() == NetBoxAuthToken {
+			multiErr.Add(errors.NewConfigError(
+				"netbox.token",
+				fmt.Sprintf("token is required when url is set (or set token_command or %s)", defaults.EnvNetBoxToken)))
+		}
+		if c.NetBox.TokenCommand != "" && c.NetBox.Token != "" {
+			multiErr.Add(errors.NewConfigError("netbox.token_command",
+				fmt.Sprintf("set either token (or %s) or token_command, not both", defaults.EnvNetBoxToken)))
+		}
+		if c.NetBox.TokenCommand != "" && c.NetBox.Auth.GetMethod() != NetBoxAuthToken {
+			multiErr.Add(errors.NewConfigError("netbox.token_command",
+				fmt.Sprintf("requires auth method %s, got %s", NetBoxAuthToken, c.NetBox.Auth.GetMethod())))
+		}
+		c.NetBox.Auth.validate(multiErr)
+
+		if c.NetBox.URL != "" {
+			parsed, err := url.Parse(c.NetBox.URL)
+			if err != nil {
+				multiErr.Add(errors.NewConfigError("netbox.url", fmt.Sprintf("invalid url: %v", err)))
+			} else {
+				// Ensure URL has a scheme (default to https if missing)
+				if parsed.Scheme == "" {
+					c.NetBox.URL = "https://" + c.NetBox.URL
+				}
+			}
+		}
+	}
+
+	// Validate logging config
+	validLevels := map[string]bool{"debug": true, "info": true, "warn": true, "error": true}
+	if !validLevels[strings.ToLower(c.Logging.Level)] {
+		multiErr.Add(errors.NewConfigError(
+			"logging.level",
+			fmt.Sprintf("invalid level %q (must be debug, info, warn, or error)", c.Logging.Level)))
+	}
+
+	validFormats := map[string]bool{"json": true, "console": true}
+	if !validFormats[strings.ToLower(c.Logging.Format)] {
+		multiErr.Add(errors.NewConfigError(
+			"logging.format",
+			fmt.Sprintf("invalid format %q (must be json or console)", c.Logging.Format)))
+	}
+
+	if c.Logging.DebugSampling.Initial < 0 || c.Logging.DebugSampling.Thereafter < 0 {
+		multiErr.Add(errors.NewConfigError("logging.debug_sampling",
+			"initial and thereafter must not be negative"))
+	}
+	if c.Logging.InfoSampling.Initial < 0 || c.Logging.InfoSampling.Thereafter < 0 {
+		multiErr.Add(errors.NewConfigError("logging.info_sampling",
+			"initial and thereafter must not be negative"))
+	}
+	if c.Logging.File.RotateEvery != "" {
+		if d, err := time.ParseDuration(c.Logging.File.RotateEvery); err != nil || d <= 0 {
+			multiErr.Add(errors.NewConfigError("logging.file.rotate_every",
+				fmt.Sprintf("invalid duration %q", c.Logging.File.RotateEvery)))
+		}
+	}
+	if sl := c.Logging.Syslog; sl.IsEnabled() {
+		switch strings.ToLower(sl.Network) {
+		case "udp", "tcp":
+			if sl.Address == "" {
+				multiErr.Add(errors.NewConfigError("logging.syslog.address",
+					fmt.Sprintf("address is required for %s", sl.Network)))
+			}
+		case "unix":
+			if err := features.Check(features.SyslogSocket); err != nil {
+				multiErr.Add(errors.NewConfigError("logging.syslog.network", err.Error()))
+			}
+		default:
+			multiErr.Add(errors.NewConfigError("logging.syslog.network",
+				fmt.Sprintf("invalid network %q (must be udp, tcp or unix)", sl.Network)))
+		}
+		if sl.Facility != "" && !logging.ValidSyslogFacility(sl.Facility) {
+			multiErr.Add(errors.NewConfigError("logging.syslog.facility",
+				fmt.Sprintf("unknown facility %q", sl.Facility)))
+		}
+	}
+	if c.Logging.Journald.Enabled {
+		if err := features.Check(features.Journald); err != nil {
+			multiErr.Add(errors.NewConfigError("logging.journald.enabled", err.Error()))
+		}
+	}
+	if c.Logging.File.MaxSizeMB < 0 || c.Logging.File.MaxBackups < 0 || c.Logging.File.MaxAgeDays < 0 {
+		multiErr.Add(errors.NewConfigError("logging.file",
+			"max_size_mb, max_backups and max_age_days must not be negative"))
+	}
+
+	c.validateFingerprint(multiErr)
+
+	if c.NetBox.MaxChanges < 0 || c.NetBox.MaxCreates < 0 {
+		multiErr.Add(errors.NewConfigError("netbox",
+			"max_changes and max_creates must not be negative"))
+	}
+
+	switch c.NetBox.PowerDraw.Mode {
+	case "", PowerDrawAllocated, PowerDrawFeedField:
+	default:
+		multiErr.Add(errors.NewConfigError("netbox.power_draw.mode",
+			fmt.Sprintf("invalid mode %q (must be %s or %s)", c.NetBox.PowerDraw.Mode, PowerDrawAllocated, PowerDrawFeedField)))
+	}
+
+	for name := range c.HTTP.Headers {
+		if !validHeaderName(name) {
+			multiErr.Add(errors.NewConfigError("http.headers",
+				fmt.Sprintf("invalid header name %q", name)))
+		}
+	}
+
+	if c.History.SkipUnchanged && !c.History.Enabled {
+		multiErr.Add(errors.NewConfigError("history.skip_unchanged",
+			"requires history.enabled (the previous inventory is read from the history)"))
+	}
+	if c.History.FullScanAfter != "" {
+		if d, err := time.ParseDuration(c.History.FullScanAfter); err != nil || d <= 0 {
+			multiErr.Add(errors.NewConfigError("history.full_scan_after",
+				fmt.Sprintf("invalid duration %q (use a positive Go duration such as 168h)", c.History.FullScanAfter)))
+		}
+	}
+
+	if c.Clock.MaxDrift != "" {
+		if d, err := time.ParseDuration(c.Clock.MaxDrift); err != nil || d <= 0 {
+			multiErr.Add(errors.NewConfigError("clock.max_drift",
+				fmt.Sprintf("invalid duration %q (use a positive Go duration such as 90s)", c.Clock.MaxDrift)))
+		}
+	}
+
+	if c.Accounts.ProbeDefaultCredentials && !c.Accounts.Audit {
+		multiErr.Add(errors.NewConfigError("accounts.probe_default_credentials",
+			"requires accounts.audit"))
+	}
+
+	if c.Accounts.MaxAdministrators < 0 {
+		multiErr.Add(errors.NewConfigError("accounts.max_administrators",
+			"must not be negative"))
+	}
+
+	for i, sink := range c.Output.Sinks {
+		sink.validate(fmt.Sprintf("output.sinks[%d]", i), multiErr)
+	}
+
+	aliases := make([]string, 0, len(c.Catalog.Aliases))
+	for from := range c.Catalog.Aliases {
+		aliases = append(aliases, from)
+	}
+	sort.Strings(aliases)
+	for _, from := range aliases {
+		if strings.TrimSpace(from) == "" || strings.TrimSpace(c.Catalog.Aliases[from]) == "" {
+			multiErr.Add(errors.NewConfigError("catalog.aliases",
+				fmt.Sprintf("alias %q -> %q must name both models", from, c.Catalog.Aliases[from])))
+		}
+	}
+
+	if c.Output.Raw.MaxResourceKB < 0 || c.Output.Raw.MaxHostKB < 0 {
+		multiErr.Add(errors.NewConfigError("output.raw",
+			"max_resource_kb and max_host_kb must not be negative"))
+	}
+
+	if c.NetBox.Targets.IsEnabled() {
+		c.validateNetBoxTargets(multiErr)
+	}
+
+	if c.NetBox.Lock.Enabled {
+		c.validateNetBoxLock(multiErr)
+	}
+
+	if c.NetBox.DeviceCache.Enabled {
+		c.validateNetBoxDeviceCache(multiErr)
+	}
+
+	if c.NetBox.GraphQL.BatchSize < 0 {
+		multiErr.Add(errors.NewConfigError("netbox.graphql.batch_size", "must not be negative"))
+	}
+
+	switch c.NetBox.Match.GetStrategy() {
+	case NetBoxMatchSearch:
+	case NetBoxMatchBulk:
+		if _, err := c.NetBox.Match.Query(); err != nil {
+			multiErr.Add(errors.NewConfigError("netbox.match.filter",
+				fmt.Sprintf("invalid query string %q: %v", c.NetBox.Match.Filter, err)))
+		}
+	default:
+		multiErr.Add(errors.NewConfigError("netbox.match.strategy",
+			fmt.Sprintf("invalid strategy %q (must be %s or %s)", c.NetBox.Match.Strategy, NetBoxMatchSearch, NetBoxMatchBulk)))
+	}
+
+	if c.Warehouse.IsEnabled() {
+		c.Warehouse.validate(multiErr)
+	}
+
+	if c.Warranty.Enabled {
+		c.Warranty.validate(multiErr)
+	}
+
+	if c.Notify.Slack.Enabled {
+		c.Notify.Slack.validate(multiErr)
+	}
+
+	c.FirmwarePolicy.validate(multiErr)
+
+	for _, p := range c.Stats.Percentiles {
+		if p <= 0 || p > 100 {
+			multiErr.Add(errors.NewConfigError("stats.percentiles",
+				fmt.Sprintf("invalid percentile %v (must be above 0 and at most 100)", p)))
+		}
+	}
+
+	if c.Daemon.Grafana && c.Daemon.HealthListen == "" {
+		multiErr.Add(errors.NewConfigError("daemon.grafana",
+			"requires daemon.health_listen"))
+	}
+
+	if c.Daemon.Backoff.MaxInterval != "" {
+		if i, err := time.ParseDuration(c.Daemon.Backoff.MaxInterval); err != nil || i <= 0 {
+			multiErr.Add(errors.NewConfigError("daemon.backoff.max_interval",
+				fmt.Sprintf("invalid interval %q (use a positive Go duration such as 12h)", c.Daemon.Backoff.MaxInterval)))
+		}
+	}
+
+	c.Daemon.Budget.validate(multiErr)
+
+	ev := c.Daemon.Events
+	if ev.Destination != "" {
+		if !ev.IsEnabled() {
+			multiErr.Add(errors.NewConfigError("daemon.events.destination",
+				"requires daemon.events.listen"))
+		}
+		if !strings.HasPrefix(ev.Destination, "https://") && !strings.HasPrefix(ev.Destination, "http://") {
+			multiErr.Add(errors.NewConfigError("daemon.events.destination",
+				fmt.Sprintf("invalid URL %q (must start with https://)", ev.Destination)))
+		}
+	}
+	if (ev.TLSCert == "") != (ev.TLSKey == "") {
+		multiErr.Add(errors.NewConfigError("daemon.events",
+			"tls_cert and tls_key must be set together"))
+	}
+	if ev.Debounce != "" {
+		if d, err := time.ParseDuration(ev.Debounce); err != nil || d <= 0 {
+			multiErr.Add(errors.NewConfigError("daemon.events.debounce",
+				fmt.Sprintf("invalid duration %q (use a positive Go duration such as 30s)", ev.Debounce)))
+		}
+	}
+
+	le := c.Daemon.LeaderElection
+	if le.LockFile != "" && le.LockURL != "" {
+		multiErr.Add(errors.NewConfigError("daemon.leader_election",
+			"set either lock_file or lock_url, not both"))
+	}
+	if le.TTL != "" {
+		if ttl, err := time.ParseDuration(le.TTL); err != nil || ttl <= 0 {
+			multiErr.Add(errors.NewConfigError("daemon.leader_election.ttl",
+				fmt.Sprintf("invalid ttl %q (use a positive Go duration such as 30m)", le.TTL)))
+		}
+	}
+
+	if c.Shard != "" || c.ShardBy != "" {
+		if _, err := ParseShard(c.Shard, c.ShardBy); err != nil {
+			multiErr.Add(errors.NewConfigError("shard", err.Error()))
+		}
+	}
+
+	if _, err := units.ParseSystem(c.Units.System); err != nil {
+		multiErr.Add(errors.NewConfigError("units.system", err.Error()))
+	}
+	if p := c.Units.Precision; p != nil && (*p < 0 || *p > 6) {
+		multiErr.Add(errors.NewConfigError("units.precision",
+			fmt.Sprintf("invalid precision %d (must be between 0 and 6)", *p)))
+	}
+
+	switch c.Hooks.RegressionSeverity {
+	case "", "warning", "critical":
+	default:
+		multiErr.Add(errors.NewConfigError("hooks.regression_severity",
+			fmt.Sprintf("invalid severity %q (must be warning or critical)", c.Hooks.RegressionSeverity)))
+	}
+	if len(c.Hooks.OnRegression) > 0 && !c.History.Enabled {
+		multiErr.Add(errors.NewConfigError("hooks.on_regression",
+			"regression detection compares with the last good scan and requires history.enabled"))
+	}
+
+	if c.Daemon.Interval != "" {
+		if i, err := time.ParseDuration(c.Daemon.Interval); err != nil || i <= 0 {
+			multiErr.Add(errors.NewConfigError("daemon.interval",
+				fmt.Sprintf("invalid interval %q (use a positive Go duration such as 30m or 6h)", c.Daemon.Interval)))
+		}
+	}
+
+	return multiErr.ErrorOrNil()
+}
+
+// validHeaderName reports whether name is a valid HTTP header field name (RFC 9110 token).
+func validHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if r > '~' || r <= ' ' || strings.ContainsRune(`"(),/:;<=>?@[\]{}`, r) {
+			return false
+		}
+	}
+	return true
+}
+
+// validateFingerprint checks the aggregation fingerprint options.
+func (c *Config) validateFingerprint(multiErr *errors.MultiError) {
+	fp := c.Aggregation.Fingerprint
+
+	for i, attr := range fp.Ignore {
+		if !slices.Contains(models.FingerprintAttrs, attr) {
+			multiErr.Add(errors.NewConfigError(
+				fmt.Sprintf("aggregation.fingerprint.ignore[%d]", i),
+				fmt.Sprintf("unknown attribute %q (must be one of %s)", attr, strings.Join(models.FingerprintAttrs, ", "))))
+		}
+	}
+
+	for i, b := range fp.RAMBucketsGiB {
+		if b <= 0 || (i > 0 && b <= fp.RAMBucketsGiB[i-1]) {
+			multiErr.Add(errors.NewConfigError(
+				"aggregation.fingerprint.ram_buckets_gib",
+				"boundaries must be positive and strictly ascending"))
+			break
+		}
+	}
+
+	for i, b := range fp.StorageBucketsTB {
+		if b <= 0 || (i > 0 && b <= fp.StorageBucketsTB[i-1]) {
+			multiErr.Add(errors.NewConfigError(
+				"aggregation.fingerprint.storage_buckets_tb",
+				"boundaries must be positive and strictly ascending"))
+			break
+		}
+	}
+}
+
+// NewSingleServerConfig creates a config for scanning a single server.
+// This is useful for CLI single-server mode.
+func NewSingleServerConfig(host, username, password string) *Config {
+	return &Config{
+		Servers: []ServerConfig{
+			{
+				Host:     host,
+				Username: username,
+				Password: password,
+			},
+		},
+		Defaults: DefaultsConfig{
+			TimeoutSeconds: defaults.DefaultTimeoutSeconds,
+		},
+		Concurrency: 1,
+		Logging: LoggingConfig{
+			Level:  defaults.DefaultLogLevel,
+			Format: defaults.DefaultLogFormat,
+		},
+	}
+}
+
+// EnvVarHelp returns a list of all supported environment variables with descriptions.
+func EnvVarHelp() map[string]string {
+	return map[string]string{
+		defaults.EnvLogLevel:                 "Log level: debug, info, warn, error (default: info)",
+		defaults.EnvLogFormat:                "Log format: json, console (default: console)",
+		defaults.EnvDefaultUsername:          "Default iDRAC username",
+		defaults.EnvDefaultPassword:          "Default iDRAC password",
+		defaults.EnvDefaultTimeout:           "Default connection timeout in seconds (default: 60)",
+		defaults.EnvConcurrency:              "Max parallel server scans (default: 5, max: 50)",
+		defaults.EnvInsecureSkipVerify:       "Skip TLS verification for iDRAC (default: true)",
+		defaults.EnvNetBoxURL:                "NetBox API URL",
+		defaults.EnvNetBoxToken:              "NetBox API token",
+		defaults.EnvNetBoxTimeout:            "NetBox API timeout in seconds (default: 30)",
+		defaults.EnvNetBoxInsecureSkipVerify: "Skip TLS verification for NetBox (default: false)",
+		defaults.EnvNetBoxCACert:             "CA_Chain - Custom CA certificate for NetBox (PEM format)",
+		defaults.EnvNetBoxOAuth2ClientID:     "OAuth2 client ID for NetBox (netbox.auth.method: oauth2)",
+		defaults.EnvNetBoxOAuth2ClientSecret: "OAuth2 client secret for NetBox (netbox.auth.method: oauth2)",
+		defaults.EnvRetryMaxAttempts:         "Max retry attempts on failure (default: 3)",
+		defaults.EnvRetryBaseDelay:           "Base delay between retries (default: 1s)",
+		defaults.EnvRetryMaxDelay:            "Max delay between retries (default: 30s)",
+	}
+}
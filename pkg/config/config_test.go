@@ -0,0 +1,1858 @@
+package config
+
+import (
+	"net/http"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/defaults"
+	"github.com/braunma/idrac-netbox-importer/pkg/logging"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+	"github.com/braunma/idrac-netbox-importer/pkg/units"
+)
+
+func init() {
+	_ = logging.Init(logging.Config{Level: "error", Format: "console"})
+}
+
+// clearTestEnv clears all environment variables that might affect config parsing
+func clearTestEnv(t *testing.T) {
+	t.Helper()
+	envVars := []string{
+		"NETBOX_URL",
+		"NETBOX_TOKEN",
+		"IDRAC_DEFAULT_USER",
+		"IDRAC_DEFAULT_PASS",
+		"IDRAC_LOG_LEVEL",
+		"IDRAC_LOG_FORMAT",
+		"IDRAC_DEFAULT_TIMEOUT",
+		"IDRAC_CONCURRENCY",
+		"IDRAC_INSECURE_SKIP_VERIFY",
+		"NETBOX_TIMEOUT",
+		"NETBOX_INSECURE_SKIP_VERIFY",
+		"NETBOX_OAUTH2_CLIENT_ID",
+		"NETBOX_OAUTH2_CLIENT_SECRET",
+		"IDRAC_WARRANTY_CLIENT_ID",
+		"IDRAC_WARRANTY_CLIENT_SECRET",
+		"IDRAC_SLACK_WEBHOOK_URL",
+	}
+
+	for _, env := range envVars {
+		os.Unsetenv(env)
+	}
+
+	// Cleanup after test
+	t.Cleanup(func() {
+		for _, env := range envVars {
+			os.Unsetenv(env)
+		}
+	})
+}
+
+func TestParse_ValidConfig(t *testing.T) {
+	clearTestEnv(t) // Clear environment variables that might interfere
+
+	yaml := `
+netbox:
+  url: "https://netbox.example.com"
+  token: "abc123"
+
+defaults:
+  username: "root"
+  password: "password"
+  timeout_seconds: 30
+
+concurrency: 10
+
+servers:
+  - host: "192.168.1.10"
+  - host: "192.168.1.11"
+    username: "admin"
+    password: "different"
+`
+	cfg, err := Parse([]byte(yaml))
+	require.NoError(t, err)
+
+	assert.Equal(t, "https://netbox.example.com", cfg.NetBox.URL)
+	assert.Equal(t, "abc123", cfg.NetBox.Token)
+	assert.Equal(t, 10, cfg.Concurrency)
+	assert.Len(t, cfg.Servers, 2)
+
+	// First server uses defaults
+	assert.Equal(t, "192.168.1.10", cfg.Servers[0].Host)
+	assert.Equal(t, "root", cfg.Servers[0].GetUsername(cfg.Defaults.Username))
+	assert.Equal(t, "password", cfg.Servers[0].GetPassword(cfg.Defaults.Password))
+
+	// Second server uses custom credentials
+	assert.Equal(t, "admin", cfg.Servers[1].GetUsername(cfg.Defaults.Username))
+	assert.Equal(t, "different", cfg.Servers[1].GetPassword(cfg.Defaults.Password))
+}
+
+func TestParse_MinimalConfig(t *testing.T) {
+	yaml := `
+defaults:
+  username: "root"
+  password: "password"
+
+servers:
+  - host: "192.168.1.10"
+`
+	cfg, err := Parse([]byte(yaml))
+	require.NoError(t, err)
+
+	// Check defaults are applied
+	assert.Equal(t, 5, cfg.Concurrency)
+	assert.Equal(t, 60, cfg.Defaults.TimeoutSeconds)
+	assert.Equal(t, "info", cfg.Logging.Level)
+	assert.Equal(t, "console", cfg.Logging.Format)
+}
+
+func TestParse_NoServers(t *testing.T) {
+	yaml := `
+defaults:
+  username: "root"
+  password: "password"
+
+servers: []
+`
+	_, err := Parse([]byte(yaml))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no servers configured")
+}
+
+func TestParse_MissingCredentials(t *testing.T) {
+	clearTestEnv(t) // Clear environment variables that might interfere
+
+	yaml := `
+servers:
+  - host: "192.168.1.10"
+`
+	_, err := Parse([]byte(yaml))
+	require.Error(t, err)
+	// MultiError shows "2 errors occurred; first: ..." when there are multiple errors
+	assert.Contains(t, err.Error(), "2 errors occurred")
+	assert.Contains(t, err.Error(), "no username configured")
+}
+
+func TestParse_ClientCert(t *testing.T) {
+	clearTestEnv(t)
+
+	cfg, err := Parse([]byte(`
+server_groups:
+  - name: "cert-login"
+    ip_ranges: ["10.0.0.1-10.0.0.2"]
+    client_cert: "/etc/idrac-inventory/idrac.pem"
+    client_key: "/etc/idrac-inventory/idrac-key.pem"
+`))
+	require.NoError(t, err, "no username or password needed with a client certificate")
+	require.Len(t, cfg.Servers, 2)
+	assert.True(t, cfg.Servers[1].UsesClientCert())
+	assert.Equal(t, "/etc/idrac-inventory/idrac-key.pem", cfg.Servers[1].ClientKey)
+
+	_, err = Parse([]byte(`
+servers:
+  - host: "192.168.1.10"
+    client_cert: "/etc/idrac-inventory/idrac.pem"
+`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "server[0].client_key")
+}
+
+func TestParse_MissingHost(t *testing.T) {
+	yaml := `
+defaults:
+  username: "root"
+  password: "password"
+
+servers:
+  - name: "server without host"
+`
+	_, err := Parse([]byte(yaml))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "host is required")
+}
+
+func TestParse_InvalidNetBoxConfig(t *testing.T) {
+	clearTestEnv(t) // Clear environment variables that might interfere
+
+	yaml := `
+netbox:
+  url: "https://netbox.example.com"
+  # token missing
+
+defaults:
+  username: "root"
+  password: "password"
+
+servers:
+  - host: "192.168.1.10"
+`
+	_, err := Parse([]byte(yaml))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "token is required")
+}
+
+func TestParse_NetBoxURLNormalization(t *testing.T) {
+	clearTestEnv(t) // Clear environment variables that might interfere
+
+	tests := []struct {
+		name        string
+		inputURL    string
+		expectedURL string
+	}{
+		{
+			name:        "URL without scheme gets https:// prefix",
+			inputURL:    "netbox.example.com",
+			expectedURL: "https://netbox.example.com",
+		},
+		{
+			name:        "URL with https:// stays unchanged",
+			inputURL:    "https://netbox.example.com",
+			expectedURL: "https://netbox.example.com",
+		},
+		{
+			name:        "URL with http:// stays unchanged",
+			inputURL:    "http://netbox.example.com",
+			expectedURL: "http://netbox.example.com",
+		},
+		{
+			name:        "URL without scheme with path",
+			inputURL:    "netbox.example.com/api",
+			expectedURL: "https://netbox.example.com/api",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			yaml := `
+netbox:
+  url: "` + tt.inputURL + `"
+  token: "test-token"
+
+defaults:
+  username: "root"
+  password: "password"
+
+servers:
+  - host: "192.168.1.10"
+`
+			cfg, err := Parse([]byte(yaml))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedURL, cfg.NetBox.URL, "URL should be normalized correctly")
+		})
+	}
+}
+
+func TestParse_InvalidLogLevel(t *testing.T) {
+	clearTestEnv(t) // Clear environment variables that might interfere
+
+	yaml := `
+defaults:
+  username: "root"
+  password: "password"
+
+logging:
+  level: "verbose"
+
+servers:
+  - host: "192.168.1.10"
+`
+	_, err := Parse([]byte(yaml))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid level")
+}
+
+func TestParse_InvalidLogFormat(t *testing.T) {
+	clearTestEnv(t) // Clear environment variables that might interfere
+
+	yaml := `
+defaults:
+  username: "root"
+  password: "password"
+
+logging:
+  format: "xml"
+
+servers:
+  - host: "192.168.1.10"
+`
+	_, err := Parse([]byte(yaml))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid format")
+}
+
+func TestParse_EnvVarExpansionInYAMLValues(t *testing.T) {
+	os.Setenv("MY_IDRAC_USER", "expanded-user")
+	os.Setenv("MY_IDRAC_PASS", "expanded-pass")
+	defer func() {
+		os.Unsetenv("MY_IDRAC_USER")
+		os.Unsetenv("MY_IDRAC_PASS")
+	}()
+
+	yaml := `
+defaults:
+  username: "fallback"
+  password: "fallback"
+
+server_groups:
+  - name: "test-group"
+    ip_ranges:
+      - "192.168.2.32-192.168.2.33"
+    username: "${MY_IDRAC_USER}"
+    password: "${MY_IDRAC_PASS}"
+`
+	cfg, err := Parse([]byte(yaml))
+	require.NoError(t, err)
+	require.Len(t, cfg.Servers, 2)
+
+	// Per-server credentials should be the expanded env-var values, not the literal placeholder.
+	assert.Equal(t, "expanded-user", cfg.Servers[0].GetUsername(cfg.Defaults.Username))
+	assert.Equal(t, "expanded-pass", cfg.Servers[0].GetPassword(cfg.Defaults.Password))
+}
+
+func TestEnvOverrides(t *testing.T) {
+	// Set environment variables
+	os.Setenv("NETBOX_URL", "https://env-netbox.example.com")
+	os.Setenv("NETBOX_TOKEN", "env-token")
+	os.Setenv("IDRAC_DEFAULT_USER", "env-user")
+	os.Setenv("IDRAC_DEFAULT_PASS", "env-pass")
+	os.Setenv("IDRAC_LOG_LEVEL", "debug")
+	defer func() {
+		os.Unsetenv("NETBOX_URL")
+		os.Unsetenv("NETBOX_TOKEN")
+		os.Unsetenv("IDRAC_DEFAULT_USER")
+		os.Unsetenv("IDRAC_DEFAULT_PASS")
+		os.Unsetenv("IDRAC_LOG_LEVEL")
+	}()
+
+	yaml := `
+netbox:
+  url: "https://yaml-netbox.example.com"
+  token: "yaml-token"
+
+defaults:
+  username: "yaml-user"
+  password: "yaml-pass"
+
+logging:
+  level: "info"
+
+servers:
+  - host: "192.168.1.10"
+`
+	cfg, err := Parse([]byte(yaml))
+	require.NoError(t, err)
+
+	// Environment should override YAML
+	assert.Equal(t, "https://env-netbox.example.com", cfg.NetBox.URL)
+	assert.Equal(t, "env-token", cfg.NetBox.Token)
+	assert.Equal(t, "env-user", cfg.Defaults.Username)
+	assert.Equal(t, "env-pass", cfg.Defaults.Password)
+	assert.Equal(t, "debug", cfg.Logging.Level)
+}
+
+func TestServerConfig_GetDisplayName(t *testing.T) {
+	tests := []struct {
+		name     string
+		server   ServerConfig
+		expected string
+	}{
+		{
+			name:     "with name",
+			server:   ServerConfig{Host: "192.168.1.10", Name: "web-server"},
+			expected: "web-server",
+		},
+		{
+			name:     "without name",
+			server:   ServerConfig{Host: "192.168.1.10"},
+			expected: "192.168.1.10",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.server.GetDisplayName())
+		})
+	}
+}
+
+func TestServerConfig_GetTimeout(t *testing.T) {
+	defaultTimeout := 60 * time.Second
+
+	t.Run("with custom timeout", func(t *testing.T) {
+		timeout := 30
+		s := ServerConfig{TimeoutSeconds: &timeout}
+		assert.Equal(t, 30*time.Second, s.GetTimeout(defaultTimeout))
+	})
+
+	t.Run("without custom timeout", func(t *testing.T) {
+		s := ServerConfig{}
+		assert.Equal(t, defaultTimeout, s.GetTimeout(defaultTimeout))
+	})
+}
+
+func TestParse_NetBoxAuth(t *testing.T) {
+	clearTestEnv(t)
+	os.Setenv("NETBOX_OAUTH2_CLIENT_SECRET", "s3cret")
+
+	cfg, err := Parse([]byte(`
+netbox:
+  url: "https://netbox.example.com"
+  auth:
+    method: oauth2
+    oauth2:
+      token_url: "https://login.example.com/oauth2/token"
+      client_id: "idrac-inventory"
+      scopes: ["netbox"]
+    client_cert: "/etc/idrac-inventory/client.pem"
+    client_key: "/etc/idrac-inventory/client-key.pem"
+defaults:
+  username: "root"
+  password: "password"
+servers:
+  - host: "192.168.1.10"
+`))
+	require.NoError(t, err)
+	auth := cfg.NetBox.Auth
+	assert.Equal(t, NetBoxAuthOAuth2, auth.GetMethod())
+	assert.Equal(t, "s3cret", auth.OAuth2.ClientSecret)
+	assert.Equal(t, OAuth2AuthStyleHeader, auth.OAuth2.GetAuthStyle())
+	assert.Equal(t, "Authorization", auth.OAuth2.GetHeader())
+	assert.Contains(t, cfg.Secrets(), "s3cret")
+	assert.True(t, cfg.NetBox.IsEnabled(), "no static token needed")
+
+	_, err = Parse([]byte(`
+netbox:
+  url: "https://netbox.example.com"
+  auth:
+    method: oauth2
+    client_cert: "/etc/idrac-inventory/client.pem"
+    oauth2:
+      token_url: "login.example.com"
+      client_id: "idrac-inventory"
+      auth_style: jwt
+defaults:
+  username: "root"
+  password: "password"
+servers:
+  - host: "192.168.1.10"
+`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "netbox.auth.oauth2.token_url")
+	assert.Contains(t, err.Error(), "3 errors", "token_url, auth_style and client_key; no static token needed")
+
+	_, err = Parse([]byte(`
+netbox:
+  url: "https://netbox.example.com"
+  auth:
+    method: kerberos
+defaults:
+  username: "root"
+  password: "password"
+servers:
+  - host: "192.168.1.10"
+`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid method")
+}
+
+func TestNetBoxConfig_IsEnabled(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   NetBoxConfig
+		expected bool
+	}{
+		{
+			name:     "both set",
+			config:   NetBoxConfig{URL: "https://netbox.example.com", Token: "abc"},
+			expected: true,
+		},
+		{
+			name:     "only url",
+			config:   NetBoxConfig{URL: "https://netbox.example.com"},
+			expected: false,
+		},
+		{
+			name:     "only token",
+			config:   NetBoxConfig{Token: "abc"},
+			expected: false,
+		},
+		{
+			name:     "neither",
+			config:   NetBoxConfig{},
+			expected: false,
+		},
+		{
+			name:     "oauth2 without token",
+			config:   NetBoxConfig{URL: "https://netbox.example.com", Auth: NetBoxAuthConfig{Method: "oauth2"}},
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.config.IsEnabled())
+		})
+	}
+}
+
+func TestNetBoxConfig_Timeout(t *testing.T) {
+	tests := []struct {
+		name     string
+		seconds  int
+		expected time.Duration
+	}{
+		{"positive", 30, 30 * time.Second},
+		{"zero", 0, 30 * time.Second},
+		{"negative", -5, 30 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NetBoxConfig{TimeoutSeconds: tt.seconds}
+			assert.Equal(t, tt.expected, cfg.Timeout())
+		})
+	}
+}
+
+func TestDefaultsConfig_Timeout(t *testing.T) {
+	tests := []struct {
+		name     string
+		seconds  int
+		expected time.Duration
+	}{
+		{"positive", 30, 30 * time.Second},
+		{"zero", 0, 60 * time.Second},
+		{"negative", -5, 60 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultsConfig{TimeoutSeconds: tt.seconds}
+			assert.Equal(t, tt.expected, cfg.Timeout())
+		})
+	}
+}
+
+func TestNewSingleServerConfig(t *testing.T) {
+	cfg := NewSingleServerConfig("192.168.1.10", "admin", "secret")
+
+	require.Len(t, cfg.Servers, 1)
+	assert.Equal(t, "192.168.1.10", cfg.Servers[0].Host)
+	assert.Equal(t, "admin", cfg.Servers[0].Username)
+	assert.Equal(t, "secret", cfg.Servers[0].Password)
+	assert.Equal(t, 1, cfg.Concurrency)
+	assert.Equal(t, 60, cfg.Defaults.TimeoutSeconds)
+}
+
+func TestConcurrencyLimits(t *testing.T) {
+	t.Run("upper limit", func(t *testing.T) {
+		yaml := `
+concurrency: 100
+
+defaults:
+  username: "root"
+  password: "password"
+
+servers:
+  - host: "192.168.1.10"
+`
+		cfg, err := Parse([]byte(yaml))
+		require.NoError(t, err)
+		assert.Equal(t, 50, cfg.Concurrency)
+	})
+
+	t.Run("zero defaults to 5", func(t *testing.T) {
+		yaml := `
+concurrency: 0
+
+defaults:
+  username: "root"
+  password: "password"
+
+servers:
+  - host: "192.168.1.10"
+`
+		cfg, err := Parse([]byte(yaml))
+		require.NoError(t, err)
+		assert.Equal(t, 5, cfg.Concurrency)
+	})
+}
+
+func TestParse_AggregationFingerprint(t *testing.T) {
+	clearTestEnv(t)
+
+	base := `
+defaults:
+  username: "root"
+  password: "password"
+servers:
+  - host: "192.168.1.10"
+`
+	t.Run("valid options", func(t *testing.T) {
+		cfg, err := Parse([]byte(base + `
+aggregation:
+  fingerprint:
+    ignore: ["ram_speed", "gpu"]
+    ram_buckets_gib: [128, 256, 512]
+    storage_buckets_tb: [1, 10.5]
+`))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"ram_speed", "gpu"}, cfg.Aggregation.Fingerprint.Ignore)
+		assert.Equal(t, []int{128, 256, 512}, cfg.Aggregation.Fingerprint.RAMBucketsGiB)
+		assert.Equal(t, []float64{1, 10.5}, cfg.Aggregation.Fingerprint.StorageBucketsTB)
+	})
+
+	t.Run("unknown ignore attribute", func(t *testing.T) {
+		_, err := Parse([]byte(base + `
+aggregation:
+  fingerprint:
+    ignore: ["colour"]
+`))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "aggregation.fingerprint.ignore")
+		assert.Contains(t, err.Error(), "storage, gpu, ram_speed")
+	})
+
+	t.Run("every fingerprint attribute", func(t *testing.T) {
+		cfg, err := Parse([]byte(base + `
+aggregation:
+  fingerprint:
+    ignore: ["` + strings.Join(models.FingerprintAttrs, `", "`) + `"]
+`))
+		require.NoError(t, err)
+		assert.Len(t, cfg.Aggregation.Fingerprint.Ignore, 6)
+	})
+
+	t.Run("descending buckets", func(t *testing.T) {
+		_, err := Parse([]byte(base + `
+aggregation:
+  fingerprint:
+    ram_buckets_gib: [512, 256]
+`))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "ram_buckets_gib")
+	})
+}
+
+func TestParse_DaemonAndPaths(t *testing.T) {
+	clearTestEnv(t)
+	t.Setenv("IDRAC_STATE_DIR", "")
+	t.Setenv("XDG_STATE_HOME", "/xdg/state")
+
+	base := `
+defaults:
+  username: "root"
+  password: "password"
+servers:
+  - host: "192.168.1.10"
+`
+	t.Run("defaults", func(t *testing.T) {
+		cfg, err := Parse([]byte(base))
+		require.NoError(t, err)
+		assert.Equal(t, time.Hour, cfg.Daemon.GetInterval())
+		if runtime.GOOS != "windows" {
+			assert.Equal(t, "/xdg/state/idrac-inventory", cfg.Paths.GetStateDir())
+		}
+	})
+
+	t.Run("overrides", func(t *testing.T) {
+		cfg, err := Parse([]byte(base + `
+daemon:
+  interval: "30m"
+paths:
+  state_dir: "/var/lib/inventory"
+`))
+		require.NoError(t, err)
+		assert.Equal(t, 30*time.Minute, cfg.Daemon.GetInterval())
+		assert.Equal(t, "/var/lib/inventory", cfg.Paths.GetStateDir())
+	})
+
+	t.Run("invalid interval", func(t *testing.T) {
+		_, err := Parse([]byte(base + `
+daemon:
+  interval: "soon"
+`))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "daemon.interval")
+	})
+}
+
+func TestParse_LogDebugSampling(t *testing.T) {
+	clearTestEnv(t)
+
+	base := `
+defaults:
+  username: "root"
+  password: "password"
+servers:
+  - host: "192.168.1.10"
+`
+	cfg, err := Parse([]byte(base + `
+logging:
+  debug_sampling:
+    initial: 10
+    thereafter: 100
+`))
+	require.NoError(t, err)
+	assert.True(t, cfg.Logging.DebugSampling.IsEnabled())
+	assert.Equal(t, 10, cfg.Logging.DebugSampling.Initial)
+	assert.Equal(t, 100, cfg.Logging.DebugSampling.Thereafter)
+
+	_, err = Parse([]byte(base + `
+logging:
+  debug_sampling:
+    thereafter: -1
+`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "logging.debug_sampling")
+
+	cfg, err = Parse([]byte(base + `
+logging:
+  info_sampling:
+    initial: 50
+`))
+	require.NoError(t, err)
+	assert.True(t, cfg.Logging.InfoSampling.IsEnabled())
+	assert.False(t, cfg.Logging.DebugSampling.IsEnabled())
+
+	_, err = Parse([]byte(base + `
+logging:
+  info_sampling:
+    initial: -5
+`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "logging.info_sampling")
+}
+
+func TestParse_LogFile(t *testing.T) {
+	clearTestEnv(t)
+
+	base := `
+defaults:
+  username: "root"
+  password: "password"
+servers:
+  - host: "192.168.1.10"
+`
+	cfg, err := Parse([]byte(base + `
+logging:
+  file:
+    path: "/var/log/idrac-inventory/idrac-inventory.log"
+    error_path: "/var/log/idrac-inventory/error.log"
+    max_size_mb: 100
+    rotate_every: "24h"
+    max_backups: 7
+  stdout: true
+`))
+	require.NoError(t, err)
+	assert.True(t, cfg.Logging.File.IsEnabled())
+	assert.Equal(t, 24*time.Hour, cfg.Logging.File.GetRotateEvery())
+	assert.Equal(t, 7, cfg.Logging.File.MaxBackups)
+	assert.True(t, cfg.Logging.Stdout)
+
+	_, err = Parse([]byte(base + `
+logging:
+  file:
+    path: "/tmp/x.log"
+    rotate_every: "daily"
+`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "logging.file.rotate_every")
+}
+
+func TestParse_LogSyslog(t *testing.T) {
+	clearTestEnv(t)
+
+	base := `
+defaults:
+  username: "root"
+  password: "password"
+servers:
+  - host: "192.168.1.10"
+`
+	cfg, err := Parse([]byte(base + `
+logging:
+  syslog:
+    network: "unix"
+    facility: "local3"
+  journald:
+    enabled: true
+`))
+	require.NoError(t, err)
+	assert.True(t, cfg.Logging.Syslog.IsEnabled())
+	assert.True(t, cfg.Logging.Journald.Enabled)
+
+	_, err = Parse([]byte(base + `
+logging:
+  syslog:
+    network: "tcp"
+    facility: "local9"
+`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "2 errors")
+	assert.Contains(t, err.Error(), "logging.syslog.address")
+}
+
+func TestParse_HTTPHeaders(t *testing.T) {
+	clearTestEnv(t)
+	t.Setenv("SCAN_TICKET", "CHG-1234")
+
+	base := `
+defaults:
+  username: "root"
+  password: "password"
+servers:
+  - host: "192.168.1.10"
+`
+	t.Run("user agent and headers", func(t *testing.T) {
+		cfg, err := Parse([]byte(base + `
+http:
+  user_agent: "inventory-scanner/{version} (secops)"
+  headers:
+    X-Scan-Ticket: "${SCAN_TICKET}"
+`))
+		require.NoError(t, err)
+
+		header := http.Header{}
+		cfg.HTTP.ApplyHeaders(header)
+		assert.Equal(t, "inventory-scanner/"+defaults.Version+" (secops)", header.Get("User-Agent"))
+		assert.Equal(t, "CHG-1234", header.Get("X-Scan-Ticket"))
+	})
+
+	t.Run("default user agent", func(t *testing.T) {
+		cfg, err := Parse([]byte(base))
+		require.NoError(t, err)
+		assert.Equal(t, "github.com/braunma/idrac-netbox-importer/"+defaults.Version, cfg.HTTP.GetUserAgent())
+	})
+
+	t.Run("invalid header name", func(t *testing.T) {
+		_, err := Parse([]byte(base + `
+http:
+  headers:
+    "X Scan Ticket": "1"
+`))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "http.headers")
+	})
+}
+
+func TestParseProfile(t *testing.T) {
+	clearTestEnv(t)
+
+	data := []byte(`
+defaults:
+  username: "root"
+  password: "shared"
+  timeout_seconds: 30
+servers:
+  - host: "10.0.0.1"
+netbox:
+  url: "https://netbox.prod.example.com"
+  token: "prod-token"
+profiles:
+  lab:
+    defaults:
+      password: "lab-pass"
+    server_groups:
+      - name: "lab"
+        ip_ranges: ["10.9.0.1-10.9.0.2"]
+    netbox:
+      url: "https://netbox.lab.example.com"
+      token: "lab-token"
+  empty:
+    concurrency: 2
+`)
+
+	t.Run("no profile uses top-level", func(t *testing.T) {
+		cfg, err := ParseProfile(data, "")
+		require.NoError(t, err)
+		assert.Empty(t, cfg.Profile)
+		require.Len(t, cfg.Servers, 1)
+		assert.Equal(t, "10.0.0.1", cfg.Servers[0].Host)
+		assert.Equal(t, []string{"empty", "lab"}, cfg.ProfileNames())
+	})
+
+	t.Run("profile overlays settings", func(t *testing.T) {
+		cfg, err := ParseProfile(data, "lab")
+		require.NoError(t, err)
+		assert.Equal(t, "lab", cfg.Profile)
+
+		// Inventory is replaced, not merged
+		require.Len(t, cfg.Servers, 2)
+		assert.Equal(t, "10.9.0.1", cfg.Servers[0].Host)
+
+		// Defaults are merged field by field
+		assert.Equal(t, "root", cfg.Defaults.Username)
+		assert.Equal(t, "lab-pass", cfg.Defaults.Password)
+		assert.Equal(t, 30, cfg.Defaults.TimeoutSeconds)
+
+		assert.Equal(t, "https://netbox.lab.example.com", cfg.NetBox.URL)
+		assert.Equal(t, "lab-token", cfg.NetBox.Token)
+	})
+
+	t.Run("profile without servers keeps top-level inventory", func(t *testing.T) {
+		cfg, err := ParseProfile(data, "empty")
+		require.NoError(t, err)
+		assert.Equal(t, 2, cfg.Concurrency)
+		require.Len(t, cfg.Servers, 1)
+	})
+
+	t.Run("unknown profile", func(t *testing.T) {
+		_, err := ParseProfile(data, "staging")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `unknown profile "staging" (available: empty, lab)`)
+	})
+
+	t.Run("profiles only requires selection", func(t *testing.T) {
+		_, err := ParseProfile([]byte(`
+profiles:
+  lab:
+    servers:
+      - host: "10.9.0.1"
+        username: "root"
+        password: "pw"
+`), "")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "select a profile with -profile (lab)")
+	})
+}
+
+func TestParse_Catalog(t *testing.T) {
+	clearTestEnv(t)
+
+	cfg, err := Parse([]byte(`
+defaults:
+  username: "root"
+  password: "password"
+netbox:
+  sync_device_types: true
+catalog:
+  file: "/etc/idrac-inventory/catalog.yaml"
+servers:
+  - host: "192.168.1.10"
+`))
+	require.NoError(t, err)
+	assert.True(t, cfg.NetBox.SyncDeviceTypes)
+	assert.Equal(t, "/etc/idrac-inventory/catalog.yaml", cfg.Catalog.File)
+}
+
+func TestParse_Placement(t *testing.T) {
+	clearTestEnv(t)
+
+	cfg, err := Parse([]byte(`
+defaults:
+  username: "root"
+  password: "password"
+placement:
+  file: "placement.csv"
+servers:
+  - host: "192.168.1.10"
+`))
+	require.NoError(t, err)
+	assert.Equal(t, "placement.csv", cfg.Placement.File)
+}
+
+func TestParse_OnRegression(t *testing.T) {
+	clearTestEnv(t)
+
+	base := `
+defaults:
+  username: "root"
+  password: "password"
+servers:
+  - host: "192.168.1.10"
+hooks:
+  on_regression: ["notify.sh"]
+`
+	cfg, err := Parse([]byte(base + `
+  regression_severity: critical
+history:
+  enabled: true
+`))
+	require.NoError(t, err)
+	assert.True(t, cfg.Hooks.IsEnabled())
+	assert.Equal(t, "critical", cfg.Hooks.RegressionSeverity)
+
+	_, err = Parse([]byte(base))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "hooks.on_regression")
+
+	_, err = Parse([]byte(base + `
+  regression_severity: info
+history:
+  enabled: true
+`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "hooks.regression_severity")
+}
+
+func TestParse_LeaderElection(t *testing.T) {
+	clearTestEnv(t)
+
+	cfg, err := Parse([]byte(`
+defaults:
+  username: "root"
+  password: "password"
+servers:
+  - host: "192.168.1.10"
+daemon:
+  interval: "30m"
+  health_listen: ":8081"
+  leader_election:
+    lock_file: "/shared/idrac-inventory.lock"
+    identity: "inventory-0"
+`))
+	require.NoError(t, err)
+	le := cfg.Daemon.LeaderElection
+	assert.True(t, le.IsEnabled())
+	assert.Equal(t, "inventory-0", le.GetIdentity())
+	assert.Equal(t, time.Hour, le.GetTTL(2*cfg.Daemon.GetInterval()), "default is twice the interval")
+	assert.Equal(t, ":8081", cfg.Daemon.HealthListen)
+
+	_, err = Parse([]byte(`
+defaults:
+  username: "root"
+  password: "password"
+servers:
+  - host: "192.168.1.10"
+daemon:
+  leader_election:
+    lock_file: "/shared/idrac-inventory.lock"
+    lock_url: "http://lock:8080/inventory"
+    ttl: "soon"
+`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "daemon.leader_election")
+	assert.Contains(t, err.Error(), "2 errors")
+}
+
+func TestParse_DaemonGrafana(t *testing.T) {
+	clearTestEnv(t)
+
+	_, err := Parse([]byte(`
+defaults:
+  username: "root"
+  password: "password"
+servers:
+  - host: "192.168.1.10"
+daemon:
+  grafana: true
+`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "daemon.grafana")
+
+	cfg, err := Parse([]byte(`
+defaults:
+  username: "root"
+  password: "password"
+servers:
+  - host: "192.168.1.10"
+daemon:
+  health_listen: ":8081"
+  grafana: true
+`))
+	require.NoError(t, err)
+	assert.True(t, cfg.Daemon.Grafana)
+}
+
+func TestBackoffConfig(t *testing.T) {
+	assert.Equal(t, 24*time.Hour, BackoffConfig{}.GetMaxInterval())
+	assert.Equal(t, 10, BackoffConfig{}.GetQuarantineAfter())
+	assert.Equal(t, 0, BackoffConfig{QuarantineAfter: -1}.GetQuarantineAfter(), "negative disables quarantine")
+
+	b := BackoffConfig{MaxInterval: "6h", QuarantineAfter: 3}
+	assert.Equal(t, 6*time.Hour, b.GetMaxInterval())
+	assert.Equal(t, 3, b.GetQuarantineAfter())
+}
+
+func TestParse_DaemonEvents(t *testing.T) {
+	clearTestEnv(t)
+
+	cfg, err := Parse([]byte(`
+defaults:
+  username: "root"
+  password: "password"
+servers:
+  - host: "192.168.1.10"
+daemon:
+  events:
+    listen: ":8443"
+    tls_cert: "/etc/idrac-inventory/events.crt"
+    tls_key: "/etc/idrac-inventory/events.key"
+    destination: "https://inventory.example.com:8443/events"
+    severities: ["Critical", "Warning"]
+`))
+	require.NoError(t, err)
+	ev := cfg.Daemon.Events
+	assert.True(t, ev.IsEnabled())
+	assert.Equal(t, []string{"Critical", "Warning"}, ev.GetSeverities())
+	assert.Contains(t, ev.GetMessageIDs(), "PDR")
+	assert.Equal(t, 30*time.Second, ev.GetDebounce())
+	assert.False(t, EventsConfig{}.IsEnabled())
+
+	_, err = Parse([]byte(`
+defaults:
+  username: "root"
+  password: "password"
+servers:
+  - host: "192.168.1.10"
+daemon:
+  events:
+    tls_cert: "/etc/idrac-inventory/events.crt"
+    destination: "inventory.example.com:8443"
+    debounce: "soon"
+`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "daemon.events")
+	assert.Contains(t, err.Error(), "4 errors")
+}
+
+func TestParse_SkipUnchanged(t *testing.T) {
+	clearTestEnv(t)
+
+	cfg, err := Parse([]byte(`
+defaults:
+  username: "root"
+  password: "password"
+servers:
+  - host: "192.168.1.10"
+history:
+  enabled: true
+  skip_unchanged: true
+  full_scan_after: "72h"
+`))
+	require.NoError(t, err)
+	assert.True(t, cfg.History.SkipUnchanged)
+	assert.Equal(t, 72*time.Hour, cfg.History.GetFullScanAfter())
+	assert.Equal(t, 7*24*time.Hour, HistoryConfig{}.GetFullScanAfter())
+
+	_, err = Parse([]byte(`
+defaults:
+  username: "root"
+  password: "password"
+servers:
+  - host: "192.168.1.10"
+history:
+  skip_unchanged: true
+  full_scan_after: "weekly"
+`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "history.skip_unchanged")
+	assert.Contains(t, err.Error(), "2 errors")
+}
+
+func TestParse_Units(t *testing.T) {
+	clearTestEnv(t)
+
+	cfg, err := Parse([]byte(`
+defaults:
+  username: "root"
+  password: "password"
+servers:
+  - host: "192.168.1.10"
+`))
+	require.NoError(t, err)
+	assert.Equal(t, units.Default(), cfg.Units.Format(), "binary with 2 decimals by default")
+
+	cfg, err = Parse([]byte(`
+defaults:
+  username: "root"
+  password: "password"
+servers:
+  - host: "192.168.1.10"
+units:
+  system: decimal
+  precision: 0
+`))
+	require.NoError(t, err)
+	assert.Equal(t, units.Format{System: units.Decimal, Precision: 0}, cfg.Units.Format())
+
+	_, err = Parse([]byte(`
+defaults:
+  username: "root"
+  password: "password"
+servers:
+  - host: "192.168.1.10"
+units:
+  system: metric
+  precision: 9
+`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "units.system")
+}
+
+func TestParse_Clock(t *testing.T) {
+	clearTestEnv(t)
+
+	cfg, err := Parse([]byte(`
+defaults:
+  username: "root"
+  password: "password"
+servers:
+  - host: "192.168.1.10"
+`))
+	require.NoError(t, err)
+	assert.Equal(t, defaults.DefaultMaxClockDrift, cfg.Clock.GetMaxDrift())
+
+	cfg, err = Parse([]byte(`
+defaults:
+  username: "root"
+  password: "password"
+servers:
+  - host: "192.168.1.10"
+clock:
+  max_drift: 30s
+`))
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Second, cfg.Clock.GetMaxDrift())
+
+	_, err = Parse([]byte(`
+defaults:
+  username: "root"
+  password: "password"
+servers:
+  - host: "192.168.1.10"
+clock:
+  max_drift: soon
+`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "clock.max_drift")
+}
+
+func TestParse_Accounts(t *testing.T) {
+	clearTestEnv(t)
+
+	cfg, err := Parse([]byte(`
+defaults:
+  username: "root"
+  password: "password"
+servers:
+  - host: "192.168.1.10"
+accounts:
+  audit: true
+  probe_default_credentials: true
+  max_administrators: 2
+`))
+	require.NoError(t, err)
+	assert.Equal(t, AccountsConfig{Audit: true, ProbeDefaultCredentials: true, MaxAdministrators: 2}, cfg.Accounts)
+
+	_, err = Parse([]byte(`
+defaults:
+  username: "root"
+  password: "password"
+servers:
+  - host: "192.168.1.10"
+accounts:
+  probe_default_credentials: true
+  max_administrators: -1
+`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "2 errors")
+}
+
+func TestParse_OutputSinks(t *testing.T) {
+	clearTestEnv(t)
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_SESSION_TOKEN", "")
+
+	cfg, err := Parse([]byte(`
+defaults:
+  username: "root"
+  password: "password"
+servers:
+  - host: "192.168.1.10"
+output:
+  sinks:
+    - type: file
+      path: /var/lib/idrac-inventory/archive/inventory-{timestamp}.csv
+      format: csv
+    - type: s3
+      compress: true
+      s3:
+        endpoint: https://minio.example.com:9000/
+        bucket: inventory
+        path_style: true
+        session_token: FwoGZXIvYXdzEXAMPLE
+    - type: http
+      url: https://archive.example.com/inventory.md
+      format: markdown
+      headers:
+        Authorization: Bearer token
+`))
+	require.NoError(t, err)
+	require.Len(t, cfg.Output.Sinks, 3)
+	assert.Equal(t, "csv", cfg.Output.Sinks[0].GetFormat())
+	assert.Equal(t, defaults.DefaultSinkTimeout, cfg.Output.Sinks[0].Timeout())
+
+	s3 := cfg.Output.Sinks[1]
+	assert.Equal(t, "json", s3.GetFormat())
+	assert.Equal(t, "https://minio.example.com:9000", s3.S3.GetEndpoint())
+	assert.Equal(t, "us-east-1", s3.S3.GetRegion())
+	accessKey, secretKey, _ := s3.S3.Credentials()
+	assert.Equal(t, "AKIDEXAMPLE", accessKey, "falls back to AWS_ACCESS_KEY_ID")
+	assert.Equal(t, "secret", secretKey)
+	assert.Subset(t, cfg.Secrets(), []string{"secret", "FwoGZXIvYXdzEXAMPLE"}, "secret key and session token are redacted")
+	assert.Equal(t, "https://s3.eu-central-1.amazonaws.com", S3Config{Region: "eu-central-1"}.GetEndpoint())
+
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	_, err = Parse([]byte(`
+defaults:
+  username: "root"
+  password: "password"
+servers:
+  - host: "192.168.1.10"
+output:
+  sinks:
+    - type: file
+    - type: s3
+      format: xml
+    - type: http
+      url: ftp://archive.example.com
+    - type: email
+`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "6 errors")
+}
+
+func TestParse_NetBoxTargets(t *testing.T) {
+	clearTestEnv(t)
+
+	cfg, err := Parse([]byte(`
+netbox:
+  url: "https://netbox.example.com"
+  token: "abc123"
+  targets:
+    filter: "role=server&tag=idrac"
+    username: "svc-inventory"
+    password: "secret"
+`))
+	require.NoError(t, err, "servers are optional with netbox.targets")
+	targets := cfg.NetBox.Targets
+	assert.True(t, targets.IsEnabled())
+	assert.Equal(t, TargetAddressOOBIP, targets.GetAddress())
+	query, err := targets.Query()
+	require.NoError(t, err)
+	assert.Equal(t, "idrac", query.Get("tag"))
+	assert.Contains(t, cfg.Secrets(), "secret")
+
+	srv := targets.Server("10.0.0.5", "web-01")
+	assert.Equal(t, "svc-inventory", srv.GetUsername("root"))
+	assert.Equal(t, "web-01", srv.GetDisplayName())
+
+	_, err = Parse([]byte(`
+netbox:
+  targets:
+    filter: "role=%zz"
+`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "3 errors")
+}
+
+func TestParse_NetBoxLock(t *testing.T) {
+	clearTestEnv(t)
+
+	base := `
+defaults:
+  username: "root"
+  password: "password"
+servers:
+  - host: "192.168.1.10"
+netbox:
+  url: "https://netbox.example.com"
+  token: "abc123"
+`
+	cfg, err := Parse([]byte(base + `
+  lock:
+    enabled: true
+    wait: 10m
+`))
+	require.NoError(t, err)
+	lock := cfg.NetBox.Lock
+	assert.Equal(t, "idrac-inventory-sync-lock", lock.GetName())
+	assert.Equal(t, time.Hour, lock.GetTTL())
+	assert.Equal(t, 10*time.Minute, lock.GetWait())
+	assert.Regexp(t, `/\d+$`, lock.GetIdentity(), "runs on the same host are told apart")
+
+	_, err = Parse([]byte(base + `
+  lock:
+    enabled: true
+    name: "Sync Lock"
+    ttl: forever
+    wait: -1m
+`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "3 errors")
+}
+
+func TestParse_NetBoxDeviceCache(t *testing.T) {
+	clearTestEnv(t)
+
+	base := `
+defaults:
+  username: "root"
+  password: "password"
+servers:
+  - host: "192.168.1.10"
+netbox:
+  url: "https://netbox.example.com"
+  token: "abc123"
+`
+	cfg, err := Parse([]byte(base + `
+  device_cache:
+    enabled: true
+    prewarm: true
+    prewarm_filter: "site=dc1&manufacturer=dell"
+`))
+	require.NoError(t, err)
+	cache := cfg.NetBox.DeviceCache
+	assert.Equal(t, 24*time.Hour, cache.GetTTL())
+	query, err := cache.PrewarmQuery()
+	require.NoError(t, err)
+	assert.Equal(t, "dell", query.Get("manufacturer"))
+
+	_, err = Parse([]byte(base + `
+  device_cache:
+    enabled: true
+    ttl: 0s
+    prewarm_filter: "site=%zz"
+`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "2 errors")
+}
+
+func TestParse_NetBoxMatch(t *testing.T) {
+	clearTestEnv(t)
+
+	base := `
+defaults:
+  username: "root"
+  password: "password"
+servers:
+  - host: "192.168.1.10"
+netbox:
+  url: "https://netbox.example.com"
+  token: "abc123"
+`
+	cfg, err := Parse([]byte(base))
+	require.NoError(t, err)
+	assert.Equal(t, NetBoxMatchSearch, cfg.NetBox.Match.GetStrategy())
+
+	cfg, err = Parse([]byte(base + `
+  match:
+    strategy: Bulk
+`))
+	require.NoError(t, err)
+	assert.Equal(t, NetBoxMatchBulk, cfg.NetBox.Match.GetStrategy())
+	query, err := cfg.NetBox.Match.Query()
+	require.NoError(t, err)
+	assert.Equal(t, "dell", query.Get("manufacturer"))
+
+	_, err = Parse([]byte(base + `
+  match:
+    strategy: fuzzy
+`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "netbox.match.strategy")
+
+	_, err = Parse([]byte(base + `
+  match:
+    strategy: bulk
+    filter: "site=%zz"
+`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "netbox.match.filter")
+}
+
+func TestParse_NetBoxGraphQL(t *testing.T) {
+	clearTestEnv(t)
+
+	base := `
+defaults:
+  username: "root"
+  password: "password"
+servers:
+  - host: "192.168.1.10"
+netbox:
+  url: "https://netbox.example.com"
+  token: "abc123"
+`
+	cfg, err := Parse([]byte(base + `
+  graphql:
+    enabled: true
+`))
+	require.NoError(t, err)
+	assert.Equal(t, 60, cfg.NetBox.GraphQL.GetBatchSize())
+
+	_, err = Parse([]byte(base + `
+  graphql:
+    enabled: true
+    batch_size: -1
+`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "netbox.graphql.batch_size")
+}
+
+func TestParse_NetBoxTokenCommand(t *testing.T) {
+	clearTestEnv(t)
+
+	base := `
+defaults:
+  username: "root"
+  password: "password"
+servers:
+  - host: "192.168.1.10"
+netbox:
+  url: "https://netbox.example.com"
+  token_command: "vault read -field=token secret/netbox"
+`
+	cfg, err := Parse([]byte(base))
+	require.NoError(t, err)
+	assert.True(t, cfg.NetBox.IsEnabled())
+
+	_, err = Parse([]byte(base + `
+  token: "abc123"
+`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not both")
+
+	_, err = Parse([]byte(base + `
+  auth:
+    method: mtls
+    client_cert: /etc/idrac-inventory/client.pem
+`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "netbox.token_command")
+}
+
+func TestParse_StatsPercentiles(t *testing.T) {
+	clearTestEnv(t)
+
+	base := `
+defaults:
+  username: "root"
+  password: "password"
+servers:
+  - host: "192.168.1.10"
+`
+	cfg, err := Parse([]byte(base))
+	require.NoError(t, err)
+	assert.Equal(t, []float64{50, 90, 99}, cfg.Stats.GetPercentiles())
+
+	cfg, err = Parse([]byte(base + `
+stats:
+  percentiles: [75, 95, 99.9]
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []float64{75, 95, 99.9}, cfg.Stats.GetPercentiles())
+
+	_, err = Parse([]byte(base + `
+stats:
+  percentiles: [0, 150]
+`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "2 errors")
+}
+
+func TestParse_Warranty(t *testing.T) {
+	clearTestEnv(t)
+
+	base := `
+defaults:
+  username: "root"
+  password: "password"
+servers:
+  - host: "192.168.1.10"
+warranty:
+  enabled: true
+`
+	_, err := Parse([]byte(base + `
+  url: "ftp://warranty"
+  timeout_seconds: -1
+`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "3 errors")
+
+	t.Setenv("IDRAC_WARRANTY_CLIENT_ID", "l7xx1234")
+	t.Setenv("IDRAC_WARRANTY_CLIENT_SECRET", "secret")
+	cfg, err := Parse([]byte(base))
+	require.NoError(t, err)
+	assert.Equal(t, "l7xx1234", cfg.Warranty.GetClientID())
+	assert.Equal(t, "secret", cfg.Warranty.GetClientSecret())
+	assert.Contains(t, cfg.Secrets(), "secret")
+	assert.Equal(t, "https://apigtwb2c.us.dell.com/PROD/sbil/eapi/v5/asset-entitlements", cfg.Warranty.GetURL())
+	assert.Equal(t, 30*time.Second, cfg.Warranty.Timeout())
+}
+
+func TestParse_FirmwarePolicy(t *testing.T) {
+	clearTestEnv(t)
+
+	base := `
+defaults:
+  username: "root"
+  password: "password"
+servers:
+  - host: "192.168.1.10"
+firmware_policy:
+`
+	cfg, err := Parse([]byte(base + `
+  min_idrac_version: "6.10.30.00"
+  models:
+    - model: R750
+      min_bios_version: "1.9.2"
+`))
+	require.NoError(t, err)
+	assert.True(t, cfg.FirmwarePolicy.IsEnabled())
+	assert.Equal(t, "1.9.2", cfg.FirmwarePolicy.Models[0].MinBIOSVersion)
+
+	_, err = Parse([]byte(base + `
+  min_bios_version: "latest"
+  models:
+    - min_idrac_version: "7.00.00.00"
+    - model: R660
+`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "3 errors")
+}
+
+func TestParse_Warehouse(t *testing.T) {
+	clearTestEnv(t)
+	t.Setenv("IDRAC_WAREHOUSE_DSN", "postgres://inventory@db/inventory")
+
+	cfg, err := Parse([]byte(`
+defaults:
+  username: "root"
+  password: "password"
+servers:
+  - host: "192.168.1.10"
+warehouse:
+  driver: postgres
+  schema: inventory
+  table_prefix: idrac_
+`))
+	require.NoError(t, err)
+	assert.True(t, cfg.Warehouse.IsEnabled())
+	assert.Equal(t, "postgres://inventory@db/inventory", cfg.Warehouse.GetDSN(), "falls back to IDRAC_WAREHOUSE_DSN")
+	assert.Equal(t, defaults.DefaultWarehouseTimeout, cfg.Warehouse.Timeout())
+	assert.False(t, WarehouseConfig{}.IsEnabled())
+	assert.Empty(t, cfg.Warehouse.Password())
+
+	for dsn, want := range map[string]string{
+		"postgres://inventory:pg-s3cret@db/inventory":  "pg-s3cret",
+		"inventory:my-s3cret@tcp(db:3306)/inventory":   "my-s3cret",
+		"inventory:p@ss@tcp(db:3306)/inventory":        "p@ss",
+		"host=db user=inventory password=kv-s3cret":    "",
+		"inventory@tcp(db:3306)/inventory?parseTime=1": "",
+	} {
+		assert.Equal(t, want, WarehouseConfig{DSN: dsn}.Password(), dsn)
+	}
+	cfg.Warehouse.DSN = "inventory:my-s3cret@tcp(db:3306)/inventory"
+	assert.Contains(t, cfg.Secrets(), "my-s3cret")
+
+	t.Setenv("IDRAC_WAREHOUSE_DSN", "")
+	_, err = Parse([]byte(`
+defaults:
+  username: "root"
+  password: "password"
+servers:
+  - host: "192.168.1.10"
+warehouse:
+  driver: sqlite
+  schema: "inventory; DROP TABLE servers"
+  table_prefix: idrac-
+`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "4 errors")
+}
+
+func TestParse_OutputRaw(t *testing.T) {
+	clearTestEnv(t)
+
+	base := `
+defaults:
+  username: "root"
+  password: "password"
+servers:
+  - host: "192.168.1.10"
+output:
+  raw:
+    enabled: true
+`
+	cfg, err := Parse([]byte(base + `
+    phases: [storage]
+    prune: ["@odata.etag", "Oem"]
+`))
+	require.NoError(t, err)
+	assert.Equal(t, 256*1024, cfg.Output.Raw.GetMaxResourceBytes())
+	assert.Equal(t, 4096*1024, cfg.Output.Raw.GetMaxHostBytes())
+	assert.True(t, cfg.Output.Raw.IncludesPhase("Storage"))
+	assert.False(t, cfg.Output.Raw.IncludesPhase("memory"))
+	assert.Equal(t, []string{"@odata.etag", "Oem"}, cfg.Output.Raw.Prune)
+
+	_, err = Parse([]byte(base + `
+    max_host_kb: -1
+`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "output.raw")
+}
+
+func TestParse_CatalogAliases(t *testing.T) {
+	clearTestEnv(t)
+
+	base := `
+defaults:
+  username: "root"
+  password: "password"
+servers:
+  - host: "192.168.1.10"
+catalog:
+  aliases:
+    "PowerEdge R750 HX": "PowerEdge R750"
+`
+	cfg, err := Parse([]byte(base))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"PowerEdge R750 HX": "PowerEdge R750"}, cfg.Catalog.Aliases)
+
+	_, err = Parse([]byte(base + `    "PowerEdge R760 HX": " "
+`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "catalog.aliases")
+}
+
+func TestParse_Labels(t *testing.T) {
+	clearTestEnv(t)
+
+	cfg, err := Parse([]byte(`
+defaults:
+  username: "root"
+  password: "password"
+servers:
+  - host: "192.168.1.10"
+    labels:
+      environment: lab
+server_groups:
+  - name: "fra1"
+    ip_ranges: ["10.0.0.1-10.0.0.2"]
+    labels:
+      datacenter: fra1
+      environment: prod
+`))
+	require.NoError(t, err)
+	require.Len(t, cfg.Servers, 3)
+	assert.Equal(t, map[string]string{"environment": "lab"}, cfg.Servers[0].Labels)
+	assert.Equal(t, map[string]string{"datacenter": "fra1", "environment": "prod"}, cfg.Servers[2].Labels)
+
+	cfg.Servers[1].Labels["datacenter"] = "changed"
+	assert.Equal(t, "fra1", cfg.Servers[2].Labels["datacenter"], "each server gets its own copy")
+
+	for _, name := range []string{"data-center", "1st", "__name__"} {
+		_, err = Parse([]byte(`
+defaults:
+  username: "root"
+  password: "password"
+servers:
+  - host: "192.168.1.10"
+    labels:
+      ` + name + `: x
+`))
+		require.Error(t, err, name)
+		assert.Contains(t, err.Error(), "server[0].labels")
+	}
+}
+
+func TestMergeLabels(t *testing.T) {
+	assert.Nil(t, MergeLabels(nil, nil))
+	assert.Equal(t, map[string]string{"datacenter": "fra1", "environment": "lab"},
+		MergeLabels(map[string]string{"datacenter": "fra1", "environment": "prod"}, map[string]string{"environment": "lab"}))
+}
+
+func TestParse_NotifySlack(t *testing.T) {
+	clearTestEnv(t)
+
+	base := `
+defaults:
+  username: "root"
+  password: "password"
+servers:
+  - host: "192.168.1.10"
+notify:
+  slack:
+    enabled: true
+`
+	_, err := Parse([]byte(base))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "notify.slack.webhook_url")
+
+	t.Setenv(defaults.EnvSlackWebhookURL, "https://hooks.slack.com/services/T0/B0/x")
+	cfg, err := Parse([]byte(base + `    report_url: "https://ci.example.com/{run_id}"
+`))
+	require.NoError(t, err)
+	assert.Equal(t, "https://hooks.slack.com/services/T0/B0/x", cfg.Notify.Slack.GetWebhookURL())
+	assert.Equal(t, defaults.DefaultSlackMaxItems, cfg.Notify.Slack.GetMaxItems())
+	assert.Equal(t, defaults.DefaultSlackTimeout, cfg.Notify.Slack.Timeout())
+	assert.Contains(t, cfg.Secrets(), "https://hooks.slack.com/services/T0/B0/x")
+}
+
+func TestParse_DaemonBudget(t *testing.T) {
+	clearTestEnv(t)
+
+	base := `
+defaults:
+  username: "root"
+  password: "password"
+servers:
+  - host: "192.168.1.10"
+`
+	cfg, err := Parse([]byte(base + `
+daemon:
+  budget:
+    max_duration: "45m"
+    max_hosts: 200
+    blackout: ["08:00-18:00", "22:00-23:00"]
+    timezone: "UTC"
+`))
+	require.NoError(t, err)
+	b := cfg.Daemon.Budget
+	assert.Equal(t, 45*time.Minute, b.GetMaxDuration())
+	assert.Equal(t, 200, b.MaxHosts)
+	assert.Equal(t, time.UTC, b.Location())
+
+	until, ok := b.BlackoutUntil(time.Date(2025, 3, 4, 9, 30, 0, 0, time.UTC))
+	assert.True(t, ok)
+	assert.Equal(t, time.Date(2025, 3, 4, 18, 0, 0, 0, time.UTC), until)
+	_, ok = b.BlackoutUntil(time.Date(2025, 3, 4, 18, 0, 0, 0, time.UTC))
+	assert.False(t, ok)
+
+	start := time.Date(2025, 3, 4, 21, 30, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2025, 3, 4, 22, 0, 0, 0, time.UTC), b.Deadline(start), "a blackout window ends the cycle")
+	start = time.Date(2025, 3, 4, 2, 0, 0, 0, time.UTC)
+	assert.Equal(t, start.Add(45*time.Minute), b.Deadline(start))
+
+	assert.True(t, BudgetConfig{}.Deadline(start).IsZero(), "no limit by default")
+
+	// A cycle skipped for a blackout resumes when it ends, even with daily
+	// or longer intervals that would land in the window again
+	for _, interval := range []time.Duration{24 * time.Hour, 48 * time.Hour} {
+		assert.Equal(t, 8*time.Hour, b.CycleWait(time.Date(2025, 3, 4, 10, 0, 0, 0, time.UTC), interval))
+		assert.Equal(t, interval, b.CycleWait(time.Date(2025, 3, 4, 19, 0, 0, 0, time.UTC), interval))
+	}
+	assert.Equal(t, time.Hour, b.CycleWait(time.Date(2025, 3, 4, 10, 0, 0, 0, time.UTC), time.Hour))
+
+	for field, value := range map[string]string{
+		"max_duration": `"soon"`,
+		"max_hosts":    "-1",
+		"blackout":     `["8-18"]`,
+		"timezone":     `"Mars/Olympus"`,
+	} {
+		_, err = Parse([]byte(base + "daemon:\n  budget:\n    " + field + ": " + value + "\n"))
+		require.Error(t, err, field)
+		assert.Contains(t, err.Error(), "daemon.budget."+field)
+	}
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+)
+
+// Shard selection methods.
+const (
+	ShardByHash  = "hash"  // by a hash of the host: stable when servers are added or removed
+	ShardByIndex = "index" // by position in the server list: evenly sized shards
+)
+
+// Shard is one of Count disjoint subsets of the configured servers. Index is
+// 1-based, as in "-shard 2/5".
+type Shard struct {
+	Index int
+	Count int
+	By    string
+}
+
+// ParseShard parses a shard spec such as "2/5" with the selection method by
+// (hash if empty). An empty spec selects all servers (1/1).
+func ParseShard(spec, by string) (Shard, error) {
+	switch by {
+	case "":
+		by = ShardByHash
+	case ShardByHash, ShardByIndex:
+	default:
+		return Shard{}, fmt.Errorf("invalid shard_by %q (must be %s or %s)", by, ShardByHash, ShardByIndex)
+	}
+	if spec == "" {
+		return Shard{Index: 1, Count: 1, By: by}, nil
+	}
+
+	i, n, ok := strings.Cut(spec, "/")
+	index, err1 := strconv.Atoi(strings.TrimSpace(i))
+	count, err2 := strconv.Atoi(strings.TrimSpace(n))
+	if !ok || err1 != nil || err2 != nil || count < 1 || index < 1 || index > count {
+		return Shard{}, fmt.Errorf("invalid shard %q (use K/N with 1 ≤ K ≤ N, e.g. 2/5)", spec)
+	}
+	return Shard{Index: index, Count: count, By: by}, nil
+}
+
+// String returns the shard as "K/N".
+func (s Shard) String() string {
+	return fmt.Sprintf("%d/%d", s.Index, s.Count)
+}
+
+// IsAll reports whether the shard selects all servers.
+func (s Shard) IsAll() bool {
+	return s.Count <= 1
+}
+
+// Filter returns the servers that belong to the shard. Every server belongs
+// to exactly one of the shards 1/N … N/N.
+func (s Shard) Filter(servers []ServerConfig) []ServerConfig {
+	if s.IsAll() {
+		return servers
+	}
+	var kept []ServerConfig
+	for i, srv := range servers {
+		pos := i
+		if s.By != ShardByIndex {
+			h := fnv.New32a()
+			h.Write([]byte(strings.ToLower(srv.Host)))
+			pos = int(h.Sum32() % uint32(s.Count))
+		}
+		if pos%s.Count == s.Index-1 {
+			kept = append(kept, srv)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseShard(t *testing.T) {
+	s, err := ParseShard("2/5", "")
+	require.NoError(t, err)
+	assert.Equal(t, Shard{Index: 2, Count: 5, By: ShardByHash}, s)
+	assert.Equal(t, "2/5", s.String())
+
+	s, err = ParseShard("", "index")
+	require.NoError(t, err)
+	assert.True(t, s.IsAll())
+
+	for _, spec := range []string{"2", "0/5", "6/5", "a/b", "1/0"} {
+		_, err := ParseShard(spec, "")
+		assert.Error(t, err, spec)
+	}
+	_, err = ParseShard("1/2", "random")
+	assert.Error(t, err)
+}
+
+func TestShard_Filter(t *testing.T) {
+	var servers []ServerConfig
+	for i := 0; i < 100; i++ {
+		servers = append(servers, ServerConfig{Host: fmt.Sprintf("10.0.0.%d", i)})
+	}
+
+	for _, by := range []string{ShardByHash, ShardByIndex} {
+		seen := map[string]int{}
+		for k := 1; k <= 3; k++ {
+			shard, err := ParseShard(fmt.Sprintf("%d/3", k), by)
+			require.NoError(t, err)
+			part := shard.Filter(servers)
+			assert.NotEmpty(t, part, by)
+			for _, srv := range part {
+				seen[srv.Host]++
+			}
+		}
+		assert.Len(t, seen, len(servers), "%s: every server is in a shard", by)
+		for host, n := range seen {
+			assert.Equal(t, 1, n, "%s: %s is in exactly one shard", by, host)
+		}
+	}
+
+	// Hash sharding does not depend on the position in the list
+	shard, _ := ParseShard("1/3", ShardByHash)
+	assert.Equal(t, shard.Filter(servers[:50]), shard.Filter(servers)[:len(shard.Filter(servers[:50]))])
+
+	index, _ := ParseShard("2/3", ShardByIndex)
+	assert.Equal(t, "10.0.0.1", index.Filter(servers)[0].Host)
+}
+
+func TestParse_Shard(t *testing.T) {
+	clearTestEnv(t)
+
+	_, err := Parse([]byte(`
+defaults:
+  username: "root"
+  password: "password"
+servers:
+  - host: "192.168.1.10"
+shard: "3/2"
+`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "shard")
+}
@@ -0,0 +1,103 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/braunma/idrac-netbox-importer/internal/csvtable"
+	"github.com/braunma/idrac-netbox-importer/pkg/errors"
+)
+
+// loadTargets appends the servers of the targets file to the servers.
+func (c *Config) loadTargets() error {
+	if c.Targets == "" {
+		return nil
+	}
+	f, err := os.Open(c.Targets)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	servers, err := ParseTargets(f, c.ServerGroups)
+	if err != nil {
+		return fmt.Errorf("%s: %w", c.Targets, err)
+	}
+	c.Servers = append(c.Servers, servers...)
+	return nil
+}
+
+// ParseTargets reads servers from CSV with a header row. Columns are matched
+// by name (host, name, username, password, group; case-insensitive) and
+// unknown columns, such as the rest of a DCIM export, are ignored. Rows are
+// expanded like server_groups: host may be an IP range, and a row of a
+// group gets the name "<group> - <host>" unless it has its own. group must
+// name one of groups; the row inherits the labels and the credentials, TLS
+// and timeout settings of that group that it does not set itself. Lines
+// starting with # are comments.
+func ParseTargets(r io.Reader, groups []ServerGroup) ([]ServerConfig, error) {
+	t, err := csvtable.Read(r, "targets")
+	if err != nil {
+		return nil, err
+	}
+	if err := t.Require("host"); err != nil {
+		return nil, err
+	}
+
+	byName := map[string]ServerGroup{}
+	for _, g := range groups {
+		if g.Name != "" {
+			byName[g.Name] = g
+		}
+	}
+
+	var servers []ServerConfig
+	for n := 0; n < t.Len(); n++ {
+		get := func(col string) string { return t.Get(n, col) }
+
+		host := get("host")
+		if host == "" {
+			return nil, fmt.Errorf("row %d: host is required", n+1)
+		}
+		hosts := []string{host}
+		if isIPRange(host) {
+			if hosts, err = ParseIPRange(host); err != nil {
+				return nil, fmt.Errorf("row %d: %w", n+1, err)
+			}
+		}
+
+		group := get("group")
+		g, ok := byName[group]
+		if group != "" && !ok {
+			return nil, errors.NewConfigError("targets", fmt.Sprintf("row %d (%s): unknown group %q, not in server_groups", n+1, host, group))
+		}
+		for _, ip := range hosts {
+			srv := ServerConfig{
+				Host:               ip,
+				Name:               get("name"),
+				Username:           getStringOrDefault(get("username"), g.Username),
+				Password:           getStringOrDefault(get("password"), g.Password),
+				InsecureSkipVerify: g.InsecureSkipVerify,
+				TimeoutSeconds:     g.TimeoutSeconds,
+				ClientCert:         g.ClientCert,
+				ClientKey:          g.ClientKey,
+				Labels:             MergeLabels(g.Labels, nil),
+			}
+			if srv.Name == "" && group != "" {
+				srv.Name = fmt.Sprintf("%s - %s", group, ip)
+			}
+			servers = append(servers, srv)
+		}
+	}
+	return servers, nil
+}
+
+// isIPRange reports whether host is an IP range such as
+// "10.0.0.1-10.0.0.9" rather than a host name containing a dash.
+func isIPRange(host string) bool {
+	start, end, ok := strings.Cut(host, "-")
+	return ok && net.ParseIP(strings.TrimSpace(start)) != nil && net.ParseIP(strings.TrimSpace(end)) != nil
+}
@@ -0,0 +1,153 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/errors"
+)
+
+func TestParseTargets(t *testing.T) {
+	insecure := true
+	timeout := 90
+	groups := []ServerGroup{{
+		Name:               "rack-a",
+		Username:           "svc",
+		Password:           "group-pass",
+		InsecureSkipVerify: &insecure,
+		TimeoutSeconds:     &timeout,
+		Labels:             map[string]string{"datacenter": "fra1"},
+	}}
+
+	csv := `# exported from DCIM
+Host, Name, Username, Password, Group, Rack
+10.0.0.1,db-01,root,secret,,A1
+idrac-web-01.example.com,,,,rack-a,A2
+10.0.1.1-10.0.1.3,,,own-pass,rack-a,A3
+`
+	servers, err := ParseTargets(strings.NewReader(csv), groups)
+	require.NoError(t, err)
+	require.Len(t, servers, 5)
+
+	assert.Equal(t, ServerConfig{Host: "10.0.0.1", Name: "db-01", Username: "root", Password: "secret"}, servers[0])
+
+	web := servers[1]
+	assert.Equal(t, "idrac-web-01.example.com", web.Host, "host names with dashes are not ranges")
+	assert.Equal(t, "rack-a - idrac-web-01.example.com", web.Name)
+	assert.Equal(t, "svc", web.Username, "inherited from the group")
+	assert.Equal(t, "group-pass", web.Password)
+	assert.Equal(t, &insecure, web.InsecureSkipVerify)
+	assert.Equal(t, &timeout, web.TimeoutSeconds)
+	assert.Equal(t, map[string]string{"datacenter": "fra1"}, web.Labels)
+
+	assert.Equal(t, "10.0.1.1", servers[2].Host)
+	assert.Equal(t, "10.0.1.3", servers[4].Host)
+	assert.Equal(t, "rack-a - 10.0.1.3", servers[4].Name)
+	assert.Equal(t, "own-pass", servers[4].Password, "row credentials win over the group")
+	assert.Equal(t, "svc", servers[4].Username)
+}
+
+func TestParseTargets_Errors(t *testing.T) {
+	servers, err := ParseTargets(strings.NewReader(""), nil)
+	require.NoError(t, err)
+	assert.Empty(t, servers)
+
+	_, err = ParseTargets(strings.NewReader("name,username\nweb,root\n"), nil)
+	assert.ErrorContains(t, err, "no host column")
+
+	_, err = ParseTargets(strings.NewReader("host,name\n10.0.0.1,a\n,b\n"), nil)
+	assert.ErrorContains(t, err, "row 2: host is required")
+
+	_, err = ParseTargets(strings.NewReader("host\n10.0.0.9-10.0.0.1\n"), nil)
+	assert.ErrorContains(t, err, "row 1")
+
+	_, err = ParseTargets(strings.NewReader("host,group\n10.0.0.1,rack-a\n10.0.2.1,lab\n"), []ServerGroup{{Name: "rack-a"}})
+	var cfgErr *errors.ConfigError
+	require.ErrorAs(t, err, &cfgErr)
+	assert.Equal(t, "targets", cfgErr.Field)
+	assert.ErrorContains(t, err, `row 2 (10.0.2.1): unknown group "lab"`)
+}
+
+func TestLoadProfileTargets(t *testing.T) {
+	clearTestEnv(t)
+	dir := t.TempDir()
+	targets := filepath.Join(dir, "hosts.csv")
+	require.NoError(t, os.WriteFile(targets, []byte("host,group\n10.0.0.5,rack-a\n"), 0o600))
+	other := filepath.Join(dir, "other.csv")
+	require.NoError(t, os.WriteFile(other, []byte("host\n10.0.0.9\n"), 0o600))
+
+	path := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+defaults:
+  username: "root"
+  password: "password"
+servers:
+  - host: "10.0.0.1"
+server_groups:
+  - name: "rack-a"
+    ip_ranges: ["10.0.0.2"]
+    password: "rack-pass"
+targets: `+targets+`
+profiles:
+  lab:
+    servers:
+      - host: "10.9.0.1"
+`), 0o600))
+
+	cfg, err := LoadProfile(path, "")
+	require.NoError(t, err)
+	hosts := make([]string, len(cfg.Servers))
+	for i, srv := range cfg.Servers {
+		hosts[i] = srv.Host
+	}
+	assert.Equal(t, []string{"10.0.0.1", "10.0.0.2", "10.0.0.5"}, hosts, "appended after the server groups")
+	assert.Equal(t, "rack-pass", cfg.Servers[2].Password)
+
+	cfg, err = LoadProfileTargets(path, "", other)
+	require.NoError(t, err)
+	require.Len(t, cfg.Servers, 3)
+	assert.Equal(t, "10.0.0.9", cfg.Servers[2].Host, "-targets overrides targets")
+
+	cfg, err = LoadProfile(path, "lab")
+	require.NoError(t, err)
+	require.Len(t, cfg.Servers, 1, "a profile with servers replaces the targets")
+
+	_, err = LoadProfileTargets(path, "", filepath.Join(dir, "missing.csv"))
+	assert.ErrorContains(t, err, "failed to load targets")
+}
+
+func TestLoadProfile_RelativeTargets(t *testing.T) {
+	clearTestEnv(t)
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "hosts.csv"), []byte("host\n10.0.0.5\n"), 0o600))
+	path := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+defaults:
+  username: "root"
+  password: "password"
+targets: hosts.csv
+`), 0o600))
+
+	// Found next to the config file, not in the working directory
+	cfg, err := LoadProfile(path, "")
+	require.NoError(t, err)
+	require.Len(t, cfg.Servers, 1)
+	assert.Equal(t, "10.0.0.5", cfg.Servers[0].Host)
+	assert.Equal(t, filepath.Join(dir, "hosts.csv"), cfg.Targets)
+}
+
+func TestParse_OnlyTargets(t *testing.T) {
+	clearTestEnv(t)
+	targets := filepath.Join(t.TempDir(), "hosts.csv")
+	require.NoError(t, os.WriteFile(targets, []byte("host,username,password\n10.0.0.5,root,secret\n"), 0o600))
+
+	cfg, err := Parse([]byte("targets: " + targets + "\n"))
+	require.NoError(t, err)
+	require.Len(t, cfg.Servers, 1)
+	assert.Equal(t, "secret", cfg.Servers[0].GetPassword(""))
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ClockWindow is a daily time window such as "08:00-18:00". A window whose
+// end is before its start spans midnight ("22:00-06:00").
+type ClockWindow struct {
+	Start time.Duration // since midnight
+	End   time.Duration // since midnight
+}
+
+// ParseClockWindow parses a window in the form "HH:MM-HH:MM".
+func ParseClockWindow(s string) (ClockWindow, error) {
+	start, end, ok := strings.Cut(s, "-")
+	from, err1 := parseClock(start)
+	to, err2 := parseClock(end)
+	if !ok || err1 != nil || err2 != nil || from == to {
+		return ClockWindow{}, fmt.Errorf("invalid window %q (use HH:MM-HH:MM, e.g. 08:00-18:00)", s)
+	}
+	return ClockWindow{Start: from, End: to}, nil
+}
+
+// parseClock parses a time of day ("HH:MM", "24:00" for midnight at the end
+// of a window) into the time since midnight.
+func parseClock(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "24:00" {
+		return 24 * time.Hour, nil
+	}
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// String returns the window as "HH:MM-HH:MM".
+func (w ClockWindow) String() string {
+	clock := func(d time.Duration) string {
+		return fmt.Sprintf("%02d:%02d", int(d/time.Hour), int(d%time.Hour/time.Minute))
+	}
+	return clock(w.Start) + "-" + clock(w.End)
+}
+
+// sinceMidnight returns the time of day of t in its location.
+func sinceMidnight(t time.Time) time.Duration {
+	h, m, s := t.Clock()
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute + time.Duration(s)*time.Second
+}
+
+// clockAt returns the time of day d on the day of t plus days, in the
+// location of t (so windows keep their wall clock times across DST changes).
+func clockAt(t time.Time, days int, d time.Duration) time.Time {
+	y, m, day := t.Date()
+	return time.Date(y, m, day+days, int(d/time.Hour), int(d%time.Hour/time.Minute), 0, 0, t.Location())
+}
+
+// Contains reports whether t, in its location, is inside the window.
+func (w ClockWindow) Contains(t time.Time) bool {
+	d := sinceMidnight(t)
+	if w.Start < w.End {
+		return d >= w.Start && d < w.End
+	}
+	return d >= w.Start || d < w.End
+}
+
+// EndAfter returns when the window that contains t ends.
+func (w ClockWindow) EndAfter(t time.Time) time.Time {
+	if w.Start > w.End && sinceMidnight(t) >= w.Start {
+		return clockAt(t, 1, w.End)
+	}
+	return clockAt(t, 0, w.End)
+}
+
+// NextStart returns the first start of the window after t.
+func (w ClockWindow) NextStart(t time.Time) time.Time {
+	if start := clockAt(t, 0, w.Start); start.After(t) {
+		return start
+	}
+	return clockAt(t, 1, w.Start)
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseClockWindow(t *testing.T) {
+	w, err := ParseClockWindow("08:00-18:30")
+	require.NoError(t, err)
+	assert.Equal(t, ClockWindow{Start: 8 * time.Hour, End: 18*time.Hour + 30*time.Minute}, w)
+	assert.Equal(t, "08:00-18:30", w.String())
+
+	w, err = ParseClockWindow("18:00 - 24:00")
+	require.NoError(t, err)
+	assert.Equal(t, 24*time.Hour, w.End)
+
+	for _, s := range []string{"", "08:00", "8-18", "08:00-08:00", "25:00-26:00"} {
+		_, err := ParseClockWindow(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestClockWindow(t *testing.T) {
+	at := func(day, h, m int) time.Time { return time.Date(2025, 3, day, h, m, 0, 0, time.UTC) }
+
+	day := ClockWindow{Start: 8 * time.Hour, End: 18 * time.Hour}
+	assert.False(t, day.Contains(at(4, 7, 59)))
+	assert.True(t, day.Contains(at(4, 8, 0)))
+	assert.False(t, day.Contains(at(4, 18, 0)))
+	assert.Equal(t, at(4, 18, 0), day.EndAfter(at(4, 9, 0)))
+	assert.Equal(t, at(4, 8, 0), day.NextStart(at(4, 7, 0)))
+	assert.Equal(t, at(5, 8, 0), day.NextStart(at(4, 8, 0)))
+
+	night := ClockWindow{Start: 22 * time.Hour, End: 6 * time.Hour}
+	assert.True(t, night.Contains(at(4, 23, 0)))
+	assert.True(t, night.Contains(at(5, 1, 0)))
+	assert.False(t, night.Contains(at(5, 6, 0)))
+	assert.Equal(t, at(5, 6, 0), night.EndAfter(at(4, 23, 0)), "ends the next morning")
+	assert.Equal(t, at(5, 6, 0), night.EndAfter(at(5, 1, 0)))
+}
@@ -15,6 +15,8 @@ const (
 	// Application
 	EnvLogLevel  = "IDRAC_LOG_LEVEL"
 	EnvLogFormat = "IDRAC_LOG_FORMAT"
+	EnvProfile   = "IDRAC_PROFILE"
+	EnvShard     = "IDRAC_SHARD"
 
 	// iDRAC Connection
 	EnvDefaultUsername    = "IDRAC_DEFAULT_USER"
@@ -29,6 +31,8 @@ const (
 	EnvNetBoxTimeout            = "NETBOX_TIMEOUT"
 	EnvNetBoxInsecureSkipVerify = "NETBOX_INSECURE_SKIP_VERIFY"
 	EnvNetBoxCACert             = "CA_Chain"
+	EnvNetBoxOAuth2ClientID     = "NETBOX_OAUTH2_CLIENT_ID"
+	EnvNetBoxOAuth2ClientSecret = "NETBOX_OAUTH2_CLIENT_SECRET"
 
 	// HTTP Client
 	EnvHTTPMaxIdleConns    = "HTTP_MAX_IDLE_CONNS"
@@ -38,6 +42,21 @@ const (
 	EnvRetryMaxAttempts = "IDRAC_RETRY_MAX_ATTEMPTS"
 	EnvRetryBaseDelay   = "IDRAC_RETRY_BASE_DELAY"
 	EnvRetryMaxDelay    = "IDRAC_RETRY_MAX_DELAY"
+
+	// S3 output sinks without configured credentials
+	EnvAWSAccessKeyID     = "AWS_ACCESS_KEY_ID"
+	EnvAWSSecretAccessKey = "AWS_SECRET_ACCESS_KEY"
+	EnvAWSSessionToken    = "AWS_SESSION_TOKEN"
+
+	// Connection string of the SQL warehouse
+	EnvWarehouseDSN = "IDRAC_WAREHOUSE_DSN"
+
+	// Client credentials of the Dell warranty API
+	EnvWarrantyClientID     = "IDRAC_WARRANTY_CLIENT_ID"
+	EnvWarrantyClientSecret = "IDRAC_WARRANTY_CLIENT_SECRET"
+
+	// Incoming webhook of the Slack run summaries
+	EnvSlackWebhookURL = "IDRAC_SLACK_WEBHOOK_URL"
 )
 
 // Default values - these are used when no environment variable or config is set.
@@ -67,22 +86,144 @@ var (
 	DefaultRetryMaxAttempts = getEnvOrDefaultInt(EnvRetryMaxAttempts, 3)
 	DefaultRetryBaseDelay   = getEnvOrDefaultDuration(EnvRetryBaseDelay, 1*time.Second)
 	DefaultRetryMaxDelay    = getEnvOrDefaultDuration(EnvRetryMaxDelay, 30*time.Second)
+
+	// Rescan (second pass over failed hosts) defaults
+	DefaultRescanTimeoutMultiplier = 2
+	DefaultRescanConcurrency       = 2
+
+	// Daemon mode defaults
+	DefaultDaemonInterval = 1 * time.Hour
+
+	// Per-host backoff of failing hosts in daemon mode; the schedule is kept
+	// in this file in the state directory
+	DefaultBackoffMaxInterval = 24 * time.Hour
+	DefaultQuarantineAfter    = 10
+	DefaultScheduleFile       = "scan-schedule.json"
+
+	// History (last known good inventory) file in the state directory
+	DefaultHistoryFile = "last-known-good.json"
+
+	// Hosts whose Lifecycle Controller reports no change are still fully
+	// scanned once this long has passed since their last full scan
+	DefaultFullScanAfter = 7 * 24 * time.Hour
+
+	// Redfish events of daemon mode: hosts are rescanned once no further
+	// event arrived for this long
+	DefaultEventDebounce = 30 * time.Second
+
+	// iDRAC clocks further off the local clock than this are flagged
+	DefaultMaxClockDrift = 2 * time.Minute
+
+	// Daily fleet totals for the trend report in the state directory
+	DefaultTrendsFile = "trends.json"
+
+	// Redfish ETag cache file in the cache directory
+	DefaultETagCacheFile = "redfish-etags.json"
+
+	// Responses kept in the ETag cache; the least recently used are dropped
+	DefaultETagCacheMaxEntries = 50000
+
+	// Custom field on NetBox power feeds for the summed measured draw
+	DefaultPowerFeedField = "measured_draw_watts"
+
+	// Timeout of each hook command
+	DefaultHookTimeout = 5 * time.Minute
+
+	// Servers per file for chunked JSON output (-output-dir)
+	DefaultOutputChunkSize = 1000
+
+	// Size limits, after pruning, of the raw Redfish responses in the JSON
+	// output (output.raw): per response and per host
+	DefaultRawMaxResourceKB = 256
+	DefaultRawMaxHostKB     = 4096
+
+	// Timeout of each output sink upload and the region of S3 sinks
+	DefaultSinkTimeout = time.Minute
+	DefaultS3Region    = "us-east-1"
+
+	// Timeout of each SQL warehouse export
+	DefaultWarehouseTimeout = 2 * time.Minute
+
+	// Name (tag) and lease TTL of the advisory NetBox sync lock
+	DefaultNetBoxLockName = "idrac-inventory-sync-lock"
+	DefaultNetBoxLockTTL  = time.Hour
+
+	// File (in the cache directory) and entry TTL of the NetBox device ID cache
+	DefaultNetBoxDeviceCacheFile = "netbox-devices.json"
+	DefaultNetBoxDeviceCacheTTL  = 24 * time.Hour
+
+	// NetBox device filters of the bulk device listing (netbox.match)
+	DefaultNetBoxMatchFilter = "manufacturer=dell"
+
+	// Asset tag and serial lookups per NetBox GraphQL query; each is an alias
+	// in the query string of a GET request, which limits the URL length
+	DefaultNetBoxGraphQLBatchSize = 60
+
+	// Timeout of the NetBox token_command
+	DefaultNetBoxTokenCommandTimeout = 30 * time.Second
+
+	// Dell warranty API (asset entitlements) for ship dates, at most
+	// DefaultWarrantyBatchSize service tags per request
+	DefaultWarrantyURL       = "https://apigtwb2c.us.dell.com/PROD/sbil/eapi/v5/asset-entitlements"
+	DefaultWarrantyTokenURL  = "https://apigtwb2c.us.dell.com/auth/oauth/v2/token"
+	DefaultWarrantyTimeout   = 30 * time.Second
+	DefaultWarrantyBatchSize = 100
+
+	// Timeout of the Slack webhook request and the number of failure and
+	// drift lines in the summary
+	DefaultSlackTimeout  = 15 * time.Second
+	DefaultSlackMaxItems = 5
+
+	// User-Agent sent to iDRAC and NetBox; {version} is replaced with Version
+	DefaultUserAgent = "github.com/braunma/idrac-netbox-importer/{version}"
 )
 
+// Version is the running tool version, used in the User-Agent header.
+// It is set by main from the build information.
+var Version = "dev"
+
 // Redfish API paths - centralized for easy maintenance
 var (
 	RedfishBasePath       = getEnvOrDefault("REDFISH_BASE_PATH", "/redfish/v1")
+	RedfishSystemsPath    = getEnvOrDefault("REDFISH_SYSTEMS_PATH", "/redfish/v1/Systems")
 	RedfishSystemPath     = getEnvOrDefault("REDFISH_SYSTEM_PATH", "/redfish/v1/Systems/System.Embedded.1")
 	RedfishProcessorsPath = getEnvOrDefault("REDFISH_PROCESSORS_PATH", "/redfish/v1/Systems/System.Embedded.1/Processors")
 	RedfishMemoryPath     = getEnvOrDefault("REDFISH_MEMORY_PATH", "/redfish/v1/Systems/System.Embedded.1/Memory")
 	RedfishStoragePath    = getEnvOrDefault("REDFISH_STORAGE_PATH", "/redfish/v1/Systems/System.Embedded.1/Storage")
 	RedfishPowerPath      = getEnvOrDefault("REDFISH_POWER_PATH", "/redfish/v1/Chassis/System.Embedded.1/Power")
+	RedfishPCIeSlotsPath  = getEnvOrDefault("REDFISH_PCIE_SLOTS_PATH", "/redfish/v1/Chassis/System.Embedded.1/PCIeSlots")
+	RedfishManagerPath    = getEnvOrDefault("REDFISH_MANAGER_PATH", "/redfish/v1/Managers/iDRAC.Embedded.1")
+	RedfishAccountsPath   = getEnvOrDefault("REDFISH_ACCOUNTS_PATH", "/redfish/v1/AccountService/Accounts")
+)
+
+// Redfish EventService paths
+var (
+	RedfishEventSubscriptionsPath = getEnvOrDefault("REDFISH_EVENT_SUBSCRIPTIONS_PATH", "/redfish/v1/EventService/Subscriptions")
+)
+
+// Dell OEM Redfish paths
+var (
+	RedfishDellLicensesPath = getEnvOrDefault("REDFISH_DELL_LICENSES_PATH", "/redfish/v1/Managers/iDRAC.Embedded.1/Oem/Dell/DellLicenses")
 )
 
 // NetBox API paths
 var (
-	NetBoxDevicesPath = getEnvOrDefault("NETBOX_DEVICES_PATH", "/api/dcim/devices/")
-	NetBoxStatusPath  = getEnvOrDefault("NETBOX_STATUS_PATH", "/api/status/")
+	NetBoxDevicesPath            = getEnvOrDefault("NETBOX_DEVICES_PATH", "/api/dcim/devices/")
+	NetBoxDeviceTypesPath        = getEnvOrDefault("NETBOX_DEVICE_TYPES_PATH", "/api/dcim/device-types/")
+	NetBoxStatusPath             = getEnvOrDefault("NETBOX_STATUS_PATH", "/api/status/")
+	NetBoxModuleBaysPath         = getEnvOrDefault("NETBOX_MODULE_BAYS_PATH", "/api/dcim/module-bays/")
+	NetBoxModulesPath            = getEnvOrDefault("NETBOX_MODULES_PATH", "/api/dcim/modules/")
+	NetBoxModuleTypesPath        = getEnvOrDefault("NETBOX_MODULE_TYPES_PATH", "/api/dcim/module-types/")
+	NetBoxManufacturersPath      = getEnvOrDefault("NETBOX_MANUFACTURERS_PATH", "/api/dcim/manufacturers/")
+	NetBoxPowerPortsPath         = getEnvOrDefault("NETBOX_POWER_PORTS_PATH", "/api/dcim/power-ports/")
+	NetBoxPowerPortTemplatesPath = getEnvOrDefault("NETBOX_POWER_PORT_TEMPLATES_PATH", "/api/dcim/power-port-templates/")
+	NetBoxPowerFeedsPath         = getEnvOrDefault("NETBOX_POWER_FEEDS_PATH", "/api/dcim/power-feeds/")
+	NetBoxSitesPath              = getEnvOrDefault("NETBOX_SITES_PATH", "/api/dcim/sites/")
+	NetBoxRacksPath              = getEnvOrDefault("NETBOX_RACKS_PATH", "/api/dcim/racks/")
+	NetBoxTenantsPath            = getEnvOrDefault("NETBOX_TENANTS_PATH", "/api/tenancy/tenants/")
+	NetBoxJournalEntriesPath     = getEnvOrDefault("NETBOX_JOURNAL_ENTRIES_PATH", "/api/extras/journal-entries/")
+	NetBoxTagsPath               = getEnvOrDefault("NETBOX_TAGS_PATH", "/api/extras/tags/")
+	NetBoxGraphQLPath            = getEnvOrDefault("NETBOX_GRAPHQL_PATH", "/graphql/")
 )
 
 // NetBox custom field names - configurable for different NetBox setups
@@ -109,6 +250,21 @@ var (
 	NetBoxFieldGPUCount    = getEnvOrDefault("NETBOX_FIELD_GPU_COUNT", "hw_gpu_count")
 	NetBoxFieldGPUModel    = getEnvOrDefault("NETBOX_FIELD_GPU_MODEL", "hw_gpu_model")
 	NetBoxFieldGPUMemoryGB = getEnvOrDefault("NETBOX_FIELD_GPU_MEMORY_GB", "hw_gpu_memory_gb")
+
+	// iDRAC license level (Express, Enterprise, Datacenter)
+	NetBoxFieldIDRACLicense = getEnvOrDefault("NETBOX_FIELD_IDRAC_LICENSE", "hw_idrac_license")
+
+	// DNS name of the iDRAC (FQDN, or host name without domain)
+	NetBoxFieldIDRACDNSName = getEnvOrDefault("NETBOX_FIELD_IDRAC_DNS_NAME", "hw_idrac_dns_name")
+
+	// ID of the run that last synced the device (see pkg/runid)
+	NetBoxFieldLastScanID = getEnvOrDefault("NETBOX_FIELD_LAST_SCAN_ID", "hw_last_scan_id")
+
+	// Manufacture date of the server (a date field; netbox.sync_manufacture_date)
+	NetBoxFieldManufactureDate = getEnvOrDefault("NETBOX_FIELD_MANUFACTURE_DATE", "hw_manufacture_date")
+
+	// Whether the server meets the firmware policy (a boolean field; netbox.sync_firmware_compliance)
+	NetBoxFieldFirmwareCompliant = getEnvOrDefault("NETBOX_FIELD_FIRMWARE_COMPLIANT", "hw_firmware_compliant")
 )
 
 // Helper functions for reading environment variables with defaults
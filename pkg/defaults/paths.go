@@ -0,0 +1,69 @@
+package defaults
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// AppName is used for state/cache directory names and the default service name.
+const AppName = "idrac-inventory"
+
+// Environment variables for state and cache directories.
+const (
+	EnvStateDir = "IDRAC_STATE_DIR"
+	EnvCacheDir = "IDRAC_CACHE_DIR"
+
+	// Set by systemd for units with StateDirectory= / CacheDirectory=.
+	envSystemdStateDir = "STATE_DIRECTORY"
+	envSystemdCacheDir = "CACHE_DIRECTORY"
+)
+
+// StateDir returns the directory for persistent state (last results, history).
+// Resolution order: IDRAC_STATE_DIR, systemd STATE_DIRECTORY, $XDG_STATE_HOME/idrac-inventory,
+// ~/.local/state/idrac-inventory. On Windows: %LOCALAPPDATA%\idrac-inventory\state.
+func StateDir() string {
+	if dir := os.Getenv(EnvStateDir); dir != "" {
+		return dir
+	}
+	if dir := firstPath(os.Getenv(envSystemdStateDir)); dir != "" {
+		return dir
+	}
+	if runtime.GOOS == "windows" {
+		if base := os.Getenv("LOCALAPPDATA"); base != "" {
+			return filepath.Join(base, AppName, "state")
+		}
+	}
+	if base := os.Getenv("XDG_STATE_HOME"); base != "" {
+		return filepath.Join(base, AppName)
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(home, ".local", "state", AppName)
+	}
+	return filepath.Join(os.TempDir(), AppName, "state")
+}
+
+// CacheDir returns the directory for disposable cached data.
+// Resolution order: IDRAC_CACHE_DIR, systemd CACHE_DIRECTORY, the OS user cache
+// directory ($XDG_CACHE_HOME or ~/.cache on Linux, %LOCALAPPDATA% on Windows).
+func CacheDir() string {
+	if dir := os.Getenv(EnvCacheDir); dir != "" {
+		return dir
+	}
+	if dir := firstPath(os.Getenv(envSystemdCacheDir)); dir != "" {
+		return dir
+	}
+	if base, err := os.UserCacheDir(); err == nil {
+		return filepath.Join(base, AppName)
+	}
+	return filepath.Join(os.TempDir(), AppName, "cache")
+}
+
+// firstPath returns the first entry of a colon-separated systemd directory list.
+func firstPath(list string) string {
+	if i := strings.IndexByte(list, ':'); i >= 0 {
+		return list[:i]
+	}
+	return list
+}
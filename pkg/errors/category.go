@@ -0,0 +1,111 @@
+package errors
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"net"
+	"strings"
+)
+
+// Category classifies an error by its likely root cause.
+type Category string
+
+// Error categories used in scan statistics.
+const (
+	CategoryAuth            Category = "auth"
+	CategoryTimeout         Category = "timeout"
+	CategoryTLS             Category = "tls"
+	CategoryDNS             Category = "dns"
+	CategoryConnection      Category = "connection"
+	CategoryEndpointMissing Category = "endpoint_missing"
+	CategoryParse           Category = "parse"
+	CategoryBMCBusy         Category = "bmc_busy"
+	CategoryHTTP            Category = "http_error"
+	CategoryCanceled        Category = "canceled"
+	CategoryOther           Category = "other"
+)
+
+// Categorize returns the category of err based on the error types and sentinels
+// in the chain. Errors that were flattened to strings are matched on well-known
+// message fragments as a fallback. Returns an empty category for a nil error.
+func Categorize(err error) Category {
+	if err == nil {
+		return ""
+	}
+
+	var (
+		rfErr        *RedfishError
+		busyErr      *BMCBusyError
+		dnsErr       *net.DNSError
+		netErr       net.Error
+		opErr        *net.OpError
+		syntaxErr    *json.SyntaxError
+		typeErr      *json.UnmarshalTypeError
+		certErr      *tls.CertificateVerificationError
+		recordErr    tls.RecordHeaderError
+		authorityErr x509.UnknownAuthorityError
+		hostnameErr  x509.HostnameError
+		invalidErr   x509.CertificateInvalidError
+	)
+
+	switch {
+	case errors.Is(err, ErrAuthenticationFailed):
+		return CategoryAuth
+	case errors.As(err, &rfErr) && rfErr.IsAuthError():
+		return CategoryAuth
+	case errors.Is(err, ErrNotFound):
+		return CategoryEndpointMissing
+	case errors.As(err, &rfErr) && (rfErr.IsNotFound() || rfErr.IsNotImplemented()):
+		return CategoryEndpointMissing
+	case errors.Is(err, context.Canceled):
+		return CategoryCanceled
+	case errors.As(err, &dnsErr):
+		return CategoryDNS
+	case errors.As(err, &certErr), errors.As(err, &recordErr), errors.As(err, &authorityErr),
+		errors.As(err, &hostnameErr), errors.As(err, &invalidErr):
+		return CategoryTLS
+	case errors.Is(err, ErrTimeout), errors.Is(err, context.DeadlineExceeded):
+		return CategoryTimeout
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return CategoryTimeout
+	case errors.As(err, &syntaxErr), errors.As(err, &typeErr), errors.Is(err, ErrInvalidResponse):
+		return CategoryParse
+	case errors.Is(err, ErrConnectionFailed), errors.As(err, &opErr):
+		return CategoryConnection
+	case errors.As(err, &busyErr):
+		return CategoryBMCBusy
+	case errors.As(err, &rfErr) && rfErr.StatusCode >= 400:
+		return CategoryHTTP
+	}
+
+	return categorizeMessage(err.Error())
+}
+
+// categorizeMessage matches well-known message fragments for errors whose type
+// information was lost (e.g. wrapped with %v or transported as strings).
+func categorizeMessage(msg string) Category {
+	msg = strings.ToLower(msg)
+	switch {
+	case strings.Contains(msg, "authentication failed"), strings.Contains(msg, "unauthorized"):
+		return CategoryAuth
+	case strings.Contains(msg, "bmc busy"):
+		return CategoryBMCBusy
+	case strings.Contains(msg, "no such host"), strings.Contains(msg, "server misbehaving"):
+		return CategoryDNS
+	case strings.Contains(msg, "x509:"), strings.Contains(msg, "tls:"), strings.Contains(msg, "certificate"):
+		return CategoryTLS
+	case strings.Contains(msg, "timeout"), strings.Contains(msg, "timed out"), strings.Contains(msg, "deadline exceeded"):
+		return CategoryTimeout
+	case strings.Contains(msg, "connection refused"), strings.Contains(msg, "connection reset"),
+		strings.Contains(msg, "no route to host"), strings.Contains(msg, "network is unreachable"):
+		return CategoryConnection
+	case strings.Contains(msg, "not found"):
+		return CategoryEndpointMissing
+	case strings.Contains(msg, "unmarshal"), strings.Contains(msg, "invalid character"):
+		return CategoryParse
+	}
+	return CategoryOther
+}
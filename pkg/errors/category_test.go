@@ -0,0 +1,46 @@
+package errors
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCategorize(t *testing.T) {
+	syntaxErr := json.Unmarshal([]byte("{"), &struct{}{})
+
+	tests := []struct {
+		name string
+		err  error
+		want Category
+	}{
+		{"nil", nil, ""},
+		{"auth sentinel", NewCollectionError("h", "system", ErrAuthenticationFailed), CategoryAuth},
+		{"auth status", NewRedfishError("h", "/", 403, "Forbidden", ""), CategoryAuth},
+		{"not found", NewCollectionError("h", "system", ErrNotFound), CategoryEndpointMissing},
+		{"not implemented", NewRedfishError("h", "/", 501, "Not Implemented", ""), CategoryEndpointMissing},
+		{"deadline", fmt.Errorf("get: %w", context.DeadlineExceeded), CategoryTimeout},
+		{"canceled", context.Canceled, CategoryCanceled},
+		{"dns", NewRedfishTransportError("h", "/", &net.DNSError{Err: "no such host", Name: "idrac"}), CategoryDNS},
+		{"tls", NewRedfishTransportError("h", "/", x509.UnknownAuthorityError{}), CategoryTLS},
+		{"connection", NewRedfishTransportError("h", "/", &net.OpError{Op: "dial", Err: fmt.Errorf("connection refused")}), CategoryConnection},
+		{"parse", fmt.Errorf("failed to unmarshal response: %w", syntaxErr), CategoryParse},
+		{"server error", NewRedfishError("h", "/", 500, "Internal Server Error", ""), CategoryHTTP},
+		{"bmc busy", NewCollectionError("h", "system", NewBMCBusyError("h", "/", "503 Service Unavailable", "", time.Minute)), CategoryBMCBusy},
+		{"flattened bmc busy", fmt.Errorf("BMC busy, retry after 1m0s: redfish error on h/: (HTTP 503)"), CategoryBMCBusy},
+		{"flattened tls", fmt.Errorf("x509: certificate signed by unknown authority"), CategoryTLS},
+		{"unknown", fmt.Errorf("something odd"), CategoryOther},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Categorize(tt.err))
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package errors
+
+import (
+	"errors"
+	"strings"
+)
+
+// Code is a stable, machine-readable error code for the failure class of an
+// error, so automation can branch on it without matching messages. Codes are
+// part of the JSON output, logs and hook environment and never change.
+type Code string
+
+// Error codes.
+const (
+	CodeAuth            Code = "E_AUTH"
+	CodeTLS             Code = "E_TLS"
+	CodeTimeout         Code = "E_TIMEOUT"
+	CodeDNS             Code = "E_DNS"
+	CodeConnection      Code = "E_CONNECTION"
+	CodeCanceled        Code = "E_CANCELED"
+	CodeRedfishNotFound Code = "E_REDFISH_404"
+	CodeRedfishHTTP     Code = "E_REDFISH_HTTP"
+	CodeBMCBusy         Code = "E_BMC_BUSY"
+	CodeParse           Code = "E_PARSE"
+	CodeConfig          Code = "E_CONFIG"
+	CodeNoServers       Code = "E_NO_SERVERS"
+	CodeReadOnly        Code = "E_READ_ONLY"
+	CodeTooManyChanges  Code = "E_TOO_MANY_CHANGES"
+	CodeLocked          Code = "E_LOCKED"
+	CodeNetBoxNotFound  Code = "E_NETBOX_NOTFOUND"
+	CodeNetBoxAuth      Code = "E_NETBOX_AUTH"
+	CodeNetBoxHTTP      Code = "E_NETBOX_HTTP"
+	CodeScanFailed      Code = "E_SCAN_FAILED"
+	CodeSyncFailed      Code = "E_SYNC_FAILED"
+	CodeHealthPolicy    Code = "E_HEALTH_POLICY"
+	CodeUnknown         Code = "E_UNKNOWN"
+)
+
+// categoryCodes maps the error categories of scan failures to their code.
+var categoryCodes = map[Category]Code{
+	CategoryAuth:            CodeAuth,
+	CategoryTimeout:         CodeTimeout,
+	CategoryTLS:             CodeTLS,
+	CategoryDNS:             CodeDNS,
+	CategoryConnection:      CodeConnection,
+	CategoryEndpointMissing: CodeRedfishNotFound,
+	CategoryParse:           CodeParse,
+	CategoryBMCBusy:         CodeBMCBusy,
+	CategoryHTTP:            CodeRedfishHTTP,
+	CategoryCanceled:        CodeCanceled,
+}
+
+// CodedError is an error with an explicit code: the summary error of a run,
+// or an error read back from JSON output, where the type information of the
+// original error is lost.
+type CodedError struct {
+	Code    Code
+	Message string
+}
+
+func (e *CodedError) Error() string {
+	return e.Message
+}
+
+// WithCode returns an error with the given message and code.
+func WithCode(code Code, message string) error {
+	return &CodedError{Code: code, Message: message}
+}
+
+// CodeOf returns the code of err. Application errors (configuration, read-only
+// mode, change limits, sync lock, NetBox) have their own codes, other sync
+// errors are E_SYNC_FAILED; all others are coded by their category (see
+// Categorize). Returns "" for a nil error.
+func CodeOf(err error) Code {
+	if err == nil {
+		return ""
+	}
+
+	var (
+		coded   *CodedError
+		cfgErr  *ConfigError
+		nbErr   *NetBoxError
+		syncErr *SyncError
+	)
+	switch {
+	case errors.As(err, &coded) && coded.Code != "":
+		return coded.Code
+	case errors.As(err, &cfgErr), errors.Is(err, ErrConfigInvalid):
+		return CodeConfig
+	case errors.Is(err, ErrNoServers):
+		return CodeNoServers
+	case errors.Is(err, ErrReadOnly):
+		return CodeReadOnly
+	case errors.Is(err, ErrTooManyChanges):
+		return CodeTooManyChanges
+	case errors.Is(err, ErrLocked):
+		return CodeLocked
+	case errors.Is(err, ErrDeviceNotFound):
+		return CodeNetBoxNotFound
+	case errors.As(err, &nbErr):
+		return nbErr.Code()
+	case strings.Contains(strings.ToLower(err.Error()), "not found in netbox"):
+		return CodeNetBoxNotFound
+	case errors.As(err, &syncErr):
+		return CodeSyncFailed
+	}
+
+	if code, ok := categoryCodes[Categorize(err)]; ok {
+		return code
+	}
+	return CodeUnknown
+}
@@ -0,0 +1,81 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCodeOf(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want Code
+	}{
+		{"nil", nil, ""},
+		{"auth", NewCollectionError("h", "system", ErrAuthenticationFailed), CodeAuth},
+		{"timeout", fmt.Errorf("get: %w", context.DeadlineExceeded), CodeTimeout},
+		{"redfish 404", NewRedfishError("h", "/", 404, "Not Found", ""), CodeRedfishNotFound},
+		{"redfish 500", NewRedfishError("h", "/", 500, "Internal Server Error", ""), CodeRedfishHTTP},
+		{"flattened timeout", fmt.Errorf("context deadline exceeded"), CodeTimeout},
+		{"config", NewConfigError("netbox.url", "is required"), CodeConfig},
+		{"no servers", ErrNoServers, CodeNoServers},
+		{"read-only", fmt.Errorf("sync: %w", ErrReadOnly), CodeReadOnly},
+		{"too many changes", fmt.Errorf("%w: 12 > 10", ErrTooManyChanges), CodeTooManyChanges},
+		{"locked", fmt.Errorf("%w (held by ci-runner/42)", ErrLocked), CodeLocked},
+		{"device not found", fmt.Errorf("%w (service_tag=ABC1234)", ErrDeviceNotFound), CodeNetBoxNotFound},
+		{"netbox 401", NewNetBoxError(401, "Invalid token"), CodeNetBoxAuth},
+		{"netbox 403", NewNetBoxError(403, "Forbidden"), CodeNetBoxAuth},
+		{"netbox 404", NewNetBoxError(404, "Not found"), CodeNetBoxNotFound},
+		{"netbox 500", fmt.Errorf("patch: %w", NewNetBoxError(500, "boom")), CodeNetBoxHTTP},
+		{"scan failed", WithCode(CodeScanFailed, "2 of 10 servers failed"), CodeScanFailed},
+		{"restored", WithCode(CodeBMCBusy, "BMC busy"), CodeBMCBusy},
+		{"restored without code", WithCode("", "something odd"), CodeUnknown},
+		{"unknown", fmt.Errorf("something odd"), CodeUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, CodeOf(tt.err))
+		})
+	}
+}
+
+func TestNetBoxError(t *testing.T) {
+	err := NewNetBoxError(400, `{"serial": ["invalid"]}`)
+	assert.Equal(t, `API error 400: {"serial": ["invalid"]}`, err.Error())
+	assert.Equal(t, 400, NetBoxStatus(fmt.Errorf("create tag: %w", err)))
+	assert.Equal(t, 0, NetBoxStatus(fmt.Errorf("request failed")))
+}
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"ok", nil, ExitOK},
+		{"scan failed", WithCode(CodeScanFailed, "2 of 10 servers failed"), ExitFailure},
+		{"other", fmt.Errorf("failed to output results: broken pipe"), ExitFailure},
+		{"config", NewConfigError("netbox.url", "is required"), ExitConfig},
+		{"no servers", ErrNoServers, ExitConfig},
+		{"sync failed", WithCode(CodeSyncFailed, "1 of 10 servers failed to sync"), ExitSync},
+		{"export", AsSyncError(fmt.Errorf("git push: rejected")), ExitSync},
+		{"netbox", AsSyncError(fmt.Errorf("%w (held by ci-runner/42)", ErrLocked)), ExitSync},
+		{"health", WithCode(CodeHealthPolicy, "3 servers violate the health policy"), ExitHealth},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ExitCode(tt.err))
+		})
+	}
+
+	err := AsSyncError(fmt.Errorf("%w (held by ci-runner/42)", ErrLocked))
+	assert.Equal(t, CodeLocked, CodeOf(err), "the code of the sync error is kept")
+	assert.Equal(t, "sync locked by another run (held by ci-runner/42)", err.Error(), "and its message")
+	assert.Equal(t, CodeSyncFailed, CodeOf(AsSyncError(fmt.Errorf("git push: rejected"))))
+	assert.Nil(t, AsSyncError(nil))
+}
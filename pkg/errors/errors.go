@@ -5,6 +5,9 @@ package errors
 import (
 	"errors"
 	"fmt"
+	"time"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/redact"
 )
 
 // Sentinel errors for common failure conditions.
@@ -29,6 +32,21 @@ var (
 
 	// ErrNoServers indicates no servers are configured.
 	ErrNoServers = errors.New("no servers configured")
+
+	// ErrReadOnly indicates a mutating operation refused in read-only mode.
+	ErrReadOnly = errors.New("refused in read-only mode")
+
+	// ErrTooManyChanges indicates a sync refused because it would change
+	// more NetBox objects than allowed.
+	ErrTooManyChanges = errors.New("too many changes")
+
+	// ErrLocked indicates a sync refused because another run holds the
+	// NetBox sync lock.
+	ErrLocked = errors.New("sync locked by another run")
+
+	// ErrDeviceNotFound indicates a scanned server without a matching
+	// NetBox device.
+	ErrDeviceNotFound = errors.New("device not found in NetBox")
 )
 
 // RedfishError represents an error returned by the Redfish API.
@@ -38,12 +56,19 @@ type RedfishError struct {
 	Message    string
 	Host       string
 	Path       string
+
+	// Err is the underlying transport error for failed requests (StatusCode 0).
+	Err error
 }
 
 func (e *RedfishError) Error() string {
 	return fmt.Sprintf("redfish error on %s%s: %s (HTTP %d)", e.Host, e.Path, e.Message, e.StatusCode)
 }
 
+func (e *RedfishError) Unwrap() error {
+	return e.Err
+}
+
 // IsAuthError returns true if this is an authentication error.
 func (e *RedfishError) IsAuthError() bool {
 	return e.StatusCode == 401 || e.StatusCode == 403
@@ -54,15 +79,122 @@ func (e *RedfishError) IsNotFound() bool {
 	return e.StatusCode == 404
 }
 
-// NewRedfishError creates a new RedfishError.
+// IsNotImplemented returns true if the BMC does not implement the resource
+// or method (HTTP 501).
+func (e *RedfishError) IsNotImplemented() bool {
+	return e.StatusCode == 501
+}
+
+// NewRedfishError creates a new RedfishError. Credentials in the message
+// (e.g. echoed from a response body) are masked.
 func NewRedfishError(host, path string, statusCode int, status, message string) *RedfishError {
 	return &RedfishError{
 		Host:       host,
 		Path:       path,
 		StatusCode: statusCode,
 		Status:     status,
-		Message:    message,
+		Message:    redact.String(message),
+	}
+}
+
+// NewRedfishTransportError creates a RedfishError for a request that failed
+// before an HTTP response was received (DNS, TLS, connection, timeout).
+func NewRedfishTransportError(host, path string, err error) *RedfishError {
+	return &RedfishError{
+		Host:    host,
+		Path:    path,
+		Message: redact.String(err.Error()),
+		Err:     err,
+	}
+}
+
+// NetBoxError represents an error response of the NetBox API.
+type NetBoxError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *NetBoxError) Error() string {
+	return fmt.Sprintf("API error %d: %s", e.StatusCode, e.Message)
+}
+
+// Code returns the error code for the HTTP status.
+func (e *NetBoxError) Code() Code {
+	switch e.StatusCode {
+	case 401, 403:
+		return CodeNetBoxAuth
+	case 404:
+		return CodeNetBoxNotFound
+	}
+	return CodeNetBoxHTTP
+}
+
+// NewNetBoxError creates a new NetBoxError. Credentials in the message
+// (e.g. echoed from a response body) are masked.
+func NewNetBoxError(statusCode int, message string) *NetBoxError {
+	return &NetBoxError{
+		StatusCode: statusCode,
+		Message:    redact.String(message),
+	}
+}
+
+// NetBoxStatus returns the HTTP status of the NetBoxError in err's chain, or
+// 0 if err is not a NetBoxError.
+func NetBoxStatus(err error) int {
+	var nbErr *NetBoxError
+	if !errors.As(err, &nbErr) {
+		return 0
+	}
+	return nbErr.StatusCode
+}
+
+// NetBoxMessage returns the response body of the NetBoxError in err's chain,
+// or "" if err is not a NetBoxError.
+func NetBoxMessage(err error) string {
+	var nbErr *NetBoxError
+	if !errors.As(err, &nbErr) {
+		return ""
+	}
+	return nbErr.Message
+}
+
+// BMCBusyError is returned when the iDRAC answers 503 Service Unavailable,
+// as it does while it resets (racreset) or is in maintenance. The host is not
+// broken; the request can be repeated once the BMC is back.
+type BMCBusyError struct {
+	// RetryAfter is the wait requested by the Retry-After header (0 if none).
+	RetryAfter time.Duration
+
+	Err *RedfishError
+}
+
+func (e *BMCBusyError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("BMC busy, retry after %s: %v", e.RetryAfter, e.Err)
+	}
+	return fmt.Sprintf("BMC busy: %v", e.Err)
+}
+
+func (e *BMCBusyError) Unwrap() error {
+	return e.Err
+}
+
+// NewBMCBusyError creates a BMCBusyError for a 503 response.
+func NewBMCBusyError(host, path, status, message string, retryAfter time.Duration) *BMCBusyError {
+	return &BMCBusyError{
+		RetryAfter: retryAfter,
+		Err:        NewRedfishError(host, path, 503, status, message),
+	}
+}
+
+// RetryAfter returns the wait requested by a busy BMC in err's chain, and
+// false if err is not a BMCBusyError.
+func RetryAfter(err error) (time.Duration, bool) {
+	var busy *BMCBusyError
+	if !errors.As(err, &busy) {
+		return 0, false
 	}
+	return busy.RetryAfter, true
 }
 
 // CollectionError represents an error that occurred during hardware collection.
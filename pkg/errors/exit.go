@@ -0,0 +1,56 @@
+package errors
+
+import "errors"
+
+// Exit codes of a run. Like the error codes they are part of the interface
+// for cron jobs and CI pipelines and never change.
+const (
+	ExitOK      = 0 // run succeeded
+	ExitFailure = 1 // servers failed to scan, or another runtime error
+	ExitConfig  = 2 // invalid configuration or flags; nothing was scanned
+	ExitSync    = 3 // NetBox sync or export of the results failed
+	ExitHealth  = 4 // servers violate the health policy (-fail-on health)
+)
+
+// SyncError marks an error of the NetBox sync or an export of the results
+// (output sinks, warehouse, GitLab) as a sync failure, keeping its message.
+type SyncError struct {
+	Err error
+}
+
+func (e *SyncError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *SyncError) Unwrap() error {
+	return e.Err
+}
+
+// AsSyncError marks err as a sync failure. Returns nil for a nil error.
+func AsSyncError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &SyncError{Err: err}
+}
+
+// IsSyncFailure reports whether err is a sync failure.
+func IsSyncFailure(err error) bool {
+	var syncErr *SyncError
+	return errors.As(err, &syncErr) || CodeOf(err) == CodeSyncFailed
+}
+
+// ExitCode returns the exit code of a run that ended with err.
+func ExitCode(err error) int {
+	switch code := CodeOf(err); {
+	case err == nil:
+		return ExitOK
+	case code == CodeConfig, code == CodeNoServers:
+		return ExitConfig
+	case code == CodeHealthPolicy:
+		return ExitHealth
+	case IsSyncFailure(err):
+		return ExitSync
+	}
+	return ExitFailure
+}
@@ -0,0 +1,121 @@
+// Package features reports which platform-specific features the running
+// binary supports. The binary is built for every release platform without
+// build tags; features the target OS lacks are compiled in but refused at
+// run time with an error that names the feature, the platform and why.
+package features
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+)
+
+// Names of the platform-specific features.
+const (
+	ServiceInstall = "service-install"
+	Journald       = "journald"
+	SyslogSocket   = "syslog-socket"
+	SyslogNetwork  = "syslog-network"
+	FileModes      = "file-modes"
+)
+
+// Feature is the availability of a feature on a platform.
+type Feature struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Enabled     bool   `json:"enabled"`
+
+	// Reason explains why a disabled feature is not available.
+	Reason string `json:"reason,omitempty"`
+}
+
+// spec describes a feature and the operating systems that support it.
+type spec struct {
+	name        string
+	description string
+	supported   func(goos string) bool
+	reason      string
+}
+
+func anyOS(string) bool { return true }
+
+func onlyOn(oses ...string) func(string) bool {
+	return func(goos string) bool {
+		for _, name := range oses {
+			if goos == name {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func notOn(oses ...string) func(string) bool {
+	only := onlyOn(oses...)
+	return func(goos string) bool { return !only(goos) }
+}
+
+var specs = []spec{
+	{ServiceInstall, "install-service (systemd unit or Windows service)",
+		onlyOn("linux", "windows"), "needs systemd or the Windows service control manager"},
+	{Journald, "logging to the systemd journal (logging.journald)",
+		onlyOn("linux"), "systemd-journald only runs on Linux"},
+	{SyslogSocket, "logging to the local syslog socket (logging.syslog.network: unix)",
+		notOn("windows", "plan9", "js", "wasip1"), "the OS has no syslog datagram socket; use udp or tcp"},
+	{SyslogNetwork, "logging to a syslog server over udp or tcp", anyOS, ""},
+	{FileModes, "owner-only permissions of written configs, state and reports",
+		notOn("windows", "plan9"), "Unix file modes are not enforced; files inherit the directory ACL"},
+}
+
+// For returns the features and their availability on goos/goarch.
+func For(goos, goarch string) []Feature {
+	out := make([]Feature, len(specs))
+	for i, s := range specs {
+		out[i] = Feature{Name: s.name, Description: s.description, Enabled: s.supported(goos)}
+		if !out[i].Enabled {
+			out[i].Reason = s.reason
+		}
+	}
+	return out
+}
+
+// List returns the features of the running binary.
+func List() []Feature {
+	return For(runtime.GOOS, runtime.GOARCH)
+}
+
+// UnsupportedError is returned when a feature is used on a platform that
+// does not support it. It matches errors.ErrUnsupported.
+type UnsupportedError struct {
+	Feature Feature
+	GOOS    string
+	GOARCH  string
+}
+
+func (e *UnsupportedError) Error() string {
+	return fmt.Sprintf("%s is not supported on %s/%s: %s", e.Feature.Description, e.GOOS, e.GOARCH, e.Feature.Reason)
+}
+
+// Is makes errors.Is(err, errors.ErrUnsupported) true.
+func (e *UnsupportedError) Is(target error) bool {
+	return target == errors.ErrUnsupported
+}
+
+// Check returns an *UnsupportedError if the running binary does not
+// support the named feature.
+func Check(name string) error {
+	return check(name, runtime.GOOS, runtime.GOARCH)
+}
+
+func check(name, goos, goarch string) error {
+	for _, f := range For(goos, goarch) {
+		if f.Name != name {
+			continue
+		}
+		if f.Enabled {
+			return nil
+		}
+		return &UnsupportedError{Feature: f, GOOS: goos, GOARCH: goarch}
+	}
+	panic("features: unknown feature " + name)
+}
@@ -0,0 +1,54 @@
+package features
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func enabled(list []Feature) map[string]bool {
+	m := make(map[string]bool)
+	for _, f := range list {
+		m[f.Name] = f.Enabled
+	}
+	return m
+}
+
+func TestFor(t *testing.T) {
+	assert.Equal(t, map[string]bool{
+		ServiceInstall: true, Journald: true, SyslogSocket: true, SyslogNetwork: true, FileModes: true,
+	}, enabled(For("linux", "amd64")))
+
+	assert.Equal(t, map[string]bool{
+		ServiceInstall: true, Journald: false, SyslogSocket: false, SyslogNetwork: true, FileModes: false,
+	}, enabled(For("windows", "arm64")))
+
+	assert.Equal(t, map[string]bool{
+		ServiceInstall: false, Journald: false, SyslogSocket: true, SyslogNetwork: true, FileModes: true,
+	}, enabled(For("darwin", "arm64")))
+
+	for _, f := range For("windows", "arm64") {
+		if f.Enabled {
+			assert.Empty(t, f.Reason, f.Name)
+		} else {
+			assert.NotEmpty(t, f.Reason, f.Name)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	assert.NoError(t, check(Journald, "linux", "arm64"))
+
+	err := check(Journald, "windows", "arm64")
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, errors.ErrUnsupported))
+	assert.Equal(t, "logging to the systemd journal (logging.journald) is not supported on windows/arm64: systemd-journald only runs on Linux", err.Error())
+
+	var unsupported *UnsupportedError
+	require.ErrorAs(t, check(ServiceInstall, "darwin", "amd64"), &unsupported)
+	assert.Equal(t, ServiceInstall, unsupported.Feature.Name)
+
+	assert.Panics(t, func() { _ = check("keyring", "linux", "amd64") })
+}
@@ -0,0 +1,183 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// DefaultJournalSocket is the native protocol socket of systemd-journald.
+const DefaultJournalSocket = "/run/systemd/journal/socket"
+
+// JournaldConfig configures the systemd journal target.
+type JournaldConfig struct {
+	Enabled bool `yaml:"enabled"`
+
+	// Identifier is the SYSLOG_IDENTIFIER field (default: idrac-inventory).
+	Identifier string `yaml:"identifier"`
+
+	// Socket overrides the journald socket path.
+	Socket string `yaml:"socket"`
+}
+
+// journal sends entries over the journald native protocol. Log fields become
+// journal fields (host → HOST, run_id → RUN_ID), so they can be filtered
+// with journalctl, e.g. journalctl RUN_ID=<id>.
+type journal struct {
+	socket     string
+	identifier string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+func newJournal(cfg JournaldConfig) *journal {
+	return &journal{
+		socket:     getOrDefault(cfg.Socket, DefaultJournalSocket),
+		identifier: getOrDefault(cfg.Identifier, DefaultSyslogTag),
+	}
+}
+
+func (j *journal) send(payload []byte) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.conn == nil {
+		conn, err := net.Dial("unixgram", j.socket)
+		if err != nil {
+			return fmt.Errorf("failed to connect to journald at %s: %w", j.socket, err)
+		}
+		j.conn = conn
+	}
+	if _, err := j.conn.Write(payload); err != nil {
+		j.conn.Close()
+		j.conn = nil
+		return err
+	}
+	return nil
+}
+
+// Close closes the socket. A later entry reconnects.
+func (j *journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.conn == nil {
+		return nil
+	}
+	err := j.conn.Close()
+	j.conn = nil
+	return err
+}
+
+// journalFieldName converts a log field key to a valid journal field name:
+// upper case letters, digits and underscores, not starting with an underscore
+// (reserved for trusted fields) or a digit.
+func journalFieldName(key string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, key)
+	name = strings.TrimLeft(name, "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "F_" + name
+	}
+	return name
+}
+
+// writeJournalField appends one field in the native protocol format. Values
+// containing newlines use the binary length-prefixed form.
+func writeJournalField(buf *bytes.Buffer, name, value string) {
+	if !strings.Contains(value, "\n") {
+		fmt.Fprintf(buf, "%s=%s\n", name, value)
+		return
+	}
+	buf.WriteString(name)
+	buf.WriteByte('\n')
+	_ = binary.Write(buf, binary.LittleEndian, uint64(len(value)))
+	buf.WriteString(value)
+	buf.WriteByte('\n')
+}
+
+func journalValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case fmt.Stringer:
+		return v.String()
+	case error:
+		return v.Error()
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, bool:
+		return fmt.Sprint(v)
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(data)
+	}
+}
+
+// journalCore is a zapcore.Core that sends each entry to journald.
+type journalCore struct {
+	zapcore.LevelEnabler
+	j      *journal
+	fields []zapcore.Field
+}
+
+func (c *journalCore) With(fields []zapcore.Field) zapcore.Core {
+	all := make([]zapcore.Field, 0, len(c.fields)+len(fields))
+	all = append(all, c.fields...)
+	all = append(all, fields...)
+	return &journalCore{LevelEnabler: c.LevelEnabler, j: c.j, fields: all}
+}
+
+func (c *journalCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *journalCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range c.fields {
+		f.AddTo(enc)
+	}
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+
+	var buf bytes.Buffer
+	writeJournalField(&buf, "MESSAGE", ent.Message)
+	writeJournalField(&buf, "PRIORITY", strconv.Itoa(syslogSeverity(ent.Level)))
+	writeJournalField(&buf, "SYSLOG_IDENTIFIER", c.j.identifier)
+	if ent.Caller.Defined {
+		writeJournalField(&buf, "CODE_FILE", ent.Caller.File)
+		writeJournalField(&buf, "CODE_LINE", strconv.Itoa(ent.Caller.Line))
+	}
+	if ent.Stack != "" {
+		writeJournalField(&buf, "STACKTRACE", ent.Stack)
+	}
+	for key, value := range enc.Fields {
+		writeJournalField(&buf, journalFieldName(key), journalValue(value))
+	}
+
+	return c.j.send(buf.Bytes())
+}
+
+func (c *journalCore) Sync() error {
+	return nil
+}
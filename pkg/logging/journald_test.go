@@ -0,0 +1,57 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestJournalFieldName(t *testing.T) {
+	assert.Equal(t, "RUN_ID", journalFieldName("run_id"))
+	assert.Equal(t, "SERVICE_TAG", journalFieldName("service-tag"))
+	assert.Equal(t, "HIDDEN", journalFieldName("_hidden"))
+	assert.Equal(t, "F_1X", journalFieldName("1x"))
+}
+
+func TestJournal_SendsFields(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unix datagram sockets")
+	}
+	socket := filepath.Join(t.TempDir(), "journal.sock")
+	pc, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	require.NoError(t, err)
+	defer pc.Close()
+
+	j := newJournal(JournaldConfig{Enabled: true, Socket: socket})
+	defer j.Close()
+	logger := zap.New(&journalCore{LevelEnabler: zap.DebugLevel, j: j})
+
+	logger.With(zap.String("host", "10.0.0.1")).Info("server scan completed",
+		zap.Int("cpus", 2), zap.String("detail", "line1\nline2"))
+
+	buf := make([]byte, 4096)
+	require.NoError(t, pc.SetReadDeadline(time.Now().Add(5*time.Second)))
+	n, err := pc.Read(buf)
+	require.NoError(t, err)
+	payload := buf[:n]
+
+	assert.Contains(t, string(payload), "MESSAGE=server scan completed\n")
+	assert.Contains(t, string(payload), "PRIORITY=6\n")
+	assert.Contains(t, string(payload), "SYSLOG_IDENTIFIER=idrac-inventory\n")
+	assert.Contains(t, string(payload), "HOST=10.0.0.1\n")
+	assert.Contains(t, string(payload), "CPUS=2\n")
+
+	var multiline bytes.Buffer
+	multiline.WriteString("DETAIL\n")
+	_ = binary.Write(&multiline, binary.LittleEndian, uint64(len("line1\nline2")))
+	multiline.WriteString("line1\nline2\n")
+	assert.Contains(t, string(payload), multiline.String())
+}
@@ -4,17 +4,23 @@
 package logging
 
 import (
+	"io"
 	"sync"
+	"time"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/features"
 )
 
 var (
-	globalLogger *zap.SugaredLogger
-	globalLevel  zap.AtomicLevel
-	once         sync.Once
-	mu           sync.RWMutex
+	globalLogger  *zap.SugaredLogger
+	globalLevel   zap.AtomicLevel
+	globalRunID   string
+	globalOutputs []io.Closer
+	once          sync.Once
+	mu            sync.RWMutex
 )
 
 // Config holds logging configuration options.
@@ -26,9 +32,18 @@ type Config struct {
 	Format string `yaml:"format"`
 
 	// OutputPaths are the destinations for log output.
-	// Defaults to stderr if not specified.
+	// Defaults to stderr if neither OutputPaths nor File.Path is specified.
 	OutputPaths []string `yaml:"output_paths"`
 
+	// File writes logs to rotating log files, in addition to OutputPaths.
+	File FileConfig `yaml:"file"`
+
+	// Syslog sends logs to a syslog server or socket in RFC 5424 format.
+	Syslog SyslogConfig `yaml:"syslog"`
+
+	// Journald sends logs to the systemd journal with structured fields.
+	Journald JournaldConfig `yaml:"journald"`
+
 	// Development enables development mode with more verbose output.
 	Development bool `yaml:"development"`
 
@@ -37,6 +52,31 @@ type Config struct {
 
 	// DisableStacktrace disables stacktrace for error logs.
 	DisableStacktrace bool `yaml:"disable_stacktrace"`
+
+	// RunID is attached to every log line as run_id, if set.
+	RunID string `yaml:"-"`
+
+	// DebugSampling thins out repetitive debug messages.
+	DebugSampling SamplingConfig `yaml:"debug_sampling"`
+
+	// InfoSampling thins out repetitive info messages, such as the
+	// per-host component summaries of large scans. Warnings and errors
+	// are never sampled.
+	InfoSampling SamplingConfig `yaml:"info_sampling"`
+}
+
+// SamplingConfig limits how often the same message is logged. Within
+// each tick, the first Initial entries with a given message are logged and
+// after that only every Thereafter-th one (none if Thereafter is 0).
+type SamplingConfig struct {
+	Initial    int           `yaml:"initial"`
+	Thereafter int           `yaml:"thereafter"`
+	Tick       time.Duration `yaml:"tick"`
+}
+
+// IsEnabled reports whether sampling is configured.
+func (s SamplingConfig) IsEnabled() bool {
+	return s.Initial > 0 || s.Thereafter > 0
 }
 
 // DefaultConfig returns a sensible default logging configuration.
@@ -109,10 +149,21 @@ func initLogger(cfg Config) error {
 
 	// Set output paths
 	outputPaths := cfg.OutputPaths
-	if len(outputPaths) == 0 {
+	if len(outputPaths) == 0 && cfg.File.Path == "" && !cfg.Syslog.IsEnabled() && !cfg.Journald.Enabled {
 		outputPaths = []string{"stderr"}
 	}
 
+	// Log files and syslog never get color codes
+	plainEncoderConfig := encoderConfig
+	plainEncoderConfig.EncodeLevel = zapcore.CapitalLevelEncoder
+	if cfg.Format != "console" {
+		plainEncoderConfig.EncodeLevel = zapcore.LowercaseLevelEncoder
+	}
+	extraCores, outputs, err := outputCores(cfg, plainEncoderConfig)
+	if err != nil {
+		return err
+	}
+
 	// Build zap config
 	zapConfig := zap.Config{
 		Level:             globalLevel,
@@ -131,14 +182,35 @@ func initLogger(cfg Config) error {
 	}
 
 	// Build logger
-	logger, err := zapConfig.Build(
+	opts := []zap.Option{
 		zap.AddCallerSkip(1), // Skip the logging wrapper functions
-	)
+	}
+	opts = append(opts, zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		if len(extraCores) > 0 {
+			core = zapcore.NewTee(append([]zapcore.Core{core}, extraCores...)...)
+		}
+		if cfg.DebugSampling.IsEnabled() {
+			core = newSampler(core, zapcore.DebugLevel, cfg.DebugSampling)
+		}
+		if cfg.InfoSampling.IsEnabled() {
+			core = newSampler(core, zapcore.InfoLevel, cfg.InfoSampling)
+		}
+		// Credentials never reach any output
+		return &redactingCore{Core: core}
+	}))
+	logger, err := zapConfig.Build(opts...)
 	if err != nil {
+		closeAll(outputs)
 		return err
 	}
+	if cfg.RunID != "" {
+		logger = logger.With(zap.String("run_id", cfg.RunID))
+	}
 
 	globalLogger = logger.Sugar()
+	globalRunID = cfg.RunID
+	closeAll(globalOutputs)
+	globalOutputs = outputs
 	return nil
 }
 
@@ -157,6 +229,78 @@ func Logger() *zap.SugaredLogger {
 	return globalLogger
 }
 
+// outputCores opens the configured log files, syslog and journald targets.
+// The error file only receives entries at error level and above.
+func outputCores(cfg Config, encoderConfig zapcore.EncoderConfig) ([]zapcore.Core, []io.Closer, error) {
+	newEncoder := func(ec zapcore.EncoderConfig) zapcore.Encoder {
+		if cfg.Format == "console" {
+			return zapcore.NewConsoleEncoder(ec)
+		}
+		return zapcore.NewJSONEncoder(ec)
+	}
+
+	var cores []zapcore.Core
+	var outputs []io.Closer
+	for _, target := range []struct {
+		path  string
+		level zapcore.LevelEnabler
+	}{
+		{cfg.File.Path, globalLevel},
+		{cfg.File.ErrorPath, zapcore.ErrorLevel},
+	} {
+		if target.path == "" {
+			continue
+		}
+		f, err := NewRotatingFile(target.path, cfg.File.Rotation)
+		if err != nil {
+			closeAll(outputs)
+			return nil, nil, err
+		}
+		outputs = append(outputs, f)
+		cores = append(cores, zapcore.NewCore(newEncoder(encoderConfig), f, target.level))
+	}
+
+	// The syslog header carries the timestamp and severity
+	if cfg.Syslog.IsEnabled() {
+		w, err := newSyslogWriter(cfg.Syslog)
+		if err != nil {
+			closeAll(outputs)
+			return nil, nil, err
+		}
+		ec := encoderConfig
+		ec.TimeKey = zapcore.OmitKey
+		ec.LevelKey = zapcore.OmitKey
+		ec.LineEnding = "\n"
+		outputs = append(outputs, w)
+		cores = append(cores, &syslogCore{LevelEnabler: globalLevel, enc: newEncoder(ec), w: w})
+	}
+
+	if cfg.Journald.Enabled {
+		if err := features.Check(features.Journald); err != nil {
+			closeAll(outputs)
+			return nil, nil, err
+		}
+		j := newJournal(cfg.Journald)
+		outputs = append(outputs, j)
+		cores = append(cores, &journalCore{LevelEnabler: globalLevel, j: j})
+	}
+
+	return cores, outputs, nil
+}
+
+func closeAll(outputs []io.Closer) {
+	for _, o := range outputs {
+		_ = o.Close()
+	}
+}
+
+// RunID returns the run ID attached to every log line, or "" if none.
+func RunID() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return globalRunID
+}
+
 // SetLevel changes the log level at runtime.
 func SetLevel(level string) error {
 	lvl, err := zapcore.ParseLevel(level)
@@ -236,6 +380,45 @@ func Sync() error {
 	return nil
 }
 
+// sampler samples the entries of one level and passes all other levels
+// through.
+type sampler struct {
+	zapcore.Core
+	sampled zapcore.Core
+	level   zapcore.Level
+}
+
+func newSampler(core zapcore.Core, level zapcore.Level, cfg SamplingConfig) zapcore.Core {
+	tick := cfg.Tick
+	if tick <= 0 {
+		tick = time.Second
+	}
+	initial := cfg.Initial
+	if initial <= 0 {
+		initial = 1
+	}
+	return &sampler{
+		Core:    core,
+		sampled: zapcore.NewSamplerWithOptions(core, tick, initial, cfg.Thereafter),
+		level:   level,
+	}
+}
+
+func (s *sampler) With(fields []zapcore.Field) zapcore.Core {
+	return &sampler{
+		Core:    s.Core.With(fields),
+		sampled: s.sampled.With(fields),
+		level:   s.level,
+	}
+}
+
+func (s *sampler) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if ent.Level == s.level {
+		return s.sampled.Check(ent, ce)
+	}
+	return s.Core.Check(ent, ce)
+}
+
 // NewNopLogger returns a logger that discards all output.
 // Useful for testing.
 func NewNopLogger() *zap.SugaredLogger {
@@ -1,10 +1,14 @@
 package logging
 
 import (
+	"bytes"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
 func TestDefaultConfig(t *testing.T) {
@@ -127,3 +131,60 @@ func TestSync(t *testing.T) {
 	// Sync to stderr might return an error on some systems, that's OK
 	_ = err
 }
+
+func TestDebugSampler(t *testing.T) {
+	var buf bytes.Buffer
+	core := zapcore.NewCore(
+		zapcore.NewJSONEncoder(zapcore.EncoderConfig{MessageKey: "msg"}),
+		zapcore.AddSync(&buf),
+		zapcore.DebugLevel,
+	)
+	logger := zap.New(newSampler(core, zapcore.DebugLevel, SamplingConfig{Initial: 2, Thereafter: 5})).
+		With(zap.String("host", "10.0.0.1"))
+
+	for i := 0; i < 12; i++ {
+		logger.Debug("making redfish request")
+		logger.Info("server scan completed")
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	var debug, info int
+	for _, l := range lines {
+		assert.Contains(t, l, `"host":"10.0.0.1"`)
+		if strings.Contains(l, "making redfish request") {
+			debug++
+		} else {
+			info++
+		}
+	}
+	// Entries 1-2, then 7 and 12
+	assert.Equal(t, 4, debug)
+	assert.Equal(t, 12, info, "info is never sampled")
+}
+
+func TestInfoSampler(t *testing.T) {
+	var buf bytes.Buffer
+	core := zapcore.NewCore(
+		zapcore.NewJSONEncoder(zapcore.EncoderConfig{MessageKey: "msg"}),
+		zapcore.AddSync(&buf),
+		zapcore.DebugLevel,
+	)
+	logger := zap.New(newSampler(core, zapcore.InfoLevel, SamplingConfig{Initial: 3}))
+
+	for i := 0; i < 10; i++ {
+		logger.Info("extracted memory information")
+		logger.Warn("BMC busy, waiting before retrying")
+	}
+
+	out := buf.String()
+	assert.Equal(t, 3, strings.Count(out, "extracted memory information"), "only the first entries without thereafter")
+	assert.Equal(t, 10, strings.Count(out, "BMC busy"), "warnings are never sampled")
+}
+
+func TestRunID(t *testing.T) {
+	require.NoError(t, Reinit(Config{Level: "info", Format: "json", RunID: "run-1"}))
+	assert.Equal(t, "run-1", RunID())
+
+	require.NoError(t, Reinit(DefaultConfig()))
+	assert.Empty(t, RunID())
+}
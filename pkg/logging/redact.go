@@ -0,0 +1,64 @@
+package logging
+
+import (
+	"fmt"
+
+	"go.uber.org/zap/zapcore"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/redact"
+)
+
+// redactingCore masks credentials in messages and in string, error and
+// Stringer fields before they reach any output (see package redact).
+type redactingCore struct {
+	zapcore.Core
+}
+
+func (c *redactingCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactingCore{Core: c.Core.With(redactFields(fields))}
+}
+
+func (c *redactingCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *redactingCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	ent.Message = redact.String(ent.Message)
+	ent.Stack = redact.String(ent.Stack)
+
+	// The wrapped core may filter (sampling, per-target levels), so it decides
+	// again which of its cores write the entry
+	ce := c.Core.Check(ent, nil)
+	if ce == nil {
+		return nil
+	}
+	ce.Write(redactFields(fields)...)
+	return nil
+}
+
+func redactFields(fields []zapcore.Field) []zapcore.Field {
+	out := make([]zapcore.Field, len(fields))
+	for i, f := range fields {
+		switch f.Type {
+		case zapcore.StringType:
+			f.String = redact.String(f.String)
+		case zapcore.ErrorType:
+			if err, ok := f.Interface.(error); ok && err != nil {
+				f = zapcore.Field{Key: f.Key, Type: zapcore.StringType, String: redact.String(err.Error())}
+			}
+		case zapcore.StringerType:
+			if s, ok := f.Interface.(fmt.Stringer); ok && s != nil {
+				f = zapcore.Field{Key: f.Key, Type: zapcore.StringType, String: redact.String(s.String())}
+			}
+		case zapcore.ByteStringType:
+			if b, ok := f.Interface.([]byte); ok {
+				f.Interface = []byte(redact.String(string(b)))
+			}
+		}
+		out[i] = f
+	}
+	return out
+}
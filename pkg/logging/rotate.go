@@ -0,0 +1,200 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat is the timestamp appended to rotated log files. It sorts
+// lexically in chronological order.
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// FileConfig configures logging to files.
+type FileConfig struct {
+	// Path receives all log entries at the configured level.
+	Path string `yaml:"path"`
+
+	// ErrorPath additionally receives entries at error level and above.
+	ErrorPath string `yaml:"error_path"`
+
+	// Rotation applies to both files.
+	Rotation RotationConfig `yaml:"rotation"`
+}
+
+// RotationConfig controls when log files are rotated and how many old files
+// are kept. Zero values disable the respective limit.
+type RotationConfig struct {
+	// MaxSizeMB rotates the file before it grows beyond this size.
+	MaxSizeMB int `yaml:"max_size_mb"`
+
+	// Every rotates the file once it has been written to for this long.
+	Every time.Duration `yaml:"every"`
+
+	// MaxBackups is the number of rotated files to keep.
+	MaxBackups int `yaml:"max_backups"`
+
+	// MaxAgeDays removes rotated files older than this.
+	MaxAgeDays int `yaml:"max_age_days"`
+}
+
+// RotatingFile is a log file that is rotated by size and age, in the style of
+// lumberjack. Rotated files are renamed to <name>-<timestamp><ext> next to the
+// original. It implements zapcore.WriteSyncer.
+type RotatingFile struct {
+	path string
+	cfg  RotationConfig
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	openedAt time.Time
+
+	now func() time.Time
+}
+
+// NewRotatingFile opens (or creates) the log file at path, creating its
+// directory if necessary.
+func NewRotatingFile(path string, cfg RotationConfig) (*RotatingFile, error) {
+	r := &RotatingFile{path: path, cfg: cfg, now: time.Now}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Write appends p to the file, rotating it first if a limit would be exceeded.
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		if err := r.open(); err != nil {
+			return 0, err
+		}
+	}
+	if r.shouldRotate(int64(len(p))) {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// Sync flushes the file to disk.
+func (r *RotatingFile) Sync() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return nil
+	}
+	return r.file.Sync()
+}
+
+// Close closes the file. A later Write reopens it.
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+func (r *RotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("failed to stat log file: %w", err)
+	}
+
+	r.file = f
+	r.size = info.Size()
+	r.openedAt = r.now()
+	return nil
+}
+
+func (r *RotatingFile) shouldRotate(n int64) bool {
+	if r.size == 0 {
+		return false
+	}
+	if r.cfg.MaxSizeMB > 0 && r.size+n > int64(r.cfg.MaxSizeMB)*1024*1024 {
+		return true
+	}
+	return r.cfg.Every > 0 && r.now().Sub(r.openedAt) >= r.cfg.Every
+}
+
+func (r *RotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		return fmt.Errorf("failed to close log file: %w", err)
+	}
+	r.file = nil
+
+	if err := os.Rename(r.path, r.backupName(r.now())); err != nil {
+		return fmt.Errorf("failed to rotate log file: %w", err)
+	}
+	if err := r.open(); err != nil {
+		return err
+	}
+	r.prune()
+	return nil
+}
+
+func (r *RotatingFile) backupName(t time.Time) string {
+	ext := filepath.Ext(r.path)
+	base := strings.TrimSuffix(r.path, ext)
+	return base + "-" + t.Format(backupTimeFormat) + ext
+}
+
+// backups returns the rotated files, newest first.
+func (r *RotatingFile) backups() []string {
+	ext := filepath.Ext(r.path)
+	base := strings.TrimSuffix(r.path, ext)
+	matches, _ := filepath.Glob(base + "-*" + ext)
+
+	var backups []string
+	for _, m := range matches {
+		stamp := strings.TrimSuffix(strings.TrimPrefix(m, base+"-"), ext)
+		if _, err := time.Parse(backupTimeFormat, stamp); err == nil {
+			backups = append(backups, m)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(backups)))
+	return backups
+}
+
+// prune removes rotated files beyond MaxBackups or older than MaxAgeDays.
+// Errors are ignored; a leftover file is retried on the next rotation.
+func (r *RotatingFile) prune() {
+	if r.cfg.MaxBackups <= 0 && r.cfg.MaxAgeDays <= 0 {
+		return
+	}
+	cutoff := r.now().AddDate(0, 0, -r.cfg.MaxAgeDays)
+	for i, b := range r.backups() {
+		if r.cfg.MaxBackups > 0 && i >= r.cfg.MaxBackups {
+			os.Remove(b)
+			continue
+		}
+		if r.cfg.MaxAgeDays > 0 {
+			if info, err := os.Stat(b); err == nil && info.ModTime().Before(cutoff) {
+				os.Remove(b)
+			}
+		}
+	}
+}
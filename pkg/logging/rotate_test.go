@@ -0,0 +1,120 @@
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotatingFile_RotatesBySize(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	r, err := NewRotatingFile(path, RotationConfig{MaxSizeMB: 1, MaxBackups: 2})
+	require.NoError(t, err)
+	defer r.Close()
+
+	clock := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	r.now = func() time.Time { clock = clock.Add(time.Second); return clock }
+
+	line := []byte(strings.Repeat("x", 512*1024))
+	for i := 0; i < 8; i++ {
+		_, err := r.Write(line)
+		require.NoError(t, err)
+	}
+
+	backups := r.backups()
+	assert.Len(t, backups, 2, "older backups are pruned")
+	for _, b := range backups {
+		info, err := os.Stat(b)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1024*1024), info.Size())
+	}
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1024*1024), info.Size())
+}
+
+func TestRotatingFile_RotatesByAge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "app.log")
+
+	r, err := NewRotatingFile(path, RotationConfig{Every: time.Hour})
+	require.NoError(t, err)
+	defer r.Close()
+
+	clock := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	r.now = func() time.Time { return clock }
+	r.openedAt = clock
+
+	_, err = r.Write([]byte("first\n"))
+	require.NoError(t, err)
+	clock = clock.Add(30 * time.Minute)
+	_, err = r.Write([]byte("second\n"))
+	require.NoError(t, err)
+	assert.Empty(t, r.backups())
+
+	clock = clock.Add(time.Hour)
+	_, err = r.Write([]byte("third\n"))
+	require.NoError(t, err)
+
+	backups := r.backups()
+	require.Len(t, backups, 1)
+	assert.Equal(t, filepath.Join(filepath.Dir(path), "app-2026-01-01T01-30-00.000.log"), backups[0])
+
+	old, err := os.ReadFile(backups[0])
+	require.NoError(t, err)
+	assert.Equal(t, "first\nsecond\n", string(old))
+	current, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "third\n", string(current))
+}
+
+func TestRotatingFile_ReopensAfterClose(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+
+	r, err := NewRotatingFile(path, RotationConfig{})
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+
+	_, err = r.Write([]byte("after close\n"))
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "after close\n", string(data))
+}
+
+func TestReinit_FileOutput(t *testing.T) {
+	dir := t.TempDir()
+	cfg := Config{
+		Level:  "info",
+		Format: "console",
+		File: FileConfig{
+			Path:      filepath.Join(dir, "app.log"),
+			ErrorPath: filepath.Join(dir, "error.log"),
+		},
+	}
+	require.NoError(t, Reinit(cfg))
+	defer Reinit(DefaultConfig())
+
+	Info("scan started")
+	Error("scan failed")
+	require.NoError(t, Sync())
+
+	all, err := os.ReadFile(cfg.File.Path)
+	require.NoError(t, err)
+	assert.Contains(t, string(all), "scan started")
+	assert.Contains(t, string(all), "scan failed")
+	assert.NotContains(t, string(all), "\x1b[", "no color codes in log files")
+
+	errs, err := os.ReadFile(cfg.File.ErrorPath)
+	require.NoError(t, err)
+	assert.NotContains(t, string(errs), "scan started")
+	assert.Contains(t, string(errs), "scan failed")
+}
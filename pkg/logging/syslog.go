@@ -0,0 +1,243 @@
+package logging
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/features"
+)
+
+// DefaultSyslogTag is the APP-NAME used if SyslogConfig.Tag is empty.
+const DefaultSyslogTag = "idrac-inventory"
+
+// syslogFacilities maps facility names to their RFC 5424 codes.
+var syslogFacilities = map[string]int{
+	"kern": 0, "user": 1, "mail": 2, "daemon": 3, "auth": 4, "syslog": 5,
+	"lpr": 6, "news": 7, "uucp": 8, "cron": 9, "authpriv": 10, "ftp": 11,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19,
+	"local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+// SyslogConfig configures the syslog target.
+type SyslogConfig struct {
+	// Network is udp, tcp or unix (empty disables syslog).
+	Network string `yaml:"network"`
+
+	// Address is host:port for udp/tcp or the socket path for unix
+	// (default: /dev/log).
+	Address string `yaml:"address"`
+
+	// Facility is the syslog facility name (default: daemon).
+	Facility string `yaml:"facility"`
+
+	// Tag is the APP-NAME of each message (default: idrac-inventory).
+	Tag string `yaml:"tag"`
+}
+
+// IsEnabled reports whether a syslog target is configured.
+func (s SyslogConfig) IsEnabled() bool {
+	return s.Network != ""
+}
+
+// ValidSyslogFacility reports whether name is a known syslog facility.
+func ValidSyslogFacility(name string) bool {
+	_, ok := syslogFacilities[strings.ToLower(name)]
+	return ok
+}
+
+// syslogSeverity maps zap levels to RFC 5424 severities.
+func syslogSeverity(l zapcore.Level) int {
+	switch {
+	case l <= zapcore.DebugLevel:
+		return 7
+	case l == zapcore.InfoLevel:
+		return 6
+	case l == zapcore.WarnLevel:
+		return 4
+	case l == zapcore.ErrorLevel:
+		return 3
+	default:
+		return 2
+	}
+}
+
+// syslogWriter sends RFC 5424 messages. TCP uses octet-counting framing
+// (RFC 6587); udp and unix sockets send one message per datagram.
+// The connection is (re)established lazily, so a syslog server restart only
+// loses the message that hit the broken connection.
+type syslogWriter struct {
+	network  string
+	address  string
+	facility int
+	tag      string
+	hostname string
+	pid      int
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+func newSyslogWriter(cfg SyslogConfig) (*syslogWriter, error) {
+	network := strings.ToLower(cfg.Network)
+	switch network {
+	case "udp", "tcp", "unix":
+	default:
+		return nil, fmt.Errorf("unsupported syslog network %q (use udp, tcp or unix)", cfg.Network)
+	}
+
+	if network == "unix" {
+		if err := features.Check(features.SyslogSocket); err != nil {
+			return nil, err
+		}
+	}
+
+	address := cfg.Address
+	if address == "" {
+		if network != "unix" {
+			return nil, fmt.Errorf("syslog over %s requires an address", network)
+		}
+		address = "/dev/log"
+	}
+
+	facility := syslogFacilities["daemon"]
+	if cfg.Facility != "" {
+		f, ok := syslogFacilities[strings.ToLower(cfg.Facility)]
+		if !ok {
+			return nil, fmt.Errorf("unknown syslog facility %q", cfg.Facility)
+		}
+		facility = f
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "-"
+	}
+
+	w := &syslogWriter{
+		network:  network,
+		address:  address,
+		facility: facility,
+		tag:      getOrDefault(cfg.Tag, DefaultSyslogTag),
+		hostname: hostname,
+		pid:      os.Getpid(),
+	}
+	if err := w.connect(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *syslogWriter) connect() error {
+	var conn net.Conn
+	var err error
+	if w.network == "unix" {
+		// /dev/log is usually a datagram socket
+		conn, err = net.Dial("unixgram", w.address)
+		if err != nil {
+			conn, err = net.Dial("unix", w.address)
+		}
+	} else {
+		conn, err = net.DialTimeout(w.network, w.address, 5*time.Second)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to syslog at %s: %w", w.address, err)
+	}
+	w.conn = conn
+	return nil
+}
+
+// format builds the RFC 5424 message. MSGID and STRUCTURED-DATA are nil.
+func (w *syslogWriter) format(severity int, t time.Time, msg string) string {
+	return fmt.Sprintf("<%d>1 %s %s %s %d - - %s",
+		w.facility*8+severity,
+		t.Format("2006-01-02T15:04:05.000000Z07:00"),
+		w.hostname, w.tag, w.pid, msg)
+}
+
+func (w *syslogWriter) send(severity int, t time.Time, msg string) error {
+	line := w.format(severity, t, msg)
+	if w.network == "tcp" {
+		line = fmt.Sprintf("%d %s", len(line), line)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn == nil {
+		if err := w.connect(); err != nil {
+			return err
+		}
+	}
+	if _, err := w.conn.Write([]byte(line)); err != nil {
+		// Reconnect once, e.g. after a syslog server restart
+		w.conn.Close()
+		w.conn = nil
+		if err := w.connect(); err != nil {
+			return err
+		}
+		_, err = w.conn.Write([]byte(line))
+		return err
+	}
+	return nil
+}
+
+// Close closes the connection. A later message reconnects.
+func (w *syslogWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+// syslogCore is a zapcore.Core that sends each entry as one syslog message.
+type syslogCore struct {
+	zapcore.LevelEnabler
+	enc zapcore.Encoder
+	w   *syslogWriter
+}
+
+func (c *syslogCore) With(fields []zapcore.Field) zapcore.Core {
+	enc := c.enc.Clone()
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+	return &syslogCore{LevelEnabler: c.LevelEnabler, enc: enc, w: c.w}
+}
+
+func (c *syslogCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *syslogCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+	msg := strings.TrimRight(buf.String(), "\n")
+	buf.Free()
+	return c.w.send(syslogSeverity(ent.Level), ent.Time, msg)
+}
+
+func (c *syslogCore) Sync() error {
+	return nil
+}
+
+func getOrDefault(value, def string) string {
+	if value == "" {
+		return def
+	}
+	return value
+}
@@ -0,0 +1,95 @@
+package logging
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+var rfc5424 = regexp.MustCompile(`^<(\d+)>1 \d{4}-\d\d-\d\dT\d\d:\d\d:\d\d\.\d{6}\S* \S+ idrac-inventory \d+ - - (.*)$`)
+
+func newSyslogTestLogger(t *testing.T, cfg SyslogConfig) *zap.Logger {
+	w, err := newSyslogWriter(cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() { w.Close() })
+
+	enc := zapcore.NewJSONEncoder(zapcore.EncoderConfig{MessageKey: "msg"})
+	return zap.New(&syslogCore{LevelEnabler: zapcore.DebugLevel, enc: enc, w: w})
+}
+
+func TestSyslog_UDP(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer pc.Close()
+
+	logger := newSyslogTestLogger(t, SyslogConfig{Network: "udp", Address: pc.LocalAddr().String(), Facility: "local0"})
+	logger.With(zap.String("host", "10.0.0.1")).Warn("failed to collect memory info")
+
+	buf := make([]byte, 2048)
+	require.NoError(t, pc.SetReadDeadline(time.Now().Add(5*time.Second)))
+	n, _, err := pc.ReadFrom(buf)
+	require.NoError(t, err)
+
+	m := rfc5424.FindStringSubmatch(string(buf[:n]))
+	require.NotNil(t, m, string(buf[:n]))
+	assert.Equal(t, strconv.Itoa(16*8+4), m[1], "local0.warning")
+	assert.Equal(t, `{"msg":"failed to collect memory info","host":"10.0.0.1"}`, m[2])
+}
+
+func TestSyslog_TCPOctetCounting(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		length, _ := r.ReadString(' ')
+		n, _ := strconv.Atoi(strings.TrimSpace(length))
+		msg := make([]byte, n)
+		_, _ = io.ReadFull(r, msg)
+		received <- string(msg)
+	}()
+
+	logger := newSyslogTestLogger(t, SyslogConfig{Network: "tcp", Address: ln.Addr().String()})
+	logger.Error("scan failed\nwith details")
+
+	select {
+	case msg := <-received:
+		m := rfc5424.FindStringSubmatch(msg)
+		require.NotNil(t, m, msg)
+		assert.Equal(t, strconv.Itoa(3*8+3), m[1], "daemon.err")
+		assert.Contains(t, m[2], `scan failed\nwith details`)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no syslog message received")
+	}
+}
+
+func TestSyslog_InvalidConfig(t *testing.T) {
+	_, err := newSyslogWriter(SyslogConfig{Network: "http", Address: "x"})
+	assert.Error(t, err)
+
+	_, err = newSyslogWriter(SyslogConfig{Network: "udp"})
+	assert.Error(t, err)
+
+	_, err = newSyslogWriter(SyslogConfig{Network: "udp", Address: "127.0.0.1:514", Facility: "nope"})
+	assert.Error(t, err)
+
+	assert.True(t, ValidSyslogFacility("LOCAL7"))
+	assert.False(t, ValidSyslogFacility("local8"))
+}
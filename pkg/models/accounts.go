@@ -0,0 +1,131 @@
+// Package models defines the core data structures used throughout the application.
+// This file holds the audit of the local iDRAC user accounts.
+package models
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Redfish account roles of an iDRAC.
+const (
+	RoleAdministrator = "Administrator"
+	RoleOperator      = "Operator"
+	RoleReadOnly      = "ReadOnly"
+)
+
+// DefaultUserName is the factory default iDRAC user.
+const DefaultUserName = "root"
+
+// AccountInfo describes a local iDRAC user account.
+type AccountInfo struct {
+	ID       string `json:"id"`
+	UserName string `json:"user_name"`
+	Role     string `json:"role,omitempty"` // Administrator, Operator, ReadOnly or None
+	Enabled  bool   `json:"enabled"`
+	Locked   bool   `json:"locked,omitempty"`
+}
+
+// AccountAudit is the account configuration of an iDRAC.
+type AccountAudit struct {
+	// Accounts are the configured accounts; the empty slots of the iDRAC
+	// account table are left out.
+	Accounts []AccountInfo `json:"accounts"`
+
+	// DefaultCredentials is true if the iDRAC accepts the factory default
+	// password of root, nil if it was not probed.
+	DefaultCredentials *bool `json:"default_credentials,omitempty"`
+
+	// MaxAdministrators is the accepted number of enabled administrators
+	// (0 for no limit).
+	MaxAdministrators int `json:"max_administrators,omitempty"`
+}
+
+// Enabled returns the enabled accounts.
+func (a AccountAudit) Enabled() []AccountInfo {
+	var enabled []AccountInfo
+	for _, acc := range a.Accounts {
+		if acc.Enabled {
+			enabled = append(enabled, acc)
+		}
+	}
+	return enabled
+}
+
+// Administrators returns the enabled accounts with the Administrator role.
+func (a AccountAudit) Administrators() []AccountInfo {
+	var admins []AccountInfo
+	for _, acc := range a.Enabled() {
+		if acc.Role == RoleAdministrator {
+			admins = append(admins, acc)
+		}
+	}
+	return admins
+}
+
+// Risks lists the risky settings of the account configuration: the
+// factory default password still working, the default root user still
+// enabled and more administrators than accepted.
+func (a AccountAudit) Risks() []string {
+	var risks []string
+	if a.DefaultCredentials != nil && *a.DefaultCredentials {
+		risks = append(risks, "default password of root accepted")
+	}
+	for _, acc := range a.Enabled() {
+		if acc.UserName == DefaultUserName {
+			risks = append(risks, "default user root enabled")
+			break
+		}
+	}
+	if n := len(a.Administrators()); a.MaxAdministrators > 0 && n > a.MaxAdministrators {
+		risks = append(risks, fmt.Sprintf("%d administrators (max %d)", n, a.MaxAdministrators))
+	}
+	return risks
+}
+
+// AccountRisk is an entry of the account audit report.
+type AccountRisk struct {
+	Host               string   `json:"host"`
+	ServiceTag         string   `json:"service_tag,omitempty"`
+	EnabledUsers       []string `json:"enabled_users"`
+	Administrators     []string `json:"administrators"`
+	DefaultCredentials *bool    `json:"default_credentials,omitempty"`
+	Risks              []string `json:"risks"`
+}
+
+// BuildAccountAuditReport lists the audited servers with a risky account
+// configuration, most risks first. Servers without an account audit are
+// skipped.
+func BuildAccountAuditReport(results []ServerInfo) []AccountRisk {
+	var report []AccountRisk
+	for _, s := range results {
+		if s.Error != nil || s.Accounts == nil {
+			continue
+		}
+		risks := s.Accounts.Risks()
+		if len(risks) == 0 {
+			continue
+		}
+		r := AccountRisk{
+			Host:               s.Host,
+			ServiceTag:         s.ServiceTag,
+			DefaultCredentials: s.Accounts.DefaultCredentials,
+			Risks:              risks,
+		}
+		for _, acc := range s.Accounts.Enabled() {
+			r.EnabledUsers = append(r.EnabledUsers, acc.UserName)
+		}
+		for _, acc := range s.Accounts.Administrators() {
+			r.Administrators = append(r.Administrators, acc.UserName)
+		}
+		report = append(report, r)
+	}
+
+	sort.SliceStable(report, func(i, j int) bool {
+		if len(report[i].Risks) != len(report[j].Risks) {
+			return len(report[i].Risks) > len(report[j].Risks)
+		}
+		return report[i].Host < report[j].Host
+	})
+	return report
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountAudit_Risks(t *testing.T) {
+	accepted, rejected := true, false
+	audit := AccountAudit{
+		Accounts: []AccountInfo{
+			{ID: "2", UserName: "root", Role: RoleAdministrator, Enabled: true},
+			{ID: "3", UserName: "ops", Role: RoleAdministrator, Enabled: true},
+			{ID: "4", UserName: "monitor", Role: RoleReadOnly, Enabled: true},
+			{ID: "5", UserName: "old-admin", Role: RoleAdministrator},
+		},
+		DefaultCredentials: &accepted,
+		MaxAdministrators:  1,
+	}
+	assert.Len(t, audit.Enabled(), 3)
+	assert.Len(t, audit.Administrators(), 2, "disabled administrators don't count")
+	assert.Equal(t, []string{
+		"default password of root accepted",
+		"default user root enabled",
+		"2 administrators (max 1)",
+	}, audit.Risks())
+
+	audit.Accounts[0].Enabled = false
+	audit.DefaultCredentials = &rejected
+	assert.Empty(t, audit.Risks())
+}
+
+func TestBuildAccountAuditReport(t *testing.T) {
+	results := []ServerInfo{
+		{Host: "clean", Accounts: &AccountAudit{Accounts: []AccountInfo{{UserName: "ops", Role: RoleAdministrator, Enabled: true}}}},
+		{Host: "one", Accounts: &AccountAudit{Accounts: []AccountInfo{{UserName: "root", Role: RoleAdministrator, Enabled: true}}}},
+		{Host: "two", Accounts: &AccountAudit{
+			Accounts:          []AccountInfo{{UserName: "root", Role: RoleAdministrator, Enabled: true}, {UserName: "ops", Role: RoleAdministrator, Enabled: true}},
+			MaxAdministrators: 1,
+		}},
+		{Host: "not-audited"},
+	}
+
+	report := BuildAccountAuditReport(results)
+	require.Len(t, report, 2)
+	assert.Equal(t, "two", report[0].Host, "most risks first")
+	assert.Equal(t, []string{"root", "ops"}, report[0].EnabledUsers)
+	assert.Equal(t, "one", report[1].Host)
+}
@@ -0,0 +1,100 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Sources of ServerInfo.ManufactureDate.
+const (
+	ManufactureDateIDRAC    = "idrac"    // Dell OEM DellSystem
+	ManufactureDateWarranty = "warranty" // ship date of the Dell warranty API
+)
+
+// AgeBuckets are the upper bounds in years of the age distribution; a last
+// bucket counts the older servers.
+var AgeBuckets = []int{1, 3, 5, 7}
+
+// AgeBucket counts the servers whose age in years is at least the bound of
+// the previous bucket and below MaxYears. MaxYears is 0 for the last,
+// unbounded bucket and -1 for the servers of unknown age.
+type AgeBucket struct {
+	MaxYears int `json:"max_years"`
+	Servers  int `json:"servers"`
+}
+
+// Label returns the range of the bucket, e.g. "1-3 years", "< 1 year",
+// "≥ 7 years" or "unknown".
+func (b AgeBucket) Label(prev int) string {
+	switch {
+	case b.MaxYears < 0:
+		return "unknown"
+	case b.MaxYears == 0:
+		return fmt.Sprintf("≥ %d years", prev)
+	case prev == 0 && b.MaxYears == 1:
+		return "< 1 year"
+	case prev == 0:
+		return fmt.Sprintf("< %d years", b.MaxYears)
+	}
+	return fmt.Sprintf("%d-%d years", prev, b.MaxYears)
+}
+
+// AgeYears returns the age of the server at now in years since its
+// manufacture date, and false if the date is not known.
+func (s ServerInfo) AgeYears(now time.Time) (float64, bool) {
+	if s.ManufactureDate == nil {
+		return 0, false
+	}
+	return now.Sub(*s.ManufactureDate).Hours() / (24 * 365.25), true
+}
+
+// ageHistogram accumulates an age distribution. The zero value is ready to
+// use.
+type ageHistogram struct {
+	dates   []time.Time
+	unknown int
+}
+
+func (h *ageHistogram) add(srv ServerInfo) {
+	if srv.ManufactureDate == nil {
+		h.unknown++
+		return
+	}
+	h.dates = append(h.dates, *srv.ManufactureDate)
+}
+
+// buckets returns the distribution at now, or nil if no server has a known
+// age.
+func (h *ageHistogram) buckets(now time.Time) []AgeBucket {
+	if len(h.dates) == 0 {
+		return nil
+	}
+	out := make([]AgeBucket, len(AgeBuckets)+1, len(AgeBuckets)+2)
+	for i, years := range AgeBuckets {
+		out[i].MaxYears = years
+	}
+	for _, date := range h.dates {
+		age, _ := ServerInfo{ManufactureDate: &date}.AgeYears(now)
+		i := 0
+		for i < len(AgeBuckets) && age >= float64(AgeBuckets[i]) {
+			i++
+		}
+		out[i].Servers++
+	}
+	if h.unknown > 0 {
+		out = append(out, AgeBucket{MaxYears: -1, Servers: h.unknown})
+	}
+	return out
+}
+
+// AgeDistribution counts the successfully scanned servers per age bucket
+// at now, or returns nil if no server has a known manufacture date.
+func AgeDistribution(servers []ServerInfo, now time.Time) []AgeBucket {
+	var h ageHistogram
+	for _, srv := range servers {
+		if srv.Error == nil {
+			h.add(srv)
+		}
+	}
+	return h.buckets(now)
+}
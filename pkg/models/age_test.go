@@ -0,0 +1,71 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func builtOn(date string) ServerInfo {
+	t, _ := time.Parse("2006-01-02", date)
+	return ServerInfo{ManufactureDate: &t}
+}
+
+func TestAgeDistribution(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	servers := []ServerInfo{
+		builtOn("2025-01-10"),
+		builtOn("2023-01-10"),
+		builtOn("2024-03-01"),
+		builtOn("2016-03-01"),
+		{Model: "PowerEdge R750"},
+		{Host: "10.0.0.9", Error: errors.New("timeout")},
+	}
+
+	ages := AgeDistribution(servers, now)
+	require.Len(t, ages, 6)
+	assert.Equal(t, []AgeBucket{
+		{MaxYears: 1, Servers: 1},
+		{MaxYears: 3, Servers: 2},
+		{MaxYears: 5, Servers: 0},
+		{MaxYears: 7, Servers: 0},
+		{MaxYears: 0, Servers: 1},
+		{MaxYears: -1, Servers: 1},
+	}, ages)
+
+	labels := make([]string, len(ages))
+	prev := 0
+	for i, b := range ages {
+		labels[i] = b.Label(prev)
+		prev = b.MaxYears
+	}
+	assert.Equal(t, []string{"< 1 year", "1-3 years", "3-5 years", "5-7 years", "≥ 7 years", "unknown"}, labels)
+
+	assert.Nil(t, AgeDistribution([]ServerInfo{{Model: "PowerEdge R750"}}, now), "no known age")
+}
+
+func TestServerInfo_AgeYears(t *testing.T) {
+	srv := builtOn("2020-06-01")
+	age, ok := srv.AgeYears(time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC))
+	assert.True(t, ok)
+	assert.InDelta(t, 5, age, 0.01)
+
+	_, ok = ServerInfo{}.AgeYears(time.Now())
+	assert.False(t, ok)
+}
+
+func TestAggregator_Ages(t *testing.T) {
+	srv := testServer("10.0.0.1", 3200, 256, 960)
+	built := time.Now().AddDate(-2, 0, 0)
+	srv.ManufactureDate = &built
+
+	inv := GroupByConfiguration([]ServerInfo{srv, testServer("10.0.0.2", 3200, 256, 960)}, CollectionStats{})
+	require.Len(t, inv.Ages, 6)
+	assert.Equal(t, 1, inv.Ages[1].Servers)
+	assert.Equal(t, 1, inv.Ages[5].Servers, "unknown")
+
+	assert.Nil(t, GroupByConfiguration([]ServerInfo{testServer("10.0.0.2", 3200, 256, 960)}, CollectionStats{}).Ages)
+}
@@ -0,0 +1,501 @@
+// Package models defines the core data structures used throughout the application.
+// This file provides aggregation models for grouping servers by hardware configuration.
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HardwareFingerprint uniquely identifies a hardware configuration.
+// Servers with identical fingerprints are grouped together in the report.
+type HardwareFingerprint struct {
+	Manufacturer      string `json:"manufacturer"`
+	Model             string `json:"model"`
+	Generation        string `json:"generation,omitempty"` // Dell generation, e.g. "15G"
+	CPUCount          int    `json:"cpu_count"`
+	CPUModel          string `json:"cpu_model"`
+	CPUCoresPerSocket int    `json:"cpu_cores_per_socket"`
+	CPUSpeedMHz       int    `json:"cpu_speed_mhz"`
+	RAMTotalGiB       int    `json:"ram_total_gib"`
+	RAMModuleSizeGiB  int    `json:"ram_module_size_gib"` // size of a single DIMM in GiB
+	RAMType           string `json:"ram_type"`
+	RAMSpeedMHz       int    `json:"ram_speed_mhz"`
+	RAMSlotsTotal     int    `json:"ram_slots_total"`
+	StorageSummary    string `json:"storage_summary"` // e.g. "2×960GB SSD, 4×16000GB HDD"
+	// GPU / Accelerator ("Beschleuniger" in German iDRAC)
+	GPUCount     int    `json:"gpu_count"`
+	GPUModel     string `json:"gpu_model"`      // model of the first GPU (all assumed identical)
+	GPUMemoryGiB int    `json:"gpu_memory_gib"` // VRAM per GPU in GiB
+
+	// Bucketed ranges, set instead of the exact values when FingerprintOptions
+	// define RAM or storage buckets (e.g. "256–512 GiB", "≥ 20 TB").
+	RAMRange     string `json:"ram_range,omitempty"`
+	StorageRange string `json:"storage_range,omitempty"`
+}
+
+// RAMDisplay returns the RAM bucket label if set, otherwise the exact total.
+func (f HardwareFingerprint) RAMDisplay() string {
+	if f.RAMRange != "" {
+		return f.RAMRange
+	}
+	return fmt.Sprintf("%d GiB", f.RAMTotalGiB)
+}
+
+// StorageDisplay returns the storage bucket label if set, otherwise the storage summary.
+func (f HardwareFingerprint) StorageDisplay() string {
+	if f.StorageRange != "" {
+		return f.StorageRange
+	}
+	return f.StorageSummary
+}
+
+// Fingerprint attribute names that can be excluded from config grouping.
+const (
+	FingerprintAttrStorage       = "storage"
+	FingerprintAttrGPU           = "gpu"
+	FingerprintAttrRAMSpeed      = "ram_speed"
+	FingerprintAttrRAMModuleSize = "ram_module_size"
+	FingerprintAttrRAMSlots      = "ram_slots"
+	FingerprintAttrCPUSpeed      = "cpu_speed"
+)
+
+// FingerprintAttrs lists all FingerprintAttr* names, e.g. to validate
+// FingerprintOptions.Ignore.
+var FingerprintAttrs = []string{
+	FingerprintAttrStorage,
+	FingerprintAttrGPU,
+	FingerprintAttrRAMSpeed,
+	FingerprintAttrRAMModuleSize,
+	FingerprintAttrRAMSlots,
+	FingerprintAttrCPUSpeed,
+}
+
+// FingerprintOptions customises how hardware fingerprints are built, so that
+// differences that don't matter operationally do not split config groups.
+type FingerprintOptions struct {
+	// Ignore lists attributes (FingerprintAttr* constants) excluded from the fingerprint.
+	Ignore []string
+
+	// RAMBucketsGiB, if set, replaces the exact RAM total with the range it falls
+	// into. Boundaries must be ascending, e.g. [128, 256, 512, 1024].
+	RAMBucketsGiB []int
+
+	// StorageBucketsTB, if set, replaces the per-drive storage summary with the
+	// range the server's total storage falls into, e.g. [1, 10, 50].
+	StorageBucketsTB []float64
+}
+
+// ignores returns true if the given attribute is excluded from the fingerprint.
+func (o FingerprintOptions) ignores(attr string) bool {
+	for _, a := range o.Ignore {
+		if a == attr {
+			return true
+		}
+	}
+	return false
+}
+
+// Key returns a stable string key for hardware config (excludes manufacturer/model —
+// those are the model-group key). Used as the config-subgroup discriminator.
+func (f HardwareFingerprint) Key() string {
+	return fmt.Sprintf("%d|%s|%d|%d|%d|%s|%d|%s|%d|%d|%s|%s|%d|%s|%d",
+		f.CPUCount, f.CPUModel, f.CPUCoresPerSocket, f.CPUSpeedMHz,
+		f.RAMTotalGiB, f.RAMRange, f.RAMModuleSizeGiB, f.RAMType, f.RAMSpeedMHz, f.RAMSlotsTotal,
+		f.StorageSummary, f.StorageRange,
+		f.GPUCount, f.GPUModel, f.GPUMemoryGiB,
+	)
+}
+
+// HardwareGroup represents a set of servers that share identical hardware configuration
+// within a model group.
+type HardwareGroup struct {
+	Fingerprint    HardwareFingerprint `json:"fingerprint"`
+	Count          int                 `json:"count"`
+	Servers        []ServerInfo        `json:"servers"`
+	TotalStorageTB float64             `json:"total_storage_tb,omitempty"` // from first server
+}
+
+// ModelGroup represents all servers of a specific hardware model (e.g. "Dell PowerEdge R440").
+// Servers with different hardware configurations within the same model appear as separate
+// ConfigGroups, making it easy to spot e.g. "50× R440: 45 with config A, 5 with config B".
+type ModelGroup struct {
+	Manufacturer string          `json:"manufacturer"`
+	Model        string          `json:"model"`
+	TotalCount   int             `json:"total_count"`
+	ConfigGroups []HardwareGroup `json:"config_groups"`
+
+	// Chassis holds the physical properties of the model from the model
+	// catalog (nil if the model is not in the catalog).
+	Chassis *ChassisSpec `json:"chassis,omitempty"`
+}
+
+// ChassisSpec describes the physical properties of a server model, as used
+// for NetBox device types and rack planning.
+type ChassisSpec struct {
+	UHeight  float64 `json:"u_height" yaml:"u_height"`
+	Airflow  string  `json:"airflow,omitempty" yaml:"airflow"` // NetBox airflow choice, e.g. front-to-rear
+	WeightKg float64 `json:"weight_kg,omitempty" yaml:"weight_kg"`
+	PSUSlots int     `json:"psu_slots,omitempty" yaml:"psu_slots"`
+}
+
+// String returns a short summary, e.g. "2U · front-to-rear · 28.6 kg · 2 PSU slots".
+func (c ChassisSpec) String() string {
+	parts := []string{strconv.FormatFloat(c.UHeight, 'f', -1, 64) + "U"}
+	if c.Airflow != "" {
+		parts = append(parts, c.Airflow)
+	}
+	if c.WeightKg > 0 {
+		parts = append(parts, strconv.FormatFloat(c.WeightKg, 'f', -1, 64)+" kg")
+	}
+	if c.PSUSlots > 0 {
+		parts = append(parts, fmt.Sprintf("%d PSU slots", c.PSUSlots))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// DisplayModel returns a human-friendly model string including manufacturer.
+func (g ModelGroup) DisplayModel() string {
+	if g.Manufacturer != "" && !strings.HasPrefix(strings.ToLower(g.Model), strings.ToLower(g.Manufacturer)) {
+		return g.Manufacturer + " " + g.Model
+	}
+	return g.Model
+}
+
+// AggregatedInventory is the top-level structure for the aggregated hardware report.
+type AggregatedInventory struct {
+	GeneratedAt     time.Time       `json:"generated_at"`
+	TotalServers    int             `json:"total_servers"`
+	SuccessfulCount int             `json:"successful_count"`
+	FailedCount     int             `json:"failed_count"`
+	ModelGroups     []ModelGroup    `json:"model_groups"`
+	FailedServers   []ServerInfo    `json:"failed_servers,omitempty"`
+	Stats           CollectionStats `json:"stats"`
+
+	// Ages counts the servers by age (nil if no manufacture date is known)
+	Ages []AgeBucket `json:"age_distribution,omitempty"`
+
+	// Firmware is the firmware policy compliance per model (nil if no
+	// policy is configured)
+	Firmware []FirmwareComplianceRate `json:"firmware_compliance,omitempty"`
+}
+
+// TotalConfigGroups returns the total number of distinct hardware-config sub-groups
+// across all model groups.
+func (inv AggregatedInventory) TotalConfigGroups() int {
+	total := 0
+	for _, mg := range inv.ModelGroups {
+		total += len(mg.ConfigGroups)
+	}
+	return total
+}
+
+// Servers returns the servers of the report: those of the model groups in
+// report order, then the failed ones.
+func (inv AggregatedInventory) Servers() []ServerInfo {
+	var servers []ServerInfo
+	for _, mg := range inv.ModelGroups {
+		for _, cg := range mg.ConfigGroups {
+			servers = append(servers, cg.Servers...)
+		}
+	}
+	return append(servers, inv.FailedServers...)
+}
+
+// GroupByConfiguration groups servers using a two-level hierarchy:
+//  1. Model group — all servers of the same Manufacturer+Model
+//  2. Config subgroup — servers within a model that share the same hardware config
+//
+// Failed servers are collected separately.
+// Model groups are sorted by total count (descending); config subgroups within each model
+// are also sorted by count (descending).
+func GroupByConfiguration(servers []ServerInfo, stats CollectionStats) AggregatedInventory {
+	return GroupByConfigurationWithOptions(servers, stats, FingerprintOptions{})
+}
+
+// GroupByConfigurationWithOptions is like GroupByConfiguration but builds the
+// config-subgroup fingerprints according to opts.
+func GroupByConfigurationWithOptions(servers []ServerInfo, stats CollectionStats, opts FingerprintOptions) AggregatedInventory {
+	agg := NewAggregator(opts)
+	for _, srv := range servers {
+		agg.Add(srv)
+	}
+	return agg.Inventory(stats)
+}
+
+// Aggregator builds an AggregatedInventory incrementally, one server at a
+// time, so results can be grouped as they stream in from a scan.
+type Aggregator struct {
+	opts  FingerprintOptions
+	inv   AggregatedInventory
+	fleet FleetBuilder
+	ages  ageHistogram
+	fw    firmwareTally
+
+	modelMap map[aggregateModelKey]*ModelGroup
+	// configIdxMap maps "manufacturer|model\x00fpKey" → index in ModelGroup.ConfigGroups.
+	configIdxMap map[string]int
+	modelOrder   []aggregateModelKey
+}
+
+type aggregateModelKey struct {
+	manufacturer string
+	model        string
+}
+
+// NewAggregator creates an empty Aggregator using the given fingerprint options.
+func NewAggregator(opts FingerprintOptions) *Aggregator {
+	return &Aggregator{
+		opts:         opts,
+		modelMap:     make(map[aggregateModelKey]*ModelGroup),
+		configIdxMap: make(map[string]int),
+	}
+}
+
+// Add adds a server to its model and config group (or to the failed servers).
+func (a *Aggregator) Add(srv ServerInfo) {
+	a.inv.TotalServers++
+
+	if srv.Error != nil {
+		a.inv.FailedServers = append(a.inv.FailedServers, srv)
+		a.inv.FailedCount++
+		return
+	}
+
+	a.inv.SuccessfulCount++
+	a.fleet.Add(srv)
+	a.ages.add(srv)
+	a.fw.add(srv)
+
+	mk := aggregateModelKey{manufacturer: srv.Manufacturer, model: srv.Model}
+	if _, exists := a.modelMap[mk]; !exists {
+		a.modelMap[mk] = &ModelGroup{
+			Manufacturer: srv.Manufacturer,
+			Model:        srv.Model,
+		}
+		a.modelOrder = append(a.modelOrder, mk)
+	}
+	mg := a.modelMap[mk]
+	mg.TotalCount++
+
+	fp := buildFingerprint(srv, a.opts)
+	combKey := fmt.Sprintf("%s|%s\x00%s", mk.manufacturer, mk.model, fp.Key())
+
+	if idx, exists := a.configIdxMap[combKey]; exists {
+		mg.ConfigGroups[idx].Servers = append(mg.ConfigGroups[idx].Servers, srv)
+		mg.ConfigGroups[idx].Count++
+	} else {
+		a.configIdxMap[combKey] = len(mg.ConfigGroups)
+		group := HardwareGroup{
+			Fingerprint: fp,
+			Count:       1,
+			Servers:     []ServerInfo{srv},
+		}
+		// Only show a per-group storage total if storage is compared exactly;
+		// otherwise the first server's total is not representative.
+		if fp.StorageSummary != "" {
+			group.TotalStorageTB = srv.TotalStorageTB
+		}
+		mg.ConfigGroups = append(mg.ConfigGroups, group)
+	}
+}
+
+// Inventory returns the aggregated inventory of all servers added so far.
+// Model groups are sorted by total count (descending); config subgroups within
+// each model are also sorted by count (descending). The fleet totals in the
+// stats are those of the servers added, so results read back from files
+// without them get them too.
+func (a *Aggregator) Inventory(stats CollectionStats) AggregatedInventory {
+	inv := a.inv
+	inv.GeneratedAt = time.Now().UTC()
+	inv.Ages = a.ages.buckets(inv.GeneratedAt)
+	inv.Firmware = a.fw.rates()
+	inv.Stats = stats
+	inv.Stats.Fleet = nil
+	if inv.SuccessfulCount > 0 {
+		fleet := a.fleet.Summary()
+		inv.Stats.Fleet = &fleet
+	}
+	inv.ModelGroups = nil
+
+	for _, mk := range a.modelOrder {
+		mg := *a.modelMap[mk]
+		mg.ConfigGroups = append([]HardwareGroup(nil), mg.ConfigGroups...)
+		inv.ModelGroups = append(inv.ModelGroups, mg)
+	}
+
+	// Sort model groups by total count descending.
+	sort.Slice(inv.ModelGroups, func(i, j int) bool {
+		return inv.ModelGroups[i].TotalCount > inv.ModelGroups[j].TotalCount
+	})
+
+	// Sort config subgroups within each model by count descending.
+	for i := range inv.ModelGroups {
+		sort.Slice(inv.ModelGroups[i].ConfigGroups, func(a, b int) bool {
+			return inv.ModelGroups[i].ConfigGroups[a].Count > inv.ModelGroups[i].ConfigGroups[b].Count
+		})
+	}
+
+	return inv
+}
+
+// buildFingerprint derives a HardwareFingerprint from a successfully scanned server.
+func buildFingerprint(s ServerInfo, opts FingerprintOptions) HardwareFingerprint {
+	fp := HardwareFingerprint{
+		Manufacturer:   s.Manufacturer,
+		Model:          s.Model,
+		CPUCount:       s.CPUCount,
+		CPUModel:       s.CPUModel,
+		RAMTotalGiB:    int(s.TotalMemoryGiB + 0.5), // round to nearest GiB
+		RAMSlotsTotal:  s.MemorySlotsTotal,
+		StorageSummary: NormalizeStorageSummary(s.Drives),
+		GPUCount:       s.GPUCount,
+	}
+	if p := s.PlatformInfo(); p != nil {
+		fp.Generation = p.Generation
+	}
+
+	// Pull per-socket CPU details from the first populated CPU socket.
+	for _, cpu := range s.CPUs {
+		if cpu.Cores > 0 {
+			fp.CPUCoresPerSocket = cpu.Cores
+			fp.CPUSpeedMHz = cpu.MaxSpeedMHz
+			if fp.CPUModel == "" {
+				fp.CPUModel = cpu.Model
+			}
+			break
+		}
+	}
+
+	// Pull memory type/speed/module-size from the first populated DIMM.
+	for _, mem := range s.Memory {
+		if mem.IsPopulated() {
+			fp.RAMType = mem.Type
+			fp.RAMSpeedMHz = mem.SpeedMHz
+			fp.RAMModuleSizeGiB = (mem.CapacityMiB + 512) / 1024 // round to nearest GiB
+			break
+		}
+	}
+
+	// Pull GPU model and VRAM from the first GPU (assumes homogeneous GPU config).
+	if len(s.GPUs) > 0 {
+		fp.GPUModel = s.GPUs[0].Model
+		fp.GPUMemoryGiB = int(s.GPUs[0].MemoryGB() + 0.5) // round to nearest GiB
+	}
+
+	applyFingerprintOptions(&fp, s, opts)
+
+	return fp
+}
+
+// applyFingerprintOptions clears ignored attributes and replaces exact values
+// with bucket labels according to opts.
+func applyFingerprintOptions(fp *HardwareFingerprint, s ServerInfo, opts FingerprintOptions) {
+	if opts.ignores(FingerprintAttrCPUSpeed) {
+		fp.CPUSpeedMHz = 0
+	}
+	if opts.ignores(FingerprintAttrRAMSpeed) {
+		fp.RAMSpeedMHz = 0
+	}
+	if opts.ignores(FingerprintAttrRAMModuleSize) {
+		fp.RAMModuleSizeGiB = 0
+	}
+	if opts.ignores(FingerprintAttrRAMSlots) {
+		fp.RAMSlotsTotal = 0
+	}
+	if opts.ignores(FingerprintAttrGPU) {
+		fp.GPUCount = 0
+		fp.GPUModel = ""
+		fp.GPUMemoryGiB = 0
+	}
+
+	if len(opts.RAMBucketsGiB) > 0 {
+		bounds := make([]float64, len(opts.RAMBucketsGiB))
+		for i, b := range opts.RAMBucketsGiB {
+			bounds[i] = float64(b)
+		}
+		fp.RAMRange = bucketLabel(float64(fp.RAMTotalGiB), bounds, "GiB")
+		fp.RAMTotalGiB = 0
+	}
+
+	if opts.ignores(FingerprintAttrStorage) {
+		fp.StorageSummary = ""
+	} else if len(opts.StorageBucketsTB) > 0 {
+		fp.StorageRange = bucketLabel(s.TotalStorageTB, opts.StorageBucketsTB, "TB")
+		fp.StorageSummary = ""
+	}
+}
+
+// bucketLabel returns a human-readable label for the range that value falls into.
+// bounds must be ascending; ranges are half-open: [bounds[i], bounds[i+1]).
+func bucketLabel(value float64, bounds []float64, unit string) string {
+	if value < bounds[0] {
+		return fmt.Sprintf("< %s %s", formatBound(bounds[0]), unit)
+	}
+	for i := 1; i < len(bounds); i++ {
+		if value < bounds[i] {
+			return fmt.Sprintf("%s–%s %s", formatBound(bounds[i-1]), formatBound(bounds[i]), unit)
+		}
+	}
+	return fmt.Sprintf("≥ %s %s", formatBound(bounds[len(bounds)-1]), unit)
+}
+
+// formatBound formats a bucket boundary without a trailing ".0" for whole numbers.
+func formatBound(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// NormalizeStorageSummary builds a canonical, sorted storage summary string.
+// Drives are grouped by marketing capacity (see DriveInfo.MarketingGB) and
+// media type, so drives of the same labelled size but slightly different
+// exact capacity fall into one group.
+// Example output: "2×960GB SSD, 4×16000GB HDD"
+func NormalizeStorageSummary(drives []DriveInfo) string {
+	if len(drives) == 0 {
+		return "no drives"
+	}
+
+	type driveKey struct {
+		capacityGB int
+		mediaType  string
+	}
+
+	counts := make(map[driveKey]int)
+	var keys []driveKey
+
+	for _, d := range drives {
+		k := driveKey{
+			capacityGB: d.MarketingGB(),
+			mediaType:  d.MediaType,
+		}
+		if _, exists := counts[k]; !exists {
+			keys = append(keys, k)
+		}
+		counts[k]++
+	}
+
+	// Sort: SSDs first, then by capacity descending.
+	sort.Slice(keys, func(i, j int) bool {
+		ki, kj := keys[i], keys[j]
+		if ki.mediaType != kj.mediaType {
+			if ki.mediaType == "SSD" {
+				return true
+			}
+			if kj.mediaType == "SSD" {
+				return false
+			}
+			return ki.mediaType < kj.mediaType
+		}
+		return ki.capacityGB > kj.capacityGB
+	})
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, fmt.Sprintf("%d×%dGB %s", counts[k], k.capacityGB, k.mediaType))
+	}
+
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,161 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testServer(host string, ramSpeed int, ramGiB float64, driveGB float64) ServerInfo {
+	return ServerInfo{
+		Host:           host,
+		Manufacturer:   "Dell Inc.",
+		Model:          "PowerEdge R750",
+		CPUCount:       2,
+		CPUModel:       "Intel Xeon Gold 6342",
+		TotalMemoryGiB: ramGiB,
+		Memory: []MemoryInfo{
+			{Slot: "A1", State: MemoryStateEnabled, CapacityMiB: 32768, Type: "DDR4", SpeedMHz: ramSpeed},
+		},
+		Drives: []DriveInfo{
+			{Name: "Disk.Bay.0", CapacityGB: driveGB, MediaType: "SSD"},
+		},
+		TotalStorageTB: driveGB / 1024,
+	}
+}
+
+func TestGroupByConfiguration_SplitsOnDifferences(t *testing.T) {
+	servers := []ServerInfo{
+		testServer("10.0.0.1", 3200, 256, 960),
+		testServer("10.0.0.2", 2933, 256, 960),
+	}
+
+	inv := GroupByConfiguration(servers, CollectionStats{})
+
+	require.Len(t, inv.ModelGroups, 1)
+	assert.Equal(t, 2, inv.ModelGroups[0].TotalCount)
+	assert.Len(t, inv.ModelGroups[0].ConfigGroups, 2)
+	assert.Equal(t, "15G", inv.ModelGroups[0].ConfigGroups[0].Fingerprint.Generation)
+}
+
+func TestAggregatedInventory_Servers(t *testing.T) {
+	failed := ServerInfo{Host: "10.0.0.9", Error: errors.New("connection refused")}
+	inv := GroupByConfiguration([]ServerInfo{
+		testServer("10.0.0.1", 3200, 256, 960),
+		failed,
+		testServer("10.0.0.2", 2933, 256, 960),
+	}, CollectionStats{})
+
+	var hosts []string
+	for _, s := range inv.Servers() {
+		hosts = append(hosts, s.Host)
+	}
+	assert.ElementsMatch(t, []string{"10.0.0.1", "10.0.0.2", "10.0.0.9"}, hosts)
+	assert.Equal(t, "10.0.0.9", hosts[2], "failed servers come last")
+}
+
+func TestGroupByConfigurationWithOptions_IgnoreRAMSpeed(t *testing.T) {
+	servers := []ServerInfo{
+		testServer("10.0.0.1", 3200, 256, 960),
+		testServer("10.0.0.2", 2933, 256, 960),
+	}
+
+	inv := GroupByConfigurationWithOptions(servers, CollectionStats{}, FingerprintOptions{
+		Ignore: []string{FingerprintAttrRAMSpeed},
+	})
+
+	require.Len(t, inv.ModelGroups, 1)
+	require.Len(t, inv.ModelGroups[0].ConfigGroups, 1)
+	assert.Equal(t, 2, inv.ModelGroups[0].ConfigGroups[0].Count)
+	assert.Equal(t, 0, inv.ModelGroups[0].ConfigGroups[0].Fingerprint.RAMSpeedMHz)
+}
+
+func TestGroupByConfigurationWithOptions_IgnoreStorage(t *testing.T) {
+	servers := []ServerInfo{
+		testServer("10.0.0.1", 3200, 256, 960),
+		testServer("10.0.0.2", 3200, 256, 1920),
+	}
+
+	inv := GroupByConfigurationWithOptions(servers, CollectionStats{}, FingerprintOptions{
+		Ignore: []string{FingerprintAttrStorage},
+	})
+
+	require.Len(t, inv.ModelGroups[0].ConfigGroups, 1)
+	cg := inv.ModelGroups[0].ConfigGroups[0]
+	assert.Empty(t, cg.Fingerprint.StorageDisplay())
+	assert.Zero(t, cg.TotalStorageTB)
+}
+
+func TestGroupByConfigurationWithOptions_RAMBuckets(t *testing.T) {
+	servers := []ServerInfo{
+		testServer("10.0.0.1", 3200, 384, 960),
+		testServer("10.0.0.2", 3200, 320, 960),
+		testServer("10.0.0.3", 3200, 1024, 960),
+	}
+
+	inv := GroupByConfigurationWithOptions(servers, CollectionStats{}, FingerprintOptions{
+		RAMBucketsGiB: []int{128, 256, 512, 1024},
+	})
+
+	require.Len(t, inv.ModelGroups[0].ConfigGroups, 2)
+	assert.Equal(t, 2, inv.ModelGroups[0].ConfigGroups[0].Count)
+	assert.Equal(t, "256–512 GiB", inv.ModelGroups[0].ConfigGroups[0].Fingerprint.RAMDisplay())
+	assert.Equal(t, "≥ 1024 GiB", inv.ModelGroups[0].ConfigGroups[1].Fingerprint.RAMDisplay())
+}
+
+func TestGroupByConfigurationWithOptions_StorageBuckets(t *testing.T) {
+	servers := []ServerInfo{
+		testServer("10.0.0.1", 3200, 256, 960),
+		testServer("10.0.0.2", 3200, 256, 480),
+	}
+
+	inv := GroupByConfigurationWithOptions(servers, CollectionStats{}, FingerprintOptions{
+		StorageBucketsTB: []float64{1, 10},
+	})
+
+	require.Len(t, inv.ModelGroups[0].ConfigGroups, 1)
+	assert.Equal(t, "< 1 TB", inv.ModelGroups[0].ConfigGroups[0].Fingerprint.StorageDisplay())
+}
+
+func TestBucketLabel(t *testing.T) {
+	bounds := []float64{1, 2.5, 10}
+
+	assert.Equal(t, "< 1 TB", bucketLabel(0.5, bounds, "TB"))
+	assert.Equal(t, "1–2.5 TB", bucketLabel(1, bounds, "TB"))
+	assert.Equal(t, "2.5–10 TB", bucketLabel(9.99, bounds, "TB"))
+	assert.Equal(t, "≥ 10 TB", bucketLabel(10, bounds, "TB"))
+}
+
+func TestGroupByConfiguration_MatchesAggregator(t *testing.T) {
+	servers := []ServerInfo{
+		{Host: "10.0.0.1", Model: "R750", CPUCount: 2},
+		{Host: "10.0.0.2", Model: "R650", CPUCount: 1},
+		{Host: "10.0.0.3", Model: "R750", CPUCount: 2},
+		{Host: "10.0.0.4", Error: errors.New("timeout")},
+	}
+
+	agg := NewAggregator(FingerprintOptions{})
+	for _, srv := range servers {
+		agg.Add(srv)
+	}
+	streamed := agg.Inventory(CollectionStats{})
+	batch := GroupByConfiguration(servers, CollectionStats{})
+
+	streamed.GeneratedAt = batch.GeneratedAt
+	assert.Equal(t, batch, streamed)
+	assert.Equal(t, 3, streamed.SuccessfulCount)
+	assert.Equal(t, "R750", streamed.ModelGroups[0].Model)
+}
+
+func TestNormalizeStorageSummary(t *testing.T) {
+	drives := []DriveInfo{
+		{CapacityGB: 894.25, CapacityBytes: 960197124096, MediaType: "SSD"},
+		{CapacityGB: 894.25, MediaType: "SSD"}, // no exact size from older results
+		{CapacityGB: 894.3, CapacityBytes: 960252198912, MediaType: "SSD"},
+		{CapacityGB: 7452, MediaType: "HDD"},
+	}
+	assert.Equal(t, "3×960GB SSD, 1×8000GB HDD", NormalizeStorageSummary(drives))
+	assert.Equal(t, "no drives", NormalizeStorageSummary(nil))
+}
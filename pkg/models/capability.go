@@ -0,0 +1,177 @@
+package models
+
+import "sort"
+
+// Capability states of a collector phase, see ServerInfo.Capabilities.
+const (
+	CapabilitySupported   = "supported"
+	CapabilityDegraded    = "degraded"
+	CapabilityUnsupported = "unsupported"
+)
+
+// UnsupportedEndpoint is a Redfish resource the iDRAC answered with 404 Not
+// Found or 501 Not Implemented, e.g. the Power resource on firmware that only
+// has PowerSubsystem.
+type UnsupportedEndpoint struct {
+	Phase  string `json:"phase"`
+	Path   string `json:"path"`
+	Status int    `json:"status"`
+}
+
+// SetCapability records the capability state of a collector phase and the
+// endpoints it found missing, replacing those of an earlier run of the phase.
+func (s *ServerInfo) SetCapability(phase, state string, missing []UnsupportedEndpoint) {
+	if s.Capabilities == nil {
+		s.Capabilities = make(map[string]string)
+	}
+	s.Capabilities[phase] = state
+
+	var kept []UnsupportedEndpoint
+	for _, e := range s.UnsupportedEndpoints {
+		if e.Phase != phase {
+			kept = append(kept, e)
+		}
+	}
+	for _, e := range missing {
+		e.Phase = phase
+		kept = append(kept, e)
+	}
+	sort.SliceStable(kept, func(i, j int) bool {
+		if kept[i].Phase != kept[j].Phase {
+			return kept[i].Phase < kept[j].Phase
+		}
+		return kept[i].Path < kept[j].Path
+	})
+	s.UnsupportedEndpoints = kept
+}
+
+// UnsupportedEndpointsOf returns the missing endpoints of a collector phase.
+func (s *ServerInfo) UnsupportedEndpointsOf(phase string) []UnsupportedEndpoint {
+	var out []UnsupportedEndpoint
+	for _, e := range s.UnsupportedEndpoints {
+		if e.Phase == phase {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// CapabilityCount counts the servers of a group by the capability state of
+// one collector phase.
+type CapabilityCount struct {
+	Supported   int `json:"supported"`
+	Degraded    int `json:"degraded"`
+	Unsupported int `json:"unsupported"`
+}
+
+// Checked returns the number of servers whose support of the phase is known.
+func (c CapabilityCount) Checked() int {
+	return c.Supported + c.Degraded + c.Unsupported
+}
+
+// EndpointGap is an unsupported endpoint and the hosts missing it.
+type EndpointGap struct {
+	Phase  string   `json:"phase"`
+	Path   string   `json:"path"`
+	Status int      `json:"status"`
+	Hosts  []string `json:"hosts"`
+}
+
+// CapabilityGroup is the feature support of the servers sharing a model and
+// iDRAC firmware version, which together decide the endpoints an iDRAC has.
+type CapabilityGroup struct {
+	Model                string                     `json:"model"`
+	IDRACFirmwareVersion string                     `json:"idrac_firmware_version"`
+	Servers              int                        `json:"servers"`
+	Phases               map[string]CapabilityCount `json:"phases"`
+	Endpoints            []EndpointGap              `json:"unsupported_endpoints,omitempty"`
+}
+
+// SummarizeCapabilities groups the successfully scanned servers by model
+// and iDRAC firmware version and counts their capability states per
+// collector phase. Groups are sorted by model and firmware version.
+func SummarizeCapabilities(servers []ServerInfo) []CapabilityGroup {
+	type key struct{ model, firmware string }
+	groups := make(map[key]*CapabilityGroup)
+	gaps := make(map[key]map[UnsupportedEndpoint]*EndpointGap)
+
+	for _, srv := range servers {
+		if srv.Error != nil || len(srv.Capabilities) == 0 {
+			continue
+		}
+		k := key{srv.Model, srv.IDRACFirmwareVersion}
+		g, ok := groups[k]
+		if !ok {
+			g = &CapabilityGroup{Model: srv.Model, IDRACFirmwareVersion: srv.IDRACFirmwareVersion, Phases: make(map[string]CapabilityCount)}
+			groups[k] = g
+			gaps[k] = make(map[UnsupportedEndpoint]*EndpointGap)
+		}
+		g.Servers++
+		for phase, state := range srv.Capabilities {
+			c := g.Phases[phase]
+			switch state {
+			case CapabilitySupported:
+				c.Supported++
+			case CapabilityDegraded:
+				c.Degraded++
+			case CapabilityUnsupported:
+				c.Unsupported++
+			}
+			g.Phases[phase] = c
+		}
+		for _, e := range srv.UnsupportedEndpoints {
+			gap, ok := gaps[k][e]
+			if !ok {
+				gap = &EndpointGap{Phase: e.Phase, Path: e.Path, Status: e.Status}
+				gaps[k][e] = gap
+			}
+			gap.Hosts = append(gap.Hosts, srv.Host)
+		}
+	}
+
+	out := make([]CapabilityGroup, 0, len(groups))
+	for k, g := range groups {
+		for _, gap := range gaps[k] {
+			sort.Strings(gap.Hosts)
+			g.Endpoints = append(g.Endpoints, *gap)
+		}
+		sort.Slice(g.Endpoints, func(i, j int) bool {
+			if g.Endpoints[i].Phase != g.Endpoints[j].Phase {
+				return g.Endpoints[i].Phase < g.Endpoints[j].Phase
+			}
+			return g.Endpoints[i].Path < g.Endpoints[j].Path
+		})
+		out = append(out, *g)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Model != out[j].Model {
+			return out[i].Model < out[j].Model
+		}
+		return CompareVersions(out[i].IDRACFirmwareVersion, out[j].IDRACFirmwareVersion) < 0
+	})
+	return out
+}
+
+// CapabilityPhases returns the collector phases checked in any group: the
+// built-in phases in scan order, then custom collectors by name.
+func CapabilityPhases(groups []CapabilityGroup) []string {
+	seen := make(map[string]bool)
+	for _, g := range groups {
+		for phase := range g.Phases {
+			seen[phase] = true
+		}
+	}
+	var phases []string
+	for _, phase := range Phases {
+		if seen[phase] {
+			phases = append(phases, phase)
+			delete(seen, phase)
+		}
+	}
+	custom := make([]string, 0, len(seen))
+	for phase := range seen {
+		custom = append(custom, phase)
+	}
+	sort.Strings(custom)
+	return append(phases, custom...)
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummarizeCapabilities(t *testing.T) {
+	power := UnsupportedEndpoint{Phase: PhasePower, Path: "/redfish/v1/Chassis/System.Embedded.1/Power", Status: 404}
+	servers := []ServerInfo{
+		{Host: "b", Model: "PowerEdge R740", IDRACFirmwareVersion: "4.40.00.00",
+			Capabilities: map[string]string{PhaseMemory: CapabilitySupported, PhasePower: CapabilityDegraded}, UnsupportedEndpoints: []UnsupportedEndpoint{power}},
+		{Host: "a", Model: "PowerEdge R740", IDRACFirmwareVersion: "4.40.00.00",
+			Capabilities: map[string]string{PhaseMemory: CapabilitySupported, PhasePower: CapabilityDegraded}, UnsupportedEndpoints: []UnsupportedEndpoint{power}},
+		{Host: "c", Model: "PowerEdge R740", IDRACFirmwareVersion: "4.40.00.00",
+			Capabilities: map[string]string{PhaseMemory: CapabilitySupported, PhasePower: CapabilitySupported}},
+		{Host: "d", Model: "PowerEdge R740", IDRACFirmwareVersion: "10.1.0.0",
+			Capabilities: map[string]string{"gpu_telemetry": CapabilityUnsupported}},
+		{Host: "e", Model: "PowerEdge R740", Error: errors.New("timeout"), Capabilities: map[string]string{PhaseMemory: CapabilityUnsupported}},
+		{Host: "f", Model: "PowerEdge R740"},
+	}
+
+	groups := SummarizeCapabilities(servers)
+	assert.Equal(t, []CapabilityGroup{
+		{
+			Model: "PowerEdge R740", IDRACFirmwareVersion: "4.40.00.00", Servers: 3,
+			Phases: map[string]CapabilityCount{
+				PhaseMemory: {Supported: 3},
+				PhasePower:  {Supported: 1, Degraded: 2},
+			},
+			Endpoints: []EndpointGap{{Phase: PhasePower, Path: power.Path, Status: 404, Hosts: []string{"a", "b"}}},
+		},
+		{
+			Model: "PowerEdge R740", IDRACFirmwareVersion: "10.1.0.0", Servers: 1,
+			Phases: map[string]CapabilityCount{"gpu_telemetry": {Unsupported: 1}},
+		},
+	}, groups, "firmware versions sort numerically")
+
+	assert.Equal(t, []string{PhaseMemory, PhasePower, "gpu_telemetry"}, CapabilityPhases(groups))
+	assert.Equal(t, 3, groups[0].Phases[PhasePower].Checked())
+}
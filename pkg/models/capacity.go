@@ -0,0 +1,92 @@
+// Package models defines the core data structures used throughout the application.
+// This file computes expansion headroom (free DIMM slots, drive bays, PCIe slots).
+package models
+
+import "sort"
+
+// ServerHeadroom describes where future upgrades can land on a single server.
+// Pointer fields are nil when the value could not be detected.
+type ServerHeadroom struct {
+	Host                string  `json:"host"`
+	Name                string  `json:"name,omitempty"`
+	Model               string  `json:"model"`
+	FreeDIMMSlots       int     `json:"free_dimm_slots"`
+	LargestDIMMGiB      float64 `json:"largest_dimm_gib"`
+	MaxAdditionalRAMGiB float64 `json:"max_additional_ram_gib"`
+	FreeDriveBays       *int    `json:"free_drive_bays,omitempty"`
+	FreePCIeSlots       *int    `json:"free_pcie_slots,omitempty"`
+}
+
+// CapacityHeadroom is the per-server and fleet-wide expansion headroom.
+type CapacityHeadroom struct {
+	Servers []ServerHeadroom `json:"servers"`
+
+	TotalFreeDIMMSlots       int     `json:"total_free_dimm_slots"`
+	TotalMaxAdditionalRAMGiB float64 `json:"total_max_additional_ram_gib"`
+	TotalFreeDriveBays       int     `json:"total_free_drive_bays"`
+	TotalFreePCIeSlots       int     `json:"total_free_pcie_slots"`
+
+	// Number of servers for which drive bay / PCIe slot counts were detectable.
+	DriveBaysKnown int `json:"drive_bays_known"`
+	PCIeSlotsKnown int `json:"pcie_slots_known"`
+}
+
+// BuildCapacityHeadroom computes expansion headroom for all successfully scanned
+// servers. Additional RAM is estimated as free DIMM slots × largest installed DIMM.
+// Servers are sorted by max additional RAM (descending), then by host.
+func BuildCapacityHeadroom(servers []ServerInfo) CapacityHeadroom {
+	var result CapacityHeadroom
+
+	for _, srv := range servers {
+		if srv.Error != nil {
+			continue
+		}
+
+		h := ServerHeadroom{
+			Host:          srv.Host,
+			Name:          srv.HostName,
+			Model:         srv.Model,
+			FreeDIMMSlots: srv.MemorySlotsFree,
+		}
+
+		for _, mem := range srv.Memory {
+			if mem.IsPopulated() && mem.CapacityGB() > h.LargestDIMMGiB {
+				h.LargestDIMMGiB = mem.CapacityGB()
+			}
+		}
+		h.MaxAdditionalRAMGiB = float64(h.FreeDIMMSlots) * h.LargestDIMMGiB
+
+		if srv.DriveBaysTotal > 0 {
+			free := srv.DriveBaysTotal - srv.DriveCount
+			if free < 0 {
+				free = 0
+			}
+			h.FreeDriveBays = &free
+			result.TotalFreeDriveBays += free
+			result.DriveBaysKnown++
+		}
+
+		if srv.PCIeSlotsTotal > 0 {
+			free := srv.PCIeSlotsTotal - srv.PCIeSlotsUsed
+			if free < 0 {
+				free = 0
+			}
+			h.FreePCIeSlots = &free
+			result.TotalFreePCIeSlots += free
+			result.PCIeSlotsKnown++
+		}
+
+		result.TotalFreeDIMMSlots += h.FreeDIMMSlots
+		result.TotalMaxAdditionalRAMGiB += h.MaxAdditionalRAMGiB
+		result.Servers = append(result.Servers, h)
+	}
+
+	sort.Slice(result.Servers, func(i, j int) bool {
+		if result.Servers[i].MaxAdditionalRAMGiB != result.Servers[j].MaxAdditionalRAMGiB {
+			return result.Servers[i].MaxAdditionalRAMGiB > result.Servers[j].MaxAdditionalRAMGiB
+		}
+		return result.Servers[i].Host < result.Servers[j].Host
+	})
+
+	return result
+}
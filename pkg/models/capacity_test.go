@@ -0,0 +1,43 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildCapacityHeadroom(t *testing.T) {
+	a := testServer("10.0.0.1", 3200, 256, 960)
+	a.MemorySlotsFree = 8
+	a.Memory = append(a.Memory, MemoryInfo{Slot: "A2", State: MemoryStateEnabled, CapacityMiB: 65536})
+	a.DriveCount = 2
+	a.DriveBaysTotal = 8
+	a.PCIeSlotsTotal = 6
+	a.PCIeSlotsUsed = 2
+
+	b := testServer("10.0.0.2", 3200, 256, 960)
+	b.MemorySlotsFree = 2
+
+	failed := ServerInfo{Host: "10.0.0.3", Error: errors.New("timeout")}
+
+	c := BuildCapacityHeadroom([]ServerInfo{b, a, failed})
+
+	require.Len(t, c.Servers, 2)
+	assert.Equal(t, "10.0.0.1", c.Servers[0].Host)
+	assert.Equal(t, 64.0, c.Servers[0].LargestDIMMGiB)
+	assert.Equal(t, 512.0, c.Servers[0].MaxAdditionalRAMGiB)
+	require.NotNil(t, c.Servers[0].FreeDriveBays)
+	assert.Equal(t, 6, *c.Servers[0].FreeDriveBays)
+	require.NotNil(t, c.Servers[0].FreePCIeSlots)
+	assert.Equal(t, 4, *c.Servers[0].FreePCIeSlots)
+
+	assert.Nil(t, c.Servers[1].FreeDriveBays)
+	assert.Nil(t, c.Servers[1].FreePCIeSlots)
+
+	assert.Equal(t, 10, c.TotalFreeDIMMSlots)
+	assert.Equal(t, 576.0, c.TotalMaxAdditionalRAMGiB)
+	assert.Equal(t, 1, c.DriveBaysKnown)
+	assert.Equal(t, 1, c.PCIeSlotsKnown)
+}
@@ -0,0 +1,65 @@
+// Package models defines the core data structures used throughout the application.
+// This file holds the TLS certificate inventory and the expiry report.
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+// CertificateInfo describes the TLS certificate presented by an iDRAC.
+type CertificateInfo struct {
+	Subject    string    `json:"subject"`
+	Issuer     string    `json:"issuer"`
+	NotBefore  time.Time `json:"not_before"`
+	NotAfter   time.Time `json:"not_after"`
+	SelfSigned bool      `json:"self_signed"`
+}
+
+// DaysLeft returns the number of whole days until the certificate expires
+// (negative if already expired).
+func (c CertificateInfo) DaysLeft(now time.Time) int {
+	return int(c.NotAfter.Sub(now).Hours() / 24)
+}
+
+// IsExpired returns true if the certificate is no longer valid at now.
+func (c CertificateInfo) IsExpired(now time.Time) bool {
+	return now.After(c.NotAfter)
+}
+
+// ExpiringCertificate is an entry of the certificate expiry report.
+type ExpiringCertificate struct {
+	Host     string `json:"host"`
+	Name     string `json:"name,omitempty"`
+	DaysLeft int    `json:"days_left"`
+	CertificateInfo
+}
+
+// FindExpiringCertificates returns the certificates that expire within the given
+// number of days of now (including already expired ones), soonest first.
+// Hosts without certificate data are skipped.
+func FindExpiringCertificates(servers []ServerInfo, withinDays int, now time.Time) []ExpiringCertificate {
+	cutoff := now.Add(time.Duration(withinDays) * 24 * time.Hour)
+
+	result := make([]ExpiringCertificate, 0)
+	for _, srv := range servers {
+		if srv.Certificate == nil || srv.Certificate.NotAfter.After(cutoff) {
+			continue
+		}
+		result = append(result, ExpiringCertificate{
+			Host:            srv.Host,
+			Name:            srv.GetDisplayName(),
+			DaysLeft:        srv.Certificate.DaysLeft(now),
+			CertificateInfo: *srv.Certificate,
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].NotAfter.Equal(result[j].NotAfter) {
+			return result[i].NotAfter.Before(result[j].NotAfter)
+		}
+		return result[i].Host < result[j].Host
+	})
+
+	return result
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindExpiringCertificates(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	cert := func(notAfter time.Time) *CertificateInfo {
+		return &CertificateInfo{Subject: "CN=idrac", NotAfter: notAfter}
+	}
+
+	servers := []ServerInfo{
+		{Host: "10.0.0.1", Certificate: cert(now.AddDate(0, 0, 10))},
+		{Host: "10.0.0.2", Certificate: cert(now.AddDate(0, 0, -3))},
+		{Host: "10.0.0.3", Certificate: cert(now.AddDate(1, 0, 0))},
+		{Host: "10.0.0.4"},
+	}
+
+	certs := FindExpiringCertificates(servers, 30, now)
+
+	require.Len(t, certs, 2)
+	assert.Equal(t, "10.0.0.2", certs[0].Host)
+	assert.Equal(t, -3, certs[0].DaysLeft)
+	assert.True(t, certs[0].IsExpired(now))
+	assert.Equal(t, "10.0.0.1", certs[1].Host)
+	assert.Equal(t, 10, certs[1].DaysLeft)
+}
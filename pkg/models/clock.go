@@ -0,0 +1,57 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// ClockInfo compares the clock of an iDRAC with the local clock.
+type ClockInfo struct {
+	// BMCTime is the DateTime reported by the manager.
+	BMCTime time.Time `json:"bmc_time"`
+
+	// DriftSeconds is the BMC time minus the local time at the moment of
+	// the request, positive if the iDRAC clock is ahead.
+	DriftSeconds float64 `json:"drift_seconds"`
+
+	// MaxDriftSeconds is the threshold the drift was checked against.
+	MaxDriftSeconds float64 `json:"max_drift_seconds"`
+}
+
+// NewClockInfo measures the drift of a BMC time against the local time at
+// which it was read, e.g. the midpoint of the request.
+func NewClockInfo(bmcTime, local time.Time, maxDrift time.Duration) ClockInfo {
+	return ClockInfo{
+		BMCTime:         bmcTime,
+		DriftSeconds:    bmcTime.Sub(local).Seconds(),
+		MaxDriftSeconds: maxDrift.Seconds(),
+	}
+}
+
+// Drift returns the drift as a duration, rounded to the second (the
+// resolution of Redfish DateTime).
+func (c ClockInfo) Drift() time.Duration {
+	return (time.Duration(c.DriftSeconds * float64(time.Second))).Round(time.Second)
+}
+
+// Drifted reports whether the drift exceeds the threshold in either
+// direction.
+func (c ClockInfo) Drifted() bool {
+	d := c.DriftSeconds
+	if d < 0 {
+		d = -d
+	}
+	return c.MaxDriftSeconds > 0 && d > c.MaxDriftSeconds
+}
+
+// String describes the drift, e.g. "iDRAC clock 5m12s ahead".
+func (c ClockInfo) String() string {
+	d := c.Drift()
+	switch {
+	case d > 0:
+		return fmt.Sprintf("iDRAC clock %s ahead", d)
+	case d < 0:
+		return fmt.Sprintf("iDRAC clock %s behind", -d)
+	}
+	return "iDRAC clock in sync"
+}
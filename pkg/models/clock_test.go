@@ -0,0 +1,25 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClockInfo(t *testing.T) {
+	local := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	ahead := NewClockInfo(local.Add(5*time.Minute+12*time.Second), local, 2*time.Minute)
+	assert.Equal(t, 312.0, ahead.DriftSeconds)
+	assert.True(t, ahead.Drifted())
+	assert.Equal(t, "iDRAC clock 5m12s ahead", ahead.String())
+
+	behind := NewClockInfo(local.Add(-3*time.Minute), local, 2*time.Minute)
+	assert.True(t, behind.Drifted(), "drift in either direction counts")
+	assert.Equal(t, "iDRAC clock 3m0s behind", behind.String())
+
+	synced := NewClockInfo(local.Add(400*time.Millisecond), local, 2*time.Minute)
+	assert.False(t, synced.Drifted())
+	assert.Equal(t, "iDRAC clock in sync", synced.String())
+}
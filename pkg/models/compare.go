@@ -0,0 +1,259 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// FleetComparison is the difference between two result sets of the same
+// fleet, e.g. last month's and today's, for audit sign-off. Servers are
+// matched by service tag, or by host if they have none.
+type FleetComparison struct {
+	Added     []ServerRef `json:"added"`
+	Removed   []ServerRef `json:"removed"`
+	Changed   []HostDiff  `json:"changed"`
+	Unchanged int         `json:"unchanged"`
+
+	// Unscanned are servers that failed in one of the result sets (or in
+	// the only one they appear in), so their hardware cannot be compared.
+	Unscanned []ServerRef `json:"unscanned,omitempty"`
+
+	Old FleetSummary `json:"old_totals"`
+	New FleetSummary `json:"new_totals"`
+}
+
+// ServerRef identifies a server in a FleetComparison.
+type ServerRef struct {
+	Host       string `json:"host"`
+	ServiceTag string `json:"service_tag,omitempty"`
+	Model      string `json:"model,omitempty"`
+}
+
+// String identifies the server, e.g. "10.0.0.1 (ABC1234, PowerEdge R750)".
+func (r ServerRef) String() string {
+	var details []string
+	for _, v := range []string{r.ServiceTag, r.Model} {
+		if v != "" {
+			details = append(details, v)
+		}
+	}
+	if len(details) == 0 {
+		return r.Host
+	}
+	return fmt.Sprintf("%s (%s)", r.Host, strings.Join(details, ", "))
+}
+
+// HostDiff is a server whose hardware changed between the result sets.
+type HostDiff struct {
+	ServerRef
+	Changes []HostChange `json:"changes"`
+}
+
+// HostChange is one attribute or component of a server that differs, e.g.
+// {memory, "256 GiB in 8 DIMMs", "512 GiB in 16 DIMMs"}. Old is empty for
+// added components and New for removed ones.
+type HostChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old,omitempty"`
+	New   string `json:"new,omitempty"`
+}
+
+// String renders the change, e.g. "memory 256 GiB in 8 DIMMs -> 512 GiB in
+// 16 DIMMs" or "drive added Disk.Bay.2 S3X... (...)".
+func (c HostChange) String() string {
+	switch {
+	case strings.HasSuffix(c.Field, " added"):
+		return c.Field + " " + c.New
+	case strings.HasSuffix(c.Field, " removed"):
+		return c.Field + " " + c.Old
+	}
+	none := func(v string) string {
+		if v == "" {
+			return "none"
+		}
+		return v
+	}
+	return fmt.Sprintf("%s %s -> %s", c.Field, none(c.Old), none(c.New))
+}
+
+// CompareFleets compares the old and the new results of a fleet.
+func CompareFleets(old, cur []ServerInfo) FleetComparison {
+	c := FleetComparison{
+		Added:   []ServerRef{},
+		Removed: []ServerRef{},
+		Changed: []HostDiff{},
+		Old:     FleetSummaryOf(old),
+		New:     FleetSummaryOf(cur),
+	}
+
+	byTag := map[string]int{}
+	byKey := map[string]int{}
+	for i, s := range old {
+		if tag := strings.ToUpper(s.ServiceTag); tag != "" {
+			byTag[tag] = i
+		}
+		byKey[s.Key()] = i
+	}
+
+	matched := make([]bool, len(old))
+	match := func(s ServerInfo) (int, bool) {
+		if i, ok := byTag[strings.ToUpper(s.ServiceTag)]; ok && s.ServiceTag != "" && !matched[i] {
+			return i, true
+		}
+		// A failed result has no service tag, so fall back to the host
+		i, ok := byKey[s.Key()]
+		if !ok || matched[i] || (s.ServiceTag != "" && old[i].ServiceTag != "" && !strings.EqualFold(s.ServiceTag, old[i].ServiceTag)) {
+			return 0, false
+		}
+		return i, true
+	}
+
+	for _, s := range cur {
+		i, ok := match(s)
+		switch {
+		case !ok && s.Error != nil:
+			c.Unscanned = append(c.Unscanned, refOf(s))
+		case !ok:
+			c.Added = append(c.Added, refOf(s))
+		case s.Error != nil || old[i].Error != nil:
+			matched[i] = true
+			ref := refOf(s)
+			if s.Error != nil {
+				ref = refOf(old[i])
+				ref.Host = s.Host
+			}
+			c.Unscanned = append(c.Unscanned, ref)
+		default:
+			matched[i] = true
+			if changes := diffServer(old[i], s); len(changes) > 0 {
+				c.Changed = append(c.Changed, HostDiff{ServerRef: refOf(s), Changes: changes})
+			} else {
+				c.Unchanged++
+			}
+		}
+	}
+	for i, s := range old {
+		switch {
+		case matched[i]:
+		case s.Error != nil:
+			c.Unscanned = append(c.Unscanned, refOf(s))
+		default:
+			c.Removed = append(c.Removed, refOf(s))
+		}
+	}
+
+	for _, refs := range [][]ServerRef{c.Added, c.Removed, c.Unscanned} {
+		sort.Slice(refs, func(i, j int) bool { return refs[i].Host < refs[j].Host })
+	}
+	sort.Slice(c.Changed, func(i, j int) bool { return c.Changed[i].Host < c.Changed[j].Host })
+	return c
+}
+
+// HasChanges reports whether any server was added, removed or changed.
+func (c FleetComparison) HasChanges() bool {
+	return len(c.Added) > 0 || len(c.Removed) > 0 || len(c.Changed) > 0
+}
+
+func refOf(s ServerInfo) ServerRef {
+	return ServerRef{Host: s.Host, ServiceTag: s.ServiceTag, Model: s.Model}
+}
+
+// compareAttributes are the summarized attributes compared per server.
+var compareAttributes = []struct {
+	field string
+	value func(ServerInfo) string
+}{
+	{"host", func(s ServerInfo) string { return s.Key() }},
+	{"hostname", func(s ServerInfo) string { return s.HostName }},
+	{"model", func(s ServerInfo) string { return s.Model }},
+	{"bios_version", func(s ServerInfo) string { return s.BiosVersion }},
+	{"cpu", func(s ServerInfo) string {
+		if s.CPUCount == 0 {
+			return ""
+		}
+		cores := 0
+		for _, cpu := range s.CPUs {
+			cores += cpu.Cores
+		}
+		return fmt.Sprintf("%d× %s (%d cores)", s.CPUCount, s.CPUModel, cores)
+	}},
+	{"memory", func(s ServerInfo) string {
+		return fmt.Sprintf("%.0f GiB in %d DIMMs", s.TotalMemoryGiB, s.MemorySlotsUsed)
+	}},
+	{"storage", func(s ServerInfo) string {
+		return fmt.Sprintf("%d drives, %.2f TB", s.DriveCount, s.TotalStorageTB)
+	}},
+	{"gpu", func(s ServerInfo) string {
+		if s.GPUCount == 0 {
+			return ""
+		}
+		return fmt.Sprintf("%d× %s", s.GPUCount, s.GPUs[0].Model)
+	}},
+	{"psu", func(s ServerInfo) string {
+		var installed []string
+		for _, psu := range s.PowerSupplies {
+			if psu.IsInstalled() {
+				installed = append(installed, fmt.Sprintf("%.0f W", psu.CapacityWatts))
+			}
+		}
+		return strings.Join(installed, ", ")
+	}},
+}
+
+// diffServer returns the changed attributes of a server, followed by the
+// drives and DIMMs added or removed (by serial number, so a replaced part
+// shows up even if the totals stay the same).
+func diffServer(old, cur ServerInfo) []HostChange {
+	var changes []HostChange
+	for _, a := range compareAttributes {
+		if o, n := a.value(old), a.value(cur); o != n {
+			changes = append(changes, HostChange{Field: a.field, Old: o, New: n})
+		}
+	}
+
+	drives := func(s ServerInfo) map[string]string {
+		m := map[string]string{}
+		for _, d := range s.Drives {
+			if d.SerialNumber != "" {
+				m[d.SerialNumber] = fmt.Sprintf("%s %s (%s, %.0f GiB %s)", d.Name, d.SerialNumber, d.Model, d.CapacityGB, d.MediaType)
+			}
+		}
+		return m
+	}
+	dimms := func(s ServerInfo) map[string]string {
+		m := map[string]string{}
+		for _, d := range s.Memory {
+			if d.SerialNumber != "" && d.State != MemoryStateAbsent {
+				m[d.SerialNumber] = fmt.Sprintf("%s %s (%d GiB %s)", d.Slot, d.SerialNumber, d.CapacityMiB/1024, d.Type)
+			}
+		}
+		return m
+	}
+	changes = append(changes, diffParts("drive", drives(old), drives(cur))...)
+	changes = append(changes, diffParts("dimm", dimms(old), dimms(cur))...)
+	return changes
+}
+
+// diffParts returns the parts (serial → description) removed from and added
+// to a server, sorted by description.
+func diffParts(kind string, old, cur map[string]string) []HostChange {
+	var changes []HostChange
+	for serial, desc := range old {
+		if _, ok := cur[serial]; !ok {
+			changes = append(changes, HostChange{Field: kind + " removed", Old: desc})
+		}
+	}
+	for serial, desc := range cur {
+		if _, ok := old[serial]; !ok {
+			changes = append(changes, HostChange{Field: kind + " added", New: desc})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Field != changes[j].Field {
+			return changes[i].Field > changes[j].Field // removed before added
+		}
+		return changes[i].Old+changes[i].New < changes[j].Old+changes[j].New
+	})
+	return changes
+}
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func compareServer(host, tag string) ServerInfo {
+	return ServerInfo{
+		Host:            host,
+		ServiceTag:      tag,
+		Model:           "PowerEdge R650",
+		Manufacturer:    "Dell Inc.",
+		BiosVersion:     "1.8.2",
+		CPUCount:        2,
+		CPUModel:        "Intel Xeon Gold 6338",
+		CPUs:            []CPUInfo{{Socket: "CPU.Socket.1", Cores: 32}, {Socket: "CPU.Socket.2", Cores: 32}},
+		TotalMemoryGiB:  256,
+		MemorySlotsUsed: 8,
+		Drives: []DriveInfo{
+			{Name: "Disk.Bay.0", SerialNumber: "S1", Model: "MZ7L3", CapacityGB: 894, MediaType: "SSD"},
+			{Name: "Disk.Bay.1", SerialNumber: "S2", Model: "MZ7L3", CapacityGB: 894, MediaType: "SSD"},
+		},
+		DriveCount:     2,
+		TotalStorageTB: 1.92,
+	}
+}
+
+func TestCompareFleets(t *testing.T) {
+	old := []ServerInfo{
+		compareServer("10.0.0.1", "AAA1111"),
+		compareServer("10.0.0.2", "BBB2222"),
+		compareServer("10.0.0.3", "CCC3333"),
+		compareServer("10.0.0.4", "DDD4444"),
+		compareServer("10.0.0.5", "EEE5555"),
+	}
+
+	upgraded := compareServer("10.0.0.2", "bbb2222")
+	upgraded.TotalMemoryGiB, upgraded.MemorySlotsUsed = 512, 16
+	upgraded.Drives[1].SerialNumber = "S9" // replaced drive
+	moved := compareServer("10.0.1.3", "CCC3333")
+	cur := []ServerInfo{
+		compareServer("10.0.0.1", "AAA1111"),
+		upgraded,
+		moved,
+		{Host: "10.0.0.4", Error: errors.New("timeout")},
+		compareServer("10.0.0.6", "FFF6666"),
+		{Host: "10.0.0.7", Error: errors.New("timeout")},
+	}
+
+	c := CompareFleets(old, cur)
+	assert.True(t, c.HasChanges())
+	assert.Equal(t, 1, c.Unchanged)
+	assert.Equal(t, []ServerRef{{Host: "10.0.0.6", ServiceTag: "FFF6666", Model: "PowerEdge R650"}}, c.Added)
+	assert.Equal(t, []ServerRef{{Host: "10.0.0.5", ServiceTag: "EEE5555", Model: "PowerEdge R650"}}, c.Removed)
+	assert.Equal(t, []ServerRef{
+		{Host: "10.0.0.4", ServiceTag: "DDD4444", Model: "PowerEdge R650"},
+		{Host: "10.0.0.7"},
+	}, c.Unscanned, "failed hosts are matched by host")
+
+	require.Len(t, c.Changed, 2)
+	assert.Equal(t, "10.0.0.2", c.Changed[0].Host)
+	assert.Equal(t, []HostChange{
+		{Field: "memory", Old: "256 GiB in 8 DIMMs", New: "512 GiB in 16 DIMMs"},
+		{Field: "drive removed", Old: "Disk.Bay.1 S2 (MZ7L3, 894 GiB SSD)"},
+		{Field: "drive added", New: "Disk.Bay.1 S9 (MZ7L3, 894 GiB SSD)"},
+	}, c.Changed[0].Changes)
+	assert.Equal(t, []HostChange{{Field: "host", Old: "10.0.0.3", New: "10.0.1.3"}}, c.Changed[1].Changes,
+		"matched by service tag after moving")
+
+	assert.Equal(t, 5, c.Old.Servers)
+	assert.Equal(t, 4, c.New.Servers)
+	assert.Equal(t, 1280.0, c.Old.MemoryGiB)
+	assert.Equal(t, 1280.0, c.New.MemoryGiB)
+}
+
+func TestCompareFleets_Identical(t *testing.T) {
+	servers := []ServerInfo{compareServer("10.0.0.1", "AAA1111"), compareServer("10.0.0.2", "")}
+	c := CompareFleets(servers, servers)
+	assert.False(t, c.HasChanges())
+	assert.Equal(t, 2, c.Unchanged)
+	assert.Empty(t, c.Unscanned)
+}
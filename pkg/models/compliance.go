@@ -0,0 +1,68 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Compliance is the result of checking a server against the golden config
+// (expected build) of its model or NetBox device role.
+type Compliance struct {
+	Profile    string      `json:"profile"`
+	Violations []Violation `json:"violations,omitempty"`
+}
+
+// Compliant reports whether the server matches its expected build.
+func (c Compliance) Compliant() bool {
+	return len(c.Violations) == 0
+}
+
+// Violation is a deviation of a server from its expected build.
+type Violation struct {
+	Component string `json:"component"` // cpu, memory, drives or gpus
+	Check     string `json:"check"`     // the golden config key, e.g. count or min_gib
+	Expected  string `json:"expected"`
+	Actual    string `json:"actual"`
+}
+
+// String returns e.g. "memory min_gib: expected ≥ 512, found 384".
+func (v Violation) String() string {
+	return fmt.Sprintf("%s %s: expected %s, found %s", v.Component, v.Check, v.Expected, v.Actual)
+}
+
+// ComplianceFinding is an entry of the compliance report: a server that
+// deviates from its expected build.
+type ComplianceFinding struct {
+	Host       string      `json:"host"`
+	Name       string      `json:"name,omitempty"`
+	ServiceTag string      `json:"service_tag"`
+	Model      string      `json:"model"`
+	Profile    string      `json:"profile"`
+	Violations []Violation `json:"violations"`
+}
+
+// FindNonCompliant lists the servers with golden config violations, sorted
+// by profile and host.
+func FindNonCompliant(servers []ServerInfo) []ComplianceFinding {
+	var findings []ComplianceFinding
+	for _, srv := range servers {
+		if srv.Compliance == nil || srv.Compliance.Compliant() {
+			continue
+		}
+		findings = append(findings, ComplianceFinding{
+			Host:       srv.Host,
+			Name:       srv.Name,
+			ServiceTag: srv.ServiceTag,
+			Model:      srv.Model,
+			Profile:    srv.Compliance.Profile,
+			Violations: srv.Compliance.Violations,
+		})
+	}
+	sort.Slice(findings, func(i, j int) bool {
+		if findings[i].Profile != findings[j].Profile {
+			return findings[i].Profile < findings[j].Profile
+		}
+		return findings[i].Host < findings[j].Host
+	})
+	return findings
+}
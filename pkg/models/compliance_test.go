@@ -0,0 +1,24 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindNonCompliant(t *testing.T) {
+	drives := Violation{Component: "drives", Check: "count", Expected: "4", Actual: "3"}
+	servers := []ServerInfo{
+		{Host: "10.0.0.3", ServiceTag: "CCC3333", Compliance: &Compliance{Profile: "storage", Violations: []Violation{drives}}},
+		{Host: "10.0.0.1", Compliance: &Compliance{Profile: "compute"}},
+		{Host: "10.0.0.2", ServiceTag: "BBB2222", Compliance: &Compliance{Profile: "compute", Violations: []Violation{drives}}},
+		{Host: "10.0.0.4"},
+	}
+
+	findings := FindNonCompliant(servers)
+	require.Len(t, findings, 2)
+	assert.Equal(t, "10.0.0.2", findings[0].Host, "sorted by profile")
+	assert.Equal(t, "storage", findings[1].Profile)
+	assert.Equal(t, "drives count: expected 4, found 3", findings[1].Violations[0].String())
+}
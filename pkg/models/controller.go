@@ -0,0 +1,83 @@
+package models
+
+import "fmt"
+
+// StorageControllerInfo describes a storage controller (PERC, HBA or BOSS).
+type StorageControllerInfo struct {
+	ID              string `json:"id"` // Dell FQDD, e.g. "RAID.Integrated.1-1"
+	Name            string `json:"name"`
+	Model           string `json:"model,omitempty"`
+	FirmwareVersion string `json:"firmware_version,omitempty"`
+	Health          string `json:"health"`
+	CacheMiB        int    `json:"cache_mib,omitempty"`
+	CacheHealth     string `json:"cache_health,omitempty"`
+
+	// Battery is the cache battery or cachevault of the controller, nil for
+	// controllers without one (HBAs, BOSS, software RAID).
+	Battery *BatteryInfo `json:"battery,omitempty"`
+}
+
+// BatteryInfo is the health of a controller cache battery. A battery that is
+// not OK makes the controller fall back from write-back to write-through
+// caching, which costs a lot of write performance without any other alert.
+type BatteryInfo struct {
+	Name   string `json:"name,omitempty"`
+	Health string `json:"health"`          // OK, Warning or Critical
+	State  string `json:"state,omitempty"` // Dell RAIDState: Ready, Charging, Learning, Degraded, Failed, Missing
+}
+
+// Dell controller battery states.
+const (
+	BatteryStateReady    = "Ready"
+	BatteryStateCharging = "Charging"
+	BatteryStateLearning = "Learning"
+	BatteryStateDegraded = "Degraded"
+	BatteryStateFailed   = "Failed"
+	BatteryStateMissing  = "Missing"
+)
+
+// BatteryHealth derives the health of a controller battery from the Dell
+// PrimaryStatus (OK, Degraded, Error) and RAIDState. A failed or missing
+// battery is Critical and a degraded one Warning, even if the status says
+// otherwise; charging and learning cycles are OK. It returns "" if neither
+// is known.
+func BatteryHealth(status, state string) string {
+	health := ""
+	switch status {
+	case "OK":
+		health = HealthOK
+	case "Degraded":
+		health = HealthWarning
+	case "Error":
+		health = HealthCritical
+	}
+	switch state {
+	case BatteryStateFailed, BatteryStateMissing:
+		return HealthCritical
+	case BatteryStateDegraded:
+		if health != HealthCritical {
+			return HealthWarning
+		}
+	case BatteryStateReady, BatteryStateCharging, BatteryStateLearning:
+		if health == "" {
+			return HealthOK
+		}
+	}
+	return health
+}
+
+// String returns a human-readable representation of the controller.
+func (c StorageControllerInfo) String() string {
+	name := c.Model
+	if name == "" {
+		name = c.Name
+	}
+	s := name
+	if c.FirmwareVersion != "" {
+		s += fmt.Sprintf(" (firmware %s)", c.FirmwareVersion)
+	}
+	if c.CacheMiB > 0 {
+		s += fmt.Sprintf(", %d MiB cache", c.CacheMiB)
+	}
+	return s
+}
@@ -0,0 +1,162 @@
+// Package models defines the core data structures used throughout the application.
+// This file detects duplicate serial numbers across scanned hosts.
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Duplicate kinds.
+const (
+	DuplicateServiceTag   = "service_tag"
+	DuplicateSerialNumber = "serial_number"
+	DuplicateDriveSerial  = "drive_serial"
+)
+
+// placeholderSerials are values some firmware reports instead of a real serial.
+var placeholderSerials = map[string]bool{
+	"":                       true,
+	"0":                      true,
+	"N/A":                    true,
+	"NA":                     true,
+	"NONE":                   true,
+	"UNKNOWN":                true,
+	"NOT SPECIFIED":          true,
+	"TO BE FILLED BY O.E.M.": true,
+}
+
+// Duplicate is a serial number or service tag reported by more than one host.
+// This usually indicates cloned iDRAC configs or a target that was scanned
+// under two different addresses.
+type Duplicate struct {
+	Kind  string   `json:"kind"`
+	Value string   `json:"value"`
+	Hosts []string `json:"hosts"`
+}
+
+// String returns a human-readable description of the duplicate.
+func (d Duplicate) String() string {
+	return fmt.Sprintf("duplicate %s %q reported by %s", d.Kind, d.Value, strings.Join(d.Hosts, ", "))
+}
+
+// FindDuplicates returns all service tags, system serial numbers and drive serials
+// that appear on more than one successfully scanned host. Values are compared
+// case-insensitively; empty and placeholder values are ignored.
+func FindDuplicates(servers []ServerInfo) []Duplicate {
+	type dupKey struct {
+		kind  string
+		value string
+	}
+
+	seen := make(map[dupKey]map[string]bool)
+	var order []dupKey
+
+	add := func(kind, value, host string) {
+		value = strings.ToUpper(strings.TrimSpace(value))
+		if placeholderSerials[value] {
+			return
+		}
+		k := dupKey{kind: kind, value: value}
+		if seen[k] == nil {
+			seen[k] = make(map[string]bool)
+			order = append(order, k)
+		}
+		seen[k][host] = true
+	}
+
+	for _, srv := range servers {
+		if srv.Error != nil {
+			continue
+		}
+		add(DuplicateServiceTag, srv.ServiceTag, srv.Host)
+		add(DuplicateSerialNumber, srv.SerialNumber, srv.Host)
+		for _, d := range srv.Drives {
+			add(DuplicateDriveSerial, d.SerialNumber, srv.Host)
+		}
+	}
+
+	result := make([]Duplicate, 0)
+	for _, k := range order {
+		if len(seen[k]) < 2 {
+			continue
+		}
+		hosts := make([]string, 0, len(seen[k]))
+		for h := range seen[k] {
+			hosts = append(hosts, h)
+		}
+		sort.Strings(hosts)
+		result = append(result, Duplicate{Kind: k.kind, Value: k.value, Hosts: hosts})
+	}
+
+	kindOrder := map[string]int{DuplicateServiceTag: 0, DuplicateSerialNumber: 1, DuplicateDriveSerial: 2}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Kind != result[j].Kind {
+			return kindOrder[result[i].Kind] < kindOrder[result[j].Kind]
+		}
+		return result[i].Value < result[j].Value
+	})
+
+	return result
+}
+
+// DuplicateHosts maps each host involved in any duplicate to the first duplicate
+// it was found in. Used to exclude ambiguous hosts from NetBox writes.
+func DuplicateHosts(dups []Duplicate) map[string]Duplicate {
+	hosts := make(map[string]Duplicate)
+	for _, d := range dups {
+		for _, h := range d.Hosts {
+			if _, exists := hosts[h]; !exists {
+				hosts[h] = d
+			}
+		}
+	}
+	return hosts
+}
+
+// DuplicateTracker detects duplicates incrementally while results stream in.
+// Unlike FindDuplicates it can only flag the second and later hosts reporting
+// a value; the first one has usually been processed already.
+type DuplicateTracker struct {
+	firstHost map[string]string // "kind\x00VALUE" → first host reporting it
+}
+
+// NewDuplicateTracker creates an empty DuplicateTracker.
+func NewDuplicateTracker() *DuplicateTracker {
+	return &DuplicateTracker{firstHost: make(map[string]string)}
+}
+
+// Check records the identifiers of srv and returns the first duplicate with a
+// previously checked host, if any. Failed hosts are ignored.
+func (t *DuplicateTracker) Check(srv ServerInfo) (Duplicate, bool) {
+	if srv.Error != nil {
+		return Duplicate{}, false
+	}
+
+	var dup Duplicate
+	found := false
+	check := func(kind, value string) {
+		value = strings.ToUpper(strings.TrimSpace(value))
+		if placeholderSerials[value] {
+			return
+		}
+		k := kind + "\x00" + value
+		first, seen := t.firstHost[k]
+		if !seen {
+			t.firstHost[k] = srv.Host
+			return
+		}
+		if first != srv.Host && !found {
+			dup = Duplicate{Kind: kind, Value: value, Hosts: []string{first, srv.Host}}
+			found = true
+		}
+	}
+
+	check(DuplicateServiceTag, srv.ServiceTag)
+	check(DuplicateSerialNumber, srv.SerialNumber)
+	for _, d := range srv.Drives {
+		check(DuplicateDriveSerial, d.SerialNumber)
+	}
+	return dup, found
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindDuplicates(t *testing.T) {
+	servers := []ServerInfo{
+		{Host: "10.0.0.1", ServiceTag: "ABC1234", SerialNumber: "CN1", Drives: []DriveInfo{{SerialNumber: "DRV1"}}},
+		{Host: "10.0.0.2", ServiceTag: "abc1234 ", SerialNumber: "CN2", Drives: []DriveInfo{{SerialNumber: "DRV2"}}},
+		{Host: "10.0.0.3", ServiceTag: "XYZ9876", SerialNumber: "CN3", Drives: []DriveInfo{{SerialNumber: "DRV2"}}},
+		{Host: "10.0.0.4", ServiceTag: "N/A", SerialNumber: "", Drives: []DriveInfo{{SerialNumber: ""}}},
+		{Host: "10.0.0.5", ServiceTag: "N/A"},
+		{Host: "10.0.0.6", ServiceTag: "ABC1234", Error: errors.New("timeout")},
+	}
+
+	dups := FindDuplicates(servers)
+
+	require.Len(t, dups, 2)
+	assert.Equal(t, Duplicate{Kind: DuplicateServiceTag, Value: "ABC1234", Hosts: []string{"10.0.0.1", "10.0.0.2"}}, dups[0])
+	assert.Equal(t, Duplicate{Kind: DuplicateDriveSerial, Value: "DRV2", Hosts: []string{"10.0.0.2", "10.0.0.3"}}, dups[1])
+
+	hosts := DuplicateHosts(dups)
+	assert.Len(t, hosts, 3)
+	assert.Equal(t, DuplicateServiceTag, hosts["10.0.0.2"].Kind)
+	assert.NotContains(t, hosts, "10.0.0.4")
+}
+
+func TestFindDuplicates_SameHostNotFlagged(t *testing.T) {
+	servers := []ServerInfo{
+		{Host: "10.0.0.1", Drives: []DriveInfo{{SerialNumber: "DRV1"}, {SerialNumber: "DRV1"}}},
+	}
+
+	assert.Empty(t, FindDuplicates(servers))
+}
+
+func TestDuplicateTracker(t *testing.T) {
+	tracker := NewDuplicateTracker()
+
+	_, dup := tracker.Check(ServerInfo{Host: "10.0.0.1", ServiceTag: "ABC1234", Drives: []DriveInfo{{SerialNumber: "DRV1"}, {SerialNumber: "DRV1"}}})
+	assert.False(t, dup, "first host and repeated values on the same host are not duplicates")
+
+	_, dup = tracker.Check(ServerInfo{Host: "10.0.0.2", ServiceTag: "ABC1234", Error: errors.New("timeout")})
+	assert.False(t, dup, "failed hosts are ignored")
+
+	d, dup := tracker.Check(ServerInfo{Host: "10.0.0.3", ServiceTag: "XYZ9876", Drives: []DriveInfo{{SerialNumber: "drv1"}}})
+	require.True(t, dup)
+	assert.Equal(t, Duplicate{Kind: DuplicateDriveSerial, Value: "DRV1", Hosts: []string{"10.0.0.1", "10.0.0.3"}}, d)
+}
@@ -0,0 +1,127 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultPercentiles are the scan duration percentiles of the statistics
+// unless configured otherwise.
+var DefaultPercentiles = []float64{50, 90, 99}
+
+// DurationBuckets are the upper bounds of the scan duration histogram; a
+// last, unbounded bucket counts the slower hosts.
+var DurationBuckets = []time.Duration{
+	time.Second, 2 * time.Second, 5 * time.Second, 10 * time.Second,
+	30 * time.Second, time.Minute, 2 * time.Minute, 5 * time.Minute,
+}
+
+// DurationPercentile is the scan duration within which Percent percent of
+// the hosts were scanned.
+type DurationPercentile struct {
+	Percent  float64       `json:"percent"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Label returns the percentile as "p90" (or "p99.9").
+func (p DurationPercentile) Label() string {
+	return "p" + strconv.FormatFloat(p.Percent, 'f', -1, 64)
+}
+
+// HistogramBucket counts the hosts whose scan took longer than the bound of
+// the previous bucket and at most LE. LE is 0 for the last, unbounded bucket.
+type HistogramBucket struct {
+	LE    time.Duration `json:"le"`
+	Count int           `json:"count"`
+}
+
+// Label returns the range of the bucket, e.g. "2s-5s", "<=1s" or ">5m0s".
+func (b HistogramBucket) Label(prev time.Duration) string {
+	switch {
+	case b.LE == 0:
+		return ">" + prev.String()
+	case prev == 0:
+		return "<=" + b.LE.String()
+	}
+	return prev.String() + "-" + b.LE.String()
+}
+
+// Percentiles returns the durations at percents by the nearest-rank method.
+// Returns nil if durations is empty.
+func Percentiles(durations []time.Duration, percents []float64) []DurationPercentile {
+	if len(durations) == 0 || len(percents) == 0 {
+		return nil
+	}
+	sorted := append([]time.Duration(nil), durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	out := make([]DurationPercentile, len(percents))
+	for i, p := range percents {
+		rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+		rank = min(max(rank, 1), len(sorted))
+		out[i] = DurationPercentile{Percent: p, Duration: sorted[rank-1]}
+	}
+	return out
+}
+
+// Histogram counts durations per bucket of DurationBuckets. Returns nil if
+// durations is empty.
+func Histogram(durations []time.Duration) []HistogramBucket {
+	if len(durations) == 0 {
+		return nil
+	}
+	buckets := make([]HistogramBucket, len(DurationBuckets)+1)
+	for i, le := range DurationBuckets {
+		buckets[i].LE = le
+	}
+	for _, d := range durations {
+		i := sort.Search(len(DurationBuckets), func(i int) bool { return d <= DurationBuckets[i] })
+		buckets[i].Count++
+	}
+	return buckets
+}
+
+// PercentilesSummary formats the duration percentiles, e.g.
+// "p50 3.1s, p90 8.2s, p99 41s", or "" if there are none.
+func (s CollectionStats) PercentilesSummary() string {
+	parts := make([]string, len(s.DurationPercentiles))
+	for i, p := range s.DurationPercentiles {
+		parts[i] = fmt.Sprintf("%s %s", p.Label(), p.Duration.Round(time.Millisecond))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// mergeHistograms adds the counts of b to a, which have the same buckets
+// unless one of them is empty.
+func mergeHistograms(a, b []HistogramBucket) []HistogramBucket {
+	if len(a) == 0 {
+		return append([]HistogramBucket(nil), b...)
+	}
+	for i := range a {
+		if i < len(b) && a[i].LE == b[i].LE {
+			a[i].Count += b[i].Count
+		}
+	}
+	return a
+}
+
+// mergePercentiles merges the percentiles of shards by taking the slowest
+// duration per percentile: the exact value needs the durations of all
+// hosts, so merged percentiles are an upper bound.
+func mergePercentiles(a, b []DurationPercentile) []DurationPercentile {
+	if len(a) == 0 {
+		return append([]DurationPercentile(nil), b...)
+	}
+	for i := range a {
+		for _, p := range b {
+			if p.Percent == a[i].Percent && p.Duration > a[i].Duration {
+				a[i].Duration = p.Duration
+			}
+		}
+	}
+	return a
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPercentiles(t *testing.T) {
+	// 97 fast hosts and 3 pathological ones
+	var durations []time.Duration
+	for i := 1; i <= 97; i++ {
+		durations = append(durations, time.Duration(i)*100*time.Millisecond)
+	}
+	durations = append(durations, 4*time.Minute, 5*time.Minute, 10*time.Minute)
+
+	p := Percentiles(durations, []float64{50, 90, 99, 100})
+	require.Len(t, p, 4)
+	assert.Equal(t, 5*time.Second, p[0].Duration)
+	assert.Equal(t, 9*time.Second, p[1].Duration)
+	assert.Equal(t, 5*time.Minute, p[2].Duration)
+	assert.Equal(t, 10*time.Minute, p[3].Duration)
+	assert.Equal(t, "p99", p[2].Label())
+	assert.Equal(t, "p99.9", DurationPercentile{Percent: 99.9}.Label())
+	assert.Equal(t, 97*100*time.Millisecond, durations[96], "input is not sorted in place")
+
+	assert.Nil(t, Percentiles(nil, DefaultPercentiles))
+	assert.Equal(t, time.Second, Percentiles([]time.Duration{time.Second}, []float64{1})[0].Duration)
+}
+
+func TestHistogram(t *testing.T) {
+	h := Histogram([]time.Duration{
+		500 * time.Millisecond, time.Second, 3 * time.Second, 3 * time.Second, time.Hour,
+	})
+	require.Len(t, h, len(DurationBuckets)+1)
+	assert.Equal(t, HistogramBucket{LE: time.Second, Count: 2}, h[0], "bounds are inclusive")
+	assert.Equal(t, HistogramBucket{LE: 5 * time.Second, Count: 2}, h[2])
+	assert.Equal(t, HistogramBucket{LE: 0, Count: 1}, h[len(h)-1])
+
+	assert.Equal(t, "<=1s", h[0].Label(0))
+	assert.Equal(t, "2s-5s", h[2].Label(h[1].LE))
+	assert.Equal(t, ">5m0s", h[len(h)-1].Label(5*time.Minute))
+
+	assert.Nil(t, Histogram(nil))
+}
+
+func TestMergeStats_Durations(t *testing.T) {
+	a := CollectionStats{
+		TotalServers:        2,
+		DurationPercentiles: []DurationPercentile{{50, 2 * time.Second}, {99, 6 * time.Second}},
+		DurationHistogram:   Histogram([]time.Duration{2 * time.Second, 6 * time.Second}),
+	}
+	b := CollectionStats{
+		TotalServers:        1,
+		DurationPercentiles: []DurationPercentile{{50, 3 * time.Second}, {99, 3 * time.Second}},
+		DurationHistogram:   Histogram([]time.Duration{1500 * time.Millisecond}),
+	}
+
+	stats := MergeStats(a, b)
+	assert.Equal(t, "p50 3s, p99 6s", stats.PercentilesSummary(), "slowest of the parts")
+	assert.Equal(t, 2, stats.DurationHistogram[1].Count, "1s-2s")
+	assert.Equal(t, 1, stats.DurationHistogram[3].Count, "5s-10s")
+	assert.Equal(t, 1, a.DurationHistogram[1].Count, "parts are not modified")
+}
@@ -0,0 +1,22 @@
+// Package models defines the core data structures used throughout the application.
+// This file maps errors to the stable error codes of pkg/errors.
+package models
+
+import (
+	"github.com/braunma/idrac-netbox-importer/pkg/errors"
+)
+
+// errorCode returns the stable code of err ("" for nil).
+func errorCode(err error) string {
+	return string(errors.CodeOf(err))
+}
+
+// restoreError rebuilds an error read back from JSON output. Without a
+// stored code (results of older versions), the code is derived from the
+// message again.
+func restoreError(message, code string) error {
+	if code == "" {
+		code = string(errors.CodeOf(errors.WithCode("", message)))
+	}
+	return errors.WithCode(errors.Code(code), message)
+}
@@ -0,0 +1,118 @@
+package models
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// ComponentMatch is a component whose serial or part number matched a
+// search, with the server and slot containing it.
+type ComponentMatch struct {
+	Query        string    `json:"query"`
+	Host         string    `json:"host"`
+	Name         string    `json:"name,omitempty"`
+	ServiceTag   string    `json:"service_tag,omitempty"`
+	Component    string    `json:"component"` // system, drive, dimm, psu, enclosure, pcie
+	Slot         string    `json:"slot,omitempty"`
+	Model        string    `json:"model,omitempty"`
+	SerialNumber string    `json:"serial_number,omitempty"`
+	PartNumber   string    `json:"part_number,omitempty"`
+	CollectedAt  time.Time `json:"collected_at"`
+}
+
+// components returns the parts of a server that have a serial or part
+// number, without the query.
+func components(s ServerInfo) []ComponentMatch {
+	base := ComponentMatch{Host: s.Host, Name: s.Name, ServiceTag: s.ServiceTag, CollectedAt: s.CollectedAt}
+	part := func(kind, slot, model, serial, partNumber string) ComponentMatch {
+		m := base
+		m.Component, m.Slot, m.Model, m.SerialNumber, m.PartNumber = kind, slot, model, serial, partNumber
+		return m
+	}
+
+	parts := []ComponentMatch{part("system", "", s.Model, s.SerialNumber, "")}
+	for _, d := range s.Drives {
+		parts = append(parts, part("drive", d.Name, d.Model, d.SerialNumber, d.PartNumber))
+	}
+	for _, m := range s.Memory {
+		if m.State != MemoryStateAbsent {
+			parts = append(parts, part("dimm", m.Slot, strings.TrimSpace(m.Manufacturer+" "+m.Type), m.SerialNumber, m.PartNumber))
+		}
+	}
+	for _, p := range s.PowerSupplies {
+		parts = append(parts, part("psu", p.Name, p.Model, p.SerialNumber, p.PartNumber))
+		if p.SparePartNumber != "" && p.SparePartNumber != p.PartNumber {
+			parts = append(parts, part("psu", p.Name, p.Model, p.SerialNumber, p.SparePartNumber))
+		}
+	}
+	for _, e := range s.Enclosures {
+		parts = append(parts, part("enclosure", e.Name, e.Model, e.SerialNumber, e.PartNumber))
+	}
+	for _, slot := range s.PCIeSlots {
+		if c := slot.Card; c != nil {
+			parts = append(parts, part("pcie", slot.Label, c.Model, c.SerialNumber, c.PartNumber))
+		}
+	}
+	return parts
+}
+
+// FindComponents returns the components of the servers whose serial or part
+// number equals one of the queries, ignoring case, or contains it if partial
+// is set. A component found in several results of the same host (e.g. the
+// history and the last scan) is reported once, from the latest result.
+// Matches are sorted by query, host and slot.
+func FindComponents(servers []ServerInfo, queries []string, partial bool) []ComponentMatch {
+	var terms []string
+	for _, q := range queries {
+		if q = strings.ToUpper(strings.TrimSpace(q)); q != "" {
+			terms = append(terms, q)
+		}
+	}
+	matches := func(value, term string) bool {
+		value = strings.ToUpper(strings.TrimSpace(value))
+		if value == "" {
+			return false
+		}
+		if partial {
+			return strings.Contains(value, term)
+		}
+		return value == term
+	}
+
+	type key struct{ query, host, component, slot, serial string }
+	found := map[key]ComponentMatch{}
+	for _, s := range servers {
+		if s.Error != nil {
+			continue
+		}
+		for _, c := range components(s) {
+			for _, term := range terms {
+				if !matches(c.SerialNumber, term) && !matches(c.PartNumber, term) {
+					continue
+				}
+				c.Query = term
+				k := key{term, s.Key(), c.Component, c.Slot, c.SerialNumber}
+				if prev, ok := found[k]; !ok || c.CollectedAt.After(prev.CollectedAt) {
+					found[k] = c
+				}
+			}
+		}
+	}
+
+	result := make([]ComponentMatch, 0, len(found))
+	for _, m := range found {
+		result = append(result, m)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.Query != b.Query {
+			return a.Query < b.Query
+		}
+		if a.Host != b.Host {
+			return a.Host < b.Host
+		}
+		return a.Slot < b.Slot
+	})
+	return result
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindComponents(t *testing.T) {
+	older := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	newer := older.AddDate(0, 1, 0)
+	srv := ServerInfo{
+		Host:         "10.0.0.1",
+		ServiceTag:   "AAA1111",
+		Model:        "PowerEdge R650",
+		SerialNumber: "CN7016",
+		CollectedAt:  newer,
+		Drives:       []DriveInfo{{Name: "Disk.Bay.3", Model: "MZ7L3", SerialNumber: "S3Z9NA0R123456", PartNumber: "0R5F8K"}},
+		Memory: []MemoryInfo{
+			{Slot: "DIMM.Socket.A1", SerialNumber: "8A1B2C3D", PartNumber: "M393A4K40DB3", State: MemoryStateEnabled},
+			{Slot: "DIMM.Socket.A2", SerialNumber: "FFFF", State: MemoryStateAbsent},
+		},
+		PowerSupplies: []PowerSupplyInfo{{Name: "PSU.Slot.1", SerialNumber: "PSU1", PartNumber: "0ABC12", SparePartNumber: "450-AIYX"}},
+		PCIeSlots:     []PCIeSlotInfo{{Label: "Slot 1", Card: &PCIeCardInfo{Model: "ConnectX-6", SerialNumber: "MT2034"}}},
+	}
+	stale := srv
+	stale.CollectedAt = older
+	other := ServerInfo{Host: "10.0.0.2", Drives: []DriveInfo{{Name: "Disk.Bay.0", PartNumber: "0R5F8K", SerialNumber: "S3Z9NA0R999999"}}}
+	failed := ServerInfo{Host: "10.0.0.3", Error: errors.New("timeout")}
+
+	matches := FindComponents([]ServerInfo{stale, srv, other, failed}, []string{" s3z9na0r123456 ", "8A1B2C3D", "FFFF", "450-aiyx", "MT2034", "CN7016"}, false)
+	require.Len(t, matches, 5, "the absent DIMM is skipped")
+
+	assert.Equal(t, "450-AIYX", matches[0].Query)
+	assert.Equal(t, "psu", matches[0].Component)
+	assert.Equal(t, "8A1B2C3D", matches[1].Query)
+	assert.Equal(t, "dimm", matches[1].Component)
+	assert.Equal(t, "CN7016", matches[2].Query)
+	assert.Equal(t, "system", matches[2].Component)
+	assert.Equal(t, "pcie", matches[3].Component)
+	assert.Equal(t, ComponentMatch{
+		Query:        "S3Z9NA0R123456",
+		Host:         "10.0.0.1",
+		ServiceTag:   "AAA1111",
+		Component:    "drive",
+		Slot:         "Disk.Bay.3",
+		Model:        "MZ7L3",
+		SerialNumber: "S3Z9NA0R123456",
+		PartNumber:   "0R5F8K",
+		CollectedAt:  newer,
+	}, matches[4], "reported once, from the latest result")
+
+	byPart := FindComponents([]ServerInfo{srv, other}, []string{"0R5F8K"}, false)
+	assert.Len(t, byPart, 2)
+
+	partial := FindComponents([]ServerInfo{srv, other}, []string{"S3Z9NA0R"}, true)
+	assert.Len(t, partial, 2)
+	assert.Empty(t, FindComponents([]ServerInfo{srv}, []string{"S3Z9NA0R"}, false))
+}
@@ -0,0 +1,183 @@
+package models
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Components of a firmware policy violation.
+const (
+	FirmwareIDRAC = "idrac"
+	FirmwareBIOS  = "bios"
+)
+
+// FirmwareCompliance is the result of checking the iDRAC and BIOS versions
+// of a server against the minimum versions of the firmware policy.
+type FirmwareCompliance struct {
+	// Policy is the model rule that applied, or "default".
+	Policy     string      `json:"policy"`
+	Violations []Violation `json:"violations,omitempty"`
+}
+
+// Compliant reports whether the server runs at least the minimum versions.
+func (c FirmwareCompliance) Compliant() bool {
+	return len(c.Violations) == 0
+}
+
+// MatchModel reports whether the model name of a rule (firmware policy,
+// golden config profile) matches the model of a server: the full name or
+// its last word, case-insensitively, so "R750" matches "PowerEdge R750".
+func MatchModel(want, model string) bool {
+	if strings.EqualFold(want, model) {
+		return true
+	}
+	fields := strings.Fields(model)
+	return len(fields) > 0 && strings.EqualFold(want, fields[len(fields)-1])
+}
+
+// CompareVersions compares two firmware versions and returns -1, 0 or 1.
+// Versions are split into numeric and alphabetic parts at every change of
+// kind and at separators, so "2.19.1" < "2.100.0" and "7.00.00.171" >
+// "7.00.00.00"; numeric parts compare as numbers and a missing part is 0.
+func CompareVersions(a, b string) int {
+	pa, pb := versionParts(a), versionParts(b)
+	for i := 0; i < max(len(pa), len(pb)); i++ {
+		x, y := "0", "0"
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+		if c := comparePart(x, y); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+func comparePart(x, y string) int {
+	nx, errX := strconv.ParseUint(x, 10, 64)
+	ny, errY := strconv.ParseUint(y, 10, 64)
+	switch {
+	case errX == nil && errY == nil:
+		switch {
+		case nx < ny:
+			return -1
+		case nx > ny:
+			return 1
+		}
+		return 0
+	case errX == nil:
+		return 1 // a number sorts after a suffix: 1.0 > 1.0rc
+	case errY == nil:
+		return -1
+	}
+	return strings.Compare(strings.ToLower(x), strings.ToLower(y))
+}
+
+func versionParts(v string) []string {
+	var parts []string
+	start := -1
+	digits := false
+	for i, r := range v {
+		alnum := unicode.IsDigit(r) || unicode.IsLetter(r)
+		if start >= 0 && (!alnum || unicode.IsDigit(r) != digits) {
+			parts = append(parts, v[start:i])
+			start = -1
+		}
+		if alnum && start < 0 {
+			start, digits = i, unicode.IsDigit(r)
+		}
+	}
+	if start >= 0 {
+		parts = append(parts, v[start:])
+	}
+	return parts
+}
+
+// FirmwareComplianceRate is the firmware policy compliance of the servers
+// of one model.
+type FirmwareComplianceRate struct {
+	Model     string `json:"model"`
+	Servers   int    `json:"servers"`
+	Compliant int    `json:"compliant"`
+
+	// NonCompliant lists the hosts below the minimum versions.
+	NonCompliant []string `json:"non_compliant,omitempty"`
+}
+
+// Rate returns the share of compliant servers in percent.
+func (r FirmwareComplianceRate) Rate() float64 {
+	if r.Servers == 0 {
+		return 0
+	}
+	return float64(r.Compliant) * 100 / float64(r.Servers)
+}
+
+// firmwareTally accumulates firmware compliance rates per model. The zero
+// value is ready to use.
+type firmwareTally struct {
+	models map[string]*FirmwareComplianceRate
+}
+
+func (t *firmwareTally) add(srv ServerInfo) {
+	if srv.FirmwareCompliance == nil {
+		return
+	}
+	if t.models == nil {
+		t.models = make(map[string]*FirmwareComplianceRate)
+	}
+	r, ok := t.models[srv.Model]
+	if !ok {
+		r = &FirmwareComplianceRate{Model: srv.Model}
+		t.models[srv.Model] = r
+	}
+	r.Servers++
+	if srv.FirmwareCompliance.Compliant() {
+		r.Compliant++
+	} else {
+		r.NonCompliant = append(r.NonCompliant, srv.Host)
+	}
+}
+
+// rates returns the rates sorted by model, or nil if no server was checked.
+func (t *firmwareTally) rates() []FirmwareComplianceRate {
+	if len(t.models) == 0 {
+		return nil
+	}
+	out := make([]FirmwareComplianceRate, 0, len(t.models))
+	for _, r := range t.models {
+		sort.Strings(r.NonCompliant)
+		out = append(out, *r)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Model < out[j].Model })
+	return out
+}
+
+// SummarizeFirmwareCompliance returns the firmware policy compliance per
+// model of the checked servers, sorted by model, or nil if no server was
+// checked.
+func SummarizeFirmwareCompliance(servers []ServerInfo) []FirmwareComplianceRate {
+	var t firmwareTally
+	for _, srv := range servers {
+		if srv.Error == nil {
+			t.add(srv)
+		}
+	}
+	return t.rates()
+}
+
+// TotalFirmwareCompliance sums the rates of all models.
+func TotalFirmwareCompliance(rates []FirmwareComplianceRate) FirmwareComplianceRate {
+	total := FirmwareComplianceRate{Model: "Total"}
+	for _, r := range rates {
+		total.Servers += r.Servers
+		total.Compliant += r.Compliant
+		total.NonCompliant = append(total.NonCompliant, r.NonCompliant...)
+	}
+	sort.Strings(total.NonCompliant)
+	return total
+}
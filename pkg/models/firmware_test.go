@@ -0,0 +1,64 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchModel(t *testing.T) {
+	assert.True(t, MatchModel("PowerEdge R750", "PowerEdge R750"))
+	assert.True(t, MatchModel("r750", "PowerEdge R750"), "last word, any case")
+	assert.False(t, MatchModel("R75", "PowerEdge R750"))
+	assert.False(t, MatchModel("PowerEdge", "PowerEdge R750"))
+	assert.False(t, MatchModel("R750", ""))
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"2.19.1", "2.19.1", 0},
+		{"2.19.1", "2.100.0", -1},
+		{"7.00.00.171", "7.00.00.00", 1},
+		{"7.00.00.00", "7.0", 0},
+		{"1.10.2", "1.9.2", 1},
+		{"1.0rc1", "1.0", -1},
+		{"1.0b", "1.0a", 1},
+		{"U46", "U32", 1},
+		{"", "1.0", -1},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, CompareVersions(tt.a, tt.b), "%s vs %s", tt.a, tt.b)
+		assert.Equal(t, -tt.want, CompareVersions(tt.b, tt.a), "%s vs %s", tt.b, tt.a)
+	}
+}
+
+func TestSummarizeFirmwareCompliance(t *testing.T) {
+	compliant := &FirmwareCompliance{Policy: "default"}
+	outdated := &FirmwareCompliance{Policy: "default", Violations: []Violation{{Component: FirmwareBIOS}}}
+	servers := []ServerInfo{
+		{Host: "b", Model: "PowerEdge R740", FirmwareCompliance: outdated},
+		{Host: "a", Model: "PowerEdge R740", FirmwareCompliance: outdated},
+		{Host: "c", Model: "PowerEdge R740", FirmwareCompliance: compliant},
+		{Host: "d", Model: "PowerEdge R750", FirmwareCompliance: compliant},
+		{Host: "e", Model: "PowerEdge R750"},
+		{Host: "f", Model: "PowerEdge R750", Error: errors.New("timeout"), FirmwareCompliance: outdated},
+	}
+
+	rates := SummarizeFirmwareCompliance(servers)
+	assert.Equal(t, []FirmwareComplianceRate{
+		{Model: "PowerEdge R740", Servers: 3, Compliant: 1, NonCompliant: []string{"a", "b"}},
+		{Model: "PowerEdge R750", Servers: 1, Compliant: 1},
+	}, rates)
+	assert.InDelta(t, 33.3, rates[0].Rate(), 0.1)
+
+	total := TotalFirmwareCompliance(rates)
+	assert.Equal(t, 4, total.Servers)
+	assert.Equal(t, 2, total.Compliant)
+	assert.Equal(t, 50.0, total.Rate())
+
+	assert.Nil(t, SummarizeFirmwareCompliance([]ServerInfo{{Host: "x"}}))
+}
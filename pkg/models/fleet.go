@@ -0,0 +1,168 @@
+package models
+
+import "sort"
+
+// FleetSummary totals the hardware of the successfully scanned servers of a
+// run. Sizes are binary, as in ServerInfo.
+type FleetSummary struct {
+	Servers     int                 `json:"servers"`
+	CPUSockets  int                 `json:"cpu_sockets"`
+	CPUCores    int                 `json:"cpu_cores"`
+	CPUThreads  int                 `json:"cpu_threads"`
+	MemoryGiB   float64             `json:"memory_gib"`
+	Drives      int                 `json:"drives"`
+	StorageTB   float64             `json:"storage_tb"`
+	Storage     []MediaTotal        `json:"storage_by_media,omitempty"`
+	GPUs        int                 `json:"gpus"`
+	Generations []GenerationSummary `json:"generations,omitempty"`
+}
+
+// MediaTotal is the number and capacity of the drives of one media type
+// (SSD, HDD, ...).
+type MediaTotal struct {
+	MediaType  string  `json:"media_type"`
+	Drives     int     `json:"drives"`
+	CapacityTB float64 `json:"capacity_tb"`
+}
+
+// GenerationSummary counts the servers of one Dell PowerEdge generation
+// ("15G"); servers whose generation is not known are counted as "other".
+type GenerationSummary struct {
+	Generation string `json:"generation"`
+	Servers    int    `json:"servers"`
+}
+
+// unknownGeneration is the generation of non-Dell and unrecognized models.
+const unknownGeneration = "other"
+
+// FleetBuilder accumulates a FleetSummary one server at a time. The zero
+// value is ready to use.
+type FleetBuilder struct {
+	sum         FleetSummary
+	media       map[string]*MediaTotal
+	generations map[string]int
+}
+
+// Add adds a server to the totals. Failed servers are ignored.
+func (b *FleetBuilder) Add(srv ServerInfo) {
+	if srv.Error != nil {
+		return
+	}
+	b.sum.Servers++
+	b.sum.CPUSockets += srv.CPUCount
+	for _, cpu := range srv.CPUs {
+		b.sum.CPUCores += cpu.Cores
+		b.sum.CPUThreads += cpu.Threads
+	}
+	b.sum.MemoryGiB += srv.TotalMemoryGiB
+	b.sum.StorageTB += srv.TotalStorageTB
+	b.sum.GPUs += srv.GPUCount
+
+	for _, d := range srv.Drives {
+		b.addMedia(MediaTotal{MediaType: d.MediaType, Drives: 1, CapacityTB: d.CapacityTB()})
+	}
+	gen := ""
+	if p := srv.PlatformInfo(); p != nil {
+		gen = p.Generation
+	}
+	b.addGeneration(gen, 1)
+}
+
+// AddSummary adds the totals of another summary, e.g. of a shard.
+func (b *FleetBuilder) AddSummary(s FleetSummary) {
+	b.sum.Servers += s.Servers
+	b.sum.CPUSockets += s.CPUSockets
+	b.sum.CPUCores += s.CPUCores
+	b.sum.CPUThreads += s.CPUThreads
+	b.sum.MemoryGiB += s.MemoryGiB
+	b.sum.StorageTB += s.StorageTB
+	b.sum.GPUs += s.GPUs
+	for _, m := range s.Storage {
+		b.addMedia(m)
+	}
+	for _, g := range s.Generations {
+		b.addGeneration(g.Generation, g.Servers)
+	}
+}
+
+func (b *FleetBuilder) addMedia(m MediaTotal) {
+	if m.MediaType == "" {
+		m.MediaType = "Unknown"
+	}
+	if b.media == nil {
+		b.media = make(map[string]*MediaTotal)
+	}
+	b.sum.Drives += m.Drives
+	total, ok := b.media[m.MediaType]
+	if !ok {
+		total = &MediaTotal{MediaType: m.MediaType}
+		b.media[m.MediaType] = total
+	}
+	total.Drives += m.Drives
+	total.CapacityTB += m.CapacityTB
+}
+
+func (b *FleetBuilder) addGeneration(gen string, servers int) {
+	if gen == "" {
+		gen = unknownGeneration
+	}
+	if b.generations == nil {
+		b.generations = make(map[string]int)
+	}
+	b.generations[gen] += servers
+}
+
+// Summary returns the totals of the servers added so far. Media types are
+// sorted by capacity, generations from newest to oldest.
+func (b *FleetBuilder) Summary() FleetSummary {
+	sum := b.sum
+	sum.Storage = nil
+	for _, m := range b.media {
+		sum.Storage = append(sum.Storage, *m)
+	}
+	sort.Slice(sum.Storage, func(i, j int) bool {
+		if sum.Storage[i].CapacityTB != sum.Storage[j].CapacityTB {
+			return sum.Storage[i].CapacityTB > sum.Storage[j].CapacityTB
+		}
+		return sum.Storage[i].MediaType < sum.Storage[j].MediaType
+	})
+
+	sum.Generations = nil
+	for gen, n := range b.generations {
+		sum.Generations = append(sum.Generations, GenerationSummary{Generation: gen, Servers: n})
+	}
+	sort.Slice(sum.Generations, func(i, j int) bool {
+		gi, gj := sum.Generations[i].Generation, sum.Generations[j].Generation
+		if (gi == unknownGeneration) != (gj == unknownGeneration) {
+			return gj == unknownGeneration
+		}
+		if len(gi) != len(gj) {
+			return len(gi) > len(gj)
+		}
+		return gi > gj
+	})
+	return sum
+}
+
+// FleetSummaryOf returns the fleet totals of servers.
+func FleetSummaryOf(servers []ServerInfo) FleetSummary {
+	var b FleetBuilder
+	for _, srv := range servers {
+		b.Add(srv)
+	}
+	return b.Summary()
+}
+
+// Generation returns the generation of a Dell PowerEdge model, e.g. "15G"
+// for "PowerEdge R750", "R6525" or "R750xa" and "16G" for "XE9680", or ""
+// for other models. The generation is ten plus the second digit of the
+// model number.
+func Generation(manufacturer, model string) string {
+	if manufacturer == "" {
+		return ""
+	}
+	if p := ParsePlatform(manufacturer, model, ""); p != nil {
+		return p.Generation
+	}
+	return ""
+}
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fleetServer(model string, ssds, hdds int) ServerInfo {
+	srv := ServerInfo{
+		Manufacturer:   "Dell Inc.",
+		Model:          model,
+		CPUCount:       2,
+		CPUs:           []CPUInfo{{Cores: 32, Threads: 64}, {Cores: 32, Threads: 64}},
+		TotalMemoryGiB: 512,
+		GPUCount:       1,
+	}
+	for i := 0; i < ssds; i++ {
+		srv.Drives = append(srv.Drives, DriveInfo{CapacityGB: 1024, MediaType: "SSD"})
+	}
+	for i := 0; i < hdds; i++ {
+		srv.Drives = append(srv.Drives, DriveInfo{CapacityGB: 8192, MediaType: "HDD"})
+	}
+	srv.TotalStorageTB = float64(ssds) + 8*float64(hdds)
+	return srv
+}
+
+func TestFleetSummaryOf(t *testing.T) {
+	fl := FleetSummaryOf([]ServerInfo{
+		fleetServer("PowerEdge R750", 2, 0),
+		fleetServer("PowerEdge R660", 2, 4),
+		fleetServer("PowerEdge R750xa", 0, 0),
+		{Manufacturer: "Supermicro", Model: "SYS-620P"},
+		{Host: "10.0.0.9", Error: errors.New("timeout")},
+	})
+
+	assert.Equal(t, 4, fl.Servers)
+	assert.Equal(t, 6, fl.CPUSockets)
+	assert.Equal(t, 192, fl.CPUCores)
+	assert.Equal(t, 384, fl.CPUThreads)
+	assert.Equal(t, float64(1536), fl.MemoryGiB)
+	assert.Equal(t, 3, fl.GPUs)
+	assert.Equal(t, 8, fl.Drives)
+	assert.Equal(t, float64(36), fl.StorageTB)
+	assert.Equal(t, []MediaTotal{
+		{MediaType: "HDD", Drives: 4, CapacityTB: 32},
+		{MediaType: "SSD", Drives: 4, CapacityTB: 4},
+	}, fl.Storage)
+	assert.Equal(t, []GenerationSummary{
+		{Generation: "16G", Servers: 1},
+		{Generation: "15G", Servers: 2},
+		{Generation: "other", Servers: 1},
+	}, fl.Generations)
+}
+
+func TestMergeStats_Fleet(t *testing.T) {
+	a := FleetSummaryOf([]ServerInfo{fleetServer("PowerEdge R750", 2, 0)})
+	b := FleetSummaryOf([]ServerInfo{fleetServer("PowerEdge R740", 0, 2)})
+
+	merged := MergeStats(CollectionStats{Fleet: &a}, CollectionStats{Fleet: &b}, CollectionStats{})
+	require.NotNil(t, merged.Fleet)
+	assert.Equal(t, 2, merged.Fleet.Servers)
+	assert.Equal(t, 4, merged.Fleet.Drives)
+	assert.Len(t, merged.Fleet.Storage, 2)
+	assert.Equal(t, []GenerationSummary{{Generation: "15G", Servers: 1}, {Generation: "14G", Servers: 1}}, merged.Fleet.Generations)
+
+	assert.Nil(t, MergeStats(CollectionStats{}).Fleet)
+}
+
+func TestGeneration(t *testing.T) {
+	for model, want := range map[string]string{
+		"PowerEdge R750":   "15G",
+		"PowerEdge R750xa": "15G",
+		"PowerEdge R6525":  "15G",
+		"PowerEdge R640":   "14G",
+		"PowerEdge XE9680": "16G",
+		"PowerEdge MX750c": "15G",
+		"PowerEdge R730xd": "13G",
+		"PowerEdge":        "",
+		"":                 "",
+	} {
+		assert.Equal(t, want, Generation("Dell Inc.", model), model)
+	}
+	assert.Empty(t, Generation("HPE", "ProLiant DL380 Gen10"))
+}
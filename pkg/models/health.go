@@ -0,0 +1,94 @@
+package models
+
+// HealthIssue is a component that reported a health other than OK.
+type HealthIssue struct {
+	Component string `json:"component"` // cpu, memory, drive, controller, battery, gpu, psu, clock
+	Name      string `json:"name"`      // socket, slot or drive/controller/PSU name; drift for the clock
+	Health    string `json:"health"`    // Warning or Critical
+}
+
+// HealthIssues returns the components of the server whose health is reported
+// and not OK, in the order CPUs, memory, drives, storage controllers and
+// their cache batteries, GPUs, power supplies, followed by a Warning for an
+// iDRAC clock that drifted beyond the threshold. Empty slots and components
+// without a health reading are skipped.
+func (s ServerInfo) HealthIssues() []HealthIssue {
+	var issues []HealthIssue
+	add := func(component, name, health string) {
+		if health != "" && health != HealthOK {
+			issues = append(issues, HealthIssue{Component: component, Name: name, Health: health})
+		}
+	}
+
+	for _, cpu := range s.CPUs {
+		add("cpu", cpu.Socket, cpu.Health)
+	}
+	for _, mem := range s.Memory {
+		if mem.IsPopulated() {
+			add("memory", mem.Slot, mem.Health)
+		}
+	}
+	for _, d := range s.Drives {
+		add("drive", d.Name, d.Health)
+	}
+	for _, c := range s.StorageControllers {
+		add("controller", c.ID, c.Health)
+		if b := c.Battery; b != nil {
+			add("battery", c.ID, b.Health)
+		}
+	}
+	for _, gpu := range s.GPUs {
+		add("gpu", gpu.Slot, gpu.Health)
+	}
+	for _, psu := range s.PowerSupplies {
+		if psu.IsInstalled() {
+			add("psu", psu.Name, psu.Health)
+		}
+	}
+	if c := s.Clock; c != nil && c.Drifted() {
+		add("clock", c.String(), HealthWarning)
+	}
+	return issues
+}
+
+// WorstHealth returns Critical if any issue is critical, Warning if there are
+// other issues and OK otherwise.
+func WorstHealth(issues []HealthIssue) string {
+	worst := HealthOK
+	for _, i := range issues {
+		if i.Health == HealthCritical {
+			return HealthCritical
+		}
+		worst = HealthWarning
+	}
+	return worst
+}
+
+// Health policy violations, see PolicyViolations.
+const (
+	PolicyCriticalHealth = "critical_health"
+	PolicyFirmware       = "firmware"
+	PolicyGolden         = "golden_config"
+)
+
+// PolicyViolations returns the parts of the health policy the server
+// violates: a component in Critical health, firmware below the minimum
+// versions of the firmware policy, or a deviation from its golden config.
+// Failed scans, including those reported with stale data, violate nothing;
+// they count as scan failures.
+func (s ServerInfo) PolicyViolations() []string {
+	if s.Error != nil || s.Stale {
+		return nil
+	}
+	var violations []string
+	if WorstHealth(s.HealthIssues()) == HealthCritical {
+		violations = append(violations, PolicyCriticalHealth)
+	}
+	if c := s.FirmwareCompliance; c != nil && !c.Compliant() {
+		violations = append(violations, PolicyFirmware)
+	}
+	if c := s.Compliance; c != nil && !c.Compliant() {
+		violations = append(violations, PolicyGolden)
+	}
+	return violations
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServerInfo_HealthIssues(t *testing.T) {
+	info := ServerInfo{
+		CPUs: []CPUInfo{{Socket: "CPU.Socket.1", Health: HealthOK}, {Socket: "CPU.Socket.2"}},
+		Memory: []MemoryInfo{
+			{Slot: "DIMM.Socket.A1", State: MemoryStateEnabled, Health: HealthWarning},
+			{Slot: "DIMM.Socket.A2", State: MemoryStateAbsent, Health: HealthCritical},
+		},
+		Drives: []DriveInfo{{Name: "Disk.Bay.3", Health: HealthWarning}},
+		StorageControllers: []StorageControllerInfo{
+			{ID: "RAID.Integrated.1-1", Health: HealthOK, Battery: &BatteryInfo{Health: HealthWarning, State: BatteryStateDegraded}},
+			{ID: "AHCI.Slot.2-1", Health: HealthOK},
+		},
+		PowerSupplies: []PowerSupplyInfo{{Name: "PSU 2", State: "Enabled", Health: HealthCritical}},
+		Clock:         &ClockInfo{DriftSeconds: -300, MaxDriftSeconds: 120},
+	}
+
+	issues := info.HealthIssues()
+	assert.Equal(t, []HealthIssue{
+		{Component: "memory", Name: "DIMM.Socket.A1", Health: HealthWarning},
+		{Component: "drive", Name: "Disk.Bay.3", Health: HealthWarning},
+		{Component: "battery", Name: "RAID.Integrated.1-1", Health: HealthWarning},
+		{Component: "psu", Name: "PSU 2", Health: HealthCritical},
+		{Component: "clock", Name: "iDRAC clock 5m0s behind", Health: HealthWarning},
+	}, issues)
+	assert.Equal(t, HealthCritical, WorstHealth(issues))
+	assert.Equal(t, HealthWarning, WorstHealth(issues[:3]))
+	assert.Equal(t, HealthOK, WorstHealth(nil))
+}
+
+func TestServerInfo_PolicyViolations(t *testing.T) {
+	assert.Empty(t, ServerInfo{Drives: []DriveInfo{{Name: "Disk.Bay.3", Health: HealthWarning}}}.PolicyViolations())
+
+	info := ServerInfo{
+		PowerSupplies:      []PowerSupplyInfo{{Name: "PSU 2", State: "Enabled", Health: HealthCritical}},
+		FirmwareCompliance: &FirmwareCompliance{Policy: "default", Violations: []Violation{{Component: FirmwareBIOS}}},
+		Compliance:         &Compliance{Profile: "R750"},
+	}
+	assert.Equal(t, []string{PolicyCriticalHealth, PolicyFirmware}, info.PolicyViolations())
+
+	info.Compliance.Violations = []Violation{{Component: "memory"}}
+	assert.Equal(t, []string{PolicyCriticalHealth, PolicyFirmware, PolicyGolden}, info.PolicyViolations())
+
+	info.Error = assert.AnError
+	assert.Empty(t, info.PolicyViolations(), "failed scans count as scan failures")
+}
+
+func TestBatteryHealth(t *testing.T) {
+	assert.Equal(t, HealthOK, BatteryHealth("OK", BatteryStateReady))
+	assert.Equal(t, HealthOK, BatteryHealth("", BatteryStateLearning), "learning cycles are expected")
+	assert.Equal(t, HealthWarning, BatteryHealth("OK", BatteryStateDegraded))
+	assert.Equal(t, HealthWarning, BatteryHealth("Degraded", ""))
+	assert.Equal(t, HealthCritical, BatteryHealth("Degraded", BatteryStateFailed))
+	assert.Equal(t, HealthCritical, BatteryHealth("Error", BatteryStateDegraded))
+	assert.Equal(t, HealthCritical, BatteryHealth("", BatteryStateMissing))
+	assert.Empty(t, BatteryHealth("Unknown", "Unknown"))
+}
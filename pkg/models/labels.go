@@ -0,0 +1,20 @@
+package models
+
+import "sort"
+
+// LabelKeys returns the label keys of the servers, sorted and without
+// duplicates.
+func LabelKeys(servers []ServerInfo) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, s := range servers {
+		for k := range s.Labels {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,109 @@
+// Package models defines the core data structures used throughout the application.
+// This file holds the iDRAC license inventory.
+package models
+
+import (
+	"sort"
+	"strings"
+)
+
+// iDRAC license levels, lowest first.
+const (
+	LicenseLevelBasic      = "Basic"
+	LicenseLevelExpress    = "Express"
+	LicenseLevelEnterprise = "Enterprise"
+	LicenseLevelDatacenter = "Datacenter"
+)
+
+var licenseLevelRank = map[string]int{
+	LicenseLevelBasic:      1,
+	LicenseLevelExpress:    2,
+	LicenseLevelEnterprise: 3,
+	LicenseLevelDatacenter: 4,
+}
+
+// LicenseInfo describes a license installed on an iDRAC.
+type LicenseInfo struct {
+	Description   string `json:"description"`
+	EntitlementID string `json:"entitlement_id,omitempty"`
+	Type          string `json:"type,omitempty"`
+	Status        string `json:"status,omitempty"`
+}
+
+// Level returns the iDRAC license level named in the description, or "" if the
+// license is not an iDRAC license (e.g. an OpenManage Enterprise add-on).
+func (l LicenseInfo) Level() string {
+	desc := strings.ToLower(l.Description)
+	if !strings.Contains(desc, "idrac") {
+		return ""
+	}
+	switch {
+	case strings.Contains(desc, "datacenter"):
+		return LicenseLevelDatacenter
+	case strings.Contains(desc, "enterprise"):
+		return LicenseLevelEnterprise
+	case strings.Contains(desc, "express"):
+		return LicenseLevelExpress
+	case strings.Contains(desc, "basic"):
+		return LicenseLevelBasic
+	}
+	return ""
+}
+
+// HighestLicenseLevel returns the highest iDRAC license level among the given
+// licenses, or "" if none of them is an iDRAC license.
+func HighestLicenseLevel(licenses []LicenseInfo) string {
+	best := ""
+	for _, l := range licenses {
+		if level := l.Level(); licenseLevelRank[level] > licenseLevelRank[best] {
+			best = level
+		}
+	}
+	return best
+}
+
+// IsLicenseLimited returns true if the license level restricts Redfish features
+// (Basic and Express lack parts of the telemetry and OEM inventory).
+func IsLicenseLimited(level string) bool {
+	return level == LicenseLevelBasic || level == LicenseLevelExpress
+}
+
+// LicenseSummary is an entry of the license report.
+type LicenseSummary struct {
+	Level   string   `json:"level"`
+	Count   int      `json:"count"`
+	Limited bool     `json:"limited"`
+	Hosts   []string `json:"hosts"`
+}
+
+// SummarizeLicenses groups successfully scanned servers by iDRAC license level,
+// highest level first. Servers with an undetected level are grouped under "unknown".
+func SummarizeLicenses(servers []ServerInfo) []LicenseSummary {
+	byLevel := make(map[string]*LicenseSummary)
+	for _, srv := range servers {
+		if srv.Error != nil {
+			continue
+		}
+		level := srv.LicenseLevel
+		if level == "" {
+			level = "unknown"
+		}
+		s, ok := byLevel[level]
+		if !ok {
+			s = &LicenseSummary{Level: level, Limited: IsLicenseLimited(level)}
+			byLevel[level] = s
+		}
+		s.Count++
+		s.Hosts = append(s.Hosts, srv.Host)
+	}
+
+	result := make([]LicenseSummary, 0, len(byLevel))
+	for _, s := range byLevel {
+		sort.Strings(s.Hosts)
+		result = append(result, *s)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return licenseLevelRank[result[i].Level] > licenseLevelRank[result[j].Level]
+	})
+	return result
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLicenseInfo_Level(t *testing.T) {
+	assert.Equal(t, LicenseLevelEnterprise, LicenseInfo{Description: "iDRAC9 Enterprise License"}.Level())
+	assert.Equal(t, LicenseLevelDatacenter, LicenseInfo{Description: "iDRAC9 15g Datacenter License"}.Level())
+	assert.Equal(t, LicenseLevelExpress, LicenseInfo{Description: "iDRAC9 Express License"}.Level())
+	assert.Empty(t, LicenseInfo{Description: "OpenManage Enterprise Advanced"}.Level())
+}
+
+func TestHighestLicenseLevel(t *testing.T) {
+	licenses := []LicenseInfo{
+		{Description: "iDRAC9 Express License"},
+		{Description: "iDRAC9 Datacenter License"},
+		{Description: "OpenManage Enterprise Advanced"},
+	}
+	assert.Equal(t, LicenseLevelDatacenter, HighestLicenseLevel(licenses))
+	assert.Empty(t, HighestLicenseLevel(nil))
+}
+
+func TestSummarizeLicenses(t *testing.T) {
+	servers := []ServerInfo{
+		{Host: "10.0.0.2", LicenseLevel: LicenseLevelExpress},
+		{Host: "10.0.0.1", LicenseLevel: LicenseLevelEnterprise},
+		{Host: "10.0.0.3"},
+		{Host: "10.0.0.4", Error: errors.New("timeout")},
+	}
+
+	summary := SummarizeLicenses(servers)
+
+	require.Len(t, summary, 3)
+	assert.Equal(t, LicenseLevelEnterprise, summary[0].Level)
+	assert.Equal(t, LicenseLevelExpress, summary[1].Level)
+	assert.True(t, summary[1].Limited)
+	assert.Equal(t, "unknown", summary[2].Level)
+	assert.Equal(t, []string{"10.0.0.3"}, summary[2].Hosts)
+}
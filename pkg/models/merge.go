@@ -0,0 +1,115 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// MergeResults combines the results of several partial runs, e.g. the shards
+// of a fleet scanned by separate instances, into one result set. Stats are
+// combined as for a single run of all hosts; since shards run in parallel,
+// the total duration is the longest of the parts. A host found in more than
+// one part is an error, as the parts would overlap.
+func MergeResults(results [][]ServerInfo, stats []CollectionStats) ([]ServerInfo, CollectionStats, error) {
+	var merged []ServerInfo
+	seen := map[string]int{}
+	for i, part := range results {
+		for _, srv := range part {
+			if j, dup := seen[srv.Key()]; dup {
+				return nil, CollectionStats{}, fmt.Errorf("host %s is in parts %d and %d", srv.Key(), j+1, i+1)
+			}
+			seen[srv.Key()] = i
+			merged = append(merged, srv)
+		}
+	}
+	return merged, MergeStats(stats...), nil
+}
+
+// MergeStats combines the statistics of partial runs. Duration histograms
+// are added up; the duration percentiles are the slowest of the parts (an
+// upper bound, as the exact value needs the durations of all hosts).
+func MergeStats(parts ...CollectionStats) CollectionStats {
+	var out CollectionStats
+	if len(parts) == 0 {
+		return out
+	}
+	out.RunID = parts[0].RunID
+
+	var durationSum float64
+	reasons := map[string]*FailureReason{}
+	var fleet FleetBuilder
+	hasFleet := false
+	for _, p := range parts {
+		if p.RunID != out.RunID {
+			out.RunID = ""
+		}
+		out.TotalServers += p.TotalServers
+		out.SuccessfulCount += p.SuccessfulCount
+		out.FailedCount += p.FailedCount
+		out.RescannedCount += p.RescannedCount
+		out.RecoveredCount += p.RecoveredCount
+		out.StaleCount += p.StaleCount
+		out.UnchangedCount += p.UnchangedCount
+		out.PartialCount += p.PartialCount
+		for phase, n := range p.FailedComponents {
+			if out.FailedComponents == nil {
+				out.FailedComponents = make(map[string]int)
+			}
+			out.FailedComponents[phase] += n
+		}
+		for code, n := range p.FailureCodes {
+			if out.FailureCodes == nil {
+				out.FailureCodes = make(map[string]int)
+			}
+			out.FailureCodes[code] += n
+		}
+
+		if p.TotalDuration > out.TotalDuration {
+			out.TotalDuration = p.TotalDuration
+		}
+		if p.TotalServers > 0 {
+			durationSum += float64(p.AverageDuration) * float64(p.TotalServers)
+			if out.FastestDuration == 0 || (p.FastestDuration > 0 && p.FastestDuration < out.FastestDuration) {
+				out.FastestDuration = p.FastestDuration
+			}
+			if p.SlowestDuration > out.SlowestDuration {
+				out.SlowestDuration = p.SlowestDuration
+			}
+			out.DurationPercentiles = mergePercentiles(out.DurationPercentiles, p.DurationPercentiles)
+			out.DurationHistogram = mergeHistograms(out.DurationHistogram, p.DurationHistogram)
+		}
+
+		if p.Fleet != nil {
+			fleet.AddSummary(*p.Fleet)
+			hasFleet = true
+		}
+
+		for _, r := range p.FailureReasons {
+			if existing, ok := reasons[r.Category]; ok {
+				existing.Count += r.Count
+				continue
+			}
+			r := r
+			reasons[r.Category] = &r
+		}
+	}
+
+	if out.TotalServers > 0 {
+		out.AverageDuration = time.Duration(durationSum / float64(out.TotalServers))
+	}
+	if hasFleet {
+		summary := fleet.Summary()
+		out.Fleet = &summary
+	}
+	for _, r := range reasons {
+		out.FailureReasons = append(out.FailureReasons, *r)
+	}
+	sort.Slice(out.FailureReasons, func(i, j int) bool {
+		if out.FailureReasons[i].Count != out.FailureReasons[j].Count {
+			return out.FailureReasons[i].Count > out.FailureReasons[j].Count
+		}
+		return out.FailureReasons[i].Category < out.FailureReasons[j].Category
+	})
+	return out
+}
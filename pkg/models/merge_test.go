@@ -0,0 +1,55 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeResults(t *testing.T) {
+	a := CollectionStats{
+		RunID: "run-1", Shard: "1/2", TotalServers: 2, SuccessfulCount: 1, FailedCount: 1,
+		TotalDuration: 10 * time.Second, AverageDuration: 4 * time.Second,
+		FastestDuration: 2 * time.Second, SlowestDuration: 6 * time.Second,
+		FailureReasons: []FailureReason{{Category: "auth", Count: 1, ExampleHost: "10.0.0.2"}},
+		PartialCount:   1, FailedComponents: map[string]int{"memory": 1},
+		FailureCodes: map[string]int{"E_AUTH": 1},
+	}
+	b := CollectionStats{
+		RunID: "run-2", Shard: "2/2", TotalServers: 2, SuccessfulCount: 0, FailedCount: 2,
+		TotalDuration: 30 * time.Second, AverageDuration: 10 * time.Second,
+		FastestDuration: 5 * time.Second, SlowestDuration: 15 * time.Second,
+		FailureReasons: []FailureReason{
+			{Category: "auth", Count: 1, ExampleHost: "10.0.0.3"},
+			{Category: "timeout", Count: 1, ExampleHost: "10.0.0.4"},
+		},
+		FailureCodes: map[string]int{"E_AUTH": 1, "E_TIMEOUT": 1},
+	}
+
+	results, stats, err := MergeResults(
+		[][]ServerInfo{{{Host: "10.0.0.1"}, {Host: "10.0.0.2"}}, {{Host: "10.0.0.3"}, {Host: "10.0.0.4"}}},
+		[]CollectionStats{a, b},
+	)
+	require.NoError(t, err)
+	assert.Len(t, results, 4)
+
+	assert.Equal(t, "", stats.RunID, "runs differ")
+	assert.Equal(t, "", stats.Shard)
+	assert.Equal(t, 4, stats.TotalServers)
+	assert.Equal(t, 1, stats.SuccessfulCount)
+	assert.Equal(t, 3, stats.FailedCount)
+	assert.Equal(t, 30*time.Second, stats.TotalDuration, "shards run in parallel")
+	assert.Equal(t, 7*time.Second, stats.AverageDuration)
+	assert.Equal(t, 2*time.Second, stats.FastestDuration)
+	assert.Equal(t, 15*time.Second, stats.SlowestDuration)
+	require.Len(t, stats.FailureReasons, 2)
+	assert.Equal(t, FailureReason{Category: "auth", Count: 2, ExampleHost: "10.0.0.2"}, stats.FailureReasons[0])
+	assert.Equal(t, 1, stats.PartialCount)
+	assert.Equal(t, map[string]int{"memory": 1}, stats.FailedComponents)
+	assert.Equal(t, "E_AUTH=2,E_TIMEOUT=1", stats.FailureCodesSummary())
+
+	_, _, err = MergeResults([][]ServerInfo{{{Host: "10.0.0.1"}}, {{Host: "10.0.0.1"}}}, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"strings"
+	"unicode"
+)
+
+// vendorPrefixes are the words some firmware puts before the model name, as
+// in "Dell PowerEdge R750" or "Dell EMC PowerEdge R750".
+var vendorPrefixes = map[string]bool{"DELL": true, "EMC": true, "DELLEMC": true, "INC.": true, "INC": true}
+
+// ModelNames maps the model names reported by different firmware to one
+// canonical name, so that identical machines group together.
+type ModelNames struct {
+	aliases map[string]string
+}
+
+// NewModelNames returns a normalizer with the given aliases, which map
+// model names (after normalization, compared case-insensitively) to the
+// name to use instead (catalog.aliases).
+func NewModelNames(aliases map[string]string) ModelNames {
+	n := ModelNames{aliases: make(map[string]string, len(aliases))}
+	for from, to := range aliases {
+		n.aliases[strings.ToUpper(NormalizeModel("", from))] = strings.Join(strings.Fields(to), " ")
+	}
+	return n
+}
+
+// Canonical returns the normalized model name (see NormalizeModel), replaced
+// by its alias if it has one.
+func (n ModelNames) Canonical(manufacturer, model string) string {
+	name := NormalizeModel(manufacturer, model)
+	if alias, ok := n.aliases[strings.ToUpper(name)]; ok {
+		return alias
+	}
+	return name
+}
+
+// NormalizeModel trims a model name, collapses its whitespace and drops a
+// vendor prefix. PowerEdge models are spelled the Dell way, so "Dell
+// PowerEdge R750", "POWEREDGE r750 " and, for a Dell manufacturer, "R750"
+// all become "PowerEdge R750"; "poweredge r750XA" becomes "PowerEdge R750xa".
+// Other names keep their case.
+func NormalizeModel(manufacturer, model string) string {
+	fields := strings.Fields(model)
+	for len(fields) > 1 && vendorPrefixes[strings.ToUpper(fields[0])] {
+		fields = fields[1:]
+	}
+
+	dell := strings.Contains(strings.ToLower(manufacturer), "dell")
+	switch {
+	case len(fields) == 2 && strings.EqualFold(fields[0], defaultFamily):
+		if name, ok := dellModelNumber(fields[1]); ok {
+			fields = []string{defaultFamily, name}
+		}
+	case len(fields) == 1 && dell:
+		if name, ok := dellModelNumber(fields[0]); ok {
+			fields = []string{defaultFamily, name}
+		}
+	}
+	return strings.Join(fields, " ")
+}
+
+// dellModelNumber spells a model number such as "r750XA" the Dell way:
+// series in upper case, variant in lower case ("R750xa").
+func dellModelNumber(name string) (string, bool) {
+	rest := strings.TrimLeftFunc(name, unicode.IsLetter)
+	series := name[:len(name)-len(rest)]
+	variant := strings.TrimLeftFunc(rest, unicode.IsDigit)
+	number := rest[:len(rest)-len(variant)]
+	if series == "" || number == "" || strings.IndexFunc(variant, func(r rune) bool { return !unicode.IsLetter(r) }) >= 0 {
+		return "", false
+	}
+	return strings.ToUpper(series) + number + strings.ToLower(variant), true
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeModel(t *testing.T) {
+	tests := []struct {
+		manufacturer, model, want string
+	}{
+		{"Dell Inc.", "PowerEdge R750", "PowerEdge R750"},
+		{"Dell Inc.", "PowerEdge R750 ", "PowerEdge R750"},
+		{"Dell Inc.", "Dell PowerEdge R750", "PowerEdge R750"},
+		{"Dell Inc.", "Dell EMC  PowerEdge R750", "PowerEdge R750"},
+		{"Dell Inc.", "POWEREDGE r750XA", "PowerEdge R750xa"},
+		{"Dell Inc.", "R750", "PowerEdge R750"},
+		{"Dell Inc.", "PowerEdge MX750c", "PowerEdge MX750c"},
+		{"", "R750", "R750"},
+		{"Dell Inc.", "PowerEdge R750-A", "PowerEdge R750-A"},
+		{"Supermicro", " Super Server ", "Super Server"},
+		{"Dell Inc.", "", ""},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, NormalizeModel(tt.manufacturer, tt.model), "%q", tt.model)
+	}
+}
+
+func TestModelNames_Canonical(t *testing.T) {
+	names := NewModelNames(map[string]string{
+		"Dell PowerEdge R750 HX": "PowerEdge R750",
+		"vxrail p670f":           " VxRail  P670F ",
+	})
+
+	assert.Equal(t, "PowerEdge R750", names.Canonical("Dell Inc.", "PowerEdge R750 hx"))
+	assert.Equal(t, "VxRail P670F", names.Canonical("Dell Inc.", "VxRail P670F"))
+	assert.Equal(t, "PowerEdge R760", names.Canonical("Dell Inc.", "Dell PowerEdge R760"))
+	assert.Equal(t, "PowerEdge R760", ModelNames{}.Canonical("Dell Inc.", "PowerEdge R760 "))
+}
@@ -0,0 +1,706 @@
+// Package models defines the core data structures used throughout the application.
+// These models represent hardware information collected from iDRAC systems.
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/units"
+)
+
+// ServerInfo contains all hardware information collected from a single server.
+type ServerInfo struct {
+	// Connection details
+	Host        string    `json:"host"`
+	Name        string    `json:"name,omitempty"`
+	CollectedAt time.Time `json:"collected_at"`
+	RunID       string    `json:"run_id,omitempty"` // ID of the run that collected this data
+
+	// Labels are the labels configured for the server, e.g. datacenter and
+	// environment, for partitioning the results downstream.
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// SystemID is the Redfish ComputerSystem ID when the BMC exposes more
+	// than one system (e.g. a multi-node chassis); each system is a separate
+	// result. ParentChassis is the Redfish chassis containing the system.
+	SystemID      string `json:"system_id,omitempty"`
+	ParentChassis string `json:"parent_chassis,omitempty"`
+
+	// Error tracking - nil if collection succeeded
+	Error error `json:"-"`
+	// ErrorMessage is the string representation for JSON serialization
+	ErrorMessage string `json:"error,omitempty"`
+	// ErrorCode is the stable code of Error (e.g. E_AUTH, see pkg/errors)
+	ErrorCode string `json:"error_code,omitempty"`
+
+	// ComponentErrors maps the collector phases that failed to their error
+	// (e.g. "memory": E_TIMEOUT). A host with component errors but no Error
+	// is a partial result: the data of the other components is valid.
+	ComponentErrors map[string]ComponentError `json:"component_errors,omitempty"`
+
+	// Capabilities maps the collector phases to whether the iDRAC supports
+	// them: supported, degraded (some endpoints are missing) or unsupported.
+	// Phases that failed for another reason are not listed.
+	// UnsupportedEndpoints are the endpoints answered with 404 or 501.
+	Capabilities         map[string]string     `json:"capabilities,omitempty"`
+	UnsupportedEndpoints []UnsupportedEndpoint `json:"unsupported_endpoints,omitempty"`
+
+	// Stale is set when this host failed the current scan and the inventory
+	// below is the last known good data from StaleSince (CollectedAt of that scan).
+	// StaleError holds the error of the current scan.
+	Stale      bool       `json:"stale,omitempty"`
+	StaleSince *time.Time `json:"stale_since,omitempty"`
+	StaleError string     `json:"stale_error,omitempty"`
+
+	// LastChangedAt is the last inventory or configuration change reported
+	// by the Lifecycle Controller (nil if not reported). Unchanged is set
+	// when it matched the previous scan, so the hardware below was reused
+	// from the full scan at FullScanAt instead of being collected again.
+	LastChangedAt *time.Time `json:"last_changed_at,omitempty"`
+	Unchanged     bool       `json:"unchanged,omitempty"`
+	FullScanAt    *time.Time `json:"full_scan_at,omitempty"`
+
+	// System identification. Model is the canonical model name (see
+	// ModelNames); ReportedModel is the name the iDRAC reported if it differs.
+	Model         string `json:"model"`
+	ReportedModel string `json:"reported_model,omitempty"`
+	Manufacturer  string `json:"manufacturer"`
+	SerialNumber  string `json:"serial_number"`
+	ServiceTag    string `json:"service_tag"`
+	BiosVersion   string `json:"bios_version"`
+	HostName      string `json:"hostname"`
+	PowerState    string `json:"power_state"`
+
+	// Firmware version of the iDRAC ("" if the manager is not readable)
+	IDRACFirmwareVersion string `json:"idrac_firmware_version,omitempty"`
+
+	// Family, series and generation parsed from the model (nil if not a Dell)
+	Platform *Platform `json:"platform,omitempty"`
+
+	// Manufacture date for the age of the server (nil if unknown), and where
+	// it came from: the iDRAC or the ship date of the warranty API
+	ManufactureDate       *time.Time `json:"manufacture_date,omitempty"`
+	ManufactureDateSource string     `json:"manufacture_date_source,omitempty"`
+
+	// iDRAC license level (Basic, Express, Enterprise, Datacenter; "" if unknown)
+	LicenseLevel string        `json:"license_level,omitempty"`
+	Licenses     []LicenseInfo `json:"licenses,omitempty"`
+
+	// Network settings of the iDRAC itself (nil if not readable)
+	IDRACNetwork *IDRACNetworkInfo `json:"idrac_network,omitempty"`
+
+	// Clock drift of the iDRAC against the local clock (nil if the manager
+	// reports no DateTime)
+	Clock *ClockInfo `json:"clock,omitempty"`
+
+	// Local iDRAC accounts (nil unless accounts.audit is enabled)
+	Accounts *AccountAudit `json:"accounts,omitempty"`
+
+	// Result of the golden config check (nil if no profile matches)
+	Compliance *Compliance `json:"compliance,omitempty"`
+
+	// Result of the firmware policy check (nil if no policy is configured)
+	FirmwareCompliance *FirmwareCompliance `json:"firmware_compliance,omitempty"`
+
+	// Physical placement from the placement file (nil if not listed)
+	Placement *Placement `json:"placement,omitempty"`
+
+	// Operational notes from the annotations file (e.g. "pending RMA")
+	Notes []string `json:"notes,omitempty"`
+
+	// TLS certificate presented by the iDRAC (nil if not captured)
+	Certificate *CertificateInfo `json:"certificate,omitempty"`
+
+	// CPU information
+	CPUs     []CPUInfo `json:"cpus"`
+	CPUCount int       `json:"cpu_count"`
+	CPUModel string    `json:"cpu_model"`
+
+	// Memory information
+	Memory           []MemoryInfo `json:"memory"`
+	TotalMemoryGiB   float64      `json:"total_memory_gib"`
+	MemorySlotsTotal int          `json:"memory_slots_total"`
+	MemorySlotsUsed  int          `json:"memory_slots_used"`
+	MemorySlotsFree  int          `json:"memory_slots_free"`
+
+	// Storage information
+	Drives         []DriveInfo `json:"drives"`
+	DriveCount     int         `json:"drive_count"`
+	TotalStorageTB float64     `json:"total_storage_tb"`
+	DriveBaysTotal int         `json:"drive_bays_total,omitempty"` // 0 if not detectable
+
+	// Enclosures are the backplanes and external JBODs linked from the
+	// storage controllers
+	Enclosures []EnclosureInfo `json:"enclosures,omitempty"`
+
+	// Storage controllers with their cache and cache battery health
+	StorageControllers []StorageControllerInfo `json:"storage_controllers,omitempty"`
+
+	// Expansion slots (0 if the PCIeSlots resource is not available)
+	PCIeSlotsTotal int            `json:"pcie_slots_total,omitempty"`
+	PCIeSlotsUsed  int            `json:"pcie_slots_used,omitempty"`
+	PCIeSlots      []PCIeSlotInfo `json:"pcie_slots,omitempty"`
+
+	// GPU/Accelerator information ("Beschleuniger" in German iDRAC)
+	GPUs     []GPUInfo `json:"gpus,omitempty"`
+	GPUCount int       `json:"gpu_count"`
+
+	// Power information
+	PowerConsumedWatts int               `json:"power_consumed_watts,omitempty"`
+	PowerPeakWatts     int               `json:"power_peak_watts,omitempty"`
+	PowerSupplies      []PowerSupplyInfo `json:"power_supplies,omitempty"`
+
+	// Custom holds the data of site-specific collectors, keyed by collector
+	// name (see scanner.Collector).
+	Custom map[string]interface{} `json:"custom,omitempty"`
+
+	// Raw holds the Redfish responses read during the scan (output.raw).
+	Raw *RawPayloads `json:"raw,omitempty"`
+
+	// Scan timing: total duration and time spent per collector phase
+	ScanDuration   time.Duration            `json:"scan_duration,omitempty"`
+	PhaseDurations map[string]time.Duration `json:"phase_durations,omitempty"`
+}
+
+// Key identifies the result among those of a run: the host, plus the system
+// ID for hosts with several systems.
+func (s *ServerInfo) Key() string {
+	if s.SystemID == "" {
+		return s.Host
+	}
+	return s.Host + "/" + s.SystemID
+}
+
+// SetCustom stores the data of a custom collector under key.
+func (s *ServerInfo) SetCustom(key string, value interface{}) {
+	if s.Custom == nil {
+		s.Custom = make(map[string]interface{})
+	}
+	s.Custom[key] = value
+}
+
+// IsValid returns true if the server info was collected without errors.
+func (s *ServerInfo) IsValid() bool {
+	return s.Error == nil
+}
+
+// IsPartial returns true if the collection succeeded but some components
+// could not be collected.
+func (s *ServerInfo) IsPartial() bool {
+	return s.Error == nil && len(s.ComponentErrors) > 0
+}
+
+// ComponentError is the failure of a single collector phase.
+type ComponentError struct {
+	Code    string `json:"code"` // stable error code, e.g. E_TIMEOUT
+	Message string `json:"error"`
+}
+
+// SetComponentError records the failure of a collector phase.
+func (s *ServerInfo) SetComponentError(phase string, err error) {
+	if s.ComponentErrors == nil {
+		s.ComponentErrors = make(map[string]ComponentError)
+	}
+	s.ComponentErrors[phase] = ComponentError{Code: errorCode(err), Message: err.Error()}
+}
+
+// FailedComponents returns the collector phases with component errors,
+// sorted by name.
+func (s *ServerInfo) FailedComponents() []string {
+	phases := make([]string, 0, len(s.ComponentErrors))
+	for phase := range s.ComponentErrors {
+		phases = append(phases, phase)
+	}
+	sort.Strings(phases)
+	return phases
+}
+
+// ComponentErrorList returns the component errors as "phase: error (code)",
+// sorted by phase.
+func (s *ServerInfo) ComponentErrorList() []string {
+	list := s.FailedComponents()
+	for i, phase := range list {
+		e := s.ComponentErrors[phase]
+		list[i] = fmt.Sprintf("%s: %s (%s)", phase, e.Message, e.Code)
+	}
+	return list
+}
+
+// Summary returns a brief one-line summary of the server.
+func (s *ServerInfo) Summary() string {
+	if s.Error != nil {
+		return fmt.Sprintf("%s: ERROR - %v", s.Host, s.Error)
+	}
+	return fmt.Sprintf("%s: %s, %d CPUs, %.0f GiB RAM (%d/%d slots), %d drives (%.2f TB)",
+		s.Host, s.Model, s.CPUCount, s.TotalMemoryGiB,
+		s.MemorySlotsUsed, s.MemorySlotsTotal, s.DriveCount, s.TotalStorageTB)
+}
+
+// MarshalJSON implements custom JSON marshaling to include error message
+// and code.
+func (s ServerInfo) MarshalJSON() ([]byte, error) {
+	type Alias ServerInfo
+	aux := struct {
+		Alias
+		ErrorMessage  string `json:"error,omitempty"`
+		ErrorCode     string `json:"error_code,omitempty"`
+		SchemaVersion int    `json:"schema_version"`
+	}{
+		Alias:         Alias(s),
+		SchemaVersion: SchemaVersion,
+	}
+	if s.Error != nil {
+		aux.ErrorMessage = s.Error.Error()
+		aux.ErrorCode = errorCode(s.Error)
+	}
+	return json.Marshal(aux)
+}
+
+// UnmarshalJSON restores Error from the serialized error message and code,
+// so that results read back from JSON output are treated as failed again.
+// Results of older schema versions are migrated first (see SchemaVersion).
+func (s *ServerInfo) UnmarshalJSON(data []byte) error {
+	data, _, err := MigrateServerJSON(data)
+	if err != nil {
+		return err
+	}
+	type Alias ServerInfo
+	var aux Alias
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	*s = ServerInfo(aux)
+	if s.ErrorMessage != "" {
+		s.Error = restoreError(s.ErrorMessage, s.ErrorCode)
+	}
+	return nil
+}
+
+// GetDisplayName returns the best available name for the server.
+func (s *ServerInfo) GetDisplayName() string {
+	if s.Name != "" {
+		if s.SystemID != "" {
+			return s.Name + "/" + s.SystemID
+		}
+		return s.Name
+	}
+	if s.HostName != "" {
+		return s.HostName
+	}
+	return s.Key()
+}
+
+// CPUInfo contains detailed information about a single processor.
+type CPUInfo struct {
+	Socket            string `json:"socket"`
+	Model             string `json:"model"`
+	Manufacturer      string `json:"manufacturer"`
+	Brand             string `json:"brand"`   // CPU brand (e.g., "Intel Xeon", "AMD EPYC")
+	Cores             int    `json:"cores"`   // Physical core count
+	Threads           int    `json:"threads"` // Logical thread count
+	MaxSpeedMHz       int    `json:"max_speed_mhz"`
+	OperatingSpeedMHz int    `json:"operating_speed_mhz"`
+	ProcessorType     string `json:"processor_type"`  // e.g., "CPU"
+	Architecture      string `json:"architecture"`    // e.g., "x86", "ARM"
+	InstructionSet    string `json:"instruction_set"` // e.g., "x86-64"
+	Health            string `json:"health"`
+}
+
+// String returns a human-readable representation of the CPU.
+func (c CPUInfo) String() string {
+	brand := c.Model
+	if c.Brand != "" {
+		brand = c.Brand
+	}
+	return fmt.Sprintf("%s: %s (%d cores/%d threads @ %d MHz)",
+		c.Socket, brand, c.Cores, c.Threads, c.MaxSpeedMHz)
+}
+
+// TotalSpeed returns the total theoretical speed (cores * speed).
+func (c CPUInfo) TotalSpeed() int {
+	return c.Cores * c.MaxSpeedMHz
+}
+
+// MemoryInfo contains detailed information about a single memory module or slot.
+type MemoryInfo struct {
+	Slot           string `json:"slot"`
+	CapacityMiB    int    `json:"capacity_mib"`     // Module size in MiB
+	Type           string `json:"type"`             // Memory device type (e.g., "DDR4", "DDR5")
+	Technology     string `json:"technology"`       // Memory technology detail
+	BaseModuleType string `json:"base_module_type"` // Module type (e.g., "RDIMM", "UDIMM", "LRDIMM")
+	SpeedMHz       int    `json:"speed_mhz"`        // Operating speed
+	Manufacturer   string `json:"manufacturer"`
+	PartNumber     string `json:"part_number"`
+	SerialNumber   string `json:"serial_number"`
+	RankCount      int    `json:"rank_count"`      // Number of ranks
+	DataWidthBits  int    `json:"data_width_bits"` // Data width
+	State          string `json:"state"`
+	Health         string `json:"health"`
+}
+
+// Memory state constants as returned by Redfish API.
+const (
+	MemoryStateEnabled  = "Enabled"
+	MemoryStateAbsent   = "Absent"
+	MemoryStateDisabled = "Disabled"
+)
+
+// IsPopulated returns true if this memory slot contains a DIMM.
+func (m MemoryInfo) IsPopulated() bool {
+	return m.State == MemoryStateEnabled
+}
+
+// IsEmpty returns true if this memory slot is empty.
+func (m MemoryInfo) IsEmpty() bool {
+	return m.State == MemoryStateAbsent
+}
+
+// CapacityGB returns the capacity in gigabytes.
+func (m MemoryInfo) CapacityGB() float64 {
+	return float64(m.CapacityMiB) / 1024
+}
+
+// String returns a human-readable representation of the memory module.
+func (m MemoryInfo) String() string {
+	if m.IsEmpty() {
+		return fmt.Sprintf("%s: [empty]", m.Slot)
+	}
+
+	// Include base module type if available (e.g., "DDR4 RDIMM")
+	memType := m.Type
+	if m.BaseModuleType != "" && m.BaseModuleType != m.Type {
+		memType = fmt.Sprintf("%s %s", m.Type, m.BaseModuleType)
+	}
+
+	return fmt.Sprintf("%s: %.0f GB %s @ %d MHz (%s)",
+		m.Slot, m.CapacityGB(), memType, m.SpeedMHz, m.Manufacturer)
+}
+
+// DriveInfo contains detailed information about a single storage drive.
+type DriveInfo struct {
+	Name         string  `json:"name"`
+	Model        string  `json:"model"`
+	Manufacturer string  `json:"manufacturer"`
+	SerialNumber string  `json:"serial_number"`
+	PartNumber   string  `json:"part_number,omitempty"`
+	CapacityGB   float64 `json:"capacity_gb"` // exact capacity in GiB
+	// CapacityBytes is the exact capacity as reported by Redfish. Results
+	// from older versions only have CapacityGB.
+	CapacityBytes int64   `json:"capacity_bytes,omitempty"`
+	MediaType     string  `json:"media_type"`
+	Protocol      string  `json:"protocol"`
+	LifeLeftPct   float64 `json:"life_left_pct,omitempty"`
+	Health        string  `json:"health"`
+
+	// Bay is the backplane bay of the drive (nil for drives that are not
+	// in a bay, like BOSS cards), and Enclosure the ID of its backplane or
+	// enclosure. Results from older versions have neither.
+	Bay       *int   `json:"bay,omitempty"`
+	Enclosure string `json:"enclosure,omitempty"`
+
+	// NVMe is the link and endurance telemetry of NVMe drives, nil for
+	// other protocols.
+	NVMe *NVMeInfo `json:"nvme,omitempty"`
+}
+
+// Bytes returns the exact capacity in bytes.
+func (d DriveInfo) Bytes() int64 {
+	if d.CapacityBytes > 0 {
+		return d.CapacityBytes
+	}
+	return units.GiBToBytes(d.CapacityGB)
+}
+
+// MarketingGB returns the labelled capacity of the drive in decimal GB,
+// e.g. 960 for a drive of 894 GiB.
+func (d DriveInfo) MarketingGB() int {
+	return units.MarketingGB(d.Bytes())
+}
+
+// CapacityTB returns the capacity in terabytes.
+func (d DriveInfo) CapacityTB() float64 {
+	return d.CapacityGB / 1024
+}
+
+// IsSSD returns true if this is a solid-state drive.
+func (d DriveInfo) IsSSD() bool {
+	return d.MediaType == "SSD"
+}
+
+// IsHDD returns true if this is a hard disk drive.
+func (d DriveInfo) IsHDD() bool {
+	return d.MediaType == "HDD"
+}
+
+// String returns a human-readable representation of the drive.
+func (d DriveInfo) String() string {
+	lifeInfo := ""
+	if d.LifeLeftPct > 0 && d.IsSSD() {
+		lifeInfo = fmt.Sprintf(" [%.0f%% life remaining]", d.LifeLeftPct)
+	}
+	return fmt.Sprintf("%s: %.0f GB %s %s (%s)%s",
+		d.Name, d.CapacityGB, d.MediaType, d.Protocol, d.Model, lifeInfo)
+}
+
+// IDRACNetworkInfo holds the out-of-band network settings of the iDRAC, from
+// the active Manager EthernetInterface.
+type IDRACNetworkInfo struct {
+	Interface   string   `json:"interface"` // e.g. "NIC.1"
+	MACAddress  string   `json:"mac_address,omitempty"`
+	HostName    string   `json:"hostname,omitempty"`
+	FQDN        string   `json:"fqdn,omitempty"`
+	DHCP        bool     `json:"dhcp"`
+	IPv4Address string   `json:"ipv4_address,omitempty"`
+	SubnetMask  string   `json:"subnet_mask,omitempty"`
+	Gateway     string   `json:"gateway,omitempty"`
+	VLANEnabled bool     `json:"vlan_enabled"`
+	VLANID      int      `json:"vlan_id,omitempty"`
+	NameServers []string `json:"name_servers,omitempty"`
+	SpeedMbps   int      `json:"speed_mbps,omitempty"`
+
+	// NICSelection is the port the iDRAC uses: "Dedicated" or a shared LOM
+	// ("LOM1", ...), from the Dell iDRAC attributes ("" if unknown).
+	NICSelection string `json:"nic_selection,omitempty"`
+}
+
+// DNSName returns the DNS name of the iDRAC: the FQDN, or the host name if
+// no domain is configured.
+func (n IDRACNetworkInfo) DNSName() string {
+	if n.FQDN != "" {
+		return n.FQDN
+	}
+	return n.HostName
+}
+
+// EnclosureInfo describes a drive backplane or an external storage enclosure
+// (JBOD) attached to a storage controller.
+type EnclosureInfo struct {
+	ID              string `json:"id"` // Dell FQDD, e.g. "Enclosure.Internal.0-1:RAID.Integrated.1-1"
+	Name            string `json:"name"`
+	Model           string `json:"model,omitempty"`
+	Manufacturer    string `json:"manufacturer,omitempty"`
+	PartNumber      string `json:"part_number,omitempty"`
+	SerialNumber    string `json:"serial_number,omitempty"`
+	ServiceTag      string `json:"service_tag,omitempty"` // of an external enclosure
+	FirmwareVersion string `json:"firmware_version,omitempty"`
+	External        bool   `json:"external"`
+	Connector       int    `json:"connector,omitempty"`
+	SlotCount       int    `json:"slot_count"`           // 0 if not reported
+	SlotsUsed       int    `json:"slots_used,omitempty"` // drives linked from the enclosure
+	Health          string `json:"health"`
+}
+
+// String returns a human-readable representation of the enclosure.
+func (e EnclosureInfo) String() string {
+	kind := "backplane"
+	if e.External {
+		kind = "external"
+	}
+	name := e.Model
+	if name == "" {
+		name = e.Name
+	}
+	s := fmt.Sprintf("%s (%s", name, kind)
+	if e.SlotCount > 0 {
+		s += fmt.Sprintf(", %d/%d slots used", e.SlotsUsed, e.SlotCount)
+	}
+	if e.FirmwareVersion != "" {
+		s += ", firmware " + e.FirmwareVersion
+	}
+	return s + ")"
+}
+
+// GPUInfo contains information about a GPU or accelerator ("Beschleuniger" in German iDRAC).
+type GPUInfo struct {
+	Slot         string `json:"slot"`
+	Model        string `json:"model"`
+	Manufacturer string `json:"manufacturer"`
+	MemoryMiB    int    `json:"memory_mib"`  // VRAM size in MiB (0 if unknown)
+	MemoryType   string `json:"memory_type"` // e.g. "GDDR6", "HBM2"
+	Health       string `json:"health"`
+}
+
+// MemoryGB returns the GPU VRAM in gigabytes.
+func (g GPUInfo) MemoryGB() float64 {
+	return float64(g.MemoryMiB) / 1024
+}
+
+// String returns a human-readable representation of the GPU.
+func (g GPUInfo) String() string {
+	if g.MemoryMiB > 0 {
+		return fmt.Sprintf("%s: %s (%.0f GB VRAM)", g.Slot, g.Model, g.MemoryGB())
+	}
+	return fmt.Sprintf("%s: %s", g.Slot, g.Model)
+}
+
+// PowerSupplyInfo contains information about a single power supply unit.
+type PowerSupplyInfo struct {
+	Name            string  `json:"name"`
+	Model           string  `json:"model"`
+	Manufacturer    string  `json:"manufacturer"`
+	PartNumber      string  `json:"part_number"`
+	SparePartNumber string  `json:"spare_part_number,omitempty"`
+	SerialNumber    string  `json:"serial_number"`
+	FirmwareVersion string  `json:"firmware_version"`
+	CapacityWatts   float64 `json:"capacity_watts"`
+	InputWatts      float64 `json:"input_watts,omitempty"` // current input power, 0 if not reported
+	Health          string  `json:"health"`
+	State           string  `json:"state"`
+}
+
+// IsInstalled returns true if the PSU bay is populated.
+func (p PowerSupplyInfo) IsInstalled() bool {
+	return p.State != "" && p.State != MemoryStateAbsent
+}
+
+// String returns a human-readable representation of the power supply.
+func (p PowerSupplyInfo) String() string {
+	return fmt.Sprintf("%s: %s (%.0f W)", p.Name, p.Model, p.CapacityWatts)
+}
+
+// Health status constants.
+const (
+	HealthOK       = "OK"
+	HealthWarning  = "Warning"
+	HealthCritical = "Critical"
+)
+
+// Power state constants.
+const (
+	PowerStateOn          = "On"
+	PowerStateOff         = "Off"
+	PowerStatePoweringOn  = "PoweringOn"
+	PowerStatePoweringOff = "PoweringOff"
+)
+
+// ScanResult contains the result of scanning multiple servers.
+type ScanResult struct {
+	Servers   []ServerInfo    `json:"servers"`
+	Stats     CollectionStats `json:"stats"`
+	StartTime time.Time       `json:"start_time"`
+	EndTime   time.Time       `json:"end_time"`
+}
+
+// CollectionStats provides statistics about a batch collection operation.
+type CollectionStats struct {
+	RunID           string        `json:"run_id,omitempty"`
+	Shard           string        `json:"shard,omitempty"` // "K/N" if only a shard of the servers was scanned
+	TotalServers    int           `json:"total_servers"`
+	SuccessfulCount int           `json:"successful_count"`
+	FailedCount     int           `json:"failed_count"`
+	TotalDuration   time.Duration `json:"total_duration"`
+
+	// AverageDuration and FastestDuration are kept for consumers of the
+	// JSON output; the summaries show the percentiles instead, which a few
+	// pathological hosts do not skew.
+	AverageDuration time.Duration `json:"average_duration"`
+	FastestDuration time.Duration `json:"fastest_duration"`
+	SlowestDuration time.Duration `json:"slowest_duration"`
+
+	// DurationPercentiles are the per-host scan durations at the
+	// configured percentiles (stats.percentiles, default p50, p90, p99);
+	// DurationHistogram counts the hosts per DurationBuckets range.
+	DurationPercentiles []DurationPercentile `json:"duration_percentiles,omitempty"`
+	DurationHistogram   []HistogramBucket    `json:"duration_histogram,omitempty"`
+
+	// RescannedCount and RecoveredCount describe the optional second pass over
+	// failed hosts: how many were retried and how many of those succeeded.
+	RescannedCount int `json:"rescanned_count,omitempty"`
+	RecoveredCount int `json:"recovered_count,omitempty"`
+
+	// StaleCount is the number of failed hosts reported with their last known
+	// good inventory instead (included in FailedCount).
+	StaleCount int `json:"stale_count,omitempty"`
+
+	// UnchangedCount is the number of successful hosts whose inventory was
+	// reused because the Lifecycle Controller reported no change.
+	UnchangedCount int `json:"unchanged_count,omitempty"`
+
+	// PartialCount is the number of successful hosts with component errors
+	// (included in SuccessfulCount); FailedComponents counts those errors
+	// by collector phase.
+	PartialCount     int            `json:"partial_count,omitempty"`
+	FailedComponents map[string]int `json:"failed_components,omitempty"`
+
+	// FailureReasons groups failed hosts by error category, most frequent first.
+	FailureReasons []FailureReason `json:"failure_reasons,omitempty"`
+
+	// FailureCodes counts failed hosts by error code (see pkg/errors).
+	FailureCodes map[string]int `json:"failure_codes,omitempty"`
+
+	// Fleet totals the hardware of the successfully scanned hosts (nil if
+	// there were none).
+	Fleet *FleetSummary `json:"fleet,omitempty"`
+}
+
+// FailureReason counts failed hosts that share an error category
+// (auth, timeout, tls, dns, endpoint_missing, parse, ...).
+type FailureReason struct {
+	Category string `json:"category"`
+	Count    int    `json:"count"`
+
+	// Example is the error of the first failed host in this category.
+	ExampleHost  string `json:"example_host"`
+	ExampleError string `json:"example_error"`
+}
+
+// TopFailureReasons returns at most n failure reasons (all if n <= 0).
+func (s CollectionStats) TopFailureReasons(n int) []FailureReason {
+	if n <= 0 || n >= len(s.FailureReasons) {
+		return s.FailureReasons
+	}
+	return s.FailureReasons[:n]
+}
+
+// FailedComponentsSummary lists the failed collector phases with their
+// host counts, most frequent first, e.g. "memory 3, storage 1".
+func (s CollectionStats) FailedComponentsSummary() string {
+	phases := make([]string, 0, len(s.FailedComponents))
+	for phase := range s.FailedComponents {
+		phases = append(phases, phase)
+	}
+	sort.Slice(phases, func(i, j int) bool {
+		if s.FailedComponents[phases[i]] != s.FailedComponents[phases[j]] {
+			return s.FailedComponents[phases[i]] > s.FailedComponents[phases[j]]
+		}
+		return phases[i] < phases[j]
+	})
+	parts := make([]string, len(phases))
+	for i, phase := range phases {
+		parts[i] = fmt.Sprintf("%s %d", phase, s.FailedComponents[phase])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// FailureCodesSummary lists the error codes of failed hosts with their
+// counts, sorted by code, e.g. "E_AUTH=2,E_TIMEOUT=1".
+func (s CollectionStats) FailureCodesSummary() string {
+	codes := make([]string, 0, len(s.FailureCodes))
+	for code := range s.FailureCodes {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	parts := make([]string, len(codes))
+	for i, code := range codes {
+		parts[i] = fmt.Sprintf("%s=%d", code, s.FailureCodes[code])
+	}
+	return strings.Join(parts, ",")
+}
+
+// SuccessRate returns the percentage of successful collections.
+func (s CollectionStats) SuccessRate() float64 {
+	if s.TotalServers == 0 {
+		return 0
+	}
+	return float64(s.SuccessfulCount) / float64(s.TotalServers) * 100
+}
+
+// String returns a human-readable summary of the collection stats.
+func (s CollectionStats) String() string {
+	return fmt.Sprintf("Scanned %d servers: %d successful, %d failed (%.1f%% success rate) in %s",
+		s.TotalServers, s.SuccessfulCount, s.FailedCount, s.SuccessRate(), s.TotalDuration)
+}
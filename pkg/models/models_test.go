@@ -1,6 +1,7 @@
 package models
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"testing"
@@ -20,6 +21,16 @@ func TestServerInfo_IsValid(t *testing.T) {
 		s := ServerInfo{Host: "192.168.1.10", Error: errors.New("connection failed")}
 		assert.False(t, s.IsValid())
 	})
+
+	t.Run("partial server", func(t *testing.T) {
+		s := ServerInfo{Host: "192.168.1.10"}
+		s.SetComponentError(PhaseStorage, errors.New("500 Internal Server Error"))
+		s.SetComponentError(PhaseMemory, context.DeadlineExceeded)
+		assert.True(t, s.IsValid())
+		assert.True(t, s.IsPartial())
+		assert.Equal(t, []string{"memory", "storage"}, s.FailedComponents())
+		assert.Equal(t, []string{"memory: context deadline exceeded (E_TIMEOUT)", "storage: 500 Internal Server Error (E_UNKNOWN)"}, s.ComponentErrorList())
+	})
 }
 
 func TestServerInfo_Summary(t *testing.T) {
@@ -72,6 +83,7 @@ func TestServerInfo_MarshalJSON(t *testing.T) {
 
 		assert.Equal(t, "192.168.1.10", result["host"])
 		assert.Equal(t, "connection timeout", result["error"])
+		assert.Equal(t, "E_TIMEOUT", result["error_code"])
 	})
 
 	t.Run("without error", func(t *testing.T) {
@@ -113,6 +125,16 @@ func TestServerInfo_GetDisplayName(t *testing.T) {
 			server:   ServerInfo{Host: "192.168.1.10"},
 			expected: "192.168.1.10",
 		},
+		{
+			name:     "system of a multi-system host",
+			server:   ServerInfo{Name: "chassis-01", Host: "192.168.1.10", SystemID: "Node2"},
+			expected: "chassis-01/Node2",
+		},
+		{
+			name:     "system of a multi-system host, IP only",
+			server:   ServerInfo{Host: "192.168.1.10", SystemID: "Node2"},
+			expected: "192.168.1.10/Node2",
+		},
 	}
 
 	for _, tt := range tests {
@@ -301,6 +323,12 @@ func TestCollectionStats_String(t *testing.T) {
 	assert.Contains(t, str, "80.0% success rate")
 }
 
+func TestCollectionStats_FailedComponentsSummary(t *testing.T) {
+	stats := CollectionStats{FailedComponents: map[string]int{"storage": 1, "memory": 3, "power": 1}}
+	assert.Equal(t, "memory 3, power 1, storage 1", stats.FailedComponentsSummary())
+	assert.Empty(t, CollectionStats{}.FailedComponentsSummary())
+}
+
 func TestConstants(t *testing.T) {
 	// Verify constants are defined
 	assert.Equal(t, "Enabled", MemoryStateEnabled)
@@ -314,3 +342,30 @@ func TestConstants(t *testing.T) {
 	assert.Equal(t, "On", PowerStateOn)
 	assert.Equal(t, "Off", PowerStateOff)
 }
+
+func TestServerInfo_UnmarshalJSON(t *testing.T) {
+	data, err := json.Marshal([]ServerInfo{
+		{Host: "192.168.1.10", Model: "PowerEdge R750"},
+		{Host: "192.168.1.11", Error: errors.New("connection timeout")},
+	})
+	require.NoError(t, err)
+
+	var servers []ServerInfo
+	require.NoError(t, json.Unmarshal(data, &servers))
+
+	require.Len(t, servers, 2)
+	assert.True(t, servers[0].IsValid())
+	assert.Equal(t, "PowerEdge R750", servers[0].Model)
+	require.Error(t, servers[1].Error)
+	assert.Equal(t, "connection timeout", servers[1].Error.Error())
+
+	t.Run("keeps the stored code", func(t *testing.T) {
+		var s ServerInfo
+		require.NoError(t, json.Unmarshal([]byte(`{"host":"192.168.1.12","error":"login rejected","error_code":"E_AUTH"}`), &s))
+		require.Error(t, s.Error)
+
+		data, err := json.Marshal(s)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"error_code":"E_AUTH"`)
+	})
+}
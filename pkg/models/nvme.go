@@ -0,0 +1,132 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ProtocolNVMe is the Redfish protocol of NVMe drives.
+const ProtocolNVMe = "NVMe"
+
+// NVMeInfo is the telemetry of an NVMe drive. Link speeds are PCIe transfer
+// rates in GT/s (8 for Gen3, 16 for Gen4, 32 for Gen5) and widths lane
+// counts; zero values were not reported.
+type NVMeInfo struct {
+	LinkSpeedGTs        float64 `json:"link_speed_gts,omitempty"`
+	CapableLinkSpeedGTs float64 `json:"capable_link_speed_gts,omitempty"`
+	LinkWidth           int     `json:"link_width,omitempty"`
+	CapableLinkWidth    int     `json:"capable_link_width,omitempty"`
+	NegotiatedSpeedGbs  float64 `json:"negotiated_speed_gbs,omitempty"` // Redfish NegotiatedSpeedGbs
+	Namespaces          int     `json:"namespaces,omitempty"`
+
+	// EnduranceLeftPct is the remaining rated write endurance from the Dell
+	// OEM data, nil if not reported.
+	EnduranceLeftPct *int `json:"endurance_left_pct,omitempty"`
+	FailurePredicted bool `json:"failure_predicted,omitempty"`
+}
+
+// Link returns the negotiated PCIe link, e.g. "x4 @ 16 GT/s", or "" if it
+// is not known.
+func (n NVMeInfo) Link() string {
+	switch {
+	case n.LinkWidth > 0 && n.LinkSpeedGTs > 0:
+		return fmt.Sprintf("x%d @ %g GT/s", n.LinkWidth, n.LinkSpeedGTs)
+	case n.LinkWidth > 0:
+		return fmt.Sprintf("x%d", n.LinkWidth)
+	case n.LinkSpeedGTs > 0:
+		return fmt.Sprintf("%g GT/s", n.LinkSpeedGTs)
+	case n.NegotiatedSpeedGbs > 0:
+		return fmt.Sprintf("%g Gbit/s", n.NegotiatedSpeedGbs)
+	}
+	return ""
+}
+
+// Degraded reports whether the link runs narrower or slower than the drive
+// supports, e.g. x2 after a bad reseat or Gen3 in a Gen4 drive.
+func (n NVMeInfo) Degraded() bool {
+	return (n.LinkWidth > 0 && n.LinkWidth < n.CapableLinkWidth) ||
+		(n.LinkSpeedGTs > 0 && n.LinkSpeedGTs < n.CapableLinkSpeedGTs)
+}
+
+// IsNVMe reports whether the drive is an NVMe drive.
+func (d DriveInfo) IsNVMe() bool {
+	return d.NVMe != nil || d.Protocol == ProtocolNVMe
+}
+
+// NVMeWear is an NVMe drive in the wear report.
+type NVMeWear struct {
+	Host             string   `json:"host"`
+	ServiceTag       string   `json:"service_tag,omitempty"`
+	Drive            string   `json:"drive"`
+	Model            string   `json:"model,omitempty"`
+	SerialNumber     string   `json:"serial_number,omitempty"`
+	Health           string   `json:"health,omitempty"`
+	LifeLeftPct      float64  `json:"life_left_pct,omitempty"`
+	EnduranceLeftPct *int     `json:"endurance_left_pct,omitempty"`
+	FailurePredicted bool     `json:"failure_predicted,omitempty"`
+	Link             string   `json:"link,omitempty"`
+	LinkDegraded     bool     `json:"link_degraded,omitempty"`
+	Namespaces       int      `json:"namespaces,omitempty"`
+	WearLeftPct      *float64 `json:"wear_left_pct,omitempty"` // lower of life and endurance left
+}
+
+// BuildNVMeWearReport lists the NVMe drives of the successfully scanned
+// servers, most worn first: drives predicted to fail, then by the lower of
+// the predicted media life and the rated write endurance left. Drives that
+// report neither come last.
+func BuildNVMeWearReport(results []ServerInfo) []NVMeWear {
+	var report []NVMeWear
+	for _, s := range results {
+		if s.Error != nil {
+			continue
+		}
+		for _, d := range s.Drives {
+			if !d.IsNVMe() {
+				continue
+			}
+			w := NVMeWear{
+				Host:         s.Host,
+				ServiceTag:   s.ServiceTag,
+				Drive:        d.Name,
+				Model:        d.Model,
+				SerialNumber: d.SerialNumber,
+				Health:       d.Health,
+				LifeLeftPct:  d.LifeLeftPct,
+			}
+			if d.LifeLeftPct > 0 {
+				left := d.LifeLeftPct
+				w.WearLeftPct = &left
+			}
+			if n := d.NVMe; n != nil {
+				w.EnduranceLeftPct = n.EnduranceLeftPct
+				w.FailurePredicted = n.FailurePredicted
+				w.Link = n.Link()
+				w.LinkDegraded = n.Degraded()
+				w.Namespaces = n.Namespaces
+				if e := n.EnduranceLeftPct; e != nil && (w.WearLeftPct == nil || float64(*e) < *w.WearLeftPct) {
+					left := float64(*e)
+					w.WearLeftPct = &left
+				}
+			}
+			report = append(report, w)
+		}
+	}
+
+	sort.SliceStable(report, func(i, j int) bool {
+		a, b := report[i], report[j]
+		if a.FailurePredicted != b.FailurePredicted {
+			return a.FailurePredicted
+		}
+		if (a.WearLeftPct == nil) != (b.WearLeftPct == nil) {
+			return a.WearLeftPct != nil
+		}
+		if a.WearLeftPct != nil && *a.WearLeftPct != *b.WearLeftPct {
+			return *a.WearLeftPct < *b.WearLeftPct
+		}
+		if a.Host != b.Host {
+			return a.Host < b.Host
+		}
+		return a.Drive < b.Drive
+	})
+	return report
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNVMeInfo_Link(t *testing.T) {
+	assert.Equal(t, "x4 @ 16 GT/s", NVMeInfo{LinkWidth: 4, LinkSpeedGTs: 16}.Link())
+	assert.Equal(t, "x2", NVMeInfo{LinkWidth: 2}.Link())
+	assert.Equal(t, "64 Gbit/s", NVMeInfo{NegotiatedSpeedGbs: 64}.Link())
+	assert.Empty(t, NVMeInfo{}.Link())
+
+	assert.False(t, NVMeInfo{LinkWidth: 4, CapableLinkWidth: 4, LinkSpeedGTs: 16, CapableLinkSpeedGTs: 16}.Degraded())
+	assert.True(t, NVMeInfo{LinkWidth: 2, CapableLinkWidth: 4}.Degraded())
+	assert.True(t, NVMeInfo{LinkSpeedGTs: 8, CapableLinkSpeedGTs: 16}.Degraded())
+	assert.False(t, NVMeInfo{CapableLinkWidth: 4}.Degraded(), "unknown negotiated width")
+}
+
+func TestBuildNVMeWearReport(t *testing.T) {
+	pct := func(n int) *int { return &n }
+	results := []ServerInfo{
+		{
+			Host: "10.0.0.1",
+			Drives: []DriveInfo{
+				{Name: "SATA SSD", Protocol: "SATA", LifeLeftPct: 10},
+				{Name: "NVMe 0", Protocol: ProtocolNVMe, LifeLeftPct: 90, NVMe: &NVMeInfo{EnduranceLeftPct: pct(40), LinkWidth: 2, CapableLinkWidth: 4}},
+				{Name: "NVMe 1", Protocol: ProtocolNVMe, NVMe: &NVMeInfo{}},
+			},
+		},
+		{
+			Host: "10.0.0.2",
+			Drives: []DriveInfo{
+				{Name: "NVMe 0", Protocol: ProtocolNVMe, LifeLeftPct: 95, NVMe: &NVMeInfo{FailurePredicted: true}},
+				{Name: "NVMe 1", Protocol: ProtocolNVMe, LifeLeftPct: 70}, // older result without telemetry
+			},
+		},
+		{Host: "10.0.0.3", Error: errors.New("timeout")},
+	}
+
+	report := BuildNVMeWearReport(results)
+	require.Len(t, report, 4)
+	assert.Equal(t, "10.0.0.2", report[0].Host, "predicted failures first")
+	assert.True(t, report[0].FailurePredicted)
+
+	assert.Equal(t, "10.0.0.1", report[1].Host)
+	assert.Equal(t, "NVMe 0", report[1].Drive)
+	require.NotNil(t, report[1].WearLeftPct)
+	assert.Equal(t, 40.0, *report[1].WearLeftPct, "endurance is lower than the media life")
+	assert.True(t, report[1].LinkDegraded)
+	assert.Equal(t, "x2", report[1].Link)
+
+	assert.Equal(t, "10.0.0.2", report[2].Host)
+	assert.Equal(t, 70.0, *report[2].WearLeftPct)
+
+	assert.Equal(t, "NVMe 1", report[3].Drive)
+	assert.Nil(t, report[3].WearLeftPct, "drives without wear data come last")
+}
@@ -0,0 +1,17 @@
+package models
+
+// Placement is the physical location of a server, which the iDRAC does not
+// know. It is merged into scan results from a placement file (service tag →
+// site/rack/position/tenant) maintained during racking.
+type Placement struct {
+	Site     string  `json:"site,omitempty" yaml:"site"`
+	Rack     string  `json:"rack,omitempty" yaml:"rack"`
+	Position float64 `json:"position,omitempty" yaml:"position"` // lowest occupied U
+	Face     string  `json:"face,omitempty" yaml:"face"`         // front or rear
+	Tenant   string  `json:"tenant,omitempty" yaml:"tenant"`
+}
+
+// IsEmpty reports whether no placement attribute is set.
+func (p Placement) IsEmpty() bool {
+	return p == Placement{}
+}
@@ -0,0 +1,140 @@
+package models
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// defaultFamily is the family of Dell models reported without one ("R750").
+const defaultFamily = "PowerEdge"
+
+// Platform is the structure of a Dell model name: "PowerEdge R750xa" is
+// family PowerEdge, series R, number 750, variant xa of generation 15G.
+type Platform struct {
+	Family     string `json:"family"`
+	Series     string `json:"series"`
+	Number     string `json:"number"`
+	Variant    string `json:"variant,omitempty"`
+	Generation string `json:"generation,omitempty"`
+}
+
+// ParsePlatform parses a Dell model name. A server is taken for a Dell if
+// the manufacturer says so or, when the manufacturer is not reported, if it
+// has a Dell service tag (seven letters and digits). Returns nil for other
+// servers and model names that do not parse.
+//
+// The generation is ten plus the second digit of a three- or four-digit
+// model number: "R640" is 14G, "R6525" and "MX750c" are 15G, "XE9680" is
+// 16G. Generations of shorter numbers (edge models such as "XR11") are not
+// derived.
+func ParsePlatform(manufacturer, model, serviceTag string) *Platform {
+	if manufacturer == "" {
+		if !isServiceTag(serviceTag) {
+			return nil
+		}
+	} else if !strings.Contains(strings.ToLower(manufacturer), "dell") {
+		return nil
+	}
+
+	fields := strings.Fields(model)
+	if len(fields) == 0 {
+		return nil
+	}
+	name := fields[len(fields)-1]
+
+	rest := strings.TrimLeftFunc(name, unicode.IsLetter)
+	series := name[:len(name)-len(rest)]
+	// The model number may have a suffix, as in "R750xa" or "MX750c"
+	number := strings.TrimRightFunc(rest, func(r rune) bool { return !unicode.IsDigit(r) })
+	if series == "" || number == "" || strings.IndexFunc(number, func(r rune) bool { return !unicode.IsDigit(r) }) >= 0 {
+		return nil
+	}
+
+	p := &Platform{
+		Family:  defaultFamily,
+		Series:  strings.ToUpper(series),
+		Number:  number,
+		Variant: strings.ToLower(rest[len(number):]),
+	}
+	if len(fields) > 1 {
+		p.Family = strings.Join(fields[:len(fields)-1], " ")
+	}
+	if len(number) == 3 || len(number) == 4 {
+		p.Generation = "1" + string(number[1]) + "G"
+	}
+	return p
+}
+
+// isServiceTag reports whether tag looks like a Dell service tag.
+func isServiceTag(tag string) bool {
+	if len(tag) != 7 {
+		return false
+	}
+	for _, r := range tag {
+		if !unicode.IsDigit(r) && (r < 'A' || r > 'Z') && (r < 'a' || r > 'z') {
+			return false
+		}
+	}
+	return true
+}
+
+// PlatformInfo returns the parsed Dell model of the server: Platform if the
+// scan set it, otherwise parsed from the model, e.g. for results of older
+// versions. Returns nil for other servers.
+func (s ServerInfo) PlatformInfo() *Platform {
+	if s.Platform != nil {
+		return s.Platform
+	}
+	return ParsePlatform(s.Manufacturer, s.Model, s.ServiceTag)
+}
+
+// GenerationGroup lists the servers of one Dell generation for refresh
+// planning.
+type GenerationGroup struct {
+	Generation string         `json:"generation"`
+	Count      int            `json:"count"`
+	Models     map[string]int `json:"models"`
+	Hosts      []string       `json:"hosts"`
+}
+
+// GroupByGeneration groups successfully scanned servers by Dell generation,
+// oldest generation first so the first candidates for a refresh lead.
+// Servers whose generation is not known are grouped under "other", last.
+func GroupByGeneration(servers []ServerInfo) []GenerationGroup {
+	byGen := make(map[string]*GenerationGroup)
+	for _, srv := range servers {
+		if srv.Error != nil {
+			continue
+		}
+		gen := unknownGeneration
+		if p := srv.PlatformInfo(); p != nil && p.Generation != "" {
+			gen = p.Generation
+		}
+		g, ok := byGen[gen]
+		if !ok {
+			g = &GenerationGroup{Generation: gen, Models: make(map[string]int)}
+			byGen[gen] = g
+		}
+		g.Count++
+		g.Models[srv.Model]++
+		g.Hosts = append(g.Hosts, srv.Host)
+	}
+
+	result := make([]GenerationGroup, 0, len(byGen))
+	for _, g := range byGen {
+		sort.Strings(g.Hosts)
+		result = append(result, *g)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		gi, gj := result[i].Generation, result[j].Generation
+		if (gi == unknownGeneration) != (gj == unknownGeneration) {
+			return gi != unknownGeneration
+		}
+		if len(gi) != len(gj) {
+			return len(gi) < len(gj)
+		}
+		return gi < gj
+	})
+	return result
+}
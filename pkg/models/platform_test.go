@@ -0,0 +1,61 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePlatform(t *testing.T) {
+	for model, want := range map[string]*Platform{
+		"PowerEdge R750":   {Family: "PowerEdge", Series: "R", Number: "750", Generation: "15G"},
+		"PowerEdge R750xa": {Family: "PowerEdge", Series: "R", Number: "750", Variant: "xa", Generation: "15G"},
+		"PowerEdge XE9680": {Family: "PowerEdge", Series: "XE", Number: "9680", Generation: "16G"},
+		"PowerEdge MX750c": {Family: "PowerEdge", Series: "MX", Number: "750", Variant: "c", Generation: "15G"},
+		"PowerEdge XR11":   {Family: "PowerEdge", Series: "XR", Number: "11"},
+		"R640":             {Family: "PowerEdge", Series: "R", Number: "640", Generation: "14G"},
+		"PowerEdge":        nil,
+		"PowerEdge 750":    nil,
+		"":                 nil,
+	} {
+		assert.Equal(t, want, ParsePlatform("Dell Inc.", model, "ABC1234"), model)
+	}
+
+	assert.Nil(t, ParsePlatform("HPE", "ProLiant DL380 Gen10", "CZ12345"))
+	assert.Nil(t, ParsePlatform("", "R750", ""), "no manufacturer and no service tag")
+	assert.Nil(t, ParsePlatform("", "R750", "ABC-123"))
+	require.NotNil(t, ParsePlatform("", "R750", "ABC1234"), "Dell service tag")
+}
+
+func TestServerInfo_PlatformInfo(t *testing.T) {
+	srv := ServerInfo{Manufacturer: "Dell Inc.", Model: "PowerEdge R650"}
+	require.NotNil(t, srv.PlatformInfo(), "parsed for results without platform")
+	assert.Equal(t, "15G", srv.PlatformInfo().Generation)
+
+	srv.Platform = &Platform{Family: "PowerEdge", Series: "R", Number: "650", Generation: "15G"}
+	assert.Same(t, srv.Platform, srv.PlatformInfo())
+}
+
+func TestGroupByGeneration(t *testing.T) {
+	groups := GroupByGeneration([]ServerInfo{
+		{Host: "10.0.0.3", Manufacturer: "Dell Inc.", Model: "PowerEdge R750"},
+		{Host: "10.0.0.1", Manufacturer: "Dell Inc.", Model: "PowerEdge R640"},
+		{Host: "10.0.0.2", Manufacturer: "Dell Inc.", Model: "PowerEdge R750"},
+		{Host: "10.0.0.4", Manufacturer: "Dell Inc.", Model: "PowerEdge R6525"},
+		{Host: "10.0.0.5", Manufacturer: "Supermicro", Model: "SYS-620P"},
+		{Host: "10.0.0.6", Error: errors.New("timeout")},
+	})
+
+	require.Len(t, groups, 3)
+	assert.Equal(t, "14G", groups[0].Generation, "oldest first")
+	assert.Equal(t, []string{"10.0.0.1"}, groups[0].Hosts)
+
+	assert.Equal(t, "15G", groups[1].Generation)
+	assert.Equal(t, 3, groups[1].Count)
+	assert.Equal(t, map[string]int{"PowerEdge R750": 2, "PowerEdge R6525": 1}, groups[1].Models)
+	assert.Equal(t, []string{"10.0.0.2", "10.0.0.3", "10.0.0.4"}, groups[1].Hosts)
+
+	assert.Equal(t, "other", groups[2].Generation)
+}
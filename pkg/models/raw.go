@@ -0,0 +1,81 @@
+package models
+
+import "encoding/json"
+
+// Reasons a Redfish response was left out of RawPayloads.
+const (
+	RawOmittedResourceLimit = "resource_limit"
+	RawOmittedHostLimit     = "host_limit"
+)
+
+// RawPayloads are the Redfish responses read while scanning a system, for
+// consumers that need properties the inventory does not model (output.raw).
+type RawPayloads struct {
+	// Resources maps the collector phases to their responses, keyed by
+	// resource path.
+	Resources map[string]map[string]json.RawMessage `json:"resources,omitempty"`
+
+	// Bytes is the total size of Resources.
+	Bytes int `json:"bytes"`
+
+	// Omitted lists the responses left out because of the size limits.
+	Omitted []RawOmission `json:"omitted,omitempty"`
+}
+
+// RawOmission is a Redfish response left out of RawPayloads.
+type RawOmission struct {
+	Phase  string `json:"phase"`
+	Path   string `json:"path"`
+	Bytes  int    `json:"bytes"`
+	Reason string `json:"reason"` // resource_limit or host_limit
+}
+
+// Add stores the response of path under phase. A response read again
+// replaces the earlier one.
+func (r *RawPayloads) Add(phase, path string, body json.RawMessage) {
+	if r.Resources == nil {
+		r.Resources = make(map[string]map[string]json.RawMessage)
+	}
+	if r.Resources[phase] == nil {
+		r.Resources[phase] = make(map[string]json.RawMessage)
+	}
+	r.Bytes += len(body) - len(r.Resources[phase][path])
+	r.Resources[phase][path] = body
+}
+
+// Has reports whether the responses of a phase were stored or left out.
+func (r *RawPayloads) Has(phase string) bool {
+	if _, ok := r.Resources[phase]; ok {
+		return true
+	}
+	for _, o := range r.Omitted {
+		if o.Phase == phase {
+			return true
+		}
+	}
+	return false
+}
+
+// KeepPhases adds the responses and omissions of the phases of prev that r
+// has none of, e.g. to keep those of a full scan when only some phases ran
+// again.
+func (r *RawPayloads) KeepPhases(prev *RawPayloads) {
+	if prev == nil {
+		return
+	}
+	var kept []RawOmission
+	for _, o := range prev.Omitted {
+		if !r.Has(o.Phase) {
+			kept = append(kept, o)
+		}
+	}
+	for phase, resources := range prev.Resources {
+		if r.Has(phase) {
+			continue
+		}
+		for path, body := range resources {
+			r.Add(phase, path, body)
+		}
+	}
+	r.Omitted = append(r.Omitted, kept...)
+}
@@ -0,0 +1,95 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// SchemaVersion is the version of the ServerInfo JSON layout, written as
+// schema_version with every server. Results without it are version 1.
+//
+//  1. Layout before versioning.
+//  2. Model names are normalized (see NormalizeModel) and the reported name
+//     is kept in reported_model.
+const SchemaVersion = 2
+
+// migrations[i] upgrades a serialized ServerInfo from version i+1 to i+2.
+// Migrations work on the JSON object, so they can handle renamed, moved or
+// retyped properties that the current struct no longer decodes.
+var migrations = []func(doc map[string]json.RawMessage) error{
+	migrateModelNames,
+}
+
+// MigrateServerJSON upgrades a serialized ServerInfo to SchemaVersion and
+// returns it along with the version it was written with. Current data is
+// returned unchanged. Data of a newer version is returned unchanged too;
+// properties this version does not know are then ignored when decoding.
+func MigrateServerJSON(data []byte) ([]byte, int, error) {
+	var head struct {
+		SchemaVersion *int `json:"schema_version"`
+	}
+	if err := json.Unmarshal(data, &head); err != nil {
+		return nil, 0, err
+	}
+	version := 1
+	if head.SchemaVersion != nil {
+		version = *head.SchemaVersion
+	}
+	if version >= SchemaVersion {
+		return data, version, nil
+	}
+
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, version, err
+	}
+	for v := max(version, 1); v < SchemaVersion; v++ {
+		if err := migrations[v-1](doc); err != nil {
+			return nil, version, fmt.Errorf("migrating schema version %d to %d: %w", v, v+1, err)
+		}
+	}
+	doc["schema_version"] = json.RawMessage(fmt.Sprint(SchemaVersion))
+	upgraded, err := json.Marshal(doc)
+	return upgraded, version, err
+}
+
+// migrateModelNames normalizes the model name; version 1 stored it as
+// reported by the iDRAC.
+func migrateModelNames(doc map[string]json.RawMessage) error {
+	var model, manufacturer string
+	if err := unmarshalProperty(doc, "model", &model); err != nil {
+		return err
+	}
+	if err := unmarshalProperty(doc, "manufacturer", &manufacturer); err != nil {
+		return err
+	}
+	normalized := NormalizeModel(manufacturer, model)
+	if normalized == model {
+		return nil
+	}
+	return setProperties(doc, map[string]interface{}{"model": normalized, "reported_model": model})
+}
+
+// unmarshalProperty decodes the property key of doc into v, if present.
+func unmarshalProperty(doc map[string]json.RawMessage, key string, v interface{}) error {
+	raw, ok := doc[key]
+	if !ok {
+		return nil
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	return nil
+}
+
+// setProperties encodes values into doc.
+func setProperties(doc map[string]json.RawMessage, values map[string]interface{}) error {
+	for key, v := range values {
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		doc[key] = raw
+	}
+	return nil
+}
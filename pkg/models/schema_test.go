@@ -0,0 +1,52 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServerInfo_SchemaVersion(t *testing.T) {
+	data, err := json.Marshal(ServerInfo{Host: "10.0.0.1", Model: "PowerEdge R750"})
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"schema_version":2`)
+
+	migrated, version, err := MigrateServerJSON(data)
+	require.NoError(t, err)
+	assert.Equal(t, SchemaVersion, version)
+	assert.Equal(t, data, migrated, "current data is not rewritten")
+}
+
+func TestMigrateServerJSON_Version1(t *testing.T) {
+	v1 := []byte(`{"host": "10.0.0.1", "model": "Dell PowerEdge R750 ", "manufacturer": "Dell Inc.", "error": "timeout"}`)
+
+	migrated, version, err := MigrateServerJSON(v1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, version)
+	assert.Contains(t, string(migrated), `"schema_version":2`)
+
+	var info ServerInfo
+	require.NoError(t, json.Unmarshal(v1, &info))
+	assert.Equal(t, "PowerEdge R750", info.Model)
+	assert.Equal(t, "Dell PowerEdge R750 ", info.ReportedModel)
+	assert.Error(t, info.Error, "the error is restored after migrating")
+
+	var servers []ServerInfo
+	require.NoError(t, json.Unmarshal([]byte(`[{"host": "a", "model": "PowerEdge R640"}]`), &servers))
+	assert.Equal(t, "PowerEdge R640", servers[0].Model)
+	assert.Empty(t, servers[0].ReportedModel)
+}
+
+func TestMigrateServerJSON_Newer(t *testing.T) {
+	newer := []byte(`{"host": "10.0.0.1", "model": "Dell PowerEdge R750", "schema_version": 99, "future": true}`)
+
+	migrated, version, err := MigrateServerJSON(newer)
+	require.NoError(t, err)
+	assert.Equal(t, 99, version)
+	assert.Equal(t, newer, migrated)
+
+	_, _, err = MigrateServerJSON([]byte(`{"schema_version": "two"}`))
+	assert.Error(t, err)
+}
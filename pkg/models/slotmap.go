@@ -0,0 +1,174 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// Kinds of slot maps.
+const (
+	SlotKindDriveBays = "drive bays"
+	SlotKindDIMMs     = "DIMM slots"
+)
+
+// SlotMap is the occupancy of a group of slots of a server, e.g. the drive
+// bays of a backplane or the DIMM slots, for slot diagrams.
+type SlotMap struct {
+	Kind  string // SlotKindDriveBays or SlotKindDIMMs
+	Title string
+	Rows  []SlotRow
+
+	// Unplaced is the number of drives an enclosure reports whose bay is
+	// unknown (e.g. in results from older versions).
+	Unplaced int
+}
+
+// SlotRow is a row of a slot diagram, e.g. the DIMM slots of one CPU socket.
+// Label is empty if the map has a single row.
+type SlotRow struct {
+	Label string
+	Slots []Slot
+}
+
+// Slot is one drive bay or DIMM slot. Failed is set for an occupied slot
+// whose part is not healthy or disabled.
+type Slot struct {
+	Label  string
+	Used   bool
+	Failed bool
+}
+
+// Total returns the number of slots of the map.
+func (m SlotMap) Total() int {
+	n := 0
+	for _, r := range m.Rows {
+		n += len(r.Slots)
+	}
+	return n
+}
+
+// Free returns the number of empty slots of the map.
+func (m SlotMap) Free() int {
+	n := 0
+	for _, r := range m.Rows {
+		for _, s := range r.Slots {
+			if !s.Used {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// SlotMaps returns the drive bay maps of the enclosures followed by the DIMM
+// slot map. Enclosures that do not report a slot count are left out.
+func (s ServerInfo) SlotMaps() []SlotMap {
+	maps := s.DriveBayMaps()
+	if m, ok := s.DIMMSlotMap(); ok {
+		maps = append(maps, m)
+	}
+	return maps
+}
+
+// DriveBayMaps returns a map of the bays of each enclosure with a slot
+// count, placing the drives by their Bay and Enclosure.
+func (s ServerInfo) DriveBayMaps() []SlotMap {
+	var maps []SlotMap
+	for _, enc := range s.Enclosures {
+		if enc.SlotCount <= 0 {
+			continue
+		}
+		used := map[int]bool{}
+		failed := map[int]bool{}
+		bays := enc.SlotCount
+		for _, d := range s.Drives {
+			if d.Bay == nil || d.Enclosure != enc.ID {
+				continue
+			}
+			used[*d.Bay] = true
+			failed[*d.Bay] = failed[*d.Bay] || unhealthy(d.Health)
+			if *d.Bay >= bays {
+				bays = *d.Bay + 1
+			}
+		}
+
+		row := SlotRow{}
+		for bay := 0; bay < bays; bay++ {
+			row.Slots = append(row.Slots, Slot{Label: strconv.Itoa(bay), Used: used[bay], Failed: failed[bay]})
+		}
+		name := enc.Model
+		if name == "" {
+			name = enc.Name
+		}
+		m := SlotMap{Kind: SlotKindDriveBays, Title: fmt.Sprintf("Drive bays: %s (%s)", name, enc.ID), Rows: []SlotRow{row}}
+		if unplaced := enc.SlotsUsed - len(used); unplaced > 0 {
+			m.Unplaced = unplaced
+		}
+		maps = append(maps, m)
+	}
+	return maps
+}
+
+// dimmLocator matches the bank and number at the end of a DIMM slot name,
+// e.g. "DIMM A1" or "DIMM.Socket.B12".
+var dimmLocator = regexp.MustCompile(`([A-Za-z]+)(\d+)$`)
+
+// DIMMSlotMap returns a map of the DIMM slots with a row per bank (the
+// letter of Dell slot names like "A1", one bank per CPU socket). Slots whose
+// names have no bank are put in one unlabeled row in their original order.
+// ok is false if the server reports no DIMM slots.
+func (s ServerInfo) DIMMSlotMap() (SlotMap, bool) {
+	if len(s.Memory) == 0 {
+		return SlotMap{}, false
+	}
+
+	type numbered struct {
+		n    int
+		slot Slot
+	}
+	var banks []string
+	byBank := map[string][]numbered{}
+	var other []Slot
+	for _, m := range s.Memory {
+		slot := Slot{
+			Used:   m.State != MemoryStateAbsent,
+			Failed: m.State == MemoryStateDisabled || unhealthy(m.Health),
+		}
+		match := dimmLocator.FindStringSubmatch(m.Slot)
+		if match == nil {
+			slot.Label = m.Slot
+			other = append(other, slot)
+			continue
+		}
+		bank := match[1]
+		n, _ := strconv.Atoi(match[2])
+		slot.Label = match[2]
+		if _, ok := byBank[bank]; !ok {
+			banks = append(banks, bank)
+		}
+		byBank[bank] = append(byBank[bank], numbered{n, slot})
+	}
+
+	m := SlotMap{Kind: SlotKindDIMMs, Title: "DIMM slots"}
+	sort.Strings(banks)
+	for _, bank := range banks {
+		slots := byBank[bank]
+		sort.SliceStable(slots, func(i, j int) bool { return slots[i].n < slots[j].n })
+		row := SlotRow{Label: bank}
+		for _, s := range slots {
+			row.Slots = append(row.Slots, s.slot)
+		}
+		m.Rows = append(m.Rows, row)
+	}
+	if len(other) > 0 {
+		m.Rows = append(m.Rows, SlotRow{Slots: other})
+	}
+	return m, true
+}
+
+// unhealthy reports whether a health status is Warning or Critical.
+func unhealthy(health string) bool {
+	return health == HealthWarning || health == HealthCritical
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDriveBayMaps(t *testing.T) {
+	bay := func(n int) *int { return &n }
+	const enc = "Enclosure.Internal.0-1:RAID.Integrated.1-1"
+	srv := ServerInfo{
+		Enclosures: []EnclosureInfo{
+			{ID: enc, Model: "BP15G+ 8x2.5", SlotCount: 8, SlotsUsed: 4},
+			{ID: "Enclosure.External.0-0:RAID.Slot.1-1", Name: "MD1400", SlotsUsed: 12}, // no slot count
+		},
+		Drives: []DriveInfo{
+			{Name: "Disk 0", Bay: bay(0), Enclosure: enc, Health: HealthOK},
+			{Name: "Disk 1", Bay: bay(1), Enclosure: enc, Health: HealthCritical},
+			{Name: "Disk 5", Bay: bay(5), Enclosure: enc, Health: HealthOK},
+			{Name: "BOSS", Health: HealthOK},
+		},
+	}
+
+	maps := srv.DriveBayMaps()
+	require.Len(t, maps, 1)
+	m := maps[0]
+	assert.Equal(t, SlotKindDriveBays, m.Kind)
+	assert.Equal(t, "Drive bays: BP15G+ 8x2.5 ("+enc+")", m.Title)
+	require.Len(t, m.Rows, 1)
+	require.Len(t, m.Rows[0].Slots, 8)
+	assert.Equal(t, Slot{Label: "0", Used: true}, m.Rows[0].Slots[0])
+	assert.Equal(t, Slot{Label: "1", Used: true, Failed: true}, m.Rows[0].Slots[1])
+	assert.Equal(t, Slot{Label: "2"}, m.Rows[0].Slots[2])
+	assert.True(t, m.Rows[0].Slots[5].Used)
+	assert.Equal(t, 8, m.Total())
+	assert.Equal(t, 5, m.Free())
+	assert.Equal(t, 1, m.Unplaced, "the enclosure reports a fourth drive without a bay")
+}
+
+func TestDIMMSlotMap(t *testing.T) {
+	_, ok := ServerInfo{}.DIMMSlotMap()
+	assert.False(t, ok)
+
+	srv := ServerInfo{Memory: []MemoryInfo{
+		{Slot: "DIMM.Socket.B1", State: MemoryStateEnabled},
+		{Slot: "DIMM.Socket.A10", State: MemoryStateAbsent},
+		{Slot: "DIMM.Socket.A2", State: MemoryStateDisabled},
+		{Slot: "DIMM.Socket.A1", State: MemoryStateEnabled, Health: HealthOK},
+		{Slot: "Onboard", State: MemoryStateEnabled},
+	}}
+	m, ok := srv.DIMMSlotMap()
+	require.True(t, ok)
+	assert.Equal(t, SlotKindDIMMs, m.Kind)
+	require.Len(t, m.Rows, 3)
+
+	assert.Equal(t, "A", m.Rows[0].Label)
+	assert.Equal(t, []Slot{
+		{Label: "1", Used: true},
+		{Label: "2", Used: true, Failed: true},
+		{Label: "10"},
+	}, m.Rows[0].Slots, "slots are sorted by number, not by name")
+	assert.Equal(t, "B", m.Rows[1].Label)
+	assert.Equal(t, []Slot{{Label: "Onboard", Used: true}}, m.Rows[2].Slots)
+	assert.Equal(t, 5, m.Total())
+	assert.Equal(t, 1, m.Free())
+}
@@ -0,0 +1,91 @@
+// Package models defines the core data structures used throughout the application.
+// This file holds the PCIe slot layout, including mezzanine and riser cards.
+package models
+
+import "strings"
+
+// Module kinds of PCIe slots that are modelled as NetBox module bays.
+const (
+	ModuleKindOCPMezzanine = "ocp_mezzanine"
+	ModuleKindRiser        = "riser"
+)
+
+// PCIeSlotInfo describes a PCIe slot and the card installed in it.
+type PCIeSlotInfo struct {
+	Label    string        `json:"label"`
+	SlotType string        `json:"slot_type,omitempty"` // FullLength, HalfLength, OCP3Small, ...
+	PCIeType string        `json:"pcie_type,omitempty"` // Gen3, Gen4, ...
+	Lanes    int           `json:"lanes,omitempty"`
+	Occupied bool          `json:"occupied"`
+	Card     *PCIeCardInfo `json:"card,omitempty"` // nil if empty or not readable
+}
+
+// PCIeCardInfo identifies a card installed in a PCIe slot.
+type PCIeCardInfo struct {
+	Name         string `json:"name"`
+	Manufacturer string `json:"manufacturer,omitempty"`
+	Model        string `json:"model,omitempty"`
+	PartNumber   string `json:"part_number,omitempty"`
+	SerialNumber string `json:"serial_number,omitempty"`
+
+	// DeviceClass is the Redfish PCIe function class (DisplayController,
+	// ProcessingAccelerators, NetworkController, ...), if exposed.
+	DeviceClass string `json:"device_class,omitempty"`
+}
+
+// ModuleKind classifies the slot as an OCP mezzanine or a riser slot (GPU risers
+// included), or returns "" for a plain PCIe slot on the system board.
+func (s PCIeSlotInfo) ModuleKind() string {
+	label := strings.ToLower(s.Label)
+	switch {
+	case strings.HasPrefix(strings.ToLower(s.SlotType), "ocp"),
+		strings.Contains(label, "ocp"),
+		strings.Contains(label, "mezz"):
+		return ModuleKindOCPMezzanine
+	case strings.Contains(label, "riser"):
+		return ModuleKindRiser
+	case s.Card != nil && isAcceleratorCard(*s.Card):
+		return ModuleKindRiser
+	}
+	return ""
+}
+
+// CardModel returns the most specific model name of the installed card.
+func (c PCIeCardInfo) CardModel() string {
+	if c.Model != "" {
+		return c.Model
+	}
+	return c.Name
+}
+
+// isAcceleratorCard reports whether the card is a GPU or accelerator, which Dell
+// servers always mount on a riser. The PCI device class decides if known; the
+// product name is only a fallback for firmware without PCIe functions.
+func isAcceleratorCard(c PCIeCardInfo) bool {
+	switch c.DeviceClass {
+	case "DisplayController", "ProcessingAccelerators", "Coprocessor":
+		return true
+	case "":
+	default:
+		return false
+	}
+
+	text := strings.ToLower(c.Name + " " + c.Model)
+	for _, kw := range []string{"gpu", "accelerator", "tesla", "nvidia a", "nvidia h", "nvidia l", "instinct"} {
+		if strings.Contains(text, kw) {
+			return true
+		}
+	}
+	return false
+}
+
+// ModuleSlots returns the slots that are modelled as NetBox module bays.
+func (s *ServerInfo) ModuleSlots() []PCIeSlotInfo {
+	var result []PCIeSlotInfo
+	for _, slot := range s.PCIeSlots {
+		if slot.ModuleKind() != "" {
+			result = append(result, slot)
+		}
+	}
+	return result
+}
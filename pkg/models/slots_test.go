@@ -0,0 +1,48 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPCIeSlotInfo_ModuleKind(t *testing.T) {
+	assert.Equal(t, ModuleKindOCPMezzanine, PCIeSlotInfo{Label: "OCP 1", SlotType: "OCP3Small"}.ModuleKind())
+	assert.Equal(t, ModuleKindOCPMezzanine, PCIeSlotInfo{Label: "Mezzanine 1A"}.ModuleKind())
+	assert.Equal(t, ModuleKindRiser, PCIeSlotInfo{Label: "Riser 2 Slot 4"}.ModuleKind())
+	assert.Equal(t, ModuleKindRiser, PCIeSlotInfo{
+		Label: "Slot 7",
+		Card:  &PCIeCardInfo{Name: "NVIDIA A100 80GB PCIe"},
+	}.ModuleKind())
+	assert.Empty(t, PCIeSlotInfo{Label: "Slot 1", Card: &PCIeCardInfo{Name: "Broadcom 57414"}}.ModuleKind())
+}
+
+func TestPCIeSlotInfo_ModuleKind_DeviceClass(t *testing.T) {
+	// Localized or unfamiliar names are classified by the PCI device class
+	assert.Equal(t, ModuleKindRiser, PCIeSlotInfo{
+		Label: "Steckplatz 7",
+		Card:  &PCIeCardInfo{Name: "Grafikkarte", DeviceClass: "DisplayController"},
+	}.ModuleKind())
+	assert.Equal(t, ModuleKindRiser, PCIeSlotInfo{
+		Label: "Slot 3",
+		Card:  &PCIeCardInfo{Name: "Beschleuniger", DeviceClass: "ProcessingAccelerators"},
+	}.ModuleKind())
+	// A known class overrides the name heuristic
+	assert.Empty(t, PCIeSlotInfo{
+		Label: "Slot 2",
+		Card:  &PCIeCardInfo{Name: "NVIDIA ConnectX-7 GPU-Direct", DeviceClass: "NetworkController"},
+	}.ModuleKind())
+}
+
+func TestServerInfo_ModuleSlots(t *testing.T) {
+	srv := ServerInfo{PCIeSlots: []PCIeSlotInfo{
+		{Label: "Slot 1"},
+		{Label: "OCP 1", SlotType: "OCP3Small", Occupied: true},
+	}}
+
+	slots := srv.ModuleSlots()
+
+	require.Len(t, slots, 1)
+	assert.Equal(t, "OCP 1", slots[0].Label)
+}
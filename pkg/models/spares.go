@@ -0,0 +1,134 @@
+// Package models defines the core data structures used throughout the application.
+// This file derives a spare-part demand report from the installed component base.
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Spare part categories.
+const (
+	SparePartDIMM  = "DIMM"
+	SparePartDrive = "Drive"
+	SparePartPSU   = "PSU"
+)
+
+// SparePart summarises how often a single part number is installed across the fleet.
+type SparePart struct {
+	Category     string   `json:"category"`
+	PartNumber   string   `json:"part_number"`
+	Manufacturer string   `json:"manufacturer,omitempty"`
+	Description  string   `json:"description"`
+	Quantity     int      `json:"quantity"`
+	ServerCount  int      `json:"server_count"`
+	Models       []string `json:"models"`
+}
+
+// BuildSparePartsReport aggregates DIMM, drive and PSU part numbers across all
+// successfully scanned servers. Parts are sorted by category, then by installed
+// quantity (descending). Components without any part number are skipped.
+func BuildSparePartsReport(servers []ServerInfo) []SparePart {
+	type partKey struct {
+		category   string
+		partNumber string
+	}
+
+	parts := make(map[partKey]*SparePart)
+	hosts := make(map[partKey]map[string]bool)
+	modelSets := make(map[partKey]map[string]bool)
+
+	add := func(srv ServerInfo, category, partNumber, manufacturer, description string) {
+		partNumber = strings.TrimSpace(partNumber)
+		if partNumber == "" {
+			return
+		}
+		k := partKey{category: category, partNumber: partNumber}
+		p, exists := parts[k]
+		if !exists {
+			p = &SparePart{
+				Category:     category,
+				PartNumber:   partNumber,
+				Manufacturer: strings.TrimSpace(manufacturer),
+				Description:  description,
+			}
+			parts[k] = p
+			hosts[k] = make(map[string]bool)
+			modelSets[k] = make(map[string]bool)
+		}
+		p.Quantity++
+		hosts[k][srv.Host] = true
+		if srv.Model != "" {
+			modelSets[k][srv.Model] = true
+		}
+	}
+
+	for _, srv := range servers {
+		if srv.Error != nil {
+			continue
+		}
+
+		for _, mem := range srv.Memory {
+			if !mem.IsPopulated() {
+				continue
+			}
+			desc := fmt.Sprintf("%.0f GiB %s", mem.CapacityGB(), mem.Type)
+			if mem.BaseModuleType != "" {
+				desc += " " + mem.BaseModuleType
+			}
+			if mem.SpeedMHz > 0 {
+				desc += fmt.Sprintf(" @ %d MHz", mem.SpeedMHz)
+			}
+			add(srv, SparePartDIMM, mem.PartNumber, mem.Manufacturer, desc)
+		}
+
+		for _, d := range srv.Drives {
+			// Fall back to the drive model, which identifies the FRU on most firmware.
+			pn := d.PartNumber
+			if strings.TrimSpace(pn) == "" {
+				pn = d.Model
+			}
+			desc := strings.TrimSpace(fmt.Sprintf("%d GB %s %s", d.MarketingGB(), d.MediaType, d.Protocol))
+			add(srv, SparePartDrive, pn, d.Manufacturer, desc)
+		}
+
+		for _, psu := range srv.PowerSupplies {
+			if !psu.IsInstalled() {
+				continue
+			}
+			pn := psu.PartNumber
+			if strings.TrimSpace(pn) == "" {
+				pn = psu.SparePartNumber
+			}
+			desc := psu.Model
+			if psu.CapacityWatts > 0 {
+				desc = strings.TrimSpace(fmt.Sprintf("%s %.0f W", psu.Model, psu.CapacityWatts))
+			}
+			add(srv, SparePartPSU, pn, psu.Manufacturer, desc)
+		}
+	}
+
+	result := make([]SparePart, 0, len(parts))
+	for k, p := range parts {
+		p.ServerCount = len(hosts[k])
+		for m := range modelSets[k] {
+			p.Models = append(p.Models, m)
+		}
+		sort.Strings(p.Models)
+		result = append(result, *p)
+	}
+
+	categoryOrder := map[string]int{SparePartDIMM: 0, SparePartDrive: 1, SparePartPSU: 2}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Category != result[j].Category {
+			return categoryOrder[result[i].Category] < categoryOrder[result[j].Category]
+		}
+		if result[i].Quantity != result[j].Quantity {
+			return result[i].Quantity > result[j].Quantity
+		}
+		return result[i].PartNumber < result[j].PartNumber
+	})
+
+	return result
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildSparePartsReport(t *testing.T) {
+	dimm := MemoryInfo{Slot: "A1", State: MemoryStateEnabled, CapacityMiB: 32768, Type: "DDR4", SpeedMHz: 3200, PartNumber: "M393A4K40DB3-CWE   "}
+	servers := []ServerInfo{
+		{
+			Host:   "10.0.0.1",
+			Model:  "PowerEdge R750",
+			Memory: []MemoryInfo{dimm, dimm, {Slot: "A3", State: MemoryStateAbsent}},
+			Drives: []DriveInfo{{Model: "MZ7LH960", CapacityGB: 894, MediaType: "SSD"}},
+			PowerSupplies: []PowerSupplyInfo{
+				{Name: "PSU1", Model: "PWR SPLY,1400W", PartNumber: "0CMPGM", CapacityWatts: 1400, State: "Enabled"},
+				{Name: "PSU2", State: "Absent"},
+			},
+		},
+		{
+			Host:   "10.0.0.2",
+			Model:  "PowerEdge R650",
+			Memory: []MemoryInfo{dimm},
+		},
+		{
+			Host:   "10.0.0.3",
+			Model:  "PowerEdge R650",
+			Memory: []MemoryInfo{dimm},
+			Error:  errors.New("timeout"),
+		},
+	}
+
+	parts := BuildSparePartsReport(servers)
+
+	require.Len(t, parts, 3)
+
+	assert.Equal(t, SparePartDIMM, parts[0].Category)
+	assert.Equal(t, "M393A4K40DB3-CWE", parts[0].PartNumber)
+	assert.Equal(t, 3, parts[0].Quantity)
+	assert.Equal(t, 2, parts[0].ServerCount)
+	assert.Equal(t, []string{"PowerEdge R650", "PowerEdge R750"}, parts[0].Models)
+
+	assert.Equal(t, SparePartDrive, parts[1].Category)
+	assert.Equal(t, "MZ7LH960", parts[1].PartNumber)
+
+	assert.Equal(t, SparePartPSU, parts[2].Category)
+	assert.Equal(t, "0CMPGM", parts[2].PartNumber)
+	assert.Equal(t, 1, parts[2].Quantity)
+}
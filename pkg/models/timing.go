@@ -0,0 +1,85 @@
+// Package models defines the core data structures used throughout the application.
+// This file ranks hosts by scan duration to identify slow iDRACs.
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+// Collector phases recorded in ServerInfo.PhaseDurations.
+const (
+	PhaseSystem     = "system"
+	PhaseProcessors = "processors"
+	PhaseMemory     = "memory"
+	PhaseStorage    = "storage"
+	PhasePower      = "power"
+	PhasePCIeSlots  = "pcie_slots"
+	PhaseLicense    = "license"
+	PhaseNetwork    = "idrac_network"
+	PhaseClock      = "clock"
+	PhaseAccounts   = "accounts"
+)
+
+// Phases lists the built-in collector phases in scan order. Custom
+// collectors record their phase under their own name.
+var Phases = []string{
+	PhaseSystem, PhaseProcessors, PhaseMemory, PhaseStorage,
+	PhasePower, PhasePCIeSlots, PhaseLicense, PhaseNetwork, PhaseClock, PhaseAccounts,
+}
+
+// DominantPhase returns the collector phase that took the longest for this server.
+// Returns an empty phase if no phase timings were recorded.
+func (s *ServerInfo) DominantPhase() (string, time.Duration) {
+	var phase string
+	var longest time.Duration
+	for p, d := range s.PhaseDurations {
+		if d > longest || (d == longest && p < phase) {
+			phase, longest = p, d
+		}
+	}
+	return phase, longest
+}
+
+// SlowHost is an entry of the slow-host report.
+type SlowHost struct {
+	Host                  string        `json:"host"`
+	Name                  string        `json:"name,omitempty"`
+	Model                 string        `json:"model,omitempty"`
+	Duration              time.Duration `json:"duration"`
+	DominantPhase         string        `json:"dominant_phase,omitempty"`
+	DominantPhaseDuration time.Duration `json:"dominant_phase_duration,omitempty"`
+	Error                 string        `json:"error,omitempty"`
+}
+
+// SlowestHosts returns the n hosts with the longest scan duration, slowest first.
+// Failed hosts are included, since timeouts are usually the slowest scans.
+// If n <= 0 all hosts are returned.
+func SlowestHosts(servers []ServerInfo, n int) []SlowHost {
+	hosts := make([]SlowHost, 0, len(servers))
+	for i := range servers {
+		srv := &servers[i]
+		phase, phaseDur := srv.DominantPhase()
+		h := SlowHost{
+			Host:                  srv.Host,
+			Name:                  srv.Name,
+			Model:                 srv.Model,
+			Duration:              srv.ScanDuration,
+			DominantPhase:         phase,
+			DominantPhaseDuration: phaseDur,
+		}
+		if srv.Error != nil {
+			h.Error = srv.Error.Error()
+		}
+		hosts = append(hosts, h)
+	}
+
+	sort.SliceStable(hosts, func(i, j int) bool {
+		return hosts[i].Duration > hosts[j].Duration
+	})
+
+	if n > 0 && n < len(hosts) {
+		hosts = hosts[:n]
+	}
+	return hosts
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlowestHosts(t *testing.T) {
+	servers := []ServerInfo{
+		{
+			Host:         "10.0.0.1",
+			ScanDuration: 2 * time.Second,
+			PhaseDurations: map[string]time.Duration{
+				PhaseSystem:  200 * time.Millisecond,
+				PhaseStorage: 1500 * time.Millisecond,
+			},
+		},
+		{Host: "10.0.0.2", ScanDuration: 500 * time.Millisecond},
+		{
+			Host:           "10.0.0.3",
+			ScanDuration:   30 * time.Second,
+			PhaseDurations: map[string]time.Duration{PhaseSystem: 30 * time.Second},
+			Error:          errors.New("timeout"),
+		},
+	}
+
+	slow := SlowestHosts(servers, 2)
+
+	require.Len(t, slow, 2)
+	assert.Equal(t, "10.0.0.3", slow[0].Host)
+	assert.Equal(t, PhaseSystem, slow[0].DominantPhase)
+	assert.Equal(t, "timeout", slow[0].Error)
+	assert.Equal(t, "10.0.0.1", slow[1].Host)
+	assert.Equal(t, PhaseStorage, slow[1].DominantPhase)
+	assert.Equal(t, 1500*time.Millisecond, slow[1].DominantPhaseDuration)
+
+	assert.Len(t, SlowestHosts(servers, 0), 3)
+}
@@ -0,0 +1,104 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// TrendSample holds the fleet totals of one scan. The history store keeps
+// one sample per day for the trend section of the Markdown report.
+type TrendSample struct {
+	At         time.Time `json:"at"`
+	Servers    int       `json:"servers"`
+	MemoryGiB  float64   `json:"memory_gib"`
+	PowerWatts int       `json:"power_watts"` // sum of the current draw of the servers reporting it
+
+	// Failed counts the hosts whose scan failed, including those reported
+	// with their last known good inventory (stale).
+	Failed int `json:"failed,omitempty"`
+
+	// FailedDrives identifies the drives in Critical health as
+	// "<service tag or host>/<drive name>#<serial>" (without "#<serial>" if
+	// not reported), so new failures can be told from known ones.
+	FailedDrives []string `json:"failed_drives,omitempty"`
+}
+
+// Add adds a server to the totals. Failed servers are only counted in
+// Failed; stale servers count with their last known good inventory.
+func (s *TrendSample) Add(srv ServerInfo) {
+	if srv.Error != nil {
+		s.Failed++
+		return
+	}
+	if srv.Stale {
+		s.Failed++
+	}
+	s.Servers++
+	s.MemoryGiB += srv.TotalMemoryGiB
+	s.PowerWatts += srv.PowerConsumedWatts
+
+	id := srv.ServiceTag
+	if id == "" {
+		id = srv.Key()
+	}
+	for _, d := range srv.Drives {
+		if d.Health != HealthCritical {
+			continue
+		}
+		key := id + "/" + d.Name
+		if d.SerialNumber != "" {
+			key += "#" + d.SerialNumber
+		}
+		s.FailedDrives = append(s.FailedDrives, key)
+	}
+	sort.Strings(s.FailedDrives)
+}
+
+// TrendSampleOf returns the totals of servers scanned at at.
+func TrendSampleOf(at time.Time, servers []ServerInfo) TrendSample {
+	s := TrendSample{At: at}
+	for _, srv := range servers {
+		s.Add(srv)
+	}
+	return s
+}
+
+// MonthCount is a count per calendar month ("2025-03").
+type MonthCount struct {
+	Month string `json:"month"`
+	Count int    `json:"count"`
+}
+
+// MonthlyDriveFailures counts the drives that turned Critical per month,
+// from the first to the last month of the samples (which must be sorted by
+// time). Drives already failed in the first sample are not counted, as the
+// month they failed in is unknown.
+func MonthlyDriveFailures(samples []TrendSample) []MonthCount {
+	if len(samples) == 0 {
+		return nil
+	}
+
+	counts := make(map[string]int)
+	known := make(map[string]bool)
+	for i, s := range samples {
+		for _, d := range s.FailedDrives {
+			if !known[d] && i > 0 {
+				counts[month(s.At)]++
+			}
+			known[d] = true
+		}
+	}
+
+	var months []MonthCount
+	first, last := samples[0].At.UTC(), samples[len(samples)-1].At.UTC()
+	for t := time.Date(first.Year(), first.Month(), 1, 0, 0, 0, 0, time.UTC); !t.After(last); t = t.AddDate(0, 1, 0) {
+		months = append(months, MonthCount{Month: month(t), Count: counts[month(t)]})
+	}
+	return months
+}
+
+func month(t time.Time) string {
+	t = t.UTC()
+	return fmt.Sprintf("%04d-%02d", t.Year(), t.Month())
+}
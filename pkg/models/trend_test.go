@@ -0,0 +1,46 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrendSampleOf(t *testing.T) {
+	at := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	s := TrendSampleOf(at, []ServerInfo{
+		{ServiceTag: "TAG2", TotalMemoryGiB: 512, PowerConsumedWatts: 400,
+			Drives: []DriveInfo{{Name: "Disk 1", SerialNumber: "S1", Health: HealthCritical}, {Name: "Disk 0", Health: HealthOK}}},
+		{Host: "10.0.0.1", TotalMemoryGiB: 256, PowerConsumedWatts: 300,
+			Drives: []DriveInfo{{Name: "Disk 3", Health: HealthCritical}}},
+		{Host: "10.0.0.9", Error: errors.New("timeout")},
+		{Host: "10.0.0.8", TotalMemoryGiB: 128, Stale: true},
+	})
+
+	assert.Equal(t, at, s.At)
+	assert.Equal(t, 3, s.Servers)
+	assert.Equal(t, 2, s.Failed, "failed and stale hosts")
+	assert.Equal(t, 896.0, s.MemoryGiB)
+	assert.Equal(t, 700, s.PowerWatts)
+	assert.Equal(t, []string{"10.0.0.1/Disk 3", "TAG2/Disk 1#S1"}, s.FailedDrives)
+}
+
+func TestMonthlyDriveFailures(t *testing.T) {
+	day := func(month time.Month, d int) time.Time { return time.Date(2025, month, d, 6, 0, 0, 0, time.UTC) }
+	samples := []TrendSample{
+		{At: day(1, 20), FailedDrives: []string{"TAG1/Disk 0"}},
+		{At: day(1, 21), FailedDrives: []string{"TAG1/Disk 0", "TAG2/Disk 4"}},
+		{At: day(2, 5)},
+		{At: day(4, 2), FailedDrives: []string{"TAG1/Disk 0", "TAG3/Disk 1#S9", "TAG3/Disk 2#S7"}},
+	}
+
+	assert.Equal(t, []MonthCount{
+		{Month: "2025-01", Count: 1},
+		{Month: "2025-02", Count: 0},
+		{Month: "2025-03", Count: 0},
+		{Month: "2025-04", Count: 2},
+	}, MonthlyDriveFailures(samples), "failures of the first sample are not new")
+	assert.Nil(t, MonthlyDriveFailures(nil))
+}
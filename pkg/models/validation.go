@@ -0,0 +1,102 @@
+// Package models defines the core data structures used throughout the application.
+// This file holds the result of a connection validation run (-validate).
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ConnectionCheck is the result of validating connectivity to a single iDRAC.
+type ConnectionCheck struct {
+	Host string `json:"host"`
+	Name string `json:"name,omitempty"`
+
+	// Error is nil if the Redfish service root was reachable with the configured credentials.
+	Error error `json:"-"`
+
+	RedfishVersion  string `json:"redfish_version,omitempty"`
+	FirmwareVersion string `json:"firmware_version,omitempty"`
+
+	// Latency is the round-trip time of the service root request.
+	Latency time.Duration `json:"latency"`
+
+	// TLS certificate presented by the iDRAC (nil if not available).
+	Certificate *CertificateInfo `json:"certificate,omitempty"`
+
+	// Clock drift of the iDRAC (nil if the manager reports no DateTime).
+	Clock *ClockInfo `json:"clock,omitempty"`
+}
+
+// OK returns true if the connection was validated successfully.
+func (c ConnectionCheck) OK() bool {
+	return c.Error == nil
+}
+
+// CertDaysLeft returns the number of days until the certificate expires
+// (negative if already expired). The second value is false if no certificate is known.
+func (c ConnectionCheck) CertDaysLeft(now time.Time) (int, bool) {
+	if c.Certificate == nil {
+		return 0, false
+	}
+	return c.Certificate.DaysLeft(now), true
+}
+
+// MarshalJSON implements custom JSON marshaling to include the error message
+// and code.
+func (c ConnectionCheck) MarshalJSON() ([]byte, error) {
+	type Alias ConnectionCheck
+	aux := struct {
+		Alias
+		ErrorMessage string `json:"error,omitempty"`
+		ErrorCode    string `json:"error_code,omitempty"`
+	}{
+		Alias: Alias(c),
+	}
+	if c.Error != nil {
+		aux.ErrorMessage = c.Error.Error()
+		aux.ErrorCode = errorCode(c.Error)
+	}
+	return json.Marshal(aux)
+}
+
+// PowerStateCheck is the power state of a server as queried by the
+// power-state command, from the service root and the System resource only.
+type PowerStateCheck struct {
+	Host string `json:"host"`
+	Name string `json:"name,omitempty"`
+
+	// Error is nil if the System resource could be read.
+	Error error `json:"-"`
+
+	PowerState string `json:"power_state,omitempty"` // On, Off, PoweringOn, PoweringOff
+	Health     string `json:"health,omitempty"`
+	Model      string `json:"model,omitempty"`
+	ServiceTag string `json:"service_tag,omitempty"`
+
+	// Latency is the time taken by both requests.
+	Latency time.Duration `json:"latency"`
+}
+
+// OK returns true if the power state was read successfully.
+func (c PowerStateCheck) OK() bool {
+	return c.Error == nil
+}
+
+// MarshalJSON implements custom JSON marshaling to include the error message
+// and code.
+func (c PowerStateCheck) MarshalJSON() ([]byte, error) {
+	type Alias PowerStateCheck
+	aux := struct {
+		Alias
+		ErrorMessage string `json:"error,omitempty"`
+		ErrorCode    string `json:"error_code,omitempty"`
+	}{
+		Alias: Alias(c),
+	}
+	if c.Error != nil {
+		aux.ErrorMessage = c.Error.Error()
+		aux.ErrorCode = errorCode(c.Error)
+	}
+	return json.Marshal(aux)
+}
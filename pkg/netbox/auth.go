@@ -0,0 +1,302 @@
+package netbox
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/config"
+	"github.com/braunma/idrac-netbox-importer/pkg/defaults"
+	"github.com/braunma/idrac-netbox-importer/pkg/redact"
+)
+
+// tokenRefreshMargin is how long before its expiry an OAuth2 access token is
+// replaced, at most half of its lifetime.
+const tokenRefreshMargin = time.Minute
+
+// maxTokenResponseSize limits the token endpoint response that is read.
+const maxTokenResponseSize = 1 << 20
+
+// oauth2Source fetches the access tokens of an OAuth2 client credentials
+// grant and caches them until shortly before they expire.
+type oauth2Source struct {
+	cfg        config.OAuth2Config
+	httpClient *http.Client
+	now        func() time.Time
+
+	mu        sync.Mutex
+	token     string
+	refreshAt time.Time // zero if the token endpoint did not return expires_in
+}
+
+// newOAuth2Source returns a token source for the grant. The token endpoint
+// is usually not NetBox itself, so it is trusted by the system roots and
+// the NetBox CA certificate, if any, and is not subject to read-only mode
+// (fetching a token changes nothing).
+func newOAuth2Source(cfg config.OAuth2Config, caCert string, timeout time.Duration) *oauth2Source {
+	tlsConfig := &tls.Config{}
+	if caCert != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if pool.AppendCertsFromPEM([]byte(caCert)) {
+			tlsConfig.RootCAs = pool
+		}
+	}
+	return &oauth2Source{
+		cfg: cfg,
+		httpClient: &http.Client{
+			Timeout:   timeout,
+			Transport: &http.Transport{TLSClientConfig: tlsConfig},
+		},
+		now: time.Now,
+	}
+}
+
+// tokenResponse is the token endpoint response (RFC 6749 sections 5.1 and 5.2).
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	TokenType        string `json:"token_type"`
+	ExpiresIn        int    `json:"expires_in"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// Token returns the cached access token, fetching a new one if there is none
+// or it is about to expire.
+func (s *oauth2Source) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && (s.refreshAt.IsZero() || s.now().Before(s.refreshAt)) {
+		return s.token, nil
+	}
+
+	tok, err := s.fetch(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get OAuth2 access token: %w", err)
+	}
+	s.token = tok.AccessToken
+	s.refreshAt = time.Time{}
+	if tok.ExpiresIn > 0 {
+		lifetime := time.Duration(tok.ExpiresIn) * time.Second
+		margin := tokenRefreshMargin
+		if margin > lifetime/2 {
+			margin = lifetime / 2
+		}
+		s.refreshAt = s.now().Add(lifetime - margin)
+	}
+	return s.token, nil
+}
+
+// invalidate drops the access token if it is still the cached one, so the
+// next request fetches a new one. Used when a token is rejected before its
+// expiry, e.g. after it was revoked.
+func (s *oauth2Source) invalidate(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token == token {
+		s.token = ""
+	}
+}
+
+func (s *oauth2Source) fetch(ctx context.Context) (*tokenResponse, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(s.cfg.Scopes) > 0 {
+		form.Set("scope", strings.Join(s.cfg.Scopes, " "))
+	}
+	if s.cfg.Audience != "" {
+		form.Set("audience", s.cfg.Audience)
+	}
+	if s.cfg.GetAuthStyle() == config.OAuth2AuthStyleParams {
+		form.Set("client_id", s.cfg.ClientID)
+		form.Set("client_secret", s.cfg.ClientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.cfg.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if s.cfg.GetAuthStyle() == config.OAuth2AuthStyleHeader {
+		req.SetBasicAuth(url.QueryEscape(s.cfg.ClientID), url.QueryEscape(s.cfg.ClientSecret))
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxTokenResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var tok tokenResponse
+	jsonErr := json.Unmarshal(body, &tok)
+	if resp.StatusCode != http.StatusOK {
+		if jsonErr == nil && tok.Error != "" {
+			return nil, fmt.Errorf("token endpoint error %d: %s %s", resp.StatusCode, tok.Error, redact.String(tok.ErrorDescription))
+		}
+		return nil, fmt.Errorf("token endpoint error %d: %s", resp.StatusCode, redact.String(string(body)))
+	}
+	if jsonErr != nil {
+		return nil, fmt.Errorf("failed to parse token response: %w", jsonErr)
+	}
+	if tok.AccessToken == "" {
+		return nil, fmt.Errorf("token response has no access_token")
+	}
+	if tok.TokenType != "" && !strings.EqualFold(tok.TokenType, "bearer") {
+		return nil, fmt.Errorf("unsupported token type %q", tok.TokenType)
+	}
+	return &tok, nil
+}
+
+// commandToken runs the netbox.token_command to get the API token and caches
+// the token until NetBox rejects it.
+type commandToken struct {
+	command string
+	timeout time.Duration
+
+	mu    sync.Mutex
+	token string
+	// rejected is the last token dropped after NetBox rejected it. If the
+	// command prints it again, the token is valid and further rejections are
+	// about permissions, so they do not run the command again.
+	rejected string
+}
+
+func newCommandToken(command string) *commandToken {
+	return &commandToken{command: command, timeout: defaults.DefaultNetBoxTokenCommandTimeout}
+}
+
+// Token returns the cached token, running the command if there is none.
+func (s *commandToken) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" {
+		return s.token, nil
+	}
+	tok, err := s.run(ctx)
+	if err != nil {
+		return "", fmt.Errorf("netbox token_command failed: %w", err)
+	}
+	redact.AddSecrets(tok)
+	s.token = tok
+	return s.token, nil
+}
+
+// invalidate drops the token if it is still the cached one, so the next
+// request runs the command again, and reports whether it did or another
+// request already had. A token is dropped at most once.
+func (s *commandToken) invalidate(token string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if token == s.rejected {
+		return false
+	}
+	s.rejected = token
+	if s.token == token {
+		s.token = ""
+	}
+	return true
+}
+
+// run runs the command in a shell and returns its trimmed standard output.
+// Standard error is only used in errors.
+func (s *commandToken) run(ctx context.Context) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", s.command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", s.command)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if ctx.Err() == context.DeadlineExceeded {
+		return "", fmt.Errorf("timed out after %s", s.timeout)
+	}
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, redact.String(msg))
+		}
+		return "", err
+	}
+	tok := strings.TrimSpace(string(out))
+	if tok == "" {
+		return "", fmt.Errorf("no token printed")
+	}
+	if strings.ContainsAny(tok, "\r\n") {
+		return "", fmt.Errorf("printed more than one line")
+	}
+	return tok, nil
+}
+
+// authorize sets the authentication headers of a NetBox request and returns
+// the token that may be rejected and replaced: the OAuth2 access token or the
+// token of the token_command ("" for a static token).
+func (c *Client) authorize(ctx context.Context, req *http.Request) (string, error) {
+	if c.tokenCommand != nil {
+		tok, err := c.tokenCommand.Token(ctx)
+		if err != nil {
+			return "", err
+		}
+		req.Header.Set("Authorization", "Token "+tok)
+		return tok, nil
+	}
+	if c.oauth2 == nil {
+		if c.token != "" {
+			req.Header.Set("Authorization", "Token "+c.token)
+		}
+		return "", nil
+	}
+
+	tok, err := c.oauth2.Token(ctx)
+	if err != nil {
+		return "", err
+	}
+	header := c.oauth2.cfg.GetHeader()
+	if !strings.EqualFold(header, "Authorization") && c.token != "" {
+		req.Header.Set("Authorization", "Token "+c.token)
+	}
+	req.Header.Set(header, "Bearer "+tok)
+	return tok, nil
+}
+
+// rejected drops a token NetBox answered the status for, if the status means
+// the token is no longer valid, and reports whether to retry the request with
+// a new one. A token of the token_command may be answered with 403, which
+// NetBox returns for unknown and expired tokens but also for missing
+// permissions, so the command runs again at most once per token.
+func (c *Client) rejected(token string, status int) bool {
+	switch {
+	case token == "":
+		return false
+	case c.tokenCommand != nil && (status == http.StatusUnauthorized || status == http.StatusForbidden):
+		return c.tokenCommand.invalidate(token)
+	case c.oauth2 != nil && status == http.StatusUnauthorized:
+		c.oauth2.invalidate(token)
+		return true
+	}
+	return false
+}
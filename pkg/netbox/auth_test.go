@@ -0,0 +1,246 @@
+package netbox
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/braunma/idrac-netbox-importer/internal/testutil"
+	"github.com/braunma/idrac-netbox-importer/pkg/config"
+)
+
+// mockTokenServer issues access tokens at-1, at-2, ... for the client
+// credentials grant of client "inventory" with secret "s3cret".
+func mockTokenServer(t *testing.T, expiresIn int) (*httptest.Server, *int) {
+	issued := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		id, secret, ok := r.BasicAuth()
+		if !ok {
+			id, secret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+		}
+		if r.PostForm.Get("grant_type") != "client_credentials" || id != "inventory" || secret != "s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_client", "error_description": "bad credentials"})
+			return
+		}
+		assert.Equal(t, "netbox.read netbox.write", r.PostForm.Get("scope"))
+		issued++
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": fmt.Sprintf("at-%d", issued),
+			"token_type":   "Bearer",
+			"expires_in":   expiresIn,
+		})
+	}))
+	return server, &issued
+}
+
+// mockStatusServer answers the NetBox status endpoint if check accepts the
+// request and with 401 otherwise.
+func mockStatusServer(check func(r *http.Request) bool) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !check(r) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"django-version": "4.2"})
+	}))
+}
+
+func oauth2Config(netboxURL, tokenURL string) config.NetBoxConfig {
+	return config.NetBoxConfig{
+		URL: netboxURL,
+		Auth: config.NetBoxAuthConfig{
+			Method: config.NetBoxAuthOAuth2,
+			OAuth2: config.OAuth2Config{
+				TokenURL:     tokenURL,
+				ClientID:     "inventory",
+				ClientSecret: "s3cret",
+				Scopes:       []string{"netbox.read", "netbox.write"},
+			},
+		},
+	}
+}
+
+func TestClient_OAuth2(t *testing.T) {
+	tokens, issued := mockTokenServer(t, 3600)
+	defer tokens.Close()
+
+	revoked := map[string]bool{}
+	var seen []string
+	netbox := mockStatusServer(func(r *http.Request) bool {
+		auth := r.Header.Get("Authorization")
+		seen = append(seen, auth)
+		token, ok := strings.CutPrefix(auth, "Bearer ")
+		return ok && !revoked[token]
+	})
+	defer netbox.Close()
+
+	client := NewClient(oauth2Config(netbox.URL, tokens.URL))
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	client.oauth2.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	require.NoError(t, client.TestConnection(ctx))
+	require.NoError(t, client.TestConnection(ctx))
+	assert.Equal(t, 1, *issued, "the access token is cached")
+	assert.Equal(t, []string{"Bearer at-1", "Bearer at-1"}, seen)
+
+	now = now.Add(59*time.Minute + time.Second)
+	require.NoError(t, client.TestConnection(ctx))
+	assert.Equal(t, 2, *issued, "refreshed a minute before it expires")
+
+	revoked["at-2"] = true
+	require.NoError(t, client.TestConnection(ctx))
+	assert.Equal(t, 3, *issued, "fetched again when rejected")
+	assert.Equal(t, []string{"Bearer at-2", "Bearer at-3"}, seen[len(seen)-2:])
+}
+
+func TestClient_OAuth2_ProxyHeader(t *testing.T) {
+	tokens, _ := mockTokenServer(t, 0)
+	defer tokens.Close()
+
+	netbox := mockStatusServer(func(r *http.Request) bool {
+		return r.Header.Get("X-Forwarded-Access-Token") == "Bearer at-1" &&
+			r.Header.Get("Authorization") == "Token test-token"
+	})
+	defer netbox.Close()
+
+	cfg := oauth2Config(netbox.URL, tokens.URL)
+	cfg.Token = "test-token"
+	cfg.Auth.OAuth2.Header = "X-Forwarded-Access-Token"
+	cfg.Auth.OAuth2.AuthStyle = config.OAuth2AuthStyleParams
+	assert.NoError(t, NewClient(cfg).TestConnection(context.Background()))
+}
+
+func TestClient_OAuth2_TokenError(t *testing.T) {
+	tokens, _ := mockTokenServer(t, 3600)
+	defer tokens.Close()
+	netbox := mockStatusServer(func(r *http.Request) bool { return true })
+	defer netbox.Close()
+
+	cfg := oauth2Config(netbox.URL, tokens.URL)
+	cfg.Auth.OAuth2.ClientSecret = "wrong"
+	err := NewClient(cfg).TestConnection(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "OAuth2 access token")
+	assert.Contains(t, err.Error(), "invalid_client bad credentials")
+}
+
+// tokenCommand returns a command printing tok-1, tok-2, ... on each run.
+func tokenCommand(t *testing.T) string {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	counter := filepath.Join(t.TempDir(), "runs")
+	return fmt.Sprintf(`n=$(($(cat %[1]s 2>/dev/null || echo 0) + 1)); echo $n > %[1]s; echo tok-$n`, counter)
+}
+
+func TestClient_TokenCommand(t *testing.T) {
+	expired := map[string]bool{}
+	var seen []string
+	netbox := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		seen = append(seen, auth)
+		if token, _ := strings.CutPrefix(auth, "Token "); expired[token] {
+			// NetBox answers expired tokens with 403
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"django-version": "4.2"})
+	}))
+	defer netbox.Close()
+
+	client := NewClient(config.NetBoxConfig{URL: netbox.URL, TokenCommand: tokenCommand(t)})
+	ctx := context.Background()
+
+	require.NoError(t, client.TestConnection(ctx))
+	require.NoError(t, client.TestConnection(ctx))
+	assert.Equal(t, []string{"Token tok-1", "Token tok-1"}, seen, "the token is cached")
+
+	expired["tok-1"] = true
+	require.NoError(t, client.TestConnection(ctx))
+	assert.Equal(t, []string{"Token tok-1", "Token tok-2"}, seen[2:], "the command runs again when rejected")
+
+	expired["tok-2"] = true
+	expired["tok-3"] = true
+	err := client.TestConnection(ctx)
+	require.Error(t, err, "retried once")
+	assert.Len(t, seen, 6)
+}
+
+func TestClient_TokenCommandForbidden(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	var seen []string
+	netbox := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = append(seen, r.Header.Get("Authorization"))
+		// A valid token without the permission
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer netbox.Close()
+
+	runs := filepath.Join(t.TempDir(), "runs")
+	command := fmt.Sprintf(`echo run >> %s; echo tok`, runs)
+	client := NewClient(config.NetBoxConfig{URL: netbox.URL, TokenCommand: command})
+	for i := 0; i < 3; i++ {
+		assert.Error(t, client.TestConnection(context.Background()))
+	}
+
+	data, err := os.ReadFile(runs)
+	require.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(string(data), "run"), "the command runs again once for the rejected token")
+	assert.Len(t, seen, 4, "only the first request is retried")
+}
+
+func TestClient_TokenCommandErrors(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	netbox := mockStatusServer(func(r *http.Request) bool { return true })
+	defer netbox.Close()
+
+	for command, want := range map[string]string{
+		"echo denied >&2; exit 2": "exit status 2: denied",
+		"true":                    "no token printed",
+		"printf 'a\\nb\\n'":       "more than one line",
+	} {
+		err := NewClient(config.NetBoxConfig{URL: netbox.URL, TokenCommand: command}).TestConnection(context.Background())
+		require.Error(t, err, command)
+		assert.Contains(t, err.Error(), "token_command failed", command)
+		assert.Contains(t, err.Error(), want, command)
+	}
+}
+
+func TestClient_MTLS(t *testing.T) {
+	certFile, keyFile, cert := testutil.WriteClientCert(t)
+
+	netbox := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"django-version": "4.2"})
+	}))
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	netbox.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: pool}
+	netbox.StartTLS()
+	defer netbox.Close()
+
+	cfg := config.NetBoxConfig{URL: netbox.URL, InsecureSkipVerify: true}
+	assert.Error(t, NewClient(cfg).TestConnection(context.Background()), "no client certificate")
+
+	cfg.Auth = config.NetBoxAuthConfig{Method: config.NetBoxAuthMTLS, ClientCert: certFile, ClientKey: keyFile}
+	assert.NoError(t, NewClient(cfg).TestConnection(context.Background()))
+}
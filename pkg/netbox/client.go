@@ -0,0 +1,1055 @@
+// Package netbox provides a client for syncing hardware inventory to NetBox.
+package netbox
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/braunma/idrac-netbox-importer/internal/readonly"
+	"github.com/braunma/idrac-netbox-importer/pkg/audit"
+	"github.com/braunma/idrac-netbox-importer/pkg/catalog"
+	"github.com/braunma/idrac-netbox-importer/pkg/config"
+	"github.com/braunma/idrac-netbox-importer/pkg/defaults"
+	"github.com/braunma/idrac-netbox-importer/pkg/errors"
+	"github.com/braunma/idrac-netbox-importer/pkg/logging"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+	"github.com/braunma/idrac-netbox-importer/pkg/units"
+)
+
+// Client provides methods for interacting with the NetBox API.
+type Client struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+	logger     *zap.SugaredLogger
+	fieldNames FieldNames
+	headers    config.HTTPConfig // User-Agent and extra headers
+	audit      *audit.Logger     // nil if auditing is disabled
+	readOnly   bool
+
+	// syncModules enables module bay / module creation (netbox.sync_modules)
+	syncModules bool
+
+	// powerDraw controls where measured power draw is written (netbox.power_draw)
+	powerDraw config.PowerDrawConfig
+
+	// syncDeviceTypes fills device type attributes from catalog (netbox.sync_device_types)
+	syncDeviceTypes bool
+	catalog         *catalog.Catalog
+
+	// journal adds a journal entry per synced device (netbox.journal)
+	journal bool
+
+	// syncComments writes annotation notes to the device comments (netbox.sync_comments)
+	syncComments bool
+
+	// syncManufactureDate writes the manufacture date (netbox.sync_manufacture_date)
+	syncManufactureDate bool
+
+	// syncFirmwareCompliance writes the firmware policy result (netbox.sync_firmware_compliance)
+	syncFirmwareCompliance bool
+
+	// syncIDRACLicense writes the iDRAC license level (netbox.sync_idrac_license)
+	syncIDRACLicense bool
+
+	// syncLastScanID writes the run ID (netbox.sync_last_scan_id)
+	syncLastScanID bool
+
+	// syncIDRACDNSName writes the iDRAC DNS name (netbox.sync_idrac_dns_name)
+	syncIDRACDNSName bool
+
+	// labelTags tags the devices with their labels (netbox.sync_label_tags)
+	labelTags *labelTagCache
+
+	// units converts memory and storage sizes for custom fields (units)
+	units units.Format
+
+	// plan records writes instead of sending them (see Plan)
+	plan *SyncPlan
+
+	// oauth2 provides the access tokens of the oauth2 auth method (netbox.auth)
+	oauth2 *oauth2Source
+
+	// tokenCommand provides the API token printed by netbox.token_command
+	tokenCommand *commandToken
+
+	// devices caches the device IDs of asset tags and serials (netbox.device_cache)
+	devices *deviceCache
+
+	// bulk matches servers against one device listing (netbox.match, nil to search)
+	bulk *bulkMatch
+
+	// graphql holds the device lookups made with the GraphQL API (netbox.graphql)
+	graphql *graphqlLookup
+}
+
+// FieldNames holds the configurable NetBox custom field names.
+type FieldNames struct {
+	CPUCount            string
+	CPUModel            string
+	CPUCores            string
+	RAMTotalGB          string
+	RAMSlotsTotal       string
+	RAMSlotsUsed        string
+	RAMSlotsAvailable   string
+	RAMType             string
+	RAMSpeedMHz         string
+	DiskCount           string
+	StorageSummary      string
+	StorageTotalTB      string
+	BIOSVersion         string
+	PowerState          string
+	PowerConsumedWatts  string
+	PowerPeakWatts      string
+	LastInventory       string
+	// GPU / Accelerator fields ("Beschleuniger" in German iDRAC)
+	GPUCount    string
+	GPUModel    string
+	GPUMemoryGB string
+	// iDRAC license level (netbox.sync_idrac_license)
+	IDRACLicense string
+	// DNS name of the iDRAC (netbox.sync_idrac_dns_name)
+	IDRACDNSName string
+	// ID of the run that last synced the device (netbox.sync_last_scan_id)
+	LastScanID string
+	// Manufacture date of the server (netbox.sync_manufacture_date)
+	ManufactureDate string
+	// Firmware policy compliance (netbox.sync_firmware_compliance)
+	FirmwareCompliant string
+}
+
+// DefaultFieldNames returns the default field names from the defaults package.
+func DefaultFieldNames() FieldNames {
+	return FieldNames{
+		CPUCount:           defaults.NetBoxFieldCPUCount,
+		CPUModel:           defaults.NetBoxFieldCPUModel,
+		CPUCores:           defaults.NetBoxFieldCPUCores,
+		RAMTotalGB:         defaults.NetBoxFieldRAMTotalGB,
+		RAMSlotsTotal:      defaults.NetBoxFieldRAMSlotsTotal,
+		RAMSlotsUsed:       defaults.NetBoxFieldRAMSlotsUsed,
+		RAMSlotsAvailable:  defaults.NetBoxFieldRAMSlotsAvailable,
+		RAMType:            defaults.NetBoxFieldRAMType,
+		RAMSpeedMHz:        defaults.NetBoxFieldRAMSpeedMHz,
+		DiskCount:          defaults.NetBoxFieldDiskCount,
+		StorageSummary:     defaults.NetBoxFieldStorageSummary,
+		StorageTotalTB:     defaults.NetBoxFieldStorageTotalTB,
+		BIOSVersion:        defaults.NetBoxFieldBIOSVersion,
+		PowerState:         defaults.NetBoxFieldPowerState,
+		PowerConsumedWatts: defaults.NetBoxFieldPowerConsumedWatts,
+		PowerPeakWatts:     defaults.NetBoxFieldPowerPeakWatts,
+		LastInventory:      defaults.NetBoxFieldLastInventory,
+		GPUCount:           defaults.NetBoxFieldGPUCount,
+		GPUModel:           defaults.NetBoxFieldGPUModel,
+		GPUMemoryGB:        defaults.NetBoxFieldGPUMemoryGB,
+		IDRACLicense:       defaults.NetBoxFieldIDRACLicense,
+		IDRACDNSName:       defaults.NetBoxFieldIDRACDNSName,
+		LastScanID:         defaults.NetBoxFieldLastScanID,
+		ManufactureDate:    defaults.NetBoxFieldManufactureDate,
+		FirmwareCompliant:  defaults.NetBoxFieldFirmwareCompliant,
+	}
+}
+
+// ClientOption is a function that configures a Client.
+type ClientOption func(*Client)
+
+// WithFieldNames sets custom field names for the NetBox client.
+func WithFieldNames(names FieldNames) ClientOption {
+	return func(c *Client) {
+		c.fieldNames = names
+	}
+}
+
+// WithHTTPClient sets a custom HTTP client.
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithHTTPHeaders sets the User-Agent and extra headers sent with every request.
+func WithHTTPHeaders(h config.HTTPConfig) ClientOption {
+	return func(c *Client) {
+		c.headers = h
+	}
+}
+
+// WithAuditLog records all mutating requests in the given audit log.
+func WithAuditLog(l *audit.Logger) ClientOption {
+	return func(c *Client) {
+		c.audit = l
+	}
+}
+
+// WithReadOnly refuses all mutating requests at the transport if enabled.
+func WithReadOnly(enabled bool) ClientOption {
+	return func(c *Client) {
+		c.readOnly = enabled
+	}
+}
+
+// WithCatalog sets the model catalog used for device types (default: the
+// embedded catalog).
+func WithCatalog(cat *catalog.Catalog) ClientOption {
+	return func(c *Client) {
+		c.catalog = cat
+	}
+}
+
+// WithUnits sets the unit system of the memory and storage custom fields
+// (default: binary, 2 decimals).
+func WithUnits(u units.Format) ClientOption {
+	return func(c *Client) {
+		c.units = u
+	}
+}
+
+// NewClient creates a new NetBox API client.
+func NewClient(cfg config.NetBoxConfig, opts ...ClientOption) *Client {
+	// Build TLS config
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+
+	// Add custom CA certificate if provided
+	if cfg.CACert != "" {
+		certPool := x509.NewCertPool()
+		if ok := certPool.AppendCertsFromPEM([]byte(cfg.CACert)); !ok {
+			logging.Warn("Failed to parse CA certificate, using system cert pool")
+		} else {
+			tlsConfig.RootCAs = certPool
+			logging.Debug("Custom CA certificate loaded for NetBox connection")
+		}
+	}
+
+	// Add the client certificate for mTLS if provided
+	if cfg.Auth.ClientCert != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.Auth.ClientCert, cfg.Auth.ClientKey)
+		if err != nil {
+			logging.Warn("Failed to load NetBox client certificate", "error", err)
+		} else {
+			tlsConfig.Certificates = []tls.Certificate{cert}
+			logging.Debug("Client certificate loaded for NetBox connection")
+		}
+	}
+
+	c := &Client{
+		baseURL: cfg.URL,
+		token:   cfg.Token,
+		httpClient: &http.Client{
+			Timeout: cfg.Timeout(),
+			Transport: &http.Transport{
+				TLSClientConfig: tlsConfig,
+				MaxIdleConns:    defaults.DefaultHTTPMaxIdleConns,
+				IdleConnTimeout: defaults.GetHTTPIdleConnTimeout(),
+			},
+		},
+		logger:                 logging.WithComponent("netbox"),
+		fieldNames:             DefaultFieldNames(),
+		syncModules:            cfg.SyncModules,
+		powerDraw:              cfg.PowerDraw,
+		syncDeviceTypes:        cfg.SyncDeviceTypes,
+		journal:                cfg.Journal,
+		syncComments:           cfg.SyncComments,
+		syncManufactureDate:    cfg.SyncManufactureDate,
+		syncFirmwareCompliance: cfg.SyncFirmwareCompliance,
+		syncIDRACLicense:       cfg.SyncIDRACLicense,
+		syncLastScanID:         cfg.SyncLastScanID,
+		syncIDRACDNSName:       cfg.SyncIDRACDNSName,
+		units:                  units.Default(),
+	}
+
+	if cfg.Auth.GetMethod() == config.NetBoxAuthOAuth2 {
+		c.oauth2 = newOAuth2Source(cfg.Auth.OAuth2, cfg.CACert, cfg.Timeout())
+	}
+	if cfg.SyncLabelTags {
+		c.labelTags = newLabelTagCache()
+	}
+	if cfg.TokenCommand != "" {
+		c.tokenCommand = newCommandToken(cfg.TokenCommand)
+	}
+
+	if cfg.GraphQL.Enabled {
+		c.graphql = newGraphQLLookup(cfg.GraphQL.GetBatchSize())
+	}
+	if cfg.Match.GetStrategy() == config.NetBoxMatchBulk {
+		query, _ := cfg.Match.Query()
+		c.bulk = &bulkMatch{query: query}
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.catalog == nil {
+		c.catalog = catalog.Default()
+	}
+
+	if c.readOnly {
+		hc := *c.httpClient
+		hc.Transport = readonly.Transport(hc.Transport)
+		c.httpClient = &hc
+	}
+
+	return c
+}
+
+// Device represents a NetBox device.
+type Device struct {
+	ID           int                    `json:"id"`
+	URL          string                 `json:"url"`
+	Name         string                 `json:"name"`
+	Serial       string                 `json:"serial"`
+	AssetTag     string                 `json:"asset_tag"`
+	DeviceType   *objectRef             `json:"device_type"`
+	Site         *objectRef             `json:"site"`
+	Rack         *objectRef             `json:"rack"`
+	Position     *float64               `json:"position"`
+	Face         *choice                `json:"face"`
+	Tenant       *objectRef             `json:"tenant"`
+	OOBIP        *ipRef                 `json:"oob_ip"`
+	PrimaryIP    *ipRef                 `json:"primary_ip"`
+	PrimaryIP4   *ipRef                 `json:"primary_ip4"`
+	Role         *namedRef              `json:"role"`
+	DeviceRole   *namedRef              `json:"device_role"` // NetBox < 4.0
+	Tags         []namedRef             `json:"tags"`
+	Comments     string                 `json:"comments"`
+	CustomFields map[string]interface{} `json:"custom_fields"`
+}
+
+// DeviceList represents a paginated list of devices.
+type DeviceList struct {
+	Count    int      `json:"count"`
+	Next     string   `json:"next"`
+	Previous string   `json:"previous"`
+	Results  []Device `json:"results"`
+}
+
+// request performs an HTTP request to the NetBox API.
+// Mutating requests are recorded in the audit log, if configured, or only in
+// the plan while planning a sync.
+func (c *Client) request(ctx context.Context, method, path string, body interface{}, target interface{}) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("failed to marshal request body: %w", err)
+		}
+	}
+
+	if c.plan != nil && method != http.MethodGet {
+		c.plan.record(method, path)
+		return nil
+	}
+
+	status, err := c.do(ctx, method, path, payload, target)
+
+	if method != http.MethodGet {
+		entry := audit.Entry{
+			System:        "netbox",
+			Action:        method,
+			Target:        c.baseURL + path,
+			PayloadSHA256: audit.Digest(payload),
+			StatusCode:    status,
+		}
+		if err != nil {
+			entry.Error = err.Error()
+		}
+		if auditErr := c.audit.Record(entry); auditErr != nil {
+			c.logger.Errorw("failed to write audit log",
+				"method", method,
+				"path", path,
+				"error", auditErr,
+			)
+		}
+	}
+
+	return err
+}
+
+// do sends a request with an optional JSON payload and decodes the response
+// into target. It returns the HTTP status code (0 if no response was received).
+func (c *Client) do(ctx context.Context, method, path string, payload []byte, target interface{}) (int, error) {
+	fullURL := c.baseURL + path
+
+	c.logger.Debugw("performing API request",
+		"method", method,
+		"path", path,
+	)
+
+	startTime := time.Now()
+	resp, accessToken, err := c.send(ctx, method, fullURL, payload)
+	if err == nil && c.rejected(accessToken, resp.StatusCode) {
+		// The token may have been revoked or expired early
+		resp.Body.Close()
+		resp, _, err = c.send(ctx, method, fullURL, payload)
+	}
+	if err != nil {
+		c.logger.Errorw("API request failed",
+			"method", method,
+			"path", path,
+			"error", err,
+		)
+		return 0, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	duration := time.Since(startTime)
+
+	c.logger.Debugw("API request completed",
+		"method", method,
+		"path", path,
+		"status_code", resp.StatusCode,
+		"duration", duration,
+	)
+
+	// Read response body
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	// Check for errors
+	if resp.StatusCode >= 400 {
+		c.logger.Errorw("API error response",
+			"method", method,
+			"path", path,
+			"status_code", resp.StatusCode,
+			"body", string(respBody),
+		)
+		return resp.StatusCode, errors.NewNetBoxError(resp.StatusCode, string(respBody))
+	}
+
+	// Decode response if target provided
+	if target != nil && len(respBody) > 0 {
+		if err := json.Unmarshal(respBody, target); err != nil {
+			return resp.StatusCode, fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+
+	return resp.StatusCode, nil
+}
+
+// send performs a single API request and returns the replaceable token it
+// was authorized with (see authorize).
+func (c *Client) send(ctx context.Context, method, fullURL string, payload []byte) (*http.Response, string, error) {
+	var reqBody io.Reader
+	if payload != nil {
+		reqBody = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, fullURL, reqBody)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	c.headers.ApplyHeaders(req.Header)
+	accessToken, err := c.authorize(ctx, req)
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	return resp, accessToken, err
+}
+
+// FindDeviceBySerial searches for a device by its serial number.
+func (c *Client) FindDeviceBySerial(ctx context.Context, serial string) (*Device, error) {
+	c.logger.Debugw("searching for device by serial",
+		"serial", serial,
+	)
+
+	path := fmt.Sprintf("%s?serial=%s", defaults.NetBoxDevicesPath, url.QueryEscape(serial))
+
+	var result DeviceList
+	if err := c.request(ctx, http.MethodGet, path, nil, &result); err != nil {
+		return nil, err
+	}
+
+	if result.Count == 0 {
+		c.logger.Debugw("device not found",
+			"serial", serial,
+		)
+		return nil, nil
+	}
+
+	c.logger.Debugw("device found",
+		"serial", serial,
+		"device_id", result.Results[0].ID,
+		"device_name", result.Results[0].Name,
+	)
+
+	return &result.Results[0], nil
+}
+
+// FindDeviceByServiceTag searches for a device by its Dell service tag (asset tag).
+func (c *Client) FindDeviceByServiceTag(ctx context.Context, serviceTag string) (*Device, error) {
+	c.logger.Debugw("searching for device by service tag",
+		"service_tag", serviceTag,
+	)
+
+	// Try asset_tag first (common for service tags)
+	path := fmt.Sprintf("%s?asset_tag=%s", defaults.NetBoxDevicesPath, url.QueryEscape(serviceTag))
+
+	var result DeviceList
+	if err := c.request(ctx, http.MethodGet, path, nil, &result); err != nil {
+		return nil, err
+	}
+
+	if result.Count > 0 {
+		return &result.Results[0], nil
+	}
+
+	// Fall back to serial number search
+	return c.FindDeviceBySerial(ctx, serviceTag)
+}
+
+// UpdateDeviceCustomFields updates the custom fields of a device.
+func (c *Client) UpdateDeviceCustomFields(ctx context.Context, deviceID int, fields map[string]interface{}) error {
+	c.logger.Debugw("updating device custom fields",
+		"device_id", deviceID,
+		"field_count", len(fields),
+	)
+
+	path := fmt.Sprintf("%s%d/", defaults.NetBoxDevicesPath, deviceID)
+	body := map[string]interface{}{
+		"custom_fields": fields,
+	}
+
+	if err := c.request(ctx, http.MethodPatch, path, body, nil); err != nil {
+		return fmt.Errorf("failed to update device %d: %w", deviceID, err)
+	}
+
+	c.logger.Infow("device custom fields updated",
+		"device_id", deviceID,
+	)
+
+	return nil
+}
+
+// SyncServerInfo syncs a server's hardware information to NetBox.
+func (c *Client) SyncServerInfo(ctx context.Context, info models.ServerInfo) error {
+	c.logger.Infow("syncing server info to NetBox",
+		"host", info.Host,
+		"service_tag", info.ServiceTag,
+		"serial", info.SerialNumber,
+	)
+
+	// Find device using consolidated lookup logic
+	device, err := c.findDevice(ctx, info)
+	if err != nil {
+		return err
+	}
+
+	if device == nil {
+		c.logger.Warnw("device not found in NetBox",
+			"host", info.Host,
+			"service_tag", info.ServiceTag,
+			"serial", info.SerialNumber,
+			"error_code", errors.CodeNetBoxNotFound,
+		)
+		return fmt.Errorf("%w (service_tag=%s, serial=%s)",
+			errors.ErrDeviceNotFound, info.ServiceTag, info.SerialNumber)
+	}
+
+	// Build custom fields payload
+	fields := c.buildCustomFields(info)
+	var changes []fieldChange
+	if c.journal {
+		changes = c.changedFields(device.CustomFields, fields)
+	}
+
+	// Update the device. While planning, an update of only the fields that
+	// change on every sync is no change.
+	if c.plan == nil || c.hardwareChanged(device.CustomFields, fields) {
+		if err := c.UpdateDeviceCustomFields(ctx, device.ID, fields); err != nil {
+			if c.devices != nil && errors.CodeOf(err) == errors.CodeNetBoxNotFound {
+				// Deleted since it was cached; the next run searches again
+				c.devices.invalidate(device.ID)
+			}
+			return err
+		}
+	}
+
+	// Model mezzanine and riser cards as modules, if enabled
+	if c.syncModules {
+		if err := c.SyncModules(ctx, device.ID, info); err != nil {
+			return fmt.Errorf("module sync failed: %w", err)
+		}
+	}
+
+	// Apply the placement from the placement file, if listed
+	if info.Placement != nil {
+		if err := c.SyncPlacement(ctx, device, *info.Placement); err != nil {
+			return fmt.Errorf("placement sync failed: %w", err)
+		}
+	}
+
+	// Write the notes from the annotations file to the comments, if enabled
+	if c.syncComments {
+		if err := c.SyncComments(ctx, device, info.Notes); err != nil {
+			return fmt.Errorf("comments sync failed: %w", err)
+		}
+	}
+
+	// Tag the device with the labels of the server, if enabled
+	if c.labelTags != nil {
+		if err := c.SyncLabelTags(ctx, device, info.Labels); err != nil {
+			return fmt.Errorf("label tags sync failed: %w", err)
+		}
+	}
+
+	// Complete the device type from the model catalog, if enabled
+	if c.syncDeviceTypes {
+		if err := c.syncDeviceType(ctx, device, info); err != nil {
+			return fmt.Errorf("device type sync failed: %w", err)
+		}
+	}
+
+	// Record the measured draw on the device power ports, if enabled
+	if c.powerDraw.Mode == config.PowerDrawAllocated {
+		if err := c.SyncAllocatedDraw(ctx, device.ID, info); err != nil {
+			return fmt.Errorf("power draw sync failed: %w", err)
+		}
+	}
+
+	// Record the scan in the device journal, if enabled
+	if c.journal {
+		if err := c.createJournalEntry(ctx, device.ID, info, changes); err != nil {
+			return fmt.Errorf("journal entry failed: %w", err)
+		}
+	}
+
+	c.logger.Infow("server info synced to NetBox",
+		"host", info.Host,
+		"device_id", device.ID,
+		"device_name", device.Name,
+	)
+
+	return nil
+}
+
+// buildCustomFields creates the custom fields map for a server.
+// Uses configurable field names from the defaults package.
+func (c *Client) buildCustomFields(info models.ServerInfo) map[string]interface{} {
+	fields := map[string]interface{}{
+		c.fieldNames.CPUCount:         info.CPUCount,
+		c.fieldNames.CPUModel:         info.CPUModel,
+		c.fieldNames.RAMTotalGB:       c.units.WholeGB(info.TotalMemoryGiB),
+		c.fieldNames.RAMSlotsTotal:    info.MemorySlotsTotal,
+		c.fieldNames.RAMSlotsUsed:     info.MemorySlotsUsed,
+		c.fieldNames.RAMSlotsAvailable: info.MemorySlotsFree,
+		c.fieldNames.StorageTotalTB:   c.units.TerabyteValue(info.TotalStorageTB),
+		c.fieldNames.BIOSVersion:    info.BiosVersion,
+		c.fieldNames.PowerState:     info.PowerState,
+		c.fieldNames.LastInventory:  info.CollectedAt.Format(time.RFC3339),
+	}
+
+	// Add CPU cores if available
+	if len(info.CPUs) > 0 {
+		fields[c.fieldNames.CPUCores] = info.CPUs[0].Cores
+	}
+
+	// Add memory details if available
+	if len(info.Memory) > 0 {
+		// Get memory type and speed from first populated module
+		for _, mem := range info.Memory {
+			if mem.IsPopulated() {
+				fields[c.fieldNames.RAMType] = mem.Type
+				fields[c.fieldNames.RAMSpeedMHz] = mem.SpeedMHz
+				break
+			}
+		}
+	}
+
+	// Add storage information
+	fields[c.fieldNames.DiskCount] = info.DriveCount
+	if len(info.Drives) > 0 {
+		fields[c.fieldNames.StorageSummary] = c.buildStorageSummary(info.Drives)
+	}
+
+	// Add power consumption data if available
+	if info.PowerConsumedWatts > 0 {
+		fields[c.fieldNames.PowerConsumedWatts] = info.PowerConsumedWatts
+	}
+	if info.PowerPeakWatts > 0 {
+		fields[c.fieldNames.PowerPeakWatts] = info.PowerPeakWatts
+	}
+
+	// Add GPU/accelerator data ("Beschleuniger" in German iDRAC)
+	fields[c.fieldNames.GPUCount] = info.GPUCount
+	if len(info.GPUs) > 0 {
+		fields[c.fieldNames.GPUModel] = c.buildGPUSummary(info.GPUs)
+		totalVRAM := 0
+		for _, gpu := range info.GPUs {
+			totalVRAM += gpu.MemoryMiB
+		}
+		if totalVRAM > 0 {
+			fields[c.fieldNames.GPUMemoryGB] = int(totalVRAM / 1024)
+		}
+	}
+
+	// Add iDRAC license level if detected
+	if c.syncIDRACLicense && info.LicenseLevel != "" {
+		fields[c.fieldNames.IDRACLicense] = info.LicenseLevel
+	}
+
+	// Add the iDRAC DNS name, to audit the out-of-band network
+	if c.syncIDRACDNSName && info.IDRACNetwork != nil && info.IDRACNetwork.DNSName() != "" {
+		fields[c.fieldNames.IDRACDNSName] = info.IDRACNetwork.DNSName()
+	}
+
+	// Add the run ID, to correlate the update with logs and outputs of the run
+	if c.syncLastScanID && info.RunID != "" {
+		fields[c.fieldNames.LastScanID] = info.RunID
+	}
+
+	// Add the manufacture date, for the age of the server
+	if c.syncManufactureDate && info.ManufactureDate != nil {
+		fields[c.fieldNames.ManufactureDate] = info.ManufactureDate.Format("2006-01-02")
+	}
+
+	// Add the firmware policy result
+	if c.syncFirmwareCompliance && info.FirmwareCompliance != nil {
+		fields[c.fieldNames.FirmwareCompliant] = info.FirmwareCompliance.Compliant()
+	}
+
+	// Keep the NetBox values of components that failed in a partial scan
+	for phase := range info.ComponentErrors {
+		for _, name := range c.componentFields(phase) {
+			delete(fields, name)
+		}
+	}
+
+	return fields
+}
+
+// componentFields returns the custom fields filled from a collector phase.
+func (c *Client) componentFields(phase string) []string {
+	f := c.fieldNames
+	switch phase {
+	case models.PhaseSystem:
+		return []string{f.BIOSVersion, f.PowerState}
+	case models.PhaseProcessors:
+		return []string{f.CPUCount, f.CPUModel, f.CPUCores, f.GPUCount, f.GPUModel, f.GPUMemoryGB}
+	case models.PhaseMemory:
+		return []string{f.RAMTotalGB, f.RAMSlotsTotal, f.RAMSlotsUsed, f.RAMSlotsAvailable, f.RAMType, f.RAMSpeedMHz}
+	case models.PhaseStorage:
+		return []string{f.StorageTotalTB, f.DiskCount, f.StorageSummary}
+	}
+	return nil
+}
+
+// buildGPUSummary returns a compact summary of installed GPUs.
+// Example: "4× NVIDIA A100 (80 GB)" or "2× NVIDIA H100, 2× NVIDIA A30"
+func (c *Client) buildGPUSummary(gpus []models.GPUInfo) string {
+	type gpuKey struct {
+		model    string
+		memoryGB int
+	}
+	counts := make(map[gpuKey]int)
+	var order []gpuKey
+
+	for _, g := range gpus {
+		k := gpuKey{model: g.Model, memoryGB: int(g.MemoryGB())}
+		if counts[k] == 0 {
+			order = append(order, k)
+		}
+		counts[k]++
+	}
+
+	parts := make([]string, 0, len(order))
+	for _, k := range order {
+		entry := fmt.Sprintf("%d× %s", counts[k], k.model)
+		if k.memoryGB > 0 {
+			entry += fmt.Sprintf(" (%d GB)", k.memoryGB)
+		}
+		parts = append(parts, entry)
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// buildStorageSummary creates a grouped summary of drives by marketing
+// capacity, independent of the configured units.
+// Example output: "2x960GB, 16x16000GB"
+func (c *Client) buildStorageSummary(drives []models.DriveInfo) string {
+	capacityGroups := make(map[int]int)
+	for _, drive := range drives {
+		capacityGroups[drive.MarketingGB()]++
+	}
+
+	// Sort capacities for consistent output.
+	capacities := make([]int, 0, len(capacityGroups))
+	for capacity := range capacityGroups {
+		capacities = append(capacities, capacity)
+	}
+	sort.Ints(capacities)
+
+	summary := make([]string, 0, len(capacities))
+	for _, capacity := range capacities {
+		summary = append(summary, fmt.Sprintf("%dx%dGB", capacityGroups[capacity], capacity))
+	}
+
+	return strings.Join(summary, ", ")
+}
+
+// findDevice searches for a device in NetBox using service tag and serial number.
+// It tries service tag first (which includes fallback to serial), then tries
+// serial number directly if service tag is empty. With netbox.device_cache
+// enabled, a cached device ID is fetched directly instead; with the bulk
+// match strategy, the server is matched against one device listing.
+func (c *Client) findDevice(ctx context.Context, info models.ServerInfo) (*Device, error) {
+	if c.bulk != nil {
+		return c.matchDevice(ctx, info)
+	}
+	if c.devices == nil {
+		return c.searchDevice(ctx, info)
+	}
+
+	device, err := c.cachedDevice(ctx, info)
+	if err != nil || device != nil {
+		return device, err
+	}
+	device, err = c.searchDevice(ctx, info)
+	if device != nil {
+		c.devices.remember(device)
+	}
+	return device, err
+}
+
+// searchDevice searches NetBox for the device of a server by service tag,
+// then by serial number, with the GraphQL API if enabled.
+func (c *Client) searchDevice(ctx context.Context, info models.ServerInfo) (*Device, error) {
+	if c.graphql != nil {
+		return c.queryDevice(ctx, info)
+	}
+
+	c.logger.Infow("searching for device in NetBox",
+		"host", info.Host,
+		"service_tag", info.ServiceTag,
+		"serial_number", info.SerialNumber,
+	)
+
+	// Try service tag first (includes serial fallback internally)
+	if info.ServiceTag != "" {
+		c.logger.Debugw("attempting lookup by service tag",
+			"service_tag", info.ServiceTag,
+		)
+		device, err := c.FindDeviceByServiceTag(ctx, info.ServiceTag)
+		if err != nil || device != nil {
+			if device != nil {
+				c.logger.Infow("device found by service tag",
+					"service_tag", info.ServiceTag,
+					"device_id", device.ID,
+					"device_name", device.Name,
+				)
+			}
+			return device, err
+		}
+	}
+
+	// Try serial number directly if service tag wasn't provided or didn't match
+	if info.SerialNumber != "" {
+		c.logger.Debugw("attempting lookup by serial number",
+			"serial_number", info.SerialNumber,
+		)
+		device, err := c.FindDeviceBySerial(ctx, info.SerialNumber)
+		if device != nil {
+			c.logger.Infow("device found by serial number",
+				"serial_number", info.SerialNumber,
+				"device_id", device.ID,
+				"device_name", device.Name,
+			)
+		}
+		return device, err
+	}
+
+	c.logger.Warnw("no service tag or serial number available for device lookup",
+		"host", info.Host,
+	)
+	return nil, nil
+}
+
+// TestConnection verifies connectivity to the NetBox API.
+func (c *Client) TestConnection(ctx context.Context) error {
+	c.logger.Debug("testing connection to NetBox")
+
+	var status struct {
+		DjangoVersion string   `json:"django-version"`
+		InstalledApps struct{} `json:"installed-apps"`
+	}
+
+	if err := c.request(ctx, http.MethodGet, defaults.NetBoxStatusPath, nil, &status); err != nil {
+		return fmt.Errorf("connection test failed: %w", err)
+	}
+
+	c.logger.Infow("connection test successful",
+		"django_version", status.DjangoVersion,
+	)
+
+	return nil
+}
+
+// SyncResult contains the result of syncing a single server.
+type SyncResult struct {
+	Host    string
+	Success bool
+	Error   error
+}
+
+// SyncAll syncs all provided server information to NetBox.
+// Hosts sharing a service tag, serial number or drive serial with another host
+// only update a device that already exists in NetBox (see syncDuplicate).
+func (c *Client) SyncAll(ctx context.Context, servers []models.ServerInfo) []SyncResult {
+	c.logger.Infow("syncing all servers to NetBox",
+		"count", len(servers),
+	)
+
+	results := make([]SyncResult, 0, len(servers))
+	duplicates := models.DuplicateHosts(models.FindDuplicates(servers))
+	c.prefetchDevices(ctx, servers)
+
+	synced := make(map[int]string)
+	for _, info := range servers {
+		var result SyncResult
+		if dup, ok := duplicates[info.Host]; ok && info.IsValid() {
+			result = c.syncDuplicate(ctx, info, dup, synced)
+		} else {
+			result = c.syncOne(ctx, info)
+		}
+		results = append(results, result)
+	}
+
+	c.finishSync(ctx, results, servers)
+	return results
+}
+
+// SyncStream syncs servers to NetBox as they arrive on the channel, until it
+// is closed. Duplicates are detected incrementally: the first host reporting
+// a serial is synced, later hosts reporting the same serial are synced like
+// duplicates in SyncAll.
+func (c *Client) SyncStream(ctx context.Context, servers <-chan models.ServerInfo) []SyncResult {
+	c.logger.Infow("syncing servers to NetBox as they are scanned")
+
+	var results []SyncResult
+	var synced []models.ServerInfo
+	tracker := models.NewDuplicateTracker()
+	devices := make(map[int]string)
+
+	for info := range servers {
+		var result SyncResult
+		if dup, ok := tracker.Check(info); ok {
+			result = c.syncDuplicate(ctx, info, dup, devices)
+		} else {
+			result = c.syncOne(ctx, info)
+		}
+		results = append(results, result)
+
+		// Only feed_field mode needs the synced servers after the loop
+		if result.Success && c.powerDraw.Mode == config.PowerDrawFeedField {
+			synced = append(synced, info)
+		}
+	}
+
+	c.finishSync(ctx, results, synced)
+	return results
+}
+
+// syncOne syncs a single host, skipping hosts whose collection failed.
+func (c *Client) syncOne(ctx context.Context, info models.ServerInfo) SyncResult {
+	result := SyncResult{Host: info.Host}
+
+	if !info.IsValid() {
+		result.Error = fmt.Errorf("skipped: collection failed with error: %w", info.Error)
+		return result
+	}
+
+	if err := c.SyncServerInfo(ctx, info); err != nil {
+		c.logger.Warnw("failed to sync server",
+			"host", info.Host,
+			"error", err,
+			"error_code", errors.CodeOf(err),
+		)
+		result.Error = err
+	} else {
+		result.Success = true
+	}
+	return result
+}
+
+// syncDuplicate syncs a host sharing a serial with another host. It updates
+// the device the host matches in NetBox, unless another host of the run
+// already updated that device (synced maps device IDs to hosts); a host
+// without a matching device is skipped instead of being reported missing.
+func (c *Client) syncDuplicate(ctx context.Context, info models.ServerInfo, dup models.Duplicate, synced map[int]string) SyncResult {
+	device, err := c.findDevice(ctx, info)
+	if err != nil {
+		return SyncResult{Host: info.Host, Error: err}
+	}
+	if device == nil {
+		return c.skipDuplicate(info, dup, "no matching device")
+	}
+	if other, ok := synced[device.ID]; ok {
+		return c.skipDuplicate(info, dup, fmt.Sprintf("device %s already synced from %s", device.Name, other))
+	}
+	synced[device.ID] = info.Host
+	return c.syncOne(ctx, info)
+}
+
+// skipDuplicate returns the result for a host skipped because of a duplicate serial.
+func (c *Client) skipDuplicate(info models.ServerInfo, dup models.Duplicate, reason string) SyncResult {
+	c.logger.Warnw("skipping host with duplicate serial",
+		"host", info.Host,
+		"kind", dup.Kind,
+		"value", dup.Value,
+		"hosts", dup.Hosts,
+		"reason", reason,
+	)
+	return SyncResult{Host: info.Host, Error: fmt.Errorf("skipped: %s: %s", dup, reason)}
+}
+
+// finishSync runs the per-run power feed update and logs the summary.
+// servers may contain unsynced hosts; only successful ones are used.
+func (c *Client) finishSync(ctx context.Context, results []SyncResult, servers []models.ServerInfo) {
+	successCount := 0
+	succeeded := make(map[string]bool, len(results))
+	for _, r := range results {
+		if r.Success {
+			successCount++
+			succeeded[r.Host] = true
+		}
+	}
+
+	// Power feeds aggregate several servers, so they are updated once per run
+	if c.powerDraw.Mode == config.PowerDrawFeedField {
+		synced := make([]models.ServerInfo, 0, successCount)
+		for _, info := range servers {
+			if succeeded[info.Host] {
+				synced = append(synced, info)
+			}
+		}
+		if err := c.syncFeedDraws(ctx, synced); err != nil {
+			c.logger.Errorw("power feed draw sync failed", "error", err)
+		}
+	}
+	c.saveDeviceCache()
+
+	c.logger.Infow("sync completed",
+		"total", len(results),
+		"successful", successCount,
+		"failed", len(results)-successCount,
+	)
+}
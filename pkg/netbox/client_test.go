@@ -5,14 +5,21 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-	"idrac-inventory/internal/config"
-	"idrac-inventory/internal/models"
-	"idrac-inventory/pkg/logging"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/audit"
+	"github.com/braunma/idrac-netbox-importer/pkg/config"
+	"github.com/braunma/idrac-netbox-importer/pkg/errors"
+	"github.com/braunma/idrac-netbox-importer/pkg/logging"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+	"github.com/braunma/idrac-netbox-importer/pkg/units"
 )
 
 func init() {
@@ -180,14 +187,14 @@ func TestClient_SyncServerInfo(t *testing.T) {
 			{CapacityMiB: 32768, Type: "DDR5", SpeedMHz: 4800, State: models.MemoryStateEnabled},
 		},
 		Drives: []models.DriveInfo{
-			{CapacityGB: 960},
-			{CapacityGB: 960},
-			{CapacityGB: 960},
-			{CapacityGB: 960},
-			{CapacityGB: 1920},
-			{CapacityGB: 1920},
-			{CapacityGB: 1920},
-			{CapacityGB: 1920},
+			{CapacityGB: 894.25},
+			{CapacityGB: 894.25},
+			{CapacityGB: 894.25},
+			{CapacityGB: 894.25},
+			{CapacityGB: 1788.5},
+			{CapacityGB: 1788.5},
+			{CapacityGB: 1788.5},
+			{CapacityGB: 1788.5},
 		},
 	}
 
@@ -208,6 +215,63 @@ func TestClient_SyncServerInfo(t *testing.T) {
 	assert.Equal(t, float64(24), patchedFields["hw_cpu_cores"])
 }
 
+// syncedFields syncs info with cfg and returns the custom fields of the
+// device PATCH.
+func syncedFields(t *testing.T, cfg config.NetBoxConfig, info models.ServerInfo) map[string]interface{} {
+	t.Helper()
+	var patched map[string]interface{}
+	server := mockNetBoxServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Query().Get("asset_tag") == info.ServiceTag:
+			json.NewEncoder(w).Encode(DeviceList{Count: 1, Results: []Device{{ID: 42}}})
+		case r.Method == http.MethodPatch && r.URL.Path == "/api/dcim/devices/42/":
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			patched = body["custom_fields"].(map[string]interface{})
+		default:
+			json.NewEncoder(w).Encode(DeviceList{})
+		}
+	})
+	defer server.Close()
+
+	cfg.URL, cfg.Token = server.URL, "test-token"
+	require.NoError(t, NewClient(cfg).SyncServerInfo(context.Background(), info))
+	require.NotNil(t, patched)
+	return patched
+}
+
+// Fields added after the initial set are opt-in: NetBox rejects a PATCH
+// naming a custom field it does not know, so existing installs would fail
+// until the field is created.
+func TestClient_SyncServerInfo_OptInFields(t *testing.T) {
+	info := models.ServerInfo{
+		Host:         "192.168.1.10",
+		ServiceTag:   "SVCTAG01",
+		CPUCount:     2,
+		LicenseLevel: models.LicenseLevelEnterprise,
+		RunID:        "5f0c6a52-1d0e-4c36-9a57-0d8f3b5e2a41",
+		IDRACNetwork: &models.IDRACNetworkInfo{HostName: "idrac-abc1234", FQDN: "idrac-abc1234.oob.example.com"},
+	}
+	tests := []struct {
+		field  string
+		enable func(*config.NetBoxConfig)
+		want   interface{}
+	}{
+		{"hw_idrac_license", func(c *config.NetBoxConfig) { c.SyncIDRACLicense = true }, "Enterprise"},
+		{"hw_last_scan_id", func(c *config.NetBoxConfig) { c.SyncLastScanID = true }, "5f0c6a52-1d0e-4c36-9a57-0d8f3b5e2a41"},
+		{"hw_idrac_dns_name", func(c *config.NetBoxConfig) { c.SyncIDRACDNSName = true }, "idrac-abc1234.oob.example.com"},
+	}
+	for _, tt := range tests {
+		fields := syncedFields(t, config.NetBoxConfig{}, info)
+		assert.NotContains(t, fields, tt.field, "only synced when enabled")
+		assert.Equal(t, float64(2), fields["hw_cpu_count"])
+
+		var cfg config.NetBoxConfig
+		tt.enable(&cfg)
+		assert.Equal(t, tt.want, syncedFields(t, cfg, info)[tt.field])
+	}
+}
+
 func TestClient_SyncServerInfo_DeviceNotFound(t *testing.T) {
 	server := mockNetBoxServer(t, func(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(DeviceList{Count: 0, Results: []Device{}})
@@ -289,6 +353,63 @@ func TestClient_SyncAll(t *testing.T) {
 	assert.Contains(t, results[2].Error.Error(), "skipped")
 }
 
+func TestClient_SyncAll_Duplicates(t *testing.T) {
+	var patched []string
+	devices := map[string]Device{
+		"SVCTAG01": {ID: 1, Name: "server-1"},
+		"SVCTAG03": {ID: 3, Name: "server-3"},
+		"SVCTAG04": {ID: 4, Name: "server-4"},
+	}
+
+	server := mockNetBoxServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPatch {
+			patched = append(patched, r.URL.Path)
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		var list DeviceList
+		if d, ok := devices[r.URL.Query().Get("asset_tag")]; ok {
+			list = DeviceList{Count: 1, Results: []Device{d}}
+		}
+		json.NewEncoder(w).Encode(list)
+	})
+	defer server.Close()
+
+	client := NewClient(config.NetBoxConfig{
+		URL:   server.URL,
+		Token: "test-token",
+	})
+
+	drives := []models.DriveInfo{{SerialNumber: "DRIVE01"}}
+	servers := []models.ServerInfo{
+		{Host: "host1", ServiceTag: "SVCTAG01"},
+		{Host: "host2", ServiceTag: "SVCTAG01"},
+		{Host: "host3", ServiceTag: "SVCTAG02"},
+		{Host: "host4", ServiceTag: "SVCTAG02"},
+		{Host: "host5", ServiceTag: "SVCTAG03", Drives: drives},
+		{Host: "host6", ServiceTag: "SVCTAG04", Drives: drives},
+	}
+
+	results := client.SyncAll(context.Background(), servers)
+
+	require.Len(t, results, 6)
+	assert.True(t, results[0].Success, "the first host updates the shared device")
+	assert.False(t, results[1].Success)
+	assert.Contains(t, results[1].Error.Error(), "already synced from host1")
+	for _, r := range results[2:4] {
+		assert.False(t, r.Success, "no device is created for a duplicate")
+		assert.Contains(t, r.Error.Error(), "duplicate service_tag")
+		assert.NotErrorIs(t, r.Error, errors.ErrDeviceNotFound)
+	}
+	assert.True(t, results[4].Success, "a duplicate drive serial still updates the matched device")
+	assert.True(t, results[5].Success)
+	assert.Equal(t, []string{"/api/dcim/devices/1/", "/api/dcim/devices/3/", "/api/dcim/devices/4/"}, patched)
+
+	// The plan counts the same device updates
+	plan := client.Plan(context.Background(), servers)
+	assert.Equal(t, []string{"host1", "host5", "host6"}, plan.Modified)
+}
+
 func TestClient_TestConnection(t *testing.T) {
 	server := mockNetBoxServer(t, func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path == "/api/status/" {
@@ -312,6 +433,80 @@ func TestClient_TestConnection(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestClient_SendsConfiguredHeaders(t *testing.T) {
+	var userAgent, ticket string
+	server := mockNetBoxServer(t, func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.Header.Get("User-Agent")
+		ticket = r.Header.Get("X-Scan-Ticket")
+		json.NewEncoder(w).Encode(map[string]interface{}{})
+	})
+	defer server.Close()
+
+	client := NewClient(config.NetBoxConfig{
+		URL:   server.URL,
+		Token: "test-token",
+	}, WithHTTPHeaders(config.HTTPConfig{
+		UserAgent: "scanner/1.2",
+		Headers:   map[string]string{"X-Scan-Ticket": "CHG-1234"},
+	}))
+
+	require.NoError(t, client.TestConnection(context.Background()))
+	assert.Equal(t, "scanner/1.2", userAgent)
+	assert.Equal(t, "CHG-1234", ticket)
+}
+
+func TestClient_AuditsMutatingRequests(t *testing.T) {
+	server := mockNetBoxServer(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(Device{ID: 1})
+	})
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	client := NewClient(config.NetBoxConfig{
+		URL:   server.URL,
+		Token: "test-token",
+	}, WithAuditLog(audit.New(path, "tester")))
+
+	ctx := context.Background()
+	_, err := client.FindDeviceBySerial(ctx, "ABC123")
+	require.NoError(t, err)
+	require.NoError(t, client.UpdateDeviceCustomFields(ctx, 1, map[string]interface{}{"hw_cpu_count": 2}))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 1, "only the PATCH is audited")
+
+	var entry audit.Entry
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &entry))
+	assert.Equal(t, "tester", entry.Actor)
+	assert.Equal(t, http.MethodPatch, entry.Action)
+	assert.Equal(t, server.URL+"/api/dcim/devices/1/", entry.Target)
+	assert.Equal(t, audit.ResultSuccess, entry.Result)
+	assert.NotEmpty(t, entry.PayloadSHA256)
+}
+
+func TestClient_ReadOnlyRefusesWrites(t *testing.T) {
+	var patched bool
+	server := mockNetBoxServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPatch {
+			patched = true
+		}
+		json.NewEncoder(w).Encode(Device{ID: 1})
+	})
+	defer server.Close()
+
+	client := NewClient(config.NetBoxConfig{
+		URL:   server.URL,
+		Token: "test-token",
+	}, WithReadOnly(true))
+
+	err := client.UpdateDeviceCustomFields(context.Background(), 1, map[string]interface{}{"hw_cpu_count": 2})
+
+	assert.ErrorIs(t, err, errors.ErrReadOnly)
+	assert.False(t, patched)
+}
+
 func TestClient_AuthenticationFailure(t *testing.T) {
 	server := mockNetBoxServer(t, func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -345,6 +540,8 @@ func TestBuildCustomFields(t *testing.T) {
 		BiosVersion:      "2.0.0",
 		PowerState:       "On",
 		CollectedAt:      time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC),
+		RunID:            "5f0c6a52-1d0e-4c36-9a57-0d8f3b5e2a41",
+		IDRACNetwork:     &models.IDRACNetworkInfo{HostName: "idrac-abc1234"},
 		CPUs: []models.CPUInfo{
 			{Cores: 16, Threads: 32, MaxSpeedMHz: 3200},
 		},
@@ -354,10 +551,10 @@ func TestBuildCustomFields(t *testing.T) {
 			{State: models.MemoryStateAbsent},
 		},
 		Drives: []models.DriveInfo{
-			{CapacityGB: 960},
-			{CapacityGB: 960},
-			{CapacityGB: 1920},
-			{CapacityGB: 1920},
+			{CapacityGB: 894.25},
+			{CapacityGB: 894.25},
+			{CapacityGB: 1788.5},
+			{CapacityGB: 1788.5},
 		},
 	}
 
@@ -385,3 +582,61 @@ func TestBuildCustomFields(t *testing.T) {
 	assert.Equal(t, "2.0.0", fields["hw_bios_version"])
 	assert.Equal(t, "On", fields["hw_power_state"])
 }
+
+func TestBuildCustomFields_DecimalUnits(t *testing.T) {
+	client := NewClient(config.NetBoxConfig{}, WithUnits(units.Format{System: units.Decimal, Precision: 1}))
+
+	fields := client.buildCustomFields(models.ServerInfo{
+		TotalMemoryGiB: 512,
+		TotalStorageTB: 6.99,
+		Drives: []models.DriveInfo{
+			{CapacityGB: 1788.5},
+			{CapacityGB: 1788.5},
+		},
+	})
+
+	assert.Equal(t, 550, fields["hw_ram_total_gb"])
+	assert.Equal(t, "7.7", fields["hw_storage_total_tb"])
+	assert.Equal(t, "2x1920GB", fields["hw_storage_summary"])
+}
+
+func TestBuildCustomFields_ManufactureDate(t *testing.T) {
+	built := time.Date(2019, 5, 14, 5, 0, 0, 0, time.UTC)
+	info := models.ServerInfo{ManufactureDate: &built}
+
+	fields := NewClient(config.NetBoxConfig{}).buildCustomFields(info)
+	assert.NotContains(t, fields, "hw_manufacture_date", "only synced when enabled")
+
+	fields = NewClient(config.NetBoxConfig{SyncManufactureDate: true}).buildCustomFields(info)
+	assert.Equal(t, "2019-05-14", fields["hw_manufacture_date"])
+}
+
+func TestBuildCustomFields_FirmwareCompliance(t *testing.T) {
+	info := models.ServerInfo{FirmwareCompliance: &models.FirmwareCompliance{
+		Policy:     "default",
+		Violations: []models.Violation{{Component: models.FirmwareBIOS, Check: "min_version"}},
+	}}
+
+	fields := NewClient(config.NetBoxConfig{}).buildCustomFields(info)
+	assert.NotContains(t, fields, "hw_firmware_compliant", "only synced when enabled")
+
+	client := NewClient(config.NetBoxConfig{SyncFirmwareCompliance: true})
+	assert.Equal(t, false, client.buildCustomFields(info)["hw_firmware_compliant"])
+	assert.NotContains(t, client.buildCustomFields(models.ServerInfo{}), "hw_firmware_compliant", "not checked")
+}
+
+func TestBuildCustomFields_PartialScan(t *testing.T) {
+	client := NewClient(config.NetBoxConfig{})
+
+	fields := client.buildCustomFields(models.ServerInfo{
+		CPUCount:        2,
+		TotalMemoryGiB:  0,
+		BiosVersion:     "2.0.0",
+		ComponentErrors: map[string]models.ComponentError{models.PhaseMemory: {Code: "E_TIMEOUT", Message: "context deadline exceeded"}},
+	})
+
+	assert.Equal(t, 2, fields["hw_cpu_count"])
+	assert.Equal(t, "2.0.0", fields["hw_bios_version"])
+	assert.NotContains(t, fields, "hw_ram_total_gb", "the NetBox value of a failed component is kept")
+	assert.NotContains(t, fields, "hw_ram_slots_total")
+}
@@ -0,0 +1,70 @@
+package netbox
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/defaults"
+)
+
+// The notes of the annotations file are kept between these markers in the
+// device comments, so the rest of the comments can be edited in NetBox.
+const (
+	notesBegin = "<!-- idrac-inventory notes -->"
+	notesEnd   = "<!-- /idrac-inventory notes -->"
+)
+
+// SyncComments writes notes to the managed section of the device comments,
+// replacing the previous notes. Without notes the section is removed.
+// Comments outside the section are kept.
+func (c *Client) SyncComments(ctx context.Context, device *Device, notes []string) error {
+	comments := mergeNotes(device.Comments, notes)
+	if comments == device.Comments {
+		return nil
+	}
+
+	path := fmt.Sprintf("%s%d/", defaults.NetBoxDevicesPath, device.ID)
+	if err := c.request(ctx, http.MethodPatch, path, map[string]interface{}{"comments": comments}, nil); err != nil {
+		return fmt.Errorf("failed to update comments of device %d: %w", device.ID, err)
+	}
+
+	c.logger.Infow("device comments updated",
+		"device_id", device.ID,
+		"notes", len(notes),
+	)
+	return nil
+}
+
+// mergeNotes returns comments with its notes section replaced by notes. A
+// new section is appended after the existing comments.
+func mergeNotes(comments string, notes []string) string {
+	rest := strings.TrimSpace(comments)
+	if begin := strings.Index(rest, notesBegin); begin >= 0 {
+		end := strings.Index(rest[begin:], notesEnd)
+		if end < 0 {
+			end = len(rest)
+		} else {
+			end += begin + len(notesEnd)
+		}
+		rest = strings.TrimSpace(strings.TrimSpace(rest[:begin]) + "\n\n" + strings.TrimSpace(rest[end:]))
+	}
+	if len(notes) == 0 {
+		if rest == strings.TrimSpace(comments) {
+			return comments
+		}
+		return rest
+	}
+
+	var b strings.Builder
+	if rest != "" {
+		b.WriteString(rest + "\n\n")
+	}
+	b.WriteString(notesBegin + "\n**Inventory notes**\n\n")
+	for _, n := range notes {
+		b.WriteString("- " + n + "\n")
+	}
+	b.WriteString(notesEnd)
+	return b.String()
+}
@@ -0,0 +1,48 @@
+package netbox
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/config"
+)
+
+func TestMergeNotes(t *testing.T) {
+	section := notesBegin + "\n**Inventory notes**\n\n- pending RMA\n" + notesEnd
+
+	assert.Equal(t, section, mergeNotes("", []string{"pending RMA"}))
+	assert.Equal(t, "Racked by J.\n\n"+section, mergeNotes("Racked by J.\n", []string{"pending RMA"}))
+
+	existing := "Racked by J.\n\n" + notesBegin + "\n- old note\n" + notesEnd + "\n\nSee ticket 42."
+	assert.Equal(t, "Racked by J.\n\nSee ticket 42.\n\n"+section, mergeNotes(existing, []string{"pending RMA"}))
+	assert.Equal(t, "Racked by J.\n\nSee ticket 42.", mergeNotes(existing, nil), "section removed without notes")
+
+	assert.Equal(t, "Racked by J.\n", mergeNotes("Racked by J.\n", nil), "unchanged without section")
+	assert.Equal(t, "Racked by J.\n\n"+section, mergeNotes("Racked by J.\n\n"+section, []string{"pending RMA"}))
+}
+
+func TestClient_SyncComments(t *testing.T) {
+	var patch map[string]interface{}
+	server := mockNetBoxServer(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPatch, r.Method)
+		assert.Equal(t, "/api/dcim/devices/42/", r.URL.Path)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&patch))
+		w.Write([]byte("{}"))
+	})
+	defer server.Close()
+
+	client := NewClient(config.NetBoxConfig{URL: server.URL, Token: "test-token"})
+	device := &Device{ID: 42, Comments: "Racked by J."}
+	require.NoError(t, client.SyncComments(context.Background(), device, []string{"pending RMA", "decommission Q3"}))
+	assert.Equal(t, "Racked by J.\n\n"+notesBegin+"\n**Inventory notes**\n\n- pending RMA\n- decommission Q3\n"+notesEnd, patch["comments"])
+
+	// Nothing to write: no request
+	patch = nil
+	require.NoError(t, client.SyncComments(context.Background(), device, nil))
+	assert.Nil(t, patch)
+}
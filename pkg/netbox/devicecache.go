@@ -0,0 +1,291 @@
+package netbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/config"
+	"github.com/braunma/idrac-netbox-importer/pkg/defaults"
+	"github.com/braunma/idrac-netbox-importer/pkg/errors"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+// deviceCacheEntry is the NetBox device ID cached for an asset tag or serial.
+type deviceCacheEntry struct {
+	ID       int       `json:"id"`
+	StoredAt time.Time `json:"stored_at"`
+}
+
+// deviceCache maps asset tags and serial numbers to NetBox device IDs, so
+// that findDevice can fetch a known device directly instead of searching
+// for it. It is persisted between runs (netbox.device_cache). Prewarming
+// lists the devices once per client and keeps them for the run, which
+// saves the fetch as well.
+type deviceCache struct {
+	path string
+	cfg  config.NetBoxDeviceCacheConfig
+
+	mu      sync.Mutex
+	entries map[string]deviceCacheEntry
+	dirty   bool
+
+	prewarmOnce sync.Once
+	listed      deviceIndex // from prewarming
+}
+
+// loadDeviceCache reads the cache file. A missing file yields an empty cache.
+func loadDeviceCache(path string, cfg config.NetBoxDeviceCacheConfig) (*deviceCache, error) {
+	c := &deviceCache{path: path, cfg: cfg, entries: make(map[string]deviceCacheEntry)}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return c, nil
+	}
+	if err != nil {
+		return c, fmt.Errorf("failed to read device cache: %w", err)
+	}
+	if err := json.Unmarshal(data, &c.entries); err != nil {
+		c.entries = make(map[string]deviceCacheEntry)
+		return c, fmt.Errorf("failed to parse device cache %s: %w", path, err)
+	}
+	return c, nil
+}
+
+// deviceKey is an asset tag or serial number findDevice searches by.
+type deviceKey struct {
+	field string // "asset_tag" or "serial"
+	value string
+}
+
+func (k deviceKey) String() string {
+	return k.field + ":" + strings.ToUpper(strings.TrimSpace(k.value))
+}
+
+// matches reports whether d still has the asset tag or serial of the key.
+// NetBox filters match both case-insensitively.
+func (k deviceKey) matches(d *Device) bool {
+	v := d.Serial
+	if k.field == "asset_tag" {
+		v = d.AssetTag
+	}
+	return strings.EqualFold(strings.TrimSpace(v), strings.TrimSpace(k.value))
+}
+
+// lookupKeys returns the keys of a server in the order findDevice searches:
+// the service tag as asset tag and as serial, then the serial number.
+func lookupKeys(info models.ServerInfo) []deviceKey {
+	var keys []deviceKey
+	if info.ServiceTag != "" {
+		keys = append(keys, deviceKey{"asset_tag", info.ServiceTag}, deviceKey{"serial", info.ServiceTag})
+	}
+	if info.SerialNumber != "" {
+		keys = append(keys, deviceKey{"serial", info.SerialNumber})
+	}
+	return keys
+}
+
+// deviceKeys returns the keys under which a device can be found.
+func deviceKeys(d *Device) []deviceKey {
+	var keys []deviceKey
+	if strings.TrimSpace(d.AssetTag) != "" {
+		keys = append(keys, deviceKey{"asset_tag", d.AssetTag})
+	}
+	if strings.TrimSpace(d.Serial) != "" {
+		keys = append(keys, deviceKey{"serial", d.Serial})
+	}
+	return keys
+}
+
+// lookup returns the cached device ID of key unless it expired.
+func (c *deviceCache) lookup(key deviceKey) (int, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key.String()]
+	if !ok || time.Since(e.StoredAt) > c.cfg.GetTTL() {
+		return 0, false
+	}
+	return e.ID, true
+}
+
+// remember caches the ID of d under its asset tag and serial.
+func (c *deviceCache) remember(d *Device) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	for _, k := range deviceKeys(d) {
+		c.entries[k.String()] = deviceCacheEntry{ID: d.ID, StoredAt: now}
+	}
+	c.dirty = true
+}
+
+// invalidate removes every key of the device with the given ID, e.g.
+// after NetBox answered 404 for it.
+func (c *deviceCache) invalidate(id int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k, e := range c.entries {
+		if e.ID == id {
+			delete(c.entries, k)
+			c.dirty = true
+		}
+	}
+	for k, d := range c.listed {
+		if d.ID == id {
+			delete(c.listed, k)
+		}
+	}
+}
+
+// listedDevice returns the device of a server listed while prewarming.
+func (c *deviceCache) listedDevice(info models.ServerInfo) *Device {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.listed.find(info)
+}
+
+// save atomically writes the cache file if it changed. Expired entries are
+// dropped.
+func (c *deviceCache) save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.dirty {
+		return nil
+	}
+	for k, e := range c.entries {
+		if time.Since(e.StoredAt) > c.cfg.GetTTL() {
+			delete(c.entries, k)
+		}
+	}
+
+	data, err := json.Marshal(c.entries)
+	if err != nil {
+		return fmt.Errorf("failed to encode device cache: %w", err)
+	}
+
+	dir := filepath.Dir(c.path)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(c.path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write device cache: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write device cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write device cache: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), c.path); err != nil {
+		return fmt.Errorf("failed to write device cache: %w", err)
+	}
+	c.dirty = false
+	return nil
+}
+
+// WithDeviceCache caches the device IDs found by asset tag and serial in the
+// file at path. An unreadable cache file is logged and replaced.
+func WithDeviceCache(path string, cfg config.NetBoxDeviceCacheConfig) ClientOption {
+	return func(c *Client) {
+		cache, err := loadDeviceCache(path, cfg)
+		if err != nil {
+			c.logger.Warnw("ignoring unreadable device cache", "path", path, "error", err)
+		}
+		c.devices = cache
+	}
+}
+
+// cachedDevice returns the device of a server from the device cache, or nil
+// if none is cached. Devices that were deleted or whose asset tag and serial
+// changed are removed from the cache.
+func (c *Client) cachedDevice(ctx context.Context, info models.ServerInfo) (*Device, error) {
+	c.devices.prewarmOnce.Do(func() {
+		if c.devices.cfg.Prewarm {
+			c.prewarmDevices(ctx)
+		}
+	})
+
+	if d := c.devices.listedDevice(info); d != nil {
+		return d, nil
+	}
+
+	for _, k := range lookupKeys(info) {
+		id, ok := c.devices.lookup(k)
+		if !ok {
+			continue
+		}
+		device, err := c.getDevice(ctx, id)
+		if errors.CodeOf(err) == errors.CodeNetBoxNotFound {
+			c.logger.Debugw("cached device no longer exists", "device_id", id, "key", k.String())
+			c.devices.invalidate(id)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !k.matches(device) {
+			c.logger.Debugw("cached device no longer matches", "device_id", id, "key", k.String())
+			c.devices.invalidate(id)
+			continue
+		}
+		c.logger.Debugw("device found in cache", "device_id", id, "key", k.String())
+		return device, nil
+	}
+	return nil, nil
+}
+
+// getDevice fetches a device by ID.
+func (c *Client) getDevice(ctx context.Context, id int) (*Device, error) {
+	var device Device
+	path := fmt.Sprintf("%s%d/", defaults.NetBoxDevicesPath, id)
+	if err := c.request(ctx, http.MethodGet, path, nil, &device); err != nil {
+		return nil, err
+	}
+	return &device, nil
+}
+
+// prewarmDevices lists the devices matching netbox.device_cache.prewarm_filter
+// (all devices if empty) and keeps them for this client. A failure is
+// logged; devices are then looked up one by one.
+func (c *Client) prewarmDevices(ctx context.Context) {
+	start := time.Now()
+	query, _ := c.devices.cfg.PrewarmQuery()
+
+	devices, err := c.listDevicesParallel(ctx, query)
+	if err != nil {
+		c.logger.Warnw("failed to prewarm device cache", "error", err)
+		return
+	}
+	for i := range devices {
+		c.devices.remember(&devices[i])
+	}
+	listed := newDeviceIndex(devices)
+	c.devices.mu.Lock()
+	c.devices.listed = listed
+	c.devices.mu.Unlock()
+
+	c.logger.Infow("prewarmed device cache",
+		"devices", len(devices),
+		"duration", time.Since(start),
+	)
+}
+
+// saveDeviceCache writes the device cache, if enabled.
+func (c *Client) saveDeviceCache() {
+	if c.devices == nil {
+		return
+	}
+	if err := c.devices.save(); err != nil {
+		c.logger.Warnw("failed to save device cache", "path", c.devices.path, "error", err)
+	}
+}
@@ -0,0 +1,168 @@
+package netbox
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/config"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+// deviceCacheServer serves device 42 (asset tag SVC1234) to searches and to
+// fetches by ID, and counts both.
+type deviceCacheServer struct {
+	mu       sync.Mutex
+	deleted  bool
+	searches int
+	fetches  int
+}
+
+func (s *deviceCacheServer) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	device := Device{ID: 42, Name: "server01", AssetTag: "SVC1234", Serial: "SN-1"}
+	switch r.URL.Path {
+	case "/api/dcim/devices/":
+		s.searches++
+		list := DeviceList{Results: []Device{}}
+		if !s.deleted && r.URL.Query().Get("asset_tag") == "SVC1234" {
+			list = DeviceList{Count: 1, Results: []Device{device}}
+		}
+		json.NewEncoder(w).Encode(list)
+	case "/api/dcim/devices/42/":
+		s.fetches++
+		if s.deleted {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(device)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestDeviceCache_SkipsSearch(t *testing.T) {
+	srv := &deviceCacheServer{}
+	server := mockNetBoxServer(t, srv.handle)
+	defer server.Close()
+
+	cfg := config.NetBoxConfig{URL: server.URL, Token: "test-token"}
+	cacheCfg := config.NetBoxDeviceCacheConfig{Enabled: true}
+	path := filepath.Join(t.TempDir(), "netbox-devices.json")
+	info := models.ServerInfo{Host: "10.0.0.1", ServiceTag: "SVC1234"}
+	ctx := context.Background()
+
+	first := NewClient(cfg, WithDeviceCache(path, cacheCfg))
+	device, err := first.findDevice(ctx, info)
+	require.NoError(t, err)
+	require.NotNil(t, device)
+	first.saveDeviceCache()
+	assert.Equal(t, 1, srv.searches)
+	assert.FileExists(t, path)
+
+	second := NewClient(cfg, WithDeviceCache(path, cacheCfg))
+	device, err = second.findDevice(ctx, info)
+	require.NoError(t, err)
+	require.NotNil(t, device)
+	assert.Equal(t, 42, device.ID)
+	assert.Equal(t, 1, srv.searches, "the cached ID is fetched instead of searched")
+	assert.Equal(t, 1, srv.fetches)
+}
+
+func TestDeviceCache_InvalidatesDeleted(t *testing.T) {
+	srv := &deviceCacheServer{}
+	server := mockNetBoxServer(t, srv.handle)
+	defer server.Close()
+
+	client := NewClient(config.NetBoxConfig{URL: server.URL, Token: "test-token"},
+		WithDeviceCache(filepath.Join(t.TempDir(), "netbox-devices.json"), config.NetBoxDeviceCacheConfig{Enabled: true}))
+	info := models.ServerInfo{Host: "10.0.0.1", ServiceTag: "SVC1234"}
+	ctx := context.Background()
+
+	_, err := client.findDevice(ctx, info)
+	require.NoError(t, err)
+
+	srv.mu.Lock()
+	srv.deleted = true
+	srv.mu.Unlock()
+	device, err := client.findDevice(ctx, info)
+	require.NoError(t, err)
+	assert.Nil(t, device)
+	assert.Equal(t, 1, srv.fetches)
+
+	_, ok := client.devices.lookup(deviceKey{"asset_tag", "SVC1234"})
+	assert.False(t, ok, "a device NetBox answered 404 for is dropped")
+}
+
+func TestDeviceCache_Expired(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "netbox-devices.json")
+	cache, err := loadDeviceCache(path, config.NetBoxDeviceCacheConfig{TTL: "1h"})
+	require.NoError(t, err)
+
+	cache.remember(&Device{ID: 7, AssetTag: "OLD", Serial: "NEW"})
+	cache.entries["asset_tag:OLD"] = deviceCacheEntry{ID: 7, StoredAt: time.Now().Add(-2 * time.Hour)}
+
+	_, ok := cache.lookup(deviceKey{"asset_tag", "old"})
+	assert.False(t, ok)
+	id, ok := cache.lookup(deviceKey{"serial", "new"})
+	assert.True(t, ok)
+	assert.Equal(t, 7, id)
+
+	require.NoError(t, cache.save())
+	reloaded, err := loadDeviceCache(path, config.NetBoxDeviceCacheConfig{TTL: "1h"})
+	require.NoError(t, err)
+	assert.Len(t, reloaded.entries, 1, "expired entries are not saved")
+}
+
+func TestDeviceCache_Prewarm(t *testing.T) {
+	const total = 2*devicePageSize + 3
+	var mu sync.Mutex
+	offsets := make(map[string]bool)
+	server := mockNetBoxServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/dcim/devices/" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		assert.Equal(t, "dc1", r.URL.Query().Get("site"))
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		mu.Lock()
+		offsets[r.URL.Query().Get("offset")] = true
+		mu.Unlock()
+
+		list := DeviceList{Count: total, Results: []Device{}}
+		for id := offset + 1; id <= min(offset+devicePageSize, total); id++ {
+			list.Results = append(list.Results, Device{ID: id, Serial: "SN-" + strconv.Itoa(id)})
+		}
+		json.NewEncoder(w).Encode(list)
+	})
+	defer server.Close()
+
+	client := NewClient(config.NetBoxConfig{URL: server.URL, Token: "test-token"},
+		WithDeviceCache(filepath.Join(t.TempDir(), "netbox-devices.json"), config.NetBoxDeviceCacheConfig{
+			Enabled:       true,
+			Prewarm:       true,
+			PrewarmFilter: "site=dc1",
+		}))
+	ctx := context.Background()
+
+	device, err := client.findDevice(ctx, models.ServerInfo{Host: "10.0.0.1", SerialNumber: "sn-1003"})
+	require.NoError(t, err)
+	require.NotNil(t, device)
+	assert.Equal(t, 1003, device.ID)
+	assert.Equal(t, map[string]bool{"0": true, "500": true, "1000": true}, offsets)
+
+	device, err = client.findDevice(ctx, models.ServerInfo{Host: "10.0.0.2", SerialNumber: "SN-17"})
+	require.NoError(t, err)
+	require.NotNil(t, device)
+	assert.Equal(t, 17, device.ID)
+	assert.Len(t, offsets, 3, "devices are listed once per client")
+}
@@ -0,0 +1,165 @@
+package netbox
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/defaults"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+// choice is a NetBox choice field such as airflow or weight_unit.
+type choice struct {
+	Value string `json:"value"`
+}
+
+// DeviceType is the subset of a NetBox device type managed from the model catalog.
+type DeviceType struct {
+	ID      int      `json:"id"`
+	Model   string   `json:"model"`
+	UHeight float64  `json:"u_height"`
+	Airflow *choice  `json:"airflow"`
+	Weight  *float64 `json:"weight"`
+}
+
+// deviceTypeList is a paginated list of device types.
+type deviceTypeList struct {
+	Count   int          `json:"count"`
+	Results []DeviceType `json:"results"`
+}
+
+// Device type sync outcomes returned by EnsureDeviceType.
+const (
+	DeviceTypeCreated      = "created"
+	DeviceTypeUpdated      = "updated"
+	DeviceTypeUnchanged    = "unchanged"
+	DeviceTypeNotInCatalog = "not in catalog"
+)
+
+// EnsureDeviceType creates the device type for a server model from the model
+// catalog, including a power port template per PSU slot, or fills the missing
+// attributes of an existing one. Models missing from the catalog are left
+// alone. The model name is normalized (see models.NormalizeModel) and
+// matched case-insensitively against existing device types. It returns one
+// of the DeviceType* outcomes.
+func (c *Client) EnsureDeviceType(ctx context.Context, manufacturer, model string) (string, error) {
+	model = models.NormalizeModel(manufacturer, model)
+	spec, ok := c.catalog.Lookup(model)
+	if !ok {
+		return DeviceTypeNotInCatalog, nil
+	}
+	if manufacturer == "" {
+		manufacturer = "Unknown"
+	}
+
+	manufacturerID, err := c.findOrCreate(ctx, defaults.NetBoxManufacturersPath,
+		url.Values{"name": {manufacturer}},
+		map[string]interface{}{"name": manufacturer, "slug": slugify(manufacturer)})
+	if err != nil {
+		return "", fmt.Errorf("manufacturer %q: %w", manufacturer, err)
+	}
+
+	query := url.Values{"manufacturer_id": {fmt.Sprint(manufacturerID)}, "model__ie": {model}}
+	var existing deviceTypeList
+	if err := c.request(ctx, http.MethodGet, defaults.NetBoxDeviceTypesPath+"?"+query.Encode(), nil, &existing); err != nil {
+		return "", err
+	}
+	if existing.Count > 0 && len(existing.Results) > 0 {
+		return c.fillDeviceType(ctx, existing.Results[0], spec)
+	}
+
+	create := map[string]interface{}{
+		"manufacturer":  manufacturerID,
+		"model":         model,
+		"slug":          slugify(model),
+		"u_height":      spec.UHeight,
+		"is_full_depth": true,
+	}
+	if spec.Airflow != "" {
+		create["airflow"] = spec.Airflow
+	}
+	if spec.WeightKg > 0 {
+		create["weight"] = spec.WeightKg
+		create["weight_unit"] = "kg"
+	}
+
+	var created DeviceType
+	if err := c.request(ctx, http.MethodPost, defaults.NetBoxDeviceTypesPath, create, &created); err != nil {
+		return "", err
+	}
+	for i := 1; i <= spec.PSUSlots; i++ {
+		name := fmt.Sprintf("PSU%d", i)
+		if _, err := c.findOrCreate(ctx, defaults.NetBoxPowerPortTemplatesPath,
+			url.Values{"devicetype_id": {fmt.Sprint(created.ID)}, "name": {name}},
+			map[string]interface{}{"device_type": created.ID, "name": name}); err != nil {
+			return "", fmt.Errorf("power port template %s: %w", name, err)
+		}
+	}
+
+	c.logger.Infow("device type created",
+		"model", model,
+		"device_type_id", created.ID,
+		"u_height", spec.UHeight,
+	)
+	return DeviceTypeCreated, nil
+}
+
+// syncDeviceType fills the missing attributes of the device's type from the
+// model catalog (netbox.sync_device_types).
+func (c *Client) syncDeviceType(ctx context.Context, device *Device, info models.ServerInfo) error {
+	if device.DeviceType == nil {
+		return nil
+	}
+	spec, ok := c.catalog.Lookup(info.Model)
+	if !ok {
+		c.logger.Debugw("model not in catalog, device type left unchanged",
+			"host", info.Host,
+			"model", info.Model,
+		)
+		return nil
+	}
+
+	var dt DeviceType
+	path := fmt.Sprintf("%s%d/", defaults.NetBoxDeviceTypesPath, device.DeviceType.ID)
+	if err := c.request(ctx, http.MethodGet, path, nil, &dt); err != nil {
+		return err
+	}
+	_, err := c.fillDeviceType(ctx, dt, spec)
+	return err
+}
+
+// fillDeviceType sets airflow and weight of dt if they are empty. The U height
+// always has a value in NetBox and is only set when creating a device type,
+// since changing it can conflict with racked devices.
+func (c *Client) fillDeviceType(ctx context.Context, dt DeviceType, spec models.ChassisSpec) (string, error) {
+	patch := map[string]interface{}{}
+	if dt.Airflow == nil && spec.Airflow != "" {
+		patch["airflow"] = spec.Airflow
+	}
+	if dt.Weight == nil && spec.WeightKg > 0 {
+		patch["weight"] = spec.WeightKg
+		patch["weight_unit"] = "kg"
+	}
+	if dt.UHeight != 0 && dt.UHeight != spec.UHeight {
+		c.logger.Warnw("device type U height differs from the model catalog",
+			"model", dt.Model,
+			"netbox_u_height", dt.UHeight,
+			"catalog_u_height", spec.UHeight,
+		)
+	}
+	if len(patch) == 0 {
+		return DeviceTypeUnchanged, nil
+	}
+
+	path := fmt.Sprintf("%s%d/", defaults.NetBoxDeviceTypesPath, dt.ID)
+	if err := c.request(ctx, http.MethodPatch, path, patch, nil); err != nil {
+		return "", fmt.Errorf("failed to update device type %d: %w", dt.ID, err)
+	}
+	c.logger.Infow("device type updated from model catalog",
+		"model", dt.Model,
+		"device_type_id", dt.ID,
+	)
+	return DeviceTypeUpdated, nil
+}
@@ -0,0 +1,117 @@
+package netbox
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/config"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+func TestClient_EnsureDeviceType_Create(t *testing.T) {
+	created := map[string][]map[string]interface{}{}
+	server := mockNetBoxServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			var body map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			created[r.URL.Path] = append(created[r.URL.Path], body)
+			json.NewEncoder(w).Encode(objectRef{ID: len(created[r.URL.Path]) + 100})
+			return
+		}
+		json.NewEncoder(w).Encode(objectList{})
+	})
+	defer server.Close()
+
+	client := NewClient(config.NetBoxConfig{URL: server.URL, Token: "test-token"})
+
+	outcome, err := client.EnsureDeviceType(context.Background(), "Dell Inc.", "PowerEdge R750")
+	require.NoError(t, err)
+	assert.Equal(t, DeviceTypeCreated, outcome)
+
+	types := created["/api/dcim/device-types/"]
+	require.Len(t, types, 1)
+	assert.Equal(t, "PowerEdge R750", types[0]["model"])
+	assert.Equal(t, "poweredge-r750", types[0]["slug"])
+	assert.Equal(t, 2.0, types[0]["u_height"])
+	assert.Equal(t, "front-to-rear", types[0]["airflow"])
+	assert.Equal(t, "kg", types[0]["weight_unit"])
+	assert.Equal(t, float64(101), types[0]["manufacturer"])
+
+	ports := created["/api/dcim/power-port-templates/"]
+	require.Len(t, ports, 2)
+	assert.Equal(t, "PSU1", ports[0]["name"])
+	assert.Equal(t, "PSU2", ports[1]["name"])
+	assert.Equal(t, float64(101), ports[0]["device_type"])
+}
+
+func TestClient_EnsureDeviceType_FillsMissing(t *testing.T) {
+	var patch map[string]interface{}
+	server := mockNetBoxServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPatch:
+			assert.Equal(t, "/api/dcim/device-types/5/", r.URL.Path)
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&patch))
+			w.Write([]byte("{}"))
+		case r.URL.Path == "/api/dcim/device-types/":
+			assert.Equal(t, "PowerEdge R640", r.URL.Query().Get("model__ie"))
+			weight := 20.0
+			json.NewEncoder(w).Encode(deviceTypeList{Count: 1, Results: []DeviceType{
+				{ID: 5, Model: "PowerEdge R640", UHeight: 1, Weight: &weight},
+			}})
+		default:
+			json.NewEncoder(w).Encode(objectList{Count: 1, Results: []objectRef{{ID: 3}}})
+		}
+	})
+	defer server.Close()
+
+	client := NewClient(config.NetBoxConfig{URL: server.URL, Token: "test-token"})
+
+	outcome, err := client.EnsureDeviceType(context.Background(), "Dell Inc.", "Dell PowerEdge R640 ")
+	require.NoError(t, err)
+	assert.Equal(t, DeviceTypeUpdated, outcome)
+	assert.Equal(t, map[string]interface{}{"airflow": "front-to-rear"}, patch, "existing weight is kept")
+}
+
+func TestClient_EnsureDeviceType_NotInCatalog(t *testing.T) {
+	server := mockNetBoxServer(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+	})
+	defer server.Close()
+
+	client := NewClient(config.NetBoxConfig{URL: server.URL, Token: "test-token"})
+
+	outcome, err := client.EnsureDeviceType(context.Background(), "Dell Inc.", "Custom Box")
+	require.NoError(t, err)
+	assert.Equal(t, DeviceTypeNotInCatalog, outcome)
+}
+
+func TestClient_SyncServerInfo_DeviceType(t *testing.T) {
+	var patched []string
+	server := mockNetBoxServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPatch:
+			patched = append(patched, r.URL.Path)
+			w.Write([]byte("{}"))
+		case r.URL.Path == "/api/dcim/devices/":
+			json.NewEncoder(w).Encode(DeviceList{Count: 1, Results: []Device{
+				{ID: 42, Name: "srv-01", DeviceType: &objectRef{ID: 9}},
+			}})
+		case r.URL.Path == "/api/dcim/device-types/9/":
+			json.NewEncoder(w).Encode(DeviceType{ID: 9, Model: "PowerEdge R750", UHeight: 2})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	})
+	defer server.Close()
+
+	client := NewClient(config.NetBoxConfig{URL: server.URL, Token: "test-token", SyncDeviceTypes: true})
+
+	info := models.ServerInfo{Host: "10.0.0.1", ServiceTag: "ABC123", Model: "PowerEdge R750"}
+	require.NoError(t, client.SyncServerInfo(context.Background(), info))
+	assert.Equal(t, []string{"/api/dcim/devices/42/", "/api/dcim/device-types/9/"}, patched)
+}
@@ -0,0 +1,254 @@
+package netbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/defaults"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+// graphqlDeviceFragment selects the device fields the sync uses. In the
+// NetBox GraphQL schema the device object type is named DeviceType.
+const graphqlDeviceFragment = `fragment device on DeviceType{id name serial asset_tag device_type{id} site{id} rack{id} position face tenant{id} oob_ip{id address} primary_ip4{id address} role{id name slug} tags{id name slug} comments custom_field_data}`
+
+// graphqlRef is a nested object of a GraphQL device. GraphQL IDs are strings.
+type graphqlRef struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Slug    string `json:"slug"`
+	Address string `json:"address"`
+}
+
+func (r *graphqlRef) id() int {
+	id, _ := strconv.Atoi(r.ID)
+	return id
+}
+
+func (r *graphqlRef) object() *objectRef {
+	if r == nil {
+		return nil
+	}
+	return &objectRef{ID: r.id()}
+}
+
+func (r *graphqlRef) named() *namedRef {
+	if r == nil {
+		return nil
+	}
+	return &namedRef{ID: r.id(), Name: r.Name, Slug: r.Slug}
+}
+
+func (r *graphqlRef) ip() *ipRef {
+	if r == nil {
+		return nil
+	}
+	return &ipRef{ID: r.id(), Address: r.Address}
+}
+
+// graphqlDevice is a device as selected by graphqlDeviceFragment.
+type graphqlDevice struct {
+	ID              string                 `json:"id"`
+	Name            string                 `json:"name"`
+	Serial          string                 `json:"serial"`
+	AssetTag        string                 `json:"asset_tag"`
+	DeviceType      *graphqlRef            `json:"device_type"`
+	Site            *graphqlRef            `json:"site"`
+	Rack            *graphqlRef            `json:"rack"`
+	Position        *float64               `json:"position"`
+	Face            *string                `json:"face"`
+	Tenant          *graphqlRef            `json:"tenant"`
+	OOBIP           *graphqlRef            `json:"oob_ip"`
+	PrimaryIP4      *graphqlRef            `json:"primary_ip4"`
+	Role            *graphqlRef            `json:"role"`
+	Tags            []graphqlRef           `json:"tags"`
+	Comments        string                 `json:"comments"`
+	CustomFieldData map[string]interface{} `json:"custom_field_data"`
+}
+
+// device converts the GraphQL device to the REST representation the sync
+// works with.
+func (g graphqlDevice) device(baseURL string) Device {
+	id, _ := strconv.Atoi(g.ID)
+	d := Device{
+		ID:           id,
+		URL:          fmt.Sprintf("%s%s%d/", baseURL, defaults.NetBoxDevicesPath, id),
+		Name:         g.Name,
+		Serial:       g.Serial,
+		AssetTag:     g.AssetTag,
+		DeviceType:   g.DeviceType.object(),
+		Site:         g.Site.object(),
+		Rack:         g.Rack.object(),
+		Position:     g.Position,
+		Tenant:       g.Tenant.object(),
+		OOBIP:        g.OOBIP.ip(),
+		PrimaryIP:    g.PrimaryIP4.ip(),
+		PrimaryIP4:   g.PrimaryIP4.ip(),
+		Role:         g.Role.named(),
+		Comments:     g.Comments,
+		CustomFields: g.CustomFieldData,
+	}
+	if g.Face != nil {
+		// Enum values are upper case in some NetBox versions
+		d.Face = &choice{Value: strings.ToLower(*g.Face)}
+	}
+	for i := range g.Tags {
+		d.Tags = append(d.Tags, *g.Tags[i].named())
+	}
+	if d.CustomFields == nil {
+		d.CustomFields = make(map[string]interface{})
+	}
+	return d
+}
+
+// graphqlLookup holds the results of the GraphQL device lookups of a client
+// (netbox.graphql). Every key looked up is recorded, so a server whose keys
+// were all looked up without a match needs no further query.
+type graphqlLookup struct {
+	batchSize int
+
+	mu     sync.Mutex
+	looked map[string]bool
+	found  map[string]*Device
+}
+
+func newGraphQLLookup(batchSize int) *graphqlLookup {
+	return &graphqlLookup{
+		batchSize: batchSize,
+		looked:    make(map[string]bool),
+		found:     make(map[string]*Device),
+	}
+}
+
+// missing returns the keys that were not looked up yet, without duplicates.
+func (l *graphqlLookup) missing(keys []deviceKey) []deviceKey {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	seen := make(map[string]bool)
+	var out []deviceKey
+	for _, k := range keys {
+		if !l.looked[k.String()] && !seen[k.String()] {
+			seen[k.String()] = true
+			out = append(out, k)
+		}
+	}
+	return out
+}
+
+// find returns the device of the first key that matched one, or nil.
+func (l *graphqlLookup) find(keys []deviceKey) *Device {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, k := range keys {
+		if d := l.found[k.String()]; d != nil {
+			return d
+		}
+	}
+	return nil
+}
+
+// queryDevices looks the keys up with the GraphQL API, batchSize keys per
+// query, and records the results.
+func (c *Client) queryDevices(ctx context.Context, keys []deviceKey) error {
+	for start := 0; start < len(keys); start += c.graphql.batchSize {
+		batch := keys[start:min(start+c.graphql.batchSize, len(keys))]
+
+		var query strings.Builder
+		query.WriteString("query{")
+		for i, k := range batch {
+			value, _ := json.Marshal(strings.TrimSpace(k.value))
+			fmt.Fprintf(&query, "k%d:device_list(filters:{%s:{i_exact:%s}}){...device}", i, k.field, value)
+		}
+		query.WriteString("}")
+		query.WriteString(graphqlDeviceFragment)
+
+		var resp struct {
+			Data   map[string][]graphqlDevice `json:"data"`
+			Errors []struct {
+				Message string `json:"message"`
+			} `json:"errors"`
+		}
+		path := defaults.NetBoxGraphQLPath + "?query=" + url.QueryEscape(query.String())
+		if err := c.request(ctx, http.MethodGet, path, nil, &resp); err != nil {
+			return fmt.Errorf("GraphQL device lookup failed: %w", err)
+		}
+		if len(resp.Errors) > 0 {
+			return fmt.Errorf("GraphQL device lookup failed: %s", resp.Errors[0].Message)
+		}
+
+		c.graphql.mu.Lock()
+		for i, k := range batch {
+			c.graphql.looked[k.String()] = true
+			if devices := resp.Data["k"+strconv.Itoa(i)]; len(devices) > 0 {
+				d := devices[0].device(c.baseURL)
+				c.graphql.found[k.String()] = &d
+			}
+		}
+		c.graphql.mu.Unlock()
+	}
+	return nil
+}
+
+// queryDevice returns the device of a server from the GraphQL lookups,
+// querying the keys that were not looked up yet.
+func (c *Client) queryDevice(ctx context.Context, info models.ServerInfo) (*Device, error) {
+	keys := lookupKeys(info)
+	if len(keys) == 0 {
+		c.logger.Warnw("no service tag or serial number available for device lookup",
+			"host", info.Host,
+		)
+		return nil, nil
+	}
+	if err := c.queryDevices(ctx, c.graphql.missing(keys)); err != nil {
+		return nil, err
+	}
+
+	device := c.graphql.find(keys)
+	if device != nil {
+		c.logger.Infow("device found with GraphQL",
+			"host", info.Host,
+			"device_id", device.ID,
+			"device_name", device.Name,
+		)
+	}
+	return device, nil
+}
+
+// prefetchDevices looks up the devices of all servers in batched GraphQL
+// queries, so that the sync does not query them one by one. It is skipped
+// if devices are matched otherwise (device cache or bulk listing). A
+// failure is logged; the servers are then looked up one by one.
+func (c *Client) prefetchDevices(ctx context.Context, servers []models.ServerInfo) {
+	if c.graphql == nil || c.bulk != nil || c.devices != nil {
+		return
+	}
+
+	var keys []deviceKey
+	for _, info := range servers {
+		if info.IsValid() {
+			keys = append(keys, lookupKeys(info)...)
+		}
+	}
+	keys = c.graphql.missing(keys)
+	if len(keys) == 0 {
+		return
+	}
+
+	start := time.Now()
+	if err := c.queryDevices(ctx, keys); err != nil {
+		c.logger.Warnw("failed to prefetch devices", "error", err)
+		return
+	}
+	c.logger.Infow("prefetched devices with GraphQL",
+		"lookups", len(keys),
+		"queries", (len(keys)+c.graphql.batchSize-1)/c.graphql.batchSize,
+		"duration", time.Since(start),
+	)
+}
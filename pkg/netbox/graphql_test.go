@@ -0,0 +1,166 @@
+package netbox
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/config"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+// graphqlAlias matches a device lookup of queryDevices.
+var graphqlAlias = regexp.MustCompile(`(k\d+):device_list\(filters:\{(\w+):\{i_exact:("[^"]*")\}\}\)`)
+
+// graphqlServer answers device lookups from devices and counts the queries.
+// Any REST request fails the test.
+type graphqlServer struct {
+	t       *testing.T
+	devices []map[string]interface{}
+
+	mu      sync.Mutex
+	queries int
+}
+
+func (s *graphqlServer) handle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet || r.URL.Path != "/graphql/" {
+		s.t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	s.mu.Lock()
+	s.queries++
+	s.mu.Unlock()
+
+	query := r.URL.Query().Get("query")
+	assert.Contains(s.t, query, "fragment device on DeviceType")
+	data := make(map[string][]map[string]interface{})
+	for _, m := range graphqlAlias.FindAllStringSubmatch(query, -1) {
+		var value string
+		require.NoError(s.t, json.Unmarshal([]byte(m[3]), &value))
+		data[m[1]] = []map[string]interface{}{}
+		for _, d := range s.devices {
+			if strings.EqualFold(d[m[2]].(string), value) {
+				data[m[1]] = append(data[m[1]], d)
+			}
+		}
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+}
+
+func TestGraphQLDevice_Device(t *testing.T) {
+	var g graphqlDevice
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"id": "42", "name": "server01", "serial": "SN-1", "asset_tag": null,
+		"device_type": {"id": "7"}, "site": {"id": "1"}, "rack": null,
+		"position": 12.0, "face": "FRONT", "tenant": null,
+		"oob_ip": {"id": "9", "address": "10.0.0.5/24"}, "primary_ip4": null,
+		"role": {"id": "3", "name": "Server", "slug": "server"},
+		"tags": [{"id": "5", "name": "idrac", "slug": "idrac"}],
+		"comments": "", "custom_field_data": {"hw_cpu_count": 2}
+	}`), &g))
+
+	d := g.device("https://netbox.example.com")
+	assert.Equal(t, 42, d.ID)
+	assert.Equal(t, "https://netbox.example.com/api/dcim/devices/42/", d.URL)
+	assert.Empty(t, d.AssetTag)
+	assert.Equal(t, &objectRef{ID: 7}, d.DeviceType)
+	assert.Nil(t, d.Rack)
+	assert.Equal(t, "front", d.Face.Value)
+	assert.Equal(t, &ipRef{ID: 9, Address: "10.0.0.5/24"}, d.OOBIP)
+	assert.Nil(t, d.PrimaryIP)
+	assert.Equal(t, "server", d.RoleSlug())
+	assert.Equal(t, []namedRef{{ID: 5, Name: "idrac", Slug: "idrac"}}, d.Tags)
+	assert.Equal(t, float64(2), d.CustomFields["hw_cpu_count"])
+}
+
+func TestClient_GraphQLLookup(t *testing.T) {
+	srv := &graphqlServer{t: t, devices: []map[string]interface{}{
+		{"id": "1", "name": "a", "asset_tag": "SVC1", "serial": "SN-1"},
+		{"id": "2", "name": "b", "asset_tag": "", "serial": "SVC2"},
+		{"id": "3", "name": "c", "asset_tag": "", "serial": "SN-3"},
+	}}
+	server := mockNetBoxServer(t, srv.handle)
+	defer server.Close()
+
+	client := NewClient(config.NetBoxConfig{
+		URL:     server.URL,
+		Token:   "test-token",
+		GraphQL: config.NetBoxGraphQLConfig{Enabled: true, BatchSize: 4},
+	}, WithReadOnly(true))
+	ctx := context.Background()
+
+	servers := []models.ServerInfo{
+		{Host: "10.0.0.1", ServiceTag: "SVC1", SerialNumber: "SN-1"},
+		{Host: "10.0.0.2", ServiceTag: "svc2"},
+		{Host: "10.0.0.3", SerialNumber: "SN-3"},
+		{Host: "10.0.0.4", ServiceTag: "SVC4"},
+	}
+	client.prefetchDevices(ctx, servers)
+	assert.Equal(t, 2, srv.queries, "8 lookups in batches of 4")
+
+	for i, id := range []int{1, 2, 3} {
+		device, err := client.findDevice(ctx, servers[i])
+		require.NoError(t, err)
+		require.NotNil(t, device, servers[i].Host)
+		assert.Equal(t, id, device.ID)
+	}
+	device, err := client.findDevice(ctx, servers[3])
+	require.NoError(t, err)
+	assert.Nil(t, device)
+	assert.Equal(t, 2, srv.queries, "prefetched servers are not queried again")
+
+	device, err = client.findDevice(ctx, models.ServerInfo{Host: "10.0.0.5", SerialNumber: "SN-3 "})
+	require.NoError(t, err)
+	require.NotNil(t, device)
+	assert.Equal(t, 3, device.ID)
+	assert.Equal(t, 2, srv.queries)
+
+	_, err = client.findDevice(ctx, models.ServerInfo{Host: "10.0.0.6", ServiceTag: "SVC6"})
+	require.NoError(t, err)
+	assert.Equal(t, 3, srv.queries, "other servers are looked up on demand")
+}
+
+func TestClient_GraphQLErrors(t *testing.T) {
+	server := mockNetBoxServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data": null, "errors": [{"message": "Cannot query field 'i_exact'"}]}`))
+	})
+	defer server.Close()
+
+	client := NewClient(config.NetBoxConfig{
+		URL:     server.URL,
+		Token:   "test-token",
+		GraphQL: config.NetBoxGraphQLConfig{Enabled: true},
+	})
+
+	_, err := client.findDevice(context.Background(), models.ServerInfo{Host: "10.0.0.1", ServiceTag: "SVC1"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Cannot query field")
+}
+
+func TestGraphQLLookup_BatchSize(t *testing.T) {
+	keys := make([]deviceKey, 0, 130)
+	for i := 0; i < cap(keys); i++ {
+		keys = append(keys, deviceKey{"serial", "SN-" + strconv.Itoa(i)})
+	}
+	srv := &graphqlServer{t: t}
+	server := mockNetBoxServer(t, srv.handle)
+	defer server.Close()
+
+	client := NewClient(config.NetBoxConfig{
+		URL:     server.URL,
+		Token:   "test-token",
+		GraphQL: config.NetBoxGraphQLConfig{Enabled: true},
+	})
+	require.NoError(t, client.queryDevices(context.Background(), keys))
+	assert.Equal(t, 3, srv.queries)
+	assert.Empty(t, client.graphql.missing(keys))
+}
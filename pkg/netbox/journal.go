@@ -0,0 +1,101 @@
+package netbox
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/defaults"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+	"github.com/braunma/idrac-netbox-importer/pkg/units"
+)
+
+// Journal entry kinds.
+const (
+	journalKindInfo    = "info"
+	journalKindWarning = "warning"
+	journalKindDanger  = "danger"
+)
+
+// fieldChange is a custom field whose value differs from the last sync.
+type fieldChange struct {
+	Field    string
+	Old, New string
+}
+
+// createJournalEntry adds a journal entry to the device summarizing the scan,
+// the custom fields that changed and the components that are not healthy.
+// The entry kind is warning or danger if components are unhealthy.
+func (c *Client) createJournalEntry(ctx context.Context, deviceID int, info models.ServerInfo, changes []fieldChange) error {
+	issues := info.HealthIssues()
+
+	kind := journalKindInfo
+	switch models.WorstHealth(issues) {
+	case models.HealthWarning:
+		kind = journalKindWarning
+	case models.HealthCritical:
+		kind = journalKindDanger
+	}
+
+	return c.request(ctx, http.MethodPost, defaults.NetBoxJournalEntriesPath, map[string]interface{}{
+		"assigned_object_type": "dcim.device",
+		"assigned_object_id":   deviceID,
+		"kind":                 kind,
+		"comments":             journalComment(info, issues, changes, c.units),
+	}, nil)
+}
+
+// journalComment renders the journal text (NetBox renders it as Markdown):
+//
+//	Inventory scan 2025-01-10: 2× CPU, 512 GiB, 8 drives; drive Disk.Bay.3 health Warning
+//
+//	Changed:
+//	- hw_ram_total_gb: 256 → 512
+func journalComment(info models.ServerInfo, issues []models.HealthIssue, changes []fieldChange, u units.Format) string {
+	var b strings.Builder
+
+	parts := []string{
+		fmt.Sprintf("%d× CPU", info.CPUCount),
+		u.Gigabytes(info.TotalMemoryGiB),
+		fmt.Sprintf("%d drives", info.DriveCount),
+	}
+	if info.GPUCount > 0 {
+		parts = append(parts, fmt.Sprintf("%d× GPU", info.GPUCount))
+	}
+	fmt.Fprintf(&b, "Inventory scan %s: %s", info.CollectedAt.Format("2006-01-02"), strings.Join(parts, ", "))
+	for _, issue := range issues {
+		fmt.Fprintf(&b, "; %s %s health %s", issue.Component, issue.Name, issue.Health)
+	}
+	b.WriteString("\n")
+
+	if len(changes) > 0 {
+		b.WriteString("\nChanged:\n")
+		for _, ch := range changes {
+			fmt.Fprintf(&b, "- %s: %s → %s\n", ch.Field, ch.Old, ch.New)
+		}
+	}
+	return b.String()
+}
+
+// changedFields compares the custom fields about to be written with the
+// device's current values. Fields without a previous value (first sync) and
+// the fields changing on every sync are not reported.
+func (c *Client) changedFields(current, fields map[string]interface{}) []fieldChange {
+	var changes []fieldChange
+	for name, value := range fields {
+		if c.volatileField(name) {
+			continue
+		}
+		old, ok := current[name]
+		if !ok || old == nil {
+			continue
+		}
+		if o, n := fmt.Sprint(old), fmt.Sprint(value); o != n {
+			changes = append(changes, fieldChange{Field: name, Old: o, New: n})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes
+}
@@ -0,0 +1,62 @@
+package netbox
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/config"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+func TestClient_SyncServerInfo_Journal(t *testing.T) {
+	var entry map[string]interface{}
+	server := mockNetBoxServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/api/extras/journal-entries/":
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&entry))
+			w.Write([]byte("{}"))
+		case r.Method == http.MethodPatch:
+			w.Write([]byte("{}"))
+		case r.URL.Path == "/api/dcim/devices/":
+			json.NewEncoder(w).Encode(DeviceList{Count: 1, Results: []Device{{
+				ID: 42,
+				CustomFields: map[string]interface{}{
+					"hw_ram_total_gb":   float64(256),
+					"hw_cpu_count":      float64(2),
+					"hw_last_inventory": "2025-01-03T00:00:00Z",
+					"hw_last_scan_id":   "previous-run",
+				},
+			}}})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	})
+	defer server.Close()
+
+	client := NewClient(config.NetBoxConfig{URL: server.URL, Token: "test-token", Journal: true, SyncLastScanID: true})
+
+	info := models.ServerInfo{
+		Host:           "10.0.0.1",
+		ServiceTag:     "ABC123",
+		CollectedAt:    time.Date(2025, 1, 10, 8, 0, 0, 0, time.UTC),
+		RunID:          "this-run",
+		CPUCount:       2,
+		TotalMemoryGiB: 512,
+		DriveCount:     8,
+		Drives:         []models.DriveInfo{{Name: "Disk.Bay.3", Health: models.HealthWarning}},
+	}
+	require.NoError(t, client.SyncServerInfo(context.Background(), info))
+
+	require.NotNil(t, entry)
+	assert.Equal(t, "dcim.device", entry["assigned_object_type"])
+	assert.Equal(t, float64(42), entry["assigned_object_id"])
+	assert.Equal(t, "warning", entry["kind"])
+	assert.Equal(t, "Inventory scan 2025-01-10: 2× CPU, 512 GiB, 8 drives; drive Disk.Bay.3 health Warning\n\n"+
+		"Changed:\n- hw_ram_total_gb: 256 → 512\n", entry["comments"])
+}
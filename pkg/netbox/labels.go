@@ -0,0 +1,102 @@
+package netbox
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/defaults"
+)
+
+// labelTagCache holds the IDs of the label tags by slug, so each tag is
+// looked up (or created) once per client.
+type labelTagCache struct {
+	mu  sync.Mutex
+	ids map[string]int
+}
+
+func newLabelTagCache() *labelTagCache {
+	return &labelTagCache{ids: make(map[string]int)}
+}
+
+// labelTagID returns the ID of the tag of a label, creating the tag if
+// needed. The cache is locked while looking up, so parallel syncs do not
+// create the same tag twice.
+func (c *Client) labelTagID(ctx context.Context, name string) (int, error) {
+	c.labelTags.mu.Lock()
+	defer c.labelTags.mu.Unlock()
+
+	slug := slugify(name)
+	if id, ok := c.labelTags.ids[slug]; ok {
+		return id, nil
+	}
+	id, err := c.findOrCreate(ctx, defaults.NetBoxTagsPath, url.Values{"slug": {slug}},
+		map[string]interface{}{"name": name, "slug": slug})
+	if err != nil {
+		return 0, fmt.Errorf("tag %q: %w", name, err)
+	}
+	c.labelTags.ids[slug] = id
+	return id, nil
+}
+
+// SyncLabelTags tags the device with a "key:value" tag per label. Tags of
+// the same keys with other values, left from earlier values of the labels,
+// are removed; the other tags of the device are kept.
+func (c *Client) SyncLabelTags(ctx context.Context, device *Device, labels map[string]string) error {
+	if len(labels) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	want := make(map[int]bool, len(keys))
+	var order []int
+	for _, k := range keys {
+		id, err := c.labelTagID(ctx, k+":"+labels[k])
+		if err != nil {
+			return err
+		}
+		want[id] = true
+		order = append(order, id)
+	}
+
+	changed := false
+	has := make(map[int]bool)
+	tags := []map[string]int{}
+	for _, t := range device.Tags {
+		key, _, ok := strings.Cut(t.Name, ":")
+		if _, labeled := labels[key]; ok && labeled && !want[t.ID] {
+			changed = true
+			continue
+		}
+		has[t.ID] = true
+		tags = append(tags, map[string]int{"id": t.ID})
+	}
+	for _, id := range order {
+		if !has[id] {
+			changed = true
+			tags = append(tags, map[string]int{"id": id})
+		}
+	}
+	if !changed {
+		return nil
+	}
+
+	path := fmt.Sprintf("%s%d/", defaults.NetBoxDevicesPath, device.ID)
+	if err := c.request(ctx, http.MethodPatch, path, map[string]interface{}{"tags": tags}, nil); err != nil {
+		return fmt.Errorf("failed to tag device %d: %w", device.ID, err)
+	}
+
+	c.logger.Infow("device label tags updated",
+		"device_id", device.ID,
+		"labels", len(labels),
+	)
+	return nil
+}
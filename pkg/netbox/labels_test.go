@@ -0,0 +1,64 @@
+package netbox
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/config"
+)
+
+func TestClient_SyncLabelTags(t *testing.T) {
+	var created []interface{}
+	lookups := 0
+	var patched interface{}
+	server := mockNetBoxServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/extras/tags/" && r.Method == http.MethodGet:
+			lookups++
+			if r.URL.Query().Get("slug") == "datacenter-fra1" {
+				json.NewEncoder(w).Encode(objectList{Count: 1, Results: []objectRef{{ID: 11}}})
+				return
+			}
+			json.NewEncoder(w).Encode(objectList{Results: []objectRef{}})
+		case r.URL.Path == "/api/extras/tags/" && r.Method == http.MethodPost:
+			var body map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			created = append(created, body["slug"])
+			json.NewEncoder(w).Encode(objectRef{ID: 12})
+		case r.URL.Path == "/api/dcim/devices/1/" && r.Method == http.MethodPatch:
+			var body map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			patched = body["tags"]
+			json.NewEncoder(w).Encode(objectRef{ID: 1})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		}
+	})
+	defer server.Close()
+
+	client := NewClient(config.NetBoxConfig{URL: server.URL, Token: "test-token", SyncLabelTags: true})
+	labels := map[string]string{"datacenter": "fra1", "environment": "prod"}
+	device := &Device{ID: 1, Tags: []namedRef{
+		{ID: 3, Name: "gpu", Slug: "gpu"},
+		{ID: 9, Name: "environment:staging", Slug: "environment-staging"},
+	}}
+
+	require.NoError(t, client.SyncLabelTags(context.Background(), device, labels))
+	assert.Equal(t, []interface{}{"environment-prod"}, created)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"id": float64(3)},
+		map[string]interface{}{"id": float64(11)},
+		map[string]interface{}{"id": float64(12)},
+	}, patched, "the tag of the old environment is replaced, other tags are kept")
+
+	patched = nil
+	device.Tags = []namedRef{{ID: 11, Name: "datacenter:fra1"}, {ID: 12, Name: "environment:prod"}}
+	require.NoError(t, client.SyncLabelTags(context.Background(), device, labels))
+	assert.Nil(t, patched, "a tagged device is not patched")
+	assert.Equal(t, 2, lookups, "tag IDs are looked up once per client")
+}
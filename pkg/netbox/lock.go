@@ -0,0 +1,196 @@
+package netbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/defaults"
+	"github.com/braunma/idrac-netbox-importer/pkg/errors"
+)
+
+// lockLease is the lease kept in the description of the lock tag.
+type lockLease struct {
+	Holder  string    `json:"holder"`
+	Expires time.Time `json:"expires"`
+}
+
+// lockTag is a lock tag as returned by NetBox.
+type lockTag struct {
+	ID          int    `json:"id"`
+	Description string `json:"description"`
+}
+
+// Lock is an advisory lock kept in NetBox, so that concurrent sync runs do
+// not interleave their writes. The lock is a tag named after the lock whose
+// description holds the lease. Creating the tag is atomic because tag slugs
+// are unique; releasing the lock deletes it. A lease that expired, e.g. of
+// a run that was killed, is taken over.
+//
+// Lock implements leader.Elector.
+type Lock struct {
+	client   *Client
+	name     string
+	identity string
+	ttl      time.Duration
+
+	id     int       // ID of the tag while held
+	holder lockLease // lease of the other holder after a failed Acquire
+}
+
+// NewLock returns the lock name (a slug) for identity with a lease of ttl.
+func (c *Client) NewLock(name, identity string, ttl time.Duration) *Lock {
+	return &Lock{client: c, name: name, identity: identity, ttl: ttl}
+}
+
+// Acquire takes the lock, or renews it if this identity holds it already.
+// It returns false if another holder's lease has not expired yet.
+func (l *Lock) Acquire(ctx context.Context) (bool, error) {
+	for attempt := 0; attempt < 2; attempt++ {
+		created, err := l.create(ctx)
+		if err == nil {
+			l.id = created
+			return true, nil
+		}
+		if !tagExists(err) {
+			return false, fmt.Errorf("failed to create lock %s: %w", l.name, err)
+		}
+
+		// The tag exists (the slug is taken): check its lease
+		tag, found, err := l.find(ctx)
+		if err != nil {
+			return false, err
+		}
+		if !found {
+			continue // released in between
+		}
+
+		var lease lockLease
+		if json.Unmarshal([]byte(tag.Description), &lease) != nil {
+			lease = lockLease{} // not a lease: treated as expired
+		}
+		switch {
+		case lease.Holder == l.identity:
+			if err := l.renew(ctx, tag.ID); err != nil {
+				return false, err
+			}
+			l.id = tag.ID
+			return true, nil
+		case time.Now().Before(lease.Expires):
+			l.holder = lease
+			return false, nil
+		}
+
+		l.client.logger.Infow("taking over expired NetBox lock",
+			"lock", l.name,
+			"holder", lease.Holder,
+			"expired", lease.Expires,
+		)
+		if err := l.delete(ctx, tag.ID); err != nil {
+			return false, err
+		}
+	}
+	// Another run took over the expired lease first
+	return false, nil
+}
+
+// Renew extends the lease of the held lock, so a sync that runs longer than
+// the TTL keeps it. It fails if the lock is not held or was taken over.
+func (l *Lock) Renew(ctx context.Context) error {
+	if l.id == 0 {
+		return fmt.Errorf("lock %s is not held", l.name)
+	}
+	return l.renew(ctx, l.id)
+}
+
+// Release deletes the lock if this identity holds it.
+func (l *Lock) Release(ctx context.Context) error {
+	if l.id == 0 {
+		return nil
+	}
+	err := l.delete(ctx, l.id)
+	l.id = 0
+	return err
+}
+
+// Holder returns the identity and lease expiry of the run that held the
+// lock when Acquire last returned false.
+func (l *Lock) Holder() (string, time.Time) {
+	return l.holder.Holder, l.holder.Expires
+}
+
+// lease returns the description of the lock tag for a new lease.
+func (l *Lock) lease() string {
+	data, _ := json.Marshal(lockLease{Holder: l.identity, Expires: time.Now().Add(l.ttl).UTC().Truncate(time.Second)})
+	return string(data)
+}
+
+// create creates the lock tag and returns its ID.
+func (l *Lock) create(ctx context.Context) (int, error) {
+	var created lockTag
+	body := map[string]interface{}{"name": l.name, "slug": l.name, "description": l.lease()}
+	if err := l.client.request(ctx, http.MethodPost, defaults.NetBoxTagsPath, body, &created); err != nil {
+		return 0, err
+	}
+	return created.ID, nil
+}
+
+// tagExists reports whether err is NetBox's unique-constraint error for the
+// name or slug of a new tag, e.g. {"slug": ["tag with this slug already
+// exists."]}. Other 400 responses, such as an invalid slug, are no held lock.
+func tagExists(err error) bool {
+	if errors.NetBoxStatus(err) != http.StatusBadRequest {
+		return false
+	}
+	var fields map[string][]string
+	if json.Unmarshal([]byte(errors.NetBoxMessage(err)), &fields) != nil {
+		return false
+	}
+	for _, field := range []string{"slug", "name"} {
+		for _, msg := range fields[field] {
+			if strings.Contains(msg, "already exists") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// find returns the lock tag, if it exists.
+func (l *Lock) find(ctx context.Context) (lockTag, bool, error) {
+	var list struct {
+		Results []lockTag `json:"results"`
+	}
+	path := defaults.NetBoxTagsPath + "?" + url.Values{"slug": {l.name}}.Encode()
+	if err := l.client.request(ctx, http.MethodGet, path, nil, &list); err != nil {
+		return lockTag{}, false, fmt.Errorf("failed to read lock %s: %w", l.name, err)
+	}
+	if len(list.Results) == 0 {
+		return lockTag{}, false, nil
+	}
+	return list.Results[0], true, nil
+}
+
+// renew extends the lease of the lock tag id.
+func (l *Lock) renew(ctx context.Context, id int) error {
+	path := fmt.Sprintf("%s%d/", defaults.NetBoxTagsPath, id)
+	if err := l.client.request(ctx, http.MethodPatch, path, map[string]interface{}{"description": l.lease()}, nil); err != nil {
+		return fmt.Errorf("failed to renew lock %s: %w", l.name, err)
+	}
+	return nil
+}
+
+// delete deletes the lock tag id. A tag that is gone already (taken over
+// after the lease expired) is not an error.
+func (l *Lock) delete(ctx context.Context, id int) error {
+	path := fmt.Sprintf("%s%d/", defaults.NetBoxTagsPath, id)
+	err := l.client.request(ctx, http.MethodDelete, path, nil, nil)
+	if err != nil && errors.NetBoxStatus(err) != http.StatusNotFound {
+		return fmt.Errorf("failed to delete lock %s: %w", l.name, err)
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package netbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/config"
+)
+
+// tagStore serves the tags endpoint with unique slugs, like NetBox.
+type tagStore struct {
+	mu     sync.Mutex
+	nextID int
+	tags   map[int]map[string]interface{}
+}
+
+func newTagStore() *tagStore {
+	return &tagStore{nextID: 1, tags: map[int]map[string]interface{}{}}
+}
+
+func (s *tagStore) description(slug string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, tag := range s.tags {
+		if tag["slug"] == slug {
+			return tag["description"].(string)
+		}
+	}
+	return ""
+}
+
+func (s *tagStore) put(slug, description string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tags[s.nextID] = map[string]interface{}{"id": s.nextID, "slug": slug, "description": description}
+	s.nextID++
+}
+
+func (s *tagStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var id int
+	fmt.Sscanf(strings.TrimPrefix(r.URL.Path, "/api/extras/tags/"), "%d/", &id)
+
+	switch r.Method {
+	case http.MethodGet:
+		var results []map[string]interface{}
+		for _, tag := range s.tags {
+			if tag["slug"] == r.URL.Query().Get("slug") {
+				results = append(results, tag)
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"count": len(results), "results": results})
+	case http.MethodPost:
+		var tag map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&tag)
+		for _, existing := range s.tags {
+			if existing["slug"] == tag["slug"] {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"slug": ["tag with this slug already exists."]}`))
+				return
+			}
+		}
+		tag["id"] = s.nextID
+		s.tags[s.nextID] = tag
+		s.nextID++
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(tag)
+	case http.MethodPatch:
+		tag, ok := s.tags[id]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&tag)
+		json.NewEncoder(w).Encode(tag)
+	case http.MethodDelete:
+		if _, ok := s.tags[id]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		delete(s.tags, id)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func TestLock(t *testing.T) {
+	store := newTagStore()
+	server := mockNetBoxServer(t, store.ServeHTTP)
+	defer server.Close()
+	client := NewClient(config.NetBoxConfig{URL: server.URL, Token: "test-token"})
+	ctx := context.Background()
+
+	first := client.NewLock("sync-lock", "host-a/1", time.Hour)
+	second := client.NewLock("sync-lock", "host-b/2", time.Hour)
+
+	ok, err := first.Acquire(ctx)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Contains(t, store.description("sync-lock"), `"holder":"host-a/1"`)
+
+	ok, err = second.Acquire(ctx)
+	require.NoError(t, err)
+	assert.False(t, ok, "held by the first run")
+	holder, expires := second.Holder()
+	assert.Equal(t, "host-a/1", holder)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), expires, time.Minute)
+
+	ok, err = first.Acquire(ctx)
+	require.NoError(t, err)
+	assert.True(t, ok, "renewed by the holder")
+
+	require.NoError(t, second.Release(ctx), "releasing a lock that is not held is a no-op")
+	assert.NotEmpty(t, store.description("sync-lock"))
+
+	require.NoError(t, first.Release(ctx))
+	assert.Empty(t, store.description("sync-lock"))
+
+	ok, err = second.Acquire(ctx)
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestLock_TakesOverExpiredLease(t *testing.T) {
+	store := newTagStore()
+	store.put("sync-lock", `{"holder":"host-a/1","expires":"2020-01-01T00:00:00Z"}`)
+	server := mockNetBoxServer(t, store.ServeHTTP)
+	defer server.Close()
+	client := NewClient(config.NetBoxConfig{URL: server.URL, Token: "test-token"})
+
+	lock := client.NewLock("sync-lock", "host-b/2", time.Hour)
+	ok, err := lock.Acquire(context.Background())
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Contains(t, store.description("sync-lock"), `"holder":"host-b/2"`)
+}
+
+func TestLock_Errors(t *testing.T) {
+	server := mockNetBoxServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	})
+	defer server.Close()
+	client := NewClient(config.NetBoxConfig{URL: server.URL, Token: "test-token"})
+
+	ok, err := client.NewLock("sync-lock", "host-a/1", time.Hour).Acquire(context.Background())
+	assert.False(t, ok)
+	assert.ErrorContains(t, err, "failed to create lock sync-lock")
+}
+
+func TestLock_BadRequestIsNoHeldLock(t *testing.T) {
+	server := mockNetBoxServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method, "no lease lookup for a rejected tag")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"slug": ["Enter a valid \"slug\" consisting of letters, numbers, underscores or hyphens."]}`))
+	})
+	defer server.Close()
+	client := NewClient(config.NetBoxConfig{URL: server.URL, Token: "test-token"})
+
+	ok, err := client.NewLock("sync lock", "host-a/1", time.Hour).Acquire(context.Background())
+	assert.False(t, ok)
+	assert.ErrorContains(t, err, "failed to create lock sync lock")
+	assert.ErrorContains(t, err, "valid")
+}
+
+func TestLock_Renew(t *testing.T) {
+	store := newTagStore()
+	server := mockNetBoxServer(t, store.ServeHTTP)
+	defer server.Close()
+	client := NewClient(config.NetBoxConfig{URL: server.URL, Token: "test-token"})
+	ctx := context.Background()
+
+	lock := client.NewLock("sync-lock", "host-a/1", time.Hour)
+	assert.ErrorContains(t, lock.Renew(ctx), "not held")
+
+	ok, err := lock.Acquire(ctx)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// Age the lease, as if the sync ran for most of the TTL
+	store.mu.Lock()
+	for _, tag := range store.tags {
+		tag["description"] = `{"holder":"host-a/1","expires":"2020-01-01T00:00:00Z"}`
+	}
+	store.mu.Unlock()
+
+	require.NoError(t, lock.Renew(ctx))
+	var lease lockLease
+	require.NoError(t, json.Unmarshal([]byte(store.description("sync-lock")), &lease))
+	assert.WithinDuration(t, time.Now().Add(time.Hour), lease.Expires, time.Minute)
+}
@@ -0,0 +1,87 @@
+package netbox
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+// deviceIndex maps the asset tags and serials of listed devices to the
+// devices. A key shared by several devices maps to the first one, like the
+// first search result.
+type deviceIndex map[string]*Device
+
+func newDeviceIndex(devices []Device) deviceIndex {
+	index := make(deviceIndex, 2*len(devices))
+	for i := range devices {
+		for _, k := range deviceKeys(&devices[i]) {
+			if _, ok := index[k.String()]; !ok {
+				index[k.String()] = &devices[i]
+			}
+		}
+	}
+	return index
+}
+
+// find returns the device of a server, trying the keys in the order
+// findDevice searches, or nil.
+func (x deviceIndex) find(info models.ServerInfo) *Device {
+	for _, k := range lookupKeys(info) {
+		if d, ok := x[k.String()]; ok {
+			return d
+		}
+	}
+	return nil
+}
+
+// bulkMatch lists the devices once per client and matches servers against
+// them locally (netbox.match.strategy bulk).
+type bulkMatch struct {
+	query url.Values
+
+	once  sync.Once
+	index deviceIndex
+	err   error
+}
+
+// matchDevice returns the device of a server from the bulk listing, listing
+// the devices on first use. A server not in the listing has no device.
+func (c *Client) matchDevice(ctx context.Context, info models.ServerInfo) (*Device, error) {
+	c.bulk.once.Do(func() {
+		start := time.Now()
+		devices, err := c.listDevicesParallel(ctx, c.bulk.query)
+		if err != nil {
+			c.bulk.err = fmt.Errorf("failed to list devices for matching: %w", err)
+			return
+		}
+		c.bulk.index = newDeviceIndex(devices)
+		c.logger.Infow("listed devices for matching",
+			"devices", len(devices),
+			"filter", c.bulk.query.Encode(),
+			"duration", time.Since(start),
+		)
+	})
+	if c.bulk.err != nil {
+		return nil, c.bulk.err
+	}
+
+	device := c.bulk.index.find(info)
+	if device == nil {
+		c.logger.Debugw("device not in device listing",
+			"host", info.Host,
+			"service_tag", info.ServiceTag,
+			"serial_number", info.SerialNumber,
+		)
+		return nil, nil
+	}
+	c.logger.Debugw("device matched from device listing",
+		"host", info.Host,
+		"device_id", device.ID,
+		"device_name", device.Name,
+	)
+	return device, nil
+}
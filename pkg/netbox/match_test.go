@@ -0,0 +1,97 @@
+package netbox
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/config"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+func TestDeviceIndex_Find(t *testing.T) {
+	index := newDeviceIndex([]Device{
+		{ID: 1, AssetTag: "SVC1", Serial: "SN-1"},
+		{ID: 2, Serial: "svc2"},
+		{ID: 3, Serial: "SN-1"},
+	})
+
+	assert.Equal(t, 1, index.find(models.ServerInfo{ServiceTag: "svc1"}).ID)
+	assert.Equal(t, 2, index.find(models.ServerInfo{ServiceTag: "SVC2"}).ID, "a service tag matches the serial too")
+	assert.Equal(t, 1, index.find(models.ServerInfo{SerialNumber: "SN-1"}).ID, "the first listed device wins")
+	assert.Nil(t, index.find(models.ServerInfo{ServiceTag: "SVC9", SerialNumber: "SN-9"}))
+	assert.Nil(t, index.find(models.ServerInfo{}))
+}
+
+func TestClient_BulkMatch(t *testing.T) {
+	const total = devicePageSize + 10
+	var mu sync.Mutex
+	var lists, searches int
+	server := mockNetBoxServer(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/api/dcim/devices/" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		mu.Lock()
+		if q.Has("asset_tag") || q.Has("serial") {
+			searches++
+		} else {
+			lists++
+		}
+		mu.Unlock()
+		assert.Equal(t, "dell", q.Get("manufacturer"))
+
+		offset, _ := strconv.Atoi(q.Get("offset"))
+		list := DeviceList{Count: total, Results: []Device{}}
+		for id := offset + 1; id <= min(offset+devicePageSize, total); id++ {
+			list.Results = append(list.Results, Device{ID: id, AssetTag: "SVC" + strconv.Itoa(id)})
+		}
+		json.NewEncoder(w).Encode(list)
+	})
+	defer server.Close()
+
+	client := NewClient(config.NetBoxConfig{
+		URL:   server.URL,
+		Token: "test-token",
+		Match: config.NetBoxMatchConfig{Strategy: config.NetBoxMatchBulk},
+	})
+	ctx := context.Background()
+
+	for _, id := range []int{3, 505, 42} {
+		device, err := client.findDevice(ctx, models.ServerInfo{ServiceTag: "SVC" + strconv.Itoa(id)})
+		require.NoError(t, err)
+		require.NotNil(t, device)
+		assert.Equal(t, id, device.ID)
+	}
+
+	device, err := client.findDevice(ctx, models.ServerInfo{ServiceTag: "OTHER"})
+	require.NoError(t, err)
+	assert.Nil(t, device, "devices outside the listing are not searched")
+
+	assert.Equal(t, 2, lists)
+	assert.Zero(t, searches)
+}
+
+func TestClient_BulkMatch_ListFails(t *testing.T) {
+	server := mockNetBoxServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	defer server.Close()
+
+	client := NewClient(config.NetBoxConfig{
+		URL:   server.URL,
+		Token: "test-token",
+		Match: config.NetBoxMatchConfig{Strategy: config.NetBoxMatchBulk, Filter: "site=dc1"},
+	})
+
+	_, err := client.findDevice(context.Background(), models.ServerInfo{ServiceTag: "SVC1"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to list devices for matching")
+}
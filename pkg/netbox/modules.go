@@ -0,0 +1,174 @@
+package netbox
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/defaults"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+// objectRef is the subset of a NetBox object needed to reference it.
+type objectRef struct {
+	ID int `json:"id"`
+}
+
+// objectList is a paginated NetBox list of object references.
+type objectList struct {
+	Count   int         `json:"count"`
+	Results []objectRef `json:"results"`
+}
+
+// Module represents a NetBox module installed in a module bay.
+type Module struct {
+	ID         int       `json:"id"`
+	Serial     string    `json:"serial"`
+	ModuleType objectRef `json:"module_type"`
+}
+
+// moduleList is a paginated list of modules.
+type moduleList struct {
+	Count   int      `json:"count"`
+	Results []Module `json:"results"`
+}
+
+// SyncModules creates a module bay for every OCP mezzanine and riser slot of
+// the server and installs a module for the detected card. Module types and
+// manufacturers are created on demand. Modules in bays that are now empty
+// are left untouched.
+func (c *Client) SyncModules(ctx context.Context, deviceID int, info models.ServerInfo) error {
+	for _, slot := range info.ModuleSlots() {
+		bayID, err := c.ensureModuleBay(ctx, deviceID, slot)
+		if err != nil {
+			return fmt.Errorf("module bay %q: %w", slot.Label, err)
+		}
+
+		if slot.Card == nil || slot.Card.CardModel() == "" {
+			continue
+		}
+
+		typeID, err := c.ensureModuleType(ctx, *slot.Card)
+		if err != nil {
+			return fmt.Errorf("module type %q: %w", slot.Card.CardModel(), err)
+		}
+		if err := c.ensureModule(ctx, deviceID, bayID, typeID, slot.Card.SerialNumber); err != nil {
+			return fmt.Errorf("module in bay %q: %w", slot.Label, err)
+		}
+	}
+	return nil
+}
+
+// ensureModuleBay returns the ID of the device's module bay for slot, creating it if needed.
+func (c *Client) ensureModuleBay(ctx context.Context, deviceID int, slot models.PCIeSlotInfo) (int, error) {
+	query := url.Values{"device_id": {fmt.Sprint(deviceID)}, "name": {slot.Label}}
+	return c.findOrCreate(ctx, defaults.NetBoxModuleBaysPath, query, map[string]interface{}{
+		"device":      deviceID,
+		"name":        slot.Label,
+		"description": moduleBayDescription(slot),
+	})
+}
+
+// ensureModuleType returns the ID of the module type for card, creating it
+// (and its manufacturer) if needed.
+func (c *Client) ensureModuleType(ctx context.Context, card models.PCIeCardInfo) (int, error) {
+	manufacturer := card.Manufacturer
+	if manufacturer == "" {
+		manufacturer = "Unknown"
+	}
+	manufacturerID, err := c.findOrCreate(ctx, defaults.NetBoxManufacturersPath,
+		url.Values{"name": {manufacturer}},
+		map[string]interface{}{"name": manufacturer, "slug": slugify(manufacturer)})
+	if err != nil {
+		return 0, fmt.Errorf("manufacturer %q: %w", manufacturer, err)
+	}
+
+	create := map[string]interface{}{
+		"manufacturer": manufacturerID,
+		"model":        card.CardModel(),
+	}
+	if card.PartNumber != "" {
+		create["part_number"] = card.PartNumber
+	}
+	return c.findOrCreate(ctx, defaults.NetBoxModuleTypesPath,
+		url.Values{"manufacturer_id": {fmt.Sprint(manufacturerID)}, "model": {card.CardModel()}},
+		create)
+}
+
+// ensureModule installs a module of the given type in the bay, or updates
+// the installed module if its type or serial changed.
+func (c *Client) ensureModule(ctx context.Context, deviceID, bayID, typeID int, serial string) error {
+	query := url.Values{"device_id": {fmt.Sprint(deviceID)}, "module_bay_id": {fmt.Sprint(bayID)}}
+	var existing moduleList
+	if err := c.request(ctx, http.MethodGet, defaults.NetBoxModulesPath+"?"+query.Encode(), nil, &existing); err != nil {
+		return err
+	}
+
+	if existing.Count == 0 {
+		return c.request(ctx, http.MethodPost, defaults.NetBoxModulesPath, map[string]interface{}{
+			"device":      deviceID,
+			"module_bay":  bayID,
+			"module_type": typeID,
+			"serial":      serial,
+			"status":      "active",
+		}, nil)
+	}
+
+	mod := existing.Results[0]
+	if mod.ModuleType.ID == typeID && mod.Serial == serial {
+		return nil
+	}
+	return c.request(ctx, http.MethodPatch, fmt.Sprintf("%s%d/", defaults.NetBoxModulesPath, mod.ID),
+		map[string]interface{}{"module_type": typeID, "serial": serial}, nil)
+}
+
+// findOrCreate returns the ID of the first object at path matching query,
+// or creates one from create.
+func (c *Client) findOrCreate(ctx context.Context, path string, query url.Values, create map[string]interface{}) (int, error) {
+	var list objectList
+	if err := c.request(ctx, http.MethodGet, path+"?"+query.Encode(), nil, &list); err != nil {
+		return 0, err
+	}
+	if list.Count > 0 && len(list.Results) > 0 {
+		return list.Results[0].ID, nil
+	}
+
+	var created objectRef
+	if err := c.request(ctx, http.MethodPost, path, create, &created); err != nil {
+		return 0, err
+	}
+	c.logger.Infow("created NetBox object",
+		"path", path,
+		"id", created.ID,
+	)
+	return created.ID, nil
+}
+
+// moduleBayDescription describes the slot, e.g. "OCP mezzanine (OCP3Small, Gen4 x16)".
+func moduleBayDescription(slot models.PCIeSlotInfo) string {
+	kind := "Riser slot"
+	if slot.ModuleKind() == models.ModuleKindOCPMezzanine {
+		kind = "OCP mezzanine"
+	}
+	var details []string
+	if slot.SlotType != "" {
+		details = append(details, slot.SlotType)
+	}
+	if slot.PCIeType != "" && slot.Lanes > 0 {
+		details = append(details, fmt.Sprintf("%s x%d", slot.PCIeType, slot.Lanes))
+	}
+	if len(details) == 0 {
+		return kind
+	}
+	return fmt.Sprintf("%s (%s)", kind, strings.Join(details, ", "))
+}
+
+var slugInvalid = regexp.MustCompile(`[^a-z0-9]+`)
+
+// slugify converts a name into a NetBox slug ("Broadcom Inc." -> "broadcom-inc").
+func slugify(name string) string {
+	return strings.Trim(slugInvalid.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
@@ -0,0 +1,58 @@
+package netbox
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/config"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+func TestClient_SyncModules(t *testing.T) {
+	created := map[string][]map[string]interface{}{}
+	server := mockNetBoxServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			var body map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			created[r.URL.Path] = append(created[r.URL.Path], body)
+			json.NewEncoder(w).Encode(objectRef{ID: len(created[r.URL.Path]) + 100})
+			return
+		}
+		// Nothing exists yet
+		json.NewEncoder(w).Encode(objectList{})
+	})
+	defer server.Close()
+
+	client := NewClient(config.NetBoxConfig{URL: server.URL, Token: "test-token"})
+
+	info := models.ServerInfo{PCIeSlots: []models.PCIeSlotInfo{
+		{Label: "Slot 1", Occupied: true, Card: &models.PCIeCardInfo{Name: "Broadcom 57414"}},
+		{Label: "OCP 1", SlotType: "OCP3Small", PCIeType: "Gen4", Lanes: 16, Occupied: true, Card: &models.PCIeCardInfo{
+			Name: "Broadcom 57504 Quad Port", Manufacturer: "Broadcom Inc.", Model: "BCM57504", SerialNumber: "SN123",
+		}},
+		{Label: "Riser 2 Slot 4"},
+	}}
+
+	require.NoError(t, client.SyncModules(context.Background(), 7, info))
+
+	bays := created["/api/dcim/module-bays/"]
+	require.Len(t, bays, 2, "plain slots are not modelled")
+	assert.Equal(t, "OCP 1", bays[0]["name"])
+	assert.Equal(t, "OCP mezzanine (OCP3Small, Gen4 x16)", bays[0]["description"])
+	assert.Equal(t, "Riser 2 Slot 4", bays[1]["name"])
+
+	require.Len(t, created["/api/dcim/manufacturers/"], 1)
+	assert.Equal(t, "broadcom-inc", created["/api/dcim/manufacturers/"][0]["slug"])
+	require.Len(t, created["/api/dcim/module-types/"], 1)
+	assert.Equal(t, "BCM57504", created["/api/dcim/module-types/"][0]["model"])
+
+	modules := created["/api/dcim/modules/"]
+	require.Len(t, modules, 1, "empty bays get no module")
+	assert.Equal(t, "SN123", modules[0]["serial"])
+	assert.Equal(t, float64(7), modules[0]["device"])
+}
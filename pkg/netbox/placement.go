@@ -0,0 +1,97 @@
+package netbox
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/defaults"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+// SyncPlacement moves the device to the site, rack, position and tenant of
+// the placement. Sites, racks and tenants are looked up by name or slug and
+// must already exist. Only attributes that are set in the placement and
+// differ from the device are changed.
+func (c *Client) SyncPlacement(ctx context.Context, device *Device, p models.Placement) error {
+	patch := map[string]interface{}{}
+
+	if p.Site != "" {
+		siteID, err := c.findByNameOrSlug(ctx, defaults.NetBoxSitesPath, p.Site, nil)
+		if err != nil {
+			return fmt.Errorf("site %q: %w", p.Site, err)
+		}
+		if device.Site == nil || device.Site.ID != siteID {
+			patch["site"] = siteID
+		}
+
+		if p.Rack != "" {
+			rackID, err := c.findByNameOrSlug(ctx, defaults.NetBoxRacksPath, p.Rack,
+				url.Values{"site_id": {fmt.Sprint(siteID)}})
+			if err != nil {
+				return fmt.Errorf("rack %q in site %q: %w", p.Rack, p.Site, err)
+			}
+			if device.Rack == nil || device.Rack.ID != rackID {
+				patch["rack"] = rackID
+			}
+		}
+	}
+
+	if p.Position > 0 {
+		if device.Position == nil || *device.Position != p.Position {
+			patch["position"] = p.Position
+		}
+		if device.Face == nil || device.Face.Value != p.Face {
+			patch["face"] = p.Face
+		}
+	}
+
+	if p.Tenant != "" {
+		tenantID, err := c.findByNameOrSlug(ctx, defaults.NetBoxTenantsPath, p.Tenant, nil)
+		if err != nil {
+			return fmt.Errorf("tenant %q: %w", p.Tenant, err)
+		}
+		if device.Tenant == nil || device.Tenant.ID != tenantID {
+			patch["tenant"] = tenantID
+		}
+	}
+
+	if len(patch) == 0 {
+		return nil
+	}
+
+	path := fmt.Sprintf("%s%d/", defaults.NetBoxDevicesPath, device.ID)
+	if err := c.request(ctx, http.MethodPatch, path, patch, nil); err != nil {
+		return fmt.Errorf("failed to update placement of device %d: %w", device.ID, err)
+	}
+
+	c.logger.Infow("device placement updated",
+		"device_id", device.ID,
+		"site", p.Site,
+		"rack", p.Rack,
+		"position", p.Position,
+		"tenant", p.Tenant,
+	)
+	return nil
+}
+
+// findByNameOrSlug returns the ID of the object at path whose name (or, failing
+// that, slug) is value, restricted by filter.
+func (c *Client) findByNameOrSlug(ctx context.Context, path, value string, filter url.Values) (int, error) {
+	for _, key := range []string{"name", "slug"} {
+		query := url.Values{key: {value}}
+		for k, v := range filter {
+			query[k] = v
+		}
+
+		var list objectList
+		if err := c.request(ctx, http.MethodGet, path+"?"+query.Encode(), nil, &list); err != nil {
+			return 0, err
+		}
+		if list.Count > 0 && len(list.Results) > 0 {
+			return list.Results[0].ID, nil
+		}
+	}
+	return 0, fmt.Errorf("not found in NetBox")
+}
@@ -0,0 +1,59 @@
+package netbox
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/config"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+func TestClient_SyncPlacement(t *testing.T) {
+	var patch map[string]interface{}
+	server := mockNetBoxServer(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch {
+		case r.Method == http.MethodPatch:
+			assert.Equal(t, "/api/dcim/devices/42/", r.URL.Path)
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&patch))
+			w.Write([]byte("{}"))
+		case r.URL.Path == "/api/dcim/sites/" && q.Get("slug") == "fra1":
+			json.NewEncoder(w).Encode(objectList{Count: 1, Results: []objectRef{{ID: 3}}})
+		case r.URL.Path == "/api/dcim/racks/" && q.Get("name") == "R12" && q.Get("site_id") == "3":
+			json.NewEncoder(w).Encode(objectList{Count: 1, Results: []objectRef{{ID: 12}}})
+		case r.URL.Path == "/api/tenancy/tenants/" && q.Get("name") == "ACME":
+			json.NewEncoder(w).Encode(objectList{Count: 1, Results: []objectRef{{ID: 7}}})
+		default:
+			json.NewEncoder(w).Encode(objectList{})
+		}
+	})
+	defer server.Close()
+
+	client := NewClient(config.NetBoxConfig{URL: server.URL, Token: "test-token"})
+
+	device := &Device{ID: 42, Site: &objectRef{ID: 3}}
+	p := models.Placement{Site: "fra1", Rack: "R12", Position: 20, Face: "front", Tenant: "ACME"}
+	require.NoError(t, client.SyncPlacement(context.Background(), device, p))
+	assert.Equal(t, map[string]interface{}{
+		"rack":     float64(12),
+		"position": float64(20),
+		"face":     "front",
+		"tenant":   float64(7),
+	}, patch, "unchanged site is not patched")
+
+	// Already placed: no request
+	patch = nil
+	pos := 20.0
+	device = &Device{ID: 42, Site: &objectRef{ID: 3}, Rack: &objectRef{ID: 12}, Position: &pos, Face: &choice{Value: "front"}, Tenant: &objectRef{ID: 7}}
+	require.NoError(t, client.SyncPlacement(context.Background(), device, p))
+	assert.Nil(t, patch)
+
+	err := client.SyncPlacement(context.Background(), device, models.Placement{Site: "nowhere"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `site "nowhere"`)
+}
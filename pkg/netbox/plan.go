@@ -0,0 +1,123 @@
+package netbox
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/defaults"
+	"github.com/braunma/idrac-netbox-importer/pkg/errors"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+// SyncPlan is what a sync would change in NetBox, determined without
+// writing anything.
+type SyncPlan struct {
+	// Modified lists the hosts whose device would be updated: different
+	// hardware custom fields, placement, comments, tags, device type or power
+	// port draw. The last inventory timestamp and run ID, which change on
+	// every sync, do not count.
+	Modified []string
+
+	// Created is the number of objects the sync would create (module bays,
+	// modules, module types and manufacturers). Journal entries do not count.
+	Created int
+
+	// host is the host being planned, whose device the updates modify
+	host string
+}
+
+// record counts a write that was not sent.
+func (p *SyncPlan) record(method, path string) {
+	switch method {
+	case http.MethodPost:
+		if !strings.HasPrefix(path, defaults.NetBoxJournalEntriesPath) {
+			p.Created++
+		}
+	case http.MethodPatch, http.MethodPut, http.MethodDelete:
+		p.modify(p.host)
+	}
+}
+
+// modify adds host to Modified, once.
+func (p *SyncPlan) modify(host string) {
+	if host == "" || slices.Contains(p.Modified, host) {
+		return
+	}
+	p.Modified = append(p.Modified, host)
+}
+
+// Check returns an error wrapping errors.ErrTooManyChanges if the plan
+// modifies more than maxChanges devices or creates more than maxCreates
+// objects. A limit of 0 disables the check.
+func (p SyncPlan) Check(maxChanges, maxCreates int) error {
+	if maxChanges > 0 && len(p.Modified) > maxChanges {
+		return fmt.Errorf("%w: sync would modify %d devices (netbox.max_changes: %d)",
+			errors.ErrTooManyChanges, len(p.Modified), maxChanges)
+	}
+	if maxCreates > 0 && p.Created > maxCreates {
+		return fmt.Errorf("%w: sync would create %d objects (netbox.max_creates: %d)",
+			errors.ErrTooManyChanges, p.Created, maxCreates)
+	}
+	return nil
+}
+
+// Plan runs the sync of servers like SyncAll, but only sends the lookups and
+// records the writes it would make instead of sending them.
+func (c *Client) Plan(ctx context.Context, servers []models.ServerInfo) SyncPlan {
+	var plan SyncPlan
+	dry := *c
+	dry.plan = &plan
+	dry.logger = zap.NewNop().Sugar()
+
+	c.prefetchDevices(ctx, servers)
+	duplicates := models.DuplicateHosts(models.FindDuplicates(servers))
+	synced := make(map[int]string)
+	for _, info := range servers {
+		plan.host = info.Host
+		var result SyncResult
+		if dup, ok := duplicates[info.Host]; ok && info.IsValid() {
+			result = dry.syncDuplicate(ctx, info, dup, synced)
+		} else {
+			result = dry.syncOne(ctx, info)
+		}
+		if result.Error != nil {
+			c.logger.Debugw("planned sync failed",
+				"host", info.Host,
+				"error", result.Error,
+			)
+		}
+	}
+
+	c.logger.Infow("planned NetBox sync",
+		"servers", len(servers),
+		"devices_modified", len(plan.Modified),
+		"objects_created", plan.Created,
+	)
+	return plan
+}
+
+// hardwareChanged reports whether fields differ from the device's current
+// custom fields, ignoring the fields that change on every sync.
+func (c *Client) hardwareChanged(current, fields map[string]interface{}) bool {
+	for name, value := range fields {
+		if c.volatileField(name) {
+			continue
+		}
+		old, ok := current[name]
+		if !ok || old == nil || fmt.Sprint(old) != fmt.Sprint(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// volatileField reports whether a custom field changes on every sync (the
+// last inventory timestamp and run ID), so it is no hardware change.
+func (c *Client) volatileField(name string) bool {
+	return name == c.fieldNames.LastInventory || name == c.fieldNames.LastScanID
+}
@@ -0,0 +1,116 @@
+package netbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/config"
+	"github.com/braunma/idrac-netbox-importer/pkg/errors"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+func TestClient_Plan(t *testing.T) {
+	client := NewClient(config.NetBoxConfig{Token: "test-token", Journal: true, SyncModules: true, SyncLastScanID: true})
+
+	unchanged := models.ServerInfo{Host: "10.0.0.1", ServiceTag: "AAA1111", TotalMemoryGiB: 256, CPUCount: 2, RunID: "this-run"}
+	misread := models.ServerInfo{Host: "10.0.0.2", ServiceTag: "BBB2222", TotalMemoryGiB: 0, CPUCount: 2,
+		PCIeSlots: []models.PCIeSlotInfo{{Label: "Riser 1", Card: &models.PCIeCardInfo{Model: "BOSS-N1"}}},
+	}
+	// The stored fields are from an earlier sync
+	stored := client.buildCustomFields(unchanged)
+	stored["hw_last_inventory"] = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).Format(time.RFC3339)
+	stored["hw_last_scan_id"] = "previous-run"
+	misreadStored := client.buildCustomFields(models.ServerInfo{TotalMemoryGiB: 256, CPUCount: 2})
+
+	writes := 0
+	server := mockNetBoxServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writes++
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		switch {
+		case r.URL.Path == "/api/dcim/devices/" && r.URL.Query().Get("asset_tag") == "AAA1111":
+			json.NewEncoder(w).Encode(DeviceList{Count: 1, Results: []Device{{ID: 1, CustomFields: stored}}})
+		case r.URL.Path == "/api/dcim/devices/" && r.URL.Query().Get("asset_tag") == "BBB2222":
+			json.NewEncoder(w).Encode(DeviceList{Count: 1, Results: []Device{{ID: 2, CustomFields: misreadStored}}})
+		default:
+			json.NewEncoder(w).Encode(objectList{})
+		}
+	})
+	defer server.Close()
+	client.baseURL = server.URL
+
+	plan := client.Plan(context.Background(), []models.ServerInfo{
+		unchanged,
+		misread,
+		{Host: "10.0.0.3", ServiceTag: "CCC3333"}, // not in NetBox
+		{Host: "10.0.0.4", Error: fmt.Errorf("timeout")},
+	})
+
+	assert.Zero(t, writes, "planning must not write")
+	assert.Equal(t, []string{"10.0.0.2"}, plan.Modified)
+	// module bay, manufacturer, module type and module of the riser card
+	assert.Equal(t, 4, plan.Created)
+
+	assert.NoError(t, plan.Check(1, 4))
+	assert.NoError(t, plan.Check(0, 0))
+	err := plan.Check(0, 3)
+	assert.ErrorIs(t, err, errors.ErrTooManyChanges)
+	assert.Contains(t, err.Error(), "would create 4 objects")
+
+	plan.Modified = append(plan.Modified, "10.0.0.5")
+	assert.ErrorContains(t, plan.Check(1, 0), "would modify 2 devices (netbox.max_changes: 1)")
+}
+
+func TestClient_Plan_PlacementOnly(t *testing.T) {
+	client := NewClient(config.NetBoxConfig{Token: "test-token", SyncComments: true})
+
+	var servers []models.ServerInfo
+	stored := map[string]map[string]interface{}{}
+	for i, tag := range []string{"AAA1111", "BBB2222", "CCC3333"} {
+		srv := models.ServerInfo{Host: fmt.Sprintf("10.0.0.%d", i+1), ServiceTag: tag, TotalMemoryGiB: 256, CPUCount: 2,
+			Placement: &models.Placement{Site: "fra1"}}
+		if i == 0 {
+			// Placement and comments: one modified device
+			srv.Notes = []string{"spare PSU in the rack"}
+		}
+		if i == 2 {
+			// Already placed; only the notes of the comments change
+			srv.Placement, srv.Notes = nil, []string{"decommission in Q3"}
+		}
+		servers = append(servers, srv)
+		stored[tag] = client.buildCustomFields(srv)
+	}
+
+	writes := 0
+	server := mockNetBoxServer(t, func(w http.ResponseWriter, r *http.Request) {
+		tag := r.URL.Query().Get("asset_tag")
+		switch {
+		case r.Method != http.MethodGet:
+			writes++
+			w.WriteHeader(http.StatusInternalServerError)
+		case r.URL.Path == "/api/dcim/devices/" && stored[tag] != nil:
+			json.NewEncoder(w).Encode(DeviceList{Count: 1, Results: []Device{{ID: len(tag), CustomFields: stored[tag]}}})
+		case r.URL.Path == "/api/dcim/sites/" && r.URL.Query().Get("slug") == "fra1":
+			json.NewEncoder(w).Encode(objectList{Count: 1, Results: []objectRef{{ID: 3}}})
+		default:
+			json.NewEncoder(w).Encode(objectList{})
+		}
+	})
+	defer server.Close()
+	client.baseURL = server.URL
+
+	plan := client.Plan(context.Background(), servers)
+
+	assert.Zero(t, writes, "planning must not write")
+	assert.Equal(t, []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}, plan.Modified,
+		"the hardware fields are unchanged, but placement and comments are updated")
+	assert.ErrorIs(t, plan.Check(2, 0), errors.ErrTooManyChanges)
+}
@@ -0,0 +1,181 @@
+package netbox
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/defaults"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+// PowerPort represents a NetBox device power port.
+type PowerPort struct {
+	ID                     int         `json:"id"`
+	Name                   string      `json:"name"`
+	AllocatedDraw          *int        `json:"allocated_draw"`
+	ConnectedEndpointsType string      `json:"connected_endpoints_type"`
+	ConnectedEndpoints     []objectRef `json:"connected_endpoints"`
+}
+
+// powerPortList is a paginated list of power ports.
+type powerPortList struct {
+	Count   int         `json:"count"`
+	Results []PowerPort `json:"results"`
+}
+
+// powerFeedEndpoint is the connected_endpoints_type of a port cabled to a power feed.
+const powerFeedEndpoint = "dcim.powerfeed"
+
+// measuredDraw returns the draw to record for a server in watts
+// (the peak if configured and reported, otherwise the current reading).
+func (c *Client) measuredDraw(info models.ServerInfo) int {
+	if c.powerDraw.UsePeak && info.PowerPeakWatts > 0 {
+		return info.PowerPeakWatts
+	}
+	return info.PowerConsumedWatts
+}
+
+// devicePowerPorts returns the power ports of a device, sorted by name.
+func (c *Client) devicePowerPorts(ctx context.Context, deviceID int) ([]PowerPort, error) {
+	query := url.Values{"device_id": {fmt.Sprint(deviceID)}, "limit": {"100"}}
+	var list powerPortList
+	if err := c.request(ctx, http.MethodGet, defaults.NetBoxPowerPortsPath+"?"+query.Encode(), nil, &list); err != nil {
+		return nil, err
+	}
+	sort.Slice(list.Results, func(i, j int) bool { return list.Results[i].Name < list.Results[j].Name })
+	return list.Results, nil
+}
+
+// distributeDraw splits the measured draw across the power ports. If every
+// installed PSU reports its input power and their number matches the ports,
+// the PSU readings are used in order; otherwise the draw is split evenly
+// across the connected ports (or all ports if none is connected).
+func distributeDraw(total int, psus []models.PowerSupplyInfo, ports []PowerPort) map[int]int {
+	draws := make(map[int]int)
+	if total <= 0 || len(ports) == 0 {
+		return draws
+	}
+
+	var inputs []float64
+	for _, psu := range psus {
+		if psu.IsInstalled() && psu.InputWatts > 0 {
+			inputs = append(inputs, psu.InputWatts)
+		}
+	}
+	if len(inputs) == len(ports) {
+		for i, p := range ports {
+			draws[p.ID] = int(inputs[i] + 0.5)
+		}
+		return draws
+	}
+
+	var connected []PowerPort
+	for _, p := range ports {
+		if len(p.ConnectedEndpoints) > 0 {
+			connected = append(connected, p)
+		}
+	}
+	if len(connected) == 0 {
+		connected = ports
+	}
+	share := (total + len(connected) - 1) / len(connected)
+	for _, p := range connected {
+		draws[p.ID] = share
+	}
+	return draws
+}
+
+// SyncAllocatedDraw writes the measured draw of a server into the
+// allocated_draw field of its power ports.
+func (c *Client) SyncAllocatedDraw(ctx context.Context, deviceID int, info models.ServerInfo) error {
+	total := c.measuredDraw(info)
+	if total <= 0 {
+		return nil
+	}
+
+	ports, err := c.devicePowerPorts(ctx, deviceID)
+	if err != nil {
+		return err
+	}
+	if len(ports) == 0 {
+		c.logger.Debugw("device has no power ports, skipping allocated draw",
+			"host", info.Host,
+			"device_id", deviceID,
+		)
+		return nil
+	}
+
+	draws := distributeDraw(total, info.PowerSupplies, ports)
+	for _, p := range ports {
+		draw, ok := draws[p.ID]
+		if !ok || (p.AllocatedDraw != nil && *p.AllocatedDraw == draw) {
+			continue
+		}
+		path := fmt.Sprintf("%s%d/", defaults.NetBoxPowerPortsPath, p.ID)
+		if err := c.request(ctx, http.MethodPatch, path, map[string]interface{}{"allocated_draw": draw}, nil); err != nil {
+			return fmt.Errorf("power port %s: %w", p.Name, err)
+		}
+	}
+	return nil
+}
+
+// addFeedDraws adds the measured draw per power feed over the ports of the
+// device that are cabled directly to a feed to feeds.
+func (c *Client) addFeedDraws(ctx context.Context, deviceID int, info models.ServerInfo, feeds map[int]int) error {
+	total := c.measuredDraw(info)
+	if total <= 0 {
+		return nil
+	}
+
+	ports, err := c.devicePowerPorts(ctx, deviceID)
+	if err != nil {
+		return err
+	}
+
+	draws := distributeDraw(total, info.PowerSupplies, ports)
+	for _, p := range ports {
+		if p.ConnectedEndpointsType != powerFeedEndpoint {
+			continue
+		}
+		for _, feed := range p.ConnectedEndpoints {
+			feeds[feed.ID] += draws[p.ID]
+		}
+	}
+	return nil
+}
+
+// syncFeedDraws writes the summed measured draw of the given servers into a
+// custom field of each connected power feed.
+func (c *Client) syncFeedDraws(ctx context.Context, servers []models.ServerInfo) error {
+	feeds := make(map[int]int)
+	for _, info := range servers {
+		device, err := c.findDevice(ctx, info)
+		if err != nil {
+			return err
+		}
+		if device == nil {
+			continue
+		}
+		if err := c.addFeedDraws(ctx, device.ID, info, feeds); err != nil {
+			return fmt.Errorf("%s: %w", info.Host, err)
+		}
+	}
+
+	field := c.powerDraw.GetFeedField()
+	for feedID, draw := range feeds {
+		path := fmt.Sprintf("%s%d/", defaults.NetBoxPowerFeedsPath, feedID)
+		body := map[string]interface{}{"custom_fields": map[string]interface{}{field: draw}}
+		if err := c.request(ctx, http.MethodPatch, path, body, nil); err != nil {
+			return fmt.Errorf("power feed %d: %w", feedID, err)
+		}
+	}
+
+	c.logger.Infow("power feed draw synced",
+		"feeds", len(feeds),
+		"field", field,
+	)
+	return nil
+}
@@ -0,0 +1,113 @@
+package netbox
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/config"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+func intPtr(v int) *int { return &v }
+
+func TestDistributeDraw_UsesPSUInputs(t *testing.T) {
+	ports := []PowerPort{{ID: 1, Name: "PSU1"}, {ID: 2, Name: "PSU2"}}
+	psus := []models.PowerSupplyInfo{
+		{Name: "PS1", State: "Enabled", InputWatts: 210.4},
+		{Name: "PS2", State: "Enabled", InputWatts: 189.6},
+	}
+
+	draws := distributeDraw(400, psus, ports)
+
+	assert.Equal(t, map[int]int{1: 210, 2: 190}, draws)
+}
+
+func TestDistributeDraw_SplitsAcrossConnectedPorts(t *testing.T) {
+	ports := []PowerPort{
+		{ID: 1, Name: "PSU1", ConnectedEndpoints: []objectRef{{ID: 9}}},
+		{ID: 2, Name: "PSU2"},
+		{ID: 3, Name: "PSU3", ConnectedEndpoints: []objectRef{{ID: 10}}},
+	}
+
+	draws := distributeDraw(401, nil, ports)
+
+	assert.Equal(t, map[int]int{1: 201, 3: 201}, draws)
+}
+
+func TestClient_SyncAllocatedDraw(t *testing.T) {
+	var patched []map[string]interface{}
+	server := mockNetBoxServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPatch {
+			assert.Equal(t, "/api/dcim/power-ports/2/", r.URL.Path)
+			var body map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			patched = append(patched, body)
+			json.NewEncoder(w).Encode(objectRef{ID: 2})
+			return
+		}
+		assert.Equal(t, "7", r.URL.Query().Get("device_id"))
+		json.NewEncoder(w).Encode(powerPortList{Count: 2, Results: []PowerPort{
+			{ID: 2, Name: "PSU2", AllocatedDraw: intPtr(100), ConnectedEndpoints: []objectRef{{ID: 5}}},
+			{ID: 1, Name: "PSU1", AllocatedDraw: intPtr(250), ConnectedEndpoints: []objectRef{{ID: 4}}},
+		}})
+	})
+	defer server.Close()
+
+	client := NewClient(config.NetBoxConfig{
+		URL:       server.URL,
+		Token:     "test-token",
+		PowerDraw: config.PowerDrawConfig{Mode: config.PowerDrawAllocated, UsePeak: true},
+	})
+
+	info := models.ServerInfo{PowerConsumedWatts: 320, PowerPeakWatts: 500}
+	require.NoError(t, client.SyncAllocatedDraw(context.Background(), 7, info))
+
+	require.Len(t, patched, 1, "unchanged ports are not written")
+	assert.Equal(t, float64(250), patched[0]["allocated_draw"])
+}
+
+func TestClient_SyncFeedDraws(t *testing.T) {
+	feedPatches := map[string]map[string]interface{}{}
+	server := mockNetBoxServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/dcim/devices/":
+			tag := r.URL.Query().Get("asset_tag")
+			id := 1
+			if tag == "TAG2" {
+				id = 2
+			}
+			json.NewEncoder(w).Encode(DeviceList{Count: 1, Results: []Device{{ID: id, Name: tag, AssetTag: tag}}})
+		case r.URL.Path == "/api/dcim/power-ports/":
+			json.NewEncoder(w).Encode(powerPortList{Count: 1, Results: []PowerPort{
+				{ID: 10, Name: "PSU1", ConnectedEndpointsType: "dcim.powerfeed", ConnectedEndpoints: []objectRef{{ID: 42}}},
+			}})
+		case r.Method == http.MethodPatch:
+			var body map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			feedPatches[r.URL.Path] = body
+			json.NewEncoder(w).Encode(objectRef{ID: 42})
+		}
+	})
+	defer server.Close()
+
+	client := NewClient(config.NetBoxConfig{
+		URL:       server.URL,
+		Token:     "test-token",
+		PowerDraw: config.PowerDrawConfig{Mode: config.PowerDrawFeedField},
+	})
+
+	servers := []models.ServerInfo{
+		{Host: "10.0.0.1", ServiceTag: "TAG1", PowerConsumedWatts: 300},
+		{Host: "10.0.0.2", ServiceTag: "TAG2", PowerConsumedWatts: 450},
+	}
+	require.NoError(t, client.syncFeedDraws(context.Background(), servers))
+
+	require.Contains(t, feedPatches, "/api/dcim/power-feeds/42/")
+	fields := feedPatches["/api/dcim/power-feeds/42/"]["custom_fields"].(map[string]interface{})
+	assert.Equal(t, float64(750), fields["measured_draw_watts"])
+}
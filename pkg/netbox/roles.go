@@ -0,0 +1,35 @@
+package netbox
+
+import (
+	"context"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+// namedRef is a nested NetBox object with its name and slug.
+type namedRef struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+// RoleSlug returns the slug of the device role, or "" if it has none.
+func (d Device) RoleSlug() string {
+	switch {
+	case d.Role != nil:
+		return d.Role.Slug
+	case d.DeviceRole != nil:
+		return d.DeviceRole.Slug
+	}
+	return ""
+}
+
+// DeviceRole returns the role slug of the NetBox device of a server, found
+// like on sync, or "" if the device is not in NetBox.
+func (c *Client) DeviceRole(ctx context.Context, info models.ServerInfo) (string, error) {
+	device, err := c.findDevice(ctx, info)
+	if err != nil || device == nil {
+		return "", err
+	}
+	return device.RoleSlug(), nil
+}
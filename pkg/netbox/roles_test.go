@@ -0,0 +1,48 @@
+package netbox
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/config"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+func TestClient_DeviceRole(t *testing.T) {
+	server := mockNetBoxServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("asset_tag") {
+		case "ABC1234":
+			json.NewEncoder(w).Encode(DeviceList{Count: 1, Results: []Device{
+				{ID: 1, Role: &namedRef{ID: 3, Name: "Hypervisor", Slug: "hypervisor"}},
+			}})
+		case "DEF5678":
+			// NetBox < 4.0
+			json.NewEncoder(w).Encode(DeviceList{Count: 1, Results: []Device{
+				{ID: 2, DeviceRole: &namedRef{ID: 4, Name: "Storage", Slug: "storage"}},
+			}})
+		default:
+			json.NewEncoder(w).Encode(DeviceList{})
+		}
+	})
+	defer server.Close()
+
+	client := NewClient(config.NetBoxConfig{URL: server.URL, Token: "test-token"})
+	ctx := context.Background()
+
+	role, err := client.DeviceRole(ctx, models.ServerInfo{ServiceTag: "ABC1234"})
+	require.NoError(t, err)
+	assert.Equal(t, "hypervisor", role)
+
+	role, err = client.DeviceRole(ctx, models.ServerInfo{ServiceTag: "DEF5678"})
+	require.NoError(t, err)
+	assert.Equal(t, "storage", role)
+
+	role, err = client.DeviceRole(ctx, models.ServerInfo{ServiceTag: "GHI9012"})
+	require.NoError(t, err)
+	assert.Empty(t, role)
+}
@@ -0,0 +1,116 @@
+package netbox
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/defaults"
+)
+
+// StaleDevice is a NetBox device whose last inventory is older than the
+// cutoff of the prune report, or that was never inventoried.
+type StaleDevice struct {
+	ID            int        `json:"id"`
+	Name          string     `json:"name"`
+	Serial        string     `json:"serial"`
+	AssetTag      string     `json:"asset_tag"`
+	URL           string     `json:"url"`
+	LastInventory *time.Time `json:"last_inventory,omitempty"` // nil if never inventoried
+
+	tags []namedRef
+}
+
+// AgeDays returns the number of whole days since the last inventory, or -1
+// if the device was never inventoried.
+func (d StaleDevice) AgeDays(now time.Time) int {
+	if d.LastInventory == nil {
+		return -1
+	}
+	return int(now.Sub(*d.LastInventory).Hours() / 24)
+}
+
+// StaleDevices lists the devices matching query (NetBox device filters, e.g.
+// manufacturer=dell) whose last inventory custom field is older than cutoff,
+// oldest first. Devices without a (parseable) last inventory are listed
+// first if includeUnset is set and skipped otherwise.
+func (c *Client) StaleDevices(ctx context.Context, query url.Values, cutoff time.Time, includeUnset bool) ([]StaleDevice, error) {
+	var stale []StaleDevice
+	err := c.listDevices(ctx, query, func(d Device) {
+		sd := StaleDevice{
+			ID:       d.ID,
+			Name:     d.Name,
+			Serial:   d.Serial,
+			AssetTag: d.AssetTag,
+			URL:      d.URL,
+			tags:     d.Tags,
+		}
+		if v, ok := d.CustomFields[c.fieldNames.LastInventory].(string); ok && v != "" {
+			if t, err := time.Parse(time.RFC3339, v); err == nil {
+				sd.LastInventory = &t
+			} else {
+				c.logger.Debugw("unparseable last inventory",
+					"device_id", d.ID,
+					"value", v,
+				)
+			}
+		}
+
+		switch {
+		case sd.LastInventory == nil && !includeUnset:
+		case sd.LastInventory == nil || sd.LastInventory.Before(cutoff):
+			stale = append(stale, sd)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(stale, func(i, j int) bool {
+		a, b := stale[i].LastInventory, stale[j].LastInventory
+		if (a == nil) != (b == nil) {
+			return a == nil
+		}
+		if a == nil {
+			return stale[i].Name < stale[j].Name
+		}
+		return a.Before(*b)
+	})
+	return stale, nil
+}
+
+// TagDevices adds the tag (created if needed) to the devices that do not
+// have it yet, keeping their other tags. It returns the number of devices
+// tagged.
+func (c *Client) TagDevices(ctx context.Context, devices []StaleDevice, tag string) (int, error) {
+	slug := slugify(tag)
+	tagID, err := c.findOrCreate(ctx, defaults.NetBoxTagsPath, url.Values{"slug": {slug}},
+		map[string]interface{}{"name": tag, "slug": slug})
+	if err != nil {
+		return 0, fmt.Errorf("tag %q: %w", tag, err)
+	}
+
+	tagged := 0
+	for _, d := range devices {
+		tags := []map[string]int{}
+		has := false
+		for _, t := range d.tags {
+			has = has || t.ID == tagID
+			tags = append(tags, map[string]int{"id": t.ID})
+		}
+		if has {
+			continue
+		}
+		tags = append(tags, map[string]int{"id": tagID})
+
+		path := fmt.Sprintf("%s%d/", defaults.NetBoxDevicesPath, d.ID)
+		if err := c.request(ctx, http.MethodPatch, path, map[string]interface{}{"tags": tags}, nil); err != nil {
+			return tagged, fmt.Errorf("failed to tag device %d: %w", d.ID, err)
+		}
+		tagged++
+	}
+	return tagged, nil
+}
@@ -0,0 +1,81 @@
+package netbox
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/config"
+)
+
+func TestClient_StaleDevices(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	var query url.Values
+	server := mockNetBoxServer(t, func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		json.NewEncoder(w).Encode(DeviceList{Count: 4, Results: []Device{
+			{ID: 1, Name: "fresh", CustomFields: map[string]interface{}{"hw_last_inventory": "2025-02-28T10:00:00Z"}},
+			{ID: 2, Name: "old", CustomFields: map[string]interface{}{"hw_last_inventory": "2024-12-01T10:00:00Z"}},
+			{ID: 3, Name: "older", CustomFields: map[string]interface{}{"hw_last_inventory": "2024-06-01T10:00:00Z"}},
+			{ID: 4, Name: "never", CustomFields: map[string]interface{}{"hw_last_inventory": nil}},
+		}})
+	})
+	defer server.Close()
+
+	client := NewClient(config.NetBoxConfig{URL: server.URL, Token: "test-token"})
+	filter := url.Values{"manufacturer": {"dell"}}
+
+	devices, err := client.StaleDevices(context.Background(), filter, now.AddDate(0, 0, -30), false)
+	require.NoError(t, err)
+	assert.Equal(t, "dell", query.Get("manufacturer"))
+	assert.Equal(t, "0", query.Get("offset"))
+	require.Len(t, devices, 2)
+	assert.Equal(t, "older", devices[0].Name, "oldest first")
+	assert.Equal(t, "old", devices[1].Name)
+	assert.Equal(t, 90, devices[1].AgeDays(now))
+
+	devices, err = client.StaleDevices(context.Background(), filter, now.AddDate(0, 0, -30), true)
+	require.NoError(t, err)
+	require.Len(t, devices, 3)
+	assert.Equal(t, "never", devices[0].Name, "never inventoried first")
+	assert.Equal(t, -1, devices[0].AgeDays(now))
+}
+
+func TestClient_TagDevices(t *testing.T) {
+	patches := map[string]interface{}{}
+	server := mockNetBoxServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/extras/tags/" && r.Method == http.MethodGet:
+			assert.Equal(t, "decommission-review", r.URL.Query().Get("slug"))
+			json.NewEncoder(w).Encode(objectList{Count: 1, Results: []objectRef{{ID: 7}}})
+		case r.Method == http.MethodPatch:
+			var body map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			patches[r.URL.Path] = body["tags"]
+			json.NewEncoder(w).Encode(objectRef{ID: 1})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		}
+	})
+	defer server.Close()
+
+	client := NewClient(config.NetBoxConfig{URL: server.URL, Token: "test-token"})
+	tagged, err := client.TagDevices(context.Background(), []StaleDevice{
+		{ID: 1, tags: []namedRef{{ID: 3, Slug: "gpu"}}},
+		{ID: 2, tags: []namedRef{{ID: 7, Slug: "decommission-review"}}},
+	}, "Decommission Review")
+	require.NoError(t, err)
+	assert.Equal(t, 1, tagged)
+	assert.Equal(t, map[string]interface{}{
+		"/api/dcim/devices/1/": []interface{}{
+			map[string]interface{}{"id": float64(3)},
+			map[string]interface{}{"id": float64(7)},
+		},
+	}, patches)
+}
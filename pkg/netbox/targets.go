@@ -0,0 +1,149 @@
+package netbox
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/config"
+	"github.com/braunma/idrac-netbox-importer/pkg/defaults"
+)
+
+// devicePageSize is the number of devices requested per page when listing
+// devices.
+const devicePageSize = 500
+
+// listDevicesConcurrency is the number of device list pages fetched at once
+// by listDevicesParallel.
+const listDevicesConcurrency = 4
+
+// ipRef is a nested NetBox IP address, e.g. the oob_ip of a device.
+type ipRef struct {
+	ID      int    `json:"id"`
+	Address string `json:"address"` // with prefix length, e.g. "10.0.0.5/24"
+}
+
+// Target is a NetBox device to scan.
+type Target struct {
+	DeviceID int
+	Name     string
+	Address  string // iDRAC IP address or DNS name
+}
+
+// listDevices calls fn for each device matching query (NetBox device
+// filters), fetching the devices page by page.
+func (c *Client) listDevices(ctx context.Context, query url.Values, fn func(Device)) error {
+	for offset := 0; ; offset += devicePageSize {
+		page, err := c.devicePage(ctx, query, offset)
+		if err != nil {
+			return err
+		}
+		for _, d := range page.Results {
+			fn(d)
+		}
+		if len(page.Results) < devicePageSize || offset+len(page.Results) >= page.Count {
+			return nil
+		}
+	}
+}
+
+// listDevicesParallel returns the devices matching query, fetching the
+// pages after the first listDevicesConcurrency at a time.
+func (c *Client) listDevicesParallel(ctx context.Context, query url.Values) ([]Device, error) {
+	first, err := c.devicePage(ctx, query, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(first.Results) < devicePageSize || first.Count <= devicePageSize {
+		return first.Results, nil
+	}
+
+	pages := make([][]Device, (first.Count-1)/devicePageSize)
+	errs := make([]error, len(pages))
+	sem := make(chan struct{}, listDevicesConcurrency)
+	var wg sync.WaitGroup
+	for i := range pages {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			page, err := c.devicePage(ctx, query, (i+1)*devicePageSize)
+			pages[i], errs[i] = page.Results, err
+		}(i)
+	}
+	wg.Wait()
+
+	devices := first.Results
+	for i, page := range pages {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		devices = append(devices, page...)
+	}
+	return devices, nil
+}
+
+// devicePage fetches the page of the devices matching query that starts at
+// offset.
+func (c *Client) devicePage(ctx context.Context, query url.Values, offset int) (DeviceList, error) {
+	q := url.Values{}
+	for k, v := range query {
+		q[k] = v
+	}
+	q.Set("limit", strconv.Itoa(devicePageSize))
+	q.Set("offset", strconv.Itoa(offset))
+
+	var page DeviceList
+	err := c.request(ctx, http.MethodGet, defaults.NetBoxDevicesPath+"?"+q.Encode(), nil, &page)
+	return page, err
+}
+
+// Targets lists the devices matching query with their iDRAC address, read
+// from address: oob_ip, primary_ip4, primary_ip or a custom field (see
+// config.NetBoxTargetsConfig). Devices without an address are skipped and
+// counted.
+func (c *Client) Targets(ctx context.Context, query url.Values, address string) (targets []Target, skipped int, err error) {
+	err = c.listDevices(ctx, query, func(d Device) {
+		addr := deviceAddress(d, address)
+		if addr == "" {
+			c.logger.Debugw("device has no iDRAC address",
+				"device_id", d.ID,
+				"device", d.Name,
+				"address", address,
+			)
+			skipped++
+			return
+		}
+		targets = append(targets, Target{DeviceID: d.ID, Name: d.Name, Address: addr})
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return targets, skipped, nil
+}
+
+// deviceAddress returns the address of a device from the source address,
+// without prefix length ("" if not set).
+func deviceAddress(d Device, address string) string {
+	var ip *ipRef
+	switch address {
+	case config.TargetAddressOOBIP:
+		ip = d.OOBIP
+	case config.TargetAddressPrimaryIP:
+		ip = d.PrimaryIP
+	case config.TargetAddressPrimaryIP4:
+		ip = d.PrimaryIP4
+	default:
+		v, _ := d.CustomFields[address].(string)
+		ip = &ipRef{Address: v}
+	}
+	if ip == nil {
+		return ""
+	}
+	addr, _, _ := strings.Cut(strings.TrimSpace(ip.Address), "/")
+	return addr
+}
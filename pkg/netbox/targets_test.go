@@ -0,0 +1,77 @@
+package netbox
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/config"
+)
+
+func TestClient_Targets(t *testing.T) {
+	var query url.Values
+	server := mockNetBoxServer(t, func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		json.NewEncoder(w).Encode(DeviceList{Count: 4, Results: []Device{
+			{ID: 1, Name: "web-01", OOBIP: &ipRef{Address: "10.0.0.11/24"}, PrimaryIP4: &ipRef{Address: "192.168.0.11/24"}},
+			{ID: 2, Name: "web-02", OOBIP: &ipRef{Address: "10.0.0.12/24"}},
+			{ID: 3, Name: "db-01", CustomFields: map[string]interface{}{"idrac_host": "idrac-db-01.example.com"}},
+			{ID: 4, Name: "spare"},
+		}})
+	})
+	defer server.Close()
+
+	client := NewClient(config.NetBoxConfig{URL: server.URL, Token: "test-token"})
+	filter := url.Values{"role": {"server"}, "tag": {"idrac"}}
+
+	targets, skipped, err := client.Targets(context.Background(), filter, config.TargetAddressOOBIP)
+	require.NoError(t, err)
+	assert.Equal(t, "server", query.Get("role"))
+	assert.Equal(t, "idrac", query.Get("tag"))
+	assert.Equal(t, []Target{
+		{DeviceID: 1, Name: "web-01", Address: "10.0.0.11"},
+		{DeviceID: 2, Name: "web-02", Address: "10.0.0.12"},
+	}, targets)
+	assert.Equal(t, 2, skipped)
+
+	targets, skipped, err = client.Targets(context.Background(), filter, config.TargetAddressPrimaryIP4)
+	require.NoError(t, err)
+	require.Len(t, targets, 1)
+	assert.Equal(t, "192.168.0.11", targets[0].Address)
+	assert.Equal(t, 3, skipped)
+
+	targets, _, err = client.Targets(context.Background(), filter, "idrac_host")
+	require.NoError(t, err)
+	require.Len(t, targets, 1)
+	assert.Equal(t, Target{DeviceID: 3, Name: "db-01", Address: "idrac-db-01.example.com"}, targets[0])
+}
+
+func TestClient_TargetsPaging(t *testing.T) {
+	var offsets []string
+	server := mockNetBoxServer(t, func(w http.ResponseWriter, r *http.Request) {
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		offsets = append(offsets, r.URL.Query().Get("offset"))
+		n := devicePageSize
+		if offset > 0 {
+			n = 2
+		}
+		page := DeviceList{Count: devicePageSize + 2}
+		for i := 0; i < n; i++ {
+			page.Results = append(page.Results, Device{ID: offset + i, OOBIP: &ipRef{Address: "10.0.0.1"}})
+		}
+		json.NewEncoder(w).Encode(page)
+	})
+	defer server.Close()
+
+	client := NewClient(config.NetBoxConfig{URL: server.URL, Token: "test-token"})
+	targets, _, err := client.Targets(context.Background(), nil, config.TargetAddressOOBIP)
+	require.NoError(t, err)
+	assert.Len(t, targets, devicePageSize+2)
+	assert.Equal(t, []string{"0", strconv.Itoa(devicePageSize)}, offsets)
+}
@@ -0,0 +1,35 @@
+// Package runid provides the unique ID of the current run. It is attached to
+// log lines, JSON output, NetBox updates and audit entries so the artifacts of
+// a particular run can be correlated across systems.
+package runid
+
+import (
+	"crypto/rand"
+	"fmt"
+	"sync"
+)
+
+var (
+	once    sync.Once
+	current string
+)
+
+// Current returns the ID of this process run, generating it on first use.
+func Current() string {
+	once.Do(func() {
+		current = New()
+	})
+	return current
+}
+
+// New returns a random (version 4) UUID.
+func New() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		// crypto/rand does not fail on supported platforms
+		panic("runid: " + err.Error())
+	}
+	b[6] = (b[6] & 0x0f) | 0x40 // version 4
+	b[8] = (b[8] & 0x3f) | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,21 @@
+package runid
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestNew(t *testing.T) {
+	id := New()
+	assert.Regexp(t, uuidPattern, id)
+	assert.NotEqual(t, id, New())
+}
+
+func TestCurrent_IsStable(t *testing.T) {
+	assert.Regexp(t, uuidPattern, Current())
+	assert.Equal(t, Current(), Current())
+}
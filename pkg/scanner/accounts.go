@@ -0,0 +1,99 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/braunma/idrac-netbox-importer/internal/redfish"
+	"github.com/braunma/idrac-netbox-importer/pkg/defaults"
+	"github.com/braunma/idrac-netbox-importer/pkg/errors"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+// factoryPassword is the default password of root on a new iDRAC.
+const factoryPassword = "calvin"
+
+// collectAccounts audits the local iDRAC accounts of the AccountService
+// and, with accounts.probe_default_credentials, whether root still accepts
+// the factory default password.
+func (s *Scanner) collectAccounts(ctx context.Context, client *redfishClient, info *models.ServerInfo) error {
+	var collection redfish.Collection
+	if err := client.get(ctx, defaults.RedfishAccountsPath, &collection); err != nil {
+		return errors.NewCollectionError(info.Host, models.PhaseAccounts, err)
+	}
+
+	audit := &models.AccountAudit{MaxAdministrators: s.cfg.Accounts.MaxAdministrators}
+	for _, member := range collection.Members {
+		var account redfish.ManagerAccount
+		if err := client.getMember(ctx, member.OdataID, &account); err != nil {
+			client.logger.Debugw("failed to get account details",
+				"account", member.OdataID,
+				"error", err,
+			)
+			continue
+		}
+		// The iDRAC lists all 16 slots of its account table
+		if account.UserName == "" {
+			continue
+		}
+		audit.Accounts = append(audit.Accounts, models.AccountInfo{
+			ID:       account.ID,
+			UserName: account.UserName,
+			Role:     account.RoleID,
+			Enabled:  account.Enabled,
+			Locked:   account.Locked,
+		})
+	}
+
+	if s.cfg.Accounts.ProbeDefaultCredentials {
+		accepted, err := client.acceptsDefaultCredentials(ctx)
+		if err != nil {
+			client.logger.Debugw("failed to probe default credentials", "error", err)
+		} else {
+			audit.DefaultCredentials = &accepted
+		}
+	}
+	info.Accounts = audit
+
+	client.logger.Infow("extracted account information",
+		"accounts", len(audit.Accounts),
+		"enabled", len(audit.Enabled()),
+		"administrators", len(audit.Administrators()),
+	)
+	if risks := audit.Risks(); len(risks) > 0 {
+		client.logger.Warnw("risky iDRAC account configuration", "risks", risks)
+	}
+	return nil
+}
+
+// acceptsDefaultCredentials reports whether the iDRAC accepts root with the
+// factory default password. It sends a single request without retries, as
+// every failed login counts towards the iDRAC's IP blocking threshold.
+func (c *redfishClient) acceptsDefaultCredentials(ctx context.Context) (bool, error) {
+	if c.username == models.DefaultUserName && c.password == factoryPassword {
+		return true, nil
+	}
+
+	req, err := c.newRequest(ctx, http.MethodGet, defaults.RedfishSystemsPath, nil)
+	if err != nil {
+		return false, err
+	}
+	req.SetBasicAuth(models.DefaultUserName, factoryPassword)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return false, errors.NewRedfishTransportError(c.baseURL, defaults.RedfishSystemsPath, err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return false, nil
+	}
+	return false, fmt.Errorf("unexpected status %s", resp.Status)
+}
@@ -0,0 +1,52 @@
+package scanner
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/logging"
+)
+
+func TestAcceptsDefaultCredentials(t *testing.T) {
+	var probes int
+	password := "calvin"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		probes++
+		if user, pass, _ := r.BasicAuth(); user != "root" || pass != password {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := &redfishClient{
+		baseURL:    server.URL,
+		username:   "admin",
+		password:   "secret",
+		httpClient: server.Client(),
+		logger:     logging.WithComponent("test"),
+	}
+
+	accepted, err := client.acceptsDefaultCredentials(context.Background())
+	require.NoError(t, err)
+	assert.True(t, accepted)
+
+	password = "changed"
+	accepted, err = client.acceptsDefaultCredentials(context.Background())
+	require.NoError(t, err)
+	assert.False(t, accepted)
+	assert.Equal(t, 2, probes, "a single request per probe")
+
+	// Scanning as root/calvin proves the default password works
+	client.username, client.password = "root", "calvin"
+	accepted, err = client.acceptsDefaultCredentials(context.Background())
+	require.NoError(t, err)
+	assert.True(t, accepted)
+	assert.Equal(t, 2, probes)
+}
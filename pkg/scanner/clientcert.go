@@ -0,0 +1,57 @@
+package scanner
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/config"
+)
+
+// certTransports caches one transport per client certificate, so the
+// servers of a group share a connection pool like the others do.
+type certTransports struct {
+	mu         sync.Mutex
+	transports map[string]http.RoundTripper
+}
+
+// certTransport returns the transport presenting the client certificate of
+// server. If the certificate cannot be loaded, every request through the
+// transport fails with the load error, so only these hosts fail.
+func (s *Scanner) certTransport(server config.ServerConfig) http.RoundTripper {
+	s.certs.mu.Lock()
+	defer s.certs.mu.Unlock()
+
+	key := server.ClientCert + "\x00" + server.ClientKey
+	if t, ok := s.certs.transports[key]; ok {
+		return t
+	}
+
+	var transport http.RoundTripper
+	cert, err := tls.LoadX509KeyPair(server.ClientCert, server.ClientKey)
+	if err != nil {
+		s.logger.Warnw("failed to load iDRAC client certificate",
+			"cert", server.ClientCert,
+			"error", err,
+		)
+		transport = failingTransport{fmt.Errorf("failed to load client certificate %s: %w", server.ClientCert, err)}
+	} else {
+		transport = newTransport(s.cfg, []tls.Certificate{cert})
+	}
+
+	if s.certs.transports == nil {
+		s.certs.transports = make(map[string]http.RoundTripper)
+	}
+	s.certs.transports[key] = transport
+	return transport
+}
+
+// failingTransport fails every request with err.
+type failingTransport struct {
+	err error
+}
+
+func (t failingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, t.err
+}
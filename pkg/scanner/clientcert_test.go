@@ -0,0 +1,64 @@
+package scanner
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/braunma/idrac-netbox-importer/internal/testutil"
+	"github.com/braunma/idrac-netbox-importer/pkg/config"
+	"github.com/braunma/idrac-netbox-importer/pkg/logging"
+)
+
+func TestNewClient_ClientCert(t *testing.T) {
+	certFile, keyFile, cert := testutil.WriteClientCert(t)
+
+	var authorization []string
+	certificates := 0
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = append(authorization, r.Header.Get("Authorization"))
+		certificates += len(r.TLS.PeerCertificates)
+		w.Write([]byte(`{"RedfishVersion": "1.17.0"}`))
+	}))
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	server.TLS = &tls.Config{ClientAuth: tls.VerifyClientCertIfGiven, ClientCAs: pool}
+	server.StartTLS()
+	defer server.Close()
+
+	insecure := true
+	s := New(&config.Config{
+		Defaults: config.DefaultsConfig{Username: "root", Password: "calvin", InsecureSkipVerify: &insecure},
+	})
+	host := strings.TrimPrefix(server.URL, "https://")
+	log := logging.WithComponent("test")
+	var root struct{ RedfishVersion string }
+
+	client := s.newClient(config.ServerConfig{Host: host, ClientCert: certFile, ClientKey: keyFile}, log)
+	require.NoError(t, client.get(context.Background(), "/redfish/v1", &root))
+	assert.Equal(t, "1.17.0", root.RedfishVersion)
+	assert.Equal(t, 1, certificates)
+	assert.Same(t, client.httpClient.Transport,
+		s.newClient(config.ServerConfig{Host: "10.0.0.2", ClientCert: certFile, ClientKey: keyFile}, log).httpClient.Transport,
+		"servers with the same certificate share the transport")
+
+	client = s.newClient(config.ServerConfig{Host: host}, log)
+	require.NoError(t, client.get(context.Background(), "/redfish/v1", &root))
+	assert.Equal(t, []string{"", "Basic cm9vdDpjYWx2aW4="}, authorization,
+		"basic auth without a client certificate only")
+	assert.Equal(t, 1, certificates)
+
+	client = s.newClient(config.ServerConfig{Host: host, ClientCert: filepath.Join(t.TempDir(), "missing.pem"), ClientKey: keyFile}, log)
+	err := client.get(context.Background(), "/redfish/v1", &root)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to load client certificate")
+	assert.Len(t, authorization, 2)
+}
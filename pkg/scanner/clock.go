@@ -0,0 +1,59 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/braunma/idrac-netbox-importer/internal/redfish"
+	"github.com/braunma/idrac-netbox-importer/pkg/errors"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+// collectClock compares the DateTime of the manager with the local clock and
+// warns if the iDRAC clock drifted beyond clock.max_drift.
+func (s *Scanner) collectClock(ctx context.Context, client *redfishClient, info *models.ServerInfo) error {
+	if client.system.Manager == "" {
+		return errNoManager
+	}
+
+	start := time.Now()
+	var manager redfish.Manager
+	if err := client.get(ctx, client.system.Manager, &manager); err != nil {
+		return errors.NewCollectionError(info.Host, models.PhaseClock, err)
+	}
+	clock, err := clockOf(manager, midpoint(start, time.Now()), s.cfg.Clock.GetMaxDrift())
+	if err != nil {
+		return errors.NewCollectionError(info.Host, models.PhaseClock, err)
+	}
+	info.Clock = clock
+
+	if clock.Drifted() {
+		client.logger.Warnw("iDRAC clock drifted",
+			"bmc_time", clock.BMCTime.Format(time.RFC3339),
+			"drift", clock.Drift().String(),
+			"max_drift", s.cfg.Clock.GetMaxDrift().String(),
+		)
+	}
+	return nil
+}
+
+// clockOf measures the drift of the manager's DateTime against the local
+// time at which it was read.
+func clockOf(manager redfish.Manager, local time.Time, maxDrift time.Duration) (*models.ClockInfo, error) {
+	if manager.DateTime == "" {
+		return nil, fmt.Errorf("manager reports no DateTime")
+	}
+	bmcTime, err := time.Parse(time.RFC3339, manager.DateTime)
+	if err != nil {
+		return nil, fmt.Errorf("invalid manager DateTime %q: %w", manager.DateTime, err)
+	}
+	clock := models.NewClockInfo(bmcTime, local, maxDrift)
+	return &clock, nil
+}
+
+// midpoint returns the time halfway between the start and the end of a
+// request, the best estimate of when the BMC read its clock.
+func midpoint(start, end time.Time) time.Time {
+	return start.Add(end.Sub(start) / 2)
+}
@@ -0,0 +1,59 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/braunma/idrac-netbox-importer/internal/redfish"
+	"github.com/braunma/idrac-netbox-importer/pkg/config"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+func TestClockOf(t *testing.T) {
+	local := time.Date(2025, 1, 10, 18, 30, 0, 0, time.UTC)
+
+	clock, err := clockOf(redfish.Manager{DateTime: "2025-01-10T12:34:56-06:00"}, local, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 296.0, clock.DriftSeconds, "offsets are honoured")
+	assert.True(t, clock.Drifted())
+
+	_, err = clockOf(redfish.Manager{}, local, time.Minute)
+	assert.Error(t, err)
+	_, err = clockOf(redfish.Manager{DateTime: "yesterday"}, local, time.Minute)
+	assert.Error(t, err)
+
+	start := time.Now()
+	assert.Equal(t, start.Add(time.Second), midpoint(start, start.Add(2*time.Second)))
+}
+
+func TestScanServer_Clock(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/redfish/v1/Systems/System.Embedded.1":
+			fmt.Fprint(w, `{"Id": "System.Embedded.1", "Model": "PowerEdge R750", "SKU": "ABC1234"}`)
+		case "/redfish/v1/Managers/iDRAC.Embedded.1":
+			// The iDRAC clock runs ten minutes ahead
+			fmt.Fprintf(w, `{"Id": "iDRAC.Embedded.1", "DateTime": %q}`, time.Now().Add(10*time.Minute).Format(time.RFC3339))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	insecure := true
+	s := New(&config.Config{Defaults: config.DefaultsConfig{InsecureSkipVerify: &insecure}})
+
+	infos := s.scanServer(context.Background(), config.ServerConfig{Host: server.Listener.Addr().String()})
+	require.Len(t, infos, 1)
+	require.NotNil(t, infos[0].Clock, "the manager reports its DateTime")
+	assert.True(t, infos[0].Clock.Drifted())
+	assert.InDelta(t, 600, infos[0].Clock.DriftSeconds, 5)
+	assert.Contains(t, infos[0].PhaseDurations, models.PhaseClock)
+}
@@ -0,0 +1,170 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"plugin"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+// Client is the Redfish client passed to custom collectors. It is bound to
+// one host and one system of that host.
+type Client interface {
+	// Get reads the Redfish resource at path (e.g. an OEM endpoint) and
+	// unmarshals its JSON into target.
+	Get(ctx context.Context, path string, target interface{}) error
+
+	// Host returns the iDRAC address.
+	Host() string
+
+	// SystemPath and ChassisPath return the Redfish paths of the scanned
+	// system and its chassis ("" if the system has no chassis link).
+	SystemPath() string
+	ChassisPath() string
+}
+
+// Collector collects site-specific data that the built-in collectors do not
+// cover, typically from OEM endpoints. Collectors run after the built-in
+// ones, on every system that was scanned successfully, and usually store
+// their data with info.SetCustom under their name. A failing collector is
+// logged and does not fail the scan.
+type Collector interface {
+	Name() string
+	Collect(ctx context.Context, client Client, info *models.ServerInfo) error
+}
+
+// PluginSymbol is the symbol looked up by LoadPlugin: a package-level
+// variable of a type implementing Collector.
+const PluginSymbol = "Collector"
+
+// registry holds the collectors by name.
+type registry struct {
+	mu         sync.Mutex
+	collectors map[string]Collector
+}
+
+var collectors = &registry{}
+
+func (r *registry) register(c Collector) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.collectors == nil {
+		r.collectors = make(map[string]Collector)
+	}
+	name := c.Name()
+	if name == "" {
+		return fmt.Errorf("collector has no name")
+	}
+	for _, phase := range models.Phases {
+		if name == phase {
+			return fmt.Errorf("collector name %q is taken by a built-in collector", name)
+		}
+	}
+	if _, ok := r.collectors[name]; ok {
+		return fmt.Errorf("collector %q is already registered", name)
+	}
+	r.collectors[name] = c
+	return nil
+}
+
+// list returns the registered collectors sorted by name, except disabled ones.
+func (r *registry) list(disabled []string) []Collector {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	skip := make(map[string]bool, len(disabled))
+	for _, name := range disabled {
+		skip[name] = true
+	}
+	var list []Collector
+	for name, c := range r.collectors {
+		if !skip[name] {
+			list = append(list, c)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name() < list[j].Name() })
+	return list
+}
+
+// Register adds a custom collector to all scanners created afterwards. It is
+// meant to be called from the init function of a compiled-in package (a
+// blank import in main) or a plugin, and panics if the name is empty or
+// already taken.
+func Register(c Collector) {
+	if err := collectors.register(c); err != nil {
+		panic(err)
+	}
+}
+
+// Collectors returns the names of the registered collectors.
+func Collectors() []string {
+	var names []string
+	for _, c := range collectors.list(nil) {
+		names = append(names, c.Name())
+	}
+	return names
+}
+
+// LoadPlugin opens a Go plugin (built with -buildmode=plugin against the
+// same version of this module) and registers its collector. The plugin
+// either exports a Collector variable or calls Register from its init
+// function. Plugins are only supported on Linux and macOS.
+func LoadPlugin(path string) error {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open collector plugin %s: %w", path, err)
+	}
+	sym, err := p.Lookup(PluginSymbol)
+	if err != nil {
+		// The plugin registered itself in init
+		return nil
+	}
+
+	var c Collector
+	switch v := sym.(type) {
+	case Collector:
+		c = v
+	case *Collector:
+		c = *v
+	default:
+		return fmt.Errorf("collector plugin %s: %s is a %T, not a scanner.Collector", path, PluginSymbol, sym)
+	}
+	if err := collectors.register(c); err != nil {
+		return fmt.Errorf("collector plugin %s: %w", path, err)
+	}
+	return nil
+}
+
+// runCollector runs a custom collector, turning a panic into an error so a
+// faulty plugin cannot crash the scan.
+func runCollector(ctx context.Context, c Collector, client Client, info *models.ServerInfo) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("collector %s panicked: %v", c.Name(), r)
+		}
+	}()
+	return c.Collect(ctx, client, info)
+}
+
+// Get implements Client.
+func (c *redfishClient) Get(ctx context.Context, path string, target interface{}) error {
+	return c.get(ctx, path, target)
+}
+
+// Host implements Client.
+func (c *redfishClient) Host() string {
+	return strings.TrimPrefix(c.baseURL, "https://")
+}
+
+// SystemPath implements Client.
+func (c *redfishClient) SystemPath() string {
+	return c.system.System
+}
+
+// ChassisPath implements Client.
+func (c *redfishClient) ChassisPath() string {
+	return c.system.Chassis
+}
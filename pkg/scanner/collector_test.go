@@ -0,0 +1,83 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/config"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+// riserCollector reads riser data from a made-up OEM endpoint.
+type riserCollector struct{ name string }
+
+func (c riserCollector) Name() string { return c.name }
+
+func (c riserCollector) Collect(ctx context.Context, client Client, info *models.ServerInfo) error {
+	var riser struct {
+		Revision string `json:"Revision"`
+	}
+	if err := client.Get(ctx, client.SystemPath()+"/Oem/Example/Riser", &riser); err != nil {
+		return err
+	}
+	info.SetCustom(c.Name(), riser.Revision)
+	return nil
+}
+
+// panickingCollector stands for a faulty plugin.
+type panickingCollector struct{}
+
+func (panickingCollector) Name() string { return "broken" }
+
+func (panickingCollector) Collect(context.Context, Client, *models.ServerInfo) error {
+	panic("nil map")
+}
+
+func TestRegistry(t *testing.T) {
+	r := &registry{}
+	require.NoError(t, r.register(riserCollector{name: "riser"}))
+	require.NoError(t, r.register(panickingCollector{}))
+
+	assert.Error(t, r.register(riserCollector{name: "riser"}), "duplicate name")
+	assert.Error(t, r.register(riserCollector{name: ""}), "empty name")
+	assert.Error(t, r.register(riserCollector{name: models.PhaseMemory}), "built-in phase")
+
+	var names []string
+	for _, c := range r.list(nil) {
+		names = append(names, c.Name())
+	}
+	assert.Equal(t, []string{"broken", "riser"}, names)
+	assert.Len(t, r.list([]string{"broken"}), 1)
+}
+
+func TestScanSystem_CustomCollectors(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/redfish/v1/Systems/System.Embedded.1":
+			fmt.Fprint(w, `{"Id": "System.Embedded.1", "Model": "PowerEdge R760", "SKU": "ABC1234"}`)
+		case "/redfish/v1/Systems/System.Embedded.1/Oem/Example/Riser":
+			fmt.Fprint(w, `{"Revision": "A02"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	insecure := true
+	s := New(&config.Config{Defaults: config.DefaultsConfig{InsecureSkipVerify: &insecure}})
+	s.collectors = []Collector{panickingCollector{}, riserCollector{name: "riser"}}
+
+	infos := s.scanServer(context.Background(), config.ServerConfig{Host: server.Listener.Addr().String()})
+	require.Len(t, infos, 1)
+	info := infos[0]
+	require.NoError(t, info.Error, "failing collectors do not fail the scan")
+	assert.Equal(t, map[string]interface{}{"riser": "A02"}, info.Custom)
+	assert.Contains(t, info.PhaseDurations, "riser")
+	assert.Contains(t, info.PhaseDurations, "broken")
+}
@@ -0,0 +1,151 @@
+package scanner
+
+import (
+	"container/list"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// etagEntry is a cached Redfish response body together with its ETag.
+type etagEntry struct {
+	ETag string          `json:"etag"`
+	Body json.RawMessage `json:"body"`
+}
+
+// etagCache stores response bodies by URL so that member resources can be
+// requested conditionally (If-None-Match) and reused on 304 Not Modified.
+// It is shared by all workers and persisted between runs. It holds at most
+// maxEntries entries and drops the least recently used beyond that, so it
+// does not grow without bound in daemon mode.
+type etagCache struct {
+	path       string
+	maxEntries int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element // values are *etagItem
+	lru     *list.List               // most recently used first
+	dirty   bool
+}
+
+// etagItem is an entry of the LRU list.
+type etagItem struct {
+	url   string
+	entry etagEntry
+}
+
+func newETagCache(path string, maxEntries int) *etagCache {
+	return &etagCache{path: path, maxEntries: maxEntries, entries: make(map[string]*list.Element), lru: list.New()}
+}
+
+// loadETagCache reads the cache file, keeping at most maxEntries entries
+// (0 for no limit). A missing file yields an empty cache.
+func loadETagCache(path string, maxEntries int) (*etagCache, error) {
+	c := newETagCache(path, maxEntries)
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return c, nil
+	}
+	if err != nil {
+		return c, fmt.Errorf("failed to read ETag cache: %w", err)
+	}
+	var entries map[string]etagEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return c, fmt.Errorf("failed to parse ETag cache %s: %w", path, err)
+	}
+	for url, e := range entries {
+		c.put(url, e)
+	}
+	c.dirty = false
+	return c, nil
+}
+
+// lookup returns the cached entry for url.
+func (c *etagCache) lookup(url string) (etagEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[url]
+	if !ok {
+		return etagEntry{}, false
+	}
+	c.lru.MoveToFront(elem)
+	return elem.Value.(*etagItem).entry, true
+}
+
+// store caches body under url if it is valid JSON.
+func (c *etagCache) store(url, etag string, body []byte) {
+	if !json.Valid(body) {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.put(url, etagEntry{ETag: etag, Body: append(json.RawMessage(nil), body...)})
+}
+
+// put adds or replaces the entry of url and evicts the least recently used
+// entries beyond maxEntries. The caller must hold c.mu (or own c exclusively).
+func (c *etagCache) put(url string, e etagEntry) {
+	c.dirty = true
+	if elem, ok := c.entries[url]; ok {
+		elem.Value.(*etagItem).entry = e
+		c.lru.MoveToFront(elem)
+		return
+	}
+	c.entries[url] = c.lru.PushFront(&etagItem{url: url, entry: e})
+	for c.maxEntries > 0 && c.lru.Len() > c.maxEntries {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*etagItem).url)
+	}
+}
+
+// size returns the number of cached entries.
+func (c *etagCache) size() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// save atomically writes the cache file if it changed.
+func (c *etagCache) save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.dirty {
+		return nil
+	}
+
+	entries := make(map[string]etagEntry, len(c.entries))
+	for url, elem := range c.entries {
+		entries[url] = elem.Value.(*etagItem).entry
+	}
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return fmt.Errorf("failed to encode ETag cache: %w", err)
+	}
+
+	dir := filepath.Dir(c.path)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(c.path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write ETag cache: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write ETag cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write ETag cache: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), c.path); err != nil {
+		return fmt.Errorf("failed to write ETag cache: %w", err)
+	}
+	c.dirty = false
+	return nil
+}
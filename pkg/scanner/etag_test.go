@@ -0,0 +1,104 @@
+package scanner
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/braunma/idrac-netbox-importer/internal/redfish"
+	"github.com/braunma/idrac-netbox-importer/pkg/logging"
+)
+
+func TestRedfishClient_GetMemberUsesETagCache(t *testing.T) {
+	const etag = `W/"gen-1"`
+	var full, notModified int
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == etag {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		full++
+		w.Header().Set("ETag", etag)
+		w.Write([]byte(`{"Id": "CPU.Socket.1", "Model": "Intel Xeon Gold 6342"}`))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "etags.json")
+	cache, err := loadETagCache(path, 0)
+	require.NoError(t, err)
+
+	client := &redfishClient{
+		baseURL:    server.URL,
+		httpClient: server.Client(),
+		logger:     logging.WithComponent("test"),
+		etags:      cache,
+	}
+
+	var first, second redfish.Processor
+	require.NoError(t, client.getMember(context.Background(), "/cpu", &first))
+	require.NoError(t, client.getMember(context.Background(), "/cpu", &second))
+
+	assert.Equal(t, 1, full)
+	assert.Equal(t, 1, notModified)
+	assert.Equal(t, "Intel Xeon Gold 6342", second.Model)
+
+	// The cache survives a restart
+	require.NoError(t, cache.save())
+	reloaded, err := loadETagCache(path, 0)
+	require.NoError(t, err)
+	_, ok := reloaded.lookup(server.URL + "/cpu")
+	assert.True(t, ok)
+}
+
+func TestRedfishClient_NotModifiedWithoutCachedBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotModified)
+	}))
+	defer server.Close()
+
+	cache, err := loadETagCache(filepath.Join(t.TempDir(), "etags.json"), 0)
+	require.NoError(t, err)
+	client := &redfishClient{
+		baseURL:    server.URL,
+		httpClient: server.Client(),
+		logger:     logging.WithComponent("test"),
+		etags:      cache,
+	}
+
+	var cpu redfish.Processor
+	err = client.getMember(context.Background(), "/cpu", &cpu)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no cached response")
+	assert.NotContains(t, err.Error(), "JSON")
+}
+
+func TestETagCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "etags.json")
+	cache, err := loadETagCache(path, 2)
+	require.NoError(t, err)
+
+	cache.store("/a", `"1"`, []byte(`{}`))
+	cache.store("/b", `"1"`, []byte(`{}`))
+	_, ok := cache.lookup("/a")
+	require.True(t, ok)
+	cache.store("/c", `"1"`, []byte(`{}`))
+
+	assert.Equal(t, 2, cache.size())
+	_, ok = cache.lookup("/b")
+	assert.False(t, ok, "least recently used entry is evicted")
+	_, ok = cache.lookup("/a")
+	assert.True(t, ok)
+
+	// The limit also applies to a cache file written with a larger one
+	require.NoError(t, cache.save())
+	reloaded, err := loadETagCache(path, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, reloaded.size())
+}
@@ -0,0 +1,154 @@
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/braunma/idrac-netbox-importer/internal/redfish"
+	"github.com/braunma/idrac-netbox-importer/pkg/audit"
+	"github.com/braunma/idrac-netbox-importer/pkg/config"
+	"github.com/braunma/idrac-netbox-importer/pkg/defaults"
+	"github.com/braunma/idrac-netbox-importer/pkg/errors"
+)
+
+// EventContextPrefix starts the Context of the event subscriptions created by
+// Subscribe. The rest of the Context is the host, so the receiver of the
+// events knows which iDRAC sent them.
+const EventContextPrefix = "idrac-inventory:"
+
+// Subscription is the event subscription of one iDRAC.
+type Subscription struct {
+	Host    string
+	Name    string
+	ID      string // URI of the subscription
+	Created bool   // false if it existed already
+	Error   error
+}
+
+// Subscribe registers a Redfish event subscription pushing alerts to
+// destination on every configured server, unless the server has one for
+// destination already. It fails per host in read-only mode. Results are
+// sorted by host.
+func (s *Scanner) Subscribe(ctx context.Context, destination string) []Subscription {
+	results := make([]Subscription, 0, len(s.cfg.Servers))
+	var mu sync.Mutex
+	s.eachServer(func(server config.ServerConfig) {
+		sub := s.subscribe(ctx, server, destination)
+		mu.Lock()
+		results = append(results, sub)
+		mu.Unlock()
+	})
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Host < results[j].Host
+	})
+	return results
+}
+
+func (s *Scanner) subscribe(ctx context.Context, server config.ServerConfig, destination string) Subscription {
+	sub := Subscription{Host: server.Host, Name: server.Name}
+
+	ctx, cancel := context.WithTimeout(ctx, server.GetTimeout(s.cfg.Defaults.Timeout()))
+	defer cancel()
+	client := s.newClient(server, s.hostLogger(server.Host))
+
+	var collection redfish.Collection
+	if err := client.get(ctx, defaults.RedfishEventSubscriptionsPath, &collection); err != nil {
+		sub.Error = errors.NewCollectionError(server.Host, "event subscriptions", err)
+		return sub
+	}
+	for _, member := range collection.Members {
+		var existing redfish.EventDestination
+		if err := client.get(ctx, member.OdataID, &existing); err != nil {
+			sub.Error = errors.NewCollectionError(server.Host, "event subscriptions", err)
+			return sub
+		}
+		if strings.TrimSuffix(existing.Destination, "/") == strings.TrimSuffix(destination, "/") {
+			sub.ID = member.OdataID
+			return sub
+		}
+	}
+
+	location, err := client.post(ctx, defaults.RedfishEventSubscriptionsPath, redfish.EventDestination{
+		Destination: destination,
+		EventTypes:  []string{"Alert"},
+		Context:     EventContextPrefix + server.Host,
+		Protocol:    "Redfish",
+	})
+	if err != nil {
+		sub.Error = fmt.Errorf("failed to create event subscription on %s: %w", server.Host, err)
+		return sub
+	}
+	sub.ID, sub.Created = location, true
+	client.logger.Infow("event subscription created", "subscription", location, "destination", destination)
+	return sub
+}
+
+// post sends body as JSON to the Redfish API and returns the Location of the
+// created resource. Like every iDRAC write, it is recorded in the audit log,
+// if configured.
+func (c *redfishClient) post(ctx context.Context, path string, body interface{}) (string, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode request: %w", err)
+	}
+	location, status, err := c.send(ctx, http.MethodPost, path, data)
+
+	entry := audit.Entry{
+		System:        "idrac",
+		Action:        http.MethodPost,
+		Target:        c.baseURL + path,
+		PayloadSHA256: audit.Digest(data),
+		StatusCode:    status,
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	if auditErr := c.audit.Record(entry); auditErr != nil {
+		c.logger.Errorw("failed to write audit log",
+			"method", http.MethodPost,
+			"path", path,
+			"error", auditErr,
+		)
+	}
+	return location, err
+}
+
+// send sends a write request with a JSON body and returns the Location
+// header and the HTTP status code (0 if no response was received).
+func (c *redfishClient) send(ctx context.Context, method, path string, data []byte) (string, int, error) {
+	req, err := c.newRequest(ctx, method, path, bytes.NewReader(data))
+	if err != nil {
+		return "", 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	c.logger.Debugw("making redfish request",
+		"method", method,
+		"url", c.baseURL+path,
+	)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", 0, errors.NewRedfishTransportError(c.baseURL, path, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", resp.StatusCode, fmt.Errorf("failed to read response body: %w", err)
+	}
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return "", resp.StatusCode, errors.ErrAuthenticationFailed
+	case resp.StatusCode >= 400:
+		return "", resp.StatusCode, errors.NewRedfishError(c.baseURL, path, resp.StatusCode, resp.Status, string(respBody))
+	}
+	return resp.Header.Get("Location"), resp.StatusCode, nil
+}
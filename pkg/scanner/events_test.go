@@ -0,0 +1,148 @@
+package scanner
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/braunma/idrac-netbox-importer/internal/redfish"
+	"github.com/braunma/idrac-netbox-importer/pkg/audit"
+	"github.com/braunma/idrac-netbox-importer/pkg/config"
+	"github.com/braunma/idrac-netbox-importer/pkg/errors"
+)
+
+// subscriptionServer is an iDRAC whose EventService holds the given
+// subscription destinations; created subscriptions are recorded.
+func subscriptionServer(t *testing.T, existing ...string) (*httptest.Server, *[]redfish.EventDestination) {
+	var mu sync.Mutex
+	var created []redfish.EventDestination
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case r.URL.Path == "/redfish/v1/EventService/Subscriptions" && r.Method == http.MethodGet:
+			var c redfish.Collection
+			for i := range existing {
+				c.Members = append(c.Members, redfish.Link{OdataID: "/redfish/v1/EventService/Subscriptions/" + string(rune('a'+i))})
+			}
+			json.NewEncoder(w).Encode(c)
+		case r.URL.Path == "/redfish/v1/EventService/Subscriptions" && r.Method == http.MethodPost:
+			var dest redfish.EventDestination
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&dest))
+			created = append(created, dest)
+			w.Header().Set("Location", "/redfish/v1/EventService/Subscriptions/new")
+			w.WriteHeader(http.StatusCreated)
+		case len(r.URL.Path) > len("/redfish/v1/EventService/Subscriptions/"):
+			i := int(r.URL.Path[len(r.URL.Path)-1] - 'a')
+			json.NewEncoder(w).Encode(redfish.EventDestination{Destination: existing[i]})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return server, &created
+}
+
+func TestSubscribe(t *testing.T) {
+	const destination = "https://inventory.example.com:8443/events"
+
+	fresh, created := subscriptionServer(t, "https://other.example.com/alerts")
+	defer fresh.Close()
+	subscribed, none := subscriptionServer(t, "https://other.example.com/alerts", destination+"/")
+	defer subscribed.Close()
+
+	cfg := &config.Config{
+		Servers: []config.ServerConfig{
+			{Host: fresh.Listener.Addr().String(), Name: "fresh"},
+			{Host: subscribed.Listener.Addr().String(), Name: "subscribed"},
+		},
+		Defaults:    config.DefaultsConfig{Username: "root", Password: "secret", TimeoutSeconds: 5},
+		Concurrency: 2,
+	}
+	subs := New(cfg).Subscribe(context.Background(), destination)
+	require.Len(t, subs, 2)
+
+	byName := map[string]Subscription{}
+	for _, sub := range subs {
+		require.NoError(t, sub.Error, sub.Host)
+		byName[sub.Name] = sub
+	}
+	assert.True(t, byName["fresh"].Created)
+	assert.Equal(t, "/redfish/v1/EventService/Subscriptions/new", byName["fresh"].ID)
+	assert.False(t, byName["subscribed"].Created, "an existing subscription is kept")
+	assert.Equal(t, "/redfish/v1/EventService/Subscriptions/b", byName["subscribed"].ID)
+	assert.Empty(t, *none)
+
+	require.Len(t, *created, 1)
+	assert.Equal(t, redfish.EventDestination{
+		Destination: destination,
+		EventTypes:  []string{"Alert"},
+		Context:     EventContextPrefix + fresh.Listener.Addr().String(),
+		Protocol:    "Redfish",
+	}, (*created)[0])
+
+	// Read-only mode refuses the POST before it is sent
+	readOnly, attempted := subscriptionServer(t)
+	defer readOnly.Close()
+	cfg = &config.Config{
+		Servers:     []config.ServerConfig{{Host: readOnly.Listener.Addr().String()}},
+		Defaults:    config.DefaultsConfig{Username: "root", Password: "secret", TimeoutSeconds: 5},
+		Concurrency: 1,
+		ReadOnly:    true,
+	}
+	subs = New(cfg).Subscribe(context.Background(), destination)
+	require.Len(t, subs, 1)
+	assert.ErrorIs(t, subs[0].Error, errors.ErrReadOnly)
+	assert.Empty(t, *attempted)
+}
+
+func TestSubscribe_AuditLog(t *testing.T) {
+	server, created := subscriptionServer(t)
+	defer server.Close()
+	readOnly, attempted := subscriptionServer(t)
+	defer readOnly.Close()
+
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	log := audit.New(path, "tester")
+	for _, tt := range []struct {
+		server   *httptest.Server
+		readOnly bool
+	}{{server, false}, {readOnly, true}} {
+		cfg := &config.Config{
+			Servers:     []config.ServerConfig{{Host: tt.server.Listener.Addr().String()}},
+			Defaults:    config.DefaultsConfig{Username: "root", Password: "secret", TimeoutSeconds: 5},
+			Concurrency: 1,
+			ReadOnly:    tt.readOnly,
+		}
+		New(cfg).WithAuditLog(log).Subscribe(context.Background(), "https://inventory.example.com/events")
+	}
+	require.Len(t, *created, 1)
+	assert.Empty(t, *attempted)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+
+	var entries [2]audit.Entry
+	for i, line := range lines {
+		require.NoError(t, json.Unmarshal([]byte(line), &entries[i]))
+		assert.Equal(t, "idrac", entries[i].System)
+		assert.Equal(t, http.MethodPost, entries[i].Action)
+		assert.Equal(t, "tester", entries[i].Actor)
+		assert.NotEmpty(t, entries[i].PayloadSHA256)
+	}
+	assert.Equal(t, "https://"+server.Listener.Addr().String()+"/redfish/v1/EventService/Subscriptions", entries[0].Target)
+	assert.Equal(t, audit.ResultSuccess, entries[0].Result)
+	assert.Equal(t, http.StatusCreated, entries[0].StatusCode)
+	assert.Equal(t, audit.ResultFailure, entries[1].Result, "refused in read-only mode")
+	assert.Contains(t, entries[1].Error, "read-only")
+}
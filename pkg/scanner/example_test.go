@@ -0,0 +1,35 @@
+package scanner_test
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/config"
+	"github.com/braunma/idrac-netbox-importer/pkg/netbox"
+	"github.com/braunma/idrac-netbox-importer/pkg/scanner"
+)
+
+// Example shows how another tool can collect inventory and push it to
+// NetBox without going through the CLI.
+func Example() {
+	cfg := &config.Config{
+		Servers: []config.ServerConfig{
+			{Host: "10.0.0.10", Username: "root", Password: "calvin"},
+		},
+		NetBox: config.NetBoxConfig{
+			URL:   "https://netbox.example.com",
+			Token: "0123456789abcdef",
+		},
+	}
+
+	ctx := context.Background()
+	results, stats := scanner.New(cfg).ScanAll(ctx)
+	fmt.Printf("scanned %d of %d servers\n", stats.SuccessfulCount, stats.TotalServers)
+
+	client := netbox.NewClient(cfg.NetBox)
+	for _, r := range client.SyncAll(ctx, results) {
+		if !r.Success {
+			fmt.Printf("%s: %v\n", r.Host, r.Error)
+		}
+	}
+}
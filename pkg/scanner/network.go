@@ -0,0 +1,109 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/braunma/idrac-netbox-importer/internal/redfish"
+	"github.com/braunma/idrac-netbox-importer/pkg/errors"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+// errNoManager is returned by the iDRAC network collector for systems without
+// a manager link.
+var errNoManager = fmt.Errorf("system has no manager link")
+
+// collectIDRACNetwork reads the firmware version of the iDRAC from the
+// manager and the network settings from the first enabled EthernetInterface
+// of the manager and, on Dell iDRACs, the NIC selection from the manager
+// attributes.
+func (s *Scanner) collectIDRACNetwork(ctx context.Context, client *redfishClient, info *models.ServerInfo) error {
+	if client.system.Manager == "" {
+		return errNoManager
+	}
+
+	var manager redfish.Manager
+	if err := client.get(ctx, client.system.Manager, &manager); err != nil {
+		return errors.NewCollectionError(info.Host, models.PhaseNetwork, err)
+	}
+	info.IDRACFirmwareVersion = manager.FirmwareVersion
+
+	interfaces := manager.EthernetInterfaces.OdataID
+	if interfaces == "" {
+		interfaces = client.system.Manager + "/EthernetInterfaces"
+	}
+
+	var collection redfish.Collection
+	if err := client.get(ctx, interfaces, &collection); err != nil {
+		return errors.NewCollectionError(info.Host, models.PhaseNetwork, err)
+	}
+
+	var nic *redfish.EthernetInterface
+	for _, member := range collection.Members {
+		var iface redfish.EthernetInterface
+		if err := client.getMember(ctx, member.OdataID, &iface); err != nil {
+			client.logger.Debugw("failed to get iDRAC interface", "path", member.OdataID, "error", err)
+			continue
+		}
+		if iface.InterfaceEnabled != nil && !*iface.InterfaceEnabled {
+			continue
+		}
+		nic = &iface
+		break
+	}
+	if nic == nil {
+		return errors.NewCollectionError(info.Host, models.PhaseNetwork, fmt.Errorf("no enabled iDRAC interface"))
+	}
+
+	network := &models.IDRACNetworkInfo{
+		Interface:   nic.ID,
+		MACAddress:  nic.MACAddress,
+		HostName:    nic.HostName,
+		FQDN:        nic.FQDN,
+		DHCP:        nic.DHCPv4.DHCPEnabled,
+		VLANEnabled: nic.VLAN.VLANEnable,
+		NameServers: nonEmpty(nic.NameServers),
+		SpeedMbps:   nic.SpeedMbps,
+	}
+	if network.VLANEnabled {
+		network.VLANID = nic.VLAN.VLANID
+	}
+	if len(nic.IPv4Addresses) > 0 {
+		addr := nic.IPv4Addresses[0]
+		network.IPv4Address = addr.Address
+		network.SubnetMask = addr.SubnetMask
+		network.Gateway = addr.Gateway
+	}
+
+	// The NIC selection is only in the Dell attribute registry
+	var attrs redfish.ManagerAttributes
+	if err := client.get(ctx, client.system.Manager+"/Attributes", &attrs); err != nil {
+		client.logger.Debugw("failed to read iDRAC attributes", "error", err)
+	} else if v, ok := attrs.Attributes["NIC.1.Selection"].(string); ok {
+		network.NICSelection = v
+	}
+	info.IDRACNetwork = network
+
+	client.logger.Infow("extracted iDRAC network information",
+		"interface", network.Interface,
+		"dns_name", network.DNSName(),
+		"dhcp", network.DHCP,
+		"vlan_enabled", network.VLANEnabled,
+		"vlan_id", network.VLANID,
+		"nic_selection", network.NICSelection,
+	)
+	return nil
+}
+
+// nonEmpty drops the unset name servers iDRACs report ("", "0.0.0.0", "::").
+func nonEmpty(values []string) []string {
+	var out []string
+	for _, v := range values {
+		switch v {
+		case "", "0.0.0.0", "::":
+			continue
+		}
+		out = append(out, v)
+	}
+	return out
+}
@@ -0,0 +1,65 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/config"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+func TestCollectIDRACNetwork(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/redfish/v1/Managers/iDRAC.Embedded.1":
+			fmt.Fprint(w, `{"Id": "iDRAC.Embedded.1", "FirmwareVersion": "6.10.30.00"}`)
+		case "/redfish/v1/Managers/iDRAC.Embedded.1/EthernetInterfaces":
+			fmt.Fprint(w, `{"Members": [
+				{"@odata.id": "/redfish/v1/Managers/iDRAC.Embedded.1/EthernetInterfaces/NIC.0"},
+				{"@odata.id": "/redfish/v1/Managers/iDRAC.Embedded.1/EthernetInterfaces/NIC.1"}
+			]}`)
+		case "/redfish/v1/Managers/iDRAC.Embedded.1/EthernetInterfaces/NIC.0":
+			fmt.Fprint(w, `{"Id": "NIC.0", "InterfaceEnabled": false}`)
+		case "/redfish/v1/Managers/iDRAC.Embedded.1/EthernetInterfaces/NIC.1":
+			fmt.Fprint(w, `{
+				"Id": "NIC.1",
+				"HostName": "idrac-abc1234",
+				"FQDN": "idrac-abc1234.mgmt.example.com",
+				"InterfaceEnabled": true,
+				"DHCPv4": {"DHCPEnabled": false},
+				"IPv4Addresses": [{"Address": "192.168.1.100", "SubnetMask": "255.255.255.0", "Gateway": "192.168.1.1"}],
+				"VLAN": {"VLANEnable": true, "VLANId": 20},
+				"NameServers": ["192.168.1.53", "0.0.0.0", "::"]
+			}`)
+		case "/redfish/v1/Managers/iDRAC.Embedded.1/Attributes":
+			fmt.Fprint(w, `{"Attributes": {"NIC.1.Selection": "Dedicated"}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	s := New(&config.Config{})
+	client := newTestClient(server)
+	var info models.ServerInfo
+	require.NoError(t, s.collectIDRACNetwork(context.Background(), client, &info))
+	assert.Equal(t, "6.10.30.00", info.IDRACFirmwareVersion)
+	network := info.IDRACNetwork
+	require.NotNil(t, network)
+	assert.Equal(t, "NIC.1", network.Interface, "disabled interfaces are skipped")
+	assert.Equal(t, "idrac-abc1234.mgmt.example.com", network.DNSName())
+	assert.False(t, network.DHCP)
+	assert.Equal(t, "192.168.1.100", network.IPv4Address)
+	assert.Equal(t, 20, network.VLANID)
+	assert.Equal(t, []string{"192.168.1.53"}, network.NameServers, "unset name servers are dropped")
+	assert.Equal(t, "Dedicated", network.NICSelection)
+
+	client.system.Manager = ""
+	assert.ErrorIs(t, s.collectIDRACNetwork(context.Background(), client, &info), errNoManager)
+}
@@ -0,0 +1,61 @@
+package scanner
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/braunma/idrac-netbox-importer/internal/redfish"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+// nvmeTelemetry returns the link and endurance telemetry of an NVMe drive
+// from the standard Redfish properties and the Dell OEM DellPCIeSSD (or
+// DellPhysicalDisk) data. The namespace count is the number of volumes
+// linked from the drive, where the BMC exposes them.
+func nvmeTelemetry(drive redfish.Drive) *models.NVMeInfo {
+	info := &models.NVMeInfo{
+		NegotiatedSpeedGbs: drive.NegotiatedSpeedGbs,
+		Namespaces:         len(drive.Links.Volumes),
+		FailurePredicted:   drive.FailurePredicted,
+	}
+
+	endurance := -1
+	if dell := drive.Oem.Dell; dell != nil {
+		if ssd := dell.DellPCIeSSD; ssd != nil {
+			info.LinkSpeedGTs = parseTransferRate(ssd.PCIeNegotiatedLinkSpeed)
+			info.CapableLinkSpeedGTs = parseTransferRate(ssd.PCIeCapableLinkSpeed)
+			info.LinkWidth = parseLinkWidth(ssd.PCIeNegotiatedLinkWidth)
+			info.CapableLinkWidth = parseLinkWidth(ssd.PCIeCapableLinkWidth)
+			endurance = ssd.RemainingRatedWriteEndurancePercent
+		} else if disk := dell.DellPhysicalDisk; disk != nil {
+			endurance = disk.RemainingRatedWriteEndurancePercent
+		}
+	}
+	// Dell reports 255 for drives without the SMART attribute
+	if endurance >= 0 && endurance <= 100 {
+		info.EnduranceLeftPct = &endurance
+	}
+	return info
+}
+
+// parseTransferRate parses a PCIe transfer rate like "16 GT/s" or
+// "8.0 GT/s", returning 0 for "Unknown" and other values.
+func parseTransferRate(s string) float64 {
+	s = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(s), "GT/s"))
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || v <= 0 {
+		return 0
+	}
+	return v
+}
+
+// parseLinkWidth parses a PCIe link width like "x4", returning 0 if it is
+// not reported.
+func parseLinkWidth(s string) int {
+	s = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(s)), "x")
+	n, err := strconv.Atoi(s)
+	if err != nil || n <= 0 {
+		return 0
+	}
+	return n
+}
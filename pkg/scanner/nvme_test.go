@@ -0,0 +1,51 @@
+package scanner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/braunma/idrac-netbox-importer/internal/redfish"
+)
+
+func TestNVMeTelemetry(t *testing.T) {
+	drive := redfish.Drive{
+		Protocol:           "NVMe",
+		NegotiatedSpeedGbs: 64,
+		Links:              redfish.DriveLinks{Volumes: []redfish.Link{{OdataID: "/v/1"}, {OdataID: "/v/2"}}},
+		Oem: redfish.DriveOEM{Dell: &redfish.DellDriveOEM{DellPCIeSSD: &redfish.DellPCIeSSD{
+			PCIeNegotiatedLinkSpeed:             "8 GT/s",
+			PCIeCapableLinkSpeed:                "16.0 GT/s",
+			PCIeNegotiatedLinkWidth:             "x4",
+			PCIeCapableLinkWidth:                "x4",
+			RemainingRatedWriteEndurancePercent: 97,
+		}}},
+	}
+
+	info := nvmeTelemetry(drive)
+	assert.Equal(t, 8.0, info.LinkSpeedGTs)
+	assert.Equal(t, 16.0, info.CapableLinkSpeedGTs)
+	assert.Equal(t, 4, info.LinkWidth)
+	assert.Equal(t, 4, info.CapableLinkWidth)
+	assert.Equal(t, 64.0, info.NegotiatedSpeedGbs)
+	assert.Equal(t, 2, info.Namespaces)
+	require.NotNil(t, info.EnduranceLeftPct)
+	assert.Equal(t, 97, *info.EnduranceLeftPct)
+	assert.True(t, info.Degraded())
+
+	// 255 means not reported; links without the Dell OEM data are unknown
+	drive.Oem.Dell = &redfish.DellDriveOEM{DellPhysicalDisk: &redfish.DellPhysicalDisk{RemainingRatedWriteEndurancePercent: 255}}
+	info = nvmeTelemetry(drive)
+	assert.Nil(t, info.EnduranceLeftPct)
+	assert.Zero(t, info.LinkWidth)
+	assert.Equal(t, "64 Gbit/s", info.Link())
+}
+
+func TestParseLinkValues(t *testing.T) {
+	assert.Equal(t, 32.0, parseTransferRate("32 GT/s"))
+	assert.Zero(t, parseTransferRate("Unknown"))
+	assert.Equal(t, 8, parseLinkWidth("x8"))
+	assert.Equal(t, 4, parseLinkWidth("4"))
+	assert.Zero(t, parseLinkWidth(""))
+}
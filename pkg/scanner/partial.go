@@ -0,0 +1,64 @@
+package scanner
+
+import (
+	"github.com/braunma/idrac-netbox-importer/pkg/errors"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+// corePhases are the hardware collectors of which at least one must succeed
+// for a host whose system resource failed to count as a partial result.
+var corePhases = []string{models.PhaseProcessors, models.PhaseMemory, models.PhaseStorage}
+
+// hostUnreachable reports whether a failed system resource means that the
+// other collectors cannot succeed either: rejected credentials, TLS and
+// network errors, or a canceled or timed out scan. More requests would only
+// cost time, and failed logins count towards the iDRAC's IP blocking.
+func hostUnreachable(err error) bool {
+	switch errors.Categorize(err) {
+	case errors.CategoryAuth, errors.CategoryTLS, errors.CategoryDNS, errors.CategoryConnection,
+		errors.CategoryTimeout, errors.CategoryCanceled:
+		return true
+	}
+	return false
+}
+
+// recordComponentError records a failed collector in info.ComponentErrors,
+// keyed by its phase. For optional collectors, data the BMC does not
+// provide (a missing endpoint, no chassis or manager link) is not an error.
+func recordComponentError(info *models.ServerInfo, phase string, err error, optional bool) {
+	if optional && (err == errNoChassis || err == errNoManager || errors.Categorize(err) == errors.CategoryEndpointMissing) {
+		return
+	}
+	if ce, ok := err.(*errors.CollectionError); ok {
+		err = ce.Err
+	}
+	info.SetComponentError(phase, err)
+}
+
+// recordCapability records whether the iDRAC supports a collector phase:
+// unsupported if its endpoint is missing, degraded if it succeeded although
+// some endpoints answered 404 or 501, supported otherwise. A phase that
+// failed for another reason (e.g. a timeout) tells nothing about support and
+// is not recorded.
+func recordCapability(info *models.ServerInfo, phase string, err error, missing []models.UnsupportedEndpoint) {
+	state := models.CapabilitySupported
+	switch {
+	case err == errNoChassis || err == errNoManager || errors.Categorize(err) == errors.CategoryEndpointMissing:
+		state = models.CapabilityUnsupported
+	case err != nil:
+		return
+	case len(missing) > 0:
+		state = models.CapabilityDegraded
+	}
+	info.SetCapability(phase, state, missing)
+}
+
+// allFailed reports whether all of the phases failed.
+func allFailed(info *models.ServerInfo, phases []string) bool {
+	for _, phase := range phases {
+		if _, failed := info.ComponentErrors[phase]; !failed {
+			return false
+		}
+	}
+	return true
+}
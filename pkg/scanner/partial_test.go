@@ -0,0 +1,67 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/errors"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+func TestHostUnreachable(t *testing.T) {
+	assert.True(t, hostUnreachable(errors.NewCollectionError("h", "system", errors.ErrAuthenticationFailed)))
+	assert.True(t, hostUnreachable(errors.NewCollectionError("h", "system", context.DeadlineExceeded)))
+	assert.False(t, hostUnreachable(errors.NewCollectionError("h", "system", errors.NewRedfishError("https://h", "/s", 500, "500 Internal Server Error", ""))))
+	assert.False(t, hostUnreachable(errors.NewCollectionError("h", "system", errors.ErrNotFound)))
+}
+
+func TestRecordComponentError(t *testing.T) {
+	var info models.ServerInfo
+
+	recordComponentError(&info, models.PhasePCIeSlots, errors.NewCollectionError("h", "pcie_slots", errors.ErrNotFound), true)
+	recordComponentError(&info, models.PhaseNetwork, errNoManager, true)
+	assert.Empty(t, info.ComponentErrors, "optional data the BMC does not provide is no error")
+
+	recordComponentError(&info, models.PhaseMemory, errors.NewCollectionError("h", "memory", fmt.Errorf("read timeout")), false)
+	recordComponentError(&info, models.PhaseStorage, errors.NewCollectionError("h", "storage", errors.ErrNotFound), false)
+	assert.Equal(t, map[string]models.ComponentError{
+		models.PhaseMemory:  {Code: "E_TIMEOUT", Message: "read timeout"},
+		models.PhaseStorage: {Code: "E_REDFISH_404", Message: errors.ErrNotFound.Error()},
+	}, info.ComponentErrors)
+	assert.True(t, info.IsPartial())
+
+	assert.False(t, allFailed(&info, corePhases))
+	recordComponentError(&info, models.PhaseProcessors, fmt.Errorf("bad JSON"), false)
+	assert.True(t, allFailed(&info, corePhases))
+}
+
+func TestRecordCapability(t *testing.T) {
+	var info models.ServerInfo
+	missing := []models.UnsupportedEndpoint{{Path: "/redfish/v1/Chassis/System.Embedded.1/Power", Status: 404}}
+
+	recordCapability(&info, models.PhaseMemory, nil, nil)
+	recordCapability(&info, models.PhasePower, nil, missing)
+	recordCapability(&info, models.PhasePCIeSlots, errors.NewCollectionError("h", "pcie_slots", errors.ErrNotFound), nil)
+	recordCapability(&info, models.PhaseLicense, errors.NewRedfishError("https://h", "/l", 501, "501 Not Implemented", ""), nil)
+	recordCapability(&info, models.PhaseNetwork, errNoManager, nil)
+	recordCapability(&info, models.PhaseStorage, fmt.Errorf("read timeout"), nil)
+
+	assert.Equal(t, map[string]string{
+		models.PhaseMemory:    models.CapabilitySupported,
+		models.PhasePower:     models.CapabilityDegraded,
+		models.PhasePCIeSlots: models.CapabilityUnsupported,
+		models.PhaseLicense:   models.CapabilityUnsupported,
+		models.PhaseNetwork:   models.CapabilityUnsupported,
+	}, info.Capabilities, "a timeout tells nothing about support")
+	assert.Equal(t, []models.UnsupportedEndpoint{
+		{Phase: models.PhasePower, Path: "/redfish/v1/Chassis/System.Embedded.1/Power", Status: 404},
+	}, info.UnsupportedEndpoints)
+
+	// A phase that runs again replaces its endpoints
+	recordCapability(&info, models.PhasePower, nil, nil)
+	assert.Equal(t, models.CapabilitySupported, info.Capabilities[models.PhasePower])
+	assert.Empty(t, info.UnsupportedEndpoints)
+}
@@ -0,0 +1,90 @@
+package scanner
+
+import (
+	"context"
+	"math"
+
+	"github.com/braunma/idrac-netbox-importer/internal/redfish"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+// collectPowerSubsystem reads the power supplies from the PowerSubsystem of
+// the chassis and the power draw from its EnvironmentMetrics, which replace
+// the Power resource in newer firmware. It returns false if the chassis does
+// not implement PowerSubsystem or it cannot be read; info is then unchanged.
+func (s *Scanner) collectPowerSubsystem(ctx context.Context, client *redfishClient, info *models.ServerInfo) (bool, error) {
+	var chassis redfish.Chassis
+	if err := client.get(ctx, client.system.Chassis, &chassis); err != nil {
+		return false, err
+	}
+	if chassis.PowerSubsystem.OdataID == "" {
+		return false, nil
+	}
+
+	var subsystem redfish.PowerSubsystem
+	if err := client.get(ctx, chassis.PowerSubsystem.OdataID, &subsystem); err != nil {
+		return false, err
+	}
+
+	var psus []models.PowerSupplyInfo
+	if subsystem.PowerSupplies.OdataID != "" {
+		var collection redfish.Collection
+		if err := client.get(ctx, subsystem.PowerSupplies.OdataID, &collection); err != nil {
+			return false, err
+		}
+		for _, member := range collection.Members {
+			var psu redfish.SubsystemPowerSupply
+			if err := client.getMember(ctx, member.OdataID, &psu); err != nil {
+				return false, err
+			}
+			p := models.PowerSupplyInfo{
+				Name:            psu.Name,
+				Model:           psu.Model,
+				Manufacturer:    psu.Manufacturer,
+				PartNumber:      psu.PartNumber,
+				SparePartNumber: psu.SparePartNumber,
+				SerialNumber:    psu.SerialNumber,
+				FirmwareVersion: psu.FirmwareVersion,
+				CapacityWatts:   psu.PowerCapacityWatts,
+				Health:          psu.Status.Health,
+				State:           psu.Status.State,
+			}
+			if psu.Metrics.OdataID != "" {
+				var metrics redfish.PowerSupplyMetrics
+				if err := client.get(ctx, psu.Metrics.OdataID, &metrics); err != nil {
+					client.logger.Debugw("failed to read power supply metrics", "psu", psu.Name, "error", err)
+				} else if r := metrics.InputPowerWatts.Reading; r != nil {
+					p.InputWatts = *r
+				}
+			}
+			psus = append(psus, p)
+		}
+	}
+	info.PowerSupplies = psus
+
+	if chassis.EnvironmentMetrics.OdataID != "" {
+		var env redfish.EnvironmentMetrics
+		if err := client.get(ctx, chassis.EnvironmentMetrics.OdataID, &env); err != nil {
+			client.logger.Debugw("failed to read environment metrics", "error", err)
+		} else if r := env.PowerWatts.Reading; r != nil {
+			info.PowerConsumedWatts = int(math.Round(*r))
+
+			// The peak is only in the full sensor resource
+			if uri := env.PowerWatts.DataSourceURI; uri != "" {
+				var sensor redfish.Sensor
+				if err := client.get(ctx, uri, &sensor); err != nil {
+					client.logger.Debugw("failed to read power sensor", "error", err)
+				} else if sensor.PeakReading != nil {
+					info.PowerPeakWatts = int(math.Round(*sensor.PeakReading))
+				}
+			}
+		}
+	}
+
+	client.logger.Infow("extracted power information from PowerSubsystem",
+		"power_consumed_watts", info.PowerConsumedWatts,
+		"power_peak_watts", info.PowerPeakWatts,
+		"psu_count", len(info.PowerSupplies),
+	)
+	return true, nil
+}
@@ -0,0 +1,70 @@
+package scanner
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/braunma/idrac-netbox-importer/internal/redfish"
+	"github.com/braunma/idrac-netbox-importer/pkg/config"
+	"github.com/braunma/idrac-netbox-importer/pkg/defaults"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+// QueryPowerStates reads the power state of all configured servers. Each host
+// costs two requests, the service root and the System resource, so it is
+// much faster than a scan. It never changes the power state; callers should
+// still create the scanner in read-only mode. Results are sorted by host.
+func (s *Scanner) QueryPowerStates(ctx context.Context) []models.PowerStateCheck {
+	s.logger.Infow("querying power states", "server_count", len(s.cfg.Servers))
+
+	results := make([]models.PowerStateCheck, 0, len(s.cfg.Servers))
+	var mu sync.Mutex
+	s.eachServer(func(server config.ServerConfig) {
+		check := s.queryPowerState(ctx, server)
+		mu.Lock()
+		results = append(results, check)
+		mu.Unlock()
+	})
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Host < results[j].Host
+	})
+	return results
+}
+
+// queryPowerState reads the power state of one server.
+func (s *Scanner) queryPowerState(ctx context.Context, server config.ServerConfig) models.PowerStateCheck {
+	check := models.PowerStateCheck{
+		Host: server.Host,
+		Name: server.Name,
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, server.GetTimeout(s.cfg.Defaults.Timeout()))
+	defer cancel()
+
+	log := s.hostLogger(server.Host)
+	client := s.newClient(server, log)
+
+	// The service root fails fast on unreachable hosts and bad credentials
+	var root redfish.ServiceRoot
+	var system redfish.System
+	start := time.Now()
+	err := client.get(ctx, defaults.RedfishBasePath, &root)
+	if err == nil {
+		err = client.get(ctx, defaults.RedfishSystemPath, &system)
+	}
+	check.Latency = time.Since(start)
+	if err != nil {
+		check.Error = err
+		return check
+	}
+	check.PowerState = system.PowerState
+	check.Health = system.Status.Health
+	check.Model = s.modelNames.Canonical(system.Manufacturer, system.Model)
+	check.ServiceTag = system.SKU
+
+	log.Debugw("power state queried", "power_state", check.PowerState)
+	return check
+}
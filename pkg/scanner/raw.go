@@ -0,0 +1,132 @@
+package scanner
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/config"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+// rawCapture records the Redfish responses of one system's scan for the JSON
+// output (output.raw). Each response is pruned, checked against the size
+// limits and stored under the collector phase that read it.
+type rawCapture struct {
+	cfg   config.RawOutputConfig
+	prune map[string]bool
+
+	mu       sync.Mutex
+	phase    string
+	payloads models.RawPayloads
+}
+
+func newRawCapture(cfg config.RawOutputConfig) *rawCapture {
+	prune := make(map[string]bool, len(cfg.Prune))
+	for _, p := range cfg.Prune {
+		prune[p] = true
+	}
+	return &rawCapture{cfg: cfg, prune: prune}
+}
+
+// start sets the collector phase the following responses belong to.
+func (r *rawCapture) start(phase string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.phase = phase
+}
+
+// record stores the response body of path. Bodies that are not JSON objects
+// are ignored.
+func (r *rawCapture) record(path string, body []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.phase == "" || !r.cfg.IncludesPhase(r.phase) {
+		return
+	}
+
+	data, ok := r.pruned(body)
+	if !ok {
+		return
+	}
+	switch {
+	case len(data) > r.cfg.GetMaxResourceBytes():
+		r.omit(path, len(data), models.RawOmittedResourceLimit)
+	case r.payloads.Bytes+len(data) > r.cfg.GetMaxHostBytes():
+		r.omit(path, len(data), models.RawOmittedHostLimit)
+	default:
+		r.payloads.Add(r.phase, path, data)
+	}
+}
+
+func (r *rawCapture) omit(path string, size int, reason string) {
+	r.payloads.Omitted = append(r.payloads.Omitted, models.RawOmission{
+		Phase:  r.phase,
+		Path:   path,
+		Bytes:  size,
+		Reason: reason,
+	})
+}
+
+// pruned returns the compacted body without the properties of
+// output.raw.prune, or false if the body is not a JSON object.
+func (r *rawCapture) pruned(body []byte) (json.RawMessage, bool) {
+	if len(r.prune) == 0 {
+		var out bytes.Buffer
+		if err := json.Compact(&out, body); err != nil || out.Len() == 0 || out.Bytes()[0] != '{' {
+			return nil, false
+		}
+		return out.Bytes(), true
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber() // keep large integers intact
+	var v map[string]interface{}
+	if err := dec.Decode(&v); err != nil || v == nil {
+		return nil, false
+	}
+	r.drop(v)
+
+	var out bytes.Buffer
+	enc := json.NewEncoder(&out)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, false
+	}
+	return bytes.TrimRight(out.Bytes(), "\n"), true
+}
+
+// drop removes the pruned properties from v at any depth.
+func (r *rawCapture) drop(v interface{}) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			if r.prune[k] {
+				delete(v, k)
+				continue
+			}
+			r.drop(child)
+		}
+	case []interface{}:
+		for _, child := range v {
+			r.drop(child)
+		}
+	}
+}
+
+// result returns the recorded responses, or nil if there are none or the
+// capture is disabled (nil). prev are the responses of the full scan of an
+// unchanged server; those of the phases that did not run again are kept.
+func (r *rawCapture) result(prev *models.RawPayloads) *models.RawPayloads {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p := r.payloads
+	p.KeepPhases(prev)
+	if p.Resources == nil && p.Omitted == nil {
+		return nil
+	}
+	return &p
+}
@@ -0,0 +1,91 @@
+package scanner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/config"
+	"github.com/braunma/idrac-netbox-importer/pkg/logging"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+func TestRedfishClient_RawCapture(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/big":
+			w.Write([]byte(`{"Id": "big", "Data": "` + strings.Repeat("x", 2048) + `"}`))
+		default:
+			w.Write([]byte(`{"@odata.id": "` + r.URL.Path + `", "@odata.etag": "W/\"1\"", "Id": "DIMM",
+				"CapacityMiB": 32768, "Oem": {"Dell": {"DellMemory": {"Id": "DIMM"}}},
+				"Ports": [{"Oem": {}, "Serial": 12345678901234567890}]}`))
+		}
+	}))
+	defer server.Close()
+
+	raw := newRawCapture(config.RawOutputConfig{
+		Enabled:       true,
+		Phases:        []string{models.PhaseMemory},
+		Prune:         []string{"@odata.etag", "Oem"},
+		MaxResourceKB: 1,
+		MaxHostKB:     1,
+	})
+	client := &redfishClient{
+		baseURL:    server.URL,
+		httpClient: server.Client(),
+		logger:     logging.WithComponent("test"),
+		raw:        raw,
+	}
+	ctx := context.Background()
+
+	raw.start(models.PhaseSystem)
+	require.NoError(t, client.get(ctx, "/system", nil))
+	raw.start(models.PhaseMemory)
+	require.NoError(t, client.get(ctx, "/big", nil))
+	for i := 1; i <= 16; i++ {
+		require.NoError(t, client.get(ctx, fmt.Sprintf("/dimm/a%d", i), nil))
+	}
+
+	result := raw.result(nil)
+	require.NotNil(t, result)
+	assert.NotContains(t, result.Resources, models.PhaseSystem, "only the configured phases are captured")
+	assert.JSONEq(t, `{"@odata.id": "/dimm/a1", "Id": "DIMM", "CapacityMiB": 32768, "Ports": [{"Serial": 12345678901234567890}]}`,
+		string(result.Resources[models.PhaseMemory]["/dimm/a1"]))
+	assert.LessOrEqual(t, result.Bytes, 1024)
+
+	require.NotEmpty(t, result.Omitted)
+	assert.Equal(t, models.RawOmission{Phase: models.PhaseMemory, Path: "/big", Bytes: 2070, Reason: models.RawOmittedResourceLimit}, result.Omitted[0])
+	last := result.Omitted[len(result.Omitted)-1]
+	assert.Equal(t, "/dimm/a16", last.Path)
+	assert.Equal(t, models.RawOmittedHostLimit, last.Reason)
+
+	data, err := json.Marshal(models.ServerInfo{Host: "10.0.0.1", Raw: result})
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"raw":{"resources":{"memory":{"/dimm/a1":{`)
+}
+
+func TestRawCapture_KeepsPhasesOfUnchangedServer(t *testing.T) {
+	prev := &models.RawPayloads{}
+	prev.Add(models.PhaseMemory, "/dimm/a1", json.RawMessage(`{"Id":"old"}`))
+	prev.Add(models.PhasePower, "/power", json.RawMessage(`{"Id":"old"}`))
+
+	raw := newRawCapture(config.RawOutputConfig{Enabled: true})
+	raw.start(models.PhasePower)
+	raw.record("/power", []byte(`{"Id": "new"}`))
+
+	result := raw.result(prev)
+	require.NotNil(t, result)
+	assert.JSONEq(t, `{"Id":"new"}`, string(result.Resources[models.PhasePower]["/power"]))
+	assert.JSONEq(t, `{"Id":"old"}`, string(result.Resources[models.PhaseMemory]["/dimm/a1"]))
+	assert.Equal(t, len(`{"Id":"new"}`)+len(`{"Id":"old"}`), result.Bytes)
+
+	var disabled *rawCapture
+	assert.Nil(t, disabled.result(prev), "responses of an earlier scan are dropped once raw output is disabled")
+}
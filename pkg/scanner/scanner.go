@@ -0,0 +1,1871 @@
+// Package scanner provides functionality for scanning Dell iDRAC servers
+// via the Redfish API to collect hardware inventory information.
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/braunma/idrac-netbox-importer/internal/history"
+	"github.com/braunma/idrac-netbox-importer/internal/readonly"
+	"github.com/braunma/idrac-netbox-importer/internal/redfish"
+	"github.com/braunma/idrac-netbox-importer/pkg/audit"
+	"github.com/braunma/idrac-netbox-importer/pkg/config"
+	"github.com/braunma/idrac-netbox-importer/pkg/defaults"
+	"github.com/braunma/idrac-netbox-importer/pkg/errors"
+	"github.com/braunma/idrac-netbox-importer/pkg/logging"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+	"github.com/braunma/idrac-netbox-importer/pkg/runid"
+)
+
+// Scanner manages hardware inventory scanning across multiple iDRAC servers.
+type Scanner struct {
+	cfg         *config.Config
+	concurrency int
+	httpClient  *http.Client
+	logger      *zap.SugaredLogger
+
+	// etags caches member resources for conditional requests (nil if disabled)
+	etags *etagCache
+
+	// collectors are the registered custom collectors that are not disabled
+	collectors []Collector
+
+	// certs holds the transports of servers with a client certificate
+	certs *certTransports
+
+	// baselinePath is the history file read at the start of each scan to
+	// skip unchanged hosts ("" if disabled); baseline is its content
+	baselinePath string
+	baseline     *history.Store
+
+	// modelNames maps the reported model names to canonical ones
+	modelNames models.ModelNames
+
+	// stopAt is when workers stop starting new hosts (zero: never)
+	stopAt time.Time
+
+	// audit records the writes to iDRACs (nil if auditing is disabled)
+	audit *audit.Logger
+}
+
+// newTransport returns the transport for iDRAC requests, presenting the
+// client certificates if any. In read-only mode it refuses anything but GET,
+// so no code path can write to an iDRAC.
+func newTransport(cfg *config.Config, certs []tls.Certificate) http.RoundTripper {
+	var transport http.RoundTripper = &http.Transport{
+		TLSClientConfig: &tls.Config{
+			InsecureSkipVerify: cfg.Defaults.GetInsecureSkipVerify(),
+			Certificates:       certs,
+		},
+		MaxIdleConns:        cfg.HTTP.GetMaxIdleConns(),
+		IdleConnTimeout:     cfg.HTTP.GetIdleConnTimeout(),
+		MaxIdleConnsPerHost: 2,
+	}
+	if cfg.ReadOnly {
+		transport = readonly.Transport(transport)
+	}
+	return transport
+}
+
+// New creates a new Scanner instance with the provided configuration.
+func New(cfg *config.Config) *Scanner {
+	// Set concurrency, defaulting to 5 if not configured
+	concurrency := cfg.Concurrency
+	if concurrency <= 0 {
+		concurrency = 5
+	}
+
+	// Create HTTP client with connection pooling and TLS config
+	httpClient := &http.Client{
+		Timeout:   cfg.Defaults.Timeout(),
+		Transport: newTransport(cfg, nil),
+	}
+
+	s := &Scanner{
+		cfg:         cfg,
+		concurrency: concurrency,
+		httpClient:  httpClient,
+		certs:       &certTransports{},
+		logger:      logging.WithComponent("scanner"),
+		collectors:  collectors.list(cfg.Collectors.Disabled),
+		modelNames:  models.NewModelNames(cfg.Catalog.Aliases),
+	}
+
+	if cfg.History.Enabled && cfg.History.SkipUnchanged {
+		s.baselinePath = cfg.History.GetPath(cfg.Paths.GetStateDir())
+	}
+
+	if cfg.HTTP.ETagCache {
+		path := filepath.Join(cfg.Paths.GetCacheDir(), defaults.DefaultETagCacheFile)
+		etags, err := loadETagCache(path, defaults.DefaultETagCacheMaxEntries)
+		if err != nil {
+			s.logger.Warnw("ignoring unreadable ETag cache", "path", path, "error", err)
+		}
+		s.etags = etags
+	}
+
+	return s
+}
+
+// ForServers returns a scanner for a subset of the configured servers. It
+// shares the HTTP client and ETag cache of s.
+func (s *Scanner) ForServers(servers []config.ServerConfig) *Scanner {
+	cfg := *s.cfg
+	cfg.Servers = servers
+	sub := *s
+	sub.cfg = &cfg
+	return &sub
+}
+
+// WithDeadline returns a scanner that does not start scanning hosts after
+// t. Scans already running finish; the hosts that were not started are left
+// out of the results. It shares the HTTP client and ETag cache of s.
+func (s *Scanner) WithDeadline(t time.Time) *Scanner {
+	sub := *s
+	sub.stopAt = t
+	return &sub
+}
+
+// WithAuditLog returns a scanner recording its iDRAC writes (e.g. event
+// subscriptions) in l. It shares the HTTP client and ETag cache of s.
+func (s *Scanner) WithAuditLog(l *audit.Logger) *Scanner {
+	sub := *s
+	sub.audit = l
+	return &sub
+}
+
+// ScanAll scans all configured servers in parallel and returns the results with statistics.
+// If rescan is enabled, failed hosts are retried once at the end of the run.
+// It buffers all results; use ScanStream to process them as they complete.
+func (s *Scanner) ScanAll(ctx context.Context) ([]models.ServerInfo, models.CollectionStats) {
+	out := make(chan models.ServerInfo)
+	done := make(chan []models.ServerInfo)
+	go func() {
+		serverInfos := make([]models.ServerInfo, 0, len(s.cfg.Servers))
+		for info := range out {
+			serverInfos = append(serverInfos, info)
+		}
+		done <- serverInfos
+	}()
+
+	stats := s.ScanStream(ctx, out)
+	return <-done, stats
+}
+
+// ScanStream scans all configured servers in parallel and sends each result
+// to out as soon as it is final, so consumers never need to hold the whole
+// fleet in memory. If rescan (or retry.defer_busy) is enabled, failed hosts
+// are held back and sent after their retry. ScanStream closes out when done and returns the statistics;
+// out must be drained concurrently.
+func (s *Scanner) ScanStream(ctx context.Context, out chan<- models.ServerInfo) models.CollectionStats {
+	defer close(out)
+
+	s.logger.Infow("starting parallel scan",
+		"server_count", len(s.cfg.Servers),
+		"concurrency", s.concurrency,
+	)
+
+	startTime := time.Now()
+	stats := newStatsBuilder(s.cfg.Stats.GetPercentiles())
+	s.baseline = s.loadBaseline()
+
+	// Failed hosts that are eligible for the rescan pass
+	var retry []scanResult
+	s.scanServers(ctx, s.cfg.Servers, s.concurrency, func(result scanResult) {
+		if s.rescannable(result.info.Error) {
+			retry = append(retry, result)
+			return
+		}
+		emitResult(stats, out, result)
+	})
+
+	var rescanned, recovered int
+	if len(retry) > 0 {
+		rescanned, recovered = s.rescanFailed(ctx, retry)
+		for _, result := range retry {
+			emitResult(stats, out, result)
+		}
+	}
+
+	totalDuration := time.Since(startTime)
+
+	if s.etags != nil {
+		if err := s.etags.save(); err != nil {
+			s.logger.Warnw("failed to save ETag cache", "error", err)
+		}
+	}
+
+	result := stats.build(totalDuration)
+	result.RunID = runid.Current()
+	result.RescannedCount = rescanned
+	result.RecoveredCount = recovered
+
+	s.logger.Infow("scan completed",
+		"total_servers", result.TotalServers,
+		"successful", result.SuccessfulCount,
+		"failed", result.FailedCount,
+		"unchanged", result.UnchangedCount,
+		"partial", result.PartialCount,
+		"recovered_by_rescan", recovered,
+		"duration", totalDuration,
+	)
+
+	return result
+}
+
+// emitResult counts a host's results and sends them to out. The further
+// systems of a multi-system host count as servers of their own, but the
+// host's scan duration is only recorded once.
+func emitResult(stats *statsBuilder, out chan<- models.ServerInfo, result scanResult) {
+	stats.add(result.info, result.duration)
+	out <- result.info
+	for _, info := range result.others {
+		stats.addResult(info)
+		out <- info
+	}
+}
+
+// scanServers scans the given servers with a pool of concurrency workers and
+// calls emit with each result as it completes. emit is called from a single
+// goroutine.
+func (s *Scanner) scanServers(ctx context.Context, servers []config.ServerConfig, concurrency int, emit func(scanResult)) {
+	// Create buffered channels for work distribution
+	jobs := make(chan config.ServerConfig, len(servers))
+	results := make(chan scanResult, concurrency)
+
+	// Start worker pool
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go s.worker(ctx, jobs, results, &wg)
+	}
+
+	// Send jobs to workers
+	for _, server := range servers {
+		jobs <- server
+	}
+	close(jobs)
+
+	// Wait for all workers to complete in a separate goroutine
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	for result := range results {
+		emit(result)
+	}
+}
+
+// retryable reports whether a failed host is worth a rescan.
+// Authentication failures and cancelled scans are not retried.
+func retryable(err error) bool {
+	switch errors.Categorize(err) {
+	case errors.CategoryAuth, errors.CategoryCanceled:
+		return false
+	}
+	return true
+}
+
+// rescannable reports whether a host that failed with err is held back for
+// the rescan pass: any retryable failure if rescan is enabled, and a BMC that
+// is still busy if retry.defer_busy is set.
+func (s *Scanner) rescannable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if s.cfg.Rescan.Enabled && retryable(err) {
+		return true
+	}
+	return s.cfg.Retry.DeferBusy && errors.Categorize(err) == errors.CategoryBMCBusy
+}
+
+// busyWait returns how long the rescan pass waits for the Retry-After of
+// deferred busy hosts that has not passed yet, at most the rescan timeout.
+func busyWait(results []scanResult, timeout time.Duration) time.Duration {
+	var wait time.Duration
+	for _, result := range results {
+		retryAfter, busy := errors.RetryAfter(result.info.Error)
+		if !busy {
+			continue
+		}
+		if d := time.Until(result.info.CollectedAt.Add(result.duration + retryAfter)); d > wait {
+			wait = d
+		}
+	}
+	if wait > timeout {
+		wait = timeout
+	}
+	return wait
+}
+
+// rescanFailed retries failed hosts once with a longer timeout and lower
+// concurrency, replacing their results in place when the retry succeeds.
+// Returns the number of retried and recovered hosts.
+func (s *Scanner) rescanFailed(ctx context.Context, results []scanResult) (int, int) {
+	if ctx.Err() != nil {
+		return 0, 0
+	}
+
+	timeout := s.cfg.Rescan.Timeout(s.cfg.Defaults.Timeout())
+
+	var retry []config.ServerConfig
+	index := make(map[string]int)
+	for i, result := range results {
+		if !s.rescannable(result.info.Error) {
+			continue
+		}
+
+		server, ok := s.serverConfig(result.info.Host)
+		if !ok {
+			continue
+		}
+		if server.GetTimeout(0) < timeout {
+			seconds := int(timeout / time.Second)
+			server.TimeoutSeconds = &seconds
+		}
+		retry = append(retry, server)
+		index[server.Host] = i
+	}
+
+	if len(retry) == 0 {
+		return 0, 0
+	}
+
+	if wait := busyWait(results, timeout); wait > 0 {
+		s.logger.Infow("waiting for busy BMCs before the rescan", "wait", wait.Round(time.Second))
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return 0, 0
+		case <-timer.C:
+		}
+	}
+
+	s.logger.Infow("rescanning failed hosts",
+		"count", len(retry),
+		"timeout", timeout,
+		"concurrency", s.cfg.Rescan.GetConcurrency(),
+	)
+
+	// The HTTP client timeout caps every request, so the second pass needs its own.
+	rescanner := *s
+	rescanner.httpClient = &http.Client{
+		Timeout:   timeout,
+		Transport: s.httpClient.Transport,
+	}
+
+	recovered := 0
+	rescanner.scanServers(ctx, retry, s.cfg.Rescan.GetConcurrency(), func(result scanResult) {
+		if result.info.Error != nil {
+			return
+		}
+		i := index[result.info.Host]
+		results[i] = scanResult{
+			info:     result.info,
+			others:   result.others,
+			duration: results[i].duration + result.duration,
+		}
+		recovered++
+		s.logger.Infow("host recovered by rescan",
+			"host", result.info.Host,
+		)
+	})
+
+	return len(retry), recovered
+}
+
+// serverConfig returns the configuration of the server with the given host.
+func (s *Scanner) serverConfig(host string) (config.ServerConfig, bool) {
+	for _, server := range s.cfg.Servers {
+		if server.Host == host {
+			return server, true
+		}
+	}
+	return config.ServerConfig{}, false
+}
+
+// ValidateConnections tests connectivity to all configured servers without collecting inventory.
+// For each host it reports the Redfish version, iDRAC firmware, TLS certificate and latency.
+// Results are sorted by host.
+func (s *Scanner) ValidateConnections(ctx context.Context) []models.ConnectionCheck {
+	s.logger.Infow("validating connections", "server_count", len(s.cfg.Servers))
+
+	results := make([]models.ConnectionCheck, 0, len(s.cfg.Servers))
+	var mu sync.Mutex
+	s.eachServer(func(server config.ServerConfig) {
+		check := s.validateConnection(ctx, server)
+		mu.Lock()
+		results = append(results, check)
+		mu.Unlock()
+	})
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Host < results[j].Host
+	})
+
+	return results
+}
+
+// eachServer calls fn for each configured server, with as many servers in
+// parallel as the configured concurrency.
+func (s *Scanner) eachServer(fn func(config.ServerConfig)) {
+	jobs := make(chan config.ServerConfig, len(s.cfg.Servers))
+	var wg sync.WaitGroup
+	for i := 0; i < s.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for server := range jobs {
+				fn(server)
+			}
+		}()
+	}
+	for _, server := range s.cfg.Servers {
+		jobs <- server
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// scanResult holds the result of scanning a single server.
+type scanResult struct {
+	info     models.ServerInfo
+	others   []models.ServerInfo // further systems of a multi-system host
+	duration time.Duration
+}
+
+// worker processes scan jobs from the jobs channel.
+func (s *Scanner) worker(ctx context.Context, jobs <-chan config.ServerConfig, results chan<- scanResult, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	for server := range jobs {
+		// Check if context is cancelled
+		select {
+		case <-ctx.Done():
+			// Context cancelled, return error result
+			results <- scanResult{
+				info: models.ServerInfo{
+					Host:        server.Host,
+					Name:        server.Name,
+					Labels:      server.Labels,
+					CollectedAt: time.Now(),
+					RunID:       runid.Current(),
+					Error:       ctx.Err(),
+				},
+				duration: 0,
+			}
+			continue
+		default:
+		}
+
+		if !s.stopAt.IsZero() && !time.Now().Before(s.stopAt) {
+			s.hostLogger(server.Host).Debugw("scan deadline passed, skipping host")
+			continue
+		}
+
+		// Scan the server
+		startTime := time.Now()
+		infos := s.scanServer(ctx, server)
+		duration := time.Since(startTime)
+		for i := range infos {
+			infos[i].ScanDuration = duration
+		}
+
+		results <- scanResult{
+			info:     infos[0],
+			others:   infos[1:],
+			duration: duration,
+		}
+	}
+}
+
+// scanServer scans a single iDRAC and collects the hardware information of
+// each system it exposes (one for rack servers). Hosts with several systems
+// get one result per system, with SystemID and ParentChassis set.
+func (s *Scanner) scanServer(ctx context.Context, server config.ServerConfig) []models.ServerInfo {
+	log := s.hostLogger(server.Host)
+	log.Debugw("scanning server")
+
+	// Create context with timeout
+	scanCtx, cancel := context.WithTimeout(ctx, server.GetTimeout(s.cfg.Defaults.Timeout()))
+	defer cancel()
+
+	// Create authenticated client for this server
+	client := s.newClient(server, log)
+	client.etags = s.etags
+	if s.cfg.HTTP.StrictSchema {
+		client.schema = newSchemaCheck()
+	}
+
+	start := time.Now()
+	systems, err := s.discoverSystems(scanCtx, client)
+	if err != nil {
+		log.Warnw("failed to list systems", "error", err, "error_code", errors.CodeOf(err))
+		return []models.ServerInfo{{
+			Host:           server.Host,
+			Name:           server.Name,
+			Labels:         server.Labels,
+			CollectedAt:    start,
+			RunID:          runid.Current(),
+			Error:          errors.NewCollectionError(server.Host, "system", err),
+			Certificate:    certificateInfo(client.peerCert),
+			PhaseDurations: map[string]time.Duration{models.PhaseSystem: time.Since(start)},
+		}}
+	}
+
+	infos := make([]models.ServerInfo, 0, len(systems))
+	for _, sys := range systems {
+		// Each system gets its own client copy holding its resource paths
+		sc := *client
+		sc.system = sys
+		if s.cfg.Output.Raw.Enabled {
+			sc.raw = newRawCapture(s.cfg.Output.Raw)
+		}
+		if len(systems) > 1 {
+			sc.logger = log.With("system", sys.ID)
+		}
+
+		info := s.scanSystem(scanCtx, &sc, server)
+		info.Raw = sc.raw.result(info.Raw)
+		if len(systems) > 1 {
+			info.SystemID = sc.system.ID
+			info.ParentChassis = sc.system.Chassis
+		}
+		infos = append(infos, info)
+	}
+
+	if client.schema != nil {
+		if total, distinct := client.schema.counts(); total > 0 {
+			log.Warnw("redfish responses do not match the schema", "violations", total, "distinct", distinct)
+		}
+	}
+	return infos
+}
+
+// scanSystem collects the hardware information of one system.
+func (s *Scanner) scanSystem(scanCtx context.Context, client *redfishClient, server config.ServerConfig) models.ServerInfo {
+	info := models.ServerInfo{
+		Host:        server.Host,
+		Name:        server.Name,
+		Labels:      server.Labels,
+		CollectedAt: time.Now(),
+		RunID:       runid.Current(),
+	}
+	log := client.logger
+
+	// timed runs a collector and records how long its phase took and
+	// whether the iDRAC supports it
+	info.PhaseDurations = make(map[string]time.Duration)
+	timed := func(phase string, collect func(context.Context, *redfishClient, *models.ServerInfo) error) error {
+		start := time.Now()
+		client.missing = nil
+		if client.raw != nil {
+			client.raw.start(phase)
+		}
+		err := collect(scanCtx, client, &info)
+		info.PhaseDurations[phase] = time.Since(start)
+		recordCapability(&info, phase, err, client.missing)
+		return err
+	}
+
+	// Collect system information. Unless the host is unreachable, a failed
+	// system resource does not stop the other collectors: the host is a
+	// partial result if any hardware component can be collected.
+	systemErr := timed(models.PhaseSystem, s.collectSystemInfo)
+	info.Certificate = certificateInfo(client.peerCert)
+	if systemErr != nil {
+		if hostUnreachable(systemErr) {
+			info.Error = systemErr
+			log.Warnw("failed to collect system info", "error", systemErr, "error_code", errors.CodeOf(systemErr))
+			return info
+		}
+		log.Warnw("failed to collect system info, collecting the other components", "error", systemErr, "error_code", errors.CodeOf(systemErr))
+		recordComponentError(&info, models.PhaseSystem, systemErr, false)
+	}
+
+	// Everything after the system resource can be tied to the service tag
+	if info.ServiceTag != "" {
+		log = log.With("service_tag", info.ServiceTag)
+		client.logger = log
+	}
+
+	// Reuse the previous inventory if the Lifecycle Controller reports no
+	// change; only the power readings are refreshed
+	if prev, ok := s.unchangedSince(info, client.system.ID); ok {
+		info = reuseInventory(prev, info)
+		info.PowerConsumedWatts, info.PowerPeakWatts, info.PowerSupplies = 0, 0, nil
+		if err := timed(models.PhasePower, s.collectPowerInfo); err != nil {
+			log.Debugw("failed to collect power info, keeping the previous readings", "error", err)
+			info.PowerConsumedWatts, info.PowerPeakWatts, info.PowerSupplies = prev.PowerConsumedWatts, prev.PowerPeakWatts, prev.PowerSupplies
+		}
+		if err := timed(models.PhaseClock, s.collectClock); err != nil {
+			log.Debugw("failed to check iDRAC clock", "error", err)
+			recordComponentError(&info, models.PhaseClock, err, true)
+		}
+		if s.cfg.Accounts.Audit {
+			if err := timed(models.PhaseAccounts, s.collectAccounts); err != nil {
+				log.Warnw("failed to audit iDRAC accounts", "error", err)
+				recordComponentError(&info, models.PhaseAccounts, err, true)
+			}
+		}
+		log.Infow("server unchanged since last full scan, reusing inventory",
+			"last_changed_at", info.LastChangedAt.Format(time.RFC3339),
+			"full_scan_at", info.FullScanAt.Format(time.RFC3339),
+		)
+		return info
+	}
+
+	// Collect processor information
+	if err := timed(models.PhaseProcessors, s.collectProcessors); err != nil {
+		log.Warnw("failed to collect processor info", "error", err, "error_code", errors.CodeOf(err))
+		recordComponentError(&info, models.PhaseProcessors, err, false)
+		// Don't fail the whole scan
+	}
+
+	// Collect memory information
+	if err := timed(models.PhaseMemory, s.collectMemory); err != nil {
+		log.Warnw("failed to collect memory info", "error", err, "error_code", errors.CodeOf(err))
+		recordComponentError(&info, models.PhaseMemory, err, false)
+		// Don't fail the whole scan
+	}
+
+	// Collect storage information
+	if err := timed(models.PhaseStorage, s.collectStorage); err != nil {
+		log.Warnw("failed to collect storage info", "error", err, "error_code", errors.CodeOf(err))
+		recordComponentError(&info, models.PhaseStorage, err, false)
+		// Don't fail the whole scan
+	}
+
+	// Without the system resource, the host needs some hardware data to
+	// count as partial rather than failed
+	if systemErr != nil && allFailed(&info, corePhases) {
+		info.Error = systemErr
+		log.Warnw("no component could be collected", "errors", info.ComponentErrors)
+		return info
+	}
+
+	// Optional collectors that failed; used to explain gaps on license-limited iDRACs
+	var optionalGaps []string
+
+	// Collect power information
+	if err := timed(models.PhasePower, s.collectPowerInfo); err != nil {
+		log.Debugw("failed to collect power info", "error", err)
+		optionalGaps = append(optionalGaps, models.PhasePower)
+		recordComponentError(&info, models.PhasePower, err, true)
+		// Don't fail the whole scan - power data is optional
+	}
+
+	// Collect PCIe slot occupancy
+	if err := timed(models.PhasePCIeSlots, s.collectPCIeSlots); err != nil {
+		log.Debugw("failed to collect PCIe slot info", "error", err)
+		optionalGaps = append(optionalGaps, models.PhasePCIeSlots)
+		recordComponentError(&info, models.PhasePCIeSlots, err, true)
+		// Don't fail the whole scan - not exposed by all firmware
+	}
+
+	// Collect iDRAC licenses (Dell OEM)
+	if err := timed(models.PhaseLicense, s.collectLicenses); err != nil {
+		log.Debugw("failed to collect license info", "error", err)
+		recordComponentError(&info, models.PhaseLicense, err, true)
+		// Don't fail the whole scan - non-Dell BMCs have no DellLicenses
+	}
+
+	// Collect the iDRAC's own network settings
+	if err := timed(models.PhaseNetwork, s.collectIDRACNetwork); err != nil {
+		log.Debugw("failed to collect iDRAC network info", "error", err)
+		recordComponentError(&info, models.PhaseNetwork, err, true)
+		// Don't fail the whole scan - the manager may not be readable
+	}
+
+	// Compare the iDRAC clock with ours
+	if err := timed(models.PhaseClock, s.collectClock); err != nil {
+		log.Debugw("failed to check iDRAC clock", "error", err)
+		recordComponentError(&info, models.PhaseClock, err, true)
+		// Don't fail the whole scan - not all BMCs report their time
+	}
+
+	// Audit the local iDRAC accounts (opt-in)
+	if s.cfg.Accounts.Audit {
+		if err := timed(models.PhaseAccounts, s.collectAccounts); err != nil {
+			log.Warnw("failed to audit iDRAC accounts", "error", err)
+			recordComponentError(&info, models.PhaseAccounts, err, true)
+			// Don't fail the whole scan - the user may lack the Configure Users privilege
+		}
+	}
+
+	// Site-specific collectors (see Register)
+	for _, c := range s.collectors {
+		c := c
+		err := timed(c.Name(), func(ctx context.Context, client *redfishClient, info *models.ServerInfo) error {
+			return runCollector(ctx, c, client, info)
+		})
+		if err != nil {
+			log.Warnw("custom collector failed", "collector", c.Name(), "error", err)
+			recordComponentError(&info, c.Name(), err, true)
+			// Don't fail the whole scan
+		}
+	}
+
+	if models.IsLicenseLimited(info.LicenseLevel) && len(optionalGaps) > 0 {
+		log.Warnw("some data could not be collected, possibly limited by the iDRAC license",
+			"license", info.LicenseLevel,
+			"missing", optionalGaps,
+		)
+	}
+
+	log.Infow("server scan completed",
+		"model", info.Model,
+		"serial_number", info.SerialNumber,
+		"cpus", info.CPUCount,
+		"gpus", info.GPUCount,
+		"ram_gb", info.TotalMemoryGiB,
+		"drives", info.DriveCount,
+	)
+	if info.IsPartial() {
+		log.Warnw("server scan is partial, some components failed", "errors", info.ComponentErrors)
+	}
+
+	return info
+}
+
+// hostLogger returns the logger for one host's scan. Every line carries the
+// host, plus the run ID if the global logger was not initialised with one
+// (e.g. when the scanner is used as a library).
+func (s *Scanner) hostLogger(host string) *zap.SugaredLogger {
+	log := s.logger.With("host", host)
+	if logging.RunID() == "" {
+		log = log.With("run_id", runid.Current())
+	}
+	return log
+}
+
+// newClient returns a Redfish client for server with its client certificate,
+// its credentials, or the default credentials.
+func (s *Scanner) newClient(server config.ServerConfig, log *zap.SugaredLogger) *redfishClient {
+	client := &redfishClient{
+		baseURL:    fmt.Sprintf("https://%s", server.Host),
+		httpClient: s.httpClient,
+		headers:    s.cfg.HTTP,
+		logger:     log,
+		retry:      s.cfg.Retry,
+		audit:      s.audit,
+	}
+	if server.UsesClientCert() {
+		client.httpClient = &http.Client{
+			Timeout:   s.httpClient.Timeout,
+			Transport: s.certTransport(server),
+		}
+		return client
+	}
+	client.username = server.GetUsername(s.cfg.Defaults.Username)
+	client.password = server.GetPassword(s.cfg.Defaults.Password)
+	return client
+}
+
+// validateConnection tests basic connectivity to an iDRAC server.
+func (s *Scanner) validateConnection(ctx context.Context, server config.ServerConfig) models.ConnectionCheck {
+	check := models.ConnectionCheck{
+		Host: server.Host,
+		Name: server.Name,
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, server.GetTimeout(s.cfg.Defaults.Timeout()))
+	defer cancel()
+
+	log := s.hostLogger(server.Host)
+	client := s.newClient(server, log)
+
+	// Try to fetch the service root
+	var root redfish.ServiceRoot
+	start := time.Now()
+	err := client.get(ctx, defaults.RedfishBasePath, &root)
+	check.Latency = time.Since(start)
+	check.Certificate = certificateInfo(client.peerCert)
+	if err != nil {
+		check.Error = err
+		return check
+	}
+	check.RedfishVersion = root.RedfishVersion
+
+	// Firmware version and clock are informational; older firmware may not expose the manager
+	var manager redfish.Manager
+	start = time.Now()
+	if err := client.get(ctx, defaults.RedfishManagerPath, &manager); err != nil {
+		log.Debugw("failed to get manager info", "error", err)
+	} else {
+		check.FirmwareVersion = manager.FirmwareVersion
+		if clock, err := clockOf(manager, midpoint(start, time.Now()), s.cfg.Clock.GetMaxDrift()); err != nil {
+			log.Debugw("failed to check iDRAC clock", "error", err)
+		} else {
+			check.Clock = clock
+		}
+	}
+
+	log.Debugw("connection validated",
+		"redfish_version", check.RedfishVersion,
+		"firmware_version", check.FirmwareVersion,
+		"latency", check.Latency,
+	)
+
+	return check
+}
+
+// collectSystemInfo retrieves system-level information from iDRAC.
+func (s *Scanner) collectSystemInfo(ctx context.Context, client *redfishClient, info *models.ServerInfo) error {
+	var system redfish.System
+
+	if err := client.get(ctx, client.system.System, &system); err != nil {
+		return errors.NewCollectionError(info.Host, "system", err)
+	}
+	client.system.resolve(system)
+
+	// Map system information
+	info.Model = s.modelNames.Canonical(system.Manufacturer, system.Model)
+	if info.Model != system.Model {
+		info.ReportedModel = system.Model
+	}
+	info.Manufacturer = system.Manufacturer
+	info.SerialNumber = system.SerialNumber
+	info.ServiceTag = system.SKU // Dell uses SKU for service tag
+	info.BiosVersion = system.BiosVersion
+	info.HostName = system.HostName
+	info.PowerState = system.PowerState
+	info.Platform = models.ParsePlatform(info.Manufacturer, info.Model, info.ServiceTag)
+
+	// Use processor summary for CPU count and model
+	info.CPUCount = system.ProcessorSummary.Count
+	info.CPUModel = system.ProcessorSummary.Model
+
+	// Use memory summary for total RAM
+	info.TotalMemoryGiB = system.MemorySummary.TotalSystemMemoryGiB
+
+	// Extract Dell OEM memory information if available
+	if system.Oem.Dell != nil && system.Oem.Dell.DellSystem != nil {
+		dellSys := system.Oem.Dell.DellSystem
+		info.LastChangedAt = lastChange(dellSys)
+		if built, ok := parseLifecycleTime(dellSys.ManufactureDate); ok {
+			info.ManufactureDate = &built
+			info.ManufactureDateSource = models.ManufactureDateIDRAC
+		}
+		if dellSys.MaxDIMMSlots > 0 {
+			info.MemorySlotsTotal = dellSys.MaxDIMMSlots
+			client.logger.Debugw("extracted Dell OEM memory slot info",
+				"max_dimm_slots", dellSys.MaxDIMMSlots,
+				"populated_slots", dellSys.PopulatedSlots,
+			)
+		}
+	}
+
+	// Log extracted system information
+	client.logger.Infow("extracted system information",
+		"manufacturer", info.Manufacturer,
+		"model", info.Model,
+		"serial_number", info.SerialNumber,
+		"service_tag", info.ServiceTag,
+		"bios_version", info.BiosVersion,
+		"hostname", info.HostName,
+		"power_state", info.PowerState,
+	)
+
+	return nil
+}
+
+// collectProcessors retrieves detailed processor information, including GPUs/accelerators.
+func (s *Scanner) collectProcessors(ctx context.Context, client *redfishClient, info *models.ServerInfo) error {
+	// Get processor collection
+	var collection redfish.Collection
+	if err := client.get(ctx, client.system.Processors, &collection); err != nil {
+		return errors.NewCollectionError(info.Host, "processors", err)
+	}
+
+	// Fetch each processor and classify as CPU or GPU/accelerator
+	var cpus []models.CPUInfo
+	var gpus []models.GPUInfo
+
+	for _, member := range collection.Members {
+		var processor redfish.Processor
+		if err := client.getMember(ctx, member.OdataID, &processor); err != nil {
+			client.logger.Warnw("failed to get processor details",
+				"path", member.OdataID,
+				"error", err,
+			)
+			continue
+		}
+
+		// Only include installed processors
+		if !processor.IsInstalled() || !redfish.IsResourceType(processor.OdataType, "Processor") {
+			continue
+		}
+
+		if processor.IsGPU() {
+			// Collect as GPU/accelerator (named "Beschleuniger" on German
+			// iDRACs, so classification never looks at Name)
+			gpu := s.buildGPUInfo(processor)
+			gpus = append(gpus, gpu)
+
+			client.logger.Debugw("GPU/accelerator details",
+				"slot", gpu.Slot,
+				"model", gpu.Model,
+				"manufacturer", gpu.Manufacturer,
+				"memory_mib", gpu.MemoryMiB,
+				"memory_type", gpu.MemoryType,
+				"health", gpu.Health,
+			)
+		} else {
+			// Collect as standard CPU
+			brand := processor.Model
+			if processor.Manufacturer != "" && processor.Model != "" {
+				brand = processor.Manufacturer + " " + processor.Model
+			}
+
+			cpu := models.CPUInfo{
+				Socket:            processor.Socket,
+				Model:             processor.Model,
+				Manufacturer:      processor.Manufacturer,
+				Brand:             brand,
+				Cores:             processor.TotalCores,
+				Threads:           processor.TotalThreads,
+				MaxSpeedMHz:       processor.MaxSpeedMHz,
+				OperatingSpeedMHz: processor.OperatingSpeedMHz,
+				ProcessorType:     processor.ProcessorType,
+				Architecture:      processor.ProcessorArchitecture,
+				InstructionSet:    processor.InstructionSet,
+				Health:            processor.Status.Health,
+			}
+			cpus = append(cpus, cpu)
+		}
+	}
+
+	info.CPUs = cpus
+	info.GPUs = gpus
+	info.GPUCount = len(gpus)
+
+	// Update count from actual installed CPUs if different from summary
+	if len(cpus) > 0 {
+		info.CPUCount = len(cpus)
+		// Use first CPU's model if more detailed than summary
+		if info.CPUModel == "" && cpus[0].Model != "" {
+			info.CPUModel = cpus[0].Model
+		}
+
+		names := make([]string, len(cpus))
+		for i, cpu := range cpus {
+			names[i] = cpu.Model
+		}
+		client.logger.Infow("extracted CPU information",
+			"cpu_count", len(cpus),
+			"models", tally(names),
+		)
+		for i, cpu := range cpus {
+			client.logger.Debugw("CPU details",
+				"cpu_index", i+1,
+				"socket", cpu.Socket,
+				"brand", cpu.Brand,
+				"manufacturer", cpu.Manufacturer,
+				"model", cpu.Model,
+				"cores", cpu.Cores,
+				"threads", cpu.Threads,
+				"max_speed_mhz", cpu.MaxSpeedMHz,
+				"architecture", cpu.Architecture,
+				"instruction_set", cpu.InstructionSet,
+			)
+		}
+	}
+
+	if len(gpus) > 0 {
+		names := make([]string, len(gpus))
+		for i, gpu := range gpus {
+			names[i] = gpu.Model
+		}
+		client.logger.Infow("extracted GPU/accelerator information",
+			"gpu_count", len(gpus),
+			"models", tally(names),
+		)
+	}
+
+	return nil
+}
+
+// buildGPUInfo constructs a GPUInfo model from a Redfish Processor entry typed as GPU/Accelerator.
+func (s *Scanner) buildGPUInfo(processor redfish.Processor) models.GPUInfo {
+	gpu := models.GPUInfo{
+		Slot:         processor.Socket,
+		Model:        processor.Model,
+		Manufacturer: processor.Manufacturer,
+		Health:       processor.Status.Health,
+	}
+
+	// Socket is usually empty for GPUs; prefer the FQDD over the localized Name
+	if gpu.Slot == "" {
+		gpu.Slot = processor.ID
+	}
+	if gpu.Slot == "" {
+		gpu.Slot = processor.Name
+	}
+
+	// Extract VRAM from inline ProcessorMemory array
+	for _, mem := range processor.ProcessorMemory {
+		if mem.CapacityMiB > 0 {
+			gpu.MemoryMiB += mem.CapacityMiB
+			if gpu.MemoryType == "" {
+				gpu.MemoryType = mem.MemoryType
+			}
+		}
+	}
+
+	return gpu
+}
+
+// collectMemory retrieves detailed memory module information.
+func (s *Scanner) collectMemory(ctx context.Context, client *redfishClient, info *models.ServerInfo) error {
+	// Get memory collection
+	var collection redfish.Collection
+	if err := client.get(ctx, client.system.Memory, &collection); err != nil {
+		return errors.NewCollectionError(info.Host, "memory", err)
+	}
+
+	// Fetch each memory module
+	var memoryModules []models.MemoryInfo
+	var totalMemoryMiB int
+	slotsUsed := 0
+
+	for _, member := range collection.Members {
+		var memory redfish.Memory
+		if err := client.getMember(ctx, member.OdataID, &memory); err != nil {
+			client.logger.Warnw("failed to get memory details",
+				"path", member.OdataID,
+				"error", err,
+			)
+			continue
+		}
+		if !redfish.IsResourceType(memory.OdataType, "Memory") {
+			continue
+		}
+
+		// Determine slot name
+		slotName := memory.DeviceLocator
+		if slotName == "" {
+			slotName = memory.ID
+		}
+
+		mem := models.MemoryInfo{
+			Slot:           slotName,
+			CapacityMiB:    memory.CapacityMiB,
+			Type:           memory.MemoryDeviceType,
+			Technology:     memory.MemoryType,
+			BaseModuleType: memory.BaseModuleType,
+			SpeedMHz:       memory.OperatingSpeedMhz,
+			Manufacturer:   memory.Manufacturer,
+			PartNumber:     memory.PartNumber,
+			SerialNumber:   memory.SerialNumber,
+			RankCount:      memory.RankCount,
+			DataWidthBits:  memory.DataWidthBits,
+			State:          memory.Status.State,
+			Health:         memory.Status.Health,
+		}
+
+		memoryModules = append(memoryModules, mem)
+
+		// Count slots and capacity
+		if memory.IsPopulated() {
+			slotsUsed++
+			totalMemoryMiB += memory.CapacityMiB
+		}
+	}
+
+	info.Memory = memoryModules
+	info.MemorySlotsUsed = slotsUsed
+
+	// Set total slots if not already set by OEM data
+	if info.MemorySlotsTotal == 0 {
+		info.MemorySlotsTotal = len(memoryModules)
+	}
+
+	// Calculate free slots
+	info.MemorySlotsFree = info.MemorySlotsTotal - slotsUsed
+
+	// Update total memory if we calculated it from DIMMs
+	if totalMemoryMiB > 0 {
+		calculatedGiB := float64(totalMemoryMiB) / 1024
+		// Use calculated value if summary was missing or different
+		if info.TotalMemoryGiB == 0 || calculatedGiB > info.TotalMemoryGiB {
+			info.TotalMemoryGiB = calculatedGiB
+		}
+	}
+
+	// Log extracted memory information; the modules only at debug level
+	var modules []string
+	for _, mem := range memoryModules {
+		if mem.IsPopulated() {
+			modules = append(modules, strings.TrimSpace(fmt.Sprintf("%.0f GiB %s", mem.CapacityGB(), mem.Type)))
+		}
+	}
+	client.logger.Infow("extracted memory information",
+		"total_memory_gib", info.TotalMemoryGiB,
+		"slots_total", info.MemorySlotsTotal,
+		"slots_used", info.MemorySlotsUsed,
+		"slots_free", info.MemorySlotsFree,
+		"modules", tally(modules),
+	)
+	for i, mem := range memoryModules {
+		if mem.IsPopulated() {
+			client.logger.Debugw("memory module details",
+				"module_index", i+1,
+				"slot", mem.Slot,
+				"capacity_gib", mem.CapacityGB(),
+				"type", mem.Type,
+				"technology", mem.Technology,
+				"base_module_type", mem.BaseModuleType,
+				"speed_mhz", mem.SpeedMHz,
+				"manufacturer", mem.Manufacturer,
+				"rank_count", mem.RankCount,
+			)
+		}
+	}
+
+	return nil
+}
+
+// collectStorage retrieves storage controller and drive information.
+func (s *Scanner) collectStorage(ctx context.Context, client *redfishClient, info *models.ServerInfo) error {
+	// Get storage collection
+	var collection redfish.Collection
+	if err := client.get(ctx, client.system.Storage, &collection); err != nil {
+		return errors.NewCollectionError(info.Host, "storage", err)
+	}
+
+	var allDrives []models.DriveInfo
+	var totalCapacityBytes int64
+	enclosures := make(map[string]bool)
+	drivePaths := make(map[string]int) // drive path → index in allDrives
+	var controllers []models.StorageControllerInfo
+
+	// Iterate through storage controllers
+	for _, member := range collection.Members {
+		var storage redfish.Storage
+		if err := client.getMember(ctx, member.OdataID, &storage); err != nil {
+			client.logger.Warnw("failed to get storage controller",
+				"path", member.OdataID,
+				"error", err,
+			)
+			continue
+		}
+		if ctrl, ok := storageController(storage); ok {
+			controllers = append(controllers, ctrl)
+		}
+
+		// Fetch each drive
+		for _, driveLink := range storage.Drives {
+			var drive redfish.Drive
+			if err := client.getMember(ctx, driveLink.OdataID, &drive); err != nil {
+				client.logger.Warnw("failed to get drive details",
+					"path", driveLink.OdataID,
+					"error", err,
+				)
+				continue
+			}
+
+			// Map drive info
+			driveInfo := models.DriveInfo{
+				Name:          drive.Name,
+				Model:         drive.Model,
+				Manufacturer:  drive.Manufacturer,
+				SerialNumber:  drive.SerialNumber,
+				PartNumber:    drive.PartNumber,
+				CapacityGB:    drive.CapacityGB(),
+				CapacityBytes: drive.CapacityBytes,
+				MediaType:     drive.MediaType,
+				Protocol:      drive.Protocol,
+				LifeLeftPct:   drive.PredictedMediaLifeLeftPercent,
+				Health:        drive.Status.Health,
+			}
+			driveInfo.Bay, driveInfo.Enclosure = driveLocation(drive)
+			if drive.Protocol == models.ProtocolNVMe {
+				driveInfo.NVMe = nvmeTelemetry(drive)
+			}
+
+			drivePaths[driveLink.OdataID] = len(allDrives)
+			allDrives = append(allDrives, driveInfo)
+			totalCapacityBytes += drive.CapacityBytes
+		}
+
+		for _, enc := range storage.Links.Enclosures {
+			enclosures[enc.OdataID] = true
+		}
+	}
+
+	info.Drives = allDrives
+	info.DriveCount = len(allDrives)
+	info.StorageControllers = controllers
+	info.Enclosures = s.collectEnclosures(ctx, client, enclosures, drivePaths, allDrives)
+	info.DriveBaysTotal = 0
+	for _, enc := range info.Enclosures {
+		info.DriveBaysTotal += enc.SlotCount
+	}
+
+	// Calculate total storage in TB
+	if totalCapacityBytes > 0 {
+		info.TotalStorageTB = float64(totalCapacityBytes) / 1024 / 1024 / 1024 / 1024
+	}
+
+	// Log extracted storage information; the drives only at debug level
+	drives := make([]string, len(allDrives))
+	for i, drive := range allDrives {
+		drives[i] = strings.TrimSpace(fmt.Sprintf("%.0f GB %s", drive.CapacityGB, drive.MediaType))
+	}
+	client.logger.Infow("extracted storage information",
+		"total_drives", info.DriveCount,
+		"total_storage_tb", fmt.Sprintf("%.2f", info.TotalStorageTB),
+		"drives", tally(drives),
+	)
+	for i, drive := range allDrives {
+		client.logger.Debugw("drive details",
+			"drive_index", i+1,
+			"name", drive.Name,
+			"model", drive.Model,
+			"manufacturer", drive.Manufacturer,
+			"serial_number", drive.SerialNumber,
+			"capacity_gb", fmt.Sprintf("%.2f", drive.CapacityGB),
+			"capacity_tb", fmt.Sprintf("%.2f", drive.CapacityTB()),
+			"media_type", drive.MediaType,
+			"protocol", drive.Protocol,
+			"health", drive.Health,
+		)
+	}
+	for _, ctrl := range controllers {
+		fields := []interface{}{"id", ctrl.ID, "model", ctrl.Model, "health", ctrl.Health, "cache_mib", ctrl.CacheMiB}
+		if b := ctrl.Battery; b != nil {
+			fields = append(fields, "battery_health", b.Health, "battery_state", b.State)
+		}
+		client.logger.Debugw("storage controller details", fields...)
+	}
+
+	return nil
+}
+
+// tally summarizes the components of a host for a single log line, e.g.
+// "2x Xeon Gold 6338, 1x Xeon Silver 4310", in order of first appearance.
+func tally(values []string) string {
+	counts := map[string]int{}
+	var order []string
+	for _, v := range values {
+		if v == "" {
+			v = "unknown"
+		}
+		if counts[v] == 0 {
+			order = append(order, v)
+		}
+		counts[v]++
+	}
+	parts := make([]string, len(order))
+	for i, v := range order {
+		parts[i] = fmt.Sprintf("%dx %s", counts[v], v)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// storageController returns the controller of a storage resource with its
+// cache and, from the Dell OEM data, its cache battery. ok is false for
+// storage without a controller.
+func storageController(storage redfish.Storage) (models.StorageControllerInfo, bool) {
+	if len(storage.StorageControllers) == 0 {
+		return models.StorageControllerInfo{}, false
+	}
+	ctrl := storage.StorageControllers[0]
+	info := models.StorageControllerInfo{
+		ID:              storage.ID,
+		Name:            ctrl.Name,
+		Model:           ctrl.Model,
+		FirmwareVersion: ctrl.FirmwareVersion,
+		Health:          ctrl.Status.Health,
+	}
+	if info.Name == "" {
+		info.Name = storage.Name
+	}
+	if info.Health == "" {
+		info.Health = storage.Status.Health
+	}
+	if cache := ctrl.CacheSummary; cache != nil {
+		info.CacheMiB = cache.TotalCacheSizeMiB
+		info.CacheHealth = cache.Status.Health
+	}
+	if dell := storage.Oem.Dell; dell != nil {
+		if dc := dell.DellController; dc != nil && info.CacheMiB == 0 {
+			info.CacheMiB = dc.CacheSizeInMB
+		}
+		if b := dell.DellControllerBattery; b != nil {
+			info.Battery = &models.BatteryInfo{
+				Name:   b.Name,
+				Health: models.BatteryHealth(b.PrimaryStatus, b.RAIDState),
+				State:  b.RAIDState,
+			}
+		}
+	}
+	return info, true
+}
+
+// dellDriveID matches the FQDD of a drive in a backplane bay, e.g.
+// "Disk.Bay.3:Enclosure.Internal.0-1:RAID.Integrated.1-1".
+var dellDriveID = regexp.MustCompile(`^Disk\.Bay\.(\d+)(?::(Enclosure\..+))?$`)
+
+// driveLocation returns the bay of a drive and the enclosure containing it,
+// from the Dell FQDD or else the Redfish PhysicalLocation. The enclosure is
+// empty if only the bay is known; collectEnclosures fills it in from the
+// drive links of the enclosures.
+func driveLocation(drive redfish.Drive) (*int, string) {
+	if m := dellDriveID.FindStringSubmatch(drive.ID); m != nil {
+		bay, _ := strconv.Atoi(m[1])
+		return &bay, m[2]
+	}
+	if loc := drive.PhysicalLocation.PartLocation; loc.LocationType == "Bay" || loc.LocationType == "Slot" {
+		bay := loc.LocationOrdinalValue
+		return &bay, ""
+	}
+	return nil, ""
+}
+
+// collectEnclosures reads the backplanes and external enclosures linked from
+// the storage controllers, sorted by ID. Slot counts, firmware and the
+// connector come from the Dell OEM DellEnclosure. Enclosures that cannot be
+// read are skipped, as is the server chassis, which Dell also links from the
+// controllers. The drives linked from an enclosure (by their path in
+// drivePaths) get its ID as their Enclosure.
+func (s *Scanner) collectEnclosures(ctx context.Context, client *redfishClient, enclosureLinks map[string]bool, drivePaths map[string]int, drives []models.DriveInfo) []models.EnclosureInfo {
+	var enclosures []models.EnclosureInfo
+	for path := range enclosureLinks {
+		var enc redfish.Enclosure
+		if err := client.getMember(ctx, path, &enc); err != nil {
+			client.logger.Debugw("failed to get storage enclosure",
+				"path", path,
+				"error", err,
+			)
+			continue
+		}
+		dell := enc.Oem.Dell != nil && enc.Oem.Dell.DellEnclosure != nil
+		if !dell && enc.ChassisType != "Enclosure" && enc.ChassisType != "StorageEnclosure" {
+			continue
+		}
+		info := models.EnclosureInfo{
+			ID:           enc.ID,
+			Name:         enc.Name,
+			Model:        enc.Model,
+			Manufacturer: enc.Manufacturer,
+			PartNumber:   enc.PartNumber,
+			SerialNumber: enc.SerialNumber,
+			External:     strings.Contains(enc.ID, "External") || enc.ChassisType == "StorageEnclosure",
+			SlotsUsed:    len(enc.Links.Drives),
+			Health:       enc.Status.Health,
+		}
+		if dell {
+			oem := enc.Oem.Dell.DellEnclosure
+			info.SlotCount = oem.SlotCount
+			info.FirmwareVersion = oem.Version
+			info.Connector = oem.Connector
+			info.ServiceTag = oem.ServiceTag
+		}
+		for _, link := range enc.Links.Drives {
+			if i, ok := drivePaths[link.OdataID]; ok {
+				drives[i].Enclosure = enc.ID
+			}
+		}
+		enclosures = append(enclosures, info)
+	}
+	sort.Slice(enclosures, func(i, j int) bool { return enclosures[i].ID < enclosures[j].ID })
+
+	for _, enc := range enclosures {
+		client.logger.Debugw("enclosure details",
+			"id", enc.ID,
+			"model", enc.Model,
+			"external", enc.External,
+			"slot_count", enc.SlotCount,
+			"slots_used", enc.SlotsUsed,
+			"firmware_version", enc.FirmwareVersion,
+		)
+	}
+	return enclosures
+}
+
+// collectPCIeSlots retrieves PCIe slot occupancy from the chassis.
+// Older firmware does not expose PCIeSlots; callers treat failures as non-fatal.
+func (s *Scanner) collectPCIeSlots(ctx context.Context, client *redfishClient, info *models.ServerInfo) error {
+	var slots redfish.PCIeSlots
+	if client.system.PCIeSlots == "" {
+		return errNoChassis
+	}
+	if err := client.get(ctx, client.system.PCIeSlots, &slots); err != nil {
+		return errors.NewCollectionError(info.Host, "pcie_slots", err)
+	}
+
+	info.PCIeSlotsTotal = len(slots.Slots)
+	info.PCIeSlotsUsed = 0
+	info.PCIeSlots = nil
+	for i, slot := range slots.Slots {
+		label := slot.Location.PartLocation.ServiceLabel
+		if label == "" {
+			label = fmt.Sprintf("Slot %d", i+1)
+		}
+		slotInfo := models.PCIeSlotInfo{
+			Label:    label,
+			SlotType: slot.SlotType,
+			PCIeType: slot.PCIeType,
+			Lanes:    slot.Lanes,
+			Occupied: slot.IsOccupied(),
+		}
+
+		if slot.IsOccupied() {
+			info.PCIeSlotsUsed++
+
+			var device redfish.PCIeDevice
+			if err := client.getMember(ctx, slot.Links.PCIeDevice[0].OdataID, &device); err != nil {
+				client.logger.Debugw("failed to get PCIe device",
+					"slot", label,
+					"error", err,
+				)
+			} else {
+				slotInfo.Card = &models.PCIeCardInfo{
+					Name:         device.Name,
+					Manufacturer: device.Manufacturer,
+					Model:        device.Model,
+					PartNumber:   device.PartNumber,
+					SerialNumber: device.SerialNumber,
+					DeviceClass:  s.pcieDeviceClass(ctx, client, device),
+				}
+			}
+		}
+
+		info.PCIeSlots = append(info.PCIeSlots, slotInfo)
+	}
+
+	client.logger.Infow("extracted PCIe slot information",
+		"slots_total", info.PCIeSlotsTotal,
+		"slots_used", info.PCIeSlotsUsed,
+	)
+
+	return nil
+}
+
+// pcieDeviceClass returns the PCI device class of the card's first function
+// (e.g. DisplayController), or "" if the firmware does not expose functions.
+func (s *Scanner) pcieDeviceClass(ctx context.Context, client *redfishClient, device redfish.PCIeDevice) string {
+	members, collectionPath := device.FunctionLinks()
+	if len(members) == 0 && collectionPath != "" {
+		var collection redfish.Collection
+		if err := client.getMember(ctx, collectionPath, &collection); err != nil {
+			client.logger.Debugw("failed to get PCIe functions", "path", collectionPath, "error", err)
+			return ""
+		}
+		members = collection.Members
+	}
+	if len(members) == 0 {
+		return ""
+	}
+
+	var function redfish.PCIeFunction
+	if err := client.getMember(ctx, members[0].OdataID, &function); err != nil {
+		client.logger.Debugw("failed to get PCIe function", "path", members[0].OdataID, "error", err)
+		return ""
+	}
+	return function.DeviceClass
+}
+
+// collectPowerInfo retrieves power consumption information from the chassis.
+// Firmware that implements PowerSubsystem is read through it; otherwise, or
+// if that fails, the legacy Power resource is used.
+// This function is resilient - it will not fail if power data is unavailable.
+func (s *Scanner) collectPowerInfo(ctx context.Context, client *redfishClient, info *models.ServerInfo) error {
+	if client.system.Chassis != "" {
+		ok, err := s.collectPowerSubsystem(ctx, client, info)
+		switch {
+		case ok && info.PowerConsumedWatts > 0:
+			return nil
+		case ok:
+			// No reading in EnvironmentMetrics: take the draw from the
+			// Power resource while the firmware still has it
+			if err := s.collectLegacyPower(ctx, client, info); err != nil {
+				client.logger.Debugw("no power reading in EnvironmentMetrics or Power", "error", err)
+			}
+			return nil
+		case err != nil:
+			client.logger.Debugw("PowerSubsystem not usable, falling back to the Power resource", "error", err)
+		}
+	}
+	return s.collectLegacyPower(ctx, client, info)
+}
+
+// collectLegacyPower reads the deprecated Power resource of the chassis.
+func (s *Scanner) collectLegacyPower(ctx context.Context, client *redfishClient, info *models.ServerInfo) error {
+	var power redfish.Power
+	if client.system.Power == "" {
+		return errNoChassis
+	}
+	if err := client.get(ctx, client.system.Power, &power); err != nil {
+		// Power data may not be available on all systems
+		return errors.NewCollectionError(info.Host, "power", err)
+	}
+
+	// Extract power consumption data from the first PowerControl entry
+	if len(power.PowerControl) > 0 {
+		pc := power.PowerControl[0]
+
+		// Set current power consumption if available
+		if pc.PowerConsumedWatts > 0 {
+			info.PowerConsumedWatts = pc.PowerConsumedWatts
+		}
+
+		// Set peak power consumption from metrics if available
+		if pc.PowerMetrics.MaxConsumedWatts > 0 {
+			info.PowerPeakWatts = pc.PowerMetrics.MaxConsumedWatts
+		}
+
+		client.logger.Infow("extracted power information",
+			"power_consumed_watts", info.PowerConsumedWatts,
+			"power_peak_watts", info.PowerPeakWatts,
+			"metrics_interval_min", pc.PowerMetrics.IntervalInMin,
+		)
+	}
+
+	// Extract installed power supply units, unless PowerSubsystem listed them
+	if len(info.PowerSupplies) > 0 {
+		return nil
+	}
+	for _, psu := range power.PowerSupplies {
+		info.PowerSupplies = append(info.PowerSupplies, models.PowerSupplyInfo{
+			Name:            psu.Name,
+			Model:           psu.Model,
+			Manufacturer:    psu.Manufacturer,
+			PartNumber:      psu.PartNumber,
+			SparePartNumber: psu.SparePartNumber,
+			SerialNumber:    psu.SerialNumber,
+			FirmwareVersion: psu.FirmwareVersion,
+			CapacityWatts:   psu.PowerCapacityWatts,
+			InputWatts:      psu.PowerInputWatts,
+			Health:          psu.Status.Health,
+			State:           psu.Status.State,
+		})
+	}
+	if len(info.PowerSupplies) > 0 {
+		client.logger.Infow("extracted power supply information",
+			"psu_count", len(info.PowerSupplies),
+		)
+	}
+
+	return nil
+}
+
+// collectLicenses retrieves the installed iDRAC licenses from the Dell OEM
+// DellLicenses collection and derives the effective license level.
+func (s *Scanner) collectLicenses(ctx context.Context, client *redfishClient, info *models.ServerInfo) error {
+	var collection redfish.Collection
+	if err := client.get(ctx, defaults.RedfishDellLicensesPath, &collection); err != nil {
+		return errors.NewCollectionError(info.Host, "license", err)
+	}
+
+	for _, member := range collection.Members {
+		var license redfish.DellLicense
+		if err := client.getMember(ctx, member.OdataID, &license); err != nil {
+			client.logger.Debugw("failed to get license details",
+				"license", member.OdataID,
+				"error", err,
+			)
+			continue
+		}
+
+		info.Licenses = append(info.Licenses, models.LicenseInfo{
+			Description:   strings.Join(license.LicenseDescription, "; "),
+			EntitlementID: license.EntitlementID,
+			Type:          license.LicenseType,
+			Status:        license.LicensePrimaryStatus,
+		})
+	}
+	info.LicenseLevel = models.HighestLicenseLevel(info.Licenses)
+
+	client.logger.Infow("extracted license information",
+		"licenses", len(info.Licenses),
+		"level", info.LicenseLevel,
+	)
+
+	return nil
+}
+
+// certificateInfo converts the leaf certificate presented by an iDRAC.
+// Returns nil if no certificate was captured.
+func certificateInfo(cert *x509.Certificate) *models.CertificateInfo {
+	if cert == nil {
+		return nil
+	}
+	return &models.CertificateInfo{
+		Subject:   cert.Subject.String(),
+		Issuer:    cert.Issuer.String(),
+		NotBefore: cert.NotBefore,
+		NotAfter:  cert.NotAfter,
+		SelfSigned: bytes.Equal(cert.RawSubject, cert.RawIssuer) &&
+			cert.CheckSignature(cert.SignatureAlgorithm, cert.RawTBSCertificate, cert.Signature) == nil,
+	}
+}
+
+// calculateStats computes statistics from scan results.
+func (s *Scanner) calculateStats(results []models.ServerInfo, durations []time.Duration, totalDuration time.Duration) models.CollectionStats {
+	b := newStatsBuilder(s.cfg.Stats.GetPercentiles())
+	for _, result := range results {
+		b.addResult(result)
+	}
+	for _, duration := range durations {
+		b.addDuration(duration)
+	}
+	return b.build(totalDuration)
+}
+
+// ============================================================================
+// Redfish HTTP Client
+// ============================================================================
+
+// redfishClient handles HTTP communication with a Redfish API endpoint.
+type redfishClient struct {
+	baseURL    string
+	username   string
+	password   string
+	httpClient *http.Client
+	headers    config.HTTPConfig // User-Agent and extra headers
+	logger     *zap.SugaredLogger
+
+	// peerCert is the leaf TLS certificate presented on the last response.
+	peerCert *x509.Certificate
+
+	// etags enables conditional requests in getMember (nil if disabled).
+	etags *etagCache
+
+	// schema validates responses in strict schema mode (nil if disabled).
+	schema *schemaCheck
+
+	// raw records the responses for the JSON output (nil if disabled).
+	raw *rawCapture
+
+	// missing collects the endpoints answered with 404 or 501 since the
+	// current collector phase started.
+	missing []models.UnsupportedEndpoint
+
+	// system holds the resource paths of the system being scanned.
+	system systemPaths
+
+	// retry bounds the retries of requests answered with 503 (BMC busy).
+	retry config.RetryConfig
+
+	// audit records the writes (nil if auditing is disabled).
+	audit *audit.Logger
+}
+
+// get performs a GET request to the Redfish API and unmarshals the response.
+func (c *redfishClient) get(ctx context.Context, path string, target interface{}) error {
+	return c.fetch(ctx, path, target, false)
+}
+
+// getMember is get for collection members and other resources that rarely
+// change. With the ETag cache enabled it sends If-None-Match and reuses the
+// cached body when the iDRAC answers 304 Not Modified.
+func (c *redfishClient) getMember(ctx context.Context, path string, target interface{}) error {
+	return c.fetch(ctx, path, target, c.etags != nil)
+}
+
+// fetch performs the request, repeating it while the iDRAC answers 503 and
+// the wait stays within the retry budget: at most retry.max_attempts
+// attempts, no single wait longer than retry.max_delay and none past the
+// deadline of ctx. The last BMCBusyError is returned if the budget runs out.
+func (c *redfishClient) fetch(ctx context.Context, path string, target interface{}, conditional bool) error {
+	for attempt := 1; ; attempt++ {
+		err := c.fetchOnce(ctx, path, target, conditional)
+		retryAfter, busy := errors.RetryAfter(err)
+		if !busy || attempt >= c.retry.GetMaxAttempts() {
+			return err
+		}
+
+		wait := retryAfter
+		if wait == 0 {
+			wait = c.retry.GetBaseDelay() << (attempt - 1)
+		}
+		if wait > c.retry.GetMaxDelay() {
+			return err
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Now().Add(wait).After(deadline) {
+			return err
+		}
+
+		c.logger.Infow("BMC busy, waiting before retrying",
+			"path", path,
+			"wait", wait,
+			"attempt", attempt,
+		)
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// parseRetryAfter returns the wait of a Retry-After header, given in seconds
+// or as an HTTP date, and 0 if it is missing or invalid.
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d.Round(time.Second)
+		}
+	}
+	return 0
+}
+
+// newRequest creates a request to the Redfish API with the client's
+// credentials and headers.
+func (c *redfishClient) newRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Set authentication (none with a client certificate)
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	// Set headers
+	req.Header.Set("Accept", "application/json")
+	if c.headers.AcceptLanguage != "" {
+		req.Header.Set("Accept-Language", c.headers.AcceptLanguage)
+	}
+	c.headers.ApplyHeaders(req.Header)
+	return req, nil
+}
+
+func (c *redfishClient) fetchOnce(ctx context.Context, path string, target interface{}, conditional bool) error {
+	url := c.baseURL + path
+
+	req, err := c.newRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return err
+	}
+
+	var cached etagEntry
+	var haveCached bool
+	if conditional {
+		if cached, haveCached = c.etags.lookup(url); haveCached {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+	}
+
+	// Make request
+	c.logger.Debugw("making redfish request",
+		"method", "GET",
+		"url", url,
+	)
+
+	startTime := time.Now()
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return errors.NewRedfishTransportError(c.baseURL, path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.TLS != nil && len(resp.TLS.PeerCertificates) > 0 {
+		c.peerCert = resp.TLS.PeerCertificates[0]
+	}
+
+	duration := time.Since(startTime)
+
+	c.logger.Debugw("redfish request completed",
+		"url", url,
+		"status", resp.StatusCode,
+		"duration", duration,
+	)
+
+	// Read response body
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	// Reuse the cached body if the resource has not changed. A 304 to a
+	// request without If-None-Match has no body to fall back to.
+	if resp.StatusCode == http.StatusNotModified {
+		if !haveCached {
+			return errors.NewRedfishError(c.baseURL, path, resp.StatusCode, resp.Status, "not modified, but no cached response to reuse")
+		}
+		body = cached.Body
+	} else if conditional && resp.StatusCode == http.StatusOK {
+		if etag := resp.Header.Get("ETag"); etag != "" {
+			c.etags.store(url, etag, body)
+		}
+	}
+
+	// The iDRAC answers 503 while it resets or is in maintenance
+	if resp.StatusCode == http.StatusServiceUnavailable {
+		c.logger.Debugw("BMC busy",
+			"url", url,
+			"retry_after", resp.Header.Get("Retry-After"),
+		)
+		return errors.NewBMCBusyError(c.baseURL, path, resp.Status, string(body), parseRetryAfter(resp.Header.Get("Retry-After")))
+	}
+
+	// Check for HTTP errors
+	if resp.StatusCode >= 400 {
+		c.logger.Errorw("redfish API error",
+			"url", url,
+			"status", resp.StatusCode,
+			"body", string(body),
+		)
+
+		// Check for authentication error
+		if resp.StatusCode == 401 || resp.StatusCode == 403 {
+			return errors.ErrAuthenticationFailed
+		}
+
+		if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusNotImplemented {
+			c.missing = append(c.missing, models.UnsupportedEndpoint{Path: path, Status: resp.StatusCode})
+		}
+		if resp.StatusCode == 404 {
+			return errors.ErrNotFound
+		}
+
+		return errors.NewRedfishError(c.baseURL, path, resp.StatusCode, resp.Status, string(body))
+	}
+
+	if c.schema != nil {
+		c.schema.check(c.logger, url, body)
+	}
+	if c.raw != nil {
+		c.raw.record(path, body)
+	}
+
+	// Unmarshal JSON
+	if target != nil {
+		if err := json.Unmarshal(body, target); err != nil {
+			return fmt.Errorf("failed to unmarshal response: %w", err)
+		}
+	}
+
+	return nil
+}
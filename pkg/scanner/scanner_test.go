@@ -0,0 +1,551 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/braunma/idrac-netbox-importer/internal/redfish"
+	"github.com/braunma/idrac-netbox-importer/pkg/config"
+	"github.com/braunma/idrac-netbox-importer/pkg/errors"
+	"github.com/braunma/idrac-netbox-importer/pkg/logging"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+func init() {
+	_ = logging.Init(logging.Config{
+		Level:  "error",
+		Format: "console",
+	})
+}
+
+func TestNew(t *testing.T) {
+	cfg := &config.Config{
+		Concurrency: 10,
+	}
+
+	scanner := New(cfg)
+
+	assert.NotNil(t, scanner)
+	assert.Equal(t, 10, scanner.concurrency)
+}
+
+func TestNew_DefaultConcurrency(t *testing.T) {
+	cfg := &config.Config{
+		Concurrency: 0,
+	}
+
+	scanner := New(cfg)
+
+	assert.Equal(t, 5, scanner.concurrency)
+}
+
+func TestCalculateStats(t *testing.T) {
+	scanner := New(&config.Config{Concurrency: 5})
+
+	results := []models.ServerInfo{
+		{Host: "host1", Model: "R750"},         // Success
+		{Host: "host2", Model: "R650"},         // Success
+		{Host: "host3", Error: assert.AnError}, // Failure
+	}
+
+	durations := []time.Duration{
+		1 * time.Second,
+		2 * time.Second,
+		500 * time.Millisecond,
+	}
+
+	stats := scanner.calculateStats(results, durations, 3*time.Second)
+
+	assert.Equal(t, 3, stats.TotalServers)
+	assert.Equal(t, 2, stats.SuccessfulCount)
+	assert.Equal(t, 1, stats.FailedCount)
+	assert.InDelta(t, 66.67, stats.SuccessRate(), 0.1)
+	assert.Equal(t, 500*time.Millisecond, stats.FastestDuration)
+	assert.Equal(t, 2*time.Second, stats.SlowestDuration)
+	assert.Equal(t, "p50 1s, p90 2s, p99 2s", stats.PercentilesSummary())
+	require.Len(t, stats.DurationHistogram, len(models.DurationBuckets)+1)
+	assert.Equal(t, 2, stats.DurationHistogram[0].Count, "up to 1s")
+	assert.Equal(t, 1, stats.DurationHistogram[1].Count, "1s-2s")
+}
+
+func TestCalculateStats_Empty(t *testing.T) {
+	scanner := New(&config.Config{Concurrency: 5})
+
+	stats := scanner.calculateStats([]models.ServerInfo{}, []time.Duration{}, 0)
+
+	assert.Equal(t, 0, stats.TotalServers)
+	assert.Equal(t, 0, stats.SuccessfulCount)
+	assert.Equal(t, 0, stats.FailedCount)
+	assert.Equal(t, float64(0), stats.SuccessRate())
+}
+
+func TestCalculateStats_AllSuccess(t *testing.T) {
+	scanner := New(&config.Config{Concurrency: 5})
+
+	results := []models.ServerInfo{
+		{Host: "host1", Model: "R750"},
+		{Host: "host2", Model: "R650"},
+	}
+
+	durations := []time.Duration{
+		1 * time.Second,
+		2 * time.Second,
+	}
+
+	stats := scanner.calculateStats(results, durations, 2*time.Second)
+
+	assert.Equal(t, 2, stats.TotalServers)
+	assert.Equal(t, 2, stats.SuccessfulCount)
+	assert.Equal(t, 0, stats.FailedCount)
+	assert.Equal(t, float64(100), stats.SuccessRate())
+}
+
+func TestCalculateStats_AllFailed(t *testing.T) {
+	scanner := New(&config.Config{Concurrency: 5})
+
+	results := []models.ServerInfo{
+		{Host: "host1", Error: assert.AnError},
+		{Host: "host2", Error: assert.AnError},
+	}
+
+	durations := []time.Duration{
+		100 * time.Millisecond,
+		200 * time.Millisecond,
+	}
+
+	stats := scanner.calculateStats(results, durations, 200*time.Millisecond)
+
+	assert.Equal(t, 2, stats.TotalServers)
+	assert.Equal(t, 0, stats.SuccessfulCount)
+	assert.Equal(t, 2, stats.FailedCount)
+	assert.Equal(t, float64(0), stats.SuccessRate())
+	assert.Nil(t, stats.Fleet)
+}
+
+func TestCalculateStats_FailureReasons(t *testing.T) {
+	scanner := New(&config.Config{Concurrency: 5})
+
+	results := []models.ServerInfo{
+		{Host: "host1", Error: errors.NewCollectionError("host1", "system", errors.ErrAuthenticationFailed)},
+		{Host: "host2", Error: errors.NewCollectionError("host2", "system", errors.ErrAuthenticationFailed)},
+		{Host: "host3", Error: errors.NewCollectionError("host3", "system", context.DeadlineExceeded)},
+		{Host: "host4", Model: "R750"},
+	}
+
+	stats := scanner.calculateStats(results, nil, time.Second)
+
+	require.Len(t, stats.FailureReasons, 2)
+	assert.Equal(t, "auth", stats.FailureReasons[0].Category)
+	assert.Equal(t, 2, stats.FailureReasons[0].Count)
+	assert.Equal(t, "host1", stats.FailureReasons[0].ExampleHost)
+	assert.Equal(t, "timeout", stats.FailureReasons[1].Category)
+	assert.Len(t, stats.TopFailureReasons(1), 1)
+	assert.Equal(t, map[string]int{"E_AUTH": 2, "E_TIMEOUT": 1}, stats.FailureCodes)
+
+	require.NotNil(t, stats.Fleet)
+	assert.Equal(t, 1, stats.Fleet.Servers, "only successful hosts count towards the fleet")
+}
+
+func TestScanAll_ContextCancellation(t *testing.T) {
+	cfg := &config.Config{
+		Servers: []config.ServerConfig{
+			{Host: "192.168.1.1", Username: "admin", Password: "pass"},
+			{Host: "192.168.1.2", Username: "admin", Password: "pass"},
+		},
+		Defaults: config.DefaultsConfig{
+			TimeoutSeconds: 1,
+		},
+		Concurrency: 2,
+	}
+
+	scanner := New(cfg)
+
+	// Create already cancelled context
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results, stats := scanner.ScanAll(ctx)
+
+	// All should fail due to context cancellation
+	assert.Equal(t, 2, len(results))
+	assert.Equal(t, 2, stats.FailedCount)
+}
+
+func TestScanAll_Deadline(t *testing.T) {
+	cfg := &config.Config{
+		Servers: []config.ServerConfig{
+			{Host: "192.168.1.1", Username: "admin", Password: "pass"},
+			{Host: "192.168.1.2", Username: "admin", Password: "pass"},
+		},
+		Concurrency: 2,
+	}
+
+	results, stats := New(cfg).WithDeadline(time.Now().Add(-time.Second)).ScanAll(context.Background())
+
+	assert.Empty(t, results, "no host is started after the deadline")
+	assert.Equal(t, 0, stats.TotalServers)
+}
+
+func TestCollectionStats_SuccessRate(t *testing.T) {
+	tests := []struct {
+		name     string
+		stats    models.CollectionStats
+		expected float64
+	}{
+		{
+			name: "all success",
+			stats: models.CollectionStats{
+				TotalServers:    10,
+				SuccessfulCount: 10,
+			},
+			expected: 100.0,
+		},
+		{
+			name: "half success",
+			stats: models.CollectionStats{
+				TotalServers:    10,
+				SuccessfulCount: 5,
+			},
+			expected: 50.0,
+		},
+		{
+			name: "none success",
+			stats: models.CollectionStats{
+				TotalServers:    10,
+				SuccessfulCount: 0,
+			},
+			expected: 0.0,
+		},
+		{
+			name: "no servers",
+			stats: models.CollectionStats{
+				TotalServers:    0,
+				SuccessfulCount: 0,
+			},
+			expected: 0.0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.stats.SuccessRate())
+		})
+	}
+}
+
+func TestRedfishClient_RetriesBusyBMC(t *testing.T) {
+	requests := 0
+	retryAfter := ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests < 3 || retryAfter != "" {
+			if retryAfter != "" {
+				w.Header().Set("Retry-After", retryAfter)
+			}
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"Id": "System.Embedded.1"}`))
+	}))
+	defer server.Close()
+
+	client := &redfishClient{
+		baseURL:    server.URL,
+		httpClient: server.Client(),
+		logger:     logging.WithComponent("test"),
+		retry:      config.RetryConfig{MaxAttempts: 3, BaseDelay: "10ms", MaxDelay: "1s"},
+	}
+
+	var target struct {
+		ID string `json:"Id"`
+	}
+	require.NoError(t, client.get(context.Background(), "/system", &target))
+	assert.Equal(t, 3, requests, "retried until the BMC answered")
+	assert.Equal(t, "System.Embedded.1", target.ID)
+
+	// A Retry-After beyond max_delay is not waited for
+	requests = 0
+	retryAfter = "120"
+	err := client.get(context.Background(), "/system", &target)
+	require.Error(t, err)
+	assert.Equal(t, 1, requests)
+	assert.Equal(t, errors.CategoryBMCBusy, errors.Categorize(err))
+	wait, busy := errors.RetryAfter(err)
+	assert.True(t, busy)
+	assert.Equal(t, 2*time.Minute, wait)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	assert.Equal(t, 30*time.Second, parseRetryAfter("30"))
+	assert.Zero(t, parseRetryAfter(""))
+	assert.Zero(t, parseRetryAfter("soon"))
+	assert.Zero(t, parseRetryAfter(time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat)))
+
+	d := parseRetryAfter(time.Now().Add(90 * time.Second).UTC().Format(http.TimeFormat))
+	assert.InDelta(t, 90, d.Seconds(), 2)
+}
+
+func TestDriveLocation(t *testing.T) {
+	bay, enc := driveLocation(redfish.Drive{ID: "Disk.Bay.3:Enclosure.Internal.0-1:RAID.Integrated.1-1"})
+	require.NotNil(t, bay)
+	assert.Equal(t, 3, *bay)
+	assert.Equal(t, "Enclosure.Internal.0-1:RAID.Integrated.1-1", enc)
+
+	bay, enc = driveLocation(redfish.Drive{ID: "Disk.Bay.0"})
+	require.NotNil(t, bay)
+	assert.Equal(t, 0, *bay)
+	assert.Empty(t, enc)
+
+	bay, _ = driveLocation(redfish.Drive{ID: "nvme0", PhysicalLocation: redfish.PhysicalLocation{
+		PartLocation: redfish.PartLocation{LocationType: "Bay", LocationOrdinalValue: 7},
+	}})
+	require.NotNil(t, bay)
+	assert.Equal(t, 7, *bay)
+
+	bay, _ = driveLocation(redfish.Drive{ID: "Disk.Direct.0-0:AHCI.Slot.2-1"})
+	assert.Nil(t, bay, "BOSS drives are not in a bay")
+}
+
+func TestTally(t *testing.T) {
+	assert.Equal(t, "2x 32 GiB DDR4, 1x 16 GiB DDR4",
+		tally([]string{"32 GiB DDR4", "32 GiB DDR4", "16 GiB DDR4"}))
+	assert.Equal(t, "1x unknown", tally([]string{""}))
+	assert.Empty(t, tally(nil))
+}
+
+func TestScanServer_CanonicalModel(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redfish/v1/Systems/System.Embedded.1" {
+			fmt.Fprint(w, `{"Id": "System.Embedded.1", "Manufacturer": "Dell Inc.", "Model": "Dell PowerEdge R750 HX ", "SKU": "ABC1234"}`)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	insecure := true
+	s := New(&config.Config{
+		Defaults: config.DefaultsConfig{InsecureSkipVerify: &insecure},
+		Catalog:  config.CatalogConfig{Aliases: map[string]string{"PowerEdge R750 HX": "PowerEdge R750"}},
+	})
+
+	infos := s.scanServer(context.Background(), config.ServerConfig{Host: server.Listener.Addr().String()})
+	require.Len(t, infos, 1)
+	assert.Equal(t, "PowerEdge R750", infos[0].Model)
+	assert.Equal(t, "Dell PowerEdge R750 HX ", infos[0].ReportedModel)
+	require.NotNil(t, infos[0].Platform)
+	assert.Equal(t, "15G", infos[0].Platform.Generation)
+}
+
+func TestScanServer_Labels(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redfish/v1/Systems/System.Embedded.1" {
+			fmt.Fprint(w, `{"Id": "System.Embedded.1", "Model": "PowerEdge R750", "SKU": "ABC1234"}`)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	insecure := true
+	s := New(&config.Config{Defaults: config.DefaultsConfig{InsecureSkipVerify: &insecure}})
+	labels := map[string]string{"datacenter": "fra1"}
+
+	infos := s.scanServer(context.Background(), config.ServerConfig{Host: server.Listener.Addr().String(), Labels: labels})
+	require.Len(t, infos, 1)
+	assert.Equal(t, labels, infos[0].Labels)
+
+	server.Close()
+	infos = s.scanServer(context.Background(), config.ServerConfig{Host: server.Listener.Addr().String(), Labels: labels})
+	require.Len(t, infos, 1)
+	require.Error(t, infos[0].Error)
+	assert.Equal(t, labels, infos[0].Labels, "failed hosts keep their labels")
+}
+
+func TestScanAll_Durations(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redfish/v1/Systems/System.Embedded.1" {
+			fmt.Fprint(w, `{"Id": "System.Embedded.1", "Model": "PowerEdge R750", "SKU": "ABC1234"}`)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	insecure := true
+	s := New(&config.Config{
+		Servers:  []config.ServerConfig{{Host: server.Listener.Addr().String()}},
+		Defaults: config.DefaultsConfig{InsecureSkipVerify: &insecure},
+	})
+
+	results, _ := s.ScanAll(context.Background())
+	require.Len(t, results, 1)
+	assert.Positive(t, results[0].ScanDuration)
+	assert.Contains(t, results[0].PhaseDurations, models.PhaseSystem)
+	assert.Contains(t, results[0].PhaseDurations, models.PhaseStorage, "failed phases are timed too")
+}
+
+func TestScanServer_Certificate(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redfish/v1/Systems/System.Embedded.1" {
+			fmt.Fprint(w, `{"Id": "System.Embedded.1", "Model": "PowerEdge R750", "SKU": "ABC1234"}`)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	insecure := true
+	s := New(&config.Config{Defaults: config.DefaultsConfig{InsecureSkipVerify: &insecure}})
+
+	infos := s.scanServer(context.Background(), config.ServerConfig{Host: server.Listener.Addr().String()})
+	require.Len(t, infos, 1)
+	cert := infos[0].Certificate
+	require.NotNil(t, cert)
+	assert.Equal(t, server.Certificate().NotAfter, cert.NotAfter)
+	assert.False(t, cert.IsExpired(time.Now()))
+	assert.True(t, cert.SelfSigned)
+}
+
+// newTestClient returns a client for the default Dell system on server.
+func newTestClient(server *httptest.Server) *redfishClient {
+	return &redfishClient{
+		baseURL:    server.URL,
+		httpClient: server.Client(),
+		logger:     logging.WithComponent("test"),
+		system:     defaultSystem(),
+	}
+}
+
+func TestCollectLicenses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/redfish/v1/Managers/iDRAC.Embedded.1/Oem/Dell/DellLicenses":
+			fmt.Fprint(w, `{"Members": [
+				{"@odata.id": "/redfish/v1/Managers/iDRAC.Embedded.1/Oem/Dell/DellLicenses/FD01"},
+				{"@odata.id": "/redfish/v1/Managers/iDRAC.Embedded.1/Oem/Dell/DellLicenses/FD02"},
+				{"@odata.id": "/redfish/v1/Managers/iDRAC.Embedded.1/Oem/Dell/DellLicenses/gone"}
+			]}`)
+		case "/redfish/v1/Managers/iDRAC.Embedded.1/Oem/Dell/DellLicenses/FD01":
+			fmt.Fprint(w, `{"EntitlementID": "FD01", "LicenseDescription": ["iDRAC9 Express License"], "LicenseType": "Perpetual", "LicensePrimaryStatus": "OK"}`)
+		case "/redfish/v1/Managers/iDRAC.Embedded.1/Oem/Dell/DellLicenses/FD02":
+			fmt.Fprint(w, `{"EntitlementID": "FD02", "LicenseDescription": ["iDRAC9 Enterprise License"], "LicenseType": "Perpetual", "LicensePrimaryStatus": "OK"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	s := New(&config.Config{})
+	var info models.ServerInfo
+	require.NoError(t, s.collectLicenses(context.Background(), newTestClient(server), &info))
+	require.Len(t, info.Licenses, 2, "unreadable licenses are skipped")
+	assert.Equal(t, "iDRAC9 Enterprise License", info.Licenses[1].Description)
+	assert.Equal(t, models.LicenseLevelEnterprise, info.LicenseLevel, "the highest license wins")
+}
+
+// storageHandler serves a PERC with a degraded cache battery and one SSD in
+// bay 0 of an 8-bay backplane.
+// Like Dell, the controller also links the server chassis as an enclosure.
+func storageHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/redfish/v1/Systems/System.Embedded.1/Storage":
+		fmt.Fprint(w, `{"Members": [{"@odata.id": "/redfish/v1/Systems/System.Embedded.1/Storage/RAID.Integrated.1-1"}]}`)
+	case "/redfish/v1/Systems/System.Embedded.1/Storage/RAID.Integrated.1-1":
+		fmt.Fprint(w, `{
+			"Id": "RAID.Integrated.1-1",
+			"Name": "PERC H755 Front",
+			"Drives": [{"@odata.id": "/redfish/v1/Systems/System.Embedded.1/Storage/RAID.Integrated.1-1/Drives/Disk.Bay.0"}],
+			"Links": {"Enclosures": [
+				{"@odata.id": "/redfish/v1/Chassis/Enclosure.Internal.0-1:RAID.Integrated.1-1"},
+				{"@odata.id": "/redfish/v1/Chassis/System.Embedded.1"}
+			]},
+			"StorageControllers": [{
+				"Name": "PERC H755 Front",
+				"Model": "PERC H755 Front",
+				"CacheSummary": {"TotalCacheSizeMiB": 8192},
+				"Status": {"Health": "OK"}
+			}],
+			"Oem": {"Dell": {"DellControllerBattery": {
+				"Id": "Battery.Integrated.1:RAID.Integrated.1-1",
+				"Name": "Battery on PERC H755 Front",
+				"PrimaryStatus": "Degraded",
+				"RAIDState": "Degraded"
+			}}}
+		}`)
+	case "/redfish/v1/Systems/System.Embedded.1/Storage/RAID.Integrated.1-1/Drives/Disk.Bay.0":
+		fmt.Fprint(w, `{"Id": "Disk.Bay.0", "Name": "SSD 0", "CapacityBytes": 960197124096, "MediaType": "SSD", "Protocol": "SATA"}`)
+	case "/redfish/v1/Chassis/System.Embedded.1":
+		fmt.Fprint(w, `{"Id": "System.Embedded.1", "ChassisType": "RackMount", "Model": "PowerEdge R750"}`)
+	case "/redfish/v1/Chassis/Enclosure.Internal.0-1:RAID.Integrated.1-1":
+		fmt.Fprint(w, `{
+			"Id": "Enclosure.Internal.0-1:RAID.Integrated.1-1",
+			"Name": "BP15G+ 0:1",
+			"ChassisType": "Enclosure",
+			"Model": "BP15G+ 8x2.5",
+			"Links": {"Drives": [{"@odata.id": "/redfish/v1/Systems/System.Embedded.1/Storage/RAID.Integrated.1-1/Drives/Disk.Bay.0"}]},
+			"Oem": {"Dell": {"DellEnclosure": {"SlotCount": 8, "Version": "7.10", "Connector": 0}}}
+		}`)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestCollectStorage_Enclosures(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(storageHandler))
+	defer server.Close()
+
+	s := New(&config.Config{})
+	var info models.ServerInfo
+	require.NoError(t, s.collectStorage(context.Background(), newTestClient(server), &info))
+	require.Len(t, info.Enclosures, 1, "the chassis itself is not a storage enclosure")
+	enc := info.Enclosures[0]
+	assert.Equal(t, "Enclosure.Internal.0-1:RAID.Integrated.1-1", enc.ID)
+	assert.Equal(t, "BP15G+ 8x2.5", enc.Model)
+	assert.Equal(t, "7.10", enc.FirmwareVersion)
+	assert.False(t, enc.External)
+	assert.Equal(t, 8, enc.SlotCount)
+	assert.Equal(t, 1, enc.SlotsUsed)
+	assert.Equal(t, 8, info.DriveBaysTotal)
+}
+
+func TestCollectStorage_DriveBays(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(storageHandler))
+	defer server.Close()
+
+	s := New(&config.Config{})
+	var info models.ServerInfo
+	require.NoError(t, s.collectStorage(context.Background(), newTestClient(server), &info))
+	require.Len(t, info.Drives, 1)
+	require.NotNil(t, info.Drives[0].Bay)
+	assert.Equal(t, 0, *info.Drives[0].Bay)
+	assert.Equal(t, "Enclosure.Internal.0-1:RAID.Integrated.1-1", info.Drives[0].Enclosure, "linked from the enclosure")
+
+	bays := info.DriveBayMaps()
+	require.Len(t, bays, 1)
+	assert.Equal(t, 7, bays[0].Free())
+}
+
+func TestCollectStorage_Battery(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(storageHandler))
+	defer server.Close()
+
+	s := New(&config.Config{})
+	var info models.ServerInfo
+	require.NoError(t, s.collectStorage(context.Background(), newTestClient(server), &info))
+	require.Len(t, info.StorageControllers, 1)
+	ctrl := info.StorageControllers[0]
+	assert.Equal(t, "RAID.Integrated.1-1", ctrl.ID)
+	assert.Equal(t, 8192, ctrl.CacheMiB)
+	require.NotNil(t, ctrl.Battery)
+	assert.Equal(t, models.HealthWarning, ctrl.Battery.Health)
+	assert.Equal(t, "Degraded", ctrl.Battery.State)
+	assert.Contains(t, info.HealthIssues(), models.HealthIssue{Component: "battery", Name: "RAID.Integrated.1-1", Health: models.HealthWarning})
+}
@@ -0,0 +1,54 @@
+package scanner
+
+import (
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/braunma/idrac-netbox-importer/internal/redfish/schema"
+)
+
+// schemaCheck validates the responses of one host's scan against the
+// bundled Redfish schemas (http.strict_schema). A violation is logged once
+// per resource type, property and message, so 24 DIMMs with the same quirk
+// log one line; all of them are counted.
+type schemaCheck struct {
+	validator *schema.Validator
+
+	mu         sync.Mutex
+	seen       map[string]bool
+	violations int
+}
+
+func newSchemaCheck() *schemaCheck {
+	return &schemaCheck{validator: schema.Default(), seen: make(map[string]bool)}
+}
+
+// check validates the body of url and logs its new violations.
+func (c *schemaCheck) check(log *zap.SugaredLogger, url string, body []byte) {
+	resource, violations := c.validator.Validate(body)
+	for _, v := range violations {
+		key := resource + "\x00" + v.Path + "\x00" + v.Message
+		c.mu.Lock()
+		c.violations++
+		first := !c.seen[key]
+		c.seen[key] = true
+		c.mu.Unlock()
+
+		if first {
+			log.Warnw("redfish schema violation",
+				"url", url,
+				"resource", resource,
+				"property", v.Path,
+				"error", v.Message,
+			)
+		}
+	}
+}
+
+// counts returns the number of violations and of distinct ones.
+func (c *schemaCheck) counts() (total, distinct int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.violations, len(c.seen)
+}
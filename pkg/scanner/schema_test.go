@@ -0,0 +1,40 @@
+package scanner
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/braunma/idrac-netbox-importer/internal/redfish"
+	"github.com/braunma/idrac-netbox-importer/pkg/logging"
+)
+
+func TestRedfishClient_StrictSchema(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// "Ok" parses fine but is not a Redfish health, so health checks miss it
+		w.Write([]byte(`{"@odata.id": "` + r.URL.Path + `", "@odata.type": "#Memory.v1_11_0.Memory",
+			"Id": "DIMM", "Name": "DIMM", "CapacityMiB": 32768, "Status": {"Health": "Ok"}}`))
+	}))
+	defer server.Close()
+
+	client := &redfishClient{
+		baseURL:    server.URL,
+		httpClient: server.Client(),
+		logger:     logging.WithComponent("test"),
+		schema:     newSchemaCheck(),
+	}
+
+	for _, path := range []string{"/dimm/a1", "/dimm/a2", "/dimm/b1"} {
+		var memory redfish.Memory
+		require.NoError(t, client.getMember(context.Background(), path, &memory))
+		assert.Equal(t, "Ok", memory.Status.Health)
+	}
+
+	total, distinct := client.schema.counts()
+	assert.Equal(t, 3, total)
+	assert.Equal(t, 1, distinct, "the same violation on every DIMM is logged once")
+}
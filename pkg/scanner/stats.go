@@ -0,0 +1,116 @@
+package scanner
+
+import (
+	"sort"
+	"time"
+
+	"github.com/braunma/idrac-netbox-importer/pkg/errors"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+// statsBuilder accumulates collection statistics one result at a time, so
+// statistics do not require the full result set in memory. Only the scan
+// durations are kept, for the percentiles.
+type statsBuilder struct {
+	stats   models.CollectionStats
+	reasons map[string]*models.FailureReason
+	fleet   models.FleetBuilder
+
+	durationSum time.Duration
+	durations   []time.Duration
+	percentiles []float64
+}
+
+// newStatsBuilder returns a builder that reports the scan durations at
+// percentiles.
+func newStatsBuilder(percentiles []float64) *statsBuilder {
+	return &statsBuilder{reasons: make(map[string]*models.FailureReason), percentiles: percentiles}
+}
+
+// add records a scan result and its duration.
+func (b *statsBuilder) add(info models.ServerInfo, duration time.Duration) {
+	b.addResult(info)
+	b.addDuration(duration)
+}
+
+// addResult counts a success or failure, grouping failures by error category.
+// Successful results are added to the fleet totals.
+func (b *statsBuilder) addResult(info models.ServerInfo) {
+	b.stats.TotalServers++
+	if info.Error == nil {
+		b.stats.SuccessfulCount++
+		if info.Unchanged {
+			b.stats.UnchangedCount++
+		}
+		if info.IsPartial() {
+			b.stats.PartialCount++
+			if b.stats.FailedComponents == nil {
+				b.stats.FailedComponents = make(map[string]int)
+			}
+			for phase := range info.ComponentErrors {
+				b.stats.FailedComponents[phase]++
+			}
+		}
+		b.fleet.Add(info)
+		return
+	}
+	b.stats.FailedCount++
+	if b.stats.FailureCodes == nil {
+		b.stats.FailureCodes = make(map[string]int)
+	}
+	b.stats.FailureCodes[string(errors.CodeOf(info.Error))]++
+
+	category := string(errors.Categorize(info.Error))
+	reason, exists := b.reasons[category]
+	if !exists {
+		reason = &models.FailureReason{
+			Category:     category,
+			ExampleHost:  info.Host,
+			ExampleError: info.Error.Error(),
+		}
+		b.reasons[category] = reason
+	}
+	reason.Count++
+}
+
+// addDuration records the scan duration of a host.
+func (b *statsBuilder) addDuration(d time.Duration) {
+	if len(b.durations) == 0 || d < b.stats.FastestDuration {
+		b.stats.FastestDuration = d
+	}
+	if len(b.durations) == 0 || d > b.stats.SlowestDuration {
+		b.stats.SlowestDuration = d
+	}
+	b.durationSum += d
+	b.durations = append(b.durations, d)
+}
+
+// build returns the accumulated statistics.
+func (b *statsBuilder) build(totalDuration time.Duration) models.CollectionStats {
+	stats := b.stats
+	stats.TotalDuration = totalDuration
+	if stats.TotalServers == 0 {
+		return stats
+	}
+
+	for _, reason := range b.reasons {
+		stats.FailureReasons = append(stats.FailureReasons, *reason)
+	}
+	sort.Slice(stats.FailureReasons, func(i, j int) bool {
+		if stats.FailureReasons[i].Count != stats.FailureReasons[j].Count {
+			return stats.FailureReasons[i].Count > stats.FailureReasons[j].Count
+		}
+		return stats.FailureReasons[i].Category < stats.FailureReasons[j].Category
+	})
+
+	if stats.SuccessfulCount > 0 {
+		fleet := b.fleet.Summary()
+		stats.Fleet = &fleet
+	}
+	if len(b.durations) > 0 {
+		stats.AverageDuration = b.durationSum / time.Duration(len(b.durations))
+		stats.DurationPercentiles = models.Percentiles(b.durations, b.percentiles)
+		stats.DurationHistogram = models.Histogram(b.durations)
+	}
+	return stats
+}
@@ -0,0 +1,128 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/braunma/idrac-netbox-importer/internal/redfish"
+	"github.com/braunma/idrac-netbox-importer/pkg/defaults"
+	"github.com/braunma/idrac-netbox-importer/pkg/errors"
+)
+
+// errNoChassis is returned by chassis collectors for systems without a
+// chassis link.
+var errNoChassis = fmt.Errorf("system has no chassis link")
+
+// systemPaths are the Redfish resources of one ComputerSystem and its chassis.
+type systemPaths struct {
+	ID         string
+	System     string
+	Processors string
+	Memory     string
+	Storage    string
+	Chassis    string // chassis containing the system ("" if not linked)
+	Power      string
+	PCIeSlots  string
+	Manager    string // manager (BMC) of the system ("" if not linked)
+}
+
+// defaultSystem returns the paths of the only system of a Dell iDRAC,
+// System.Embedded.1. Each can be overridden with REDFISH_*_PATH.
+func defaultSystem() systemPaths {
+	return systemPaths{
+		ID:         path.Base(defaults.RedfishSystemPath),
+		System:     defaults.RedfishSystemPath,
+		Processors: defaults.RedfishProcessorsPath,
+		Memory:     defaults.RedfishMemoryPath,
+		Storage:    defaults.RedfishStoragePath,
+		Chassis:    path.Dir(defaults.RedfishPowerPath),
+		Power:      defaults.RedfishPowerPath,
+		PCIeSlots:  defaults.RedfishPCIeSlotsPath,
+		Manager:    defaults.RedfishManagerPath,
+	}
+}
+
+// systemAt returns the paths of the system at p, as far as they are known
+// before its resource is read; resolve fills in the rest.
+func systemAt(p string) systemPaths {
+	p = strings.TrimSuffix(p, "/")
+	if p == defaults.RedfishSystemPath {
+		return defaultSystem()
+	}
+	return systemPaths{
+		ID:         path.Base(p),
+		System:     p,
+		Processors: p + "/Processors",
+		Memory:     p + "/Memory",
+		Storage:    p + "/Storage",
+	}
+}
+
+// resolve takes the ID, the collections, the chassis and the manager from the system
+// resource. The default Dell system keeps its configured paths.
+func (p *systemPaths) resolve(system redfish.System) {
+	if len(system.Links.Chassis) > 0 {
+		p.Chassis = strings.TrimSuffix(system.Links.Chassis[0].OdataID, "/")
+	}
+	if p.System == defaults.RedfishSystemPath {
+		return
+	}
+
+	if len(system.Links.ManagedBy) > 0 {
+		p.Manager = strings.TrimSuffix(system.Links.ManagedBy[0].OdataID, "/")
+	}
+
+	if system.ID != "" {
+		p.ID = system.ID
+	}
+	for _, l := range []struct {
+		link redfish.Link
+		dst  *string
+	}{
+		{system.Processors, &p.Processors},
+		{system.Memory, &p.Memory},
+		{system.Storage, &p.Storage},
+	} {
+		if l.link.OdataID != "" {
+			*l.dst = strings.TrimSuffix(l.link.OdataID, "/")
+		}
+	}
+	if p.Chassis != "" {
+		p.Power = p.Chassis + "/Power"
+		p.PCIeSlots = p.Chassis + "/PCIeSlots"
+	}
+}
+
+// discoverSystems lists the ComputerSystems of the BMC from the Systems
+// collection. If the collection does not exist or is empty, the default Dell
+// system is assumed; other errors fail the scan of the host.
+func (s *Scanner) discoverSystems(ctx context.Context, client *redfishClient) ([]systemPaths, error) {
+	var collection redfish.Collection
+	if err := client.get(ctx, defaults.RedfishSystemsPath, &collection); err != nil {
+		if errors.Categorize(err) != errors.CategoryEndpointMissing {
+			return nil, err
+		}
+		client.logger.Debugw("no systems collection, using the default system", "error", err)
+		return []systemPaths{defaultSystem()}, nil
+	}
+
+	var systems []systemPaths
+	seen := map[string]bool{}
+	for _, member := range collection.Members {
+		sys := systemAt(member.OdataID)
+		if sys.System == "" || seen[sys.System] {
+			continue
+		}
+		seen[sys.System] = true
+		systems = append(systems, sys)
+	}
+	if len(systems) == 0 {
+		return []systemPaths{defaultSystem()}, nil
+	}
+	if len(systems) > 1 {
+		client.logger.Infow("found multiple systems", "count", len(systems))
+	}
+	return systems, nil
+}
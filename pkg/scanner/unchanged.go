@@ -0,0 +1,141 @@
+package scanner
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/braunma/idrac-netbox-importer/internal/history"
+	"github.com/braunma/idrac-netbox-importer/internal/redfish"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+)
+
+// lastChange returns the later of the Lifecycle Controller's last inventory
+// and last update time, or nil if the system reports neither.
+func lastChange(dell *redfish.DellSystemAttributes) *time.Time {
+	if dell == nil {
+		return nil
+	}
+	var latest *time.Time
+	for _, value := range []string{dell.LastSystemInventoryTime, dell.LastUpdateTime} {
+		if t, ok := parseLifecycleTime(value); ok && (latest == nil || t.After(*latest)) {
+			latest = &t
+		}
+	}
+	return latest
+}
+
+// parseLifecycleTime parses a Lifecycle Controller timestamp. Current
+// firmware uses RFC 3339; older firmware the CIM format
+// "20230614095149.000000-300" with the UTC offset in minutes.
+func parseLifecycleTime(value string) (time.Time, bool) {
+	if value == "" {
+		return time.Time{}, false
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), true
+	}
+	if len(value) < 22 || value[14] != '.' {
+		return time.Time{}, false
+	}
+	offset, err := strconv.Atoi(value[21:])
+	if err != nil {
+		return time.Time{}, false
+	}
+	t, err := time.ParseInLocation("20060102150405", value[:14], time.FixedZone("", offset*60))
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t.UTC(), true
+}
+
+// loadBaseline reads the last known good inventory for skipping unchanged
+// hosts, or returns nil if that is disabled or the history is unreadable.
+func (s *Scanner) loadBaseline() *history.Store {
+	if s.baselinePath == "" {
+		return nil
+	}
+	store, err := history.Load(s.baselinePath)
+	if err != nil {
+		s.logger.Warnw("failed to load history, scanning all hosts fully", "error", err)
+		return nil
+	}
+	return store
+}
+
+// unchangedSince returns the last known good inventory of the host of info
+// (collected up to the system resource) if the Lifecycle Controller reports
+// no change since then and its last full scan is recent enough to reuse.
+func (s *Scanner) unchangedSince(info models.ServerInfo, systemID string) (models.ServerInfo, bool) {
+	if s.baseline == nil || info.LastChangedAt == nil {
+		return models.ServerInfo{}, false
+	}
+	// Hosts with several systems are stored per system
+	prev, ok := s.baseline.Last(info.Host + "/" + systemID)
+	if !ok {
+		prev, ok = s.baseline.Last(info.Host)
+	}
+	if !ok || prev.LastChangedAt == nil || !prev.LastChangedAt.Equal(*info.LastChangedAt) {
+		return models.ServerInfo{}, false
+	}
+	if prev.ServiceTag != info.ServiceTag || prev.SerialNumber != info.SerialNumber {
+		return models.ServerInfo{}, false
+	}
+	// A partial scan is no baseline, its gaps would be carried forward
+	if len(prev.ComponentErrors) > 0 {
+		return models.ServerInfo{}, false
+	}
+
+	fullScan := prev.CollectedAt
+	if prev.FullScanAt != nil {
+		fullScan = *prev.FullScanAt
+	}
+	if time.Since(fullScan) >= s.cfg.History.GetFullScanAfter() {
+		return models.ServerInfo{}, false
+	}
+	return prev, true
+}
+
+// reuseInventory returns the hardware of prev with the identity, state and
+// timing of the current scan cur. Placement, notes and compliance (golden
+// config and firmware policy) are applied after the scan and are not carried
+// over.
+func reuseInventory(prev, cur models.ServerInfo) models.ServerInfo {
+	fullScan := prev.CollectedAt
+	if prev.FullScanAt != nil {
+		fullScan = *prev.FullScanAt
+	}
+
+	info := prev
+	info.Host = cur.Host
+	info.Name = cur.Name
+	info.Labels = cur.Labels
+	info.CollectedAt = cur.CollectedAt
+	info.RunID = cur.RunID
+	info.Model = cur.Model
+	info.ReportedModel = cur.ReportedModel
+	info.HostName = cur.HostName
+	info.PowerState = cur.PowerState
+	info.BiosVersion = cur.BiosVersion
+	info.Certificate = cur.Certificate
+	info.PhaseDurations = cur.PhaseDurations
+	info.LastChangedAt = cur.LastChangedAt
+	info.Unchanged = true
+	info.FullScanAt = &fullScan
+	info.Placement = nil
+	info.Notes = nil
+	info.Compliance = nil
+	info.FirmwareCompliance = nil
+	info.Clock = nil
+	info.Accounts = nil
+	info.ComponentErrors = nil
+
+	// Phases that run again (system, power, clock, accounts) replace the
+	// capabilities of the full scan
+	info.Capabilities, info.UnsupportedEndpoints = nil, nil
+	for _, src := range []models.ServerInfo{prev, cur} {
+		for phase, state := range src.Capabilities {
+			info.SetCapability(phase, state, src.UnsupportedEndpointsOf(phase))
+		}
+	}
+	return info
+}
@@ -0,0 +1,41 @@
+package scanner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/braunma/idrac-netbox-importer/internal/redfish"
+)
+
+func TestParseLifecycleTime(t *testing.T) {
+	for value, want := range map[string]string{
+		"2025-03-01T08:15:00-06:00": "2025-03-01T14:15:00Z",
+		"2025-03-01T14:15:00Z":      "2025-03-01T14:15:00Z",
+		"20250301081500.000000-360": "2025-03-01T14:15:00Z",
+		"20250301141500.000000+000": "2025-03-01T14:15:00Z",
+	} {
+		got, ok := parseLifecycleTime(value)
+		require.True(t, ok, value)
+		assert.Equal(t, want, got.Format(time.RFC3339), value)
+	}
+
+	for _, value := range []string{"", "unknown", "20250301141500", "20250301141500.000000+abc"} {
+		_, ok := parseLifecycleTime(value)
+		assert.False(t, ok, value)
+	}
+}
+
+func TestLastChange(t *testing.T) {
+	assert.Nil(t, lastChange(nil))
+	assert.Nil(t, lastChange(&redfish.DellSystemAttributes{LastUpdateTime: "n/a"}))
+
+	latest := lastChange(&redfish.DellSystemAttributes{
+		LastSystemInventoryTime: "2025-03-01T08:15:00Z",
+		LastUpdateTime:          "2025-03-02T10:00:00+01:00",
+	})
+	require.NotNil(t, latest)
+	assert.Equal(t, "2025-03-02T09:00:00Z", latest.Format(time.RFC3339))
+}
@@ -0,0 +1,40 @@
+package units
+
+import "math"
+
+// marketingSizesGB are the labelled capacities of common drives in decimal
+// GB, ascending.
+var marketingSizesGB = []int{
+	100, 120, 128, 146, 200, 240, 250, 256, 300, 400, 450, 480, 500, 512,
+	600, 800, 900, 960, 1000, 1024, 1200, 1600, 1800, 1920, 2000, 2048,
+	2400, 3200, 3840, 4000, 4096, 6000, 6400, 7680, 8000, 10000, 12000,
+	12800, 14000, 15360, 16000, 18000, 20000, 22000, 24000, 30720,
+}
+
+// marketingTolerance is how far the exact capacity of a drive may be from
+// its labelled size, relative to the label.
+const marketingTolerance = 0.02
+
+// MarketingGB returns the labelled capacity of a drive of the given size in
+// bytes, in decimal GB: the exact size rounded to the closest standard drive
+// size (e.g. 960197124096 bytes, 894 GiB, is a 960 GB drive). Sizes that are
+// not within 2% of a standard size are rounded to whole decimal GB.
+func MarketingGB(bytes int64) int {
+	gb := float64(bytes) / 1e9
+	best, bestDiff := 0, math.Inf(1)
+	for _, size := range marketingSizesGB {
+		diff := math.Abs(gb - float64(size))
+		if diff <= float64(size)*marketingTolerance && diff < bestDiff {
+			best, bestDiff = size, diff
+		}
+	}
+	if best == 0 {
+		return int(math.Round(gb))
+	}
+	return best
+}
+
+// GiBToBytes converts a size in GiB to bytes.
+func GiBToBytes(gib float64) int64 {
+	return int64(math.Round(gib * bytesPerGiB))
+}
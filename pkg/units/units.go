@@ -0,0 +1,113 @@
+// Package units formats memory and storage sizes for reports and NetBox.
+//
+// The scanner records sizes in binary units as Redfish reports them (memory
+// in GiB, drives in GiB, storage totals in TiB). A Format presents them either
+// in binary units or converted to decimal units (GB, TB) as printed on drive
+// labels and data sheets, so totals and drive sizes in one report always use
+// the same system. MarketingGB maps exact drive sizes to the capacities
+// drives are sold with, for storage summaries.
+package units
+
+import (
+	"fmt"
+	"math"
+)
+
+// System is a unit system.
+type System string
+
+// Unit systems.
+const (
+	// Binary uses powers of 1024: GiB, TiB.
+	Binary System = "binary"
+	// Decimal uses powers of 1000: GB, TB.
+	Decimal System = "decimal"
+)
+
+// DefaultPrecision is the default number of decimals of terabyte values.
+const DefaultPrecision = 2
+
+const (
+	bytesPerGiB = 1 << 30
+	bytesPerTiB = 1 << 40
+)
+
+// Format converts and labels sizes in one unit system.
+type Format struct {
+	System System
+	// Precision is the number of decimals of terabyte values; gigabyte
+	// values are whole numbers.
+	Precision int
+}
+
+// Default returns the binary format with the default precision.
+func Default() Format {
+	return Format{System: Binary, Precision: DefaultPrecision}
+}
+
+// ParseSystem returns the system named s ("" is binary).
+func ParseSystem(s string) (System, error) {
+	switch System(s) {
+	case "", Binary:
+		return Binary, nil
+	case Decimal:
+		return Decimal, nil
+	}
+	return "", fmt.Errorf("unknown unit system %q (expected binary or decimal)", s)
+}
+
+func (f Format) decimal() bool {
+	return f.System == Decimal
+}
+
+// GB converts a size in GiB to the gigabyte unit of f.
+func (f Format) GB(gib float64) float64 {
+	if f.decimal() {
+		return gib * bytesPerGiB / 1e9
+	}
+	return gib
+}
+
+// TB converts a size in TiB to the terabyte unit of f.
+func (f Format) TB(tib float64) float64 {
+	if f.decimal() {
+		return tib * bytesPerTiB / 1e12
+	}
+	return tib
+}
+
+// GBUnit returns the gigabyte label of f: "GiB" or "GB".
+func (f Format) GBUnit() string {
+	if f.decimal() {
+		return "GB"
+	}
+	return "GiB"
+}
+
+// TBUnit returns the terabyte label of f: "TiB" or "TB".
+func (f Format) TBUnit() string {
+	if f.decimal() {
+		return "TB"
+	}
+	return "TiB"
+}
+
+// WholeGB returns a size in GiB as whole gigabytes of f.
+func (f Format) WholeGB(gib float64) int {
+	return int(math.Round(f.GB(gib)))
+}
+
+// Gigabytes formats a size in GiB, e.g. "512 GiB" or "550 GB".
+func (f Format) Gigabytes(gib float64) string {
+	return fmt.Sprintf("%d %s", f.WholeGB(gib), f.GBUnit())
+}
+
+// TerabyteValue formats a size in TiB without unit, with the precision of f.
+func (f Format) TerabyteValue(tib float64) string {
+	return fmt.Sprintf("%.*f", f.Precision, f.TB(tib))
+}
+
+// Terabytes formats a size in TiB, e.g. "13.97 TiB" or "15.36 TB".
+func (f Format) Terabytes(tib float64) string {
+	return f.TerabyteValue(tib) + " " + f.TBUnit()
+}
@@ -0,0 +1,52 @@
+package units
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormat(t *testing.T) {
+	binary := Default()
+	assert.Equal(t, "512 GiB", binary.Gigabytes(512))
+	assert.Equal(t, "13.97 TiB", binary.Terabytes(13.97))
+	assert.Equal(t, 512, binary.WholeGB(511.6))
+
+	decimal := Format{System: Decimal, Precision: 1}
+	assert.Equal(t, "550 GB", decimal.Gigabytes(512))
+	assert.Equal(t, "1920 GB", decimal.Gigabytes(1788.18))
+	assert.Equal(t, "15.4 TB", decimal.Terabytes(13.97))
+	assert.Equal(t, "15.4", decimal.TerabyteValue(13.97))
+}
+
+func TestParseSystem(t *testing.T) {
+	for in, want := range map[string]System{"": Binary, "binary": Binary, "decimal": Decimal} {
+		got, err := ParseSystem(in)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+	_, err := ParseSystem("metric")
+	assert.Error(t, err)
+}
+
+func TestMarketingGB(t *testing.T) {
+	for bytes, want := range map[int64]int{
+		240057409536:       240,
+		480103981056:       480,
+		960197124096:       960,
+		1920383410176:      1920,
+		3840755982336:      3840,
+		7681501126656:      7680,
+		600127266816:       600,
+		1200243695616:      1200,
+		8001563222016:      8000,
+		12000138625024:     12000,
+		GiBToBytes(894.25): 960,
+		// Not a standard size: whole decimal GB
+		GiBToBytes(64): 69,
+		0:              0,
+	} {
+		assert.Equal(t, want, MarketingGB(bytes), "%d bytes", bytes)
+	}
+}
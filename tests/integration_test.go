@@ -2,21 +2,30 @@
 package tests
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-	"idrac-inventory/internal/config"
-	"idrac-inventory/internal/models"
-	"idrac-inventory/internal/netbox"
-	"idrac-inventory/internal/redfish"
-	"idrac-inventory/internal/scanner"
-	"idrac-inventory/pkg/logging"
+
+	"github.com/braunma/idrac-netbox-importer/internal/history"
+	"github.com/braunma/idrac-netbox-importer/internal/output"
+	"github.com/braunma/idrac-netbox-importer/internal/redfish"
+	"github.com/braunma/idrac-netbox-importer/pkg/config"
+	"github.com/braunma/idrac-netbox-importer/pkg/logging"
+	"github.com/braunma/idrac-netbox-importer/pkg/models"
+	"github.com/braunma/idrac-netbox-importer/pkg/netbox"
+	"github.com/braunma/idrac-netbox-importer/pkg/redact"
+	"github.com/braunma/idrac-netbox-importer/pkg/scanner"
 )
 
 func init() {
@@ -70,6 +79,206 @@ func TestFullScanWorkflow(t *testing.T) {
 	assert.Equal(t, float64(100), stats.SuccessRate())
 }
 
+// TestMultiSystemScan tests that a BMC exposing several systems yields one
+// result per system, with the resource paths taken from the system links.
+func TestMultiSystemScan(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/redfish/v1/Systems":
+			json.NewEncoder(w).Encode(redfish.Collection{Members: []redfish.Link{
+				{OdataID: "/redfish/v1/Systems/Node1"},
+				{OdataID: "/redfish/v1/Systems/Node2/"},
+			}})
+		case "/redfish/v1/Systems/Node1", "/redfish/v1/Systems/Node2":
+			id := filepath.Base(r.URL.Path)
+			json.NewEncoder(w).Encode(redfish.System{
+				ID:           id,
+				Model:        "Multi-Node Sled",
+				Manufacturer: "Example",
+				SKU:          "TAG-" + id,
+				Processors:   redfish.Link{OdataID: "/redfish/v1/Systems/" + id + "/CPUs"},
+				Links: redfish.SystemLinks{
+					Chassis: []redfish.Link{{OdataID: "/redfish/v1/Chassis/Sled-" + id}},
+				},
+				ProcessorSummary: redfish.ProcessorSummary{Count: 1},
+			})
+		case "/redfish/v1/Systems/Node1/CPUs", "/redfish/v1/Systems/Node2/CPUs":
+			json.NewEncoder(w).Encode(redfish.Collection{})
+		case "/redfish/v1/Chassis/Sled-Node2/Power":
+			json.NewEncoder(w).Encode(redfish.Power{
+				PowerControl: []redfish.PowerControl{{PowerConsumedWatts: 180}},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{
+		Servers:     []config.ServerConfig{{Host: server.Listener.Addr().String()}},
+		Defaults:    config.DefaultsConfig{TimeoutSeconds: 10},
+		Concurrency: 1,
+	}
+	results, stats := scanner.New(cfg).ScanAll(context.Background())
+
+	require.Len(t, results, 2)
+	assert.Equal(t, 2, stats.TotalServers)
+	assert.Equal(t, 2, stats.SuccessfulCount)
+	for i, id := range []string{"Node1", "Node2"} {
+		assert.NoError(t, results[i].Error)
+		assert.Equal(t, id, results[i].SystemID)
+		assert.Equal(t, "/redfish/v1/Chassis/Sled-"+id, results[i].ParentChassis)
+		assert.Equal(t, "TAG-"+id, results[i].ServiceTag)
+		assert.Equal(t, cfg.Servers[0].Host+"/"+id, results[i].Key())
+	}
+	assert.Zero(t, results[0].PowerConsumedWatts)
+	assert.Equal(t, 180, results[1].PowerConsumedWatts, "power is read from the system's chassis")
+}
+
+// TestPowerSubsystemScan tests that power data is read from PowerSubsystem
+// and EnvironmentMetrics when the chassis links them, and that the legacy
+// Power resource is only used for what they lack.
+func TestPowerSubsystemScan(t *testing.T) {
+	watts := func(v float64) *float64 { return &v }
+	withMetrics := true
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/redfish/v1/Systems/System.Embedded.1":
+			json.NewEncoder(w).Encode(redfish.System{Model: "PowerEdge R770", SKU: "NEWFW01"})
+		case "/redfish/v1/Chassis/System.Embedded.1":
+			chassis := redfish.Chassis{
+				ID:             "System.Embedded.1",
+				PowerSubsystem: redfish.Link{OdataID: "/redfish/v1/Chassis/System.Embedded.1/PowerSubsystem"},
+			}
+			if withMetrics {
+				chassis.EnvironmentMetrics = redfish.Link{OdataID: "/redfish/v1/Chassis/System.Embedded.1/EnvironmentMetrics"}
+			}
+			json.NewEncoder(w).Encode(chassis)
+		case "/redfish/v1/Chassis/System.Embedded.1/PowerSubsystem":
+			json.NewEncoder(w).Encode(redfish.PowerSubsystem{
+				PowerSupplies: redfish.Link{OdataID: "/redfish/v1/Chassis/System.Embedded.1/PowerSubsystem/PowerSupplies"},
+			})
+		case "/redfish/v1/Chassis/System.Embedded.1/PowerSubsystem/PowerSupplies":
+			json.NewEncoder(w).Encode(redfish.Collection{Members: []redfish.Link{
+				{OdataID: "/redfish/v1/Chassis/System.Embedded.1/PowerSubsystem/PowerSupplies/PSU.Slot.1"},
+			}})
+		case "/redfish/v1/Chassis/System.Embedded.1/PowerSubsystem/PowerSupplies/PSU.Slot.1":
+			json.NewEncoder(w).Encode(redfish.SubsystemPowerSupply{
+				ID:                 "PSU.Slot.1",
+				Name:               "PS1 Status",
+				Model:              "PWR SPLY,1400W,RDNT",
+				SerialNumber:       "CNPSU0001",
+				PowerCapacityWatts: 1400,
+				Metrics:            redfish.Link{OdataID: "/redfish/v1/Chassis/System.Embedded.1/PowerSubsystem/PowerSupplies/PSU.Slot.1/Metrics"},
+				Status:             redfish.Status{Health: "OK", State: "Enabled"},
+			})
+		case "/redfish/v1/Chassis/System.Embedded.1/PowerSubsystem/PowerSupplies/PSU.Slot.1/Metrics":
+			json.NewEncoder(w).Encode(redfish.PowerSupplyMetrics{
+				InputPowerWatts: redfish.SensorExcerpt{Reading: watts(301)},
+			})
+		case "/redfish/v1/Chassis/System.Embedded.1/EnvironmentMetrics":
+			json.NewEncoder(w).Encode(redfish.EnvironmentMetrics{
+				PowerWatts: redfish.SensorExcerpt{
+					DataSourceURI: "/redfish/v1/Chassis/System.Embedded.1/Sensors/SystemBoardPwrConsumption",
+					Reading:       watts(289.6),
+				},
+			})
+		case "/redfish/v1/Chassis/System.Embedded.1/Sensors/SystemBoardPwrConsumption":
+			json.NewEncoder(w).Encode(redfish.Sensor{Reading: watts(289.6), PeakReading: watts(512)})
+		case "/redfish/v1/Chassis/System.Embedded.1/Power":
+			json.NewEncoder(w).Encode(redfish.Power{
+				PowerControl:  []redfish.PowerControl{{PowerConsumedWatts: 250}},
+				PowerSupplies: []redfish.PowerSupply{{Name: "legacy PSU"}},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{
+		Servers:     []config.ServerConfig{{Host: server.Listener.Addr().String()}},
+		Defaults:    config.DefaultsConfig{TimeoutSeconds: 10},
+		Concurrency: 1,
+	}
+
+	results, _ := scanner.New(cfg).ScanAll(context.Background())
+	require.Len(t, results, 1)
+	require.NoError(t, results[0].Error)
+	assert.Equal(t, 290, results[0].PowerConsumedWatts)
+	assert.Equal(t, 512, results[0].PowerPeakWatts)
+	require.Len(t, results[0].PowerSupplies, 1)
+	psu := results[0].PowerSupplies[0]
+	assert.Equal(t, "PWR SPLY,1400W,RDNT", psu.Model)
+	assert.Equal(t, "CNPSU0001", psu.SerialNumber)
+	assert.Equal(t, 1400.0, float64(psu.CapacityWatts))
+	assert.Equal(t, 301.0, float64(psu.InputWatts))
+	assert.Equal(t, "OK", psu.Health)
+
+	// The mock serves no memory, so the memory collector is unsupported
+	assert.Equal(t, models.CapabilitySupported, results[0].Capabilities[models.PhasePower])
+	assert.Equal(t, models.CapabilityUnsupported, results[0].Capabilities[models.PhaseMemory])
+	assert.Contains(t, results[0].UnsupportedEndpoints, models.UnsupportedEndpoint{
+		Phase: models.PhaseMemory, Path: "/redfish/v1/Systems/System.Embedded.1/Memory", Status: http.StatusNotFound,
+	})
+
+	// Without EnvironmentMetrics the draw comes from the Power resource, but
+	// the power supplies are still those of PowerSubsystem
+	withMetrics = false
+	results, _ = scanner.New(cfg).ScanAll(context.Background())
+	require.Len(t, results, 1)
+	assert.Equal(t, 250, results[0].PowerConsumedWatts)
+	require.Len(t, results[0].PowerSupplies, 1)
+	assert.Equal(t, "CNPSU0001", results[0].PowerSupplies[0].SerialNumber)
+}
+
+// TestScanStream tests that streamed results arrive one by one and can be
+// written incrementally by a StreamFormatter.
+func TestScanStream(t *testing.T) {
+	idracServer := createMockiDRAC(t)
+	defer idracServer.Close()
+
+	cfg := &config.Config{
+		Servers: []config.ServerConfig{
+			{Host: idracServer.Listener.Addr().String(), Username: "admin", Password: "password"},
+			{Host: "127.0.0.1:1", Username: "admin", Password: "password"},
+		},
+		Defaults:    config.DefaultsConfig{TimeoutSeconds: 5},
+		Concurrency: 2,
+	}
+
+	results := make(chan models.ServerInfo)
+	statsCh := make(chan models.CollectionStats, 1)
+	go func() { statsCh <- scanner.New(cfg).ScanStream(context.Background(), results) }()
+
+	var buf bytes.Buffer
+	formatter := output.NewJSONFormatter(true)
+	require.NoError(t, formatter.Begin(&buf))
+	agg := models.NewAggregator(models.FingerprintOptions{})
+	for info := range results {
+		require.NoError(t, formatter.WriteServer(&buf, info))
+		agg.Add(info)
+	}
+	stats := <-statsCh
+	require.NoError(t, formatter.End(&buf, stats))
+
+	assert.Equal(t, 2, stats.TotalServers)
+	assert.Equal(t, 1, stats.SuccessfulCount)
+	assert.Equal(t, 1, stats.FailedCount)
+
+	got, gotStats, err := output.ReadJSON(&buf)
+	require.NoError(t, err)
+	assert.Len(t, got, 2)
+	assert.Equal(t, stats.TotalServers, gotStats.TotalServers)
+
+	inv := agg.Inventory(stats)
+	assert.Equal(t, 1, inv.SuccessfulCount)
+	require.Len(t, inv.ModelGroups, 1)
+	assert.Equal(t, "PowerEdge R750", inv.ModelGroups[0].Model)
+}
+
 // TestScanWithNetBoxSync tests scanning and syncing to NetBox.
 func TestScanWithNetBoxSync(t *testing.T) {
 	// Create mock iDRAC server
@@ -246,6 +455,315 @@ func TestScanWithFailures(t *testing.T) {
 	}
 }
 
+// TestPartialScan tests that a failed system resource does not abort the
+// host while the other collectors work, and that the host fails if none do.
+func TestPartialScan(t *testing.T) {
+	mock := createMockiDRAC(t)
+	defer mock.Close()
+
+	var mu sync.Mutex
+	failing := map[string]bool{"/redfish/v1/Systems/System.Embedded.1": true}
+	broken := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		fail := failing[r.URL.Path]
+		mu.Unlock()
+		if fail {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		mock.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer broken.Close()
+
+	cfg := &config.Config{
+		Servers: []config.ServerConfig{
+			{Host: broken.Listener.Addr().String(), Username: "admin", Password: "password"},
+		},
+		Defaults:    config.DefaultsConfig{TimeoutSeconds: 5},
+		Concurrency: 1,
+	}
+
+	results, stats := scanner.New(cfg).ScanAll(context.Background())
+	require.Len(t, results, 1)
+	assert.Equal(t, 1, stats.SuccessfulCount)
+	assert.Equal(t, 1, stats.PartialCount)
+	assert.Equal(t, map[string]int{models.PhaseSystem: 1}, stats.FailedComponents)
+	res := results[0]
+	assert.True(t, res.IsValid())
+	assert.True(t, res.IsPartial())
+	assert.Contains(t, res.ComponentErrors, models.PhaseSystem)
+	assert.Empty(t, res.Model, "the system resource failed")
+	assert.Equal(t, 2, res.CPUCount, "processors are collected anyway")
+	assert.NotEmpty(t, res.Drives)
+
+	mu.Lock()
+	for _, path := range []string{
+		"/redfish/v1/Systems/System.Embedded.1/Processors",
+		"/redfish/v1/Systems/System.Embedded.1/Memory",
+		"/redfish/v1/Systems/System.Embedded.1/Storage",
+	} {
+		failing[path] = true
+	}
+	mu.Unlock()
+
+	results, _ = scanner.New(cfg).ScanAll(context.Background())
+	require.Len(t, results, 1)
+	assert.False(t, results[0].IsValid(), "no component could be collected")
+}
+
+// TestRescanRecoversTransientFailure tests that the second pass retries a host
+// whose first scan failed and merges the successful result.
+func TestRescanRecoversTransientFailure(t *testing.T) {
+	mock := createMockiDRAC(t)
+	defer mock.Close()
+
+	var mu sync.Mutex
+	failedOnce := false
+	flaky := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		first := !failedOnce
+		failedOnce = true
+		mu.Unlock()
+
+		if first {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		mock.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer flaky.Close()
+
+	cfg := &config.Config{
+		Servers: []config.ServerConfig{
+			{
+				Host:     flaky.Listener.Addr().String(),
+				Username: "admin",
+				Password: "password",
+			},
+		},
+		Defaults:    config.DefaultsConfig{TimeoutSeconds: 5},
+		Concurrency: 1,
+		Rescan:      config.RescanConfig{Enabled: true},
+	}
+
+	results, stats := scanner.New(cfg).ScanAll(context.Background())
+
+	require.Len(t, results, 1)
+	assert.True(t, results[0].IsValid())
+	assert.Equal(t, "PowerEdge R750", results[0].Model)
+	assert.Equal(t, 1, stats.SuccessfulCount)
+	assert.Equal(t, 1, stats.RescannedCount)
+	assert.Equal(t, 1, stats.RecoveredCount)
+}
+
+// TestSkipUnchangedHosts tests that a host whose Lifecycle Controller
+// timestamp matches the history is not scanned in depth again, and that a
+// new timestamp triggers a full scan.
+func TestSkipUnchangedHosts(t *testing.T) {
+	mock := createMockiDRAC(t)
+	defer mock.Close()
+
+	var mu sync.Mutex
+	inventoryTime := "2025-03-01T08:15:00-06:00"
+	memoryRequests := 0
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.URL.Path {
+		case "/redfish/v1/Systems/System.Embedded.1":
+			json.NewEncoder(w).Encode(redfish.System{
+				Model:        "PowerEdge R750",
+				SerialNumber: "ABC123",
+				SKU:          "SVCTAG01",
+				PowerState:   "On",
+				Oem: redfish.SystemOEM{Dell: &redfish.DellSystemOEM{DellSystem: &redfish.DellSystemAttributes{
+					MaxDIMMSlots:            4,
+					LastSystemInventoryTime: inventoryTime,
+					LastUpdateTime:          "2025-02-10T12:00:00Z",
+					ManufactureDate:         "2021-06-14T00:00:00Z",
+				}}},
+			})
+			return
+		case "/redfish/v1/Systems/System.Embedded.1/Memory":
+			memoryRequests++
+		}
+		mock.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{
+		Servers: []config.ServerConfig{
+			{Host: server.Listener.Addr().String(), Username: "admin", Password: "password"},
+		},
+		Defaults:    config.DefaultsConfig{TimeoutSeconds: 10},
+		Concurrency: 1,
+		Paths:       config.PathsConfig{StateDir: t.TempDir()},
+		History:     config.HistoryConfig{Enabled: true, SkipUnchanged: true},
+	}
+	historyPath := cfg.History.GetPath(cfg.Paths.GetStateDir())
+	scan := func() ([]models.ServerInfo, models.CollectionStats) {
+		results, stats := scanner.New(cfg).ScanAll(context.Background())
+		store, err := history.Load(historyPath)
+		require.NoError(t, err)
+		store.Apply(results)
+		require.NoError(t, store.Save())
+		return results, stats
+	}
+
+	first, stats := scan()
+	require.Len(t, first, 1)
+	require.NoError(t, first[0].Error)
+	assert.False(t, first[0].Unchanged)
+	require.NotNil(t, first[0].LastChangedAt)
+	assert.Equal(t, "2025-03-01T14:15:00Z", first[0].LastChangedAt.Format(time.RFC3339))
+	require.NotNil(t, first[0].ManufactureDate)
+	assert.Equal(t, "2021-06-14", first[0].ManufactureDate.Format("2006-01-02"))
+	assert.Equal(t, models.ManufactureDateIDRAC, first[0].ManufactureDateSource)
+	assert.Equal(t, 1, memoryRequests)
+	assert.Zero(t, stats.UnchangedCount)
+
+	second, stats := scan()
+	require.Len(t, second, 1)
+	assert.True(t, second[0].Unchanged)
+	require.NotNil(t, second[0].FullScanAt)
+	assert.True(t, second[0].FullScanAt.Equal(first[0].CollectedAt))
+	assert.Equal(t, first[0].Memory, second[0].Memory, "memory is reused from the first scan")
+	assert.Equal(t, 2, second[0].CPUCount)
+	assert.Equal(t, 1, memoryRequests, "the memory was not read again")
+	assert.Equal(t, 1, stats.UnchangedCount)
+
+	mu.Lock()
+	inventoryTime = "2025-03-20T09:00:00Z"
+	mu.Unlock()
+	third, stats := scan()
+	require.Len(t, third, 1)
+	assert.False(t, third[0].Unchanged)
+	assert.Nil(t, third[0].FullScanAt)
+	assert.Equal(t, 2, memoryRequests)
+	assert.Zero(t, stats.UnchangedCount)
+}
+
+// TestValidateConnections tests that validate mode reports version, firmware,
+// certificate and latency per host.
+func TestValidateConnections(t *testing.T) {
+	idracServer := createMockiDRAC(t)
+	defer idracServer.Close()
+
+	cfg := &config.Config{
+		Servers: []config.ServerConfig{
+			{Host: idracServer.Listener.Addr().String(), Username: "admin", Password: "password"},
+			{Host: idracServer.Listener.Addr().String() + "0", Username: "admin", Password: "password"},
+		},
+		Defaults:    config.DefaultsConfig{TimeoutSeconds: 2},
+		Concurrency: 2,
+	}
+
+	checks := scanner.New(cfg).ValidateConnections(context.Background())
+
+	require.Len(t, checks, 2)
+	ok := checks[0]
+	assert.True(t, ok.OK())
+	assert.Equal(t, "1.13.0", ok.RedfishVersion)
+	assert.Equal(t, "6.10.30.00", ok.FirmwareVersion)
+	assert.Positive(t, ok.Latency)
+	require.NotNil(t, ok.Certificate)
+	_, hasCert := ok.CertDaysLeft(time.Now())
+	assert.True(t, hasCert)
+	require.NotNil(t, ok.Clock)
+	assert.True(t, ok.Clock.Drifted())
+	assert.InDelta(t, 600, ok.Clock.DriftSeconds, 2)
+
+	assert.False(t, checks[1].OK())
+}
+
+// TestAccountAudit tests the opt-in audit of the iDRAC accounts and the
+// default credential probe.
+func TestAccountAudit(t *testing.T) {
+	idracServer := createMockiDRAC(t)
+	defer idracServer.Close()
+
+	cfg := &config.Config{
+		Servers: []config.ServerConfig{
+			{Host: idracServer.Listener.Addr().String(), Username: "admin", Password: "password"},
+		},
+		Defaults:    config.DefaultsConfig{TimeoutSeconds: 10},
+		Concurrency: 1,
+		Accounts: config.AccountsConfig{
+			Audit:                   true,
+			ProbeDefaultCredentials: true,
+			MaxAdministrators:       1,
+		},
+	}
+
+	results, _ := scanner.New(cfg).ScanAll(context.Background())
+	require.Len(t, results, 1)
+	audit := results[0].Accounts
+	require.NotNil(t, audit)
+	assert.Len(t, audit.Accounts, 2, "empty slots are skipped")
+	require.NotNil(t, audit.DefaultCredentials)
+	assert.False(t, *audit.DefaultCredentials, "the mock rejects root/calvin")
+	assert.Equal(t, []string{"default user root enabled", "2 administrators (max 1)"}, audit.Risks())
+	assert.Contains(t, results[0].PhaseDurations, models.PhaseAccounts)
+
+	report := models.BuildAccountAuditReport(results)
+	require.Len(t, report, 1)
+	assert.Equal(t, []string{"root", "admin"}, report[0].Administrators)
+
+	// Not collected unless enabled
+	cfg.Accounts = config.AccountsConfig{}
+	results, _ = scanner.New(cfg).ScanAll(context.Background())
+	assert.Nil(t, results[0].Accounts)
+}
+
+// TestQueryPowerStates tests that the power-state command reads the power
+// state from the System resource without scanning.
+func TestQueryPowerStates(t *testing.T) {
+	var mu sync.Mutex
+	var paths []string
+	idrac := createMockiDRAC(t)
+	handler := idrac.Config.Handler
+	idrac.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.Method+" "+r.URL.Path)
+		mu.Unlock()
+		handler.ServeHTTP(w, r)
+	})
+	defer idrac.Close()
+
+	cfg := &config.Config{
+		Servers: []config.ServerConfig{
+			{Host: idrac.Listener.Addr().String(), Name: "web01", Username: "admin", Password: "password"},
+			{Host: idrac.Listener.Addr().String(), Username: "admin", Password: "wrong"},
+		},
+		Defaults:    config.DefaultsConfig{TimeoutSeconds: 2},
+		Concurrency: 1,
+		ReadOnly:    true,
+	}
+
+	checks := scanner.New(cfg).QueryPowerStates(context.Background())
+
+	require.Len(t, checks, 2)
+	var ok, failed models.PowerStateCheck
+	for _, c := range checks {
+		if c.Name == "web01" {
+			ok = c
+		} else {
+			failed = c
+		}
+	}
+	require.True(t, ok.OK(), "%v", ok.Error)
+	assert.Equal(t, "On", ok.PowerState)
+	assert.Equal(t, "PowerEdge R750", ok.Model)
+	assert.Equal(t, "SVCTAG01", ok.ServiceTag)
+	assert.False(t, failed.OK())
+	assert.Empty(t, failed.PowerState)
+
+	assert.ElementsMatch(t, []string{
+		"GET /redfish/v1", "GET /redfish/v1/Systems/System.Embedded.1",
+		"GET /redfish/v1",
+	}, paths, "only the service root and the System resource are read")
+}
+
 // TestContextCancellation tests proper handling of context cancellation.
 func TestContextCancellation(t *testing.T) {
 	// Create slow server
@@ -293,8 +811,9 @@ func createMockiDRAC(t *testing.T) *httptest.Server {
 
 func createMockiDRACWithDelay(t *testing.T, delay time.Duration) *httptest.Server {
 	return httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Add delay if specified
-		if delay > 0 {
+		// Add delay to the system resource if specified, so each scan
+		// takes at least delay regardless of how many collectors run
+		if delay > 0 && r.URL.Path == "/redfish/v1/Systems/System.Embedded.1" {
 			time.Sleep(delay)
 		}
 
@@ -308,12 +827,43 @@ func createMockiDRACWithDelay(t *testing.T, delay time.Duration) *httptest.Serve
 		w.Header().Set("Content-Type", "application/json")
 
 		switch r.URL.Path {
-		case "/redfish/v1/":
+		case "/redfish/v1", "/redfish/v1/":
 			json.NewEncoder(w).Encode(map[string]string{
 				"RedfishVersion": "1.13.0",
 				"Name":           "Root Service",
 			})
 
+		case "/redfish/v1/Systems":
+			json.NewEncoder(w).Encode(redfish.Collection{
+				Members: []redfish.Link{{OdataID: "/redfish/v1/Systems/System.Embedded.1"}},
+			})
+
+		case "/redfish/v1/Managers/iDRAC.Embedded.1":
+			json.NewEncoder(w).Encode(redfish.Manager{
+				ID:              "iDRAC.Embedded.1",
+				ManagerType:     "BMC",
+				FirmwareVersion: "6.10.30.00",
+				DateTime:        time.Now().Add(10 * time.Minute).Format(time.RFC3339), // clock drifted ahead
+			})
+
+		case "/redfish/v1/AccountService/Accounts":
+			json.NewEncoder(w).Encode(redfish.Collection{
+				Members: []redfish.Link{
+					{OdataID: "/redfish/v1/AccountService/Accounts/2"},
+					{OdataID: "/redfish/v1/AccountService/Accounts/3"},
+					{OdataID: "/redfish/v1/AccountService/Accounts/4"},
+				},
+			})
+
+		case "/redfish/v1/AccountService/Accounts/2":
+			json.NewEncoder(w).Encode(redfish.ManagerAccount{ID: "2", UserName: "root", RoleID: "Administrator", Enabled: true})
+
+		case "/redfish/v1/AccountService/Accounts/3":
+			json.NewEncoder(w).Encode(redfish.ManagerAccount{ID: "3", UserName: "admin", RoleID: "Administrator", Enabled: true})
+
+		case "/redfish/v1/AccountService/Accounts/4":
+			json.NewEncoder(w).Encode(redfish.ManagerAccount{ID: "4", RoleID: "None"}) // empty slot
+
 		case "/redfish/v1/Systems/System.Embedded.1":
 			json.NewEncoder(w).Encode(redfish.System{
 				Model:        "PowerEdge R750",
@@ -395,6 +945,55 @@ func createMockiDRACWithDelay(t *testing.T, delay time.Duration) *httptest.Serve
 				"Drives": []map[string]string{
 					{"@odata.id": "/redfish/v1/Systems/System.Embedded.1/Storage/RAID.Integrated.1-1/Drives/Disk.Bay.0"},
 				},
+				"Links": map[string]interface{}{
+					"Enclosures": []map[string]string{
+						{"@odata.id": "/redfish/v1/Chassis/Enclosure.Internal.0-1:RAID.Integrated.1-1"},
+						{"@odata.id": "/redfish/v1/Chassis/System.Embedded.1"},
+					},
+				},
+				"StorageControllers": []map[string]interface{}{{
+					"Name":            "PERC H755 Front",
+					"Model":           "PERC H755 Front",
+					"FirmwareVersion": "52.16.1-4405",
+					"CacheSummary":    map[string]interface{}{"TotalCacheSizeMiB": 8192},
+					"Status":          map[string]string{"Health": "OK"},
+				}},
+				"Oem": map[string]interface{}{
+					"Dell": map[string]interface{}{
+						"DellControllerBattery": map[string]string{
+							"Id":            "Battery.Integrated.1:RAID.Integrated.1-1",
+							"Name":          "Battery on PERC H755 Front",
+							"PrimaryStatus": "Degraded",
+							"RAIDState":     "Degraded",
+						},
+					},
+				},
+			})
+
+		case "/redfish/v1/Chassis/System.Embedded.1":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"Id":          "System.Embedded.1",
+				"ChassisType": "RackMount",
+				"Model":       "PowerEdge R750",
+			})
+
+		case "/redfish/v1/Chassis/Enclosure.Internal.0-1:RAID.Integrated.1-1":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"Id":          "Enclosure.Internal.0-1:RAID.Integrated.1-1",
+				"Name":        "BP15G+ 0:1",
+				"ChassisType": "Enclosure",
+				"Model":       "BP15G+ 8x2.5",
+				"Links": map[string]interface{}{
+					"Drives": []map[string]string{
+						{"@odata.id": "/redfish/v1/Systems/System.Embedded.1/Storage/RAID.Integrated.1-1/Drives/Disk.Bay.0"},
+					},
+				},
+				"Oem": map[string]interface{}{
+					"Dell": map[string]interface{}{
+						"DellEnclosure": map[string]interface{}{"SlotCount": 8, "Version": "7.10", "Connector": 0},
+					},
+				},
+				"Status": map[string]string{"Health": "OK", "State": "Enabled"},
 			})
 
 		case "/redfish/v1/Systems/System.Embedded.1/Storage/RAID.Integrated.1-1/Drives/Disk.Bay.0":
@@ -429,12 +1028,121 @@ func createMockiDRACWithDelay(t *testing.T, delay time.Duration) *httptest.Serve
 				},
 			})
 
+		case "/redfish/v1/Managers/iDRAC.Embedded.1/EthernetInterfaces":
+			json.NewEncoder(w).Encode(redfish.Collection{
+				Members: []redfish.Link{{OdataID: "/redfish/v1/Managers/iDRAC.Embedded.1/EthernetInterfaces/NIC.1"}},
+			})
+
+		case "/redfish/v1/Managers/iDRAC.Embedded.1/EthernetInterfaces/NIC.1":
+			enabled := true
+			json.NewEncoder(w).Encode(redfish.EthernetInterface{
+				ID:               "NIC.1",
+				HostName:         "idrac-abc1234",
+				FQDN:             "idrac-abc1234.mgmt.example.com",
+				MACAddress:       "d0:8e:79:00:00:01",
+				SpeedMbps:        1000,
+				InterfaceEnabled: &enabled,
+				DHCPv4:           redfish.DHCPv4{DHCPEnabled: false},
+				IPv4Addresses: []redfish.IPv4Address{
+					{Address: "192.168.1.100", SubnetMask: "255.255.255.0", Gateway: "192.168.1.1", AddressOrigin: "Static"},
+				},
+				VLAN:        redfish.VLAN{VLANEnable: true, VLANID: 20},
+				NameServers: []string{"192.168.1.53", "0.0.0.0", "::"},
+			})
+
+		case "/redfish/v1/Managers/iDRAC.Embedded.1/Attributes":
+			json.NewEncoder(w).Encode(redfish.ManagerAttributes{
+				Attributes: map[string]interface{}{"NIC.1.Selection": "Dedicated"},
+			})
+
+		case "/redfish/v1/Managers/iDRAC.Embedded.1/Oem/Dell/DellLicenses":
+			json.NewEncoder(w).Encode(redfish.Collection{
+				Members: []redfish.Link{
+					{OdataID: "/redfish/v1/Managers/iDRAC.Embedded.1/Oem/Dell/DellLicenses/FD00000011111111"},
+				},
+			})
+
+		case "/redfish/v1/Managers/iDRAC.Embedded.1/Oem/Dell/DellLicenses/FD00000011111111":
+			json.NewEncoder(w).Encode(redfish.DellLicense{
+				ID:                   "FD00000011111111",
+				EntitlementID:        "FD00000011111111",
+				LicenseDescription:   []string{"iDRAC9 Enterprise License"},
+				LicenseType:          "Perpetual",
+				LicensePrimaryStatus: "OK",
+			})
+
 		default:
 			w.WriteHeader(http.StatusNotFound)
 		}
 	}))
 }
 
+// TestJSONOutputRoundTrip tests that streamed, compressed and chunked JSON
+// output can be read back (as sync -from-file does).
+func TestJSONOutputRoundTrip(t *testing.T) {
+	var servers []models.ServerInfo
+	for i := 0; i < 5; i++ {
+		servers = append(servers, models.ServerInfo{
+			Host:  fmt.Sprintf("10.0.0.%d", i+1),
+			Model: "PowerEdge R750",
+			Memory: []models.MemoryInfo{
+				{Slot: "A1", CapacityMiB: 32768},
+			},
+		})
+	}
+	servers[4].Error = assert.AnError
+	stats := models.CollectionStats{TotalServers: 5, SuccessfulCount: 4, FailedCount: 1}
+
+	t.Run("Streamed", func(t *testing.T) {
+		for _, indent := range []bool{true, false} {
+			var buf bytes.Buffer
+			require.NoError(t, output.NewJSONFormatter(indent).Format(&buf, servers, stats))
+			assert.True(t, json.Valid(buf.Bytes()))
+
+			got, gotStats, err := output.ReadJSON(&buf)
+			require.NoError(t, err)
+			require.Len(t, got, 5)
+			assert.Equal(t, "10.0.0.3", got[2].Host)
+			assert.Equal(t, float64(32), got[0].Memory[0].CapacityGB())
+			assert.Error(t, got[4].Error)
+			assert.Equal(t, stats, gotStats)
+		}
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, output.NewJSONFormatter(true).Format(&buf, nil, models.CollectionStats{}))
+		assert.True(t, json.Valid(buf.Bytes()), buf.String())
+	})
+
+	t.Run("Compressed", func(t *testing.T) {
+		var buf bytes.Buffer
+		zw := output.NewGzipWriter(&buf)
+		require.NoError(t, output.NewJSONFormatter(false).Format(zw, servers, stats))
+		require.NoError(t, zw.Close())
+
+		got, _, err := output.ReadJSON(&buf)
+		require.NoError(t, err)
+		assert.Len(t, got, 5)
+	})
+
+	t.Run("Chunked", func(t *testing.T) {
+		dir := t.TempDir()
+		index, err := output.WriteChunked(dir, servers, stats, 2, true, nil)
+		require.NoError(t, err)
+		require.Len(t, index.Chunks, 3)
+		assert.Equal(t, "servers-0003.json.gz", index.Chunks[2].File)
+		assert.Equal(t, 1, index.Chunks[2].Servers)
+		assert.Equal(t, "10.0.0.3", index.Chunks[1].FirstHost)
+
+		got, gotStats, err := output.ReadJSONFile(filepath.Join(dir, output.ChunkIndexFile))
+		require.NoError(t, err)
+		require.Len(t, got, 5)
+		assert.Equal(t, "10.0.0.5", got[4].Host)
+		assert.Equal(t, stats, gotStats)
+	})
+}
+
 // TestServerInfoMethods tests the ServerInfo helper methods.
 func TestServerInfoMethods(t *testing.T) {
 	t.Run("IsValid", func(t *testing.T) {
@@ -463,3 +1171,105 @@ func TestServerInfoMethods(t *testing.T) {
 		assert.Contains(t, summary, "512 GiB RAM")
 	})
 }
+
+// TestDebugLogsContainNoSecrets scans an iDRAC and syncs to a NetBox that both
+// echo credentials in error responses, and checks that neither the debug log
+// nor the returned errors contain them.
+func TestDebugLogsContainNoSecrets(t *testing.T) {
+	const (
+		password = "S3cret-iDRAC-pw"
+		token    = "0123456789abcdef0123456789abcdef"
+	)
+
+	logFile := filepath.Join(t.TempDir(), "debug.log")
+	require.NoError(t, logging.Reinit(logging.Config{
+		Level:  "debug",
+		Format: "json",
+		File:   logging.FileConfig{Path: logFile},
+	}))
+	defer logging.Reinit(logging.Config{Level: "error", Format: "console"})
+
+	mock := createMockiDRAC(t)
+	defer mock.Close()
+	leaky := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redfish/v1/Systems/System.Embedded.1/Memory" {
+			user, pass, _ := r.BasicAuth()
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprintf(w, `{"error":{"message":"login %s:%s failed","Password":%q,"session":"Authorization: Basic %s"}}`,
+				user, pass, pass, r.Header.Get("Authorization"))
+			return
+		}
+		// The mock only accepts its own credentials
+		r.SetBasicAuth("admin", "password")
+		mock.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer leaky.Close()
+
+	netboxServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(w, `{"detail":"bad request from %s","url":"https://admin:%s@netbox.local/api/"}`,
+			r.Header.Get("Authorization"), token)
+	}))
+	defer netboxServer.Close()
+
+	cfg := &config.Config{
+		Servers: []config.ServerConfig{
+			{Host: leaky.Listener.Addr().String(), Username: "admin", Password: password},
+		},
+		Defaults:    config.DefaultsConfig{TimeoutSeconds: 5},
+		Concurrency: 1,
+		NetBox:      config.NetBoxConfig{URL: netboxServer.URL, Token: token},
+	}
+	redact.AddSecrets(cfg.Secrets()...)
+	defer redact.Reset()
+
+	results, _ := scanner.New(cfg).ScanAll(context.Background())
+	require.Len(t, results, 1)
+	syncResults := netbox.NewClient(cfg.NetBox).SyncAll(context.Background(), results)
+	require.NoError(t, logging.Sync())
+
+	data, err := os.ReadFile(logFile)
+	require.NoError(t, err)
+	logs := string(data)
+	assert.Contains(t, logs, "redfish API error", "the leaky response was logged")
+
+	for _, secret := range []string{password, token} {
+		assert.NotContains(t, logs, secret)
+		for _, r := range syncResults {
+			if r.Error != nil {
+				assert.NotContains(t, r.Error.Error(), secret)
+			}
+		}
+	}
+}
+
+// TestAcceptLanguage tests that http.accept_language is sent to the iDRAC.
+func TestAcceptLanguage(t *testing.T) {
+	mock := createMockiDRAC(t)
+	defer mock.Close()
+
+	var mu sync.Mutex
+	languages := map[string]bool{}
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		languages[r.Header.Get("Accept-Language")] = true
+		mu.Unlock()
+		mock.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{
+		Servers: []config.ServerConfig{
+			{Host: server.Listener.Addr().String(), Username: "admin", Password: "password"},
+		},
+		Defaults:    config.DefaultsConfig{TimeoutSeconds: 5},
+		Concurrency: 1,
+		HTTP:        config.HTTPConfig{AcceptLanguage: "en-US"},
+	}
+
+	results, _ := scanner.New(cfg).ScanAll(context.Background())
+
+	require.Len(t, results, 1)
+	assert.True(t, results[0].IsValid())
+	assert.Equal(t, map[string]bool{"en-US": true}, languages)
+}